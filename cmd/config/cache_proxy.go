@@ -3,6 +3,7 @@ package config
 import (
 	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 	"github.com/spf13/cobra"
 )
@@ -29,10 +30,10 @@ konflux-build-cli config cache-proxy --enable true --http-proxy-result-path "/tm
 		l.Logger.Debug("Configuring cache-proxy...")
 		enableCacheProxy, err := commands.NewCacheProxy(cmd)
 		if err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		if err := enableCacheProxy.Run(); err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		l.Logger.Debug("cache-proxy configured")
 	},
@@ -40,4 +41,5 @@ konflux-build-cli config cache-proxy --enable true --http-proxy-result-path "/tm
 
 func init() {
 	common.RegisterParameters(ConfigCacheProxyCmd, commands.CacheProxyParamsConfig)
+	common.RegisterResults(ConfigCacheProxyCmd, commands.CacheProxyResults{})
 }
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+)
+
+var generateTektonTaskImage string
+
+var generateTektonTaskCmd = &cobra.Command{
+	Use:    "generate-tekton-task <command>",
+	Short:  "Generate the Tekton Task YAML of a command",
+	Hidden: true,
+	Long: `Renders a Tekton Task running the command, generated from the definitions of its parameters
+and results, so that the tasks of the Konflux task catalog stay in sync with the CLI.
+
+Each parameter becomes a string param of the task passed in its env var, array parameters
+take space separated values. The results are written by the Tekton results mode (--tekton-results).`,
+	Example: `  # Generate the Task of image build
+  konflux-build-cli generate-tekton-task image build > image-build.yaml`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeCommandPath,
+	Run: func(cmd *cobra.Command, args []string) {
+		task, err := generateTektonTask(args)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		fmt.Print(string(task))
+	},
+}
+
+func generateTektonTask(args []string) ([]byte, error) {
+	command, err := findCommandWithParameters(args)
+	if err != nil {
+		return nil, err
+	}
+	task, err := common.GenerateTektonTask(command, generateTektonTaskImage)
+	if err != nil {
+		return nil, kbcerrors.NewValidationError(err)
+	}
+	return task, nil
+}
+
+func init() {
+	generateTektonTaskCmd.Flags().StringVar(&generateTektonTaskImage, "image", common.DefaultTektonTaskImage, "Image the step of the task runs in")
+}
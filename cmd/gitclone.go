@@ -5,6 +5,7 @@ import (
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/commands/gitclone"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -32,10 +33,10 @@ authentication, and optional merge with a target branch.`,
 		l.Logger.Debug("Starting git-clone")
 		gitClone, err := gitclone.New(cmd)
 		if err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		if err := gitClone.Run(); err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		l.Logger.Debug("Finished git-clone")
 	},
@@ -43,4 +44,5 @@ authentication, and optional merge with a target branch.`,
 
 func init() {
 	common.RegisterParameters(gitCloneCmd, gitclone.ParamsConfig)
+	common.RegisterResults(gitCloneCmd, gitclone.Results{})
 }
@@ -15,5 +15,25 @@ func init() {
 	imageCmd.AddCommand(image.ApplyTagsCmd)
 	imageCmd.AddCommand(image.BuildCmd)
 	imageCmd.AddCommand(image.BuildImageIndexCmd)
+	imageCmd.AddCommand(image.BuildSourceImageCmd)
+	imageCmd.AddCommand(image.CompareLayersCmd)
+	imageCmd.AddCommand(image.ConvertCmd)
+	imageCmd.AddCommand(image.DownloadSBOMCmd)
+	imageCmd.AddCommand(image.ExtractArtifactCmd)
+	imageCmd.AddCommand(image.GenerateProvenanceCmd)
+	imageCmd.AddCommand(image.InspectCmd)
+	imageCmd.AddCommand(image.LintCmd)
+	imageCmd.AddCommand(image.ListArtifactsCmd)
+	imageCmd.AddCommand(image.MergeSBOMCmd)
+	imageCmd.AddCommand(image.MirrorCmd)
+	imageCmd.AddCommand(image.OciCopyCmd)
+	imageCmd.AddCommand(image.PruneTagsCmd)
+	imageCmd.AddCommand(image.PushArtifactCmd)
 	imageCmd.AddCommand(image.PushContainerfileCmd)
+	imageCmd.AddCommand(image.PushPrefetchOutputCmd)
+	imageCmd.AddCommand(image.PushSBOMCmd)
+	imageCmd.AddCommand(image.SetLabelsCmd)
+	imageCmd.AddCommand(image.SignImageCmd)
+	imageCmd.AddCommand(image.SquashPushCmd)
+	imageCmd.AddCommand(image.VerifyImageSignatureCmd)
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -19,15 +20,42 @@ Tags can be defined in two ways:
  - via tags parameter
  - via image label in the base image (see --tags-from-image-label parameter)
 Both ways can be used together.
+
+A tags parameter entry can also be a full destination with a transport, to write
+the image somewhere else than its repository:
+ - docker://registry.io/org/archive:tag - another repository, credentials are selected for the destination
+ - oci:/path/to/layout:tag - an OCI layout directory, e.g. for archival
+ - dir:/path/to/dir - a plain directory
+Local destinations get the images for all the platforms, not just the index.
+
+With --destination-repo, the tags are created in another repository instead of the one of the
+image, e.g. to promote quay.io/org/app@sha256:... to quay.io/org/app-released:v1.
+The credentials for pushing can be given separately with --dest-auth-file.
+
+Tags on quay.io can be made temporary with --tag-expires-after, e.g. for pull request builds.
+The expiration is set via the Quay API using the token from --quay-token-dir, so that
+the tags get removed by Quay once they expire. Tags on other registries don't expire.
+
+With --verify, the created tags in registries are inspected after the copy and the command
+fails if a tag doesn't point to the image digest, e.g. when another pipeline pushed the same
+tag at the same time. The verified digests are reported in the results.
+
+Re-running a pipeline doesn't need to copy the image again: with --skip-existing, the tags in
+registries already pointing to the image digest are skipped and reported in the results.
+With --dry-run, the command only checks that the image exists and logs the tags it would create.
+
+With --require-signature, the cosign signature of the image is verified before any tag is created,
+with --signature-key or keyless with the certificate identity and OIDC issuer, and the command
+fails if it doesn't pass. See also the verify-image-signature command.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		l.Logger.Debug("Starting apply-tags")
 		applyTags, err := commands.NewApplyTags(cmd)
 		if err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		if err := applyTags.Run(); err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		l.Logger.Debug("Finished apply-tags")
 	},
@@ -35,4 +63,5 @@ Both ways can be used together.
 
 func init() {
 	common.RegisterParameters(ApplyTagsCmd, commands.ApplyTagsParamsConfig)
+	common.RegisterResults(ApplyTagsCmd, commands.ApplyTagsResults{})
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -35,6 +36,55 @@ Secret Handling:
   The --mount option makes them available at /run/secrets/<basename>/<filename>
   for that particular RUN instruction.
 
+  Two files resolving to the same id are an error by default. Use
+  --on-duplicate-secret=suffix to rename the later ones (<basename>/<filename>-2,
+  -3, ...) or --on-duplicate-secret=skip to keep only the first one.
+
+  The secret ids are checked against the RUN --mount=type=secret instructions
+  before the build starts. Secrets mounted with required=true that are not
+  provided fail the build. So do provided secrets that nothing uses, unless
+  --allow-unused-secrets is set.
+
+bootc Images (--bootc):
+  Builds a bootable container image (RHEL image mode, Fedora bootc). The final
+  stage must be based on a bootc base image, or set the containers.bootc=1
+  label when building from scratch. The image is built and pushed in the oci
+  format, --squash is rejected because it would undo the layer splitting of
+  the base image. With --inherit-labels=false, the containers.bootc and
+  ostree.bootable labels are still added.
+
+  Instructions that don't affect a booted system (CMD, ENTRYPOINT, VOLUME,
+  EXPOSE, USER, ...) are reported as warnings. The logically bound images
+  (/usr/lib/bootc/bound-images.d) of the built image are listed in the
+  bound_images result, images not pinned by digest are reported as warnings.
+
+SBOM (--sbom-output):
+  Scans the built image with syft and writes its SBOM in --sbom-format. The
+  scan of the context directory (--syft-source-output) and the SBOM of the
+  prefetched dependencies (prefetch-dependencies output in --prefetch-dir) are
+  merged into it, they must be in the same format. The path and the sha256
+  digest of the SBOM are reported in the sbom_path and sbom_digest results.
+
+Multi-platform Builds (--platform):
+  Builds the image for the given platforms instead of the host platform, e.g.
+  --platform linux/amd64,linux/arm64. The TARGETPLATFORM, TARGETOS, TARGETARCH
+  and TARGETVARIANT build args are set for each platform, the base images are
+  pulled and verified for each platform. Building for a platform other than
+  the host runs RUN instructions under emulation (qemu-user-static).
+
+  With more than one platform, the images are collected in a manifest list
+  named after --output-ref, which is pushed with all its images by --push.
+  --bootc, --syft-image-output, --sbom-output and --checkpoint-file need a
+  single image and are rejected.
+
+Interruption and Resume:
+  On SIGTERM or SIGINT (e.g. a cancelled TaskRun), the signal is forwarded to
+  the running buildah process so that it can stop cleanly, and no further
+  steps are started. With --checkpoint-file, the completed phases (built image,
+  pushed image and additional tags) are recorded as they finish. A re-run with
+  --resume skips them, as long as the Containerfile and the parameters are the
+  same and the built image is still in local storage.
+
 Red Hat Subscription Management (RHSM) Handling:
   Fedora and RHEL machines typically have implicit RHSM integration, where if
   the host is subscribed, containers automatically get the subscription as well.
@@ -84,6 +134,12 @@ Red Hat Subscription Management (RHSM) Handling:
       This may be less of a problem in CI pipelines (but do note the requirement
       to run as root).
 
+    Alternatively, use --rhsm-activation-key-dir=DIRECTORY with a directory
+    containing the 'activationkey' and 'org' files (the layout of activation
+    key secrets in Konflux). Without --rhsm-activation-preregister, this
+    mounts the directory at /activation-key unless --rhsm-activation-mount
+    says otherwise.
+
     The activation keys approach is more suitable for CI pipelines, because
     unlike entitlement certificates, activation keys do not expire.
 
@@ -119,6 +175,9 @@ Red Hat Subscription Management (RHSM) Handling:
   # Build with explicit Containerfile and context
   konflux-build-cli image build -f ./Containerfile -c ./myapp -t quay.io/myorg/myimage:v1.0.0
 
+  # Build from the myapp directory of a git repository at the v1.0.0 tag
+  konflux-build-cli image build -c 'https://github.com/myorg/myrepo.git#v1.0.0:myapp' -t quay.io/myorg/myimage:v1.0.0
+
   # Build with secrets from directories
   konflux-build-cli image build -t quay.io/myorg/myimage:latest \
     --secret-dirs /path/to/secrets1 src=/path/to/secrets2,name=certs
@@ -129,10 +188,10 @@ Red Hat Subscription Management (RHSM) Handling:
 		l.Logger.Debug("Starting build")
 		build, err := commands.NewBuild(cmd, args)
 		if err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		if err := build.Run(); err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		l.Logger.Debug("Finished build")
 	},
@@ -140,4 +199,5 @@ Red Hat Subscription Management (RHSM) Handling:
 
 func init() {
 	common.RegisterParameters(BuildCmd, commands.BuildParamsConfig)
+	common.RegisterResults(BuildCmd, commands.BuildResults{})
 }
@@ -5,16 +5,20 @@ import (
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
 var BuildImageIndexCmd = &cobra.Command{
-	Use:   "build-image-index",
-	Short: "Build a multi-architecture image index",
+	Use:     "build-image-index",
+	Aliases: []string{"create-manifest-list"},
+	Short:   "Build a multi-architecture image index",
 	Long: `Build a multi-architecture image index (manifest list) from multiple platform-specific images.
 
 This command combines multiple container images into a single image index, enabling
-multi-platform container image support.
+multi-platform container image support. The images are typically the per-platform
+outputs of the build command, referenced by digest. The digest of the pushed index
+is reported in the image_digest field of the results JSON.
 `,
 	Example: `  # Build an image index from multiple platform images
   konflux-build-cli image build-image-index \
@@ -27,6 +31,12 @@ multi-platform container image support.
     --images quay.io/myorg/myapp@sha256:amd64digest... quay.io/myorg/myapp@sha256:arm64digest... \
     --additional-tags taskrun-xyz-12345 commit-abc123
 
+  # Set OCI annotations on the image index
+  konflux-build-cli image build-image-index \
+    --image quay.io/myorg/myapp:latest \
+    --images quay.io/myorg/myapp@sha256:amd64digest... quay.io/myorg/myapp@sha256:arm64digest... \
+    --annotations org.opencontainers.image.revision=abc123 org.opencontainers.image.source=https://github.com/myorg/myapp
+
   # Write results to files (useful for Tekton tasks)
   konflux-build-cli image build-image-index \
     --image quay.io/myorg/myapp:latest \
@@ -39,10 +49,10 @@ multi-platform container image support.
 		l.Logger.Debug("Starting build-image-index")
 		buildImageIndex, err := commands.NewBuildImageIndex(cmd)
 		if err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		if err := buildImageIndex.Run(); err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		l.Logger.Debug("Finished build-image-index")
 	},
@@ -50,4 +60,5 @@ multi-platform container image support.
 
 func init() {
 	common.RegisterParameters(BuildImageIndexCmd, commands.BuildImageIndexParamsConfig)
+	common.RegisterResults(BuildImageIndexCmd, commands.BuildImageIndexResults{})
 }
@@ -0,0 +1,44 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var BuildSourceImageCmd = &cobra.Command{
+	Use:   "build-source-image",
+	Short: "Build and push the source container of a binary image",
+	Long: `Assembles the source container of a binary image and pushes it to the repository of the
+binary image, tagged with the digest of the binary image and --tag-suffix (e.g. sha256-1234567.src).
+
+The source container is an OCI image with a layer for each of:
+  - the source RPMs in --srpm-dir, stored in rpm_dir/
+  - the application source in --source-dir, a tarball without the .git directory stored in extra_src_dir/
+  - the dependencies prefetched into --prefetch-dir, a tarball stored in extra_src_dir/`,
+	Example: `  # Build the source container of the application in the current directory
+  konflux-build-cli image build-source-image --image-url quay.io/org/app:v1 --digest sha256:1234567
+
+  # Include the SRPMs and the prefetched dependencies
+  konflux-build-cli image build-source-image --image-url quay.io/org/app:v1 --digest sha256:1234567 \
+    --source-dir ./source --srpm-dir ./srpms --prefetch-dir ./prefetch`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting build-source-image")
+		buildSourceImage, err := commands.NewBuildSourceImage(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := buildSourceImage.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished build-source-image")
+	},
+}
+
+func init() {
+	common.RegisterParameters(BuildSourceImageCmd, commands.BuildSourceImageParamsConfig)
+	common.RegisterResults(BuildSourceImageCmd, commands.BuildSourceImageResults{})
+}
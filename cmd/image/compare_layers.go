@@ -0,0 +1,42 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var CompareLayersCmd = &cobra.Command{
+	Use:   "compare-layers",
+	Short: "Report layer re-use between two consecutive builds",
+	Long: `Compares the layers of the image produced by the current build with the image produced
+by the previous build and reports how many layers were re-used.
+
+For the layers that changed, the Containerfile instructions that produced them are reported
+(taken from the image history). The first changed instruction is the one that invalidated
+the build cache; restructuring the Containerfile so that it comes later can speed up rebuilds.`,
+	Example: `  # Compare with the previous build, identified by digest
+  konflux-build-cli image compare-layers --image-url quay.io/org/app:v2 --previous-image sha256:1234567
+
+  # Read the previous image from a result file of the previous build
+  konflux-build-cli image compare-layers --image-url quay.io/org/app:v2 --previous-image-file /results/IMAGE_DIGEST`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting compare-layers")
+		compareLayers, err := commands.NewCompareLayers(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := compareLayers.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished compare-layers")
+	},
+}
+
+func init() {
+	common.RegisterParameters(CompareLayersCmd, commands.CompareLayersParamsConfig)
+	common.RegisterResults(CompareLayersCmd, commands.CompareLayersResults{})
+}
@@ -0,0 +1,43 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var ConvertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Re-push an existing image in a different manifest format",
+	Long: `Re-pushes an existing image with its manifests converted to the OCI or Docker schema2 format.
+
+Some downstream consumers still require Docker schema2 manifests, while builds produce OCI
+manifests by default. Image indexes are converted together with all the referenced images.
+
+Note that the conversion changes the image digest.`,
+	Example: `  # Re-push quay.io/org/app:v1 in place as a Docker schema2 image
+  konflux-build-cli image convert --image-url quay.io/org/app:v1 --format docker
+
+  # Convert an image referenced by digest and push it under another tag
+  konflux-build-cli image convert --image-url quay.io/org/app@sha256:1234567 --format docker \
+    --output-ref quay.io/org/app:v1-docker`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting convert")
+		convert, err := commands.NewConvert(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := convert.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished convert")
+	},
+}
+
+func init() {
+	common.RegisterParameters(ConvertCmd, commands.ConvertParamsConfig)
+	common.RegisterResults(ConvertCmd, commands.ConvertResults{})
+}
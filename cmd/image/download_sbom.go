@@ -0,0 +1,50 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var DownloadSBOMCmd = &cobra.Command{
+	Use:   "download-sbom",
+	Short: "Download the SBOM of an image from registry.",
+	Long: `Downloads the SBOM of a binary image from the image repository of the image.
+
+The SBOM is looked up by the conventions push-sbom and cosign use:
+  - tag: the artifact tagged after the digest of the binary image, e.g.
+    quay.io/org/app:sha256-1234567.sbom
+  - referrers: an SBOM artifact referring to the binary image, listed by the
+    OCI 1.1 referrers API
+By default, the tag is tried first, then the referrers.
+
+The downloaded SBOM must be a CycloneDX or SPDX JSON document. It is printed,
+or written to --output, in which case the results JSON is printed instead.`,
+	Example: `
+  # Print the SBOM of quay.io/org/app@sha256:1234567
+  konflux-build-cli image download-sbom --image-url quay.io/org/app --digest sha256:1234567
+
+  # Write the SBOM attached as a referrer into sbom.json
+  konflux-build-cli image download-sbom --image-url quay.io/org/app --digest sha256:1234567 \
+    --source referrers --output sbom.json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting download-sbom")
+		downloadSBOM, err := commands.NewDownloadSBOM(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := downloadSBOM.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished download-sbom")
+	},
+}
+
+func init() {
+	common.RegisterParameters(DownloadSBOMCmd, commands.DownloadSBOMParamsConfig)
+	common.RegisterResults(DownloadSBOMCmd, commands.DownloadSBOMResults{})
+}
@@ -0,0 +1,47 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var ExtractArtifactCmd = &cobra.Command{
+	Use:   "extract-artifact",
+	Short: "Pull an OCI artifact from registry and extract it into a directory.",
+	Long: `Pulls an OCI artifact, e.g. one pushed by push-artifact, push-containerfile or
+push-prefetch-output, and extracts its layers into the output directory.
+
+The digest of every layer is verified, as well as the digest of the manifest
+if --image-ref is referenced by digest.
+
+Layers with a tarball media type (*.tar, *.tar+gzip) are unpacked into the
+output directory, unless --unpack=false. Other layers are saved as files named
+after their org.opencontainers.image.title annotation.`,
+	Example: `
+  # Extract the prefetch output pushed by push-prefetch-output
+  konflux-build-cli image extract-artifact --image-ref quay.io/org/app@sha256:1234567 --output-dir ./prefetch-output
+
+  # Download the Containerfile pushed by push-containerfile
+  konflux-build-cli image extract-artifact --image-ref quay.io/org/app:sha256-1234567.containerfile --output-dir ./source
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting extract-artifact")
+		extractArtifact, err := commands.NewExtractArtifact(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := extractArtifact.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished extract-artifact")
+	},
+}
+
+func init() {
+	common.RegisterParameters(ExtractArtifactCmd, commands.ExtractArtifactParamsConfig)
+	common.RegisterResults(ExtractArtifactCmd, commands.ExtractArtifactResults{})
+}
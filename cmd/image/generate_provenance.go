@@ -0,0 +1,46 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var GenerateProvenanceCmd = &cobra.Command{
+	Use:   "generate-provenance",
+	Short: "Generate SLSA v1 provenance of a built image",
+	Long: `Generates the SLSA v1 provenance predicate of a built image, to be signed and attached later.
+
+The source repository, the build parameters and the base images from the Containerfile JSON
+written by image build --containerfile-json-output are recorded in the build definition.
+The predicate is serialized canonically, with sorted keys and without whitespace, so the same
+inputs always produce the same predicate digest.`,
+	Example: `  # Generate provenance of a built image
+  konflux-build-cli image generate-provenance --image-url quay.io/org/app --image-digest sha256:1234... \
+    --git-url https://github.com/org/app --git-commit 0123abc \
+    --build-params CONTAINERFILE=Containerfile --build-params HERMETIC=true \
+    --containerfile-json /tmp/containerfile.json --output /tmp/provenance.json
+
+  # Write also the in-toto statement with the image as the subject
+  konflux-build-cli image generate-provenance --image-url quay.io/org/app --image-digest sha256:1234... \
+    --output /tmp/provenance.json --statement-output /tmp/statement.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting generate-provenance")
+		generateProvenance, err := commands.NewGenerateProvenance(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := generateProvenance.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished generate-provenance")
+	},
+}
+
+func init() {
+	common.RegisterParameters(GenerateProvenanceCmd, commands.GenerateProvenanceParamsConfig)
+	common.RegisterResults(GenerateProvenanceCmd, commands.GenerateProvenanceResults{})
+}
@@ -0,0 +1,42 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var InspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Print normalized metadata of an image or an image index",
+	Long: `Inspects an image in the registry and prints its metadata as JSON.
+
+For an image manifest, the result contains the labels, environment variables, layers,
+os, architecture and creation date of the image. For an image index (manifest list),
+the result contains the manifests of the index and the list of architectures.
+Docker and OCI media types are both supported and reported in the same format.`,
+	Example: `  # Inspect an image by tag
+  konflux-build-cli image inspect --image-url quay.io/org/app:v1
+
+  # Inspect the image built by a previous task
+  konflux-build-cli image inspect --image-url quay.io/org/app:v1 --digest sha256:1234567`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting inspect")
+		inspect, err := commands.NewInspect(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := inspect.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished inspect")
+	},
+}
+
+func init() {
+	common.RegisterParameters(InspectCmd, commands.InspectParamsConfig)
+	common.RegisterResults(InspectCmd, commands.InspectResults{})
+}
@@ -0,0 +1,55 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var LintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check a Containerfile for common problems",
+	Long: `Checks a Containerfile for common problems and prints the findings as JSON.
+
+Rules:
+  required-labels       (warning) the final stage doesn't set the labels required by Konflux
+  latest-base-image     (warning) a base image is referenced by the latest tag or without a tag
+  unpinned-base-image   (error)   a base image is not pinned to a digest, only with --require-pinned-base-images
+  add-instead-of-copy   (info)    ADD is used for local files, COPY is preferred
+  secret-in-env         (error)   ENV sets a variable that looks like a secret
+  secret-in-arg         (warning) ARG sets a variable that looks like a secret
+  unpinned-packages     (warning) dnf/yum/microdnf/apt packages are installed without a version
+
+The Containerfile is expanded with the build arguments before linting.
+The command fails if there's a finding of the --fail-on severity or higher.`,
+	Example: `  # Lint a Containerfile
+  konflux-build-cli image lint --containerfile ./Containerfile
+
+  # Lint with the labels set at build time, fail on warnings too
+  konflux-build-cli image lint -f ./Containerfile --labels name=app --labels version=1.0 --fail-on warning
+
+  # Require the base images to be pinned to digests
+  konflux-build-cli image lint -f ./Containerfile --require-pinned-base-images
+
+  # Report the findings without failing, skipping the package pinning rule
+  konflux-build-cli image lint -f ./Containerfile --disable-rules unpinned-packages --fail-on never`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting lint")
+		lint, err := commands.NewLint(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := lint.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished lint")
+	},
+}
+
+func init() {
+	common.RegisterParameters(LintCmd, commands.LintParamsConfig)
+	common.RegisterResults(LintCmd, commands.LintResults{})
+}
@@ -0,0 +1,41 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var ListArtifactsCmd = &cobra.Command{
+	Use:   "list-artifacts",
+	Short: "Lists the artifacts associated with an image",
+	Long: `Lists the artifacts associated with an image digest as JSON: the OCI 1.1 referrers of the image
+and the artifacts tagged after the image digest, i.e. sha256-<digest>.sig, .att, .sbom, .dockerfile
+and .containerfile.
+
+Each artifact has a kind (sbom, signature, attestation, containerfile, prefetch-output or other),
+derived from the artifact type of a referrer or from the suffix of the tag.
+Only the direct referrers of the image are listed, not the referrers of its artifacts.
+`,
+	Example: `  # List the artifacts of an image
+  konflux-build-cli image list-artifacts --image-url quay.io/org/app:v1`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting list-artifacts")
+		listArtifacts, err := commands.NewListArtifacts(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := listArtifacts.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished list-artifacts")
+	},
+}
+
+func init() {
+	common.RegisterParameters(ListArtifactsCmd, commands.ListArtifactsParamsConfig)
+	common.RegisterResults(ListArtifactsCmd, commands.ListArtifactsResults{})
+}
@@ -0,0 +1,45 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var MergeSBOMCmd = &cobra.Command{
+	Use:   "merge-sbom",
+	Short: "Merge CycloneDX and SPDX SBOMs into one",
+	Long: `Merges multiple SBOMs into one document and writes it to the output path.
+
+The first SBOM describes the image, the packages of the others are added to it.
+Packages with the same purl are included once. SBOMs in the other format are
+converted before merging, converting keeps the packages, their identification
+(name, version, purl, checksums, licenses) and their dependencies.
+
+The merged SBOM is in the --format format, by default the format of the first SBOM.
+The path, digest and format of the merged SBOM are printed as JSON.`,
+	Example: `  # Merge the image SBOM with the SBOM of the prefetched dependencies
+  konflux-build-cli image merge-sbom --sbom ./image.spdx.json --sbom ./prefetch/bom.json --output ./sbom.json
+
+  # Convert a CycloneDX SBOM to SPDX
+  konflux-build-cli image merge-sbom -s ./sbom.cdx.json -o ./sbom.spdx.json --format spdx`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting merge-sbom")
+		mergeSBOM, err := commands.NewMergeSBOM(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := mergeSBOM.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished merge-sbom")
+	},
+}
+
+func init() {
+	common.RegisterParameters(MergeSBOMCmd, commands.MergeSBOMParamsConfig)
+	common.RegisterResults(MergeSBOMCmd, commands.MergeSBOMResults{})
+}
@@ -0,0 +1,46 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var MirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Copies an image and its artifacts from one registry to another",
+	Long: `Copies an image with the images of all its platforms from one registry to another,
+e.g. to promote it from a staging to a production registry.
+
+The source is pinned to the digest it points to when the command starts, and the digests are
+preserved, so the destination gets exactly the same image. With --digest, the command fails
+if the source doesn't point to the expected digest.
+
+With --include-artifacts, the cosign signature, attestation and SBOM tags of the image and its
+OCI referrers are mirrored as well.
+
+The registries may need different credentials, see --src-auth-file and --dest-auth-file.
+`,
+	Example: `  # Promote an image to the production registry
+  konflux-build-cli image mirror --source quay.io/staging/app:v1 --destination registry.example.com/prod/app \
+    --digest sha256:... --include-artifacts --dest-auth-file /var/run/secrets/prod-registry`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting mirror")
+		mirror, err := commands.NewMirror(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := mirror.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished mirror")
+	},
+}
+
+func init() {
+	common.RegisterParameters(MirrorCmd, commands.MirrorParamsConfig)
+	common.RegisterResults(MirrorCmd, commands.MirrorResults{})
+}
@@ -0,0 +1,55 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var OciCopyCmd = &cobra.Command{
+	Use:   "oci-copy",
+	Short: "Download artifacts listed in oci-copy.yaml and push them to registry as an OCI artifact.",
+	Long: `Downloads the artifacts listed in an oci-copy.yaml file and pushes them to
+image registry as the layers of a single OCI artifact.
+
+The oci-copy.yaml file has the following format:
+
+  oci-artifact-type: application/vnd.konflux-ci.attached-artifact
+  artifacts:
+    - source: https://example.com/file.iso
+      filename: file.iso
+      type: application/vnd.konflux-ci.attached-artifact.iso
+      sha256sum: <sha256 checksum of the file>
+
+Each artifact is verified against its sha256sum after download. Files already
+present in --download-dir with the expected checksum are not downloaded again.
+
+The results JSON contains the image_url and digest of the pushed artifact.`,
+	Example: `
+  # Push the artifacts listed in ./oci-copy.yaml to quay.io/org/app:v1
+  konflux-build-cli image oci-copy --output-ref quay.io/org/app:v1
+
+  # Use a custom oci-copy file and authenticate the downloads
+  konflux-build-cli image oci-copy --output-ref quay.io/org/app:v1 \
+    --oci-copy-file source/artifacts.yaml --bearer-token-file /workspace/token/token
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting oci-copy")
+		ociCopy, err := commands.NewOciCopy(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := ociCopy.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished oci-copy")
+	},
+}
+
+func init() {
+	common.RegisterParameters(OciCopyCmd, commands.OciCopyParamsConfig)
+	common.RegisterResults(OciCopyCmd, commands.OciCopyResults{})
+}
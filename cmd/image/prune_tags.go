@@ -0,0 +1,42 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var PruneTagsCmd = &cobra.Command{
+	Use:   "prune-tags",
+	Short: "Deletes the tags matching a pattern from a repository",
+	Long: `Deletes the tags matching a pattern from a repository, e.g. the temporary tags of CI builds.
+
+The tags are selected with a shell glob pattern (see --tag-pattern). With --older-than, only the tags
+of images created longer ago are deleted, the creation time is read from the image config.
+
+Registries delete the manifest the tag points to, not only the tag. Other tags pointing to the same
+manifest, including tags not matching the pattern, may be removed as well.
+With --dry-run, the command only logs the tags it would delete.
+`,
+	Example: `  # Delete the pull request tags older than a week
+  konflux-build-cli image prune-tags --repository quay.io/org/app --tag-pattern 'on-pr-*' --older-than 1w`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting prune-tags")
+		pruneTags, err := commands.NewPruneTags(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := pruneTags.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished prune-tags")
+	},
+}
+
+func init() {
+	common.RegisterParameters(PruneTagsCmd, commands.PruneTagsParamsConfig)
+	common.RegisterResults(PruneTagsCmd, commands.PruneTagsResults{})
+}
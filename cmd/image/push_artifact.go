@@ -0,0 +1,50 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var PushArtifactCmd = &cobra.Command{
+	Use:   "push-artifact",
+	Short: "Push files and directories to registry as an OCI artifact.",
+	Long: `Pushes arbitrary files and directories to image registry as an OCI artifact.
+
+Each file is a layer of the artifact, directories are pushed as tarballs.
+The artifact is either pushed to a tag, given by the --image-url tag, --tag,
+or --tag-suffix which derives the tag from the --subject digest, or, with
+--subject only, attached to the subject image as an OCI 1.1 referrer.`,
+	Example: `
+  # Push report.json as artifact quay.io/org/app:report
+  konflux-build-cli image push-artifact --image-url quay.io/org/app:report \
+    --files report.json:application/json --artifact-type application/vnd.my-org.report
+
+  # Push the docs directory as artifact quay.io/org/app:sha256-1234567.docs with annotations
+  konflux-build-cli image push-artifact --image-url quay.io/org/app --subject sha256:1234567 --tag-suffix .docs \
+    --files docs --artifact-type application/vnd.my-org.docs --annotations org.opencontainers.image.title=docs
+
+  # Attach scan results to quay.io/org/app@sha256:1234567 as a referrer
+  konflux-build-cli image push-artifact --image-url quay.io/org/app --subject sha256:1234567 \
+    --files scan.sarif --artifact-type application/sarif+json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting push-artifact")
+		pushArtifact, err := commands.NewPushArtifact(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := pushArtifact.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished push-artifact")
+	},
+}
+
+func init() {
+	common.RegisterParameters(PushArtifactCmd, commands.PushArtifactParamsConfig)
+	common.RegisterResults(PushArtifactCmd, commands.PushArtifactResults{})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -17,7 +18,12 @@ Containerfile is auto-detected from the source by default. It is searched
 firstly from build context, then the source directory. Dockerfile is supported
 as a fallback. If neither is found command exits as normal without pushing
 anyting. The search is highly customizable with arguments --source, --context
-and --containerfile.`,
+and --containerfile.
+
+For multi-file builds, the files included by a Containerfile.in (#include
+lines) and the files matching --extra-files are pushed along with the
+Containerfile, each as a layer titled by its path relative to the source
+directory.`,
 	Example: `
   # Push source/Containerfile as artifact quay.io/org/app:sha256-1234567.containerfile
   konflux-build-cli image push-containerfile --image-url quay.io/org/app --image-digest sha256:1234567 --source source
@@ -46,15 +52,19 @@ and --containerfile.`,
   konflux-build-cli image push-containerfile --image-url quay.io/org/app --image-digest sha256:1234567 \
     --source /path/to/source --context db --containerfile containerfiles/db \
     --alternative-filename Dockerfile
+
+  # Push the Containerfile with the .dockerignore and the scripts of the build context
+  konflux-build-cli image push-containerfile --image-url quay.io/org/app --image-digest sha256:1234567 \
+    --source source --extra-files .dockerignore --extra-files 'scripts/*'
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		l.Logger.Debug("Starting push-containerfile")
 		pushContainerfile, err := commands.NewPushContainerfile(cmd)
 		if err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		if err := pushContainerfile.Run(); err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		l.Logger.Debug("Finished push-containerfile")
 	},
@@ -62,4 +72,5 @@ and --containerfile.`,
 
 func init() {
 	common.RegisterParameters(PushContainerfileCmd, commands.PushContainerfileParamsConfig)
+	common.RegisterResults(PushContainerfileCmd, commands.PushContainerfileResults{})
 }
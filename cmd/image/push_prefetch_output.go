@@ -0,0 +1,47 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var PushPrefetchOutputCmd = &cobra.Command{
+	Use:   "push-prefetch-output",
+	Short: "Push the output of prefetch-dependencies to registry as an OCI artifact.",
+	Long: `Pushes the output directory of prefetch-dependencies to image registry as an OCI artifact,
+so that hermetic rebuilds and source image builds can use exactly the same dependencies.
+
+The output directory is pushed as a single gzip compressed tarball layer. The tarball is
+reproducible, the same dependencies result in the same tarball. Unless --image-url has a tag,
+the artifact is pushed to a tag derived from the tarball digest: sha256-<digest><tag-suffix>.
+
+The digested reference of the artifact is in the image_ref result.`,
+	Example: `
+  # Push the prefetch output to quay.io/org/app:sha256-<tarball digest>.prefetch
+  konflux-build-cli image push-prefetch-output --image-url quay.io/org/app --output-dir ./prefetch-output
+
+  # Push the prefetch output to quay.io/org/app:deps with the source revision annotation
+  konflux-build-cli image push-prefetch-output --image-url quay.io/org/app:deps --output-dir ./prefetch-output \
+    --annotations org.opencontainers.image.revision=abc123
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting push-prefetch-output")
+		pushPrefetchOutput, err := commands.NewPushPrefetchOutput(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := pushPrefetchOutput.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished push-prefetch-output")
+	},
+}
+
+func init() {
+	common.RegisterParameters(PushPrefetchOutputCmd, commands.PushPrefetchOutputParamsConfig)
+	common.RegisterResults(PushPrefetchOutputCmd, commands.PushPrefetchOutputResults{})
+}
@@ -0,0 +1,49 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var PushSBOMCmd = &cobra.Command{
+	Use:   "push-sbom",
+	Short: "Push the SBOM of an image to registry as an OCI artifact.",
+	Long: `Pushes an SBOM file (CycloneDX or SPDX JSON) to the image repository of the
+binary image it describes.
+
+By default, the SBOM is pushed as an artifact tagged after the digest of the
+binary image, e.g. quay.io/org/app:sha256-1234567.sbom. With --attach, it is
+attached to the binary image as an OCI 1.1 referrer instead, which requires
+a registry that supports the referrers API.
+
+The media type of the SBOM layer is detected from the SBOM format and is also
+used as the artifact type unless --artifact-type is set.`,
+	Example: `
+  # Push sbom.json as artifact quay.io/org/app:sha256-1234567.sbom
+  konflux-build-cli image push-sbom --image-url quay.io/org/app --digest sha256:1234567 --sbom-path sbom.json
+
+  # Attach sbom.json to quay.io/org/app@sha256:1234567 as a referrer
+  konflux-build-cli image push-sbom --image-url quay.io/org/app --digest sha256:1234567 --sbom-path sbom.json \
+    --attach
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting push-sbom")
+		pushSBOM, err := commands.NewPushSBOM(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := pushSBOM.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished push-sbom")
+	},
+}
+
+func init() {
+	common.RegisterParameters(PushSBOMCmd, commands.PushSBOMParamsConfig)
+	common.RegisterResults(PushSBOMCmd, commands.PushSBOMResults{})
+}
@@ -0,0 +1,46 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var SetLabelsCmd = &cobra.Command{
+	Use:   "set-labels",
+	Short: "Add or update labels and annotations of a pushed image",
+	Long: `Adds or updates labels in the config and annotations in the manifest of an image
+that was already pushed, without rebuilding it, and pushes the updated image.
+
+The layers are reused as they are, only the config and the manifest change,
+so the updated image gets a new digest, which is returned in the results.
+By default the updated image is pushed under the tag of --image-url, use --destination to push it elsewhere.
+
+Only the image for the current platform is updated, image indexes are not supported.`,
+	Example: `  # Add a label to quay.io/org/app:v1 and move the v1 tag to the updated image
+  konflux-build-cli image set-labels --image-url quay.io/org/app:v1 --labels release=1
+
+  # Set a label and an annotation, pushing the updated image as quay.io/org/app:v1-released
+  konflux-build-cli image set-labels --image-url quay.io/org/app:v1@sha256:1234567 \
+    --labels release=1 --annotations org.opencontainers.image.url=https://example.com \
+    --destination quay.io/org/app:v1-released`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting set-labels")
+		setLabels, err := commands.NewSetLabels(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := setLabels.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished set-labels")
+	},
+}
+
+func init() {
+	common.RegisterParameters(SetLabelsCmd, commands.SetLabelsParamsConfig)
+	common.RegisterResults(SetLabelsCmd, commands.SetLabelsResults{})
+}
@@ -0,0 +1,45 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var SignImageCmd = &cobra.Command{
+	Use:   "sign-image",
+	Short: "Sign an image with cosign and attach signed attestations",
+	Long: `Signs an image referenced by digest with cosign and pushes the signature to the registry.
+
+The image is signed with the given key, or keyless when no key is given: the signing certificate
+is issued by Fulcio for the OIDC identity token and the signature is recorded in Rekor.
+The Sigstore environment variables, e.g. SIGSTORE_ID_TOKEN or COSIGN_PASSWORD, are passed to cosign.
+
+Optionally, an SBOM and a SLSA provenance predicate are attached to the image as signed attestations.`,
+	Example: `  # Sign an image with a key
+  konflux-build-cli image sign-image --image-url quay.io/org/app --digest sha256:1234... --key /keys/cosign.key
+
+  # Sign keyless with a private Sigstore instance and attach the SBOM and provenance
+  konflux-build-cli image sign-image --image-url quay.io/org/app@sha256:1234... \
+    --fulcio-url https://fulcio.example.com --rekor-url https://rekor.example.com \
+    --identity-token /var/run/sigstore/token --sbom /tmp/sbom.json --provenance /tmp/provenance.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting sign-image")
+		signImage, err := commands.NewSignImage(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := signImage.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished sign-image")
+	},
+}
+
+func init() {
+	common.RegisterParameters(SignImageCmd, commands.SignImageParamsConfig)
+	common.RegisterResults(SignImageCmd, commands.SignImageResults{})
+}
@@ -0,0 +1,43 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var SquashPushCmd = &cobra.Command{
+	Use:   "squash-push",
+	Short: "Flatten an image into a single layer and push it",
+	Long: `Pulls an existing image, squashes all its layers (including those of the base image)
+into a single layer and pushes the result under the original tag with --tag-suffix appended.
+
+The image config, such as entrypoint, environment variables and labels, is preserved.
+Useful for consumers with limits on the number of layers.
+
+Only the image for the current platform is flattened, image indexes are not supported.`,
+	Example: `  # Push a flattened variant of quay.io/org/app:v1 as quay.io/org/app:v1-squashed
+  konflux-build-cli image squash-push --image-url quay.io/org/app:v1
+
+  # Use a custom tag suffix, i.e. push quay.io/org/app:v1-flat
+  konflux-build-cli image squash-push --image-url quay.io/org/app:v1@sha256:1234567 --tag-suffix -flat`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting squash-push")
+		squashPush, err := commands.NewSquashPush(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := squashPush.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished squash-push")
+	},
+}
+
+func init() {
+	common.RegisterParameters(SquashPushCmd, commands.SquashPushParamsConfig)
+	common.RegisterResults(SquashPushCmd, commands.SquashPushResults{})
+}
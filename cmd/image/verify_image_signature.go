@@ -0,0 +1,47 @@
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var VerifyImageSignatureCmd = &cobra.Command{
+	Use:   "verify-image-signature",
+	Short: "Verify the cosign signature and attestations of an image",
+	Long: `Verifies the cosign signature and, optionally, the signed attestations of an image referenced
+by digest, e.g. before tagging or promoting it. The command fails if the verification doesn't pass.
+
+The signatures are verified with the given public key, or keyless: the Fulcio signing certificate
+must be issued for the certificate identity by the certificate OIDC issuer, given exactly or as
+regular expressions. The Sigstore environment variables, e.g. SIGSTORE_REKOR_PUBLIC_KEY, are passed to cosign.
+
+The apply-tags command can run the same signature verification with --require-signature.`,
+	Example: `  # Verify the signature with a public key
+  konflux-build-cli image verify-image-signature --image-url quay.io/org/app --digest sha256:1234... --key /keys/cosign.pub
+
+  # Verify a keyless signature and the SLSA provenance attestation
+  konflux-build-cli image verify-image-signature --image-url quay.io/org/app@sha256:1234... \
+    --certificate-identity-regexp '^https://github.com/org/' \
+    --certificate-oidc-issuer https://token.actions.githubusercontent.com \
+    --attestation-types slsaprovenance1`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting verify-image-signature")
+		verifyImageSignature, err := commands.NewVerifyImageSignature(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := verifyImageSignature.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished verify-image-signature")
+	},
+}
+
+func init() {
+	common.RegisterParameters(VerifyImageSignatureCmd, commands.VerifyImageSignatureParamsConfig)
+	common.RegisterResults(VerifyImageSignatureCmd, commands.VerifyImageSignatureResults{})
+}
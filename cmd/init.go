@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Determine whether the pipeline needs to build the image",
+	Long: `Checks whether the image the pipeline builds already exists in the registry, as the
+first task of a Konflux build pipeline.
+
+The build result is true if the image doesn't exist or --rebuild is set, the pipeline
+then builds it. Otherwise, the build result is false and the image_digest result is the
+digest of the existing image.
+
+The image is checked by the tag of --image-url, or by --digest if set.`,
+	Example: `  # Check whether quay.io/org/app:abc123 exists
+  konflux-build-cli init --image-url quay.io/org/app:abc123
+
+  # Always build, e.g. for a push pipeline run
+  konflux-build-cli init --image-url quay.io/org/app:abc123 --rebuild
+
+  # Write the build and image_digest results for Tekton
+  konflux-build-cli init --image-url quay.io/org/app:abc123 --tekton-results`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting init")
+		initTask, err := commands.NewInit(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := initTask.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished init")
+	},
+}
+
+func init() {
+	common.RegisterParameters(initCmd, commands.InitParamsConfig)
+	common.RegisterResults(initCmd, commands.InitResults{})
+}
@@ -4,7 +4,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
-	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 )
 
 var InUserNamespaceCmd = &cobra.Command{
@@ -20,7 +20,7 @@ non-flag argument (or after --) is passed to the command as-is.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		loopbackUp, _ := cmd.Flags().GetBool("loopback-up")
 		if err := commands.RunInUserNamespace(loopbackUp, args); err != nil {
-			l.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 	},
 }
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+)
+
+var paramsFormat string
+
+var paramsCmd = &cobra.Command{
+	Use:   "params [command]",
+	Short: "Describe the parameters of the commands",
+	Long: `Prints the parameters of a command: their flags, env vars, types, defaults and whether
+they are required, as JSON or as a markdown table. Without a command, describes all the commands,
+as a JSON array or as markdown sections.
+
+The output is meant for tools, e.g. task editors and docs generators.
+Shell completions are generated with the completion command.`,
+	Example: `  # Describe the parameters of image build as JSON
+  konflux-build-cli params image build
+
+  # Generate the markdown reference of all the commands
+  konflux-build-cli params --format md > parameters.md
+
+  # Enable the bash completions
+  source <(konflux-build-cli completion bash)`,
+	ValidArgsFunction: completeCommandPath,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, err := describeParams(args, paramsFormat)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		fmt.Print(output)
+	},
+}
+
+func describeParams(args []string, format string) (string, error) {
+	if format != "json" && format != "md" {
+		return "", kbcerrors.NewValidationError(fmt.Errorf("format must be one of 'json', 'md', got '%s'", format))
+	}
+
+	commands := common.CommandsWithParameters()
+	if len(args) > 0 {
+		command, err := findCommandWithParameters(args)
+		if err != nil {
+			return "", err
+		}
+		commands = []*cobra.Command{command}
+	}
+
+	descriptions := []*common.CommandDescription{}
+	for _, command := range commands {
+		description, err := common.DescribeCommand(command)
+		if err != nil {
+			return "", err
+		}
+		descriptions = append(descriptions, description)
+	}
+
+	if format == "md" {
+		sections := []string{}
+		for _, description := range descriptions {
+			sections = append(sections, description.Markdown())
+		}
+		return strings.Join(sections, "\n"), nil
+	}
+
+	var content []byte
+	var err error
+	if len(args) > 0 {
+		content, err = json.MarshalIndent(descriptions[0], "", "  ")
+	} else {
+		content, err = json.MarshalIndent(descriptions, "", "  ")
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(content) + "\n", nil
+}
+
+// Find the command by its path, given as separate args (image build) or as one ("image build").
+func findCommandWithParameters(args []string) (*cobra.Command, error) {
+	commandPath := strings.Fields(strings.Join(args, " "))
+	command, remainingArgs, err := rootCmd.Find(commandPath)
+	if err != nil || len(remainingArgs) > 0 || command == rootCmd {
+		return nil, kbcerrors.NewValidationError(fmt.Errorf("unknown command '%s'", strings.Join(commandPath, " ")))
+	}
+	if _, ok := common.RegisteredParameters(command); !ok {
+		return nil, kbcerrors.NewValidationError(fmt.Errorf("command '%s' has no parameters", strings.Join(commandPath, " ")))
+	}
+	return command, nil
+}
+
+// Complete the next word of the path of a command with parameters.
+func completeCommandPath(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := strings.Join(args, " ")
+	completions := []string{}
+	for _, command := range common.CommandsWithParameters() {
+		if command.Hidden {
+			continue
+		}
+		path := strings.Fields(command.CommandPath())[1:]
+		if len(path) <= len(args) || strings.Join(path[:len(args)], " ") != prefix || !strings.HasPrefix(path[len(args)], toComplete) {
+			continue
+		}
+		completion := path[len(args)]
+		if len(path) == len(args)+1 {
+			completion += "\t" + command.Short
+		}
+		if !containsCompletion(completions, completion) {
+			completions = append(completions, completion)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func containsCompletion(completions []string, completion string) bool {
+	word, _, _ := strings.Cut(completion, "\t")
+	for _, c := range completions {
+		if w, _, _ := strings.Cut(c, "\t"); w == word {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	paramsCmd.Flags().StringVar(&paramsFormat, "format", "json", "Output format: json or md")
+	_ = paramsCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"json", "md"}, cobra.ShellCompDirectiveNoFileComp))
+}
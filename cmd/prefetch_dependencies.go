@@ -5,6 +5,7 @@ import (
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/commands/prefetch_dependencies"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	"github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -16,10 +17,10 @@ var prefetchDependenciesCmd = &cobra.Command{
 		logger.Logger.Debug("Starting prefetch-dependencies")
 		prefetchDependencies, err := prefetch_dependencies.New(cmd)
 		if err != nil {
-			logger.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		if err := prefetchDependencies.Run(); err != nil {
-			logger.Logger.Fatal(err)
+			kbcerrors.Fatal(err)
 		}
 		logger.Logger.Debug("Finished prefetch-dependencies")
 	},
@@ -27,4 +28,5 @@ var prefetchDependenciesCmd = &cobra.Command{
 
 func init() {
 	common.RegisterParameters(prefetchDependenciesCmd, prefetch_dependencies.ParamsConfig)
+	common.RegisterResults(prefetchDependenciesCmd, prefetch_dependencies.Results{})
 }
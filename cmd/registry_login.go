@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var registryLoginCmd = &cobra.Command{
+	Use:   "registry-login",
+	Short: "Write registry credentials into the auth file used by the other commands",
+	Long: `Writes the credentials of a registry into an auth file, merging them with the credentials
+already there, so that the subsequent commands, and buildah, skopeo and oras, use them.
+This replaces running buildah login in the tasks.
+
+The credentials are given as a username and password, as a token, or as a directory with
+username and password, or token files, e.g. a mounted kubernetes.io/basic-auth secret.
+Mounted kubernetes.io/dockerconfigjson secrets don't need a login, pass them with --auth-file.
+
+With --check, the credentials are verified with the registry first, with a GET /v2/ request.
+Without credentials, --check verifies the credentials already in the auth file.`,
+	Example: `  # Log in with the credentials of a mounted basic-auth secret
+  konflux-build-cli registry-login --registry quay.io --secret-dir /secrets/quay
+
+  # Log in with a robot account token for a single namespace, verifying it first
+  KBC_REGISTRY_LOGIN_TOKEN=... konflux-build-cli registry-login --registry quay.io/org --username org+robot --check
+
+  # Verify the credentials in a specific auth file
+  konflux-build-cli registry-login --registry quay.io --check --target-auth-file /workspace/auth.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting registry-login")
+		registryLogin, err := commands.NewRegistryLogin(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := registryLogin.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished registry-login")
+	},
+}
+
+func init() {
+	common.RegisterParameters(registryLoginCmd, commands.RegistryLoginParamsConfig)
+	common.RegisterResults(registryLoginCmd, commands.RegistryLoginResults{})
+}
@@ -3,11 +3,18 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+	"github.com/konflux-ci/konflux-build-cli/pkg/metrics"
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -22,9 +29,63 @@ func Execute() {
 	processedArgs := common.ExpandArrayParameters(os.Args[1:])
 	rootCmd.SetArgs(processedArgs)
 
+	stopSignalHandling := cliwrappers.HandleTerminationSignals()
 	err := rootCmd.Execute()
+	stopSignalHandling()
+	exitCode := 0
 	if err != nil {
-		os.Exit(1)
+		// Run functions exit on their own, the errors here are about the command line, e.g. unknown flags
+		err = kbcerrors.NewValidationError(err)
+		exitCode = kbcerrors.ExitCode(err)
+	}
+	writeMetrics(exitCode)
+	cleanupRegistryAuthContext()
+	if err != nil {
+		if jsonErr := kbcerrors.WriteJSON(os.Stderr, err); jsonErr != nil {
+			l.Logger.Warnf("Failed to write error JSON: %s", jsonErr)
+		}
+		os.Exit(exitCode)
+	}
+}
+
+// Name of the running command, e.g. "image build", empty until the command line is parsed.
+var commandName string
+
+// Whether to validate the results of the command against its results schema, see --validate-results.
+var validateResults bool
+
+// Write the metrics of the command, if enabled with --metrics-file.
+func writeMetrics(exitCode int) {
+	if err := metrics.Write(commandName, exitCode); err != nil {
+		l.Logger.Warnf("Failed to write metrics: %s", err)
+	}
+}
+
+// Remove the temporary DOCKER_CONFIG with the merged registry credentials, see --auth-file.
+func cleanupRegistryAuthContext() {
+	if err := common.CleanupRegistryAuthContext(); err != nil {
+		l.Logger.Warnf("Failed to clean up registry auth context: %s", err)
+	}
+}
+
+// Where to store the result fields over --results-size-limit.
+func newResultsOverflowStore(dir, repository string) (common.ResultsOverflowStore, error) {
+	switch {
+	case dir != "" && repository != "":
+		return nil, kbcerrors.NewValidationError(fmt.Errorf("--results-overflow-dir and --results-overflow-repository are mutually exclusive"))
+	case dir != "":
+		return common.NewResultsDirStore(dir), nil
+	case repository != "":
+		if !common.IsImageNameValid(repository) {
+			return nil, kbcerrors.NewValidationError(fmt.Errorf("invalid results overflow repository '%s'", repository))
+		}
+		orasCli, err := cliwrappers.NewOrasCli(cliwrappers.NewCliExecutor())
+		if err != nil {
+			return nil, err
+		}
+		return cliwrappers.NewOrasResultsStore(orasCli, repository), nil
+	default:
+		return nil, kbcerrors.NewValidationError(fmt.Errorf("--results-size-limit requires --results-overflow-dir or --results-overflow-repository"))
 	}
 }
 
@@ -32,6 +93,55 @@ func init() {
 	// Common flags for all subcommands
 	var logLevel string
 	rootCmd.PersistentFlags().StringVar(&logLevel, "loglevel", "info", "Set the logging level (debug, info, warn, error, fatal)")
+	var logFormat string
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", l.LogFormatText, "Set the logging format (text, json)")
+
+	var resultFileMode, resultFileGroup string
+	var resultFileFsync bool
+	rootCmd.PersistentFlags().StringVar(&resultFileMode, "result-file-mode", "0644", "Permissions (octal) of the result and artifact files")
+	rootCmd.PersistentFlags().StringVar(&resultFileGroup, "result-file-group", "", "Group name or GID to assign to the result and artifact files")
+	rootCmd.PersistentFlags().BoolVar(&resultFileFsync, "result-file-fsync", false, "Flush the result and artifact files to disk after writing")
+
+	var resultsFile string
+	rootCmd.PersistentFlags().StringVar(&resultsFile, "results-file", "", "In addition to printing the results JSON, write it into this file. "+
+		"The file is replaced atomically, it never contains partial results")
+
+	var tektonResults bool
+	var tektonResultsDir string
+	rootCmd.PersistentFlags().BoolVar(&tektonResults, "tekton-results", false, "In addition to printing the results JSON, write each result field into its own file in --tekton-results-dir")
+	rootCmd.PersistentFlags().StringVar(&tektonResultsDir, "tekton-results-dir", common.DefaultTektonResultsDir, "Directory for the result files written with --tekton-results")
+
+	var resultsSizeLimit int
+	var resultsOverflowDir, resultsOverflowRepository string
+	rootCmd.PersistentFlags().IntVar(&resultsSizeLimit, "results-size-limit", 0, "Maximum size of the results JSON in bytes, 0 for no limit. "+
+		"The largest result fields are stored in --results-overflow-dir or --results-overflow-repository and replaced by {ref,digest,size} records until the JSON fits")
+	rootCmd.PersistentFlags().StringVar(&resultsOverflowDir, "results-overflow-dir", "", "Directory for the result fields over --results-size-limit, written as <field>.json")
+	rootCmd.PersistentFlags().StringVar(&resultsOverflowRepository, "results-overflow-repository", "", "Repository to push the result fields over --results-size-limit into as OCI artifacts with oras")
+
+	var authFiles []string
+	rootCmd.PersistentFlags().StringArrayVar(&authFiles, "auth-file", nil, "Registry auth file (docker config) or a directory containing config.json or .dockerconfigjson. "+
+		"Merged into a temporary DOCKER_CONFIG that exists only while the command runs, along with $DOCKER_CONFIG/config.json (or ~/.docker/config.json) and $REGISTRY_AUTH_FILE. "+
+		"Entries from later files take precedence. Can be repeated. Also accepted as --authfile")
+	// Same spelling as buildah, podman and skopeo
+	rootCmd.SetGlobalNormalizationFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "authfile" {
+			name = "auth-file"
+		}
+		return pflag.NormalizedName(name)
+	})
+
+	var registryRetries int
+	var registryRetryDelay time.Duration
+	rootCmd.PersistentFlags().IntVar(&registryRetries, "registry-retries", 10, "Maximum number of attempts of a registry operation (push, pull, copy, inspect). "+
+		"Authentication and authorization errors are not retried")
+	rootCmd.PersistentFlags().DurationVar(&registryRetryDelay, "registry-retry-delay", 1*time.Second, "Delay after the first failed registry operation attempt, doubled after each next failure")
+
+	rootCmd.PersistentFlags().BoolVar(&validateResults, "validate-results", false, "Validate the results JSON against the results schema of the command before printing or writing it, "+
+		"fail the command if it doesn't match. Enabled by default with --loglevel debug. See the schema command")
+
+	var metricsFile, metricsFormat string
+	rootCmd.PersistentFlags().StringVar(&metricsFile, "metrics-file", "", "Write the durations of the command, its steps and the CLI tools it runs into this file when the command exits")
+	rootCmd.PersistentFlags().StringVar(&metricsFormat, "metrics-format", metrics.FormatJSON, "Format of the --metrics-file (json, prometheus). The prometheus format is for the node exporter textfile collector")
 
 	cobra.OnInitialize(func() {
 		if !rootCmd.Flags().Changed("loglevel") {
@@ -41,16 +151,176 @@ func init() {
 				logLevel = logLevelEnv
 			}
 		}
-		if err := l.InitLogger(logLevel); err != nil {
+		if !rootCmd.Flags().Changed("log-format") {
+			if v := os.Getenv("KBC_LOG_FORMAT"); v != "" {
+				logFormat = v
+			}
+		}
+		if err := l.InitLogger(logLevel, logFormat); err != nil {
 			fmt.Printf("failed to init logger: %s", err.Error())
 			os.Exit(2)
 		}
+
+		// Same as for the log level, flags take precedence over env vars
+		if !rootCmd.Flags().Changed("result-file-mode") {
+			if v := os.Getenv("KBC_RESULT_FILE_MODE"); v != "" {
+				resultFileMode = v
+			}
+		}
+		if !rootCmd.Flags().Changed("result-file-group") {
+			if v := os.Getenv("KBC_RESULT_FILE_GROUP"); v != "" {
+				resultFileGroup = v
+			}
+		}
+		if !rootCmd.Flags().Changed("result-file-fsync") {
+			if v := os.Getenv("KBC_RESULT_FILE_FSYNC"); v != "" {
+				resultFileFsync = v == "true"
+			}
+		}
+		resultFileOptions, err := common.ParseResultFileOptions(resultFileMode, resultFileGroup, resultFileFsync)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		common.SetResultFileOptions(resultFileOptions)
+
+		if !rootCmd.Flags().Changed("results-file") {
+			if v := os.Getenv("KBC_RESULTS_FILE"); v != "" {
+				resultsFile = v
+			}
+		}
+		common.SetResultsFile(resultsFile)
+
+		if !rootCmd.Flags().Changed("tekton-results") {
+			if v := os.Getenv("KBC_TEKTON_RESULTS"); v != "" {
+				tektonResults = v == "true"
+			}
+		}
+		if !rootCmd.Flags().Changed("tekton-results-dir") {
+			if v := os.Getenv("KBC_TEKTON_RESULTS_DIR"); v != "" {
+				tektonResultsDir = v
+			}
+		}
+		if tektonResults {
+			common.SetTektonResultsDir(tektonResultsDir)
+		}
+
+		if !rootCmd.Flags().Changed("results-size-limit") {
+			if v := os.Getenv("KBC_RESULTS_SIZE_LIMIT"); v != "" {
+				limit, err := strconv.Atoi(v)
+				if err != nil {
+					kbcerrors.Fatal(kbcerrors.NewValidationError(fmt.Errorf("invalid KBC_RESULTS_SIZE_LIMIT value '%s': %w", v, err)))
+				}
+				resultsSizeLimit = limit
+			}
+		}
+		if !rootCmd.Flags().Changed("results-overflow-dir") {
+			if v := os.Getenv("KBC_RESULTS_OVERFLOW_DIR"); v != "" {
+				resultsOverflowDir = v
+			}
+		}
+		if !rootCmd.Flags().Changed("results-overflow-repository") {
+			if v := os.Getenv("KBC_RESULTS_OVERFLOW_REPOSITORY"); v != "" {
+				resultsOverflowRepository = v
+			}
+		}
+		if resultsSizeLimit > 0 {
+			store, err := newResultsOverflowStore(resultsOverflowDir, resultsOverflowRepository)
+			if err != nil {
+				kbcerrors.Fatal(err)
+			}
+			common.SetResultsSizeLimit(resultsSizeLimit, store)
+		} else if resultsSizeLimit < 0 {
+			kbcerrors.Fatal(kbcerrors.NewValidationError(fmt.Errorf("invalid results size limit %d", resultsSizeLimit)))
+		}
+
+		if !rootCmd.Flags().Changed("validate-results") {
+			if v := os.Getenv("KBC_VALIDATE_RESULTS"); v != "" {
+				validateResults = v == "true"
+			} else {
+				// Catch results not matching the published schemas during development
+				validateResults = logLevel == "debug"
+			}
+		}
+
+		if !rootCmd.Flags().Changed("registry-retries") {
+			if v := os.Getenv("KBC_REGISTRY_RETRIES"); v != "" {
+				retries, err := strconv.Atoi(v)
+				if err != nil {
+					kbcerrors.Fatal(kbcerrors.NewValidationError(fmt.Errorf("invalid KBC_REGISTRY_RETRIES value '%s': %w", v, err)))
+				}
+				registryRetries = retries
+			}
+		}
+		if !rootCmd.Flags().Changed("registry-retry-delay") {
+			if v := os.Getenv("KBC_REGISTRY_RETRY_DELAY"); v != "" {
+				delay, err := time.ParseDuration(v)
+				if err != nil {
+					kbcerrors.Fatal(kbcerrors.NewValidationError(fmt.Errorf("invalid KBC_REGISTRY_RETRY_DELAY value '%s': %w", v, err)))
+				}
+				registryRetryDelay = delay
+			}
+		}
+		if err := cliwrappers.SetRegistryRetryOptions(registryRetries, registryRetryDelay); err != nil {
+			kbcerrors.Fatal(kbcerrors.NewValidationError(err))
+		}
+
+		if !rootCmd.Flags().Changed("auth-file") {
+			if v := os.Getenv("KBC_AUTH_FILES"); v != "" {
+				authFiles = strings.Split(v, ",")
+			} else if v := os.Getenv("KBC_AUTHFILE"); v != "" {
+				authFiles = []string{v}
+			}
+		}
+		if !rootCmd.Flags().Changed("metrics-file") {
+			if v := os.Getenv("KBC_METRICS_FILE"); v != "" {
+				metricsFile = v
+			}
+		}
+		if !rootCmd.Flags().Changed("metrics-format") {
+			if v := os.Getenv("KBC_METRICS_FORMAT"); v != "" {
+				metricsFormat = v
+			}
+		}
+		if metricsFile != "" {
+			if err := metrics.Enable(metricsFile, metricsFormat); err != nil {
+				kbcerrors.Fatal(kbcerrors.NewValidationError(err))
+			}
+			kbcerrors.RegisterExitHandler(writeMetrics)
+		}
+
+		if err := common.SetupRegistryAuthContext(authFiles); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		// Fatal errors exit without returning to Execute()
+		kbcerrors.RegisterExitHandler(func(int) { cleanupRegistryAuthContext() })
 	})
 
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		commandName = strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")
+		l.SetCommand(commandName)
+
+		if validateResults {
+			if _, ok := common.RegisteredResults(cmd); ok {
+				schema, err := common.ResultsSchema(cmd)
+				if err != nil {
+					kbcerrors.Fatal(err)
+				}
+				common.SetResultsSchema(schema)
+			}
+		}
+	}
+
 	// Add commands
 	rootCmd.AddCommand(imageCmd)
 	rootCmd.AddCommand(prefetchDependenciesCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(internalCmdGroup)
 	rootCmd.AddCommand(gitCloneCmd)
+	rootCmd.AddCommand(registryLoginCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(summaryCmd)
+	rootCmd.AddCommand(paramsCmd)
+	rootCmd.AddCommand(generateTektonTaskCmd)
+	rootCmd.AddCommand(schemaCmd)
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// Run the CLI in a subprocess of the test binary, the failing commands exit the process.
+func TestMain(m *testing.M) {
+	if os.Getenv("KBC_TEST_RUN_CLI") == "true" {
+		os.Args = append([]string{"konflux-build-cli"}, os.Args[1:]...)
+		Execute()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func runCLI(tmpDir string, args ...string) (int, error) {
+	cmd := exec.Command(os.Args[0], args...) //nolint:gosec // the test binary itself
+	cmd.Env = append(os.Environ(), "KBC_TEST_RUN_CLI=true", "TMPDIR="+tmpDir)
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), nil
+	}
+	return 0, err
+}
+
+func TestExecuteCleansUpRegistryAuthContext(t *testing.T) {
+	g := NewWithT(t)
+	authFile := filepath.Join(t.TempDir(), "auth.json")
+	g.Expect(os.WriteFile(authFile, []byte(`{"auths": {}}`), 0600)).To(Succeed())
+	tmpDir := t.TempDir()
+
+	// Fails on the missing required --output-ref
+	exitCode, err := runCLI(tmpDir, "--auth-file", authFile, "image", "build")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(exitCode).To(Equal(2))
+
+	authContextDirs, err := filepath.Glob(filepath.Join(tmpDir, "kbc-docker-config-*"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(authContextDirs).To(BeEmpty())
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema <command>",
+	Short: "Print the JSON schema of the results of a command",
+	Long: `Prints the JSON schema (draft-07) of the results JSON the command prints, generated from
+the definition of its results, so that consumers such as Tekton tasks and the Konflux UI
+can code against a contract.
+
+Fields without a default are always present, fields not in the schema are never printed.
+With --validate-results, the commands check their results against the schema before printing them.`,
+	Example: `  # Print the results schema of image build
+  konflux-build-cli schema image build > image-build-results.schema.json`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeCommandPath,
+	Run: func(cmd *cobra.Command, args []string) {
+		schema, err := resultsSchema(args)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		fmt.Println(string(schema))
+	},
+}
+
+func resultsSchema(args []string) ([]byte, error) {
+	command, err := findCommandWithParameters(args)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := common.RegisteredResults(command); !ok {
+		return nil, kbcerrors.NewValidationError(fmt.Errorf("command '%s' has no results", strings.Join(strings.Fields(strings.Join(args, " ")), " ")))
+	}
+	return common.ResultsSchema(command)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local HTTP API to drive builds",
+	Long: `Runs a long-lived HTTP server that executes image build, image apply-tags and
+prefetch-dependencies jobs, so that a controller or an IDE plugin can drive builds on a
+persistent builder pod without spawning the CLI for every request.
+
+Each job runs as a separate process of this CLI with the given arguments.
+
+API:
+  POST   /jobs            start a job, body: {"command": ["image", "build"], "args": ["--output-ref", "..."]}
+  GET    /jobs            list jobs
+  GET    /jobs/{id}       job status
+  GET    /jobs/{id}/logs  job output, streamed until the job finishes
+  DELETE /jobs/{id}       cancel a running job
+
+All requests must have the 'Authorization: Bearer <token>' header with the token of --token-file,
+and POST /jobs an application/json body. The jobs can only be run with an allowlist of the flags
+of each command, leaving out the flags that run scripts, select registry credentials, mount host
+paths into the build or write files (e.g. --auth-file, --pre-build-script, --volumes, --output),
+and without arguments after '--'. The paths given to the jobs (e.g. --context, --source-dir)
+must be inside the working directory of serve. The 100 most recently finished jobs are kept.
+
+On SIGTERM or SIGINT, the running jobs get SIGTERM and 20s to stop before serve exits.
+Keep the API bound to localhost or a pod-internal address.`,
+	Example: `  konflux-build-cli serve --listen 127.0.0.1:8080 --token-file /var/run/secrets/kbc/token
+
+  curl -X POST localhost:8080/jobs -H "Authorization: Bearer $(cat /var/run/secrets/kbc/token)" \
+    -H "Content-Type: application/json" -d '{"command": ["image", "build"], "args": ["--output-ref", "quay.io/org/app:v1"]}'
+  curl -H "Authorization: Bearer $(cat /var/run/secrets/kbc/token)" localhost:8080/jobs/1/logs`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting serve")
+		serve, err := commands.NewServe(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := serve.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished serve")
+	},
+}
+
+func init() {
+	common.RegisterParameters(serveCmd, commands.ServeParamsConfig)
+}
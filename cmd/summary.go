@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var summaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize the results of a pipeline",
+	Long: `Reads the results JSON files of the pipeline tasks from a directory and prints
+a combined results JSON, with the results of each task under its file name, e.g.
+{"results": {"build": {...}, "apply-tags": {...}}}.
+
+The human-readable summary, listing the results of each task, is logged and can be
+written into a file with --summary-path. The files that are not a JSON object, e.g.
+left empty by a failed task, are skipped.
+
+The results files are typically written by the other commands with --results-file.`,
+	Example: `  # Summarize the results written into /workspace/results
+  konflux-build-cli image build --output-ref quay.io/org/app:v1 --results-file /workspace/results/build.json
+  konflux-build-cli image apply-tags ... --results-file /workspace/results/apply-tags.json
+  konflux-build-cli summary --results-dir /workspace/results --summary-path /workspace/summary.txt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		l.Logger.Debug("Starting summary")
+		summary, err := commands.NewSummary(cmd)
+		if err != nil {
+			kbcerrors.Fatal(err)
+		}
+		if err := summary.Run(); err != nil {
+			kbcerrors.Fatal(err)
+		}
+		l.Logger.Debug("Finished summary")
+	},
+}
+
+func init() {
+	common.RegisterParameters(summaryCmd, commands.SummaryParamsConfig)
+	common.RegisterResults(summaryCmd, commands.SummaryResults{})
+}
@@ -42,7 +42,7 @@ func init() {
 	if logLevelEnv != "" {
 		logLevel = logLevelEnv
 	}
-	if err := l.InitLogger(logLevel); err != nil {
+	if err := l.InitLogger(logLevel, os.Getenv("KBC_LOG_FORMAT")); err != nil {
 		fmt.Printf("failed to init logger: %s", err.Error())
 		os.Exit(2)
 	}
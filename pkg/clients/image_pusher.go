@@ -0,0 +1,192 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"go.podman.io/image/v5/copy"
+	_ "go.podman.io/image/v5/directory"
+	_ "go.podman.io/image/v5/docker"
+	"go.podman.io/image/v5/manifest"
+	_ "go.podman.io/image/v5/oci/archive"
+	_ "go.podman.io/image/v5/oci/layout"
+	"go.podman.io/image/v5/signature"
+	"go.podman.io/image/v5/transports"
+	"go.podman.io/image/v5/types"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var pushLog = l.Logger.WithField("logger", "ImagePusher")
+
+type ImagePusherInterface interface {
+	// Push the image, return the digest of the pushed manifest.
+	Push(args *ImagePushArgs) (string, error)
+}
+
+var _ ImagePusherInterface = &ImagePusher{}
+
+// ImagePusher copies images with containers/image in-process, without running buildah or skopeo.
+// The supported transports are docker://, oci:, oci-archive: and dir:, not containers-storage:.
+type ImagePusher struct{}
+
+func NewImagePusher() *ImagePusher {
+	return &ImagePusher{}
+}
+
+type ImagePushArgs struct {
+	// Image to push, with the transport, e.g. oci-archive:/path/image.tar or oci:/path/layout
+	Source string
+	// Where to push the image, with the transport, e.g. docker://quay.io/org/app:v1
+	Destination string
+	// Verify the TLS certificates of the destination registry, defaults to true
+	TLSVerify *bool
+	// Directory with the certificates for accessing the destination registry, as buildah --cert-dir
+	CertDir string
+	// Registry auth file, REGISTRY_AUTH_FILE or the default auth files apply if empty
+	AuthFile string
+	// Manifest format to push: oci or docker, the format of the source is kept if empty
+	Format string
+	// Stop each push attempt if it takes longer, no timeout if zero
+	Timeout time.Duration
+}
+
+// Parse a transport:reference image name, e.g. docker://quay.io/org/app:v1.
+func parseImageName(name string) (types.ImageReference, error) {
+	transportName, reference, found := strings.Cut(name, ":")
+	if !found {
+		return nil, fmt.Errorf("image '%s' has no transport, e.g. docker://", name)
+	}
+	transport := transports.Get(transportName)
+	if transport == nil {
+		return nil, fmt.Errorf("image '%s' has unsupported transport '%s'", name, transportName)
+	}
+	return transport.ParseReference(reference)
+}
+
+func manifestMIMEType(format string) (string, error) {
+	switch format {
+	case "":
+		return "", nil
+	case "oci":
+		return imgspecv1.MediaTypeImageManifest, nil
+	case "docker":
+		return manifest.DockerV2Schema2MediaType, nil
+	default:
+		return "", fmt.Errorf("unknown manifest format '%s', expected oci or docker", format)
+	}
+}
+
+// Push copies the source image, or all the images of a source image index, to the destination.
+// Progress is logged per blob. Failed attempts are retried as registry CLI calls are.
+func (p *ImagePusher) Push(args *ImagePushArgs) (string, error) {
+	if args.Source == "" {
+		return "", errors.New("source is empty")
+	}
+	if args.Destination == "" {
+		return "", errors.New("destination is empty")
+	}
+	srcRef, err := parseImageName(args.Source)
+	if err != nil {
+		return "", err
+	}
+	destRef, err := parseImageName(args.Destination)
+	if err != nil {
+		return "", err
+	}
+	mimeType, err := manifestMIMEType(args.Format)
+	if err != nil {
+		return "", err
+	}
+
+	// The source is a local output of the build, there are no signatures to verify
+	policyContext, err := signature.NewPolicyContext(&signature.Policy{
+		Default: []signature.PolicyRequirement{signature.NewPRInsecureAcceptAnything()},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = policyContext.Destroy() }()
+
+	destContext := &types.SystemContext{
+		DockerCertPath: args.CertDir,
+		AuthFilePath:   args.AuthFile,
+	}
+	if args.TLSVerify != nil {
+		destContext.DockerInsecureSkipTLSVerify = types.NewOptionalBool(!*args.TLSVerify)
+	}
+
+	pushLog.Debugf("Copying %s to %s", args.Source, args.Destination)
+
+	var manifestBytes []byte
+	retryer := cliWrappers.NewRetryer(func() (string, string, int, error) {
+		ctx := context.Background()
+		if args.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, args.Timeout)
+			defer cancel()
+		}
+
+		var err error
+		manifestBytes, err = copyImage(ctx, policyContext, destRef, srcRef, &copy.Options{
+			DestinationCtx:        destContext,
+			ForceManifestMIMEType: mimeType,
+			ImageListSelection:    copy.CopyAllImages,
+		})
+		if err != nil {
+			// The retryer matches the registry errors in the output
+			return "", err.Error(), 1, err
+		}
+		return "", "", 0, nil
+	}).WithImageRegistryPreset()
+
+	if _, _, _, err := retryer.Run(); err != nil {
+		pushLog.Errorf("pushing %s failed: %s", args.Destination, err.Error())
+		return "", err
+	}
+
+	digest, err := manifest.Digest(manifestBytes)
+	if err != nil {
+		return "", fmt.Errorf("computing the digest of the pushed manifest: %w", err)
+	}
+	pushLog.Debugf("Pushed %s with digest %s", args.Destination, digest)
+	return digest.String(), nil
+}
+
+// Copy the image with the progress of the blobs logged. Returns the copied manifest.
+func copyImage(ctx context.Context, policyContext *signature.PolicyContext, destRef, srcRef types.ImageReference, options *copy.Options) ([]byte, error) {
+	progress := make(chan types.ProgressProperties)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for p := range progress {
+			logProgress(p)
+		}
+	}()
+	options.Progress = progress
+	options.ProgressInterval = 5 * time.Second
+
+	manifestBytes, err := copy.Image(ctx, policyContext, destRef, srcRef, options)
+	close(progress)
+	<-done
+	return manifestBytes, err
+}
+
+func logProgress(p types.ProgressProperties) {
+	blobLog := pushLog.WithField("blob", p.Artifact.Digest.String())
+	switch p.Event {
+	case types.ProgressEventNewArtifact:
+		blobLog.WithField("size", p.Artifact.Size).Info("Copying blob")
+	case types.ProgressEventRead:
+		blobLog.WithField("offset", p.Offset).WithField("size", p.Artifact.Size).Debug("Copying blob")
+	case types.ProgressEventDone:
+		blobLog.WithField("size", p.Artifact.Size).Info("Copied blob")
+	case types.ProgressEventSkipped:
+		blobLog.Info("Blob already exists, skipped")
+	}
+}
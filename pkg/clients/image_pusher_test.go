@@ -0,0 +1,156 @@
+package clients
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+// Write an OCI layout with a single image of one layer to dir, returns the digest of the manifest.
+func writeTestLayout(t *testing.T, dir string) digest.Digest {
+	g := NewWithT(t)
+
+	writeBlob := func(content []byte) digest.Digest {
+		d := digest.FromBytes(content)
+		blobDir := filepath.Join(dir, "blobs", d.Algorithm().String())
+		g.Expect(os.MkdirAll(blobDir, 0755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(blobDir, d.Encoded()), content, 0644)).To(Succeed())
+		return d
+	}
+	mustMarshal := func(v any) []byte {
+		content, err := json.Marshal(v)
+		g.Expect(err).ToNot(HaveOccurred())
+		return content
+	}
+
+	var layerTar bytes.Buffer
+	tw := tar.NewWriter(&layerTar)
+	content := []byte("hello\n")
+	g.Expect(tw.WriteHeader(&tar.Header{Name: "hello.txt", Mode: 0644, Size: int64(len(content))})).To(Succeed())
+	_, err := tw.Write(content)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(tw.Close()).To(Succeed())
+	var layerGzip bytes.Buffer
+	gw := gzip.NewWriter(&layerGzip)
+	_, err = gw.Write(layerTar.Bytes())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(gw.Close()).To(Succeed())
+
+	layer := layerGzip.Bytes()
+	config := mustMarshal(imgspecv1.Image{
+		Platform: imgspecv1.Platform{Architecture: "amd64", OS: "linux"},
+		RootFS:   imgspecv1.RootFS{Type: "layers", DiffIDs: []digest.Digest{digest.FromBytes(layerTar.Bytes())}},
+	})
+	manifest := mustMarshal(imgspecv1.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: imgspecv1.MediaTypeImageManifest,
+		Config:    imgspecv1.Descriptor{MediaType: imgspecv1.MediaTypeImageConfig, Digest: writeBlob(config), Size: int64(len(config))},
+		Layers:    []imgspecv1.Descriptor{{MediaType: imgspecv1.MediaTypeImageLayerGzip, Digest: writeBlob(layer), Size: int64(len(layer))}},
+	})
+	manifestDigest := writeBlob(manifest)
+
+	index := imgspecv1.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: imgspecv1.MediaTypeImageIndex,
+		Manifests: []imgspecv1.Descriptor{{MediaType: imgspecv1.MediaTypeImageManifest, Digest: manifestDigest, Size: int64(len(manifest))}},
+	}
+	g.Expect(os.WriteFile(filepath.Join(dir, "index.json"), mustMarshal(index), 0644)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dir, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0644)).To(Succeed())
+	return manifestDigest
+}
+
+// The digest and media type of the single manifest in the OCI layout.
+func readLayoutManifest(t *testing.T, dir string) (digest.Digest, string) {
+	g := NewWithT(t)
+	content, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	g.Expect(err).ToNot(HaveOccurred())
+	var index imgspecv1.Index
+	g.Expect(json.Unmarshal(content, &index)).To(Succeed())
+	g.Expect(index.Manifests).To(HaveLen(1))
+	return index.Manifests[0].Digest, index.Manifests[0].MediaType
+}
+
+func disableRetryer(t *testing.T) {
+	cliWrappers.DisableRetryer = true
+	t.Cleanup(func() { cliWrappers.DisableRetryer = false })
+}
+
+func TestImagePusher_Push(t *testing.T) {
+	disableRetryer(t)
+
+	t.Run("should push the image and return the digest of the pushed manifest", func(t *testing.T) {
+		g := NewWithT(t)
+		srcDir := t.TempDir()
+		destDir := t.TempDir()
+		srcDigest := writeTestLayout(t, srcDir)
+
+		pushedDigest, err := NewImagePusher().Push(&ImagePushArgs{
+			Source:      "oci:" + srcDir,
+			Destination: "oci:" + destDir + ":v1",
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		destDigest, _ := readLayoutManifest(t, destDir)
+		g.Expect(pushedDigest).To(Equal(destDigest.String()))
+		g.Expect(pushedDigest).To(Equal(srcDigest.String()))
+	})
+
+	t.Run("should convert the manifest to the format", func(t *testing.T) {
+		g := NewWithT(t)
+		srcDir := t.TempDir()
+		destDir := t.TempDir()
+		srcDigest := writeTestLayout(t, srcDir)
+
+		pushedDigest, err := NewImagePusher().Push(&ImagePushArgs{
+			Source:      "oci:" + srcDir,
+			Destination: "dir:" + destDir,
+			Format:      "docker",
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(pushedDigest).ToNot(Equal(srcDigest.String()))
+		manifest, err := os.ReadFile(filepath.Join(destDir, "manifest.json"))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(pushedDigest).To(Equal(digest.FromBytes(manifest).String()))
+		g.Expect(string(manifest)).To(ContainSubstring("application/vnd.docker.distribution.manifest.v2+json"))
+	})
+
+	t.Run("should error on invalid arguments", func(t *testing.T) {
+		g := NewWithT(t)
+		pusher := NewImagePusher()
+
+		_, err := pusher.Push(&ImagePushArgs{Destination: "docker://quay.io/org/app:v1"})
+		g.Expect(err).To(MatchError("source is empty"))
+
+		_, err = pusher.Push(&ImagePushArgs{Source: "/tmp/layout", Destination: "docker://quay.io/org/app:v1"})
+		g.Expect(err).To(MatchError("image '/tmp/layout' has no transport, e.g. docker://"))
+
+		_, err = pusher.Push(&ImagePushArgs{Source: "containers-storage:quay.io/org/app:v1", Destination: "docker://quay.io/org/app:v1"})
+		g.Expect(err).To(MatchError(ContainSubstring("unsupported transport 'containers-storage'")))
+
+		_, err = pusher.Push(&ImagePushArgs{Source: "oci:/tmp/layout", Destination: "docker://quay.io/org/app:v1", Format: "v2s1"})
+		g.Expect(err).To(MatchError("unknown manifest format 'v2s1', expected oci or docker"))
+	})
+
+	t.Run("should error if the source doesn't exist", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := NewImagePusher().Push(&ImagePushArgs{
+			Source:      "oci:" + filepath.Join(t.TempDir(), "missing"),
+			Destination: "oci:" + t.TempDir() + ":v1",
+		})
+
+		g.Expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,55 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.podman.io/image/v5/docker"
+	"go.podman.io/image/v5/types"
+)
+
+type RegistryAuthCheckerInterface interface {
+	// CheckAuth returns an error if the registry rejects the credentials.
+	CheckAuth(args *RegistryAuthCheckArgs) error
+}
+
+var _ RegistryAuthCheckerInterface = &RegistryAuthChecker{}
+
+// RegistryAuthChecker verifies registry credentials with a GET /v2/ request, as podman login does.
+type RegistryAuthChecker struct{}
+
+func NewRegistryAuthChecker() *RegistryAuthChecker {
+	return &RegistryAuthChecker{}
+}
+
+type RegistryAuthCheckArgs struct {
+	// Registry host, e.g. quay.io, without a namespace
+	Registry string
+	Username string
+	Password string
+	// Verify the TLS certificates of the registry, defaults to true
+	TLSVerify *bool
+	// Directory with the certificates for accessing the registry, as buildah --cert-dir
+	CertDir string
+	// Stop the check if it takes longer, no timeout if zero
+	Timeout time.Duration
+}
+
+func (c *RegistryAuthChecker) CheckAuth(args *RegistryAuthCheckArgs) error {
+	sys := &types.SystemContext{DockerCertPath: args.CertDir}
+	if args.TLSVerify != nil {
+		sys.DockerInsecureSkipTLSVerify = types.NewOptionalBool(!*args.TLSVerify)
+	}
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if args.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, args.Timeout)
+	}
+	defer cancel()
+
+	if err := docker.CheckAuth(ctx, sys, args.Username, args.Password, args.Registry); err != nil {
+		return fmt.Errorf("checking credentials for %s: %w", args.Registry, err)
+	}
+	return nil
+}
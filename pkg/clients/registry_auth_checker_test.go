@@ -0,0 +1,57 @@
+package clients
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_RegistryAuthChecker_CheckAuth(t *testing.T) {
+	registry := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "https://")
+	tlsVerify := false
+
+	t.Run("should accept valid credentials", func(t *testing.T) {
+		g := NewWithT(t)
+
+		err := NewRegistryAuthChecker().CheckAuth(&RegistryAuthCheckArgs{
+			Registry: host, Username: "user", Password: "secret", TLSVerify: &tlsVerify, Timeout: time.Minute,
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("should reject invalid credentials", func(t *testing.T) {
+		g := NewWithT(t)
+
+		err := NewRegistryAuthChecker().CheckAuth(&RegistryAuthCheckArgs{
+			Registry: host, Username: "user", Password: "wrong", TLSVerify: &tlsVerify, Timeout: time.Minute,
+		})
+
+		g.Expect(err).To(MatchError(ContainSubstring("checking credentials for " + host)))
+	})
+
+	t.Run("should verify the certificate by default", func(t *testing.T) {
+		g := NewWithT(t)
+
+		err := NewRegistryAuthChecker().CheckAuth(&RegistryAuthCheckArgs{Registry: host, Username: "user", Password: "secret"})
+
+		g.Expect(err).To(MatchError(ContainSubstring("certificate")))
+	})
+}
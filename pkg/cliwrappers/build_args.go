@@ -0,0 +1,39 @@
+package cliwrappers
+
+import (
+	"os"
+	"strings"
+)
+
+// ApplyBuildArgs sets the --build-arg values in values the way buildah does. A KEY=VALUE arg sets
+// the build arg, a KEY arg takes the value of the KEY environment variable, or unsets the build arg
+// if the variable isn't set. The args are applied in order, over the --build-arg-file values.
+func ApplyBuildArgs(values map[string]string, buildArgs []string) {
+	for _, buildArg := range buildArgs {
+		key, value, hasValue := strings.Cut(buildArg, "=")
+		if !hasValue {
+			value, hasValue = os.LookupEnv(key)
+		}
+		if hasValue {
+			values[key] = value
+		} else {
+			delete(values, key)
+		}
+	}
+}
+
+// Resolve the KEY build args to KEY=VALUE from the environment of this process, as ApplyBuildArgs does.
+// Otherwise the build tool looks the values up in its own environment, which is not this one
+// when it runs on a remote host. The KEY args of unset variables are kept to unset the build args.
+func resolveBuildArgs(buildArgs []string) []string {
+	resolved := make([]string, 0, len(buildArgs))
+	for _, buildArg := range buildArgs {
+		if !strings.Contains(buildArg, "=") {
+			if value, ok := os.LookupEnv(buildArg); ok {
+				buildArg += "=" + value
+			}
+		}
+		resolved = append(resolved, buildArg)
+	}
+	return resolved
+}
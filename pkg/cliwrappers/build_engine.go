@@ -0,0 +1,54 @@
+package cliwrappers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// BuildEngine builds images and moves them between the local image store and the registries.
+// BuildahCli is the default engine, PodmanCli and DockerCli (docker buildx) support a subset
+// of the build options, see their Build methods.
+type BuildEngine interface {
+	Build(args *BuildahBuildArgs) error
+	// Push the image, return the digest of the pushed manifest.
+	Push(args *BuildahPushArgs) (string, error)
+	Pull(args *BuildahPullArgs) error
+	InspectImage(name string) (BuildahImageInfo, error)
+}
+
+var (
+	_ BuildEngine = &BuildahCli{}
+	_ BuildEngine = &PodmanCli{}
+	_ BuildEngine = &DockerCli{}
+)
+
+// The subset of the 'podman image inspect' and 'docker image inspect' output of an image
+// that maps to the OCI image config.
+type imageInspectEntry struct {
+	Architecture string
+	Os           string
+	Variant      string
+	Config       ociv1.ImageConfig
+}
+
+// Parse the output of 'podman image inspect' or 'docker image inspect' of a single image
+// into the BuildahImageInfo buildah inspect returns.
+func parseImageInspect(output string) (BuildahImageInfo, error) {
+	var entries []imageInspectEntry
+	if err := json.Unmarshal([]byte(output), &entries); err != nil {
+		return BuildahImageInfo{}, fmt.Errorf("parsing inspect output: %w", err)
+	}
+	if len(entries) != 1 {
+		return BuildahImageInfo{}, fmt.Errorf("expected one image in inspect output, found %d", len(entries))
+	}
+
+	entry := entries[0]
+	return BuildahImageInfo{
+		OCIv1: ociv1.Image{
+			Platform: ociv1.Platform{Architecture: entry.Architecture, OS: entry.Os, Variant: entry.Variant},
+			Config:   entry.Config,
+		},
+	}, nil
+}
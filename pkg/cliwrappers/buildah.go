@@ -9,9 +9,11 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
 
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
@@ -29,17 +31,25 @@ type BuildahCliInterface interface {
 	Version() (BuildahVersionInfo, error)
 	ManifestCreate(args *BuildahManifestCreateArgs) error
 	ManifestAdd(args *BuildahManifestAddArgs) error
+	ManifestAnnotate(args *BuildahManifestAnnotateArgs) error
 	ManifestInspect(args *BuildahManifestInspectArgs) (string, error)
 	ManifestPush(args *BuildahManifestPushArgs) (string, error)
+	ManifestPushWithDigests(args *BuildahManifestPushArgs) (*BuildahManifestPushResult, error)
 	From(image string) (string, error)
 	Rm(container string) error
+	Rmi(image string) error
+	Tag(image string, names ...string) error
 	Mount(container string) (string, error)
+	Commit(args *BuildahCommitArgs) error
+	Config(args *BuildahConfigArgs) error
 }
 
 var _ BuildahCliInterface = &BuildahCli{}
 
 type BuildahCli struct {
 	Executor CliExecutorInterface
+	// Storage root directory (buildah --root), the default from storage.conf if empty
+	Root string
 }
 
 func NewBuildahCli(executor CliExecutorInterface) (*BuildahCli, error) {
@@ -48,7 +58,7 @@ func NewBuildahCli(executor CliExecutorInterface) (*BuildahCli, error) {
 		return nil, err
 	}
 	if !buildahCliAvailable {
-		return nil, errors.New("buildah CLI is not available")
+		return nil, &kbcerrors.ToolMissingError{Tool: "buildah"}
 	}
 
 	return &BuildahCli{
@@ -56,6 +66,14 @@ func NewBuildahCli(executor CliExecutorInterface) (*BuildahCli, error) {
 	}, nil
 }
 
+// Prepend the global options of the buildah CLI to the args of a subcommand.
+func (b *BuildahCli) withGlobalArgs(buildahArgs []string) []string {
+	if b.Root == "" {
+		return buildahArgs
+	}
+	return append([]string{"--root", b.Root}, buildahArgs...)
+}
+
 type BuildahBuildArgs struct {
 	Containerfile    string
 	ContextDir       string
@@ -76,18 +94,41 @@ type BuildahBuildArgs struct {
 	Target           string
 	SkipUnusedStages *bool
 	TLSVerify        *bool
-	Squash           bool
-	OmitHistory      bool
-	NoCache          bool
-	SecurityOpts     []string
-	CapAdd           []string
-	CapDrop          []string
-	Devices          []string
-	Ulimits          []string
-	SaveStages       bool
-	StageLabels      bool
-	ExtraArgs        []string
-	Wrapper          *WrapperCmd
+	// Directory with the certificates for accessing the registries, see buildah --cert-dir
+	CertDir string
+	// Registry auth file, REGISTRY_AUTH_FILE or the default auth file of buildah applies if empty
+	AuthFile    string
+	Squash      bool
+	OmitHistory bool
+	NoCache     bool
+	// Cache intermediate images (layers), needed by CacheFrom and CacheTo
+	Layers bool
+	// Repositories to look up cached layers in and to push the cached layers to
+	CacheFrom    []string
+	CacheTo      []string
+	SecurityOpts []string
+	CapAdd       []string
+	CapDrop      []string
+	Devices      []string
+	Ulimits      []string
+	SaveStages   bool
+	StageLabels  bool
+	// chroot, oci or rootless, buildah's default (BUILDAH_ISOLATION) applies if empty
+	Isolation string
+	// User namespace of the RUN instructions: private, host or the path of a namespace
+	Userns string
+	// Image manifest format: oci or docker. Buildah's default applies if empty.
+	Format string
+	// Platforms to build for (os/arch[/variant]), the host platform if empty
+	Platforms []string
+	// Ignore file applied to the context instead of its .containerignore/.dockerignore, see buildah --ignorefile
+	IgnoreFile string
+	// Add the built images to this manifest list, instead of (or in addition to) tagging them
+	Manifest  string
+	ExtraArgs []string
+	Wrapper   *WrapperCmd
+	// Stop the build if it takes longer, no timeout if zero
+	Timeout time.Duration
 }
 
 type BuildahSecret struct {
@@ -126,7 +167,7 @@ func (args *BuildahBuildArgs) Validate() error {
 	if args.ContextDir == "" {
 		return errors.New("context directory is empty")
 	}
-	if len(args.Tags) == 0 {
+	if len(args.Tags) == 0 && args.Manifest == "" {
 		return errors.New("tags are empty")
 	}
 	for _, mount := range args.Mounts {
@@ -203,19 +244,38 @@ func (args *BuildahBuildArgs) MakePathsAbsolute(baseDir string) error {
 		}
 	}
 
-	return nil
-}
+	if args.IgnoreFile != "" {
+		err = ensureAbsolute(&args.IgnoreFile)
+		if err != nil {
+			return err
+		}
+	}
 
-func (b *BuildahCli) Build(args *BuildahBuildArgs) error {
-	if err := args.Validate(); err != nil {
-		return fmt.Errorf("validating buildah args: %w", err)
+	if args.AuthFile != "" {
+		err = ensureAbsolute(&args.AuthFile)
+		if err != nil {
+			return err
+		}
 	}
 
+	return nil
+}
+
+// The arguments of the build subcommand, shared by buildah and podman, which accept the same options.
+func (args *BuildahBuildArgs) buildCommandArgs() []string {
 	buildahArgs := []string{"build", "--file", args.Containerfile}
 	for _, tag := range args.Tags {
 		buildahArgs = append(buildahArgs, "--tag", tag)
 	}
 
+	if args.Manifest != "" {
+		buildahArgs = append(buildahArgs, "--manifest", args.Manifest)
+	}
+
+	if len(args.Platforms) > 0 {
+		buildahArgs = append(buildahArgs, "--platform="+strings.Join(args.Platforms, ","))
+	}
+
 	for _, secret := range args.Secrets {
 		secretArg := "src=" + secret.Src + ",id=" + secret.Id
 		buildahArgs = append(buildahArgs, "--secret="+secretArg)
@@ -237,7 +297,7 @@ func (b *BuildahCli) Build(args *BuildahBuildArgs) error {
 		buildahArgs = append(buildahArgs, "--build-context="+buildcontext.Name+"="+buildcontext.Location)
 	}
 
-	for _, buildArg := range args.BuildArgs {
+	for _, buildArg := range resolveBuildArgs(args.BuildArgs) {
 		buildahArgs = append(buildahArgs, "--build-arg="+buildArg)
 	}
 
@@ -245,6 +305,10 @@ func (b *BuildahCli) Build(args *BuildahBuildArgs) error {
 		buildahArgs = append(buildahArgs, "--build-arg-file="+args.BuildArgsFile)
 	}
 
+	if args.IgnoreFile != "" {
+		buildahArgs = append(buildahArgs, "--ignorefile="+args.IgnoreFile)
+	}
+
 	for _, env := range args.Envs {
 		buildahArgs = append(buildahArgs, "--env="+env)
 	}
@@ -281,6 +345,14 @@ func (b *BuildahCli) Build(args *BuildahBuildArgs) error {
 		buildahArgs = append(buildahArgs, fmt.Sprintf("--tls-verify=%t", *args.TLSVerify))
 	}
 
+	if args.CertDir != "" {
+		buildahArgs = append(buildahArgs, "--cert-dir="+args.CertDir)
+	}
+
+	if args.AuthFile != "" {
+		buildahArgs = append(buildahArgs, "--authfile="+args.AuthFile)
+	}
+
 	if args.Squash {
 		buildahArgs = append(buildahArgs, "--squash")
 	}
@@ -293,10 +365,30 @@ func (b *BuildahCli) Build(args *BuildahBuildArgs) error {
 		buildahArgs = append(buildahArgs, "--no-cache")
 	}
 
+	if args.Layers {
+		buildahArgs = append(buildahArgs, "--layers")
+	}
+
+	for _, cacheFrom := range args.CacheFrom {
+		buildahArgs = append(buildahArgs, "--cache-from="+cacheFrom)
+	}
+
+	for _, cacheTo := range args.CacheTo {
+		buildahArgs = append(buildahArgs, "--cache-to="+cacheTo)
+	}
+
 	for _, opt := range args.SecurityOpts {
 		buildahArgs = append(buildahArgs, "--security-opt="+opt)
 	}
 
+	if args.Isolation != "" {
+		buildahArgs = append(buildahArgs, "--isolation="+args.Isolation)
+	}
+
+	if args.Userns != "" {
+		buildahArgs = append(buildahArgs, "--userns="+args.Userns)
+	}
+
 	for _, capability := range args.CapAdd {
 		buildahArgs = append(buildahArgs, "--cap-add="+capability)
 	}
@@ -321,11 +413,24 @@ func (b *BuildahCli) Build(args *BuildahBuildArgs) error {
 		buildahArgs = append(buildahArgs, "--stage-labels")
 	}
 
+	if args.Format != "" {
+		buildahArgs = append(buildahArgs, "--format="+args.Format)
+	}
+
 	// Append extra arguments before the context directory
 	buildahArgs = append(buildahArgs, args.ExtraArgs...)
 	// Context directory must be the last argument
 	buildahArgs = append(buildahArgs, args.ContextDir)
 
+	return buildahArgs
+}
+
+func (b *BuildahCli) Build(args *BuildahBuildArgs) error {
+	if err := args.Validate(); err != nil {
+		return fmt.Errorf("validating buildah args: %w", err)
+	}
+
+	buildahArgs := b.withGlobalArgs(args.buildCommandArgs())
 	executable := "buildah"
 	if args.Wrapper != nil {
 		executable, buildahArgs = args.Wrapper.Wrap(executable, buildahArgs)
@@ -333,7 +438,10 @@ func (b *BuildahCli) Build(args *BuildahBuildArgs) error {
 
 	buildahLog.Debugf("Running command:\n%s", shellJoin(executable, buildahArgs...))
 
-	_, _, _, err := b.Executor.Execute(Cmd{
+	ctx, cancel := contextWithTimeout("buildah build", args.Timeout)
+	defer cancel()
+
+	_, _, _, err := b.Executor.ExecuteContext(ctx, Cmd{
 		Name: executable, Args: buildahArgs,
 		// Prefix logs with "buildah" regardless of the wrappers used
 		NameInLogs: "buildah", LogOutput: true,
@@ -352,6 +460,11 @@ type BuildahPushArgs struct {
 	Image       string
 	Destination string
 	TLSVerify   *bool
+	CertDir     string
+	// Manifest format to push: oci or docker. Buildah's default applies if empty.
+	Format string
+	// Stop each push attempt if it takes longer, no timeout if zero
+	Timeout time.Duration
 }
 
 // Push an image from local storage to the registry. Return the digest of the pushed manifest.
@@ -375,18 +488,25 @@ func (b *BuildahCli) Push(args *BuildahPushArgs) (string, error) {
 	if args.TLSVerify != nil {
 		buildahArgs = append(buildahArgs, fmt.Sprintf("--tls-verify=%t", *args.TLSVerify))
 	}
+	if args.CertDir != "" {
+		buildahArgs = append(buildahArgs, "--cert-dir", args.CertDir)
+	}
+	if args.Format != "" {
+		buildahArgs = append(buildahArgs, "--format", args.Format)
+	}
 	buildahArgs = append(buildahArgs, args.Image)
 	if args.Destination != "" {
 		buildahArgs = append(buildahArgs, args.Destination)
 	}
 
+	buildahArgs = b.withGlobalArgs(buildahArgs)
 	buildahLog.Debugf("Running command:\n%s", shellJoin("buildah", buildahArgs...))
 
 	retryer := NewRetryer(func() (string, string, int, error) {
-		return b.Executor.Execute(Cmd{Name: "buildah", Args: buildahArgs, LogOutput: true})
-	}).WithImageRegistryPreset().
-		StopIfOutputContains("unauthorized").
-		StopIfOutputContains("authentication required")
+		ctx, cancel := contextWithTimeout("buildah push", args.Timeout)
+		defer cancel()
+		return b.Executor.ExecuteContext(ctx, Cmd{Name: "buildah", Args: buildahArgs, LogOutput: true})
+	}).WithImageRegistryPreset()
 
 	_, _, _, err = retryer.Run()
 	if err != nil {
@@ -411,6 +531,7 @@ type BuildahPullArgs struct {
 	HttpProxy string // Sets HTTP_PROXY and HTTPS_PROXY for the pull command
 	NoProxy   string // Sets NO_PROXY for the pull command
 	TLSVerify *bool
+	CertDir   string
 	ExtraEnv  []string // Sets extra env vars. Lower precedence than the proxy variables.
 }
 
@@ -427,8 +548,12 @@ func (b *BuildahCli) Pull(args *BuildahPullArgs) error {
 	if args.TLSVerify != nil {
 		buildahArgs = append(buildahArgs, fmt.Sprintf("--tls-verify=%t", *args.TLSVerify))
 	}
+	if args.CertDir != "" {
+		buildahArgs = append(buildahArgs, "--cert-dir", args.CertDir)
+	}
 	buildahArgs = append(buildahArgs, args.Image)
 
+	buildahArgs = b.withGlobalArgs(buildahArgs)
 	buildahLog.Debugf("Running command:\n%s", shellJoin("buildah", buildahArgs...))
 
 	cmd := Cmd{Name: "buildah", Args: buildahArgs, LogOutput: true}
@@ -441,8 +566,6 @@ func (b *BuildahCli) Pull(args *BuildahPullArgs) error {
 	retryer := NewRetryer(func() (string, string, int, error) {
 		return b.Executor.Execute(cmd)
 	}).WithImageRegistryPreset().
-		StopIfOutputContains("unauthorized").
-		StopIfOutputContains("authentication required").
 		StopIfOutputContains("no image found in image index for architecture")
 
 	_, _, _, err := retryer.Run()
@@ -475,6 +598,7 @@ func (b *BuildahCli) Inspect(args *BuildahInspectArgs) (string, error) {
 
 	buildahArgs := []string{"inspect", "--type", args.Type, args.Name}
 
+	buildahArgs = b.withGlobalArgs(buildahArgs)
 	buildahLog.Debugf("Running command:\n%s", shellJoin("buildah", buildahArgs...))
 
 	stdout, stderr, _, err := b.Executor.Execute(Command("buildah", buildahArgs...))
@@ -519,10 +643,14 @@ type BuildahImagesArgs struct {
 	Image string
 	// Whether to pass --json to buildah images.
 	Json bool
+	// Include the intermediate images, e.g. the cached layers of builds with --layers.
+	All bool
 }
 
 // A subset of the JSON output of `buildah images --json`.
 type BuildahImagesEntry struct {
+	// The image ID, i.e. the digest of the image config.
+	ID string `json:"id"`
 	// All the names (including tag) that have been used to pull this image,
 	// resolved to the fully qualified name (includes the registry domain).
 	Names []string `json:"names"`
@@ -532,6 +660,8 @@ type BuildahImagesEntry struct {
 	// or the tag resolves to a manifest, not a manifest list).
 	// Otherwise, this will be the manifest list digest.
 	Digest string `json:"digest"`
+	// Creation time of the image (from the image config), seconds since the Unix epoch.
+	Created int64 `json:"created"`
 }
 
 // List images in local storage, optionally filtering by name.
@@ -541,10 +671,14 @@ func (b *BuildahCli) Images(args *BuildahImagesArgs) (string, error) {
 	if args.Json {
 		buildahArgs = append(buildahArgs, "--json")
 	}
+	if args.All {
+		buildahArgs = append(buildahArgs, "--all")
+	}
 	if args.Image != "" {
 		buildahArgs = append(buildahArgs, args.Image)
 	}
 
+	buildahArgs = b.withGlobalArgs(buildahArgs)
 	buildahLog.Debugf("Running command:\n%s", shellJoin("buildah", buildahArgs...))
 
 	stdout, stderr, _, err := b.Executor.Execute(Command("buildah", buildahArgs...))
@@ -611,6 +745,7 @@ func (v BuildahVersionInfo) ParseVersion() ([]int, error) {
 func (b *BuildahCli) Version() (BuildahVersionInfo, error) {
 	buildahArgs := []string{"version", "--json"}
 
+	buildahArgs = b.withGlobalArgs(buildahArgs)
 	buildahLog.Debugf("Running command:\n%s", shellJoin("buildah", buildahArgs...))
 
 	stdout, stderr, _, err := b.Executor.Execute(Command("buildah", buildahArgs...))
@@ -643,6 +778,7 @@ func (b *BuildahCli) ManifestCreate(args *BuildahManifestCreateArgs) error {
 
 	buildahArgs := []string{"manifest", "create", args.ManifestName}
 
+	buildahArgs = b.withGlobalArgs(buildahArgs)
 	buildahLog.Debugf("Running command:\nbuildah %s", strings.Join(buildahArgs, " "))
 
 	_, _, _, err := b.Executor.Execute(Cmd{Name: "buildah", Args: buildahArgs, LogOutput: true})
@@ -677,6 +813,7 @@ func (b *BuildahCli) ManifestAdd(args *BuildahManifestAddArgs) error {
 		buildahArgs = append(buildahArgs, "--all")
 	}
 
+	buildahArgs = b.withGlobalArgs(buildahArgs)
 	buildahLog.Debugf("Running command:\nbuildah %s", strings.Join(buildahArgs, " "))
 
 	_, _, _, err := b.Executor.Execute(Cmd{Name: "buildah", Args: buildahArgs, LogOutput: true})
@@ -690,8 +827,47 @@ func (b *BuildahCli) ManifestAdd(args *BuildahManifestAddArgs) error {
 	return nil
 }
 
+type BuildahManifestAnnotateArgs struct {
+	ManifestName string
+	// Annotations of the image index in the key=value format.
+	Annotations []string
+}
+
+// ManifestAnnotate sets annotations on the image index of a manifest list
+func (b *BuildahCli) ManifestAnnotate(args *BuildahManifestAnnotateArgs) error {
+	if args.ManifestName == "" {
+		return errors.New("manifest name is empty")
+	}
+	if len(args.Annotations) == 0 {
+		return errors.New("annotations are empty")
+	}
+
+	buildahArgs := []string{"manifest", "annotate", "--index"}
+	for _, annotation := range args.Annotations {
+		buildahArgs = append(buildahArgs, "--annotation", annotation)
+	}
+	buildahArgs = append(buildahArgs, args.ManifestName)
+
+	buildahArgs = b.withGlobalArgs(buildahArgs)
+	buildahLog.Debugf("Running command:\nbuildah %s", strings.Join(buildahArgs, " "))
+
+	_, _, _, err := b.Executor.Execute(Cmd{Name: "buildah", Args: buildahArgs, LogOutput: true})
+	if err != nil {
+		buildahLog.Errorf("buildah manifest annotate failed: %s", err.Error())
+		return err
+	}
+
+	buildahLog.Debug("Manifest annotate completed successfully")
+
+	return nil
+}
+
 type BuildahManifestInspectArgs struct {
+	// A local manifest list, or an image in registry with the docker:// transport
 	ManifestName string
+	// Registry access options, only for images in registry
+	TLSVerify *bool
+	CertDir   string
 }
 
 // ManifestInspect inspects a manifest list and returns the JSON output
@@ -700,11 +876,28 @@ func (b *BuildahCli) ManifestInspect(args *BuildahManifestInspectArgs) (string,
 		return "", errors.New("manifest name is empty")
 	}
 
-	buildahArgs := []string{"manifest", "inspect", args.ManifestName}
+	buildahArgs := []string{"manifest", "inspect"}
+	if args.TLSVerify != nil {
+		buildahArgs = append(buildahArgs, fmt.Sprintf("--tls-verify=%t", *args.TLSVerify))
+	}
+	if args.CertDir != "" {
+		buildahArgs = append(buildahArgs, "--cert-dir", args.CertDir)
+	}
+	buildahArgs = append(buildahArgs, args.ManifestName)
 
+	buildahArgs = b.withGlobalArgs(buildahArgs)
 	buildahLog.Debugf("Running command:\nbuildah %s", strings.Join(buildahArgs, " "))
 
-	stdout, _, _, err := b.Executor.Execute(Command("buildah", buildahArgs...))
+	var stdout string
+	var err error
+	if strings.HasPrefix(args.ManifestName, "docker://") {
+		retryer := NewRetryer(func() (string, string, int, error) {
+			return b.Executor.Execute(Command("buildah", buildahArgs...))
+		}).WithImageRegistryPreset()
+		stdout, _, _, err = retryer.Run()
+	} else {
+		stdout, _, _, err = b.Executor.Execute(Command("buildah", buildahArgs...))
+	}
 	if err != nil {
 		buildahLog.Errorf("buildah manifest inspect failed: %s", err.Error())
 		return "", err
@@ -720,6 +913,9 @@ type BuildahManifestPushArgs struct {
 	Destination  string
 	Format       string
 	TLSVerify    bool
+	CertDir      string
+	// Stop each push attempt if it takes longer, no timeout if zero
+	Timeout time.Duration
 }
 
 // ManifestPush pushes a manifest list to a registry and returns the digest
@@ -753,15 +949,20 @@ func (b *BuildahCli) ManifestPush(args *BuildahManifestPushArgs) (string, error)
 		buildahArgs = append(buildahArgs, "--tls-verify=false")
 	}
 
+	if args.CertDir != "" {
+		buildahArgs = append(buildahArgs, "--cert-dir", args.CertDir)
+	}
+
 	buildahArgs = append(buildahArgs, args.ManifestName, args.Destination)
 
+	buildahArgs = b.withGlobalArgs(buildahArgs)
 	buildahLog.Debugf("Running command:\nbuildah %s", strings.Join(buildahArgs, " "))
 
 	retryer := NewRetryer(func() (string, string, int, error) {
-		return b.Executor.Execute(Cmd{Name: "buildah", Args: buildahArgs, LogOutput: true})
-	}).WithImageRegistryPreset().
-		StopIfOutputContains("unauthorized").
-		StopIfOutputContains("authentication required")
+		ctx, cancel := contextWithTimeout("buildah manifest push", args.Timeout)
+		defer cancel()
+		return b.Executor.ExecuteContext(ctx, Cmd{Name: "buildah", Args: buildahArgs, LogOutput: true})
+	}).WithImageRegistryPreset()
 
 	_, _, _, err = retryer.Run()
 	if err != nil {
@@ -780,6 +981,68 @@ func (b *BuildahCli) ManifestPush(args *BuildahManifestPushArgs) (string, error)
 	return digest, nil
 }
 
+type BuildahManifestPushResult struct {
+	// Digest of the pushed image index (manifest list)
+	IndexDigest string
+	// The per-platform manifests of the pushed image index
+	Manifests []BuildahPlatformManifest
+}
+
+type BuildahPlatformManifest struct {
+	// os/arch[/variant]
+	Platform string
+	Digest   string
+}
+
+// ManifestPushWithDigests pushes a manifest list like ManifestPush, then inspects the pushed image index
+// to return the digests of the per-platform manifests along with the index digest.
+// The registry manifests are inspected, because pushing with a different --format converts them.
+func (b *BuildahCli) ManifestPushWithDigests(args *BuildahManifestPushArgs) (*BuildahManifestPushResult, error) {
+	destination, found := strings.CutPrefix(args.Destination, "docker://")
+	if !found {
+		return nil, fmt.Errorf("destination %s must use the docker:// transport", args.Destination)
+	}
+
+	indexDigest, err := b.ManifestPush(args)
+	if err != nil {
+		return nil, err
+	}
+
+	indexJson, err := b.ManifestInspect(&BuildahManifestInspectArgs{
+		ManifestName: "docker://" + common.GetImageName(destination) + "@" + indexDigest,
+		TLSVerify:    &args.TLSVerify,
+		CertDir:      args.CertDir,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("inspecting pushed image index: %w", err)
+	}
+	manifests, err := parseIndexManifests(indexJson)
+	if err != nil {
+		return nil, err
+	}
+	return &BuildahManifestPushResult{IndexDigest: indexDigest, Manifests: manifests}, nil
+}
+
+// Parse the per-platform manifests of an image index or a Docker manifest list.
+func parseIndexManifests(indexJson string) ([]BuildahPlatformManifest, error) {
+	var index ociv1.Index
+	if err := json.Unmarshal([]byte(indexJson), &index); err != nil {
+		return nil, fmt.Errorf("parsing image index: %w", err)
+	}
+	manifests := []BuildahPlatformManifest{}
+	for _, descriptor := range index.Manifests {
+		platform := ""
+		if descriptor.Platform != nil {
+			platform = descriptor.Platform.OS + "/" + descriptor.Platform.Architecture
+			if descriptor.Platform.Variant != "" {
+				platform += "/" + descriptor.Platform.Variant
+			}
+		}
+		manifests = append(manifests, BuildahPlatformManifest{Platform: platform, Digest: descriptor.Digest.String()})
+	}
+	return manifests, nil
+}
+
 // Create a new working container from an image. Return the container name.
 func (b *BuildahCli) From(image string) (string, error) {
 	if image == "" {
@@ -788,6 +1051,7 @@ func (b *BuildahCli) From(image string) (string, error) {
 
 	buildahArgs := []string{"from", image}
 
+	buildahArgs = b.withGlobalArgs(buildahArgs)
 	buildahLog.Debugf("Running command:\n%s", shellJoin("buildah", buildahArgs...))
 
 	stdout, stderr, _, err := b.Executor.Execute(Command("buildah", buildahArgs...))
@@ -810,6 +1074,7 @@ func (b *BuildahCli) Rm(container string) error {
 
 	buildahArgs := []string{"rm", container}
 
+	buildahArgs = b.withGlobalArgs(buildahArgs)
 	buildahLog.Debugf("Running command:\n%s", shellJoin("buildah", buildahArgs...))
 
 	_, stderr, _, err := b.Executor.Execute(Command("buildah", buildahArgs...))
@@ -824,6 +1089,53 @@ func (b *BuildahCli) Rm(container string) error {
 	return nil
 }
 
+// Remove an image from local storage.
+func (b *BuildahCli) Rmi(image string) error {
+	if image == "" {
+		return errors.New("image is empty")
+	}
+
+	buildahArgs := []string{"rmi", image}
+
+	buildahArgs = b.withGlobalArgs(buildahArgs)
+	buildahLog.Debugf("Running command:\n%s", shellJoin("buildah", buildahArgs...))
+
+	_, stderr, _, err := b.Executor.Execute(Command("buildah", buildahArgs...))
+	if err != nil {
+		if stderr != "" {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Add names to an image in local storage.
+func (b *BuildahCli) Tag(image string, names ...string) error {
+	if image == "" {
+		return errors.New("image is empty")
+	}
+	if len(names) == 0 {
+		return errors.New("names are empty")
+	}
+
+	buildahArgs := append([]string{"tag", image}, names...)
+
+	buildahArgs = b.withGlobalArgs(buildahArgs)
+	buildahLog.Debugf("Running command:\n%s", shellJoin("buildah", buildahArgs...))
+
+	_, stderr, _, err := b.Executor.Execute(Command("buildah", buildahArgs...))
+	if err != nil {
+		if stderr != "" {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr))
+		}
+		return err
+	}
+
+	return nil
+}
+
 // Mount a working container's root filesystem. Return the mount point path.
 func (b *BuildahCli) Mount(container string) (string, error) {
 	if container == "" {
@@ -832,6 +1144,7 @@ func (b *BuildahCli) Mount(container string) (string, error) {
 
 	buildahArgs := []string{"mount", container}
 
+	buildahArgs = b.withGlobalArgs(buildahArgs)
 	buildahLog.Debugf("Running command:\n%s", shellJoin("buildah", buildahArgs...))
 
 	stdout, stderr, _, err := b.Executor.Execute(Command("buildah", buildahArgs...))
@@ -845,3 +1158,83 @@ func (b *BuildahCli) Mount(container string) (string, error) {
 
 	return strings.TrimSpace(stdout), nil
 }
+
+type BuildahCommitArgs struct {
+	Container string
+	Image     string
+	// Squash all the image layers, including those from the base image, into a single layer.
+	Squash bool
+	// Manifest format of the committed image: oci or docker. Buildah's default applies if empty.
+	Format string
+}
+
+// Commit a working container to an image in local storage.
+func (b *BuildahCli) Commit(args *BuildahCommitArgs) error {
+	if args.Container == "" {
+		return errors.New("container is empty")
+	}
+	if args.Image == "" {
+		return errors.New("image is empty")
+	}
+
+	buildahArgs := []string{"commit"}
+	if args.Squash {
+		buildahArgs = append(buildahArgs, "--squash")
+	}
+	if args.Format != "" {
+		buildahArgs = append(buildahArgs, "--format", args.Format)
+	}
+	buildahArgs = append(buildahArgs, args.Container, args.Image)
+
+	buildahArgs = b.withGlobalArgs(buildahArgs)
+	buildahLog.Debugf("Running command:\n%s", shellJoin("buildah", buildahArgs...))
+
+	_, stderr, _, err := b.Executor.Execute(Command("buildah", buildahArgs...))
+	if err != nil {
+		buildahLog.Errorf("buildah commit failed: %s", err.Error())
+		if stderr != "" {
+			buildahLog.Errorf("stderr:\n%s", stderr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+type BuildahConfigArgs struct {
+	Container string
+	// KEY=VALUE labels to add to the image config, replacing the existing ones with the same key
+	Labels []string
+	// KEY=VALUE annotations to add to the image manifest
+	Annotations []string
+}
+
+// Update the configuration of a working container, applied to the images committed from it.
+func (b *BuildahCli) Config(args *BuildahConfigArgs) error {
+	if args.Container == "" {
+		return errors.New("container is empty")
+	}
+
+	buildahArgs := []string{"config"}
+	for _, label := range args.Labels {
+		buildahArgs = append(buildahArgs, "--label", label)
+	}
+	for _, annotation := range args.Annotations {
+		buildahArgs = append(buildahArgs, "--annotation", annotation)
+	}
+	buildahArgs = append(buildahArgs, args.Container)
+
+	buildahArgs = b.withGlobalArgs(buildahArgs)
+	buildahLog.Debugf("Running command:\n%s", shellJoin("buildah", buildahArgs...))
+
+	_, stderr, _, err := b.Executor.Execute(Command("buildah", buildahArgs...))
+	if err != nil {
+		buildahLog.Errorf("buildah config failed: %s", err.Error())
+		if stderr != "" {
+			buildahLog.Errorf("stderr:\n%s", stderr)
+		}
+		return err
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package cliwrappers_test
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -50,6 +51,8 @@ func TestBuildahCli_Build(t *testing.T) {
 		var capturedArgs []string
 		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
 			g.Expect(cmd.Name).To(Equal("buildah"))
+			// Build output is streamed to the logs while building
+			g.Expect(cmd.LogOutput).To(BeTrue())
 			capturedArgs = cmd.Args
 			return "", "", 0, nil
 		}
@@ -234,6 +237,51 @@ func TestBuildahCli_Build(t *testing.T) {
 		g.Expect(capturedArgs).To(ContainElement("--build-arg-file=/path/to/build-args-file"))
 	})
 
+	t.Run("should pass IgnoreFile as --ignorefile", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		buildArgs := &cliwrappers.BuildahBuildArgs{
+			Containerfile: containerfile,
+			ContextDir:    contextDir,
+			Tags:          []string{outputRef},
+			IgnoreFile:    "/tmp/containerignore",
+		}
+
+		err := buildahCli.Build(buildArgs)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(capturedArgs).To(ContainElement("--ignorefile=/tmp/containerignore"))
+	})
+
+	t.Run("should resolve KEY build args from the environment", func(t *testing.T) {
+		t.Setenv("KBC_TEST_BUILD_ARG", "from env")
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		buildArgs := &cliwrappers.BuildahBuildArgs{
+			Containerfile: containerfile,
+			ContextDir:    contextDir,
+			Tags:          []string{outputRef},
+			BuildArgs:     []string{"KBC_TEST_BUILD_ARG", "KBC_TEST_UNSET_BUILD_ARG", "EMPTY="},
+		}
+
+		err := buildahCli.Build(buildArgs)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(capturedArgs).To(ContainElement("--build-arg=KBC_TEST_BUILD_ARG=from env"))
+		g.Expect(capturedArgs).To(ContainElement("--build-arg=KBC_TEST_UNSET_BUILD_ARG"))
+		g.Expect(capturedArgs).To(ContainElement("--build-arg=EMPTY="))
+	})
+
 	t.Run("should turn Envs into --env params", func(t *testing.T) {
 		buildahCli, executor := setupBuildahCli()
 		var capturedArgs []string
@@ -319,6 +367,22 @@ func TestBuildahCli_Build(t *testing.T) {
 		g.Expect(capturedArgs).To(ContainElement("--tls-verify=true"))
 	})
 
+	t.Run("should pass --cert-dir", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := buildahCli.Build(&cliwrappers.BuildahBuildArgs{
+			Containerfile: containerfile, ContextDir: contextDir, Tags: []string{outputRef},
+			CertDir: "/etc/certs",
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(ContainElement("--cert-dir=/etc/certs"))
+	})
+
 	t.Run("should pass --no-cache", func(t *testing.T) {
 		buildahCli, executor := setupBuildahCli()
 		var capturedArgs []string
@@ -335,6 +399,26 @@ func TestBuildahCli_Build(t *testing.T) {
 		g.Expect(capturedArgs).To(ContainElement("--no-cache"))
 	})
 
+	t.Run("should pass --layers and cache repositories", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := buildahCli.Build(&cliwrappers.BuildahBuildArgs{
+			Containerfile: containerfile, ContextDir: contextDir, Tags: []string{outputRef},
+			Layers:    true,
+			CacheFrom: []string{"quay.io/org/cache", "quay.io/org/other-cache"},
+			CacheTo:   []string{"quay.io/org/cache"},
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(ContainElement("--layers"))
+		g.Expect(capturedArgs).To(ContainElements("--cache-from=quay.io/org/cache", "--cache-from=quay.io/org/other-cache"))
+		g.Expect(capturedArgs).To(ContainElement("--cache-to=quay.io/org/cache"))
+	})
+
 	t.Run("should pass SecurityOpts as separate --security-opt args", func(t *testing.T) {
 		buildahCli, executor := setupBuildahCli()
 		var capturedArgs []string
@@ -353,6 +437,25 @@ func TestBuildahCli_Build(t *testing.T) {
 		g.Expect(capturedArgs[len(capturedArgs)-1]).To(Equal(contextDir))
 	})
 
+	t.Run("should pass Isolation and Userns", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := buildahCli.Build(&cliwrappers.BuildahBuildArgs{
+			Containerfile: containerfile, ContextDir: contextDir, Tags: []string{outputRef},
+			Isolation: "chroot",
+			Userns:    "/proc/1234/ns/user",
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(ContainElement("--isolation=chroot"))
+		g.Expect(capturedArgs).To(ContainElement("--userns=/proc/1234/ns/user"))
+		g.Expect(capturedArgs[len(capturedArgs)-1]).To(Equal(contextDir))
+	})
+
 	t.Run("should pass Cap{Add,Drop} as separate --cap-{add,drop} args", func(t *testing.T) {
 		buildahCli, executor := setupBuildahCli()
 		var capturedArgs []string
@@ -427,6 +530,59 @@ func TestBuildahCli_Build(t *testing.T) {
 		g.Expect(capturedArgs).To(ContainElement("--stage-labels"))
 	})
 
+	t.Run("should pass --format", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := buildahCli.Build(&cliwrappers.BuildahBuildArgs{
+			Containerfile: containerfile, ContextDir: contextDir, Tags: []string{outputRef},
+			Format: "docker",
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(ContainElement("--format=docker"))
+	})
+
+	t.Run("should pass --platform and --manifest", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := buildahCli.Build(&cliwrappers.BuildahBuildArgs{
+			Containerfile: containerfile, ContextDir: contextDir, Manifest: outputRef,
+			Platforms: []string{"linux/amd64", "linux/arm64"},
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(ContainElement("--platform=linux/amd64,linux/arm64"))
+		g.Expect(capturedArgs).To(ContainElements("--manifest", outputRef))
+		g.Expect(capturedArgs).ToNot(ContainElement("--tag"))
+	})
+
+	t.Run("should run buildah with the timeout", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var deadline time.Time
+		var hasDeadline bool
+		executor.executeContextFunc = func(ctx context.Context, cmd cliwrappers.Cmd) (string, string, int, error) {
+			deadline, hasDeadline = ctx.Deadline()
+			return "", "", 0, nil
+		}
+
+		buildArgs := &cliwrappers.BuildahBuildArgs{Containerfile: containerfile, ContextDir: contextDir, Tags: []string{outputRef}}
+		g.Expect(buildahCli.Build(buildArgs)).To(Succeed())
+		g.Expect(hasDeadline).To(BeFalse())
+
+		buildArgs.Timeout = time.Hour
+		g.Expect(buildahCli.Build(buildArgs)).To(Succeed())
+		g.Expect(hasDeadline).To(BeTrue())
+		g.Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+	})
+
 	t.Run("should not pass --save-stages and --stage-labels when false", func(t *testing.T) {
 		buildahCli, executor := setupBuildahCli()
 		var capturedArgs []string
@@ -599,6 +755,46 @@ func TestBuildahCli_Push(t *testing.T) {
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(capturedArgs).To(ContainElement("--tls-verify=true"))
 	})
+
+	t.Run("should pass --cert-dir", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = mockSuccessfulPush(&capturedArgs)
+
+		_, err := buildahCli.Push(&cliwrappers.BuildahPushArgs{
+			Image: image, CertDir: "/etc/certs",
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		expectArgAndValue(g, capturedArgs, "--cert-dir", "/etc/certs")
+	})
+
+	t.Run("should pass --format", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = mockSuccessfulPush(&capturedArgs)
+
+		_, err := buildahCli.Push(&cliwrappers.BuildahPushArgs{
+			Image: image, Format: "docker",
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		expectArgAndValue(g, capturedArgs, "--format", "docker")
+	})
+
+	t.Run("should run each push attempt with the timeout", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		var deadline time.Time
+		var hasDeadline bool
+		executor.executeContextFunc = func(ctx context.Context, cmd cliwrappers.Cmd) (string, string, int, error) {
+			deadline, hasDeadline = ctx.Deadline()
+			return mockSuccessfulPush(&capturedArgs)(cmd)
+		}
+
+		_, err := buildahCli.Push(&cliwrappers.BuildahPushArgs{Image: image, Timeout: 10 * time.Minute})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(hasDeadline).To(BeTrue())
+		g.Expect(deadline).To(BeTemporally("~", time.Now().Add(10*time.Minute), time.Minute))
+	})
 }
 
 func TestBuildahCli_Pull(t *testing.T) {
@@ -1012,6 +1208,7 @@ func TestBuildahBuildArgs_MakePathsAbsolute(t *testing.T) {
 				{Name: "additional-context", Location: "relative/additional-context"},
 			},
 			BuildArgsFile: "relative/build-args-file",
+			IgnoreFile:    "relative/containerignore",
 		}
 
 		err := args.MakePathsAbsolute("/base/dir")
@@ -1022,6 +1219,7 @@ func TestBuildahBuildArgs_MakePathsAbsolute(t *testing.T) {
 		g.Expect(args.Volumes[0].HostDir).To(Equal("/base/dir/relative/volume"))
 		g.Expect(args.BuildContexts[0].Location).To(Equal("/base/dir/relative/additional-context"))
 		g.Expect(args.BuildArgsFile).To(Equal("/base/dir/relative/build-args-file"))
+		g.Expect(args.IgnoreFile).To(Equal("/base/dir/relative/containerignore"))
 	})
 
 	t.Run("should handle a mix of relative and absolute paths", func(t *testing.T) {
@@ -1136,6 +1334,16 @@ func TestBuildahBuildArgs_Validate(t *testing.T) {
 		g.Expect(err.Error()).To(Equal("tags are empty"))
 	})
 
+	t.Run("should allow empty tags with manifest", func(t *testing.T) {
+		args := &cliwrappers.BuildahBuildArgs{
+			Containerfile: containerfile,
+			ContextDir:    contextDir,
+			Manifest:      outputRef,
+		}
+
+		g.Expect(args.Validate()).To(Succeed())
+	})
+
 	t.Run("should error when volume HostDir contains ':'", func(t *testing.T) {
 		args := &cliwrappers.BuildahBuildArgs{
 			Containerfile: containerfile,
@@ -1312,6 +1520,73 @@ func TestBuildahCli_ManifestAdd(t *testing.T) {
 	})
 }
 
+func TestBuildahCli_ManifestAnnotate(t *testing.T) {
+	g := NewWithT(t)
+
+	const manifestName = "quay.io/org/myapp:latest"
+
+	t.Run("should annotate the image index", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).To(Equal("buildah"))
+			g.Expect(cmd.LogOutput).To(BeTrue())
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := buildahCli.ManifestAnnotate(&cliwrappers.BuildahManifestAnnotateArgs{
+			ManifestName: manifestName,
+			Annotations: []string{
+				"org.opencontainers.image.revision=abc123",
+				"org.opencontainers.image.source=https://github.com/org/repo",
+			},
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{
+			"manifest", "annotate", "--index",
+			"--annotation", "org.opencontainers.image.revision=abc123",
+			"--annotation", "org.opencontainers.image.source=https://github.com/org/repo",
+			manifestName,
+		}))
+	})
+
+	t.Run("should error if manifest name is empty", func(t *testing.T) {
+		buildahCli, _ := setupBuildahCli()
+
+		err := buildahCli.ManifestAnnotate(&cliwrappers.BuildahManifestAnnotateArgs{
+			Annotations: []string{"key=value"},
+		})
+
+		g.Expect(err).To(MatchError("manifest name is empty"))
+	})
+
+	t.Run("should error if annotations are empty", func(t *testing.T) {
+		buildahCli, _ := setupBuildahCli()
+
+		err := buildahCli.ManifestAnnotate(&cliwrappers.BuildahManifestAnnotateArgs{
+			ManifestName: manifestName,
+		})
+
+		g.Expect(err).To(MatchError("annotations are empty"))
+	})
+
+	t.Run("should error if buildah execution fails", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "", 1, errors.New("failed to annotate manifest")
+		}
+
+		err := buildahCli.ManifestAnnotate(&cliwrappers.BuildahManifestAnnotateArgs{
+			ManifestName: manifestName,
+			Annotations:  []string{"key=value"},
+		})
+
+		g.Expect(err).To(MatchError("failed to annotate manifest"))
+	})
+}
+
 func TestBuildahCli_ManifestInspect(t *testing.T) {
 	g := NewWithT(t)
 
@@ -1498,6 +1773,91 @@ func TestBuildahCli_ManifestPush(t *testing.T) {
 	})
 }
 
+func TestBuildahCli_ManifestPushWithDigests(t *testing.T) {
+	g := NewWithT(t)
+
+	const indexDigest = "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	const indexJSON = `{
+		"schemaVersion": 2,
+		"mediaType": "application/vnd.oci.image.index.v1+json",
+		"manifests": [
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "size": 100,
+			 "digest": "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+			 "platform": {"os": "linux", "architecture": "amd64"}},
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "size": 100,
+			 "digest": "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+			 "platform": {"os": "linux", "architecture": "arm64", "variant": "v8"}}
+		]
+	}`
+
+	ensureRetryerDisabled(t)
+
+	t.Run("should return the index digest and the per-platform digests of the pushed index", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var inspectArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			if cmd.Args[1] == "push" {
+				os.WriteFile(findDigestFile(cmd.Args), []byte(indexDigest), 0644)
+				return "", "", 0, nil
+			}
+			inspectArgs = cmd.Args
+			return indexJSON, "", 0, nil
+		}
+
+		result, err := buildahCli.ManifestPushWithDigests(&cliwrappers.BuildahManifestPushArgs{
+			ManifestName: "quay.io/org/myapp:latest",
+			Destination:  "docker://quay.io/org/myapp:latest",
+			TLSVerify:    true,
+			CertDir:      "/etc/certs",
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(inspectArgs).To(Equal([]string{
+			"manifest", "inspect", "--tls-verify=true", "--cert-dir", "/etc/certs",
+			"docker://quay.io/org/myapp@" + indexDigest,
+		}))
+		g.Expect(result).To(Equal(&cliwrappers.BuildahManifestPushResult{
+			IndexDigest: indexDigest,
+			Manifests: []cliwrappers.BuildahPlatformManifest{
+				{Platform: "linux/amd64", Digest: "sha256:1111111111111111111111111111111111111111111111111111111111111111"},
+				{Platform: "linux/arm64/v8", Digest: "sha256:2222222222222222222222222222222222222222222222222222222222222222"},
+			},
+		}))
+	})
+
+	t.Run("should error if the destination is not in registry", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "", 1, errors.New("unexpected command")
+		}
+
+		_, err := buildahCli.ManifestPushWithDigests(&cliwrappers.BuildahManifestPushArgs{
+			ManifestName: "quay.io/org/myapp:latest",
+			Destination:  "oci:/tmp/layout",
+		})
+
+		g.Expect(err).To(MatchError(ContainSubstring("must use the docker:// transport")))
+	})
+
+	t.Run("should error if the pushed index can't be inspected", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			if cmd.Args[1] == "push" {
+				os.WriteFile(findDigestFile(cmd.Args), []byte(indexDigest), 0644)
+				return "", "", 0, nil
+			}
+			return "", "", 1, errors.New("manifest unknown")
+		}
+
+		_, err := buildahCli.ManifestPushWithDigests(&cliwrappers.BuildahManifestPushArgs{
+			ManifestName: "quay.io/org/myapp:latest",
+			Destination:  "docker://quay.io/org/myapp:latest",
+		})
+
+		g.Expect(err).To(MatchError(ContainSubstring("inspecting pushed image index: manifest unknown")))
+	})
+}
+
 func TestBuildahCli_Images(t *testing.T) {
 	g := NewWithT(t)
 
@@ -1531,6 +1891,35 @@ func TestBuildahCli_Images(t *testing.T) {
 		g.Expect(capturedArgs).To(Equal([]string{"images", "--json"}))
 	})
 
+	t.Run("should pass --all flag when All is true", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		_, err := buildahCli.Images(&cliwrappers.BuildahImagesArgs{Json: true, All: true})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"images", "--json", "--all"}))
+	})
+
+	t.Run("should pass the storage root", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		buildahCli.Root = "/cache/storage"
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		_, err := buildahCli.Images(&cliwrappers.BuildahImagesArgs{})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"--root", "/cache/storage", "images"}))
+	})
+
 	t.Run("should pass image name when Image is set", func(t *testing.T) {
 		buildahCli, executor := setupBuildahCli()
 		var capturedArgs []string
@@ -1751,6 +2140,46 @@ func TestBuildahCli_Rm(t *testing.T) {
 	})
 }
 
+func TestBuildahCli_Rmi(t *testing.T) {
+	g := NewWithT(t)
+
+	const image = "sha256:586ab46b9d6d906b2df3dad12751e807bd0f0632d5a2ab3991bdac78bdccd59a"
+
+	t.Run("should remove an image", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).To(Equal("buildah"))
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := buildahCli.Rmi(image)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"rmi", image}))
+	})
+
+	t.Run("should error if image is empty", func(t *testing.T) {
+		buildahCli, _ := setupBuildahCli()
+
+		err := buildahCli.Rmi("")
+
+		g.Expect(err).To(MatchError(ContainSubstring("image is empty")))
+	})
+
+	t.Run("should include stderr in the error", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "image used by container\n", 1, errors.New("exit status 1")
+		}
+
+		err := buildahCli.Rmi(image)
+
+		g.Expect(err).To(MatchError("exit status 1: image used by container"))
+	})
+}
+
 func TestBuildahCli_Mount(t *testing.T) {
 	g := NewWithT(t)
 
@@ -1794,3 +2223,164 @@ func TestBuildahCli_Mount(t *testing.T) {
 		g.Expect(err.Error()).To(Equal("failed to mount container"))
 	})
 }
+
+func TestBuildahCli_Commit(t *testing.T) {
+	g := NewWithT(t)
+
+	const container = "image-working-container"
+	const image = "quay.io/org/image:tag-squashed"
+
+	t.Run("should commit a working container", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).To(Equal("buildah"))
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := buildahCli.Commit(&cliwrappers.BuildahCommitArgs{Container: container, Image: image})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"commit", container, image}))
+	})
+
+	t.Run("should pass --squash and --format", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := buildahCli.Commit(&cliwrappers.BuildahCommitArgs{
+			Container: container,
+			Image:     image,
+			Squash:    true,
+			Format:    "docker",
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"commit", "--squash", "--format", "docker", container, image}))
+	})
+
+	t.Run("should error if container is empty", func(t *testing.T) {
+		buildahCli, _ := setupBuildahCli()
+
+		err := buildahCli.Commit(&cliwrappers.BuildahCommitArgs{Image: image})
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("container is empty"))
+	})
+
+	t.Run("should error if image is empty", func(t *testing.T) {
+		buildahCli, _ := setupBuildahCli()
+
+		err := buildahCli.Commit(&cliwrappers.BuildahCommitArgs{Container: container})
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("image is empty"))
+	})
+
+	t.Run("should error if buildah execution fails", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "", 1, errors.New("failed to commit container")
+		}
+
+		err := buildahCli.Commit(&cliwrappers.BuildahCommitArgs{Container: container, Image: image})
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(Equal("failed to commit container"))
+	})
+}
+
+func TestBuildahCli_Config(t *testing.T) {
+	g := NewWithT(t)
+
+	const container = "image-working-container"
+
+	t.Run("should set labels and annotations", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).To(Equal("buildah"))
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := buildahCli.Config(&cliwrappers.BuildahConfigArgs{
+			Container:   container,
+			Labels:      []string{"release=1", "build-id=abc"},
+			Annotations: []string{"org.opencontainers.image.created=2025-06-15T12:00:00Z"},
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{
+			"config",
+			"--label", "release=1",
+			"--label", "build-id=abc",
+			"--annotation", "org.opencontainers.image.created=2025-06-15T12:00:00Z",
+			container,
+		}))
+	})
+
+	t.Run("should error if container is empty", func(t *testing.T) {
+		buildahCli, _ := setupBuildahCli()
+
+		err := buildahCli.Config(&cliwrappers.BuildahConfigArgs{Labels: []string{"release=1"}})
+
+		g.Expect(err).To(MatchError(ContainSubstring("container is empty")))
+	})
+
+	t.Run("should error if buildah execution fails", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "", 1, errors.New("failed to configure container")
+		}
+
+		err := buildahCli.Config(&cliwrappers.BuildahConfigArgs{Container: container})
+
+		g.Expect(err).To(MatchError("failed to configure container"))
+	})
+}
+
+func TestBuildahCli_Tag(t *testing.T) {
+	g := NewWithT(t)
+
+	const image = "sha256:586ab46b9d6d906b2df3dad12751e807bd0f0632d5a2ab3991bdac78bdccd59a"
+
+	t.Run("should tag an image", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).To(Equal("buildah"))
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := buildahCli.Tag(image, "quay.io/org/app:v1", "quay.io/org/app:latest")
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"tag", image, "quay.io/org/app:v1", "quay.io/org/app:latest"}))
+	})
+
+	t.Run("should error without names", func(t *testing.T) {
+		buildahCli, _ := setupBuildahCli()
+
+		err := buildahCli.Tag(image)
+
+		g.Expect(err).To(MatchError("names are empty"))
+	})
+
+	t.Run("should include stderr in the error", func(t *testing.T) {
+		buildahCli, executor := setupBuildahCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "image not known\n", 1, errors.New("exit status 1")
+		}
+
+		err := buildahCli.Tag(image, "quay.io/org/app:v1")
+
+		g.Expect(err).To(MatchError("exit status 1: image not known"))
+	})
+}
@@ -3,13 +3,17 @@ package cliwrappers
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os/exec"
 	"syscall"
+	"time"
 
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+	"github.com/konflux-ci/konflux-build-cli/pkg/metrics"
 )
 
 type Cmd struct {
@@ -28,6 +32,8 @@ func Command(name string, args ...string) Cmd {
 
 type CliExecutorInterface interface {
 	Execute(cmd Cmd) (stdout, stderr string, exitCode int, err error)
+	// ExecuteContext is like Execute, but stops the command when the context is done.
+	ExecuteContext(ctx context.Context, cmd Cmd) (stdout, stderr string, exitCode int, err error)
 }
 
 var _ CliExecutorInterface = &CliExecutor{}
@@ -38,19 +44,50 @@ func NewCliExecutor() *CliExecutor {
 	return &CliExecutor{}
 }
 
+// How long a command gets to exit after SIGTERM, once its context is done, before it's killed.
+var terminationGracePeriod = 10 * time.Second
+
 // Execute runs specified command with given arguments.
 // Returns stdout, stderr, exit code, error
 func (e *CliExecutor) Execute(c Cmd) (string, string, int, error) {
-	cmd := exec.Command(c.Name, c.Args...) //nolint:gosec // CLI wrapper executes external tools by design
+	return e.ExecuteContext(context.Background(), c)
+}
+
+// ExecuteContext runs specified command with given arguments until the context is done.
+// The command is then sent SIGTERM, and SIGKILL if it doesn't exit within the grace period.
+// The returned error wraps the cause of the context cancellation, e.g. the timeout.
+// Returns stdout, stderr, exit code, error
+func (e *CliExecutor) ExecuteContext(ctx context.Context, c Cmd) (string, string, int, error) {
+	start := time.Now()
+	stdout, stderr, exitCode, err := e.execute(ctx, c)
+	metrics.RecordSubprocess(c.Name, c.Args, time.Since(start), exitCode)
+	if err != nil && ctx.Err() != nil {
+		err = fmt.Errorf("%w: %w", context.Cause(ctx), err)
+	}
+	if err != nil && exitCode > 0 {
+		err = &kbcerrors.SubprocessError{Command: c.Name, ExitCode: exitCode, Err: err}
+	}
+	return stdout, stderr, exitCode, err
+}
+
+func (e *CliExecutor) execute(ctx context.Context, c Cmd) (string, string, int, error) {
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...) //nolint:gosec // CLI wrapper executes external tools by design
 	cmd.Dir = c.Dir
 	cmd.Env = c.Env
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = terminationGracePeriod
 
 	if !c.LogOutput {
 		var stdoutBuf, stderrBuf bytes.Buffer
 		cmd.Stdout = &stdoutBuf
 		cmd.Stderr = &stderrBuf
 
-		err := cmd.Run()
+		wait, err := startTracked(cmd)
+		if err == nil {
+			err = wait()
+		}
 
 		return stdoutBuf.String(), stderrBuf.String(), getExitCodeFromError(err), err
 	}
@@ -64,7 +101,8 @@ func (e *CliExecutor) Execute(c Cmd) (string, string, int, error) {
 		return "", "", -1, fmt.Errorf("failed to get stderr: %w", err)
 	}
 
-	if err := cmd.Start(); err != nil {
+	wait, err := startTracked(cmd)
+	if err != nil {
 		return "", "", -1, fmt.Errorf("failed to start command: %w", err)
 	}
 
@@ -112,12 +150,21 @@ func (e *CliExecutor) Execute(c Cmd) (string, string, int, error) {
 	// Wait for both output streams to finish before calling cmd.Wait().
 	// Per [exec.Cmd.StdoutPipe] docs, Wait closes the pipes, so all reads must complete first.
 	readErr := errors.Join(<-done, <-done)
-	cmdErr := cmd.Wait()
+	cmdErr := wait()
 	err = errors.Join(readErr, cmdErr)
 
 	return stdoutBuf.String(), stderrBuf.String(), getExitCodeFromError(err), err
 }
 
+// Context for running a command with the timeout, without a deadline if the timeout is zero.
+// The cause of the context cancellation names the command that timed out.
+func contextWithTimeout(command string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeoutCause(context.Background(), timeout, fmt.Errorf("%s timed out after %s", command, timeout))
+}
+
 func getExitCodeFromError(cmdErr error) int {
 	if cmdErr == nil {
 		return 0
@@ -1,13 +1,16 @@
 package cliwrappers_test
 
 import (
+	"context"
+
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 )
 
 var _ cliwrappers.CliExecutorInterface = &mockExecutor{}
 
 type mockExecutor struct {
-	executeFunc func(cmd cliwrappers.Cmd) (string, string, int, error)
+	executeFunc        func(cmd cliwrappers.Cmd) (string, string, int, error)
+	executeContextFunc func(ctx context.Context, cmd cliwrappers.Cmd) (string, string, int, error)
 }
 
 func (m *mockExecutor) Execute(cmd cliwrappers.Cmd) (string, string, int, error) {
@@ -16,3 +19,10 @@ func (m *mockExecutor) Execute(cmd cliwrappers.Cmd) (string, string, int, error)
 	}
 	return "", "", 0, nil
 }
+
+func (m *mockExecutor) ExecuteContext(ctx context.Context, cmd cliwrappers.Cmd) (string, string, int, error) {
+	if m.executeContextFunc != nil {
+		return m.executeContextFunc(ctx, cmd)
+	}
+	return m.Execute(cmd)
+}
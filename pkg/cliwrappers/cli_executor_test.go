@@ -1,16 +1,22 @@
 package cliwrappers_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 	"github.com/konflux-ci/konflux-build-cli/testutil"
 )
 
@@ -91,6 +97,10 @@ func TestCliExecutor_Execute(t *testing.T) {
 		g.Expect(exitCode).To(Equal(50))
 		g.Expect(stdout).To(BeEmpty())
 		g.Expect(stderr).To(BeEmpty())
+
+		var subprocessErr *kbcerrors.SubprocessError
+		g.Expect(errors.As(err, &subprocessErr)).To(BeTrue())
+		g.Expect(subprocessErr.ExitCode).To(Equal(50))
 	})
 
 	t.Run("should handle command not found", func(t *testing.T) {
@@ -194,6 +204,31 @@ func TestCliExecutor_ExecuteWithLogOutput(t *testing.T) {
 		g.Expect(logOutput).To(ContainSubstring("echo [stdout] test output"))
 	})
 
+	t.Run("should log the output while the command is running", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("uses sh")
+		}
+		g := NewWithT(t)
+
+		// The command waits for the file, which is created once its first line is logged
+		readyFile := filepath.Join(t.TempDir(), "ready")
+		logged := &lineNotifier{line: "sh [stdout] started", notify: func() {
+			_ = os.WriteFile(readyFile, nil, 0644)
+		}}
+		origOut := l.Logger.Out
+		l.Logger.SetOutput(logged)
+		defer l.Logger.SetOutput(origOut)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		cmd := cliwrappers.Command("sh", "-c", fmt.Sprintf("echo started; while [ ! -e %s ]; do sleep 0.05; done; echo done", readyFile))
+		cmd.LogOutput = true
+		stdout, _, _, err := cliwrappers.NewCliExecutor().ExecuteContext(ctx, cmd)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(stdout).To(Equal("started\ndone\n"))
+	})
+
 	t.Run("should handle commands that write to both stdout and stderr", func(t *testing.T) {
 		g := NewWithT(t)
 
@@ -362,6 +397,82 @@ func TestCliExecutor_ExecuteWithLogOutput(t *testing.T) {
 	})
 }
 
+func TestCliExecutor_ExecuteContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("termination signals are not supported on windows")
+	}
+
+	t.Run("should terminate the command when the context times out", func(t *testing.T) {
+		g := NewWithT(t)
+
+		executor := cliwrappers.NewCliExecutor()
+
+		for _, logOutput := range []bool{false, true} {
+			ctx, cancel := context.WithTimeoutCause(context.Background(), 200*time.Millisecond, errors.New("sleep timed out"))
+			defer cancel()
+
+			start := time.Now()
+			cmd := cliwrappers.Command("sleep", "30")
+			cmd.LogOutput = logOutput
+			_, _, exitCode, err := executor.ExecuteContext(ctx, cmd)
+
+			g.Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+			g.Expect(err).To(MatchError("sleep timed out: signal: terminated"))
+			g.Expect(exitCode).To(Equal(-1))
+		}
+	})
+
+	t.Run("should kill the command if it ignores SIGTERM", func(t *testing.T) {
+		g := NewWithT(t)
+
+		gracePeriod := *cliwrappers.ExportTerminationGracePeriod
+		*cliwrappers.ExportTerminationGracePeriod = 200 * time.Millisecond
+		defer func() { *cliwrappers.ExportTerminationGracePeriod = gracePeriod }()
+
+		executor := cliwrappers.NewCliExecutor()
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, _, _, err := executor.ExecuteContext(ctx, cliwrappers.Command("sh", "-c", "trap '' TERM; exec sleep 30"))
+
+		g.Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		g.Expect(err).To(MatchError(context.DeadlineExceeded))
+		g.Expect(err.Error()).To(ContainSubstring("killed"))
+	})
+
+	t.Run("should not change the result of a command that finishes in time", func(t *testing.T) {
+		g := NewWithT(t)
+
+		executor := cliwrappers.NewCliExecutor()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		stdout, _, exitCode, err := executor.ExecuteContext(ctx, cliwrappers.Command("sh", "-c", "echo done; exit 3"))
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(errors.Is(err, context.DeadlineExceeded)).To(BeFalse())
+		g.Expect(exitCode).To(Equal(3))
+		g.Expect(stdout).To(Equal("done\n"))
+	})
+}
+
+// Calls notify when a log entry contains the line.
+type lineNotifier struct {
+	mu     sync.Mutex
+	line   string
+	notify func()
+}
+
+func (n *lineNotifier) Write(p []byte) (int, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if strings.Contains(string(p), n.line) {
+		n.notify()
+	}
+	return len(p), nil
+}
+
 func TestCheckCliToolAvailable(t *testing.T) {
 	t.Run("should return true for available CLI tool", func(t *testing.T) {
 		g := NewWithT(t)
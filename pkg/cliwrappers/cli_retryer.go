@@ -1,10 +1,13 @@
 package cliwrappers
 
 import (
+	"fmt"
 	"regexp"
 	"slices"
+	"strings"
 	"time"
 
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -31,6 +34,8 @@ type Retryer struct {
 
 	stopExitCodes   []int
 	stopErrorRegexs []*regexp.Regexp
+	// Report registry auth errors as such, set by WithImageRegistryPreset
+	registryAuthErrors bool
 }
 
 func NewRetryer(cliCall func() (stdout string, stderr string, errCode int, err error)) *Retryer {
@@ -59,6 +64,10 @@ func (r *Retryer) Run() (stdout string, stderr string, errCode int, err error) {
 			return //nolint:nilerr
 		}
 
+		if Terminating() {
+			retryerLog.Debugf("Stopping retries after attempt %d, because of a termination signal", attempt)
+			return
+		}
 		if slices.Contains(r.stopExitCodes, errCode) {
 			retryerLog.Debugf("Stopping retries after attempt %d, because cli exited with return code: %d", attempt, errCode)
 			return
@@ -66,6 +75,9 @@ func (r *Retryer) Run() (stdout string, stderr string, errCode int, err error) {
 		for _, stopRegex := range r.stopErrorRegexs {
 			if stopRegex.MatchString(stdout) || stopRegex.MatchString(stderr) {
 				retryerLog.Debugf("Stopping retries after attempt %d, because cli output matched stop regex: %s", attempt, stopRegex.String())
+				if r.registryAuthErrors && isRegistryAuthError(stdout, stderr) {
+					err = &kbcerrors.RegistryAuthError{Err: err}
+				}
 				return
 			}
 		}
@@ -151,11 +163,54 @@ func (r *Retryer) StopIfOutputContains(stopString string) *Retryer {
 	return r.StopIfOutputMatches("(?i)" + regexp.QuoteMeta(stopString))
 }
 
+// Retry settings of the image registry preset, see SetRegistryRetryOptions.
+var (
+	registryRetryMaxAttempts = 10
+	registryRetryBaseDelay   = 1 * time.Second
+)
+
+// Registry errors that won't go away by retrying, e.g. missing or wrong credentials.
+var registryPermanentErrors = []string{
+	"unauthorized",
+	"authentication required",
+	"forbidden",
+	"requested access to the resource is denied",
+}
+
+// SetRegistryRetryOptions configures the number of attempts and the initial delay
+// between them for all the commands interacting with an image registry.
+func SetRegistryRetryOptions(maxAttempts int, baseDelay time.Duration) error {
+	if maxAttempts < 1 {
+		return fmt.Errorf("registry retry attempts must be at least 1, got %d", maxAttempts)
+	}
+	if baseDelay < 0 {
+		return fmt.Errorf("registry retry delay must not be negative, got %v", baseDelay)
+	}
+	registryRetryMaxAttempts = maxAttempts
+	registryRetryBaseDelay = baseDelay
+	return nil
+}
+
 // WithImageRegistryPreset sets retryer parameters for interacting with an image registry scenario.
+// Authentication and authorization errors (401, 403) stop the retries.
 func (r *Retryer) WithImageRegistryPreset() *Retryer {
-	r.BaseDelay = 1 * time.Second
+	r.BaseDelay = registryRetryBaseDelay
 	r.DelayFactor = 2
-	r.MaxAttempts = 10
+	r.MaxAttempts = registryRetryMaxAttempts
 	r.MaxDelay = 4 * time.Minute
+	for _, permanentError := range registryPermanentErrors {
+		r.StopIfOutputContains(permanentError)
+	}
+	r.registryAuthErrors = true
 	return r
 }
+
+func isRegistryAuthError(stdout, stderr string) bool {
+	output := strings.ToLower(stdout + "\n" + stderr)
+	for _, permanentError := range registryPermanentErrors {
+		if strings.Contains(output, permanentError) {
+			return true
+		}
+	}
+	return false
+}
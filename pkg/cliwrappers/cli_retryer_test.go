@@ -8,6 +8,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 )
 
 func TestNewRetryer(t *testing.T) {
@@ -315,3 +316,65 @@ func TestRetryer_Run(t *testing.T) {
 		g.Expect(attempt).To(Equal(returnStopStringAtAttempt))
 	})
 }
+
+func TestRetryer_ImageRegistryPreset(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should use configured registry retry options", func(t *testing.T) {
+		g.Expect(cliwrappers.SetRegistryRetryOptions(4, 50*time.Millisecond)).To(Succeed())
+		defer cliwrappers.ExportResetRegistryRetryOptions()
+
+		retryer := cliwrappers.NewRetryer(func() (string, string, int, error) {
+			return "", "", 0, nil
+		}).WithImageRegistryPreset()
+
+		g.Expect(retryer.MaxAttempts).To(Equal(4))
+		g.Expect(retryer.BaseDelay).To(Equal(50 * time.Millisecond))
+	})
+
+	t.Run("should reject invalid registry retry options", func(t *testing.T) {
+		g.Expect(cliwrappers.SetRegistryRetryOptions(0, time.Second)).ToNot(Succeed())
+		g.Expect(cliwrappers.SetRegistryRetryOptions(3, -time.Second)).ToNot(Succeed())
+	})
+
+	t.Run("should retry transient registry errors", func(t *testing.T) {
+		g.Expect(cliwrappers.SetRegistryRetryOptions(3, time.Millisecond)).To(Succeed())
+		defer cliwrappers.ExportResetRegistryRetryOptions()
+
+		attempt := 0
+		retryer := cliwrappers.NewRetryer(func() (string, string, int, error) {
+			attempt++
+			return "", "received unexpected HTTP status: 502 Bad Gateway", 1, errors.New("command has failed")
+		}).WithImageRegistryPreset()
+
+		_, _, _, err := retryer.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(attempt).To(Equal(3))
+		g.Expect(kbcerrors.ExitCode(err)).To(Equal(kbcerrors.ExitCodeGeneric))
+	})
+
+	t.Run("should not retry permanent registry errors", func(t *testing.T) {
+		g.Expect(cliwrappers.SetRegistryRetryOptions(3, time.Millisecond)).To(Succeed())
+		defer cliwrappers.ExportResetRegistryRetryOptions()
+
+		for _, stderr := range []string{
+			"Error: initializing source docker://reg.io/org/app:latest: reading manifest latest in reg.io/org/app: unauthorized: access to the requested resource is not authorized",
+			"Error: authentication required",
+			"Error: writing blob: initiating layer upload to /v2/org/app/blobs/uploads/ in reg.io: StatusCode: 403, \"<html><title>403 Forbidden</title></html>\"",
+			"Error: denied: requested access to the resource is denied",
+		} {
+			attempt := 0
+			retryer := cliwrappers.NewRetryer(func() (string, string, int, error) {
+				attempt++
+				return "", stderr, 1, errors.New("command has failed")
+			}).WithImageRegistryPreset()
+
+			_, _, _, err := retryer.Run()
+
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(attempt).To(Equal(1), stderr)
+			g.Expect(kbcerrors.ExitCode(err)).To(Equal(kbcerrors.ExitCodeRegistryAuth), stderr)
+		}
+	})
+}
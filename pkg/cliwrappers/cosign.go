@@ -0,0 +1,236 @@
+package cliwrappers
+
+import (
+	"errors"
+	"slices"
+
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var cosignLog = l.Logger.WithField("logger", "CosignCli")
+
+type CosignCliInterface interface {
+	Sign(args *CosignSignArgs) error
+	Attest(args *CosignAttestArgs) error
+	Verify(args *CosignVerifyArgs) error
+	VerifyAttestation(args *CosignVerifyAttestationArgs) error
+}
+
+var _ CosignCliInterface = &CosignCli{}
+
+type CosignCli struct {
+	Executor CliExecutorInterface
+}
+
+func NewCosignCli(executor CliExecutorInterface) (*CosignCli, error) {
+	cosignCliAvailable, err := CheckCliToolAvailable("cosign")
+	if err != nil {
+		return nil, err
+	}
+	if !cosignCliAvailable {
+		return nil, &kbcerrors.ToolMissingError{Tool: "cosign"}
+	}
+
+	return &CosignCli{
+		Executor: executor,
+	}, nil
+}
+
+// CosignSigningOptions are the options common to signing and attesting.
+// Without Key, keyless signing is used: the certificate is issued by Fulcio for the identity token
+// and the signature is recorded in Rekor. The Sigstore environment variables (e.g. SIGSTORE_ID_TOKEN,
+// COSIGN_PASSWORD) are passed to cosign as they are.
+type CosignSigningOptions struct {
+	// Path or KMS URI of the private key.
+	Key string
+	// Fulcio and Rekor instances for keyless signing, cosign defaults to the public Sigstore instances.
+	FulcioURL string
+	RekorURL  string
+	// OIDC identity token (or path to a file with it) for keyless signing.
+	IdentityToken string
+	// Don't record the signature in the transparency log.
+	NoTlogUpload bool
+	// Allow HTTP and self-signed certificates of the registry.
+	AllowInsecureRegistry bool
+}
+
+func (o *CosignSigningOptions) args() []string {
+	args := []string{"--yes"}
+	if o.Key != "" {
+		args = append(args, "--key", o.Key)
+	}
+	if o.FulcioURL != "" {
+		args = append(args, "--fulcio-url", o.FulcioURL)
+	}
+	if o.RekorURL != "" {
+		args = append(args, "--rekor-url", o.RekorURL)
+	}
+	if o.IdentityToken != "" {
+		args = append(args, "--identity-token", o.IdentityToken)
+	}
+	if o.NoTlogUpload {
+		args = append(args, "--tlog-upload=false")
+	}
+	if o.AllowInsecureRegistry {
+		args = append(args, "--allow-insecure-registry")
+	}
+	return args
+}
+
+type CosignSignArgs struct {
+	CosignSigningOptions
+	// The image to sign, must be referenced by digest. Required.
+	ImageRef string
+}
+
+// Sign the image and push the signature to the registry.
+func (c *CosignCli) Sign(args *CosignSignArgs) error {
+	if args.ImageRef == "" {
+		return errors.New("image to sign is empty")
+	}
+
+	cosignArgs := append([]string{"sign"}, args.args()...)
+	cosignArgs = append(cosignArgs, args.ImageRef)
+
+	return c.run(cosignArgs, "sign")
+}
+
+type CosignAttestArgs struct {
+	CosignSigningOptions
+	// The image the attestation is about, must be referenced by digest. Required.
+	ImageRef string
+	// Path to the predicate file. Required.
+	Predicate string
+	// Predicate type, e.g. slsaprovenance1, spdxjson, cyclonedx or a custom URI. Required.
+	Type string
+}
+
+// Create a signed attestation of the predicate and push it to the registry.
+func (c *CosignCli) Attest(args *CosignAttestArgs) error {
+	if args.ImageRef == "" {
+		return errors.New("image to attest is empty")
+	}
+	if args.Predicate == "" {
+		return errors.New("predicate is empty")
+	}
+	if args.Type == "" {
+		return errors.New("predicate type is empty")
+	}
+
+	cosignArgs := append([]string{"attest"}, args.args()...)
+	cosignArgs = append(cosignArgs, "--predicate", args.Predicate, "--type", args.Type, args.ImageRef)
+
+	return c.run(cosignArgs, "attest")
+}
+
+// CosignVerifyOptions are the options common to verifying signatures and attestations.
+// With Key, the signatures are verified with the public key. Without it, the signing certificate
+// issued by Fulcio must match the identity and OIDC issuer constraints, cosign requires both.
+type CosignVerifyOptions struct {
+	// Path or KMS URI of the public key.
+	Key string
+	// Identity (e.g. email or URI) of the signing certificate, or a regular expression for it.
+	CertificateIdentity       string
+	CertificateIdentityRegexp string
+	// OIDC issuer of the signing certificate, or a regular expression for it.
+	CertificateOIDCIssuer       string
+	CertificateOIDCIssuerRegexp string
+	// Rekor transparency log instance, cosign defaults to the public Sigstore instance.
+	RekorURL string
+	// Don't require the signatures to be recorded in the transparency log.
+	IgnoreTlog bool
+	// Allow HTTP and self-signed certificates of the registry.
+	AllowInsecureRegistry bool
+}
+
+func (o *CosignVerifyOptions) args() []string {
+	var args []string
+	if o.Key != "" {
+		args = append(args, "--key", o.Key)
+	}
+	if o.CertificateIdentity != "" {
+		args = append(args, "--certificate-identity", o.CertificateIdentity)
+	}
+	if o.CertificateIdentityRegexp != "" {
+		args = append(args, "--certificate-identity-regexp", o.CertificateIdentityRegexp)
+	}
+	if o.CertificateOIDCIssuer != "" {
+		args = append(args, "--certificate-oidc-issuer", o.CertificateOIDCIssuer)
+	}
+	if o.CertificateOIDCIssuerRegexp != "" {
+		args = append(args, "--certificate-oidc-issuer-regexp", o.CertificateOIDCIssuerRegexp)
+	}
+	if o.RekorURL != "" {
+		args = append(args, "--rekor-url", o.RekorURL)
+	}
+	if o.IgnoreTlog {
+		args = append(args, "--insecure-ignore-tlog=true")
+	}
+	if o.AllowInsecureRegistry {
+		args = append(args, "--allow-insecure-registry")
+	}
+	return args
+}
+
+type CosignVerifyArgs struct {
+	CosignVerifyOptions
+	// The image to verify. Required.
+	ImageRef string
+}
+
+// Verify the signatures of the image, fails if there's no valid signature.
+func (c *CosignCli) Verify(args *CosignVerifyArgs) error {
+	if args.ImageRef == "" {
+		return errors.New("image to verify is empty")
+	}
+
+	cosignArgs := append([]string{"verify"}, args.args()...)
+	cosignArgs = append(cosignArgs, args.ImageRef)
+
+	return c.run(cosignArgs, "verify")
+}
+
+type CosignVerifyAttestationArgs struct {
+	CosignVerifyOptions
+	// The image the attestation is about. Required.
+	ImageRef string
+	// Predicate type, e.g. slsaprovenance1, spdxjson, cyclonedx or a custom URI. Required.
+	Type string
+}
+
+// Verify the attestations of the predicate type of the image, fails if there's no valid attestation.
+func (c *CosignCli) VerifyAttestation(args *CosignVerifyAttestationArgs) error {
+	if args.ImageRef == "" {
+		return errors.New("image to verify is empty")
+	}
+	if args.Type == "" {
+		return errors.New("predicate type is empty")
+	}
+
+	cosignArgs := append([]string{"verify-attestation"}, args.args()...)
+	cosignArgs = append(cosignArgs, "--type", args.Type, args.ImageRef)
+
+	return c.run(cosignArgs, "verify-attestation")
+}
+
+func (c *CosignCli) run(cosignArgs []string, subcommand string) error {
+	// The identity token may be given directly instead of a path, don't log it
+	logArgs := slices.Clone(cosignArgs)
+	if i := slices.Index(logArgs, "--identity-token"); i >= 0 && i+1 < len(logArgs) {
+		logArgs[i+1] = "***"
+	}
+	cosignLog.Debugf("Running command:\n%s", shellJoin("cosign", logArgs...))
+
+	retryer := NewRetryer(func() (string, string, int, error) {
+		return c.Executor.Execute(Cmd{Name: "cosign", Args: cosignArgs, LogOutput: true})
+	}).WithImageRegistryPreset()
+
+	if _, _, _, err := retryer.Run(); err != nil {
+		cosignLog.Errorf("cosign %s failed: %s", subcommand, err.Error())
+		return err
+	}
+
+	cosignLog.Debugf("cosign %s completed successfully", subcommand)
+	return nil
+}
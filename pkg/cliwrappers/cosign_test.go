@@ -0,0 +1,230 @@
+package cliwrappers_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func setupCosignCli() (*cliwrappers.CosignCli, *mockExecutor) {
+	executor := &mockExecutor{}
+	cosignCli := &cliwrappers.CosignCli{Executor: executor}
+	cliwrappers.DisableRetryer = true
+	return cosignCli, executor
+}
+
+func TestCosignCli_Sign(t *testing.T) {
+	g := NewWithT(t)
+
+	const imageRef = "reg.io/org/app@sha256:4d6addf62a90e392ff6d3f470259eb5667eab5b9a8e03d20b41d0ab910f92170"
+
+	t.Run("should sign with a key", func(t *testing.T) {
+		cosignCli, executor := setupCosignCli()
+		var capturedCmd cliwrappers.Cmd
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			return "", "", 0, nil
+		}
+
+		err := cosignCli.Sign(&cliwrappers.CosignSignArgs{
+			CosignSigningOptions: cliwrappers.CosignSigningOptions{Key: "/keys/cosign.key", NoTlogUpload: true},
+			ImageRef:             imageRef,
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedCmd.Name).To(Equal("cosign"))
+		g.Expect(capturedCmd.Args).To(Equal([]string{"sign", "--yes", "--key", "/keys/cosign.key", "--tlog-upload=false", imageRef}))
+	})
+
+	t.Run("should sign keyless", func(t *testing.T) {
+		cosignCli, executor := setupCosignCli()
+		var capturedCmd cliwrappers.Cmd
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			return "", "", 0, nil
+		}
+
+		err := cosignCli.Sign(&cliwrappers.CosignSignArgs{
+			CosignSigningOptions: cliwrappers.CosignSigningOptions{
+				FulcioURL:             "https://fulcio.example.com",
+				RekorURL:              "https://rekor.example.com",
+				IdentityToken:         "/var/run/sigstore/token",
+				AllowInsecureRegistry: true,
+			},
+			ImageRef: imageRef,
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedCmd.Args).To(Equal([]string{
+			"sign", "--yes",
+			"--fulcio-url", "https://fulcio.example.com",
+			"--rekor-url", "https://rekor.example.com",
+			"--identity-token", "/var/run/sigstore/token",
+			"--allow-insecure-registry",
+			imageRef,
+		}))
+	})
+
+	t.Run("should return error on missing image", func(t *testing.T) {
+		cosignCli, _ := setupCosignCli()
+
+		err := cosignCli.Sign(&cliwrappers.CosignSignArgs{})
+
+		g.Expect(err).To(MatchError("image to sign is empty"))
+	})
+
+	t.Run("should return error when sign fails", func(t *testing.T) {
+		cosignCli, executor := setupCosignCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "error: signing", 1, errors.New("exit status 1")
+		}
+
+		err := cosignCli.Sign(&cliwrappers.CosignSignArgs{ImageRef: imageRef})
+
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestCosignCli_Attest(t *testing.T) {
+	g := NewWithT(t)
+
+	const imageRef = "reg.io/org/app@sha256:4d6addf62a90e392ff6d3f470259eb5667eab5b9a8e03d20b41d0ab910f92170"
+
+	t.Run("should attest the predicate", func(t *testing.T) {
+		cosignCli, executor := setupCosignCli()
+		var capturedCmd cliwrappers.Cmd
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			return "", "", 0, nil
+		}
+
+		err := cosignCli.Attest(&cliwrappers.CosignAttestArgs{
+			CosignSigningOptions: cliwrappers.CosignSigningOptions{Key: "/keys/cosign.key"},
+			ImageRef:             imageRef,
+			Predicate:            "/tmp/provenance.json",
+			Type:                 "slsaprovenance1",
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedCmd.Args).To(Equal([]string{
+			"attest", "--yes", "--key", "/keys/cosign.key",
+			"--predicate", "/tmp/provenance.json", "--type", "slsaprovenance1", imageRef,
+		}))
+	})
+
+	t.Run("should return error on missing arguments", func(t *testing.T) {
+		cosignCli, _ := setupCosignCli()
+
+		g.Expect(cosignCli.Attest(&cliwrappers.CosignAttestArgs{Predicate: "p", Type: "t"})).To(MatchError("image to attest is empty"))
+		g.Expect(cosignCli.Attest(&cliwrappers.CosignAttestArgs{ImageRef: imageRef, Type: "t"})).To(MatchError("predicate is empty"))
+		g.Expect(cosignCli.Attest(&cliwrappers.CosignAttestArgs{ImageRef: imageRef, Predicate: "p"})).To(MatchError("predicate type is empty"))
+	})
+}
+
+func TestCosignCli_Verify(t *testing.T) {
+	g := NewWithT(t)
+
+	const imageRef = "reg.io/org/app@sha256:4d6addf62a90e392ff6d3f470259eb5667eab5b9a8e03d20b41d0ab910f92170"
+
+	t.Run("should verify with a public key", func(t *testing.T) {
+		cosignCli, executor := setupCosignCli()
+		var capturedCmd cliwrappers.Cmd
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			return "", "", 0, nil
+		}
+
+		err := cosignCli.Verify(&cliwrappers.CosignVerifyArgs{
+			CosignVerifyOptions: cliwrappers.CosignVerifyOptions{Key: "/keys/cosign.pub", IgnoreTlog: true},
+			ImageRef:            imageRef,
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedCmd.Name).To(Equal("cosign"))
+		g.Expect(capturedCmd.Args).To(Equal([]string{"verify", "--key", "/keys/cosign.pub", "--insecure-ignore-tlog=true", imageRef}))
+	})
+
+	t.Run("should verify keyless with identity constraints", func(t *testing.T) {
+		cosignCli, executor := setupCosignCli()
+		var capturedCmd cliwrappers.Cmd
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			return "", "", 0, nil
+		}
+
+		err := cosignCli.Verify(&cliwrappers.CosignVerifyArgs{
+			CosignVerifyOptions: cliwrappers.CosignVerifyOptions{
+				CertificateIdentityRegexp: "^https://github.com/org/",
+				CertificateOIDCIssuer:     "https://token.actions.githubusercontent.com",
+				RekorURL:                  "https://rekor.example.com",
+				AllowInsecureRegistry:     true,
+			},
+			ImageRef: imageRef,
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedCmd.Args).To(Equal([]string{
+			"verify",
+			"--certificate-identity-regexp", "^https://github.com/org/",
+			"--certificate-oidc-issuer", "https://token.actions.githubusercontent.com",
+			"--rekor-url", "https://rekor.example.com",
+			"--allow-insecure-registry",
+			imageRef,
+		}))
+	})
+
+	t.Run("should return error when verification fails", func(t *testing.T) {
+		cosignCli, executor := setupCosignCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "Error: no matching signatures", 1, errors.New("exit status 1")
+		}
+
+		err := cosignCli.Verify(&cliwrappers.CosignVerifyArgs{
+			CosignVerifyOptions: cliwrappers.CosignVerifyOptions{Key: "/keys/cosign.pub"},
+			ImageRef:            imageRef,
+		})
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("should return error on missing image", func(t *testing.T) {
+		cosignCli, _ := setupCosignCli()
+
+		g.Expect(cosignCli.Verify(&cliwrappers.CosignVerifyArgs{})).To(MatchError("image to verify is empty"))
+	})
+}
+
+func TestCosignCli_VerifyAttestation(t *testing.T) {
+	g := NewWithT(t)
+
+	const imageRef = "reg.io/org/app@sha256:4d6addf62a90e392ff6d3f470259eb5667eab5b9a8e03d20b41d0ab910f92170"
+
+	t.Run("should verify the attestation of the predicate type", func(t *testing.T) {
+		cosignCli, executor := setupCosignCli()
+		var capturedCmd cliwrappers.Cmd
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			return "", "", 0, nil
+		}
+
+		err := cosignCli.VerifyAttestation(&cliwrappers.CosignVerifyAttestationArgs{
+			CosignVerifyOptions: cliwrappers.CosignVerifyOptions{Key: "/keys/cosign.pub"},
+			ImageRef:            imageRef,
+			Type:                "slsaprovenance1",
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedCmd.Args).To(Equal([]string{
+			"verify-attestation", "--key", "/keys/cosign.pub", "--type", "slsaprovenance1", imageRef,
+		}))
+	})
+
+	t.Run("should return error on missing arguments", func(t *testing.T) {
+		cosignCli, _ := setupCosignCli()
+
+		g.Expect(cosignCli.VerifyAttestation(&cliwrappers.CosignVerifyAttestationArgs{Type: "t"})).To(MatchError("image to verify is empty"))
+		g.Expect(cosignCli.VerifyAttestation(&cliwrappers.CosignVerifyAttestationArgs{ImageRef: imageRef})).To(MatchError("predicate type is empty"))
+	})
+}
@@ -0,0 +1,277 @@
+package cliwrappers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var dockerLog = l.Logger.WithField("logger", "DockerCli")
+
+// DockerCli is a BuildEngine running docker buildx, for environments with only docker installed.
+// The images are built by BuildKit and loaded into the image store of the docker daemon,
+// the registry access (certificates, auth, proxy) is configured in the daemon and docker config.
+type DockerCli struct {
+	Executor CliExecutorInterface
+}
+
+func NewDockerCli(executor CliExecutorInterface) (*DockerCli, error) {
+	dockerCliAvailable, err := CheckCliToolAvailable("docker")
+	if err != nil {
+		return nil, err
+	}
+	if !dockerCliAvailable {
+		return nil, &kbcerrors.ToolMissingError{Tool: "docker"}
+	}
+
+	return &DockerCli{
+		Executor: executor,
+	}, nil
+}
+
+// Return an error naming the first build option docker buildx has no equivalent for.
+func validateDockerBuildArgs(args *BuildahBuildArgs) error {
+	unsupported := []struct {
+		name string
+		set  bool
+	}{
+		{"manifest", args.Manifest != ""},
+		{"mounts", len(args.Mounts) > 0},
+		{"volumes", len(args.Volumes) > 0},
+		{"build args file", args.BuildArgsFile != ""},
+		{"ignore file", args.IgnoreFile != ""},
+		{"envs", len(args.Envs) > 0},
+		// BuildKit always inherits the labels and skips the unused stages
+		{"not inheriting labels", args.InheritLabels != nil && !*args.InheritLabels},
+		{"building unused stages", args.SkipUnusedStages != nil && !*args.SkipUnusedStages},
+		{"disabling TLS verification", args.TLSVerify != nil && !*args.TLSVerify},
+		{"cert dir", args.CertDir != ""},
+		{"auth file", args.AuthFile != ""},
+		{"squash", args.Squash},
+		{"omit history", args.OmitHistory},
+		{"security options", len(args.SecurityOpts) > 0},
+		{"isolation", args.Isolation != ""},
+		{"user namespace", args.Userns != ""},
+		{"capabilities", len(args.CapAdd) > 0 || len(args.CapDrop) > 0},
+		{"devices", len(args.Devices) > 0},
+		{"saving the stages", args.SaveStages || args.StageLabels},
+		{"wrapper", args.Wrapper != nil},
+	}
+	for _, option := range unsupported {
+		if option.set {
+			return fmt.Errorf("%s is not supported by docker buildx", option.name)
+		}
+	}
+	return nil
+}
+
+// Build the image with docker buildx build and load it into the docker image store.
+// The options docker buildx has no equivalent for are rejected, see validateDockerBuildArgs.
+// Layers are always cached by BuildKit.
+func (d *DockerCli) Build(args *BuildahBuildArgs) error {
+	if err := args.Validate(); err != nil {
+		return fmt.Errorf("validating docker args: %w", err)
+	}
+	if err := validateDockerBuildArgs(args); err != nil {
+		return err
+	}
+
+	dockerArgs := []string{"buildx", "build", "--file", args.Containerfile}
+	for _, tag := range args.Tags {
+		dockerArgs = append(dockerArgs, "--tag", tag)
+	}
+
+	if len(args.Platforms) > 0 {
+		dockerArgs = append(dockerArgs, "--platform="+strings.Join(args.Platforms, ","))
+	}
+
+	for _, secret := range args.Secrets {
+		dockerArgs = append(dockerArgs, "--secret=id="+secret.Id+",src="+secret.Src)
+	}
+
+	for _, buildcontext := range args.BuildContexts {
+		dockerArgs = append(dockerArgs, "--build-context="+buildcontext.Name+"="+buildcontext.Location)
+	}
+
+	for _, buildArg := range resolveBuildArgs(args.BuildArgs) {
+		dockerArgs = append(dockerArgs, "--build-arg="+buildArg)
+	}
+
+	if args.SourceDateEpoch != "" {
+		// BuildKit takes the epoch from the build arg
+		dockerArgs = append(dockerArgs, "--build-arg=SOURCE_DATE_EPOCH="+args.SourceDateEpoch)
+	}
+
+	for _, label := range args.Labels {
+		dockerArgs = append(dockerArgs, "--label="+label)
+	}
+
+	for _, annotation := range args.Annotations {
+		dockerArgs = append(dockerArgs, "--annotation="+annotation)
+	}
+
+	if args.Target != "" {
+		dockerArgs = append(dockerArgs, "--target="+args.Target)
+	}
+
+	if args.NoCache {
+		dockerArgs = append(dockerArgs, "--no-cache")
+	}
+
+	for _, cacheFrom := range args.CacheFrom {
+		dockerArgs = append(dockerArgs, "--cache-from="+cacheFrom)
+	}
+
+	for _, cacheTo := range args.CacheTo {
+		dockerArgs = append(dockerArgs, "--cache-to="+cacheTo)
+	}
+
+	for _, ulimit := range args.Ulimits {
+		dockerArgs = append(dockerArgs, "--ulimit="+ulimit)
+	}
+
+	// Load the image into the docker image store, the equivalent of the local storage of buildah
+	output := "type=docker"
+	if args.RewriteTimestamp {
+		output += ",rewrite-timestamp=true"
+	}
+	if args.Format == "oci" {
+		output += ",oci-mediatypes=true"
+	}
+	dockerArgs = append(dockerArgs, "--output="+output)
+
+	dockerArgs = append(dockerArgs, args.ExtraArgs...)
+	dockerArgs = append(dockerArgs, args.ContextDir)
+
+	dockerLog.Debugf("Running command:\n%s", shellJoin("docker", dockerArgs...))
+
+	ctx, cancel := contextWithTimeout("docker buildx build", args.Timeout)
+	defer cancel()
+
+	_, _, _, err := d.Executor.ExecuteContext(ctx, Cmd{Name: "docker", Args: dockerArgs, LogOutput: true})
+	if err != nil {
+		dockerLog.Errorf("docker buildx build failed: %s", err.Error())
+		return err
+	}
+
+	dockerLog.Debug("Build completed successfully")
+
+	return nil
+}
+
+// docker push prints the digest of the pushed manifest, e.g. 'latest: digest: sha256:... size: 1234'
+var dockerPushDigestRegex = regexp.MustCompile(`digest: (sha256:[0-9a-f]{64})`)
+
+// Push an image from the docker image store to the registry. Return the digest of the pushed manifest.
+// The manifest format is set when building the image, Format is not applied.
+func (d *DockerCli) Push(args *BuildahPushArgs) (string, error) {
+	if args.Image == "" {
+		return "", errors.New("image arg is empty")
+	}
+	if args.Destination != "" {
+		return "", errors.New("pushing to a destination other than the image is not supported by docker")
+	}
+	if err := validateDockerRegistryArgs(args.TLSVerify, args.CertDir); err != nil {
+		return "", err
+	}
+
+	dockerArgs := []string{"push", args.Image}
+	dockerLog.Debugf("Running command:\n%s", shellJoin("docker", dockerArgs...))
+
+	retryer := NewRetryer(func() (string, string, int, error) {
+		ctx, cancel := contextWithTimeout("docker push", args.Timeout)
+		defer cancel()
+		return d.Executor.ExecuteContext(ctx, Cmd{Name: "docker", Args: dockerArgs, LogOutput: true})
+	}).WithImageRegistryPreset()
+
+	stdout, _, _, err := retryer.Run()
+	if err != nil {
+		dockerLog.Errorf("docker push failed: %s", err.Error())
+		return "", err
+	}
+
+	dockerLog.Debug("Push completed successfully")
+
+	matches := dockerPushDigestRegex.FindAllStringSubmatch(stdout, -1)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no digest in the docker push output of %s", args.Image)
+	}
+	return matches[len(matches)-1][1], nil
+}
+
+// Pull an image from the registry to the docker image store.
+func (d *DockerCli) Pull(args *BuildahPullArgs) error {
+	if args.Image == "" {
+		return errors.New("image arg is empty")
+	}
+	if err := validateDockerRegistryArgs(args.TLSVerify, args.CertDir); err != nil {
+		return err
+	}
+	if args.HttpProxy != "" || args.NoProxy != "" {
+		return errors.New("pulling through a proxy is not supported by docker, configure the proxy of the docker daemon")
+	}
+
+	dockerArgs := []string{"pull"}
+	if args.Platform != "" {
+		dockerArgs = append(dockerArgs, "--platform", args.Platform)
+	}
+	dockerArgs = append(dockerArgs, args.Image)
+
+	dockerLog.Debugf("Running command:\n%s", shellJoin("docker", dockerArgs...))
+
+	cmd := Cmd{Name: "docker", Args: dockerArgs, LogOutput: true}
+	if len(args.ExtraEnv) > 0 {
+		cmd.Env = append(os.Environ(), args.ExtraEnv...)
+	}
+
+	retryer := NewRetryer(func() (string, string, int, error) {
+		return d.Executor.Execute(cmd)
+	}).WithImageRegistryPreset().
+		StopIfOutputContains("no matching manifest for")
+
+	_, _, _, err := retryer.Run()
+	if err != nil {
+		dockerLog.Errorf("docker pull failed: %s", err.Error())
+		return err
+	}
+
+	dockerLog.Debug("Pull completed successfully")
+
+	return nil
+}
+
+// The docker daemon verifies the registries with the certificates in /etc/docker/certs.d.
+func validateDockerRegistryArgs(tlsVerify *bool, certDir string) error {
+	if tlsVerify != nil && !*tlsVerify {
+		return errors.New("disabling TLS verification is not supported by docker, configure insecure-registries of the docker daemon")
+	}
+	if certDir != "" {
+		return errors.New("cert dir is not supported by docker, add the certificates to /etc/docker/certs.d")
+	}
+	return nil
+}
+
+func (d *DockerCli) InspectImage(name string) (BuildahImageInfo, error) {
+	if name == "" {
+		return BuildahImageInfo{}, errors.New("name is empty")
+	}
+
+	dockerArgs := []string{"image", "inspect", name}
+	dockerLog.Debugf("Running command:\n%s", shellJoin("docker", dockerArgs...))
+
+	stdout, stderr, _, err := d.Executor.Execute(Command("docker", dockerArgs...))
+	if err != nil {
+		dockerLog.Errorf("docker image inspect failed: %s", err.Error())
+		if stderr != "" {
+			dockerLog.Errorf("stderr:\n%s", stderr)
+		}
+		return BuildahImageInfo{}, err
+	}
+
+	return parseImageInspect(stdout)
+}
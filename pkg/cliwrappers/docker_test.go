@@ -0,0 +1,191 @@
+package cliwrappers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func setupDockerCli() (*cliwrappers.DockerCli, *mockExecutor) {
+	executor := &mockExecutor{}
+	dockerCli := &cliwrappers.DockerCli{Executor: executor}
+	return dockerCli, executor
+}
+
+func TestDockerCli_Build(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should build with docker buildx and load the image", func(t *testing.T) {
+		dockerCli, executor := setupDockerCli()
+		var capturedCmd cliwrappers.Cmd
+		var deadlineSet bool
+		executor.executeContextFunc = func(ctx context.Context, cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			_, deadlineSet = ctx.Deadline()
+			return "", "", 0, nil
+		}
+
+		err := dockerCli.Build(&cliwrappers.BuildahBuildArgs{
+			Containerfile:    "/src/Containerfile",
+			ContextDir:       "/src",
+			Tags:             []string{"quay.io/org/app:v1", "quay.io/org/app:latest"},
+			Platforms:        []string{"linux/arm64"},
+			Secrets:          []cliwrappers.BuildahSecret{{Src: "/secrets/token", Id: "token"}},
+			BuildContexts:    []cliwrappers.BuildahBuildContext{{Name: "buildinfo", Location: "/tmp/buildinfo"}},
+			BuildArgs:        []string{"VERSION=1"},
+			Labels:           []string{"vendor=Org"},
+			SourceDateEpoch:  "1700000000",
+			RewriteTimestamp: true,
+			InheritLabels:    boolPtr(true),
+			SkipUnusedStages: boolPtr(true),
+			TLSVerify:        boolPtr(true),
+			Target:           "runtime",
+			Layers:           true,
+			Format:           "oci",
+			ExtraArgs:        []string{"--progress=plain"},
+			Timeout:          time.Hour,
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedCmd.Name).To(Equal("docker"))
+		g.Expect(capturedCmd.Args).To(Equal([]string{
+			"buildx", "build", "--file", "/src/Containerfile", "--tag", "quay.io/org/app:v1", "--tag", "quay.io/org/app:latest",
+			"--platform=linux/arm64", "--secret=id=token,src=/secrets/token", "--build-context=buildinfo=/tmp/buildinfo",
+			"--build-arg=VERSION=1", "--build-arg=SOURCE_DATE_EPOCH=1700000000", "--label=vendor=Org", "--target=runtime",
+			"--output=type=docker,rewrite-timestamp=true,oci-mediatypes=true", "--progress=plain", "/src",
+		}))
+		g.Expect(capturedCmd.LogOutput).To(BeTrue())
+		g.Expect(deadlineSet).To(BeTrue())
+	})
+
+	t.Run("should reject options without a docker buildx equivalent", func(t *testing.T) {
+		for _, tc := range []struct {
+			args        cliwrappers.BuildahBuildArgs
+			errExpected string
+		}{
+			{cliwrappers.BuildahBuildArgs{Volumes: []cliwrappers.BuildahVolume{{HostDir: "/a", ContainerDir: "/b"}}}, "volumes is not supported by docker buildx"},
+			{cliwrappers.BuildahBuildArgs{InheritLabels: boolPtr(false)}, "not inheriting labels is not supported by docker buildx"},
+			{cliwrappers.BuildahBuildArgs{TLSVerify: boolPtr(false)}, "disabling TLS verification is not supported by docker buildx"},
+			{cliwrappers.BuildahBuildArgs{CertDir: "/certs"}, "cert dir is not supported by docker buildx"},
+			{cliwrappers.BuildahBuildArgs{Squash: true}, "squash is not supported by docker buildx"},
+		} {
+			dockerCli, executor := setupDockerCli()
+			executor.executeContextFunc = func(ctx context.Context, cmd cliwrappers.Cmd) (string, string, int, error) {
+				t.Fatal("docker should not be called")
+				return "", "", 0, nil
+			}
+			args := tc.args
+			args.Containerfile = "/src/Containerfile"
+			args.ContextDir = "/src"
+			args.Tags = []string{"quay.io/org/app:v1"}
+
+			err := dockerCli.Build(&args)
+
+			g.Expect(err).To(MatchError(tc.errExpected))
+		}
+	})
+}
+
+func TestDockerCli_Push(t *testing.T) {
+	g := NewWithT(t)
+	ensureRetryerDisabled(t)
+
+	const digest = "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+	t.Run("should push image and parse the digest from the output", func(t *testing.T) {
+		dockerCli, executor := setupDockerCli()
+		var capturedArgs []string
+		executor.executeContextFunc = func(ctx context.Context, cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			stdout := "The push refers to repository [quay.io/org/app]\n" +
+				"5f70bf18a086: Pushed\n" +
+				"v1: digest: " + digest + " size: 528\n"
+			return stdout, "", 0, nil
+		}
+
+		returnedDigest, err := dockerCli.Push(&cliwrappers.BuildahPushArgs{
+			Image:     "quay.io/org/app:v1",
+			TLSVerify: boolPtr(true),
+			Format:    "oci",
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"push", "quay.io/org/app:v1"}))
+		g.Expect(returnedDigest).To(Equal(digest))
+	})
+
+	t.Run("should error if the output has no digest", func(t *testing.T) {
+		dockerCli, executor := setupDockerCli()
+		executor.executeContextFunc = func(ctx context.Context, cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "Pushed\n", "", 0, nil
+		}
+
+		_, err := dockerCli.Push(&cliwrappers.BuildahPushArgs{Image: "quay.io/org/app:v1"})
+
+		g.Expect(err).To(MatchError("no digest in the docker push output of quay.io/org/app:v1"))
+	})
+
+	t.Run("should reject pushing to a destination", func(t *testing.T) {
+		dockerCli, _ := setupDockerCli()
+
+		_, err := dockerCli.Push(&cliwrappers.BuildahPushArgs{Image: "quay.io/org/app:v1", Destination: "oci:/tmp/layout"})
+
+		g.Expect(err).To(MatchError(ContainSubstring("not supported by docker")))
+	})
+}
+
+func TestDockerCli_Pull(t *testing.T) {
+	g := NewWithT(t)
+	ensureRetryerDisabled(t)
+
+	t.Run("should pull image for the platform", func(t *testing.T) {
+		dockerCli, executor := setupDockerCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := dockerCli.Pull(&cliwrappers.BuildahPullArgs{
+			Image:     "registry.access.redhat.com/ubi9/ubi:latest",
+			Platform:  "linux/arm64",
+			TLSVerify: boolPtr(true),
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"pull", "--platform", "linux/arm64", "registry.access.redhat.com/ubi9/ubi:latest"}))
+	})
+
+	t.Run("should reject the registry options of the daemon", func(t *testing.T) {
+		dockerCli, _ := setupDockerCli()
+
+		err := dockerCli.Pull(&cliwrappers.BuildahPullArgs{Image: "ubi9", CertDir: "/certs"})
+		g.Expect(err).To(MatchError(ContainSubstring("cert dir is not supported by docker")))
+
+		err = dockerCli.Pull(&cliwrappers.BuildahPullArgs{Image: "ubi9", HttpProxy: "proxy.example.com:3128"})
+		g.Expect(err).To(MatchError(ContainSubstring("pulling through a proxy is not supported by docker")))
+	})
+}
+
+func TestDockerCli_InspectImage(t *testing.T) {
+	g := NewWithT(t)
+
+	dockerCli, executor := setupDockerCli()
+	var capturedCmd cliwrappers.Cmd
+	executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+		capturedCmd = cmd
+		return imageInspectOutput, "", 0, nil
+	}
+
+	info, err := dockerCli.InspectImage("registry.access.redhat.com/ubi9/ubi:latest")
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(capturedCmd.Name).To(Equal("docker"))
+	g.Expect(capturedCmd.Args).To(Equal([]string{"image", "inspect", "registry.access.redhat.com/ubi9/ubi:latest"}))
+	g.Expect(info.OCIv1.Architecture).To(Equal("arm64"))
+	g.Expect(info.OCIv1.Config.Labels).To(HaveKeyWithValue("name", "ubi9"))
+}
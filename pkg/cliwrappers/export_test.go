@@ -1,5 +1,13 @@
 package cliwrappers
 
+import "time"
+
 var ExportParseGitVersion = parseGitVersion
 var ExportIsVersionAtLeast = isVersionAtLeast
 var ExportGetUID = &getUID
+var ExportTerminationGracePeriod = &terminationGracePeriod
+var ExportResetTerminating = func() { terminating.Store(false) }
+var ExportResetRegistryRetryOptions = func() {
+	registryRetryMaxAttempts = 10
+	registryRetryBaseDelay = 1 * time.Second
+}
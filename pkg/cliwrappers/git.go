@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -79,7 +80,7 @@ func NewGitCli(executor CliExecutorInterface, workdir string) (*GitCli, error) {
 		return nil, err
 	}
 	if !gitCliAvailable {
-		return nil, errors.New("git CLI is not available")
+		return nil, &kbcerrors.ToolMissingError{Tool: "git"}
 	}
 
 	stdout, _, _, err := executor.Execute(Command("git", "--version"))
@@ -3,9 +3,13 @@
 package cliwrappers
 
 import (
-	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	"github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -16,31 +20,134 @@ type HermetoCliInterface interface {
 	FetchDeps(params *HermetoFetchDepsParams) error
 	GenerateEnv(params *HermetoGenerateEnvParams) error
 	InjectFiles(params *HermetoInjectFilesParams) error
+	MergeSBOMs(params *HermetoMergeSBOMsParams) error
+}
+
+// HermetoLauncher selects how Hermeto is run. The zero value runs hermeto from PATH.
+type HermetoLauncher struct {
+	// The command that runs Hermeto, e.g. /opt/venv/bin/hermeto or python3 -m hermeto.
+	Command []string
+	// Run Hermeto in a container from this image instead, using podman.
+	// The paths Hermeto works with are mounted into the container at the same locations.
+	Image string
 }
 
 type HermetoCli struct {
 	Executor CliExecutorInterface
 	Env      []string // constructed as expected by exec.Cmd.Env
+	Launcher HermetoLauncher
 }
 
 func NewHermetoCli(executor CliExecutorInterface, env []string) (*HermetoCli, error) {
-	hermetoCliAvailable, err := CheckCliToolAvailable("hermeto")
+	return NewHermetoCliWithLauncher(executor, env, HermetoLauncher{})
+}
+
+func NewHermetoCliWithLauncher(executor CliExecutorInterface, env []string, launcher HermetoLauncher) (*HermetoCli, error) {
+	hc := &HermetoCli{Executor: executor, Env: env, Launcher: launcher}
+
+	executable := hc.command()[0]
+	available, err := CheckCliToolAvailable(executable)
 	if err != nil {
 		return nil, err
 	}
+	if !available {
+		if launcher.Image != "" {
+			return nil, fmt.Errorf("%s is required to run hermeto in a container, but it is not available", executable)
+		}
+		if executable == "hermeto" {
+			return nil, &kbcerrors.ToolMissingError{Tool: "hermeto"}
+		}
+		return nil, fmt.Errorf("hermeto CLI is not available: %s not found", executable)
+	}
+
+	return hc, nil
+}
+
+func (hc *HermetoCli) command() []string {
+	if hc.Launcher.Image != "" {
+		return []string{"podman"}
+	}
+	if len(hc.Launcher.Command) > 0 {
+		return hc.Launcher.Command
+	}
+	return []string{"hermeto"}
+}
+
+// Create the Cmd that runs Hermeto with the given args. The paths are the files and directories
+// Hermeto reads or writes, a container needs them mounted.
+func (hc *HermetoCli) hermetoCmd(args []string, env []string, paths ...string) (Cmd, error) {
+	command := hc.command()
+	if hc.Launcher.Image == "" {
+		return Cmd{Name: command[0], Args: slices.Concat(command[1:], args), Env: env, LogOutput: true, NameInLogs: "hermeto"}, nil
+	}
 
-	if !hermetoCliAvailable {
-		return nil, errors.New("hermeto CLI is not available")
+	cwd, err := os.Getwd()
+	if err != nil {
+		return Cmd{}, err
+	}
+	runArgs := []string{"run", "--rm", "--network=host", "--entrypoint", "hermeto", "--workdir", cwd}
+	mounts, err := hermetoContainerMounts(cwd, paths)
+	if err != nil {
+		return Cmd{}, err
 	}
+	for _, mount := range mounts {
+		runArgs = append(runArgs, "--volume", mount+":"+mount)
+	}
+	// Pass the variables by name only, podman takes the values from its own environment
+	for _, envVar := range hc.Env {
+		name, _, _ := strings.Cut(envVar, "=")
+		runArgs = append(runArgs, "--env", name)
+	}
+	runArgs = append(runArgs, hc.Launcher.Image)
 
-	return &HermetoCli{Executor: executor, Env: env}, nil
+	return Cmd{Name: command[0], Args: slices.Concat(runArgs, args), Env: env, LogOutput: true, NameInLogs: "hermeto"}, nil
+}
+
+// Resolve the paths to the absolute, existing, deduplicated directories or files to mount.
+// Paths that don't exist yet (e.g. the output directory) are created by Hermeto, mount their parent.
+func hermetoContainerMounts(cwd string, paths []string) ([]string, error) {
+	mounts := []string{cwd}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cwd, path)
+		}
+		path = filepath.Clean(path)
+		for {
+			if _, err := os.Stat(path); err == nil {
+				break
+			} else if !os.IsNotExist(err) {
+				return nil, err
+			}
+			parent := filepath.Dir(path)
+			if parent == path {
+				break
+			}
+			path = parent
+		}
+		if !slices.Contains(mounts, path) {
+			mounts = append(mounts, path)
+		}
+	}
+	return mounts, nil
+}
+
+func (hc *HermetoCli) execute(args []string, env []string, paths ...string) error {
+	cmd, err := hc.hermetoCmd(args, env, paths...)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Executing %s", shellJoin(cmd.Name, cmd.Args...))
+	_, _, _, err = hc.Executor.Execute(cmd)
+	return err
 }
 
 // Print the Hermeto version.
 func (hc *HermetoCli) Version() error {
 	args := []string{"--version"}
-	_, _, _, err := hc.Executor.Execute(Cmd{Name: "hermeto", Args: args, LogOutput: true})
-	return err
+	return hc.execute(args, nil)
 }
 
 type HermetoFetchDepsParams struct {
@@ -50,6 +157,8 @@ type HermetoFetchDepsParams struct {
 	ConfigFile string
 	SBOMFormat string
 	Mode       string
+	// Allow the package managers that are still in development in Hermeto
+	DevPackageManagers bool
 }
 
 // Run the Hermeto fetch-deps command.
@@ -79,11 +188,19 @@ func (hc *HermetoCli) FetchDeps(params *HermetoFetchDepsParams) error {
 		"--output",
 		params.OutputDir,
 	)
+	if params.DevPackageManagers {
+		args = append(args, "--dev-package-managers")
+	}
 
-	log.Debugf("Executing %s", shellJoin("hermeto", args...))
 	extendedEnv := append(os.Environ(), hc.Env...)
-	_, _, _, err := hc.Executor.Execute(Cmd{Name: "hermeto", Args: args, LogOutput: true, Env: extendedEnv})
-	return err
+	paths := []string{params.SourceDir, params.OutputDir, params.ConfigFile}
+	if hc.Launcher.Image != "" {
+		// Git and netrc credentials for private dependencies are set up in the home directory
+		if home, err := os.UserHomeDir(); err == nil {
+			paths = append(paths, home)
+		}
+	}
+	return hc.execute(args, extendedEnv, paths...)
 }
 
 type HermetoGenerateEnvParams struct {
@@ -107,9 +224,7 @@ func (hc *HermetoCli) GenerateEnv(params *HermetoGenerateEnvParams) error {
 		params.Output,
 	}
 
-	log.Debugf("Executing %s", shellJoin("hermeto", args...))
-	_, _, _, err := hc.Executor.Execute(Cmd{Name: "hermeto", Args: args, LogOutput: true})
-	return err
+	return hc.execute(args, nil, params.OutputDir, params.Output)
 }
 
 type HermetoInjectFilesParams struct {
@@ -130,7 +245,32 @@ func (hc *HermetoCli) InjectFiles(params *HermetoInjectFilesParams) error {
 		params.ForOutputDir,
 	}
 
-	log.Debugf("Executing %s", shellJoin("hermeto", args...))
-	_, _, _, err := hc.Executor.Execute(Cmd{Name: "hermeto", Args: args, LogOutput: true})
-	return err
+	return hc.execute(args, nil, params.OutputDir)
+}
+
+type HermetoMergeSBOMsParams struct {
+	SBOMFiles  []string
+	SBOMFormat string
+	Output     string
+}
+
+// Run the Hermeto merge-sboms command.
+func (hc *HermetoCli) MergeSBOMs(params *HermetoMergeSBOMsParams) error {
+	logLevel := logger.Logger.GetLevel().String()
+
+	args := []string{
+		"--log-level",
+		logLevel,
+		"merge-sboms",
+	}
+	args = append(args, params.SBOMFiles...)
+	args = append(
+		args,
+		"--sbom-output-type",
+		params.SBOMFormat,
+		"--output",
+		params.Output,
+	)
+
+	return hc.execute(args, nil, slices.Concat(params.SBOMFiles, []string{params.Output})...)
 }
@@ -1,6 +1,9 @@
 package cliwrappers_test
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -45,6 +48,8 @@ func TestHermetoCliFetchDepsArgs(t *testing.T) {
 
 	executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
 		g.Expect(cmd.Name).To(Equal("hermeto"))
+		// Hermeto output is streamed to the logs while fetching
+		g.Expect(cmd.LogOutput).To(BeTrue())
 		capturedArgs = cmd.Args
 		// mock stdout, stderr, exit code and error
 		return "", "", 0, nil
@@ -77,6 +82,13 @@ func TestHermetoCliFetchDepsArgs(t *testing.T) {
 	g.Expect(capturedArgs[11]).To(Equal("/source"))
 	g.Expect(capturedArgs[12]).To(Equal("--output"))
 	g.Expect(capturedArgs[13]).To(Equal("/output"))
+
+	params.DevPackageManagers = true
+	err = hermetoCli.FetchDeps(params)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(capturedArgs).To(HaveLen(15))
+	g.Expect(capturedArgs[14]).To(Equal("--dev-package-managers"))
 }
 
 func TestHermetoCliGenerateEnvArgs(t *testing.T) {
@@ -141,3 +153,98 @@ func TestHermetoCliInjectFilesArgs(t *testing.T) {
 	g.Expect(capturedArgs[4]).To(Equal("--for-output-dir"))
 	g.Expect(capturedArgs[5]).To(Equal("/tmp"))
 }
+
+func TestHermetoCliMergeSBOMsArgs(t *testing.T) {
+	g := NewWithT(t)
+
+	hermetoCli, executor := setupHermetoCli()
+	var capturedArgs []string
+
+	executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+		g.Expect(cmd.Name).To(Equal("hermeto"))
+		capturedArgs = cmd.Args
+		// mock stdout, stderr, exit code and error
+		return "", "", 0, nil
+	}
+
+	params := &cliwrappers.HermetoMergeSBOMsParams{
+		SBOMFiles:  []string{"/output/gomod/bom.json", "/output/npm/bom.json"},
+		SBOMFormat: "spdx",
+		Output:     "/output/bom.json",
+	}
+
+	err := hermetoCli.MergeSBOMs(params)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(capturedArgs).To(HaveLen(9))
+	g.Expect(capturedArgs[0]).To(Equal("--log-level"))
+	g.Expect(capturedArgs[1]).ToNot(BeEmpty()) // log level value
+	g.Expect(capturedArgs[2:]).To(Equal([]string{
+		"merge-sboms",
+		"/output/gomod/bom.json",
+		"/output/npm/bom.json",
+		"--sbom-output-type",
+		"spdx",
+		"--output",
+		"/output/bom.json",
+	}))
+}
+
+func TestHermetoCliLauncherCommand(t *testing.T) {
+	g := NewWithT(t)
+
+	hermetoCli, executor := setupHermetoCli()
+	hermetoCli.Launcher = cliwrappers.HermetoLauncher{Command: []string{"/opt/venv/bin/python3", "-m", "hermeto"}}
+	var capturedCmd cliwrappers.Cmd
+
+	executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+		capturedCmd = cmd
+		return "", "", 0, nil
+	}
+
+	err := hermetoCli.Version()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(capturedCmd.Name).To(Equal("/opt/venv/bin/python3"))
+	g.Expect(capturedCmd.Args).To(Equal([]string{"-m", "hermeto", "--version"}))
+}
+
+func TestHermetoCliLauncherImage(t *testing.T) {
+	g := NewWithT(t)
+
+	sourceDir := t.TempDir()
+	outputDir := filepath.Join(t.TempDir(), "prefetch-output")
+	cwd, err := os.Getwd()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	hermetoCli, executor := setupHermetoCli()
+	hermetoCli.Env = []string{"HERMETO_NPM__PROXY_URL=https://proxy.example.com"}
+	hermetoCli.Launcher = cliwrappers.HermetoLauncher{Image: "quay.io/konflux-ci/hermeto:latest"}
+	var capturedCmd cliwrappers.Cmd
+
+	executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+		capturedCmd = cmd
+		return "", "", 0, nil
+	}
+
+	params := &cliwrappers.HermetoFetchDepsParams{
+		Input:      "gomod",
+		SourceDir:  sourceDir,
+		OutputDir:  outputDir,
+		SBOMFormat: "spdx",
+		Mode:       "strict",
+	}
+	err = hermetoCli.FetchDeps(params)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(capturedCmd.Name).To(Equal("podman"))
+	g.Expect(capturedCmd.Args[:7]).To(Equal([]string{"run", "--rm", "--network=host", "--entrypoint", "hermeto", "--workdir", cwd}))
+	args := strings.Join(capturedCmd.Args, " ")
+	g.Expect(args).To(ContainSubstring("--volume " + cwd + ":" + cwd))
+	g.Expect(args).To(ContainSubstring("--volume " + sourceDir + ":" + sourceDir))
+	// The output directory doesn't exist yet, its parent is mounted
+	g.Expect(args).To(ContainSubstring("--volume " + filepath.Dir(outputDir) + ":" + filepath.Dir(outputDir)))
+	g.Expect(args).To(ContainSubstring("--env HERMETO_NPM__PROXY_URL quay.io/konflux-ci/hermeto:latest --log-level"))
+	g.Expect(args).To(HaveSuffix("fetch-deps gomod --sbom-output-type spdx --source " + sourceDir + " --output " + outputDir))
+	g.Expect(capturedCmd.Env).To(ContainElement("HERMETO_NPM__PROXY_URL=https://proxy.example.com"))
+}
@@ -1,15 +1,28 @@
 package cliwrappers
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
 var orasLog = l.Logger.WithField("logger", "OrasCli")
 
+// Error of oras meaning that the manifest doesn't exist
+const orasNotFoundError = "not found"
+
 type OrasCliInterface interface {
 	Push(args *OrasPushArgs) (string, string, error)
+	Attach(args *OrasAttachArgs) (string, string, error)
+	Discover(args *OrasDiscoverArgs) ([]OrasReferrer, error)
+	ManifestFetch(args *OrasManifestFetchArgs) (string, error)
+	BlobFetch(args *OrasBlobFetchArgs) error
 }
 
 var _ OrasCliInterface = &OrasCli{}
@@ -24,7 +37,7 @@ func NewOrasCli(executor CliExecutorInterface) (*OrasCli, error) {
 		return nil, err
 	}
 	if !cliAvailable {
-		return nil, fmt.Errorf("oras CLI is not available")
+		return nil, &kbcerrors.ToolMissingError{Tool: "oras"}
 	}
 
 	return &OrasCli{
@@ -32,13 +45,56 @@ func NewOrasCli(executor CliExecutorInterface) (*OrasCli, error) {
 	}, nil
 }
 
+// A file pushed as a layer of the artifact, with an optional media type.
+type OrasPushFile struct {
+	Path      string
+	MediaType string
+}
+
 type OrasPushArgs struct {
 	DestinationImage string
 	FileName         string
-	ArtifactType     string
-	RegistryConfig   string
-	Format           string
-	Template         string
+	// Files to push in addition to FileName.
+	Files          []OrasPushFile
+	ArtifactType   string
+	RegistryConfig string
+	Format         string
+	Template       string
+	// Manifest annotations in the key=value format.
+	Annotations []string
+	// Allow absolute file paths, oras only accepts relative paths by default.
+	DisablePathValidation bool
+	// Number of concurrent uploads, oras defaults to 5.
+	Concurrency int
+	// Number of times to retry a failed push, defaults to the registry retry settings.
+	// oras checks which blobs already exist in the registry before uploading, so a retry
+	// only uploads the blobs that didn't make it.
+	Retries int
+}
+
+// The file arguments of oras push/attach: path[:mediatype]
+func orasFileArgs(files []OrasPushFile) []string {
+	var fileArgs []string
+	for _, file := range files {
+		if file.MediaType != "" {
+			fileArgs = append(fileArgs, file.Path+":"+file.MediaType)
+		} else {
+			fileArgs = append(fileArgs, file.Path)
+		}
+	}
+	return fileArgs
+}
+
+// The annotation and path validation arguments common to oras push/attach.
+func orasAnnotationArgs(annotations []string, disablePathValidation bool) []string {
+	var args []string
+	for _, annotation := range annotations {
+		args = append(args, "--annotation", annotation)
+	}
+	if disablePathValidation {
+		args = append(args, "--disable-path-validation")
+	}
+	return args
 }
 
 // Push a file from local to the registry. Return the stdout and stderr output from oras command.
@@ -46,7 +102,7 @@ func (b *OrasCli) Push(args *OrasPushArgs) (string, string, error) {
 	if args.DestinationImage == "" {
 		return "", "", fmt.Errorf("destination image arg is empty")
 	}
-	if args.FileName == "" {
+	if args.FileName == "" && len(args.Files) == 0 {
 		return "", "", fmt.Errorf("file name arg is empty")
 	}
 
@@ -63,18 +119,240 @@ func (b *OrasCli) Push(args *OrasPushArgs) (string, string, error) {
 	if args.Template != "" {
 		orasArgs = append(orasArgs, "--template", args.Template)
 	}
-	orasArgs = append(orasArgs, args.DestinationImage, args.FileName)
+	orasArgs = append(orasArgs, orasAnnotationArgs(args.Annotations, args.DisablePathValidation)...)
+	if args.Concurrency > 0 {
+		orasArgs = append(orasArgs, "--concurrency", strconv.Itoa(args.Concurrency))
+	}
+	orasArgs = append(orasArgs, args.DestinationImage)
+	if args.FileName != "" {
+		orasArgs = append(orasArgs, args.FileName)
+	}
+	orasArgs = append(orasArgs, orasFileArgs(args.Files)...)
 
 	orasLog.Debugf("Running command:\n%s", shellJoin("oras", orasArgs...))
 
-	stdout, stderr, _, err := b.Executor.Execute(Cmd{Name: "oras", Args: orasArgs, LogOutput: true})
+	start := time.Now()
+	retryer := NewRetryer(func() (string, string, int, error) {
+		return b.Executor.Execute(Cmd{Name: "oras", Args: orasArgs, LogOutput: true})
+	}).WithImageRegistryPreset()
+	if args.Retries > 0 {
+		retryer.WithMaxAttempts(args.Retries + 1)
+	}
+
+	stdout, stderr, _, err := retryer.Run()
 
 	if err != nil {
 		orasLog.Errorf("oras push failed: %s", err.Error())
 		return "", "", err
 	}
 
+	elapsed := time.Since(start)
+	paths := []string{}
+	if args.FileName != "" {
+		paths = append(paths, args.FileName)
+	}
+	for _, file := range args.Files {
+		paths = append(paths, file.Path)
+	}
+	var totalSize int64
+	for _, path := range paths {
+		if stat, statErr := os.Stat(path); statErr == nil && !stat.IsDir() {
+			totalSize += stat.Size()
+		}
+	}
+	if totalSize > 0 && elapsed > 0 {
+		throughput := float64(totalSize) / (1024 * 1024) / elapsed.Seconds()
+		orasLog.Infof("Pushed %s (%d bytes) in %s, %.2f MiB/s", strings.Join(paths, ", "), totalSize, elapsed.Round(time.Millisecond), throughput)
+	}
+
 	orasLog.Debug("Push completed successfully")
 
 	return stdout, stderr, nil
 }
+
+type OrasAttachArgs struct {
+	// The image the artifact refers to, must be referenced by digest
+	Subject        string
+	Files          []OrasPushFile
+	ArtifactType   string
+	RegistryConfig string
+	Format         string
+	Template       string
+	// Manifest annotations in the key=value format.
+	Annotations []string
+	// Allow absolute file paths, oras only accepts relative paths by default.
+	DisablePathValidation bool
+	// Number of times to retry a failed attach, defaults to the registry retry settings.
+	Retries int
+}
+
+// Attach files to an image as an OCI 1.1 referrer artifact. Return the stdout and stderr output from oras command.
+func (b *OrasCli) Attach(args *OrasAttachArgs) (string, string, error) {
+	if args.Subject == "" {
+		return "", "", fmt.Errorf("subject arg is empty")
+	}
+	if len(args.Files) == 0 {
+		return "", "", fmt.Errorf("files arg is empty")
+	}
+	if args.ArtifactType == "" {
+		// oras requires the artifact type for attach
+		return "", "", fmt.Errorf("artifact type arg is empty")
+	}
+
+	orasArgs := []string{"attach", "--artifact-type", args.ArtifactType}
+	if args.RegistryConfig != "" {
+		orasArgs = append(orasArgs, "--registry-config", args.RegistryConfig)
+	}
+	if args.Format != "" {
+		orasArgs = append(orasArgs, "--format", args.Format)
+	}
+	if args.Template != "" {
+		orasArgs = append(orasArgs, "--template", args.Template)
+	}
+	orasArgs = append(orasArgs, orasAnnotationArgs(args.Annotations, args.DisablePathValidation)...)
+	orasArgs = append(orasArgs, args.Subject)
+	orasArgs = append(orasArgs, orasFileArgs(args.Files)...)
+
+	orasLog.Debugf("Running command:\n%s", shellJoin("oras", orasArgs...))
+
+	retryer := NewRetryer(func() (string, string, int, error) {
+		return b.Executor.Execute(Cmd{Name: "oras", Args: orasArgs, LogOutput: true})
+	}).WithImageRegistryPreset()
+	if args.Retries > 0 {
+		retryer.WithMaxAttempts(args.Retries + 1)
+	}
+
+	stdout, stderr, _, err := retryer.Run()
+	if err != nil {
+		orasLog.Errorf("oras attach failed: %s", err.Error())
+		return "", "", err
+	}
+
+	orasLog.Debug("Attach completed successfully")
+
+	return stdout, stderr, nil
+}
+
+type OrasDiscoverArgs struct {
+	// The image to list the referrers of, must be referenced by digest
+	Subject string
+	// List only the referrers of this artifact type
+	ArtifactType   string
+	RegistryConfig string
+}
+
+// A referrer of an image, as listed by oras discover.
+type OrasReferrer struct {
+	Digest       string `json:"digest"`
+	MediaType    string `json:"mediaType"`
+	ArtifactType string `json:"artifactType"`
+}
+
+// Discover lists the direct referrers of an image using the OCI 1.1 referrers API.
+func (b *OrasCli) Discover(args *OrasDiscoverArgs) ([]OrasReferrer, error) {
+	if args.Subject == "" {
+		return nil, fmt.Errorf("subject arg is empty")
+	}
+
+	orasArgs := []string{"discover", "--format", "json"}
+	if args.ArtifactType != "" {
+		orasArgs = append(orasArgs, "--artifact-type", args.ArtifactType)
+	}
+	if args.RegistryConfig != "" {
+		orasArgs = append(orasArgs, "--registry-config", args.RegistryConfig)
+	}
+	orasArgs = append(orasArgs, args.Subject)
+
+	orasLog.Debugf("Running command:\n%s", shellJoin("oras", orasArgs...))
+
+	retryer := NewRetryer(func() (string, string, int, error) {
+		return b.Executor.Execute(Command("oras", orasArgs...))
+	}).WithImageRegistryPreset()
+
+	stdout, _, _, err := retryer.Run()
+	if err != nil {
+		orasLog.Errorf("oras discover failed: %s", err.Error())
+		return nil, err
+	}
+
+	// oras 1.2 lists the referrers in manifests, newer versions in referrers
+	var output struct {
+		Manifests []OrasReferrer `json:"manifests"`
+		Referrers []OrasReferrer `json:"referrers"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		return nil, fmt.Errorf("parsing oras discover output: %w", err)
+	}
+	return append(output.Manifests, output.Referrers...), nil
+}
+
+type OrasManifestFetchArgs struct {
+	Image          string
+	RegistryConfig string
+}
+
+// ManifestFetch returns the raw manifest of an image.
+func (b *OrasCli) ManifestFetch(args *OrasManifestFetchArgs) (string, error) {
+	if args.Image == "" {
+		return "", fmt.Errorf("image arg is empty")
+	}
+
+	orasArgs := []string{"manifest", "fetch"}
+	if args.RegistryConfig != "" {
+		orasArgs = append(orasArgs, "--registry-config", args.RegistryConfig)
+	}
+	orasArgs = append(orasArgs, args.Image)
+
+	orasLog.Debugf("Running command:\n%s", shellJoin("oras", orasArgs...))
+
+	retryer := NewRetryer(func() (string, string, int, error) {
+		return b.Executor.Execute(Command("oras", orasArgs...))
+	}).WithImageRegistryPreset().StopIfOutputContains(orasNotFoundError)
+
+	stdout, stderr, _, err := retryer.Run()
+	if err != nil {
+		orasLog.Errorf("oras manifest fetch failed: %s", err.Error())
+		return "", fmt.Errorf("%w: %s", err, stderr)
+	}
+	return stdout, nil
+}
+
+// IsOrasNotFound reports whether the error returned by ManifestFetch means that the manifest doesn't exist.
+func IsOrasNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), orasNotFoundError)
+}
+
+type OrasBlobFetchArgs struct {
+	// The blob reference, i.e. repository@digest
+	Blob           string
+	OutputPath     string
+	RegistryConfig string
+}
+
+// BlobFetch downloads a blob to the output path.
+func (b *OrasCli) BlobFetch(args *OrasBlobFetchArgs) error {
+	if args.Blob == "" {
+		return fmt.Errorf("blob arg is empty")
+	}
+	if args.OutputPath == "" {
+		return fmt.Errorf("output path arg is empty")
+	}
+
+	orasArgs := []string{"blob", "fetch", "--output", args.OutputPath}
+	if args.RegistryConfig != "" {
+		orasArgs = append(orasArgs, "--registry-config", args.RegistryConfig)
+	}
+	orasArgs = append(orasArgs, args.Blob)
+
+	orasLog.Debugf("Running command:\n%s", shellJoin("oras", orasArgs...))
+
+	retryer := NewRetryer(func() (string, string, int, error) {
+		return b.Executor.Execute(Command("oras", orasArgs...))
+	}).WithImageRegistryPreset()
+
+	if _, _, _, err := retryer.Run(); err != nil {
+		orasLog.Errorf("oras blob fetch failed: %s", err.Error())
+		return err
+	}
+	return nil
+}
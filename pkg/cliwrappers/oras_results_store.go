@@ -0,0 +1,56 @@
+package cliwrappers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+)
+
+// ResultsArtifactType is the artifact type of the result values pushed by OrasResultsStore.
+const ResultsArtifactType = "application/vnd.konflux-ci.result"
+
+var _ common.ResultsOverflowStore = &OrasResultsStore{}
+
+// OrasResultsStore pushes the values of large result fields as OCI artifacts into a repository,
+// tagged sha256-<value digest>.result. See common.SetResultsSizeLimit.
+type OrasResultsStore struct {
+	Oras       OrasCliInterface
+	Repository string
+}
+
+func NewOrasResultsStore(oras OrasCliInterface, repository string) *OrasResultsStore {
+	return &OrasResultsStore{Oras: oras, Repository: repository}
+}
+
+// Store returns the digest reference of the pushed artifact.
+func (s *OrasResultsStore) Store(name string, value []byte) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "kbc-result-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, name+".json")
+	if err := os.WriteFile(path, value, 0600); err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(value)
+	stdout, _, err := s.Oras.Push(&OrasPushArgs{
+		DestinationImage:      s.Repository + ":sha256-" + hex.EncodeToString(digest[:]) + ".result",
+		Files:                 []OrasPushFile{{Path: path, MediaType: "application/json"}},
+		ArtifactType:          ResultsArtifactType,
+		Format:                "go-template",
+		Template:              "{{.reference}}",
+		DisablePathValidation: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to push result '%s' into %s: %w", name, s.Repository, err)
+	}
+	return strings.TrimSpace(stdout), nil
+}
@@ -0,0 +1,70 @@
+package cliwrappers_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func TestOrasResultsStore_Store(t *testing.T) {
+	const repository = "quay.io/org/results"
+	const artifactDigest = "sha256:4d6addf62a90e392ff6d3f470259eb5667eab5b9a8e03d20b41d0ab910f92170"
+	// sha256 of "large value"
+	const valueTag = "sha256-b1d2df6873cf99cc9ba41ba5f711234f48e2637ae213c4360deed357045ddeef.result"
+
+	t.Run("should push the value as an artifact tagged by its digest", func(t *testing.T) {
+		g := NewWithT(t)
+		orasCli, executor := setupOrasCli()
+
+		var pushedPath string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).Should(Equal("oras"))
+			g.Expect(cmd.Args).Should(HaveLen(10))
+			g.Expect(cmd.Args[:8]).Should(Equal([]string{
+				"push",
+				"--artifact-type", cliwrappers.ResultsArtifactType,
+				"--format", "go-template",
+				"--template", "{{.reference}}",
+				"--disable-path-validation",
+			}))
+			g.Expect(cmd.Args[8]).Should(Equal(repository + ":" + valueTag))
+
+			path, mediaType, _ := strings.Cut(cmd.Args[9], ":")
+			g.Expect(mediaType).Should(Equal("application/json"))
+			g.Expect(filepath.Base(path)).Should(Equal("sbom.json"))
+			content, err := os.ReadFile(path)
+			g.Expect(err).ShouldNot(HaveOccurred())
+			g.Expect(string(content)).Should(Equal("large value"))
+			pushedPath = path
+
+			return repository + "@" + artifactDigest + "\n", "", 0, nil
+		}
+
+		store := cliwrappers.NewOrasResultsStore(orasCli, repository)
+		ref, err := store.Store("sbom", []byte("large value"))
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(ref).Should(Equal(repository + "@" + artifactDigest))
+
+		// The temporary file is removed after the push
+		g.Expect(pushedPath).ShouldNot(BeAnExistingFile())
+	})
+
+	t.Run("should error if push fails", func(t *testing.T) {
+		g := NewWithT(t)
+		orasCli, executor := setupOrasCli()
+
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "unauthorized", 1, errors.New("exit status 1")
+		}
+
+		store := cliwrappers.NewOrasResultsStore(orasCli, repository)
+		_, err := store.Store("sbom", []byte("large value"))
+		g.Expect(err).Should(MatchError(ContainSubstring("failed to push result 'sbom' into " + repository)))
+	})
+}
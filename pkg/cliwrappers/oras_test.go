@@ -1,6 +1,7 @@
 package cliwrappers_test
 
 import (
+	"errors"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -45,6 +46,46 @@ func TestOrasCli_Push(t *testing.T) {
 		g.Expect(stderr).Should(Equal("push progress"))
 	})
 
+	t.Run("push multiple files with media types", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Args).Should(Equal([]string{"push", artifactImage, "model.gguf:application/vnd.gguf", "LICENSE"}))
+			return "", "", 0, nil
+		}
+
+		_, _, err := orasCli.Push(&cliwrappers.OrasPushArgs{
+			DestinationImage: artifactImage,
+			Files: []cliwrappers.OrasPushFile{
+				{Path: "model.gguf", MediaType: "application/vnd.gguf"},
+				{Path: "LICENSE"},
+			},
+		})
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	t.Run("push with annotations and absolute paths", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Args).Should(Equal([]string{
+				"push", "--annotation", "org.opencontainers.image.source=https://github.com/org/app", "--annotation", "a=b",
+				"--disable-path-validation", artifactImage, "/work/report.json",
+			}))
+			return "", "", 0, nil
+		}
+
+		_, _, err := orasCli.Push(&cliwrappers.OrasPushArgs{
+			DestinationImage:      artifactImage,
+			Files:                 []cliwrappers.OrasPushFile{{Path: "/work/report.json"}},
+			Annotations:           []string{"org.opencontainers.image.source=https://github.com/org/app", "a=b"},
+			DisablePathValidation: true,
+		})
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+	})
+
 	t.Run("push with authentication", func(t *testing.T) {
 		orasCli, executor := setupOrasCli()
 
@@ -147,3 +188,288 @@ func TestOrasCli_Push(t *testing.T) {
 		g.Expect(stderr).Should(Equal(""))
 	})
 }
+
+func TestOrasCli_Push_retries(t *testing.T) {
+	g := NewWithT(t)
+
+	const artifactImage = "reg.io/org/app:source"
+
+	t.Run("should pass concurrency and retry failed push", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+		cliwrappers.DisableRetryer = false
+		defer func() { cliwrappers.DisableRetryer = true }()
+
+		attempts := 0
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Args).Should(Equal([]string{"push", "--concurrency", "2", artifactImage, "source.tar.gz"}))
+			attempts++
+			if attempts == 1 {
+				return "", "connection reset by peer", 1, errors.New("exit status 1")
+			}
+			return "Pushed", "", 0, nil
+		}
+
+		stdout, _, err := orasCli.Push(&cliwrappers.OrasPushArgs{
+			DestinationImage: artifactImage,
+			FileName:         "source.tar.gz",
+			Concurrency:      2,
+			Retries:          2,
+		})
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(stdout).Should(Equal("Pushed"))
+		g.Expect(attempts).Should(Equal(2))
+	})
+
+	t.Run("should use registry retry settings without retries", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+		cliwrappers.DisableRetryer = false
+		defer func() { cliwrappers.DisableRetryer = true }()
+		g.Expect(cliwrappers.SetRegistryRetryOptions(3, 0)).To(Succeed())
+		defer cliwrappers.ExportResetRegistryRetryOptions()
+
+		attempts := 0
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			attempts++
+			return "", "connection reset by peer", 1, errors.New("exit status 1")
+		}
+
+		_, _, err := orasCli.Push(&cliwrappers.OrasPushArgs{DestinationImage: artifactImage, FileName: "source.tar.gz"})
+
+		g.Expect(err).Should(HaveOccurred())
+		g.Expect(attempts).Should(Equal(3))
+	})
+
+	t.Run("should not retry on authorization errors", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+		cliwrappers.DisableRetryer = false
+		defer func() { cliwrappers.DisableRetryer = true }()
+
+		attempts := 0
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			attempts++
+			return "", "Error: failed to push: PUT \"https://reg.io/v2/org/app/blobs/uploads/\": response status code 403: Forbidden", 1, errors.New("exit status 1")
+		}
+
+		_, _, err := orasCli.Push(&cliwrappers.OrasPushArgs{DestinationImage: artifactImage, FileName: "source.tar.gz", Retries: 5})
+
+		g.Expect(err).Should(HaveOccurred())
+		g.Expect(attempts).Should(Equal(1))
+	})
+}
+
+func TestOrasCli_Attach(t *testing.T) {
+	g := NewWithT(t)
+
+	const subject = "reg.io/org/app@sha256:4d6addf62a90e392ff6d3f470259eb5667eab5b9a8e03d20b41d0ab910f92170"
+
+	t.Run("should attach files to the subject", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).Should(Equal("oras"))
+			g.Expect(cmd.Args).Should(Equal([]string{
+				"attach", "--artifact-type", "application/spdx+json",
+				"--registry-config", "/tmp/auth.json",
+				"--format", "go-template", "--template", "{{.reference}}",
+				subject, "sbom.json:application/spdx+json",
+			}))
+			return "reg.io/org/app@sha256:1234", "", 0, nil
+		}
+
+		stdout, _, err := orasCli.Attach(&cliwrappers.OrasAttachArgs{
+			Subject:        subject,
+			Files:          []cliwrappers.OrasPushFile{{Path: "sbom.json", MediaType: "application/spdx+json"}},
+			ArtifactType:   "application/spdx+json",
+			RegistryConfig: "/tmp/auth.json",
+			Format:         "go-template",
+			Template:       "{{.reference}}",
+		})
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(stdout).Should(Equal("reg.io/org/app@sha256:1234"))
+	})
+
+	t.Run("should attach with annotations", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Args).Should(Equal([]string{
+				"attach", "--artifact-type", "application/vnd.org.report", "--annotation", "a=b", subject, "report.json",
+			}))
+			return "", "", 0, nil
+		}
+
+		_, _, err := orasCli.Attach(&cliwrappers.OrasAttachArgs{
+			Subject:      subject,
+			Files:        []cliwrappers.OrasPushFile{{Path: "report.json"}},
+			ArtifactType: "application/vnd.org.report",
+			Annotations:  []string{"a=b"},
+		})
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	t.Run("should return error on missing arguments", func(t *testing.T) {
+		orasCli, _ := setupOrasCli()
+		files := []cliwrappers.OrasPushFile{{Path: "sbom.json"}}
+
+		_, _, err := orasCli.Attach(&cliwrappers.OrasAttachArgs{Files: files, ArtifactType: "a"})
+		g.Expect(err).Should(MatchError("subject arg is empty"))
+
+		_, _, err = orasCli.Attach(&cliwrappers.OrasAttachArgs{Subject: subject, ArtifactType: "a"})
+		g.Expect(err).Should(MatchError("files arg is empty"))
+
+		_, _, err = orasCli.Attach(&cliwrappers.OrasAttachArgs{Subject: subject, Files: files})
+		g.Expect(err).Should(MatchError("artifact type arg is empty"))
+	})
+
+	t.Run("should return error when attach fails", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "", 1, errors.New("attach failed")
+		}
+
+		_, _, err := orasCli.Attach(&cliwrappers.OrasAttachArgs{
+			Subject:      subject,
+			Files:        []cliwrappers.OrasPushFile{{Path: "sbom.json"}},
+			ArtifactType: "application/spdx+json",
+		})
+		g.Expect(err).Should(HaveOccurred())
+	})
+}
+
+func TestOrasCli_Discover(t *testing.T) {
+	g := NewWithT(t)
+
+	const subject = "reg.io/org/app@sha256:4d6addf62a90e392ff6d3f470259eb5667eab5b9a8e03d20b41d0ab910f92170"
+
+	t.Run("should list the referrers", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).Should(Equal("oras"))
+			g.Expect(cmd.Args).Should(Equal([]string{
+				"discover", "--format", "json", "--artifact-type", "application/spdx+json",
+				"--registry-config", "/tmp/auth.json", subject,
+			}))
+			return `{"manifests":[{"digest":"sha256:1111","mediaType":"application/vnd.oci.image.manifest.v1+json","artifactType":"application/spdx+json"}]}`, "", 0, nil
+		}
+
+		referrers, err := orasCli.Discover(&cliwrappers.OrasDiscoverArgs{
+			Subject:        subject,
+			ArtifactType:   "application/spdx+json",
+			RegistryConfig: "/tmp/auth.json",
+		})
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(referrers).Should(Equal([]cliwrappers.OrasReferrer{{
+			Digest:       "sha256:1111",
+			MediaType:    "application/vnd.oci.image.manifest.v1+json",
+			ArtifactType: "application/spdx+json",
+		}}))
+	})
+
+	t.Run("should parse the referrers output of newer oras versions", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return `{"reference":"` + subject + `","referrers":[{"digest":"sha256:2222","artifactType":"application/vnd.cyclonedx+json"}]}`, "", 0, nil
+		}
+
+		referrers, err := orasCli.Discover(&cliwrappers.OrasDiscoverArgs{Subject: subject})
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(referrers).Should(Equal([]cliwrappers.OrasReferrer{{Digest: "sha256:2222", ArtifactType: "application/vnd.cyclonedx+json"}}))
+	})
+
+	t.Run("should fail without subject", func(t *testing.T) {
+		orasCli, _ := setupOrasCli()
+		_, err := orasCli.Discover(&cliwrappers.OrasDiscoverArgs{})
+		g.Expect(err).Should(MatchError("subject arg is empty"))
+	})
+
+	t.Run("should fail on invalid output", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "not json", "", 0, nil
+		}
+
+		_, err := orasCli.Discover(&cliwrappers.OrasDiscoverArgs{Subject: subject})
+		g.Expect(err).Should(MatchError(ContainSubstring("parsing oras discover output")))
+	})
+}
+
+func TestOrasCli_ManifestFetch(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should return the raw manifest", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Args).Should(Equal([]string{"manifest", "fetch", "--registry-config", "/tmp/auth.json", "reg.io/org/app:v1"}))
+			return `{"schemaVersion":2}`, "", 0, nil
+		}
+
+		manifest, err := orasCli.ManifestFetch(&cliwrappers.OrasManifestFetchArgs{
+			Image:          "reg.io/org/app:v1",
+			RegistryConfig: "/tmp/auth.json",
+		})
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(manifest).Should(Equal(`{"schemaVersion":2}`))
+	})
+
+	t.Run("should fail without image", func(t *testing.T) {
+		orasCli, _ := setupOrasCli()
+		_, err := orasCli.ManifestFetch(&cliwrappers.OrasManifestFetchArgs{})
+		g.Expect(err).Should(MatchError("image arg is empty"))
+	})
+
+	t.Run("should return the command error", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "Error: reg.io/org/app:v1: not found", 1, errors.New("exit status 1")
+		}
+
+		_, err := orasCli.ManifestFetch(&cliwrappers.OrasManifestFetchArgs{Image: "reg.io/org/app:v1"})
+		g.Expect(err).Should(MatchError("exit status 1: Error: reg.io/org/app:v1: not found"))
+		g.Expect(cliwrappers.IsOrasNotFound(err)).Should(BeTrue())
+	})
+
+	t.Run("should not report other errors as not found", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "Error: unauthorized", 1, errors.New("exit status 1")
+		}
+
+		_, err := orasCli.ManifestFetch(&cliwrappers.OrasManifestFetchArgs{Image: "reg.io/org/app:v1"})
+		g.Expect(cliwrappers.IsOrasNotFound(err)).Should(BeFalse())
+	})
+}
+
+func TestOrasCli_BlobFetch(t *testing.T) {
+	g := NewWithT(t)
+
+	const blob = "reg.io/org/app@sha256:4d6addf62a90e392ff6d3f470259eb5667eab5b9a8e03d20b41d0ab910f92170"
+
+	t.Run("should download the blob", func(t *testing.T) {
+		orasCli, executor := setupOrasCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Args).Should(Equal([]string{"blob", "fetch", "--output", "/tmp/sbom.json", "--registry-config", "/tmp/auth.json", blob}))
+			return "", "", 0, nil
+		}
+
+		err := orasCli.BlobFetch(&cliwrappers.OrasBlobFetchArgs{
+			Blob:           blob,
+			OutputPath:     "/tmp/sbom.json",
+			RegistryConfig: "/tmp/auth.json",
+		})
+
+		g.Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	t.Run("should fail without blob or output path", func(t *testing.T) {
+		orasCli, _ := setupOrasCli()
+		g.Expect(orasCli.BlobFetch(&cliwrappers.OrasBlobFetchArgs{OutputPath: "/tmp/sbom.json"})).Should(MatchError("blob arg is empty"))
+		g.Expect(orasCli.BlobFetch(&cliwrappers.OrasBlobFetchArgs{Blob: blob})).Should(MatchError("output path arg is empty"))
+	})
+}
@@ -0,0 +1,191 @@
+package cliwrappers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var podmanLog = l.Logger.WithField("logger", "PodmanCli")
+
+// PodmanCli is a BuildEngine running podman, for environments where buildah is not installed.
+// podman builds with buildah internally, its build options are the same.
+type PodmanCli struct {
+	Executor CliExecutorInterface
+}
+
+func NewPodmanCli(executor CliExecutorInterface) (*PodmanCli, error) {
+	podmanCliAvailable, err := CheckCliToolAvailable("podman")
+	if err != nil {
+		return nil, err
+	}
+	if !podmanCliAvailable {
+		return nil, &kbcerrors.ToolMissingError{Tool: "podman"}
+	}
+
+	return &PodmanCli{
+		Executor: executor,
+	}, nil
+}
+
+// Build the image with podman build. The buildah-only options SaveStages and StageLabels are not supported.
+func (p *PodmanCli) Build(args *BuildahBuildArgs) error {
+	if err := args.Validate(); err != nil {
+		return fmt.Errorf("validating podman args: %w", err)
+	}
+	if args.SaveStages || args.StageLabels {
+		return errors.New("saving the stages is not supported by podman build")
+	}
+
+	podmanArgs := args.buildCommandArgs()
+	executable := "podman"
+	if args.Wrapper != nil {
+		executable, podmanArgs = args.Wrapper.Wrap(executable, podmanArgs)
+	}
+
+	podmanLog.Debugf("Running command:\n%s", shellJoin(executable, podmanArgs...))
+
+	ctx, cancel := contextWithTimeout("podman build", args.Timeout)
+	defer cancel()
+
+	_, _, _, err := p.Executor.ExecuteContext(ctx, Cmd{
+		Name: executable, Args: podmanArgs,
+		NameInLogs: "podman", LogOutput: true,
+	})
+	if err != nil {
+		podmanLog.Errorf("podman build failed: %s", err.Error())
+		return err
+	}
+
+	podmanLog.Debug("Build completed successfully")
+
+	return nil
+}
+
+// Push an image from local storage to the registry. Return the digest of the pushed manifest.
+func (p *PodmanCli) Push(args *BuildahPushArgs) (string, error) {
+	if args.Image == "" {
+		return "", errors.New("image arg is empty")
+	}
+
+	tmpFile, err := os.CreateTemp("", "podman-digest-")
+	if err != nil {
+		return "", err
+	}
+	digestFile := tmpFile.Name()
+	if err := tmpFile.Close(); err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(digestFile) }()
+
+	podmanArgs := []string{"push", "--digestfile", digestFile}
+	if args.TLSVerify != nil {
+		podmanArgs = append(podmanArgs, fmt.Sprintf("--tls-verify=%t", *args.TLSVerify))
+	}
+	if args.CertDir != "" {
+		podmanArgs = append(podmanArgs, "--cert-dir", args.CertDir)
+	}
+	switch args.Format {
+	case "":
+	case "docker":
+		// podman names the formats after the manifest schemas
+		podmanArgs = append(podmanArgs, "--format", "v2s2")
+	default:
+		podmanArgs = append(podmanArgs, "--format", args.Format)
+	}
+	podmanArgs = append(podmanArgs, args.Image)
+	if args.Destination != "" {
+		podmanArgs = append(podmanArgs, args.Destination)
+	}
+
+	podmanLog.Debugf("Running command:\n%s", shellJoin("podman", podmanArgs...))
+
+	retryer := NewRetryer(func() (string, string, int, error) {
+		ctx, cancel := contextWithTimeout("podman push", args.Timeout)
+		defer cancel()
+		return p.Executor.ExecuteContext(ctx, Cmd{Name: "podman", Args: podmanArgs, LogOutput: true})
+	}).WithImageRegistryPreset()
+
+	_, _, _, err = retryer.Run()
+	if err != nil {
+		podmanLog.Errorf("podman push failed: %s", err.Error())
+		return "", err
+	}
+
+	podmanLog.Debug("Push completed successfully")
+
+	content, err := os.ReadFile(digestFile) //nolint:gosec // digestFile is a controlled temp file path
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// Pull an image from the registry to local storage.
+func (p *PodmanCli) Pull(args *BuildahPullArgs) error {
+	if args.Image == "" {
+		return errors.New("image arg is empty")
+	}
+
+	podmanArgs := []string{"pull"}
+	if args.Platform != "" {
+		podmanArgs = append(podmanArgs, "--platform", args.Platform)
+	}
+	if args.TLSVerify != nil {
+		podmanArgs = append(podmanArgs, fmt.Sprintf("--tls-verify=%t", *args.TLSVerify))
+	}
+	if args.CertDir != "" {
+		podmanArgs = append(podmanArgs, "--cert-dir", args.CertDir)
+	}
+	podmanArgs = append(podmanArgs, args.Image)
+
+	podmanLog.Debugf("Running command:\n%s", shellJoin("podman", podmanArgs...))
+
+	cmd := Cmd{Name: "podman", Args: podmanArgs, LogOutput: true}
+	env := slices.Concat(args.ExtraEnv, common.ProxyEnvVars(args.HttpProxy, args.NoProxy))
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	retryer := NewRetryer(func() (string, string, int, error) {
+		return p.Executor.Execute(cmd)
+	}).WithImageRegistryPreset().
+		StopIfOutputContains("no image found in image index for architecture")
+
+	_, _, _, err := retryer.Run()
+	if err != nil {
+		podmanLog.Errorf("podman pull failed: %s", err.Error())
+		return err
+	}
+
+	podmanLog.Debug("Pull completed successfully")
+
+	return nil
+}
+
+func (p *PodmanCli) InspectImage(name string) (BuildahImageInfo, error) {
+	if name == "" {
+		return BuildahImageInfo{}, errors.New("name is empty")
+	}
+
+	podmanArgs := []string{"image", "inspect", name}
+	podmanLog.Debugf("Running command:\n%s", shellJoin("podman", podmanArgs...))
+
+	stdout, stderr, _, err := p.Executor.Execute(Command("podman", podmanArgs...))
+	if err != nil {
+		podmanLog.Errorf("podman image inspect failed: %s", err.Error())
+		if stderr != "" {
+			podmanLog.Errorf("stderr:\n%s", stderr)
+		}
+		return BuildahImageInfo{}, err
+	}
+
+	return parseImageInspect(stdout)
+}
@@ -0,0 +1,175 @@
+package cliwrappers_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func setupPodmanCli() (*cliwrappers.PodmanCli, *mockExecutor) {
+	executor := &mockExecutor{}
+	podmanCli := &cliwrappers.PodmanCli{Executor: executor}
+	return podmanCli, executor
+}
+
+// The same inspect output format for podman and docker
+const imageInspectOutput = `[
+  {
+    "Id": "586ab46b9d6d906b2df3dad12751e807bd0f0632d5a2ab3991bdac78bdccd59a",
+    "RepoTags": ["registry.access.redhat.com/ubi9/ubi:latest"],
+    "Architecture": "arm64",
+    "Variant": "v8",
+    "Os": "linux",
+    "Config": {
+      "Env": ["PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"],
+      "Cmd": ["/bin/bash"],
+      "Labels": {"name": "ubi9", "vendor": "Red Hat, Inc."}
+    }
+  }
+]`
+
+func TestPodmanCli_Build(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should build with the buildah build options", func(t *testing.T) {
+		podmanCli, executor := setupPodmanCli()
+		var capturedCmd cliwrappers.Cmd
+		executor.executeContextFunc = func(ctx context.Context, cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			return "", "", 0, nil
+		}
+
+		err := podmanCli.Build(&cliwrappers.BuildahBuildArgs{
+			Containerfile: "/src/Containerfile",
+			ContextDir:    "/src",
+			Tags:          []string{"quay.io/org/app:v1"},
+			Secrets:       []cliwrappers.BuildahSecret{{Src: "/secrets/token", Id: "token"}},
+			BuildArgs:     []string{"VERSION=1"},
+			TLSVerify:     boolPtr(true),
+			Format:        "docker",
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedCmd.Name).To(Equal("podman"))
+		g.Expect(capturedCmd.Args).To(Equal([]string{
+			"build", "--file", "/src/Containerfile", "--tag", "quay.io/org/app:v1",
+			"--secret=src=/secrets/token,id=token", "--build-arg=VERSION=1", "--tls-verify=true", "--format=docker", "/src",
+		}))
+		g.Expect(capturedCmd.LogOutput).To(BeTrue())
+	})
+
+	t.Run("should reject saving the stages", func(t *testing.T) {
+		podmanCli, _ := setupPodmanCli()
+
+		err := podmanCli.Build(&cliwrappers.BuildahBuildArgs{
+			Containerfile: "/src/Containerfile",
+			ContextDir:    "/src",
+			Tags:          []string{"quay.io/org/app:v1"},
+			SaveStages:    true,
+		})
+
+		g.Expect(err).To(MatchError("saving the stages is not supported by podman build"))
+	})
+}
+
+func TestPodmanCli_Push(t *testing.T) {
+	g := NewWithT(t)
+	ensureRetryerDisabled(t)
+
+	const digest = "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+	t.Run("should push image and read the digest", func(t *testing.T) {
+		podmanCli, executor := setupPodmanCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).To(Equal("podman"))
+			capturedArgs = cmd.Args
+			g.Expect(os.WriteFile(findDigestFile(cmd.Args), []byte(digest+"\n"), 0644)).To(Succeed())
+			return "", "", 0, nil
+		}
+
+		returnedDigest, err := podmanCli.Push(&cliwrappers.BuildahPushArgs{
+			Image:     "quay.io/org/app:v1",
+			TLSVerify: boolPtr(false),
+			CertDir:   "/certs",
+			Format:    "docker",
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(returnedDigest).To(Equal(digest))
+		g.Expect(capturedArgs[0]).To(Equal("push"))
+		g.Expect(capturedArgs[3:]).To(Equal([]string{"--tls-verify=false", "--cert-dir", "/certs", "--format", "v2s2", "quay.io/org/app:v1"}))
+	})
+
+	t.Run("should error if podman execution fails", func(t *testing.T) {
+		podmanCli, executor := setupPodmanCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "", 1, errors.New("exit status 1")
+		}
+
+		_, err := podmanCli.Push(&cliwrappers.BuildahPushArgs{Image: "quay.io/org/app:v1"})
+
+		g.Expect(err).To(MatchError("exit status 1"))
+	})
+}
+
+func TestPodmanCli_Pull(t *testing.T) {
+	g := NewWithT(t)
+	ensureRetryerDisabled(t)
+
+	podmanCli, executor := setupPodmanCli()
+	var capturedCmd cliwrappers.Cmd
+	executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+		capturedCmd = cmd
+		return "", "", 0, nil
+	}
+
+	err := podmanCli.Pull(&cliwrappers.BuildahPullArgs{
+		Image:     "registry.access.redhat.com/ubi9/ubi:latest",
+		Platform:  "linux/arm64",
+		HttpProxy: "proxy.example.com:3128",
+	})
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(capturedCmd.Args).To(Equal([]string{"pull", "--platform", "linux/arm64", "registry.access.redhat.com/ubi9/ubi:latest"}))
+	g.Expect(collectEnv(capturedCmd.Env)).To(HaveKeyWithValue("HTTPS_PROXY", "proxy.example.com:3128"))
+}
+
+func TestPodmanCli_InspectImage(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should parse the inspect output", func(t *testing.T) {
+		podmanCli, executor := setupPodmanCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return imageInspectOutput, "", 0, nil
+		}
+
+		info, err := podmanCli.InspectImage("registry.access.redhat.com/ubi9/ubi:latest")
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"image", "inspect", "registry.access.redhat.com/ubi9/ubi:latest"}))
+		g.Expect(info.OCIv1.Architecture).To(Equal("arm64"))
+		g.Expect(info.OCIv1.Variant).To(Equal("v8"))
+		g.Expect(info.OCIv1.OS).To(Equal("linux"))
+		g.Expect(info.OCIv1.Config.Labels).To(Equal(map[string]string{"name": "ubi9", "vendor": "Red Hat, Inc."}))
+		g.Expect(info.OCIv1.Config.Cmd).To(Equal([]string{"/bin/bash"}))
+	})
+
+	t.Run("should error if no image matches", func(t *testing.T) {
+		podmanCli, executor := setupPodmanCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "[]", "", 0, nil
+		}
+
+		_, err := podmanCli.InspectImage("missing")
+
+		g.Expect(err).To(MatchError("expected one image in inspect output, found 0"))
+	})
+}
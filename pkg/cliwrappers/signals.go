@@ -0,0 +1,93 @@
+package cliwrappers
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// ErrTerminating is returned for commands that were not started because the CLI is shutting down.
+var ErrTerminating = errors.New("not starting command, received termination signal")
+
+var terminating atomic.Bool
+
+// The child processes currently run by the executor, termination signals get forwarded to them.
+var childProcesses = struct {
+	sync.Mutex
+	procs map[*os.Process]struct{}
+}{procs: make(map[*os.Process]struct{})}
+
+// Terminating reports whether the CLI received a termination signal (SIGTERM or SIGINT).
+func Terminating() bool {
+	return terminating.Load()
+}
+
+// HandleTerminationSignals installs handlers for SIGTERM and SIGINT, e.g. sent when a TaskRun
+// is cancelled. Instead of exiting immediately, the signal is forwarded to the running child
+// processes so that they can stop cleanly (e.g. buildah releasing its storage locks), and
+// no new commands or retries are started. The running command then fails with the error
+// of the interrupted child and cleans up on the way out.
+//
+// Returns a function that restores the default signal handling.
+func HandleTerminationSignals() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				terminating.Store(true)
+				l.Logger.Warnf("Received %s, stopping the running commands", sig)
+				forwardSignal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+func forwardSignal(sig os.Signal) {
+	childProcesses.Lock()
+	defer childProcesses.Unlock()
+	for proc := range childProcesses.procs {
+		if err := proc.Signal(sig); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			l.Logger.Warnf("Failed to forward %s to process %d: %s", sig, proc.Pid, err)
+		}
+	}
+}
+
+// Start the command and track its process until it's waited for.
+func startTracked(cmd *exec.Cmd) (wait func() error, err error) {
+	// Checked under the lock, so that a process can't start after the signal got forwarded
+	childProcesses.Lock()
+	defer childProcesses.Unlock()
+	if terminating.Load() {
+		return nil, ErrTerminating
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	proc := cmd.Process
+	childProcesses.procs[proc] = struct{}{}
+
+	return func() error {
+		err := cmd.Wait()
+		childProcesses.Lock()
+		delete(childProcesses.procs, proc)
+		childProcesses.Unlock()
+		return err
+	}, nil
+}
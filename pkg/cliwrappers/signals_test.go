@@ -0,0 +1,48 @@
+package cliwrappers_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func TestHandleTerminationSignals(t *testing.T) {
+	g := NewWithT(t)
+
+	stop := cliwrappers.HandleTerminationSignals()
+	defer stop()
+	defer cliwrappers.ExportResetTerminating()
+
+	executor := cliwrappers.NewCliExecutor()
+	type result struct {
+		exitCode int
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		_, _, exitCode, err := executor.Execute(cliwrappers.Command("sleep", "30"))
+		done <- result{exitCode, err}
+	}()
+
+	// Give the child process time to start
+	time.Sleep(200 * time.Millisecond)
+	g.Expect(syscall.Kill(os.Getpid(), syscall.SIGTERM)).To(Succeed())
+
+	select {
+	case r := <-done:
+		g.Expect(r.err).To(HaveOccurred())
+		g.Expect(r.err.Error()).To(ContainSubstring("terminated"))
+	case <-time.After(10 * time.Second):
+		t.Fatal("the child process did not receive the forwarded signal")
+	}
+	g.Expect(cliwrappers.Terminating()).To(BeTrue())
+
+	// No new commands start after the signal
+	_, _, _, err := executor.Execute(cliwrappers.Command("true"))
+	g.Expect(err).To(MatchError(cliwrappers.ErrTerminating))
+}
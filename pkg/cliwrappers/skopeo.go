@@ -1,10 +1,14 @@
 package cliwrappers
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -14,9 +18,28 @@ const (
 
 var skopeoLog = l.Logger.WithField("logger", "ScopeoCli")
 
+// Registry errors meaning that the image or its repository doesn't exist
+var imageNotFoundErrors = []string{"manifest unknown", "name unknown"}
+
+// IsImageNotFound reports whether the error returned by Inspect means that the image doesn't exist.
+func IsImageNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, notFound := range imageNotFoundErrors {
+		if strings.Contains(err.Error(), notFound) {
+			return true
+		}
+	}
+	return false
+}
+
 type SkopeoCliInterface interface {
 	Copy(args *SkopeoCopyArgs) error
+	CopyAll(args *SkopeoCopyArgs) error
 	Inspect(args *SkopeoInspectArgs) (string, error)
+	ListTags(args *SkopeoListTagsArgs) ([]string, error)
+	Delete(args *SkopeoDeleteArgs) error
 }
 
 var _ SkopeoCliInterface = &SkopeoCli{}
@@ -31,7 +54,7 @@ func NewSkopeoCli(executor CliExecutorInterface) (*SkopeoCli, error) {
 		return nil, err
 	}
 	if !skopeoCliAvailable {
-		return nil, errors.New("skopeo CLI is not available")
+		return nil, &kbcerrors.ToolMissingError{Tool: "skopeo"}
 	}
 
 	return &SkopeoCli{
@@ -47,12 +70,35 @@ const (
 	SkopeoCopyArgMultiArchIndexOnly SkopeoCopyArgMultiArch = "index-only"
 )
 
+type SkopeoCopyArgFormat string
+
+const (
+	SkopeoCopyArgFormatOCI  SkopeoCopyArgFormat = "oci"
+	SkopeoCopyArgFormatV2s2 SkopeoCopyArgFormat = "v2s2"
+)
+
 type SkopeoCopyArgs struct {
-	SourceImage      string
+	// Registry image reference, or a reference with an explicit transport (e.g. oci:/path/to/layout:tag).
+	SourceImage string
+	// Registry image reference, or a reference with an explicit transport (e.g. oci:/path/to/layout:tag).
 	DestinationImage string
 	MultiArch        SkopeoCopyArgMultiArch
-	RetryTimes       int
-	ExtraArgs        []string
+	// Manifest type to convert the image to. Keeps the source format if empty.
+	Format SkopeoCopyArgFormat
+	// Write the digest of the pushed manifest into this file.
+	DigestFile string
+	// Require HTTPS and verify certificates when accessing the source and destination registries.
+	// Skopeo's default (true) applies if nil.
+	TLSVerify *bool
+	// Directory with the certificates for accessing the source and destination registries
+	CertDir string
+	// Auth files for the source and destination registries, instead of the default one
+	SourceAuthFile      string
+	DestinationAuthFile string
+	// Fail instead of changing the digests of the copied manifests, e.g. by compressing the layers differently
+	PreserveDigests bool
+	RetryTimes      int
+	ExtraArgs       []string
 }
 
 func (s *SkopeoCli) Copy(args *SkopeoCopyArgs) error {
@@ -68,6 +114,28 @@ func (s *SkopeoCli) Copy(args *SkopeoCopyArgs) error {
 	if args.MultiArch != "" {
 		scopeoArgs = append(scopeoArgs, "--multi-arch", string(args.MultiArch))
 	}
+	if args.Format != "" {
+		scopeoArgs = append(scopeoArgs, "--format", string(args.Format))
+	}
+	if args.DigestFile != "" {
+		scopeoArgs = append(scopeoArgs, "--digestfile", args.DigestFile)
+	}
+	if args.TLSVerify != nil {
+		scopeoArgs = append(scopeoArgs,
+			fmt.Sprintf("--src-tls-verify=%t", *args.TLSVerify), fmt.Sprintf("--dest-tls-verify=%t", *args.TLSVerify))
+	}
+	if args.CertDir != "" {
+		scopeoArgs = append(scopeoArgs, "--src-cert-dir", args.CertDir, "--dest-cert-dir", args.CertDir)
+	}
+	if args.SourceAuthFile != "" {
+		scopeoArgs = append(scopeoArgs, "--src-authfile", args.SourceAuthFile)
+	}
+	if args.DestinationAuthFile != "" {
+		scopeoArgs = append(scopeoArgs, "--dest-authfile", args.DestinationAuthFile)
+	}
+	if args.PreserveDigests {
+		scopeoArgs = append(scopeoArgs, "--preserve-digests")
+	}
 	if args.RetryTimes != 0 {
 		scopeoArgs = append(scopeoArgs, "--retry-times", strconv.Itoa(args.RetryTimes))
 	}
@@ -77,13 +145,21 @@ func (s *SkopeoCli) Copy(args *SkopeoCopyArgs) error {
 	}
 
 	dockerPrefix := "docker://"
-	scopeoArgs = append(scopeoArgs, dockerPrefix+args.SourceImage, dockerPrefix+args.DestinationImage)
+	source := args.SourceImage
+	if transport, _ := common.SplitImageTransport(source); transport == "" {
+		source = dockerPrefix + source
+	}
+	destination := args.DestinationImage
+	if transport, _ := common.SplitImageTransport(destination); transport == "" {
+		destination = dockerPrefix + destination
+	}
+	scopeoArgs = append(scopeoArgs, source, destination)
 
 	skopeoLog.Debugf("Running command:\n%s", shellJoin("skopeo", scopeoArgs...))
 
 	retryer := NewRetryer(func() (string, string, int, error) {
 		return s.Executor.Execute(Command("skopeo", scopeoArgs...))
-	}).WithImageRegistryPreset().StopIfOutputContains("unauthorized")
+	}).WithImageRegistryPreset()
 
 	stdout, stderr, _, err := retryer.Run()
 	if err != nil {
@@ -99,13 +175,28 @@ func (s *SkopeoCli) Copy(args *SkopeoCopyArgs) error {
 	return nil
 }
 
+// CopyAll copies the image with the images of all the platforms, regardless of args.MultiArch.
+func (s *SkopeoCli) CopyAll(args *SkopeoCopyArgs) error {
+	allArgs := *args
+	allArgs.MultiArch = SkopeoCopyArgMultiArchAll
+	return s.Copy(&allArgs)
+}
+
 type SkopeoInspectArgs struct {
 	ImageRef   string
 	RetryTimes int
 	Raw        bool
-	NoTags     bool
-	Format     string
-	ExtraArgs  []string
+	// Output the image config instead of the manifest
+	Config    bool
+	NoTags    bool
+	Format    string
+	TLSVerify *bool
+	CertDir   string
+	// Auth file for the registry, instead of the default one
+	AuthFile  string
+	ExtraArgs []string
+	// Don't retry if the image doesn't exist, for checking whether it exists
+	NoRetryIfNotFound bool
 }
 
 func (s *SkopeoCli) Inspect(args *SkopeoInspectArgs) (string, error) {
@@ -121,12 +212,24 @@ func (s *SkopeoCli) Inspect(args *SkopeoInspectArgs) (string, error) {
 	if args.Raw {
 		scopeoArgs = append(scopeoArgs, "--raw")
 	}
+	if args.Config {
+		scopeoArgs = append(scopeoArgs, "--config")
+	}
 	if args.NoTags {
 		scopeoArgs = append(scopeoArgs, "--no-tags")
 	}
 	if args.Format != "" {
 		scopeoArgs = append(scopeoArgs, "--format", args.Format)
 	}
+	if args.TLSVerify != nil {
+		scopeoArgs = append(scopeoArgs, fmt.Sprintf("--tls-verify=%t", *args.TLSVerify))
+	}
+	if args.CertDir != "" {
+		scopeoArgs = append(scopeoArgs, "--cert-dir", args.CertDir)
+	}
+	if args.AuthFile != "" {
+		scopeoArgs = append(scopeoArgs, "--authfile", args.AuthFile)
+	}
 
 	if len(args.ExtraArgs) != 0 {
 		scopeoArgs = append(scopeoArgs, args.ExtraArgs...)
@@ -140,9 +243,13 @@ func (s *SkopeoCli) Inspect(args *SkopeoInspectArgs) (string, error) {
 	retryer := NewRetryer(func() (string, string, int, error) {
 		return s.Executor.Execute(Command("skopeo", scopeoArgs...))
 	}).WithImageRegistryPreset().
-		StopIfOutputContains("unauthorized").
 		// Stop on unsupported config media type
 		StopIfOutputContains(UnsupportedOCIConfigMediaType)
+	if args.NoRetryIfNotFound {
+		for _, notFound := range imageNotFoundErrors {
+			retryer.StopIfOutputContains(notFound)
+		}
+	}
 
 	stdout, stderr, _, err := retryer.Run()
 	if err != nil {
@@ -157,3 +264,127 @@ func (s *SkopeoCli) Inspect(args *SkopeoInspectArgs) (string, error) {
 
 	return stdout, nil
 }
+
+type SkopeoListTagsArgs struct {
+	// Registry repository without a tag or digest, e.g. quay.io/org/repo
+	Repository string
+	RetryTimes int
+	TLSVerify  *bool
+	CertDir    string
+	ExtraArgs  []string
+}
+
+type skopeoListTagsOutput struct {
+	Repository string   `json:"Repository"`
+	Tags       []string `json:"Tags"`
+}
+
+// ListTags returns the tags of a registry repository.
+func (s *SkopeoCli) ListTags(args *SkopeoListTagsArgs) ([]string, error) {
+	if args.Repository == "" {
+		return nil, errors.New("no repository to list tags of")
+	}
+
+	scopeoArgs := []string{"list-tags"}
+
+	if args.RetryTimes != 0 {
+		scopeoArgs = append(scopeoArgs, "--retry-times", strconv.Itoa(args.RetryTimes))
+	}
+	if args.TLSVerify != nil {
+		scopeoArgs = append(scopeoArgs, fmt.Sprintf("--tls-verify=%t", *args.TLSVerify))
+	}
+	if args.CertDir != "" {
+		scopeoArgs = append(scopeoArgs, "--cert-dir", args.CertDir)
+	}
+
+	if len(args.ExtraArgs) != 0 {
+		scopeoArgs = append(scopeoArgs, args.ExtraArgs...)
+	}
+
+	dockerPrefix := "docker://"
+	scopeoArgs = append(scopeoArgs, dockerPrefix+args.Repository)
+
+	skopeoLog.Debugf("Running command:\n%s", shellJoin("skopeo", scopeoArgs...))
+
+	retryer := NewRetryer(func() (string, string, int, error) {
+		return s.Executor.Execute(Command("skopeo", scopeoArgs...))
+	}).WithImageRegistryPreset()
+
+	stdout, stderr, _, err := retryer.Run()
+	if err != nil {
+		skopeoLog.Errorf("skopeo list-tags failed: %s", err.Error())
+		skopeoLog.Infof("[stdout]:\n%s", stdout)
+		skopeoLog.Infof("[stderr]:\n%s", stderr)
+		return nil, fmt.Errorf("%w: %s", err, stderr)
+	}
+
+	skopeoLog.Debug("[stderr]:\n" + stderr)
+
+	var output skopeoListTagsOutput
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		return nil, fmt.Errorf("parsing skopeo list-tags output: %w", err)
+	}
+	if output.Tags == nil {
+		return []string{}, nil
+	}
+	return output.Tags, nil
+}
+
+type SkopeoDeleteArgs struct {
+	// Registry image reference, by tag or digest
+	ImageRef   string
+	RetryTimes int
+	TLSVerify  *bool
+	CertDir    string
+	ExtraArgs  []string
+}
+
+// Delete deletes the image from the registry.
+// Registries delete the manifest, so all the tags pointing to it may be removed, not just the given one.
+func (s *SkopeoCli) Delete(args *SkopeoDeleteArgs) error {
+	if args.ImageRef == "" {
+		return errors.New("no image to delete")
+	}
+
+	scopeoArgs := []string{"delete"}
+
+	if args.RetryTimes != 0 {
+		scopeoArgs = append(scopeoArgs, "--retry-times", strconv.Itoa(args.RetryTimes))
+	}
+	if args.TLSVerify != nil {
+		scopeoArgs = append(scopeoArgs, fmt.Sprintf("--tls-verify=%t", *args.TLSVerify))
+	}
+	if args.CertDir != "" {
+		scopeoArgs = append(scopeoArgs, "--cert-dir", args.CertDir)
+	}
+
+	if len(args.ExtraArgs) != 0 {
+		scopeoArgs = append(scopeoArgs, args.ExtraArgs...)
+	}
+
+	dockerPrefix := "docker://"
+	scopeoArgs = append(scopeoArgs, dockerPrefix+args.ImageRef)
+
+	skopeoLog.Debugf("Running command:\n%s", shellJoin("skopeo", scopeoArgs...))
+
+	retryer := NewRetryer(func() (string, string, int, error) {
+		return s.Executor.Execute(Command("skopeo", scopeoArgs...))
+	}).WithImageRegistryPreset()
+	// The image is gone, retrying won't bring it back
+	for _, notFound := range imageNotFoundErrors {
+		retryer.StopIfOutputContains(notFound)
+	}
+
+	stdout, stderr, _, err := retryer.Run()
+	if err != nil {
+		skopeoLog.Errorf("skopeo delete failed: %s", err.Error())
+		skopeoLog.Infof("[stdout]:\n%s", stdout)
+		skopeoLog.Infof("[stderr]:\n%s", stderr)
+		return fmt.Errorf("%w: %s", err, stderr)
+	}
+
+	skopeoLog.Debug("[stdout]:\n" + stdout)
+	skopeoLog.Debug("[stderr]:\n" + stderr)
+
+	return nil
+}
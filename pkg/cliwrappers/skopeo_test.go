@@ -84,6 +84,31 @@ func TestSkopeoCli_Copy(t *testing.T) {
 		expectArgAndValue(g, capturedArgs, "--retry-times", strconv.Itoa(retryTimes))
 	})
 
+	t.Run("should copy tag with TLS options", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		tlsVerify := false
+		copyArgs := &cliwrappers.SkopeoCopyArgs{
+			SourceImage:      sourceImage,
+			DestinationImage: destinationImage,
+			TLSVerify:        &tlsVerify,
+			CertDir:          "/etc/certs",
+		}
+
+		err := skopeoCli.Copy(copyArgs)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(ContainElements("--src-tls-verify=false", "--dest-tls-verify=false"))
+		expectArgAndValue(g, capturedArgs, "--src-cert-dir", "/etc/certs")
+		expectArgAndValue(g, capturedArgs, "--dest-cert-dir", "/etc/certs")
+		g.Expect(capturedArgs[len(capturedArgs)-1]).To(Equal("docker://" + destinationImage))
+	})
+
 	t.Run("should copy tag with extra options", func(t *testing.T) {
 		skopeoCli, executor := setupSkopeoCli()
 		var capturedArgs []string
@@ -114,6 +139,64 @@ func TestSkopeoCli_Copy(t *testing.T) {
 		g.Expect(capturedArgs).To(ContainElement("--someflag"))
 	})
 
+	t.Run("should copy with format conversion and digest file", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		copyArgs := &cliwrappers.SkopeoCopyArgs{
+			SourceImage:      sourceImage,
+			DestinationImage: destinationImage,
+			Format:           cliwrappers.SkopeoCopyArgFormatV2s2,
+			DigestFile:       "/tmp/digest",
+		}
+
+		err := skopeoCli.Copy(copyArgs)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs[0]).To(Equal("copy"))
+		expectArgAndValue(g, capturedArgs, "--format", "v2s2")
+		expectArgAndValue(g, capturedArgs, "--digestfile", "/tmp/digest")
+		g.Expect(capturedArgs[len(capturedArgs)-1]).To(Equal("docker://" + destinationImage))
+	})
+
+	t.Run("should keep transport of destination", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := skopeoCli.Copy(&cliwrappers.SkopeoCopyArgs{
+			SourceImage:      sourceImage,
+			DestinationImage: "oci:/archive/layout:tag",
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"copy", "docker://" + sourceImage, "oci:/archive/layout:tag"}))
+	})
+
+	t.Run("should keep transport of source", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := skopeoCli.Copy(&cliwrappers.SkopeoCopyArgs{
+			SourceImage:      "oci:/archive/layout:tag",
+			DestinationImage: destinationImage,
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"copy", "oci:/archive/layout:tag", "docker://" + destinationImage}))
+	})
+
 	t.Run("should error if skopeo execution fails", func(t *testing.T) {
 		skopeoCli, executor := setupSkopeoCli()
 		isExecuteCalled := false
@@ -199,6 +282,7 @@ func TestSkopeoCli_Inspect(t *testing.T) {
 			ImageRef:   imageRef,
 			RetryTimes: retryTimes,
 			Raw:        raw,
+			Config:     true,
 			NoTags:     noTags,
 			Format:     format,
 		}
@@ -206,16 +290,40 @@ func TestSkopeoCli_Inspect(t *testing.T) {
 		stdout, err := skopeoCli.Inspect(inspectArgs)
 
 		g.Expect(err).ToNot(HaveOccurred())
-		g.Expect(capturedArgs).To(HaveLen(8))
+		g.Expect(capturedArgs).To(HaveLen(9))
 		g.Expect(capturedArgs[0]).To(Equal("inspect"))
 		g.Expect(capturedArgs[len(capturedArgs)-1]).To(Equal("docker://" + imageRef))
 		expectArgAndValue(g, capturedArgs, "--retry-times", strconv.Itoa(retryTimes))
 		expectArgAndValue(g, capturedArgs, "--format", format)
 		g.Expect(capturedArgs).To(ContainElement("--raw"))
+		g.Expect(capturedArgs).To(ContainElement("--config"))
 		g.Expect(capturedArgs).To(ContainElement("--no-tags"))
 		g.Expect(stdout).To(Equal(output))
 	})
 
+	t.Run("should inspect image with TLS options", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return output, "", 0, nil
+		}
+
+		tlsVerify := true
+		inspectArgs := &cliwrappers.SkopeoInspectArgs{
+			ImageRef:  imageRef,
+			TLSVerify: &tlsVerify,
+			CertDir:   "/etc/certs",
+		}
+
+		_, err := skopeoCli.Inspect(inspectArgs)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(ContainElement("--tls-verify=true"))
+		expectArgAndValue(g, capturedArgs, "--cert-dir", "/etc/certs")
+		g.Expect(capturedArgs[len(capturedArgs)-1]).To(Equal("docker://" + imageRef))
+	})
+
 	t.Run("should inspect image with extra options", func(t *testing.T) {
 		skopeoCli, executor := setupSkopeoCli()
 		var capturedArgs []string
@@ -276,3 +384,224 @@ func TestSkopeoCli_Inspect(t *testing.T) {
 		g.Expect(err).To(HaveOccurred())
 	})
 }
+
+func TestSkopeoCli_Inspect_notFound(t *testing.T) {
+	g := NewWithT(t)
+
+	const imageRef = "quay.io/org/app:v1"
+	const notFoundStderr = "reading manifest v1 in quay.io/org/app: manifest unknown"
+
+	t.Run("should not retry if the image doesn't exist", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		cliwrappers.DisableRetryer = false
+		defer func() { cliwrappers.DisableRetryer = true }()
+
+		attempts := 0
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			attempts++
+			return "", notFoundStderr, 1, errors.New("exit status 1")
+		}
+
+		_, err := skopeoCli.Inspect(&cliwrappers.SkopeoInspectArgs{ImageRef: imageRef, NoRetryIfNotFound: true})
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(cliwrappers.IsImageNotFound(err)).To(BeTrue())
+		g.Expect(attempts).To(Equal(1))
+	})
+
+	t.Run("should retry if the image doesn't exist by default", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		cliwrappers.DisableRetryer = false
+		defer func() { cliwrappers.DisableRetryer = true }()
+		g.Expect(cliwrappers.SetRegistryRetryOptions(2, 0)).To(Succeed())
+		defer cliwrappers.ExportResetRegistryRetryOptions()
+
+		attempts := 0
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			attempts++
+			return "", notFoundStderr, 1, errors.New("exit status 1")
+		}
+
+		_, err := skopeoCli.Inspect(&cliwrappers.SkopeoInspectArgs{ImageRef: imageRef})
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(attempts).To(Equal(2))
+	})
+
+	t.Run("should not report other errors as not found", func(t *testing.T) {
+		g.Expect(cliwrappers.IsImageNotFound(nil)).To(BeFalse())
+		g.Expect(cliwrappers.IsImageNotFound(errors.New("exit status 1: unauthorized"))).To(BeFalse())
+		g.Expect(cliwrappers.IsImageNotFound(errors.New("exit status 1: name unknown"))).To(BeTrue())
+	})
+}
+
+func TestSkopeoCli_Copy_authFiles(t *testing.T) {
+	g := NewWithT(t)
+
+	skopeoCli, executor := setupSkopeoCli()
+	var capturedArgs []string
+	executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+		capturedArgs = cmd.Args
+		return "", "", 0, nil
+	}
+
+	err := skopeoCli.Copy(&cliwrappers.SkopeoCopyArgs{
+		SourceImage:         "quay.io/staging/app:v1",
+		DestinationImage:    "registry.io/prod/app:v1",
+		SourceAuthFile:      "/auth/src.json",
+		DestinationAuthFile: "/auth/dest.json",
+		PreserveDigests:     true,
+	})
+
+	g.Expect(err).ToNot(HaveOccurred())
+	expectArgAndValue(g, capturedArgs, "--src-authfile", "/auth/src.json")
+	expectArgAndValue(g, capturedArgs, "--dest-authfile", "/auth/dest.json")
+	g.Expect(capturedArgs).To(ContainElement("--preserve-digests"))
+}
+
+func TestSkopeoCli_CopyAll(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should copy the images of all the platforms", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		copyArgs := &cliwrappers.SkopeoCopyArgs{
+			SourceImage:      "quay.io/org/app:v1",
+			DestinationImage: "registry.io/org/app:v1",
+			MultiArch:        cliwrappers.SkopeoCopyArgMultiArchIndexOnly,
+		}
+		err := skopeoCli.CopyAll(copyArgs)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{
+			"copy", "--multi-arch", "all", "docker://quay.io/org/app:v1", "docker://registry.io/org/app:v1",
+		}))
+		// The args of the caller are not modified
+		g.Expect(copyArgs.MultiArch).To(Equal(cliwrappers.SkopeoCopyArgMultiArchIndexOnly))
+	})
+}
+
+func TestSkopeoCli_ListTags(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should list tags of repository", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).To(Equal("skopeo"))
+			capturedArgs = cmd.Args
+			return `{"Repository": "quay.io/org/app", "Tags": ["v1", "v2"]}`, "", 0, nil
+		}
+
+		tags, err := skopeoCli.ListTags(&cliwrappers.SkopeoListTagsArgs{Repository: "quay.io/org/app"})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(tags).To(Equal([]string{"v1", "v2"}))
+		g.Expect(capturedArgs).To(Equal([]string{"list-tags", "docker://quay.io/org/app"}))
+	})
+
+	t.Run("should list tags with all supported options", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return `{"Repository": "registry.io/org/app", "Tags": null}`, "", 0, nil
+		}
+
+		tlsVerify := false
+		tags, err := skopeoCli.ListTags(&cliwrappers.SkopeoListTagsArgs{
+			Repository: "registry.io/org/app",
+			RetryTimes: 3,
+			TLSVerify:  &tlsVerify,
+			CertDir:    "/certs",
+			ExtraArgs:  []string{"--debug"},
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(tags).To(BeEmpty())
+		expectArgAndValue(g, capturedArgs, "--retry-times", "3")
+		expectArgAndValue(g, capturedArgs, "--cert-dir", "/certs")
+		g.Expect(capturedArgs).To(ContainElements("--tls-verify=false", "--debug"))
+		g.Expect(capturedArgs[len(capturedArgs)-1]).To(Equal("docker://registry.io/org/app"))
+	})
+
+	t.Run("should error if skopeo execution fails", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "unauthorized", 1, errors.New("exit status 1")
+		}
+
+		_, err := skopeoCli.ListTags(&cliwrappers.SkopeoListTagsArgs{Repository: "quay.io/org/app"})
+		g.Expect(err).To(MatchError(ContainSubstring("unauthorized")))
+	})
+
+	t.Run("should error if output is invalid", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "not json", "", 0, nil
+		}
+
+		_, err := skopeoCli.ListTags(&cliwrappers.SkopeoListTagsArgs{Repository: "quay.io/org/app"})
+		g.Expect(err).To(MatchError(ContainSubstring("parsing skopeo list-tags output")))
+	})
+
+	t.Run("should error if repository is empty", func(t *testing.T) {
+		skopeoCli, _ := setupSkopeoCli()
+		_, err := skopeoCli.ListTags(&cliwrappers.SkopeoListTagsArgs{})
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestSkopeoCli_Delete(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should delete image", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).To(Equal("skopeo"))
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		tlsVerify := true
+		err := skopeoCli.Delete(&cliwrappers.SkopeoDeleteArgs{
+			ImageRef:   "quay.io/org/app:pr-1",
+			RetryTimes: 2,
+			TLSVerify:  &tlsVerify,
+			CertDir:    "/certs",
+		})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{
+			"delete", "--retry-times", "2", "--tls-verify=true", "--cert-dir", "/certs", "docker://quay.io/org/app:pr-1",
+		}))
+	})
+
+	t.Run("should not retry if the image doesn't exist", func(t *testing.T) {
+		skopeoCli, executor := setupSkopeoCli()
+		cliwrappers.DisableRetryer = false
+		defer func() { cliwrappers.DisableRetryer = true }()
+
+		attempts := 0
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			attempts++
+			return "", "manifest unknown", 1, errors.New("exit status 1")
+		}
+
+		err := skopeoCli.Delete(&cliwrappers.SkopeoDeleteArgs{ImageRef: "quay.io/org/app:pr-1"})
+
+		g.Expect(cliwrappers.IsImageNotFound(err)).To(BeTrue())
+		g.Expect(attempts).To(Equal(1))
+	})
+
+	t.Run("should error if image reference is empty", func(t *testing.T) {
+		skopeoCli, _ := setupSkopeoCli()
+		g.Expect(skopeoCli.Delete(&cliwrappers.SkopeoDeleteArgs{})).ToNot(Succeed())
+	})
+}
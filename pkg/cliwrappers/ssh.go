@@ -0,0 +1,150 @@
+package cliwrappers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var sshLog = l.Logger.WithField("logger", "SshCli")
+
+type SshCliInterface interface {
+	// Run runs the command on the remote host. Returns stdout, stderr, exit code, error.
+	Run(command []string, logOutput bool) (string, string, int, error)
+	// CopyTo copies the absolute local paths to the remote directory, keeping their full paths,
+	// e.g. /src/app is copied to remoteDir/src/app.
+	CopyTo(localPaths []string, remoteDir string) error
+	// CopyFrom copies the remote path to the local path.
+	CopyFrom(remotePath, localPath string) error
+}
+
+var _ SshCliInterface = &SshCli{}
+
+// SshCli runs commands on a remote host with ssh and copies files to and from it with rsync.
+// The host key must be known, the connection fails for an unknown or changed host key.
+type SshCli struct {
+	Executor CliExecutorInterface
+	// [user@]host, the options of the host in the ssh config apply, e.g. the port
+	Host string
+	// The private key to authenticate with, the keys of the ssh agent and config are used if empty
+	IdentityFile string
+	// The known_hosts file with the host key, the known hosts of the ssh config are used if empty
+	KnownHostsFile string
+}
+
+func NewSshCli(executor CliExecutorInterface, host, identityFile, knownHostsFile string) (*SshCli, error) {
+	for _, tool := range []string{"ssh", "rsync"} {
+		available, err := CheckCliToolAvailable(tool)
+		if err != nil {
+			return nil, err
+		}
+		if !available {
+			return nil, &kbcerrors.ToolMissingError{Tool: tool}
+		}
+	}
+
+	return &SshCli{
+		Executor:       executor,
+		Host:           host,
+		IdentityFile:   identityFile,
+		KnownHostsFile: knownHostsFile,
+	}, nil
+}
+
+// The options of the ssh connections, also used by rsync.
+func (s *SshCli) sshOptions() []string {
+	options := []string{"-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=yes"}
+	if s.KnownHostsFile != "" {
+		// Quoted, the option takes a whitespace-separated list of files
+		options = append(options, "-o", fmt.Sprintf("UserKnownHostsFile=\"%s\"", s.KnownHostsFile))
+	}
+	if s.IdentityFile != "" {
+		options = append(options, "-i", s.IdentityFile, "-o", "IdentitiesOnly=yes")
+	}
+	return options
+}
+
+// The ssh command running the remote command, a shell command line.
+func (s *SshCli) sshCmd(remoteCommand string, logOutput bool, nameInLogs string) Cmd {
+	args := append(s.sshOptions(), "--", s.Host, remoteCommand)
+	return Cmd{Name: "ssh", Args: args, LogOutput: logOutput, NameInLogs: nameInLogs}
+}
+
+func (s *SshCli) Run(command []string, logOutput bool) (string, string, int, error) {
+	if len(command) == 0 {
+		return "", "", -1, errors.New("remote command is empty")
+	}
+	remoteCommand := shellJoin(command[0], command[1:]...)
+	sshLog.Debugf("Running command on %s:\n%s", s.Host, remoteCommand)
+
+	stdout, stderr, exitCode, err := s.Executor.Execute(s.sshCmd(remoteCommand, logOutput, command[0]))
+	if err != nil {
+		return stdout, stderr, exitCode, fmt.Errorf("running %s on %s: %w, stderr: %s", command[0], s.Host, err, strings.TrimSpace(stderr))
+	}
+	return stdout, stderr, exitCode, nil
+}
+
+func (s *SshCli) rsync(args ...string) error {
+	rsyncArgs := append([]string{"--archive", "--rsh", shellJoin("ssh", s.sshOptions()...)}, args...)
+	sshLog.Debugf("Running command:\n%s", shellJoin("rsync", rsyncArgs...))
+
+	_, stderr, _, err := s.Executor.Execute(Cmd{Name: "rsync", Args: rsyncArgs})
+	if err != nil {
+		return fmt.Errorf("rsync failed: %w, stderr: %s", err, strings.TrimSpace(stderr))
+	}
+	return nil
+}
+
+func (s *SshCli) CopyTo(localPaths []string, remoteDir string) error {
+	if len(localPaths) == 0 {
+		return nil
+	}
+	// Copy the files symlinks outside of the copied paths point to, e.g. mounted secrets
+	args := append([]string{"--relative", "--copy-unsafe-links"}, localPaths...)
+	return s.rsync(append(args, s.Host+":"+strings.TrimSuffix(remoteDir, "/")+"/")...)
+}
+
+func (s *SshCli) CopyFrom(remotePath, localPath string) error {
+	return s.rsync(s.Host+":"+remotePath, localPath)
+}
+
+var _ CliExecutorInterface = &SshExecutor{}
+
+// SshExecutor runs the commands on the remote host of the SshCli instead of locally,
+// e.g. NewBuildahCli-like wrappers created with it run buildah on the remote host.
+// The paths in the arguments of the commands must exist on the remote host.
+type SshExecutor struct {
+	Ssh *SshCli
+}
+
+func NewSshExecutor(ssh *SshCli) *SshExecutor {
+	return &SshExecutor{Ssh: ssh}
+}
+
+func (e *SshExecutor) Execute(c Cmd) (string, string, int, error) {
+	return e.ExecuteContext(context.Background(), c)
+}
+
+// ExecuteContext runs the command on the remote host until the context is done.
+// The environment of the command is not passed to the remote host, it must be empty.
+func (e *SshExecutor) ExecuteContext(ctx context.Context, c Cmd) (string, string, int, error) {
+	if c.Env != nil {
+		return "", "", -1, fmt.Errorf("cannot set the environment of %s on the remote host", c.Name)
+	}
+
+	remoteCommand := shellJoin(c.Name, c.Args...)
+	if c.Dir != "" {
+		remoteCommand = "cd " + ShellQuote(c.Dir) + " && " + remoteCommand
+	}
+	nameInLogs := c.NameInLogs
+	if nameInLogs == "" {
+		nameInLogs = c.Name
+	}
+	sshLog.Debugf("Running command on %s:\n%s", e.Ssh.Host, remoteCommand)
+
+	return e.Ssh.Executor.ExecuteContext(ctx, e.Ssh.sshCmd(remoteCommand, c.LogOutput, nameInLogs))
+}
@@ -0,0 +1,181 @@
+package cliwrappers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func setupSshCli() (*cliwrappers.SshCli, *mockExecutor) {
+	executor := &mockExecutor{}
+	sshCli := &cliwrappers.SshCli{Executor: executor, Host: "builder@arm64.example.com", IdentityFile: "/ssh/id_ed25519", KnownHostsFile: "/ssh/known_hosts"}
+	return sshCli, executor
+}
+
+var sshOptions = []string{"-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=yes", "-o", `UserKnownHostsFile="/ssh/known_hosts"`,
+	"-i", "/ssh/id_ed25519", "-o", "IdentitiesOnly=yes"}
+
+func TestSshCli_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should run quoted command on the host", func(t *testing.T) {
+		sshCli, executor := setupSshCli()
+		var capturedCmd cliwrappers.Cmd
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			return "/tmp/kbc-build-1234\n", "", 0, nil
+		}
+
+		stdout, _, _, err := sshCli.Run([]string{"mktemp", "-d", "-t", "kbc build"}, true)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(stdout).To(Equal("/tmp/kbc-build-1234\n"))
+		g.Expect(capturedCmd.Name).To(Equal("ssh"))
+		g.Expect(capturedCmd.Args).To(Equal(append(sshOptions, "--", "builder@arm64.example.com", "mktemp -d -t 'kbc build'")))
+		g.Expect(capturedCmd.LogOutput).To(BeTrue())
+		g.Expect(capturedCmd.NameInLogs).To(Equal("mktemp"))
+	})
+
+	t.Run("should not pass an identity file and known hosts file if not set", func(t *testing.T) {
+		sshCli, executor := setupSshCli()
+		sshCli.IdentityFile = ""
+		sshCli.KnownHostsFile = ""
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		_, _, _, err := sshCli.Run([]string{"true"}, false)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=yes", "--", "builder@arm64.example.com", "true"}))
+	})
+
+	t.Run("should include stderr in the error", func(t *testing.T) {
+		sshCli, executor := setupSshCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "Permission denied (publickey).\n", 255, errors.New("exit status 255")
+		}
+
+		_, _, exitCode, err := sshCli.Run([]string{"true"}, false)
+
+		g.Expect(err).To(MatchError(ContainSubstring("running true on builder@arm64.example.com: exit status 255, stderr: Permission denied (publickey).")))
+		g.Expect(exitCode).To(Equal(255))
+	})
+
+	t.Run("should error on empty command", func(t *testing.T) {
+		sshCli, _ := setupSshCli()
+
+		_, _, _, err := sshCli.Run(nil, false)
+
+		g.Expect(err).To(MatchError("remote command is empty"))
+	})
+}
+
+func TestSshCli_Copy(t *testing.T) {
+	g := NewWithT(t)
+
+	rsh := `ssh -o BatchMode=yes -o StrictHostKeyChecking=yes -o 'UserKnownHostsFile="/ssh/known_hosts"' -i /ssh/id_ed25519 -o IdentitiesOnly=yes`
+
+	t.Run("should copy local paths to the host keeping their paths", func(t *testing.T) {
+		sshCli, executor := setupSshCli()
+		var capturedCmd cliwrappers.Cmd
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			return "", "", 0, nil
+		}
+
+		err := sshCli.CopyTo([]string{"/src/app", "/secrets/token"}, "/tmp/kbc-build-1234/")
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedCmd.Name).To(Equal("rsync"))
+		g.Expect(capturedCmd.Args).To(Equal([]string{"--archive", "--rsh", rsh, "--relative", "--copy-unsafe-links",
+			"/src/app", "/secrets/token", "builder@arm64.example.com:/tmp/kbc-build-1234/"}))
+	})
+
+	t.Run("should not run rsync without paths", func(t *testing.T) {
+		sshCli, executor := setupSshCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			t.Fatal("rsync should not be called")
+			return "", "", 0, nil
+		}
+
+		g.Expect(sshCli.CopyTo(nil, "/tmp/kbc-build-1234")).To(Succeed())
+	})
+
+	t.Run("should copy remote path to local path", func(t *testing.T) {
+		sshCli, executor := setupSshCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := sshCli.CopyFrom("/tmp/kbc-build-1234/image/", "/tmp/workdir/image")
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{"--archive", "--rsh", rsh, "builder@arm64.example.com:/tmp/kbc-build-1234/image/", "/tmp/workdir/image"}))
+	})
+
+	t.Run("should include stderr in the error", func(t *testing.T) {
+		sshCli, executor := setupSshCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "rsync: connection unexpectedly closed\n", 12, errors.New("exit status 12")
+		}
+
+		err := sshCli.CopyFrom("/tmp/a", "/tmp/b")
+
+		g.Expect(err).To(MatchError("rsync failed: exit status 12, stderr: rsync: connection unexpectedly closed"))
+	})
+}
+
+func TestSshExecutor(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should run the command on the host", func(t *testing.T) {
+		sshCli, executor := setupSshCli()
+		var capturedCmd cliwrappers.Cmd
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			return "out", "", 0, nil
+		}
+		buildahCli := &cliwrappers.BuildahCli{Executor: cliwrappers.NewSshExecutor(sshCli)}
+
+		err := buildahCli.Tag("sha256:1234", "quay.io/org/app:v1")
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedCmd.Name).To(Equal("ssh"))
+		g.Expect(capturedCmd.Args).To(Equal(append(sshOptions, "--", "builder@arm64.example.com", "buildah tag sha256:1234 quay.io/org/app:v1")))
+		g.Expect(capturedCmd.NameInLogs).To(Equal("buildah"))
+	})
+
+	t.Run("should change the directory on the host", func(t *testing.T) {
+		sshCli, executor := setupSshCli()
+		var capturedCmd cliwrappers.Cmd
+		executor.executeContextFunc = func(ctx context.Context, cmd cliwrappers.Cmd) (string, string, int, error) {
+			capturedCmd = cmd
+			return "", "", 0, nil
+		}
+
+		_, _, _, err := cliwrappers.NewSshExecutor(sshCli).ExecuteContext(context.Background(),
+			cliwrappers.Cmd{Name: "ls", Args: []string{"-l"}, Dir: "/tmp/my dir", LogOutput: true, NameInLogs: "list"})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedCmd.Args[len(capturedCmd.Args)-1]).To(Equal("cd '/tmp/my dir' && ls -l"))
+		g.Expect(capturedCmd.LogOutput).To(BeTrue())
+		g.Expect(capturedCmd.NameInLogs).To(Equal("list"))
+	})
+
+	t.Run("should refuse to set the environment", func(t *testing.T) {
+		sshCli, _ := setupSshCli()
+
+		_, _, _, err := cliwrappers.NewSshExecutor(sshCli).Execute(cliwrappers.Cmd{Name: "buildah", Env: []string{"A=b"}})
+
+		g.Expect(err).To(MatchError(ContainSubstring("cannot set the environment of buildah")))
+	})
+}
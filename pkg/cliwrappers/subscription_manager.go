@@ -5,6 +5,7 @@ import (
 	"os"
 	"slices"
 
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -15,6 +16,8 @@ var getUID = os.Getuid
 
 type SubscriptionManagerCliInterface interface {
 	Register(params *SubscriptionManagerRegisterParams) error
+	SetRelease(release string) error
+	EnableRepos(repoIDs []string) error
 	Unregister()
 }
 
@@ -34,7 +37,7 @@ func NewSubscriptionManagerCli(executor CliExecutorInterface) (*SubscriptionMana
 		return nil, err
 	}
 	if !available {
-		return nil, errors.New("subscription-manager CLI is not available")
+		return nil, &kbcerrors.ToolMissingError{Tool: "subscription-manager"}
 	}
 	return &SubscriptionManagerCli{Executor: executor}, nil
 }
@@ -72,6 +75,43 @@ func (sm *SubscriptionManagerCli) Register(params *SubscriptionManagerRegisterPa
 	return nil
 }
 
+// Set the release of the registered system, e.g. 9.4, the repositories then serve the content of that release.
+func (sm *SubscriptionManagerCli) SetRelease(release string) error {
+	if release == "" {
+		return errors.New("release is empty")
+	}
+	return sm.run("release", "--set", release)
+}
+
+// Enable the repositories of the registered system and disable all the other ones.
+func (sm *SubscriptionManagerCli) EnableRepos(repoIDs []string) error {
+	if len(repoIDs) == 0 {
+		return errors.New("repository ids are empty")
+	}
+	args := []string{"repos", "--disable", "*"}
+	for _, repoID := range repoIDs {
+		args = append(args, "--enable", repoID)
+	}
+	return sm.run(args...)
+}
+
+func (sm *SubscriptionManagerCli) run(args ...string) error {
+	submanLog.Debugf("Running command: %s", shellJoin("subscription-manager", args...))
+
+	retryer := NewRetryer(func() (string, string, int, error) {
+		return sm.Executor.Execute(Cmd{Name: "subscription-manager", Args: args})
+	})
+	_, stderr, _, err := retryer.Run()
+	if err != nil {
+		submanLog.Errorf("subscription-manager %s failed: %s", args[0], err.Error())
+		if stderr != "" {
+			submanLog.Errorf("stderr:\n%s", stderr)
+		}
+		return err
+	}
+	return nil
+}
+
 // Unregister the system from Red Hat Subscription Manager (best-effort).
 func (sm *SubscriptionManagerCli) Unregister() {
 	submanLog.Debugf("Running command: subscription-manager unregister")
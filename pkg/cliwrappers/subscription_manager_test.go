@@ -115,6 +115,79 @@ func TestSubscriptionManagerCli_Register(t *testing.T) {
 	})
 }
 
+func TestSubscriptionManagerCli_SetRelease(t *testing.T) {
+	g := NewWithT(t)
+	ensureRetryerDisabled(t)
+
+	t.Run("should set the release", func(t *testing.T) {
+		smCli, executor := setupSubscriptionManagerCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).To(Equal("subscription-manager"))
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		g.Expect(smCli.SetRelease("9.4")).To(Succeed())
+		g.Expect(capturedArgs).To(Equal([]string{"release", "--set", "9.4"}))
+	})
+
+	t.Run("should return error when release is empty", func(t *testing.T) {
+		smCli, _ := setupSubscriptionManagerCli()
+		g.Expect(smCli.SetRelease("")).To(MatchError("release is empty"))
+	})
+
+	t.Run("should return error when the command fails", func(t *testing.T) {
+		smCli, executor := setupSubscriptionManagerCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "Release 0.1 is not available", 1, errors.New("command failed")
+		}
+
+		logOutput := testutil.CaptureLogOutput(func() {
+			g.Expect(smCli.SetRelease("0.1")).To(MatchError("command failed"))
+		})
+		g.Expect(logOutput).To(ContainSubstring("Release 0.1 is not available"))
+	})
+}
+
+func TestSubscriptionManagerCli_EnableRepos(t *testing.T) {
+	g := NewWithT(t)
+	ensureRetryerDisabled(t)
+
+	t.Run("should enable only the given repositories", func(t *testing.T) {
+		smCli, executor := setupSubscriptionManagerCli()
+		var capturedArgs []string
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			g.Expect(cmd.Name).To(Equal("subscription-manager"))
+			capturedArgs = cmd.Args
+			return "", "", 0, nil
+		}
+
+		err := smCli.EnableRepos([]string{"rhel-9-for-x86_64-baseos-rpms", "rhel-9-for-x86_64-appstream-rpms"})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(capturedArgs).To(Equal([]string{
+			"repos", "--disable", "*",
+			"--enable", "rhel-9-for-x86_64-baseos-rpms",
+			"--enable", "rhel-9-for-x86_64-appstream-rpms",
+		}))
+	})
+
+	t.Run("should return error when repository ids are empty", func(t *testing.T) {
+		smCli, _ := setupSubscriptionManagerCli()
+		g.Expect(smCli.EnableRepos(nil)).To(MatchError("repository ids are empty"))
+	})
+
+	t.Run("should return error when the command fails", func(t *testing.T) {
+		smCli, executor := setupSubscriptionManagerCli()
+		executor.executeFunc = func(cmd cliwrappers.Cmd) (string, string, int, error) {
+			return "", "", 1, errors.New("command failed")
+		}
+
+		g.Expect(smCli.EnableRepos([]string{"unknown-rpms"})).To(MatchError("command failed"))
+	})
+}
+
 func TestSubscriptionManagerCli_Unregister(t *testing.T) {
 	g := NewWithT(t)
 
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strings"
 
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -25,7 +26,7 @@ func NewSyftCli(executor CliExecutorInterface) (*SyftCli, error) {
 		return nil, err
 	}
 	if !syftCliAvailable {
-		return nil, errors.New("syft CLI is not available")
+		return nil, &kbcerrors.ToolMissingError{Tool: "syft"}
 	}
 
 	return &SyftCli{
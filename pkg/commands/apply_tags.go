@@ -3,15 +3,20 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
 	"reflect"
 	"regexp"
 	"slices"
 	"strings"
+	"time"
 
 	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	"github.com/opencontainers/go-digest"
 	"github.com/spf13/cobra"
 
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -38,7 +43,22 @@ var ApplyTagsParamsConfig = map[string]common.Parameter{
 		EnvVarName:   "KBC_APPLY_TAGS",
 		TypeKind:     reflect.Array,
 		DefaultValue: "",
-		Usage:        "Tags to add to the given image",
+		Usage: "Tags to add to the given image. An entry can also be a destination with an explicit transport, " +
+			"e.g. docker://registry.io/org/archive:tag, oci:/path/to/layout:tag or dir:/path/to/dir",
+	},
+	"destination-repo": {
+		Name:       "destination-repo",
+		EnvVarName: "KBC_APPLY_TAGS_DESTINATION_REPO",
+		TypeKind:   reflect.String,
+		Usage: "Repository to create the tags in instead of the repository of the image, without a tag or digest, " +
+			"e.g. quay.io/org/app-released. The image is copied there by digest.",
+	},
+	"dest-auth-file": {
+		Name:       "dest-auth-file",
+		EnvVarName: "KBC_APPLY_TAGS_DEST_AUTH_FILE",
+		TypeKind:   reflect.String,
+		Usage: "Docker config file, or a directory containing config.json or .dockerconfigjson, with the credentials " +
+			"for pushing the tags to the registries. The default auth file is used if not set.",
 	},
 	"tags-from-image-label": {
 		Name:         "tags-from-image-label",
@@ -48,21 +68,159 @@ var ApplyTagsParamsConfig = map[string]common.Parameter{
 		DefaultValue: "",
 		Usage:        "Image label name to add tags from. Tags are comma or whitespace separated in the label value.",
 	},
+	"tag-expires-after": {
+		Name:         "tag-expires-after",
+		EnvVarName:   "KBC_APPLY_TAGS_TAG_EXPIRES_AFTER",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage: "Time after which the created tags expire on quay.io (e.g. 1h, 2d, 3w). " +
+			"The expiration is set via the Quay API, requires --quay-token-dir.",
+	},
+	"verify": {
+		Name:         "verify",
+		EnvVarName:   "KBC_APPLY_TAGS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage: "Inspect the created tags and fail if a tag doesn't point to the image digest, " +
+			"e.g. because another writer pushed the same tag concurrently. Tags written with oci: or dir: are not verified.",
+	},
+	"require-signature": {
+		Name:         "require-signature",
+		EnvVarName:   "KBC_APPLY_TAGS_REQUIRE_SIGNATURE",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage: "Verify the cosign signature of the image before creating any tags and fail if it doesn't pass, " +
+			"see --signature-key or the certificate identity params.",
+	},
+	"signature-key": {
+		Name:       "signature-key",
+		EnvVarName: "KBC_APPLY_TAGS_SIGNATURE_KEY",
+		TypeKind:   reflect.String,
+		Usage:      "Path or KMS URI of the public key to verify the signature with --require-signature.",
+	},
+	"certificate-identity": {
+		Name:       "certificate-identity",
+		EnvVarName: "KBC_APPLY_TAGS_CERTIFICATE_IDENTITY",
+		TypeKind:   reflect.String,
+		Usage:      "Identity the keyless signing certificate must be issued for, with --require-signature.",
+	},
+	"certificate-identity-regexp": {
+		Name:       "certificate-identity-regexp",
+		EnvVarName: "KBC_APPLY_TAGS_CERTIFICATE_IDENTITY_REGEXP",
+		TypeKind:   reflect.String,
+		Usage:      "Regular expression the identity of the keyless signing certificate must match, with --require-signature.",
+	},
+	"certificate-oidc-issuer": {
+		Name:       "certificate-oidc-issuer",
+		EnvVarName: "KBC_APPLY_TAGS_CERTIFICATE_OIDC_ISSUER",
+		TypeKind:   reflect.String,
+		Usage:      "OIDC issuer of the identity of the keyless signing certificate, with --require-signature.",
+	},
+	"certificate-oidc-issuer-regexp": {
+		Name:       "certificate-oidc-issuer-regexp",
+		EnvVarName: "KBC_APPLY_TAGS_CERTIFICATE_OIDC_ISSUER_REGEXP",
+		TypeKind:   reflect.String,
+		Usage:      "Regular expression the OIDC issuer of the keyless signing certificate must match, with --require-signature.",
+	},
+	"dry-run": {
+		Name:         "dry-run",
+		EnvVarName:   "KBC_APPLY_TAGS_DRY_RUN",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Check that the image exists and log the tags that would be created, without creating them.",
+	},
+	"skip-existing": {
+		Name:         "skip-existing",
+		EnvVarName:   "KBC_APPLY_TAGS_SKIP_EXISTING",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage: "Inspect the tags in registries first and don't copy the image to the ones already pointing to the image digest. " +
+			"Tags written with oci: or dir: are always copied.",
+	},
+	"quay-token-dir": {
+		Name:         "quay-token-dir",
+		EnvVarName:   "KBC_APPLY_TAGS_QUAY_TOKEN_DIR",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage:        "Directory with a 'token' file containing a Quay OAuth access token, e.g. a mounted secret.",
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_APPLY_TAGS_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Require HTTPS and verify certificates when accessing the registries.",
+	},
+	"cert-dir": {
+		Name:       "cert-dir",
+		EnvVarName: "KBC_APPLY_TAGS_CERT_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Directory with the certificates (*.crt CA certificates, *.cert and *.key client certificates) for accessing the registries.",
+	},
+	"ca-bundle-file": {
+		Name:       "ca-bundle-file",
+		EnvVarName: "KBC_APPLY_TAGS_CA_BUNDLE_FILE",
+		TypeKind:   reflect.String,
+		Usage:      "File with CA certificates to trust for accessing the registries, in addition to the system trust store and --cert-dir.",
+	},
+	"proxy": {
+		Name:       "proxy",
+		EnvVarName: "KBC_APPLY_TAGS_PROXY",
+		TypeKind:   reflect.String,
+		Usage:      "HTTP(S) proxy for the registries, set as HTTP_PROXY and HTTPS_PROXY. Defaults to the HTTPS_PROXY or HTTP_PROXY environment variable.",
+	},
+	"no-proxy": {
+		Name:       "no-proxy",
+		EnvVarName: "KBC_APPLY_TAGS_NO_PROXY",
+		TypeKind:   reflect.String,
+		Usage:      "Hosts to access without the --proxy, set as NO_PROXY. Defaults to the NO_PROXY environment variable.",
+	},
 }
 
 type ApplyTagsParams struct {
 	ImageUrl      string   `paramName:"image-url"`
 	Digest        string   `paramName:"digest"`
 	NewTags       []string `paramName:"tags"`
+	DestRepo      string   `paramName:"destination-repo"`
+	DestAuthFile  string   `paramName:"dest-auth-file"`
 	LabelWithTags string   `paramName:"tags-from-image-label"`
+	ExpiresAfter  string   `paramName:"tag-expires-after"`
+	QuayTokenDir  string   `paramName:"quay-token-dir"`
+	Verify        bool     `paramName:"verify"`
+	// Signature verification before tagging
+	RequireSignature            bool   `paramName:"require-signature"`
+	SignatureKey                string `paramName:"signature-key"`
+	CertificateIdentity         string `paramName:"certificate-identity"`
+	CertificateIdentityRegexp   string `paramName:"certificate-identity-regexp"`
+	CertificateOIDCIssuer       string `paramName:"certificate-oidc-issuer"`
+	CertificateOIDCIssuerRegexp string `paramName:"certificate-oidc-issuer-regexp"`
+	DryRun                      bool   `paramName:"dry-run"`
+	SkipExisting                bool   `paramName:"skip-existing"`
+	TLSVerify                   bool   `paramName:"tls-verify"`
+	CertDir                     string `paramName:"cert-dir"`
+	CABundleFile                string `paramName:"ca-bundle-file"`
+	Proxy                       string `paramName:"proxy"`
+	NoProxy                     string `paramName:"no-proxy"`
 }
 
 type ApplyTagsCliWrappers struct {
 	SkopeoCli cliWrappers.SkopeoCliInterface
+	// Set with --require-signature
+	CosignCli cliWrappers.CosignCliInterface
 }
 
 type ApplyTagsResults struct {
 	Tags []string `json:"tags"`
+	// Tag => digest the tag points to, set with --verify
+	VerifiedDigests map[string]string `json:"verified_digests,omitempty"`
+	// The signature of the image was verified before tagging, set with --require-signature
+	SignatureVerified bool `json:"signature_verified,omitempty"`
+	// Tags already pointing to the image digest, not copied with --skip-existing
+	SkippedTags []string `json:"skipped_tags,omitempty"`
+	// No tags were created, set with --dry-run
+	DryRun bool `json:"dry_run,omitempty"`
+	// The effective --proxy and --no-proxy, the proxy password is masked
+	Proxy *common.ProxySettings `json:"proxy,omitempty"`
 }
 
 type ApplyTags struct {
@@ -73,10 +231,17 @@ type ApplyTags struct {
 
 	imageName     string
 	imageByDigest string
+	// Resolved --dest-auth-file
+	destAuthFile string
+	// --cert-dir for skopeo, combining the --cert-dir and --ca-bundle-file params
+	certDir string
+
+	quayApiUrl string
+	httpClient *http.Client
 }
 
 func NewApplyTags(cmd *cobra.Command) (*ApplyTags, error) {
-	applyTags := &ApplyTags{}
+	applyTags := &ApplyTags{quayApiUrl: quayApiUrl, httpClient: &http.Client{Timeout: 30 * time.Second}}
 
 	params := &ApplyTagsParams{}
 	if err := common.ParseParameters(cmd, ApplyTagsParamsConfig, params); err != nil {
@@ -101,6 +266,14 @@ func (c *ApplyTags) initCliWrappers() error {
 		return err
 	}
 	c.CliWrappers.SkopeoCli = skopeoCli
+
+	if c.Params.RequireSignature {
+		cosignCli, err := cliWrappers.NewCosignCli(executor)
+		if err != nil {
+			return err
+		}
+		c.CliWrappers.CosignCli = cosignCli
+	}
 	return nil
 }
 
@@ -110,11 +283,45 @@ func (c *ApplyTags) Run() error {
 
 	c.imageName = common.GetImageName(c.Params.ImageUrl)
 	if err := c.validateParams(); err != nil {
-		return err
+		return kbcerrors.NewValidationError(err)
 	}
 
 	c.imageByDigest = c.imageName + "@" + c.Params.Digest
 
+	proxySettings := common.ResolveProxySettings(c.Params.Proxy, c.Params.NoProxy)
+	if proxySettings.IsSet() {
+		if err := proxySettings.Apply(); err != nil {
+			return fmt.Errorf("configuring proxy: %w", err)
+		}
+		redacted := proxySettings.Redacted()
+		c.Results.Proxy = &redacted
+	}
+
+	c.certDir = c.Params.CertDir
+	if c.Params.CABundleFile != "" {
+		// skopeo doesn't have an option for a CA bundle file, add it to a copy of the cert dir
+		certDir, err := os.MkdirTemp("", "kbc-apply-tags-certs-")
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := os.RemoveAll(certDir); err != nil {
+				l.Logger.Warnf("failed to remove '%s' directory: %s", certDir, err.Error())
+			}
+		}()
+		if err := common.WriteCertDir(certDir, c.Params.CertDir, c.Params.CABundleFile); err != nil {
+			return fmt.Errorf("preparing cert dir: %w", err)
+		}
+		c.certDir = certDir
+	}
+
+	if c.Params.RequireSignature {
+		if err := verifyImageSignature(c.CliWrappers.CosignCli, c.signatureVerifyOptions(), c.imageByDigest); err != nil {
+			return err
+		}
+		c.Results.SignatureVerified = true
+	}
+
 	tagsFromLabel, err := c.retrieveTagsFromImageLabel(c.Params.LabelWithTags)
 	if err != nil {
 		return err
@@ -123,8 +330,44 @@ func (c *ApplyTags) Run() error {
 	tags := slices.Concat(c.Params.NewTags, tagsFromLabel)
 	l.Logger.Debugf("Tags to create: %s", strings.Join(tags, ", "))
 
-	if err := c.applyTags(tags); err != nil {
-		return err
+	// Read the token before creating any tags, so that a missing token doesn't leave tags without expiration
+	var quay *quayClient
+	if c.Params.ExpiresAfter != "" {
+		token, err := readQuayToken(c.Params.QuayTokenDir)
+		if err != nil {
+			return err
+		}
+		quay = &quayClient{apiUrl: c.quayApiUrl, token: token, httpClient: c.httpClient}
+	}
+
+	tagsToCreate := tags
+	if c.Params.SkipExisting {
+		tagsToCreate, c.Results.SkippedTags = c.filterExistingTags(tags)
+	}
+
+	if c.Params.DryRun {
+		if err := c.dryRun(tagsToCreate); err != nil {
+			return err
+		}
+		c.Results.DryRun = true
+	} else {
+		if err := c.applyTags(tagsToCreate); err != nil {
+			return err
+		}
+
+		if c.Params.Verify {
+			verifiedDigests, err := c.verifyTags(tagsToCreate)
+			if err != nil {
+				return err
+			}
+			c.Results.VerifiedDigests = verifiedDigests
+		}
+
+		if quay != nil {
+			if err := c.setTagsExpiration(quay, tagsToCreate); err != nil {
+				return err
+			}
+		}
 	}
 
 	c.Results.Tags = tags
@@ -139,6 +382,17 @@ func (c *ApplyTags) Run() error {
 	return nil
 }
 
+func (c *ApplyTags) signatureVerifyOptions() cliWrappers.CosignVerifyOptions {
+	return cliWrappers.CosignVerifyOptions{
+		Key:                         c.Params.SignatureKey,
+		CertificateIdentity:         c.Params.CertificateIdentity,
+		CertificateIdentityRegexp:   c.Params.CertificateIdentityRegexp,
+		CertificateOIDCIssuer:       c.Params.CertificateOIDCIssuer,
+		CertificateOIDCIssuerRegexp: c.Params.CertificateOIDCIssuerRegexp,
+		AllowInsecureRegistry:       !c.Params.TLSVerify,
+	}
+}
+
 // retrieveTagsFromImageLabel fetches list of tags from the given image label.
 // In fact, two skopeo invocations are needed (and this is optimal way):
 //  1. Read the raw reference data (light request) to see if we have image manifest or image index.
@@ -164,6 +418,8 @@ func (c *ApplyTags) retrieveTagsFromImageLabel(labelName string) ([]string, erro
 		ImageRef:   c.imageByDigest,
 		Raw:        true,
 		RetryTimes: 3,
+		TLSVerify:  &c.Params.TLSVerify,
+		CertDir:    c.certDir,
 	}
 	rawManifest, err := c.CliWrappers.SkopeoCli.Inspect(rawInspectArgs)
 	if err != nil {
@@ -210,6 +466,8 @@ func (c *ApplyTags) retrieveTagsFromImageLabel(labelName string) ([]string, erro
 		Format:     fmt.Sprintf(`{{ index .Labels "%s" }}`, labelName),
 		RetryTimes: 3,
 		NoTags:     true,
+		TLSVerify:  &c.Params.TLSVerify,
+		CertDir:    c.certDir,
 	}
 	tagsLabelValue, err := c.CliWrappers.SkopeoCli.Inspect(inspectArgs)
 	if err != nil {
@@ -250,16 +508,33 @@ func (c *ApplyTags) retrieveTagsFromImageLabel(labelName string) ([]string, erro
 }
 
 func (c *ApplyTags) applyTags(tags []string) error {
-	args := &cliWrappers.SkopeoCopyArgs{
-		SourceImage: c.imageByDigest,
-		MultiArch:   cliWrappers.SkopeoCopyArgMultiArchIndexOnly,
-		RetryTimes:  3,
-	}
-
 	for _, tag := range tags {
 		l.Logger.Debugf("Creating tag: %s", tag)
 
-		args.DestinationImage = c.imageName + ":" + tag
+		args := &cliWrappers.SkopeoCopyArgs{
+			SourceImage: c.imageByDigest,
+			MultiArch:   cliWrappers.SkopeoCopyArgMultiArchIndexOnly,
+			RetryTimes:  3,
+			TLSVerify:   &c.Params.TLSVerify,
+			CertDir:     c.certDir,
+		}
+		transport, ref := common.SplitImageTransport(tag)
+		switch transport {
+		case "":
+			args.DestinationImage = c.destinationRepository() + ":" + tag
+			args.DestinationAuthFile = c.destAuthFile
+			c.checkDestinationAuth(args.DestinationImage)
+		case "docker://":
+			args.DestinationImage = ref
+			args.DestinationAuthFile = c.destAuthFile
+			c.checkDestinationAuth(ref)
+		default:
+			// Local copies must contain the images for all the platforms,
+			// copying only the index would leave it pointing to nothing.
+			args.DestinationImage = tag
+			args.MultiArch = cliWrappers.SkopeoCopyArgMultiArchAll
+		}
+
 		if err := c.CliWrappers.SkopeoCli.Copy(args); err != nil {
 			l.Logger.Errorf("failed to push '%s' tag: %s", tag, err.Error())
 			return err
@@ -271,6 +546,203 @@ func (c *ApplyTags) applyTags(tags []string) error {
 	return nil
 }
 
+// Check that the image exists and log the tags that would be created.
+func (c *ApplyTags) dryRun(tags []string) error {
+	if _, err := c.CliWrappers.SkopeoCli.Inspect(&cliWrappers.SkopeoInspectArgs{
+		ImageRef:   c.imageByDigest,
+		Raw:        true,
+		RetryTimes: 3,
+		TLSVerify:  &c.Params.TLSVerify,
+		CertDir:    c.certDir,
+	}); err != nil {
+		return fmt.Errorf("inspecting %s: %w", c.imageByDigest, err)
+	}
+
+	for _, tag := range tags {
+		destination := c.registryDestination(tag)
+		if destination == "" {
+			destination = tag
+		} else {
+			c.checkDestinationAuth(destination)
+		}
+		l.Logger.Infof("[dry-run] Would copy %s to %s", c.imageByDigest, destination)
+	}
+	if c.Params.Verify || c.Params.ExpiresAfter != "" {
+		l.Logger.Info("[dry-run] Not verifying the tags nor setting their expiration")
+	}
+	return nil
+}
+
+// Split the tags into the ones to create and the ones in registries already pointing to the image digest.
+// A tag that can't be inspected, most likely because it doesn't exist yet, is created.
+func (c *ApplyTags) filterExistingTags(tags []string) ([]string, []string) {
+	expectedDigest, err := digest.Parse(c.Params.Digest)
+	if err != nil {
+		// Validated by validateParams
+		return tags, nil
+	}
+
+	var tagsToCreate, existingTags []string
+	for _, tag := range tags {
+		destination := c.registryDestination(tag)
+		if destination == "" {
+			tagsToCreate = append(tagsToCreate, tag)
+			continue
+		}
+
+		rawManifest, err := c.CliWrappers.SkopeoCli.Inspect(&cliWrappers.SkopeoInspectArgs{
+			ImageRef:  destination,
+			Raw:       true,
+			TLSVerify: &c.Params.TLSVerify,
+			CertDir:   c.certDir,
+			AuthFile:  c.destAuthFile,
+		})
+		if err != nil {
+			l.Logger.Debugf("Creating tag '%s', inspecting %s failed: %s", tag, destination, err.Error())
+			tagsToCreate = append(tagsToCreate, tag)
+			continue
+		}
+		if actualDigest := expectedDigest.Algorithm().FromString(rawManifest); actualDigest != expectedDigest {
+			l.Logger.Debugf("Creating tag '%s', %s points to %s", tag, destination, actualDigest)
+			tagsToCreate = append(tagsToCreate, tag)
+			continue
+		}
+		l.Logger.Infof("Skipping tag '%s', %s already points to %s", tag, destination, expectedDigest)
+		existingTags = append(existingTags, tag)
+	}
+	return tagsToCreate, existingTags
+}
+
+// The repository to create the plain tags in, the repository of the image unless --destination-repo is set.
+func (c *ApplyTags) destinationRepository() string {
+	if c.Params.DestRepo != "" {
+		return common.GetImageName(c.Params.DestRepo)
+	}
+	return c.imageName
+}
+
+// The registry reference the tag is written to, empty for local destinations (oci: and dir:).
+func (c *ApplyTags) registryDestination(tag string) string {
+	switch transport, ref := common.SplitImageTransport(tag); transport {
+	case "":
+		return c.destinationRepository() + ":" + tag
+	case "docker://":
+		return ref
+	default:
+		return ""
+	}
+}
+
+// Check that the created tags in registries point to the image digest. Another writer pushing
+// the same tag between the copy and now would make the tag point to a different manifest.
+func (c *ApplyTags) verifyTags(tags []string) (map[string]string, error) {
+	expectedDigest, err := digest.Parse(c.Params.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("image digest '%s' is invalid: %w", c.Params.Digest, err)
+	}
+
+	verifiedDigests := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		destination := c.registryDestination(tag)
+		if destination == "" {
+			l.Logger.Debugf("Not verifying local destination %s", tag)
+			continue
+		}
+
+		rawManifest, err := c.CliWrappers.SkopeoCli.Inspect(&cliWrappers.SkopeoInspectArgs{
+			ImageRef:   destination,
+			Raw:        true,
+			RetryTimes: 3,
+			TLSVerify:  &c.Params.TLSVerify,
+			CertDir:    c.certDir,
+			AuthFile:   c.destAuthFile,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("verifying tag '%s': %w", tag, err)
+		}
+		actualDigest := expectedDigest.Algorithm().FromString(rawManifest)
+		if actualDigest != expectedDigest {
+			return nil, fmt.Errorf("verifying tag '%s': %s points to %s instead of %s, the tag was overwritten by another writer",
+				tag, destination, actualDigest, expectedDigest)
+		}
+		l.Logger.Debugf("Tag '%s' points to %s", tag, actualDigest)
+		verifiedDigests[tag] = actualDigest.String()
+	}
+	l.Logger.Infof("Verified digests of %d tags", len(verifiedDigests))
+	return verifiedDigests, nil
+}
+
+// Set the expiration of the created tags that are on quay.io, other registries don't support it.
+func (c *ApplyTags) setTagsExpiration(quay *quayClient, tags []string) error {
+	// Validated by validateParams
+	expiresAfter, _ := parseQuayExpiresAfter(c.Params.ExpiresAfter)
+	expiration := time.Now().Add(expiresAfter)
+
+	for _, tag := range tags {
+		destination := c.registryDestination(tag)
+		if destination == "" {
+			continue
+		}
+		repository, quayTag := quayTagRef(destination)
+		if repository == "" {
+			l.Logger.Warnf("Tag expiration is only supported on %s, %s will not expire", quayRegistry, destination)
+			continue
+		}
+		if err := quay.setTagExpiration(repository, quayTag, expiration); err != nil {
+			l.Logger.Errorf("failed to set expiration of '%s' tag: %s", tag, err.Error())
+			return err
+		}
+	}
+	l.Logger.Infof("Tags on %s expire at %s", quayRegistry, expiration.UTC().Format(time.RFC3339))
+	return nil
+}
+
+// Tags in the repository of the image use the same credentials as reading the image.
+// For other repositories, skopeo selects the credentials by the destination, warn early
+// if there are none instead of failing with a generic unauthorized error.
+func (c *ApplyTags) checkDestinationAuth(destination string) {
+	if common.GetImageName(destination) == c.imageName && c.destAuthFile == "" {
+		return
+	}
+	var err error
+	if c.destAuthFile != "" {
+		_, err = common.SelectRegistryAuth(destination, c.destAuthFile)
+	} else {
+		_, err = common.SelectRegistryAuthFromDefaultAuthFile(destination)
+	}
+	if err != nil {
+		l.Logger.Warnf("Pushing to %s may fail: %s", destination, err.Error())
+	}
+}
+
+// Transports that tags can be written to, besides the repository of the image.
+var applyTagsDestinationTransports = []string{"docker://", "oci:", "dir:"}
+
+func validateTagDestination(tag string) error {
+	transport, ref := common.SplitImageTransport(tag)
+	switch transport {
+	case "":
+		if !common.IsImageTagValid(tag) {
+			return fmt.Errorf("tag '%s' is invalid", tag)
+		}
+	case "docker://":
+		if !common.IsImageNameValid(common.GetImageName(ref)) {
+			return fmt.Errorf("destination '%s' is invalid", tag)
+		}
+		if err := common.ValidateImageHasTagOrDigest(ref); err != nil {
+			return fmt.Errorf("destination '%s' is invalid: %w", tag, err)
+		}
+	case "oci:", "dir:":
+		if path, _, _ := strings.Cut(ref, ":"); path == "" {
+			return fmt.Errorf("destination '%s' is invalid: missing path", tag)
+		}
+	default:
+		return fmt.Errorf("destination '%s' is invalid: unsupported transport, use one of: %s",
+			tag, strings.Join(applyTagsDestinationTransports, ", "))
+	}
+	return nil
+}
+
 func (c *ApplyTags) validateParams() error {
 	// Validate imageName instead of Params.ImageUrl to avoid calling normalizeImageName second time.
 	if !common.IsImageNameValid(c.imageName) {
@@ -282,15 +754,48 @@ func (c *ApplyTags) validateParams() error {
 	}
 
 	for _, tag := range c.Params.NewTags {
-		if !common.IsImageTagValid(tag) {
-			return fmt.Errorf("tag '%s' is invalid", tag)
+		if err := validateTagDestination(tag); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.RequireSignature {
+		if err := validateCosignVerifyOptions(c.signatureVerifyOptions()); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.DestRepo != "" {
+		ref, err := common.ParseReference(c.Params.DestRepo)
+		if err != nil {
+			return fmt.Errorf("destination repository '%s' is invalid: %w", c.Params.DestRepo, err)
+		}
+		if !ref.IsNameOnly() {
+			return fmt.Errorf("destination repository '%s' is invalid: must not have a tag or digest", c.Params.DestRepo)
+		}
+	}
+
+	if c.Params.DestAuthFile != "" {
+		destAuthFile, err := common.ResolveAuthFile(c.Params.DestAuthFile)
+		if err != nil {
+			return fmt.Errorf("destination auth file '%s' is invalid: %w", c.Params.DestAuthFile, err)
 		}
+		c.destAuthFile = destAuthFile
 	}
 
-	if c.Params.LabelWithTags != "" && !c.isImageLabelNameValid(c.Params.LabelWithTags) {
+	if c.Params.LabelWithTags != "" && !isImageLabelNameValid(c.Params.LabelWithTags) {
 		return fmt.Errorf("image label name '%s' is invalid", c.Params.LabelWithTags)
 	}
 
+	if c.Params.ExpiresAfter != "" {
+		if _, err := parseQuayExpiresAfter(c.Params.ExpiresAfter); err != nil {
+			return fmt.Errorf("tag expiration '%s' is invalid: %w", c.Params.ExpiresAfter, err)
+		}
+		if c.Params.QuayTokenDir == "" {
+			return fmt.Errorf("tag expiration '%s' is invalid: --quay-token-dir is required", c.Params.ExpiresAfter)
+		}
+	}
+
 	return nil
 }
 
@@ -299,7 +804,7 @@ func (c *ApplyTags) validateParams() error {
 // Image label should start and end with a letter.
 // Double separator is not allowed.
 // Image label max length is 256 characters.
-func (c *ApplyTags) isImageLabelNameValid(imageLabelName string) bool {
+func isImageLabelNameValid(imageLabelName string) bool {
 	if len(imageLabelName) == 0 || len(imageLabelName) > 256 {
 		return false
 	}
@@ -1,13 +1,20 @@
 package commands
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
 	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
 	"github.com/spf13/cobra"
 )
 
@@ -56,17 +63,16 @@ func Test_isImageLabelNameValid(t *testing.T) {
 		"label/_name",
 		"veryverylonglabelnameverylonglabelnameverylonglabelnameverylonglabelnameverylonglabelnameverylonglabelnameverylonglabelnameverylonglabelnameverylonglabelnameverylonglabelnameverylonglabelnameverylonglabelnameverylonglabelnameverylonglabelnameverylonglabelname",
 	}
-	c := &ApplyTags{}
 	for _, digest := range validImageLabelName {
 		t.Run("valid image label name", func(t *testing.T) {
-			if !c.isImageLabelNameValid(digest) {
+			if !isImageLabelNameValid(digest) {
 				t.Errorf("%s expected to be valid", digest)
 			}
 		})
 	}
 	for _, digest := range invalidImageLabelName {
 		t.Run("invalid image label name", func(t *testing.T) {
-			if c.isImageLabelNameValid(digest) {
+			if isImageLabelNameValid(digest) {
 				t.Errorf("%s expected to be invalid", digest)
 			}
 		})
@@ -152,6 +158,45 @@ func Test_validateParams(t *testing.T) {
 			errExpected:  true,
 			errSubstring: "tag",
 		},
+		{
+			name: "should allow destinations with transport",
+			params: ApplyTagsParams{
+				ImageUrl: "quay.io/org/image",
+				Digest:   "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				NewTags:  []string{"tag1", "docker://quay.io/org/archive:v1", "oci:/archive/layout:v1", "dir:/archive/dir"},
+			},
+			errExpected: false,
+		},
+		{
+			name: "should fail on unsupported transport",
+			params: ApplyTagsParams{
+				ImageUrl: "quay.io/org/image",
+				Digest:   "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				NewTags:  []string{"docker-daemon:image:v1"},
+			},
+			errExpected:  true,
+			errSubstring: "unsupported transport",
+		},
+		{
+			name: "should fail on registry destination without tag",
+			params: ApplyTagsParams{
+				ImageUrl: "quay.io/org/image",
+				Digest:   "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				NewTags:  []string{"docker://quay.io/org/archive"},
+			},
+			errExpected:  true,
+			errSubstring: "docker://quay.io/org/archive",
+		},
+		{
+			name: "should fail on local destination without path",
+			params: ApplyTagsParams{
+				ImageUrl: "quay.io/org/image",
+				Digest:   "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				NewTags:  []string{"oci::v1"},
+			},
+			errExpected:  true,
+			errSubstring: "missing path",
+		},
 		{
 			name: "should fail on invalid label name",
 			params: ApplyTagsParams{
@@ -163,6 +208,72 @@ func Test_validateParams(t *testing.T) {
 			errExpected:  true,
 			errSubstring: "image label name",
 		},
+		{
+			name: "should allow tag expiration with Quay token",
+			params: ApplyTagsParams{
+				ImageUrl:     "quay.io/org/image",
+				Digest:       "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				NewTags:      []string{"tag1"},
+				ExpiresAfter: "2d",
+				QuayTokenDir: "/mnt/quay-token",
+			},
+			errExpected: false,
+		},
+		{
+			name: "should fail on invalid tag expiration",
+			params: ApplyTagsParams{
+				ImageUrl:     "quay.io/org/image",
+				Digest:       "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				NewTags:      []string{"tag1"},
+				ExpiresAfter: "2 days",
+				QuayTokenDir: "/mnt/quay-token",
+			},
+			errExpected:  true,
+			errSubstring: "tag expiration",
+		},
+		{
+			name: "should allow destination repository",
+			params: ApplyTagsParams{
+				ImageUrl: "quay.io/org/app",
+				Digest:   "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				NewTags:  []string{"v1"},
+				DestRepo: "quay.io/org/app-released",
+			},
+			errExpected: false,
+		},
+		{
+			name: "should fail on destination repository with tag",
+			params: ApplyTagsParams{
+				ImageUrl: "quay.io/org/app",
+				Digest:   "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				NewTags:  []string{"v1"},
+				DestRepo: "quay.io/org/app-released:v1",
+			},
+			errExpected:  true,
+			errSubstring: "must not have a tag or digest",
+		},
+		{
+			name: "should fail on missing destination auth file",
+			params: ApplyTagsParams{
+				ImageUrl:     "quay.io/org/app",
+				Digest:       "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				NewTags:      []string{"v1"},
+				DestAuthFile: "/nonexistent/auth.json",
+			},
+			errExpected:  true,
+			errSubstring: "destination auth file",
+		},
+		{
+			name: "should fail on tag expiration without Quay token",
+			params: ApplyTagsParams{
+				ImageUrl:     "quay.io/org/image",
+				Digest:       "sha256:312515df62b06ed562904777a627032c93cbef945df527bcc332fe333cc0f94c",
+				NewTags:      []string{"tag1"},
+				ExpiresAfter: "2d",
+			},
+			errExpected:  true,
+			errSubstring: "--quay-token-dir",
+		},
 	}
 	c := &ApplyTags{}
 	for _, tc := range tests {
@@ -543,6 +654,7 @@ func Test_applyTags(t *testing.T) {
 
 	mockSkopeoCli := &mockSkopeoCli{}
 	c := &ApplyTags{
+		Params:        &ApplyTagsParams{TLSVerify: true},
 		CliWrappers:   ApplyTagsCliWrappers{SkopeoCli: mockSkopeoCli},
 		imageByDigest: imageRef,
 		imageName:     imageName,
@@ -578,6 +690,25 @@ func Test_applyTags(t *testing.T) {
 		g.Expect(err).ToNot(HaveOccurred())
 	})
 
+	t.Run("should write tags to destinations with transport", func(t *testing.T) {
+		var copied []cliwrappers.SkopeoCopyArgs
+		mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			copied = append(copied, *args)
+			return nil
+		}
+
+		err := c.applyTags([]string{"tag1", "docker://" + imageName + ":tag2", "oci:/archive/layout:tag3"})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(copied).To(HaveLen(3))
+		g.Expect(copied[0].DestinationImage).To(Equal(imageName + ":tag1"))
+		g.Expect(copied[0].MultiArch).To(Equal(cliwrappers.SkopeoCopyArgMultiArchIndexOnly))
+		g.Expect(copied[1].DestinationImage).To(Equal(imageName + ":tag2"))
+		g.Expect(copied[1].MultiArch).To(Equal(cliwrappers.SkopeoCopyArgMultiArchIndexOnly))
+		g.Expect(copied[2].DestinationImage).To(Equal("oci:/archive/layout:tag3"))
+		g.Expect(copied[2].MultiArch).To(Equal(cliwrappers.SkopeoCopyArgMultiArchAll))
+	})
+
 	t.Run("should error if creating tag failed", func(t *testing.T) {
 		tags := []string{"tag1", "tag2", "tag3", "tag4"}
 		scopeoCopyCalledTimes := 0
@@ -671,6 +802,116 @@ func Test_Run(t *testing.T) {
 		g.Expect(isCreateResultJsonCalled).To(BeTrue())
 	})
 
+	t.Run("should verify the image signature before creating tags", func(t *testing.T) {
+		beforeEach()
+		c.Params.NewTags = []string{"v1"}
+		c.Params.RequireSignature = true
+		c.Params.SignatureKey = "/keys/cosign.pub"
+		c.Params.TLSVerify = true
+
+		var calls []string
+		c.CliWrappers.CosignCli = &mockCosignCli{
+			VerifyFunc: func(args *cliwrappers.CosignVerifyArgs) error {
+				calls = append(calls, "verify")
+				g.Expect(args).To(Equal(&cliwrappers.CosignVerifyArgs{
+					CosignVerifyOptions: cliwrappers.CosignVerifyOptions{Key: "/keys/cosign.pub"},
+					ImageRef:            "quay.io/my-organization/namespace/image@" + c.Params.Digest,
+				}))
+				return nil
+			},
+		}
+		_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			calls = append(calls, "copy")
+			return nil
+		}
+		_mockResultsWriter.CreateResultJsonFunc = func(result any) (string, error) {
+			g.Expect(result.(ApplyTagsResults).SignatureVerified).To(BeTrue())
+			return "", nil
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+		g.Expect(calls).To(Equal([]string{"verify", "copy"}))
+	})
+
+	t.Run("should not create tags if the signature verification fails", func(t *testing.T) {
+		beforeEach()
+		c.Params.NewTags = []string{"v1"}
+		c.Params.RequireSignature = true
+		c.Params.SignatureKey = "/keys/cosign.pub"
+
+		c.CliWrappers.CosignCli = &mockCosignCli{
+			VerifyFunc: func(args *cliwrappers.CosignVerifyArgs) error {
+				return errors.New("no matching signatures")
+			},
+		}
+		_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			g.Fail("no tags should be created")
+			return nil
+		}
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("verifying signature of quay.io/my-organization/namespace/image@")))
+		g.Expect(err).To(MatchError(ContainSubstring("no matching signatures")))
+	})
+
+	t.Run("should fail validation of require-signature without a key or identity", func(t *testing.T) {
+		beforeEach()
+		c.Params.RequireSignature = true
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("keyless verification requires the certificate identity")))
+	})
+
+	t.Run("should pass the TLS options and the CA bundle to skopeo", func(t *testing.T) {
+		beforeEach()
+		c.Params.NewTags = []string{"tag1"}
+		c.Params.TLSVerify = true
+		c.Params.CertDir = t.TempDir()
+		g.Expect(os.WriteFile(filepath.Join(c.Params.CertDir, "ca.crt"), []byte("ca"), 0644)).To(Succeed())
+		c.Params.CABundleFile = filepath.Join(t.TempDir(), "bundle.pem")
+		g.Expect(os.WriteFile(c.Params.CABundleFile, []byte("bundle"), 0644)).To(Succeed())
+
+		var certDir string
+		_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			g.Expect(args.TLSVerify).To(HaveValue(BeTrue()))
+			certDir = args.CertDir
+			g.Expect(filepath.Join(certDir, "ca.crt")).To(BeARegularFile())
+			g.Expect(filepath.Join(certDir, "ca-bundle.crt")).To(BeARegularFile())
+			return nil
+		}
+		_mockResultsWriter.CreateResultJsonFunc = func(result any) (string, error) {
+			return "", nil
+		}
+
+		err := c.Run()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(certDir).ToNot(Equal(c.Params.CertDir))
+		g.Expect(certDir).ToNot(BeAnExistingFile())
+	})
+
+	t.Run("should configure and record the proxy", func(t *testing.T) {
+		beforeEach()
+		for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "NO_PROXY", "no_proxy"} {
+			t.Setenv(name, "")
+		}
+		c.Params.NewTags = []string{"tag1"}
+		c.Params.Proxy = "http://proxy:3128"
+		c.Params.NoProxy = "localhost"
+
+		_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			g.Expect(os.Getenv("HTTPS_PROXY")).To(Equal("http://proxy:3128"))
+			g.Expect(os.Getenv("NO_PROXY")).To(Equal("localhost"))
+			return nil
+		}
+		_mockResultsWriter.CreateResultJsonFunc = func(result any) (string, error) {
+			g.Expect(result.(ApplyTagsResults).Proxy).To(Equal(&common.ProxySettings{HttpProxy: "http://proxy:3128", NoProxy: "localhost"}))
+			return "", nil
+		}
+
+		err := c.Run()
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+
 	t.Run("should successfully run apply-tags with tags from label only", func(t *testing.T) {
 		beforeEach()
 		const labelWithTagsValue = "l1tag l2tag"
@@ -928,14 +1169,358 @@ func Test_Run(t *testing.T) {
 	})
 }
 
+func Test_Run_verify(t *testing.T) {
+	g := NewWithT(t)
+
+	const rawManifest = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[]}`
+	const otherManifest = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[]}`
+	imageDigest := digest.FromString(rawManifest).String()
+
+	newApplyTags := func(tags ...string) (*ApplyTags, *mockSkopeoCli) {
+		_mockSkopeoCli := &mockSkopeoCli{}
+		return &ApplyTags{
+			CliWrappers: ApplyTagsCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params: &ApplyTagsParams{
+				ImageUrl: "quay.io/org/app",
+				Digest:   imageDigest,
+				NewTags:  tags,
+				Verify:   true,
+			},
+			ResultsWriter: &mockResultsWriter{},
+		}, _mockSkopeoCli
+	}
+
+	t.Run("should report verified digests of the tags", func(t *testing.T) {
+		c, _mockSkopeoCli := newApplyTags("v1", "docker://quay.io/org/archive:v1", "oci:/archive/layout:v1")
+		var inspected []string
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			g.Expect(args.Raw).To(BeTrue())
+			inspected = append(inspected, args.ImageRef)
+			return rawManifest, nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(inspected).To(Equal([]string{"quay.io/org/app:v1", "quay.io/org/archive:v1"}))
+		g.Expect(c.Results.VerifiedDigests).To(Equal(map[string]string{
+			"v1":                              imageDigest,
+			"docker://quay.io/org/archive:v1": imageDigest,
+		}))
+	})
+
+	t.Run("should fail if a tag points to a different digest", func(t *testing.T) {
+		c, _mockSkopeoCli := newApplyTags("v1", "latest")
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			if args.ImageRef == "quay.io/org/app:latest" {
+				return otherManifest, nil
+			}
+			return rawManifest, nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("verifying tag 'latest'"))
+		g.Expect(err.Error()).To(ContainSubstring("overwritten by another writer"))
+	})
+
+	t.Run("should fail if a tag can't be inspected", func(t *testing.T) {
+		c, _mockSkopeoCli := newApplyTags("v1")
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			return "", errors.New("manifest unknown")
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("verifying tag 'v1'"))
+	})
+
+	t.Run("should not verify tags without --verify", func(t *testing.T) {
+		c, _mockSkopeoCli := newApplyTags("v1")
+		c.Params.Verify = false
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			return "", errors.New("unexpected inspect")
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Results.VerifiedDigests).To(BeNil())
+	})
+}
+
+func Test_Run_destinationRepo(t *testing.T) {
+	g := NewWithT(t)
+
+	const rawManifest = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[]}`
+	imageDigest := digest.FromString(rawManifest).String()
+
+	authFile := filepath.Join(t.TempDir(), "auth.json")
+	g.Expect(os.WriteFile(authFile, []byte(`{"auths":{"quay.io/org/app-released":{"auth":"dXNlcjpwYXNz"}}}`), 0600)).To(Succeed())
+
+	_mockSkopeoCli := &mockSkopeoCli{}
+	var copied []cliwrappers.SkopeoCopyArgs
+	_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+		copied = append(copied, *args)
+		return nil
+	}
+	var inspected []cliwrappers.SkopeoInspectArgs
+	_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+		inspected = append(inspected, *args)
+		return rawManifest, nil
+	}
+	c := &ApplyTags{
+		CliWrappers: ApplyTagsCliWrappers{SkopeoCli: _mockSkopeoCli},
+		Params: &ApplyTagsParams{
+			ImageUrl:     "quay.io/org/app:v1",
+			Digest:       imageDigest,
+			NewTags:      []string{"v1", "latest"},
+			DestRepo:     "quay.io/org/app-released",
+			DestAuthFile: authFile,
+			Verify:       true,
+		},
+		ResultsWriter: &mockResultsWriter{},
+	}
+
+	err := c.Run()
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(copied).To(HaveLen(2))
+	for i, tag := range []string{"v1", "latest"} {
+		g.Expect(copied[i].SourceImage).To(Equal("quay.io/org/app@" + imageDigest))
+		g.Expect(copied[i].DestinationImage).To(Equal("quay.io/org/app-released:" + tag))
+		g.Expect(copied[i].DestinationAuthFile).To(Equal(authFile))
+	}
+	g.Expect(inspected).To(HaveLen(2))
+	g.Expect(inspected[0].ImageRef).To(Equal("quay.io/org/app-released:v1"))
+	g.Expect(inspected[0].AuthFile).To(Equal(authFile))
+	g.Expect(c.Results.VerifiedDigests).To(HaveKeyWithValue("latest", imageDigest))
+}
+
+func Test_Run_dryRunAndSkipExisting(t *testing.T) {
+	g := NewWithT(t)
+
+	const rawManifest = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[]}`
+	const otherManifest = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[]}`
+	imageDigest := digest.FromString(rawManifest).String()
+
+	newApplyTags := func(tags ...string) (*ApplyTags, *mockSkopeoCli, *[]string) {
+		var copied []string
+		_mockSkopeoCli := &mockSkopeoCli{
+			CopyFunc: func(args *cliwrappers.SkopeoCopyArgs) error {
+				copied = append(copied, args.DestinationImage)
+				return nil
+			},
+		}
+		return &ApplyTags{
+			CliWrappers: ApplyTagsCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params: &ApplyTagsParams{
+				ImageUrl: "quay.io/org/app",
+				Digest:   imageDigest,
+				NewTags:  tags,
+			},
+			ResultsWriter: &mockResultsWriter{},
+		}, _mockSkopeoCli, &copied
+	}
+
+	t.Run("should not copy the image in dry-run mode", func(t *testing.T) {
+		c, _mockSkopeoCli, copied := newApplyTags("v1", "oci:/archive/layout:v1")
+		c.Params.DryRun = true
+		c.Params.Verify = true
+		var inspected []string
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			inspected = append(inspected, args.ImageRef)
+			return rawManifest, nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(*copied).To(BeEmpty())
+		g.Expect(inspected).To(Equal([]string{"quay.io/org/app@" + imageDigest}))
+		g.Expect(c.Results.Tags).To(Equal([]string{"v1", "oci:/archive/layout:v1"}))
+		g.Expect(c.Results.DryRun).To(BeTrue())
+		g.Expect(c.Results.VerifiedDigests).To(BeNil())
+	})
+
+	t.Run("should fail in dry-run mode if the image doesn't exist", func(t *testing.T) {
+		c, _mockSkopeoCli, _ := newApplyTags("v1")
+		c.Params.DryRun = true
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			return "", errors.New("manifest unknown")
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(MatchError(ContainSubstring("inspecting quay.io/org/app@" + imageDigest + ": manifest unknown")))
+	})
+
+	t.Run("should skip tags already pointing to the image", func(t *testing.T) {
+		c, _mockSkopeoCli, copied := newApplyTags("v1", "latest", "new", "docker://quay.io/org/archive:v1", "oci:/archive/layout:v1")
+		c.Params.SkipExisting = true
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			g.Expect(args.Raw).To(BeTrue())
+			switch args.ImageRef {
+			case "quay.io/org/app:v1", "quay.io/org/archive:v1":
+				return rawManifest, nil
+			case "quay.io/org/app:latest":
+				return otherManifest, nil
+			}
+			return "", errors.New("manifest unknown")
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(*copied).To(Equal([]string{"quay.io/org/app:latest", "quay.io/org/app:new", "oci:/archive/layout:v1"}))
+		g.Expect(c.Results.Tags).To(Equal([]string{"v1", "latest", "new", "docker://quay.io/org/archive:v1", "oci:/archive/layout:v1"}))
+		g.Expect(c.Results.SkippedTags).To(Equal([]string{"v1", "docker://quay.io/org/archive:v1"}))
+	})
+
+	t.Run("should report the tags that would be skipped in dry-run mode", func(t *testing.T) {
+		c, _mockSkopeoCli, copied := newApplyTags("v1", "new")
+		c.Params.DryRun = true
+		c.Params.SkipExisting = true
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			if args.ImageRef == "quay.io/org/app:new" {
+				return "", errors.New("manifest unknown")
+			}
+			return rawManifest, nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(*copied).To(BeEmpty())
+		g.Expect(c.Results.SkippedTags).To(Equal([]string{"v1"}))
+		g.Expect(c.Results.DryRun).To(BeTrue())
+	})
+}
+
+func Test_Run_tagExpiration(t *testing.T) {
+	g := NewWithT(t)
+
+	type expirationRequest struct {
+		path          string
+		authorization string
+		expiration    int64
+	}
+	var requests []expirationRequest
+	responseStatus := http.StatusCreated
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.Method).To(Equal(http.MethodPut))
+		var body struct {
+			Expiration int64 `json:"expiration"`
+		}
+		g.Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+		requests = append(requests, expirationRequest{r.URL.Path, r.Header.Get("Authorization"), body.Expiration})
+		w.WriteHeader(responseStatus)
+	}))
+	defer server.Close()
+
+	tokenDir := t.TempDir()
+	g.Expect(os.WriteFile(filepath.Join(tokenDir, "token"), []byte("quay-token\n"), 0600)).To(Succeed())
+
+	var _mockSkopeoCli *mockSkopeoCli
+	var c *ApplyTags
+	beforeEach := func() {
+		requests = nil
+		responseStatus = http.StatusCreated
+		_mockSkopeoCli = &mockSkopeoCli{}
+		c = &ApplyTags{
+			CliWrappers: ApplyTagsCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params: &ApplyTagsParams{
+				ImageUrl:     "quay.io/my-org/image",
+				Digest:       "sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c",
+				ExpiresAfter: "2h",
+				QuayTokenDir: tokenDir,
+			},
+			ResultsWriter: &mockResultsWriter{},
+			quayApiUrl:    server.URL + "/api/v1",
+			httpClient:    server.Client(),
+		}
+	}
+
+	t.Run("should set expiration of tags on quay.io", func(t *testing.T) {
+		beforeEach()
+		c.Params.NewTags = []string{"tag1", "docker://quay.io/other-org/archive:v1", "docker://registry.io/org/image:v1", "oci:/layout:v1"}
+		copyCalled := 0
+		_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			copyCalled++
+			return nil
+		}
+
+		start := time.Now()
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(copyCalled).To(Equal(4))
+		g.Expect(requests).To(HaveLen(2))
+		g.Expect(requests[0].path).To(Equal("/api/v1/repository/my-org/image/tag/tag1"))
+		g.Expect(requests[1].path).To(Equal("/api/v1/repository/other-org/archive/tag/v1"))
+		for _, request := range requests {
+			g.Expect(request.authorization).To(Equal("Bearer quay-token"))
+			g.Expect(request.expiration).To(BeNumerically("~", start.Add(2*time.Hour).Unix(), 5))
+		}
+	})
+
+	t.Run("should error if Quay API request failed", func(t *testing.T) {
+		beforeEach()
+		c.Params.NewTags = []string{"tag1"}
+		_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error { return nil }
+		responseStatus = http.StatusForbidden
+
+		err := c.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("403"))
+	})
+
+	t.Run("should error before creating tags if Quay token is missing", func(t *testing.T) {
+		beforeEach()
+		c.Params.NewTags = []string{"tag1"}
+		c.Params.QuayTokenDir = t.TempDir()
+		copyCalled := false
+		_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			copyCalled = true
+			return nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("reading Quay token"))
+		g.Expect(copyCalled).To(BeFalse())
+	})
+}
+
+func Test_parseQuayExpiresAfter(t *testing.T) {
+	g := NewWithT(t)
+
+	for input, expected := range map[string]time.Duration{
+		"30s": 30 * time.Second,
+		"1h":  time.Hour,
+		"2d":  48 * time.Hour,
+		"3w":  21 * 24 * time.Hour,
+	} {
+		d, err := parseQuayExpiresAfter(input)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(d).To(Equal(expected))
+	}
+	for _, input := range []string{"", "0d", "1y", "1.5h", "h"} {
+		_, err := parseQuayExpiresAfter(input)
+		g.Expect(err).To(HaveOccurred(), input)
+	}
+}
+
 func Test_NewApplyTags(t *testing.T) {
 	g := NewWithT(t)
 
 	t.Run("should create ApplyTags instance", func(t *testing.T) {
 		cmd := &cobra.Command{}
-		cmd.Flags().String("image-url", "", "image")
-		cmd.Flags().String("digest", "", "digest")
-		cmd.Flags().StringArray("tags", nil, "tags")
+		common.RegisterParameters(cmd, ApplyTagsParamsConfig)
 		parseErr := cmd.Flags().Parse([]string{
 			"--image-url", "image",
 			"--digest", "sha256:abcdef1234",
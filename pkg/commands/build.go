@@ -8,7 +8,9 @@ import (
 	"io/fs"
 	"log/slog"
 	"maps"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -17,20 +19,24 @@ import (
 	"strings"
 	"time"
 
-	"github.com/containers/image/v5/docker/reference"
 	capo "github.com/konflux-ci/capo/pkg"
 	capoContainerfile "github.com/konflux-ci/capo/pkg/containerfile"
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
 	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands/gitclone"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
 	dfeditor "github.com/konflux-ci/konflux-build-cli/pkg/common/containerfile_editor"
 	"github.com/opencontainers/go-digest"
 	"github.com/package-url/packageurl-go"
 	sloglogrus "github.com/samber/slog-logrus/v2"
 	"github.com/spf13/cobra"
+	"go.podman.io/image/v5/docker/reference"
 
 	"github.com/containerd/platforms"
+	"github.com/docker/go-units"
 	"github.com/keilerkonzept/dockerfile-json/pkg/buildargs"
 	"github.com/keilerkonzept/dockerfile-json/pkg/dockerfile"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
 )
@@ -38,6 +44,7 @@ import (
 const (
 	defaultPrefetchOutputMount = "/tmp/.prefetch-output"
 	defaultPrefetchEnvMount    = "/tmp/.prefetch.env"
+	defaultRHSMActivationMount = "/activation-key"
 
 	envVarInUserNamespace = "_KBC_IN_USER_NAMESPACE"
 )
@@ -51,13 +58,30 @@ var BuildParamsConfig = map[string]common.Parameter{
 		DefaultValue: "",
 		Usage:        "Path to Containerfile. Tries with prepended --context first before falling back to the direct path.\nIf not specified, uses Containerfile/Dockerfile from the context directory.",
 	},
+	"containerfile-json": {
+		Name:       "containerfile-json",
+		ShortName:  "",
+		EnvVarName: "KBC_BUILD_CONTAINERFILE_JSON",
+		TypeKind:   reflect.String,
+		Usage: "Path to a Containerfile JSON representation, in the format written by --containerfile-json-output." +
+			"\nThe build uses a Containerfile generated from it. Mutually exclusive with --containerfile.",
+	},
 	"context": {
 		Name:         "context",
 		ShortName:    "c",
 		EnvVarName:   "KBC_BUILD_CONTEXT",
 		TypeKind:     reflect.String,
 		DefaultValue: ".",
-		Usage:        "Build context directory.",
+		Usage: "Build context directory." +
+			"\nAlso accepts a remote context: an https:// git URL, optionally with #ref[:subdir], or the https:// URL of a .tar.gz archive." +
+			"\nThe remote context is fetched into a temporary directory, removed after the build.",
+	},
+	"git-basic-auth-directory": {
+		Name:       "git-basic-auth-directory",
+		ShortName:  "",
+		EnvVarName: "KBC_BUILD_GIT_BASIC_AUTH_DIRECTORY",
+		TypeKind:   reflect.String,
+		Usage:      "Directory with the git credentials for a remote git --context, in the format of git-clone --basic-auth-directory.",
 	},
 	"source": {
 		Name:         "source",
@@ -82,7 +106,7 @@ var BuildParamsConfig = map[string]common.Parameter{
 		ShortName:  "",
 		EnvVarName: "KBC_BUILD_ADDITIONAL_TAGS",
 		TypeKind:   reflect.Slice,
-		Usage:      "Additional tags to apply to the output image.",
+		Usage:      "Additional tags to apply to the output image. The tags are pushed along with the output image when --push is set.",
 	},
 	"push": {
 		Name:         "push",
@@ -92,6 +116,57 @@ var BuildParamsConfig = map[string]common.Parameter{
 		DefaultValue: "false",
 		Usage:        "Push the built image (and its additional tags, if any) to the registry.",
 	},
+	"build-timeout": {
+		Name:       "build-timeout",
+		EnvVarName: "KBC_BUILD_BUILD_TIMEOUT",
+		TypeKind:   reflect.String,
+		Usage: "Stop buildah build if it takes longer than this duration (e.g. 90m, 2h). No timeout by default.\n" +
+			"buildah gets SIGTERM, and SIGKILL if it doesn't exit within 10 seconds.",
+	},
+	"push-timeout": {
+		Name:       "push-timeout",
+		EnvVarName: "KBC_BUILD_PUSH_TIMEOUT",
+		TypeKind:   reflect.String,
+		Usage:      "Stop each attempt to push the image (or a tag) if it takes longer than this duration (e.g. 10m). No timeout by default.",
+	},
+	"resolve-base-images": {
+		Name:         "resolve-base-images",
+		ShortName:    "",
+		EnvVarName:   "KBC_BUILD_RESOLVE_BASE_IMAGES",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Build from the FROM images pinned to the digests reported in the base_images result, so that a tag moving during the build can't change it.\nNot supported for multi-platform builds.",
+	},
+	"require-pinned-base-images": {
+		Name:       "require-pinned-base-images",
+		EnvVarName: "KBC_BUILD_REQUIRE_PINNED_BASE_IMAGES",
+		TypeKind:   reflect.Bool,
+		Usage: "Fail before building if a FROM instruction references a base image by a tag instead of a digest." +
+			"\nStages built from other stages or from scratch are allowed. The Containerfile is checked before --resolve-base-images pins the tags.",
+	},
+	"lint": {
+		Name:         "lint",
+		ShortName:    "",
+		EnvVarName:   "KBC_BUILD_LINT",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Lint the Containerfile before building, see the image lint command for the rules.",
+	},
+	"lint-fail-on": {
+		Name:         "lint-fail-on",
+		ShortName:    "",
+		EnvVarName:   "KBC_BUILD_LINT_FAIL_ON",
+		TypeKind:     reflect.String,
+		DefaultValue: LintSeverityError,
+		Usage:        "With --lint, fail the build if there's a finding of this severity or higher: 'info', 'warning', 'error' or 'never'.",
+	},
+	"output": {
+		Name:       "output",
+		ShortName:  "",
+		EnvVarName: "KBC_BUILD_OUTPUT",
+		TypeKind:   reflect.String,
+		Usage:      "Write the built image to a local OCI layout: 'oci-archive:<path>' or 'oci:<dir>'. Can be combined with --push.",
+	},
 	"secret-dirs": {
 		Name:       "secret-dirs",
 		ShortName:  "",
@@ -99,6 +174,22 @@ var BuildParamsConfig = map[string]common.Parameter{
 		TypeKind:   reflect.Slice,
 		Usage:      "Directories containing secret files to make available during build.",
 	},
+	"on-duplicate-secret": {
+		Name:         "on-duplicate-secret",
+		ShortName:    "",
+		EnvVarName:   "KBC_BUILD_ON_DUPLICATE_SECRET",
+		TypeKind:     reflect.String,
+		DefaultValue: "error",
+		Usage:        "What to do when two --secret-dirs files resolve to the same secret ID: 'error', 'suffix' (rename to e.g. 'secret1/token-2') or 'skip' (keep the first one).",
+	},
+	"allow-unused-secrets": {
+		Name:         "allow-unused-secrets",
+		ShortName:    "",
+		EnvVarName:   "KBC_BUILD_ALLOW_UNUSED_SECRETS",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Don't fail when --secret-dirs provide secrets that no RUN --mount=type=secret in the Containerfile uses.",
+	},
 	"workdir-mount": {
 		Name:         "workdir-mount",
 		ShortName:    "",
@@ -107,6 +198,15 @@ var BuildParamsConfig = map[string]common.Parameter{
 		DefaultValue: "",
 		Usage:        "Mount the context directory (which is also the workdir) into the build with '--volume $PWD:$WORKDIR_MOUNT'.",
 	},
+	"volumes": {
+		Name:       "volumes",
+		ShortName:  "",
+		EnvVarName: "KBC_BUILD_VOLUMES",
+		TypeKind:   reflect.Slice,
+		Usage: "Additional volumes to mount into the build in the host-dir:container-dir[:options] format. " +
+			"Relative host directories are resolved against the current directory. " +
+			"With the optional=true option, a missing host directory is skipped instead of failing the build.",
+	},
 	"build-args": {
 		Name:       "build-args",
 		ShortName:  "",
@@ -223,6 +323,17 @@ var BuildParamsConfig = map[string]common.Parameter{
 		DefaultValue: "false",
 		Usage:        "When injecting files to /usr/share/buildinfo/, also inject them to /root/buildinfo/.",
 	},
+	"inject-content-sets": {
+		Name:         "inject-content-sets",
+		ShortName:    "",
+		EnvVarName:   "KBC_BUILD_INJECT_CONTENT_SETS",
+		TypeKind:     reflect.String,
+		DefaultValue: "auto",
+		Usage: "How to inject the content sets (RPM repositories) of the prefetched dependencies into /usr/share/buildinfo/. " +
+			"Valid values are 'auto', 'content-manifests', 'never'.\n" +
+			"'auto' adds content-sets.json if there is a prefetch SBOM, 'content-manifests' also adds " +
+			"content_manifests/<component>-<version>-<release>.json, the location used by OSBS-built images.",
+	},
 	"inherit-labels": {
 		Name:         "inherit-labels",
 		ShortName:    "",
@@ -268,6 +379,19 @@ var BuildParamsConfig = map[string]common.Parameter{
 		TypeKind:   reflect.String,
 		Usage:      "Set NO_PROXY for base image pulls.",
 	},
+	"proxy": {
+		Name:       "proxy",
+		EnvVarName: "KBC_BUILD_PROXY",
+		TypeKind:   reflect.String,
+		Usage: "HTTP(S) proxy for the registries and the RUN instructions of the build, set as HTTP_PROXY and HTTPS_PROXY " +
+			"environment variables and build arguments.\nDefaults to the HTTPS_PROXY or HTTP_PROXY environment variable. --image-pull-proxy takes precedence for base image pulls.",
+	},
+	"no-proxy": {
+		Name:       "no-proxy",
+		EnvVarName: "KBC_BUILD_NO_PROXY",
+		TypeKind:   reflect.String,
+		Usage:      "Hosts to access without the --proxy, set as NO_PROXY. Defaults to the NO_PROXY environment variable.",
+	},
 	"yum-repos-d-sources": {
 		Name:       "yum-repos-d-sources",
 		ShortName:  "",
@@ -345,6 +469,13 @@ var BuildParamsConfig = map[string]common.Parameter{
 		TypeKind:   reflect.String,
 		Usage:      "File containing an RHSM organization ID.\nSee 'Red Hat Subscription Management' in the help text for more details.",
 	},
+	"rhsm-activation-key-dir": {
+		Name:       "rhsm-activation-key-dir",
+		ShortName:  "",
+		EnvVarName: "KBC_BUILD_RHSM_ACTIVATION_KEY_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Directory with 'activationkey' and 'org' files, an alternative to rhsm-activation-key and rhsm-org.\nSee 'Red Hat Subscription Management' in the help text for more details.",
+	},
 	"rhsm-activation-mount": {
 		Name:       "rhsm-activation-mount",
 		ShortName:  "",
@@ -381,6 +512,57 @@ var BuildParamsConfig = map[string]common.Parameter{
 		DefaultValue: "true",
 		Usage:        "Require HTTPS and verify certificates when pushing to the destination registry.",
 	},
+	"cert-dir": {
+		Name:       "cert-dir",
+		EnvVarName: "KBC_BUILD_CERT_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Directory with the certificates (*.crt CA certificates, *.cert and *.key client certificates) for accessing the registries.",
+	},
+	"ca-bundle-file": {
+		Name:       "ca-bundle-file",
+		EnvVarName: "KBC_BUILD_CA_BUNDLE_FILE",
+		TypeKind:   reflect.String,
+		Usage:      "File with CA certificates to trust for accessing the registries, in addition to the system trust store and --cert-dir.",
+	},
+	"format": {
+		Name:       "format",
+		EnvVarName: "KBC_BUILD_FORMAT",
+		TypeKind:   reflect.String,
+		Usage:      "Manifest format of the built (and pushed) image: 'oci' or 'docker'.\nIf not set, buildah's default is used (oci). Some consumers still require Docker schema2 manifests.",
+	},
+	"sanitize-context": {
+		Name:       "sanitize-context",
+		EnvVarName: "KBC_BUILD_SANITIZE_CONTEXT",
+		TypeKind:   reflect.Bool,
+		Usage:      "Build from a temporary copy of the context directory without .git metadata, editor caches and --sanitize-context-excludes.\nThe original context directory is not modified.",
+	},
+	"sanitize-context-excludes": {
+		Name:       "sanitize-context-excludes",
+		EnvVarName: "KBC_BUILD_SANITIZE_CONTEXT_EXCLUDES",
+		TypeKind:   reflect.Slice,
+		Usage:      "Additional patterns to exclude from the sanitized context. Patterns without a '/' match the basename of any file or directory,\nother patterns match the path relative to the context directory. Implies --sanitize-context.",
+	},
+	"containerignore-from-gitignore": {
+		Name:       "containerignore-from-gitignore",
+		EnvVarName: "KBC_BUILD_CONTAINERIGNORE_FROM_GITIGNORE",
+		TypeKind:   reflect.Bool,
+		Usage: "If the context has no .containerignore/.dockerignore, exclude the .git directory and the files matched by the .gitignore files of the context from it." +
+			"\nThe ignore file is generated into a temporary file, the context directory is not modified.",
+	},
+	"max-context-size": {
+		Name:       "max-context-size",
+		EnvVarName: "KBC_BUILD_MAX_CONTEXT_SIZE",
+		TypeKind:   reflect.String,
+		Usage: "Fail before building if the context, without the files excluded by its .containerignore/.dockerignore, is larger than this size, e.g. 500MiB or 2GiB." +
+			"\nThe context is scanned and its size reported regardless.",
+	},
+	"context-report-top-files": {
+		Name:         "context-report-top-files",
+		EnvVarName:   "KBC_BUILD_CONTEXT_REPORT_TOP_FILES",
+		TypeKind:     reflect.Int,
+		DefaultValue: "10",
+		Usage:        "Number of the largest files of the context to report in the logs and results. Set to 0 to report only the total size.",
+	},
 	"squash": {
 		Name:       "squash",
 		EnvVarName: "KBC_BUILD_SQUASH",
@@ -399,29 +581,76 @@ var BuildParamsConfig = map[string]common.Parameter{
 		TypeKind:   reflect.Bool,
 		Usage:      "Do not use existing cached images for the container build.",
 	},
+	"layers": {
+		Name:       "layers",
+		EnvVarName: "KBC_BUILD_LAYERS",
+		TypeKind:   reflect.Bool,
+		Usage:      "Cache intermediate images during the build. Implied by --cache-from and --cache-to.",
+	},
+	"cache-from": {
+		Name:       "cache-from",
+		EnvVarName: "KBC_BUILD_CACHE_FROM",
+		TypeKind:   reflect.Slice,
+		Usage:      "Repository (without tag or digest) to look up cached layers in, e.g. quay.io/org/app-cache. Can be repeated.",
+	},
+	"cache-to": {
+		Name:       "cache-to",
+		EnvVarName: "KBC_BUILD_CACHE_TO",
+		TypeKind:   reflect.Slice,
+		Usage:      "Repository (without tag or digest) to push the cached layers to, e.g. quay.io/org/app-cache. Can be repeated.",
+	},
+	"storage-dir": {
+		Name:       "storage-dir",
+		EnvVarName: "KBC_BUILD_STORAGE_DIR",
+		TypeKind:   reflect.String,
+		Usage: "Container storage directory of buildah (buildah --root), e.g. a persistent volume shared by the builds to reuse the pulled images and cached layers." +
+			"\nDefaults to the graphroot of storage.conf, usually /var/lib/containers/storage.",
+	},
+	"cache-prune-older-than": {
+		Name:       "cache-prune-older-than",
+		EnvVarName: "KBC_BUILD_CACHE_PRUNE_OLDER_THAN",
+		TypeKind:   reflect.String,
+		Usage: "Before building, remove the images created earlier than this age (e.g. 12h, 7d, 2w) from the container storage, including the cached layers " +
+			"and the pulled base images.\nImages that are in use, e.g. by other builds sharing the storage, are kept.",
+	},
 	"security-opts": {
 		Name:       "security-opts",
 		EnvVarName: "KBC_BUILD_SECURITY_OPTS",
 		TypeKind:   reflect.Slice,
 		Usage:      "Security options to pass to buildah's --security-opt.",
 	},
+	"isolation": {
+		Name:       "isolation",
+		EnvVarName: "KBC_BUILD_ISOLATION",
+		TypeKind:   reflect.String,
+		Usage: "Isolation of the RUN instructions: 'chroot', 'oci' or 'rootless'. " +
+			"Defaults to BUILDAH_ISOLATION, or buildah's default if not set.",
+	},
+	"userns": {
+		Name:       "userns",
+		EnvVarName: "KBC_BUILD_USERNS",
+		TypeKind:   reflect.String,
+		Usage: "User namespace of the RUN instructions: 'private' for a new one, 'host' to reuse the one buildah runs in, " +
+			"or the path of the user namespace of another process, e.g. /proc/1234/ns/user.",
+	},
 	"cap-add": {
 		Name:       "cap-add",
 		EnvVarName: "KBC_BUILD_CAP_ADD",
 		TypeKind:   reflect.Slice,
-		Usage:      "Capabilities to add when running the build.",
+		Usage:      "Capabilities to add when running the build, e.g. CAP_SYS_ADMIN or SYS_ADMIN, or 'all'.",
 	},
 	"cap-drop": {
 		Name:       "cap-drop",
 		EnvVarName: "KBC_BUILD_CAP_DROP",
 		TypeKind:   reflect.Slice,
-		Usage:      "Capabilities to drop when running the build.",
+		Usage:      "Capabilities to drop when running the build, e.g. CAP_NET_RAW or NET_RAW, or 'all'.",
 	},
 	"devices": {
 		Name:       "devices",
 		EnvVarName: "KBC_BUILD_DEVICES",
 		TypeKind:   reflect.Slice,
-		Usage:      "Additional devices to provide during the build.",
+		Usage: "Additional devices to provide during the build: HOST-DEVICE[:CONTAINER-DEVICE][:PERMISSIONS] " +
+			"with rwm permissions, e.g. /dev/fuse:/dev/fuse:rw, or a CDI device name, e.g. nvidia.com/gpu=all.",
 	},
 	"ulimits": {
 		Name:       "ulimits",
@@ -436,6 +665,14 @@ var BuildParamsConfig = map[string]common.Parameter{
 		DefaultValue: "false",
 		Usage:        "Allow base images with a different architecture than the host.\nEmits a warning instead of failing.",
 	},
+	"platform": {
+		Name:       "platform",
+		EnvVarName: "KBC_BUILD_PLATFORM",
+		TypeKind:   reflect.Slice,
+		Usage: "Platform to build the image for, e.g. linux/arm64. Defaults to the host platform.\n" +
+			"Repeat the flag or use a comma-separated list to build a multi-platform image,\n" +
+			"which is stored and pushed as a manifest list.",
+	},
 	"syft-source-output": {
 		Name:       "syft-source-output",
 		EnvVarName: "KBC_BUILD_SYFT_SOURCE_OUTPUT",
@@ -462,17 +699,138 @@ var BuildParamsConfig = map[string]common.Parameter{
 		DefaultValue: "spdx",
 		Usage:        "SBOM output format (spdx or cyclonedx).",
 	},
+	"sbom-output": {
+		Name:       "sbom-output",
+		EnvVarName: "KBC_BUILD_SBOM_OUTPUT",
+		TypeKind:   reflect.String,
+		Usage: "File path where to write the SBOM of the built image. Merges the syft scan of the image with\n" +
+			"the scan of the context directory (if --syft-source-output is set) and the SBOM of the prefetched dependencies.",
+	},
+	"bootc": {
+		Name:         "bootc",
+		EnvVarName:   "KBC_BUILD_BOOTC",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage: "Build a bootc (ostree container) image. Requires a bootc base image, uses the oci format, " +
+			"and reports the logically bound images of the built image.",
+	},
+	"checkpoint-file": {
+		Name:       "checkpoint-file",
+		EnvVarName: "KBC_BUILD_CHECKPOINT_FILE",
+		TypeKind:   reflect.String,
+		Usage:      "Record the completed phases of the build (built image, pushed image and tags) to this file.",
+	},
+	"resume": {
+		Name:         "resume",
+		EnvVarName:   "KBC_BUILD_RESUME",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage: "Skip the phases recorded as completed in --checkpoint-file by a previous, interrupted run. " +
+			"The checkpoint is ignored if the Containerfile or the parameters changed.",
+	},
+	"remote-host": {
+		Name:       "remote-host",
+		EnvVarName: "KBC_BUILD_REMOTE_HOST",
+		TypeKind:   reflect.String,
+		Usage: "Run the build on this host over SSH, [user@]host, e.g. a native arm64, s390x or ppc64le builder. " +
+			"The context, the Containerfile, the secrets and the registry auth file are copied to the host with rsync " +
+			"and the logs are streamed back.\nThe built image is copied back into the local storage, unless --remote-push is set. " +
+			"The host needs buildah and rsync, the ssh config of the host applies, e.g. the port.",
+	},
+	"remote-ssh-key": {
+		Name:       "remote-ssh-key",
+		EnvVarName: "KBC_BUILD_REMOTE_SSH_KEY",
+		TypeKind:   reflect.String,
+		Usage:      "Private key to authenticate to --remote-host with. Defaults to the keys of the ssh agent and config.",
+	},
+	"remote-known-hosts": {
+		Name:       "remote-known-hosts",
+		EnvVarName: "KBC_BUILD_REMOTE_KNOWN_HOSTS",
+		TypeKind:   reflect.String,
+		Usage: "known_hosts file with the host key of --remote-host, required with --remote-host. " +
+			"The build fails if the host doesn't present this key, the secrets and the registry auth file are not sent to it.",
+	},
+	"remote-push": {
+		Name:         "remote-push",
+		EnvVarName:   "KBC_BUILD_REMOTE_PUSH",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage: "Push the image from --remote-host instead of copying it back into the local storage first. Requires --push. " +
+			"Not supported with the options that need the image locally, e.g. --output or --sbom-output.",
+	},
+	"engine": {
+		Name:         "engine",
+		EnvVarName:   "KBC_BUILD_ENGINE",
+		TypeKind:     reflect.String,
+		DefaultValue: "buildah",
+		Usage: "Tool that builds the image: 'buildah', 'podman' or 'docker' (docker buildx), e.g. in developer environments without buildah. " +
+			"podman and docker support a subset of the options, e.g. not --hermetic, --bootc or building several platforms.",
+	},
+	"native-push": {
+		Name:         "native-push",
+		EnvVarName:   "KBC_BUILD_NATIVE_PUSH",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage: "Push the image from the --output OCI layout in-process with containers/image instead of running the push of the engine, " +
+			"e.g. buildah push. Requires --push and --output.",
+	},
+	"chroot-workdir": {
+		Name:       "chroot-workdir",
+		EnvVarName: "KBC_BUILD_CHROOT_WORKDIR",
+		TypeKind:   reflect.String,
+		Usage: "Workspace root directory the user-provided host paths must resolve inside, following symlinks: " +
+			"the source, the context, the containerfile, --containerfile-json, the secret dirs, the volumes, " +
+			"--build-args-file, --annotations-file, --prefetch-dir, --prefetch-dir-copy, --yum-repos-d-sources, " +
+			"--rhsm-activation-key-dir, --rhsm-activation-key, --rhsm-org, --rhsm-entitlements, --cert-dir, " +
+			"--ca-bundle-file, --git-basic-auth-directory, --storage-dir, --remote-ssh-key, --remote-known-hosts, --checkpoint-file, " +
+			"--pre-build-script, --post-build-script and the written --output, --sbom-output, " +
+			"--containerfile-json-output, --syft-source-output, --syft-image-output, --builder-metadata-output " +
+			"and --resolved-base-images-output. The build fails if any of them is outside.",
+	},
+	"pre-build-script": {
+		Name:       "pre-build-script",
+		EnvVarName: "KBC_BUILD_PRE_BUILD_SCRIPT",
+		TypeKind:   reflect.String,
+		Usage: "Executable to run in the context directory right before the image is built, a failure fails the build.\n" +
+			"It gets the KBC_HOOK_* environment variables, e.g. KBC_HOOK_IMAGE_URL, KBC_HOOK_CONTEXT_DIR and KBC_HOOK_CONTAINERFILE.",
+	},
+	"post-build-script": {
+		Name:       "post-build-script",
+		EnvVarName: "KBC_BUILD_POST_BUILD_SCRIPT",
+		TypeKind:   reflect.String,
+		Usage: "Executable to run in the context directory after the image is built, pushed and written to --output, a failure fails the build.\n" +
+			"It gets the same environment variables as --pre-build-script, and KBC_HOOK_IMAGE_DIGEST and KBC_HOOK_TAGS.",
+	},
+	"hook-timeout": {
+		Name:         "hook-timeout",
+		EnvVarName:   "KBC_BUILD_HOOK_TIMEOUT",
+		TypeKind:     reflect.String,
+		DefaultValue: "10m",
+		Usage:        "Stop --pre-build-script or --post-build-script if it takes longer than this duration (e.g. 30s, 10m). Empty for no timeout.",
+	},
 }
 
 type BuildParams struct {
 	Containerfile              string   `paramName:"containerfile"`
+	ContainerfileJson          string   `paramName:"containerfile-json"`
 	Context                    string   `paramName:"context"`
 	Source                     string   `paramName:"source"`
+	GitBasicAuthDirectory      string   `paramName:"git-basic-auth-directory"`
 	OutputRef                  string   `paramName:"output-ref"`
 	AdditionalTags             []string `paramName:"additional-tags"`
 	Push                       bool     `paramName:"push"`
+	BuildTimeout               string   `paramName:"build-timeout"`
+	PushTimeout                string   `paramName:"push-timeout"`
+	Output                     string   `paramName:"output"`
+	ResolveBaseImages          bool     `paramName:"resolve-base-images"`
+	RequirePinnedBaseImages    bool     `paramName:"require-pinned-base-images"`
+	Lint                       bool     `paramName:"lint"`
+	LintFailOn                 string   `paramName:"lint-fail-on"`
 	SecretDirs                 []string `paramName:"secret-dirs"`
+	OnDuplicateSecret          string   `paramName:"on-duplicate-secret"`
+	AllowUnusedSecrets         bool     `paramName:"allow-unused-secrets"`
 	WorkdirMount               string   `paramName:"workdir-mount"`
+	Volumes                    []string `paramName:"volumes"`
 	BuildArgs                  []string `paramName:"build-args"`
 	BuildArgsFile              string   `paramName:"build-args-file"`
 	Envs                       []string `paramName:"envs"`
@@ -490,11 +848,14 @@ type BuildParams struct {
 	SkipInjections             bool     `paramName:"skip-injections"`
 	InheritLabels              bool     `paramName:"inherit-labels"`
 	IncludeLegacyBuildinfoPath bool     `paramName:"include-legacy-buildinfo-path"`
+	InjectContentSets          string   `paramName:"inject-content-sets"`
 	Target                     string   `paramName:"target"`
 	SkipUnusedStages           bool     `paramName:"skip-unused-stages"`
 	Hermetic                   bool     `paramName:"hermetic"`
 	ImagePullProxy             string   `paramName:"image-pull-proxy"`
 	ImagePullNoProxy           string   `paramName:"image-pull-noproxy"`
+	Proxy                      string   `paramName:"proxy"`
+	NoProxy                    string   `paramName:"no-proxy"`
 	YumReposDSources           []string `paramName:"yum-repos-d-sources"`
 	YumReposDTarget            string   `paramName:"yum-repos-d-target"`
 	PrefetchDir                string   `paramName:"prefetch-dir"`
@@ -506,24 +867,55 @@ type BuildParams struct {
 	RHSMEntitlements           string   `paramName:"rhsm-entitlements"`
 	RHSMActivationKey          string   `paramName:"rhsm-activation-key"`
 	RHSMOrg                    string   `paramName:"rhsm-org"`
+	RHSMActivationKeyDir       string   `paramName:"rhsm-activation-key-dir"`
 	RHSMActivationMount        string   `paramName:"rhsm-activation-mount"`
 	RHSMActivationPreregister  bool     `paramName:"rhsm-activation-preregister"`
 	RHSMMountCACerts           string   `paramName:"rhsm-mount-ca-certs"`
 	SrcTLSVerify               bool     `paramName:"src-tls-verify"`
 	DestTLSVerify              bool     `paramName:"dest-tls-verify"`
+	CertDir                    string   `paramName:"cert-dir"`
+	CABundleFile               string   `paramName:"ca-bundle-file"`
+	Format                     string   `paramName:"format"`
+	SanitizeContext            bool     `paramName:"sanitize-context"`
+	SanitizeContextExcludes    []string `paramName:"sanitize-context-excludes"`
+	IgnoreFromGitignore        bool     `paramName:"containerignore-from-gitignore"`
+	MaxContextSize             string   `paramName:"max-context-size"`
+	ContextReportTopFiles      int      `paramName:"context-report-top-files"`
 	Squash                     bool     `paramName:"squash"`
 	OmitHistory                bool     `paramName:"omit-history"`
 	NoCache                    bool     `paramName:"no-cache"`
+	Layers                     bool     `paramName:"layers"`
+	CacheFrom                  []string `paramName:"cache-from"`
+	CacheTo                    []string `paramName:"cache-to"`
+	StorageDir                 string   `paramName:"storage-dir"`
+	CachePruneOlderThan        string   `paramName:"cache-prune-older-than"`
 	SecurityOpts               []string `paramName:"security-opts"`
+	Isolation                  string   `paramName:"isolation"`
+	Userns                     string   `paramName:"userns"`
 	CapAdd                     []string `paramName:"cap-add"`
 	CapDrop                    []string `paramName:"cap-drop"`
 	Devices                    []string `paramName:"devices"`
 	Ulimits                    []string `paramName:"ulimits"`
 	AllowCrossPlatformImages   bool     `paramName:"allow-cross-platform-images"`
+	Platforms                  []string `paramName:"platform"`
 	SyftSourceOutput           string   `paramName:"syft-source-output"`
 	SyftImageOutput            string   `paramName:"syft-image-output"`
 	SyftSelectCatalogers       string   `paramName:"syft-select-catalogers"`
 	SBOMFormat                 string   `paramName:"sbom-format"`
+	SBOMOutput                 string   `paramName:"sbom-output"`
+	Bootc                      bool     `paramName:"bootc"`
+	CheckpointFile             string   `paramName:"checkpoint-file"`
+	Resume                     bool     `paramName:"resume"`
+	RemoteHost                 string   `paramName:"remote-host"`
+	RemoteSshKey               string   `paramName:"remote-ssh-key"`
+	RemoteKnownHosts           string   `paramName:"remote-known-hosts"`
+	RemotePush                 bool     `paramName:"remote-push"`
+	Engine                     string   `paramName:"engine"`
+	NativePush                 bool     `paramName:"native-push"`
+	ChrootWorkdir              string   `paramName:"chroot-workdir"`
+	PreBuildScript             string   `paramName:"pre-build-script"`
+	PostBuildScript            string   `paramName:"post-build-script"`
+	HookTimeout                string   `paramName:"hook-timeout"`
 	ExtraArgs                  []string // Additional arguments to pass to buildah build
 }
 
@@ -534,11 +926,57 @@ type BuildCliWrappers struct {
 	SelfInUserNamespace cliWrappers.WrapperCmd
 	SubscriptionManager cliWrappers.SubscriptionManagerCliInterface
 	SyftCli             cliWrappers.SyftCliInterface
+	SkopeoCli           cliWrappers.SkopeoCliInterface
+	// Set with --remote-host, RemoteBuildahCli runs buildah on the remote host
+	SshCli           cliWrappers.SshCliInterface
+	RemoteBuildahCli cliWrappers.BuildahCliInterface
+	// Builds, pulls, inspects and pushes the image, BuildahCli if not set, see --engine.
+	// BuildahCli is not set with the other engines.
+	BuildEngine cliWrappers.BuildEngine
+	// Set with --native-push
+	ImagePusher clients.ImagePusherInterface
+	// Runs --pre-build-script and --post-build-script
+	HookExecutor cliWrappers.CliExecutorInterface
 }
 
 type BuildResults struct {
 	ImageUrl string `json:"image_url"`
-	Digest   string `json:"digest,omitempty"`
+	// Digest of the image, or of the image index of a multi-platform build
+	Digest string `json:"digest,omitempty"`
+	// Digests of the per-platform manifests of the pushed image index of a multi-platform build
+	PlatformDigests []BuildPlatformDigest `json:"platform_digests,omitempty"`
+	// All tags applied to the built image, i.e. the output image and its additional tags
+	Tags []string `json:"tags,omitempty"`
+	// The local OCI layout the image was written to with --output
+	Output string `json:"output,omitempty"`
+	// The FROM images of the Containerfile stages
+	BaseImages []BuildBaseImage `json:"base_images,omitempty"`
+	// Findings of --lint
+	LintFindings []LintFinding `json:"lint_findings,omitempty"`
+	// Logically bound images of a bootc image
+	BoundImages []string `json:"bound_images,omitempty"`
+	// The SBOM written to --sbom-output and its digest
+	SBOMPath   string `json:"sbom_path,omitempty"`
+	SBOMDigest string `json:"sbom_digest,omitempty"`
+	// The effective --proxy and --no-proxy, the proxy password is masked
+	Proxy *common.ProxySettings `json:"proxy,omitempty"`
+	// Size of the context sent to buildah
+	ContextSize *BuildContextSize `json:"context_size,omitempty"`
+}
+
+type BuildPlatformDigest struct {
+	// os/arch[/variant]
+	Platform string `json:"platform"`
+	Digest   string `json:"digest"`
+}
+
+type BuildBaseImage struct {
+	// Name of the stage, its index if the stage is unnamed
+	Stage string `json:"stage"`
+	// The image reference as in the FROM instruction
+	Name string `json:"name"`
+	// Digest the reference resolved to, empty if it couldn't be resolved (e.g. short names)
+	Digest string `json:"digest,omitempty"`
 }
 
 type Build struct {
@@ -552,6 +990,19 @@ type Build struct {
 
 	containerfilePath string
 
+	// normalized --platform values
+	buildPlatforms []string
+	// the platform the Containerfile is currently processed for, the host platform if empty
+	targetPlatform string
+
+	// parsed --build-timeout and --push-timeout, zero means no timeout
+	buildTimeout time.Duration
+	pushTimeout  time.Duration
+	// parsed --hook-timeout, zero means no timeout
+	hookTimeout time.Duration
+	// resolved --chroot-workdir, empty if not set
+	sandboxRoot common.ResolvedPath
+
 	// pre-computed buildah arguments
 	buildahSecrets        []cliWrappers.BuildahSecret
 	buildahMounts         []cliWrappers.BuildahMount
@@ -559,19 +1010,41 @@ type Build struct {
 	mergedLabels          []string
 	mergedAnnotations     []string
 	buildinfoBuildContext *cliWrappers.BuildahBuildContext
+	// --cert-dir for buildah, combining the --cert-dir and --ca-bundle-file params
+	certDir string
+	// the effective --proxy and --no-proxy
+	proxySettings common.ProxySettings
+	// --max-context-size in bytes, 0 if not set
+	maxContextSize int64
+	// parsed --cache-prune-older-than, 0 if not set
+	cachePruneOlderThan time.Duration
 
 	// temporary workdir and related paths
 	tempWorkdir           string
 	containerfileCopyPath string
+	sanitizedContextDir   string
+	// --containerignore-from-gitignore
+	generatedIgnoreFile string
 
 	// temporary files/directories that could not be placed inside the tempWorkdir
 	tempFilesOutsideWorkdir []string
 
+	// SBOMs written by the syft scans, inputs of --sbom-output
+	syftSourceSbom string
+	syftImageSbom  string
+
 	registeredWithRHSM bool
+	// temporary directory on --remote-host, empty if not created
+	remoteWorkdir string
+	// nil unless --checkpoint-file is set
+	checkpoint *buildCheckpoint
 	// these are constants, but they need to be mockable for tests
 	hostEntitlements  string
 	hostConsumerCerts string
 	hostRHSMcaCerts   string
+	// for fetching a remote --context, mockable for tests
+	httpClient      *http.Client
+	cloneGitContext func(params *gitclone.Params) error
 }
 
 func NewBuild(cmd *cobra.Command, extraArgs []string) (*Build, error) {
@@ -579,6 +1052,7 @@ func NewBuild(cmd *cobra.Command, extraArgs []string) (*Build, error) {
 		hostEntitlements:  "/etc/pki/entitlement",
 		hostConsumerCerts: "/etc/pki/consumer",
 		hostRHSMcaCerts:   "/etc/rhsm/ca",
+		httpClient:        &http.Client{Timeout: 30 * time.Minute},
 	}
 
 	params := &BuildParams{}
@@ -620,16 +1094,35 @@ func (c *Build) cleanup() {
 	if c.registeredWithRHSM {
 		c.CliWrappers.SubscriptionManager.Unregister()
 	}
+	if c.remoteWorkdir != "" {
+		c.cleanupRemoteWorkdir()
+	}
 }
 
 func (c *Build) initCliWrappers() error {
 	executor := cliWrappers.NewCliExecutor()
 
-	buildahCli, err := cliWrappers.NewBuildahCli(executor)
+	if c.usesBuildah() {
+		buildahCli, err := cliWrappers.NewBuildahCli(executor)
+		if err != nil {
+			return err
+		}
+		buildahCli.Root = c.Params.StorageDir
+		c.CliWrappers.BuildahCli = buildahCli
+		c.CliWrappers.BuildEngine = buildahCli
+	} else {
+		buildEngine, err := newBuildEngine(c.Params.Engine, executor)
+		if err != nil {
+			return err
+		}
+		c.CliWrappers.BuildEngine = buildEngine
+	}
+
+	skopeoCli, err := cliWrappers.NewSkopeoCli(executor)
 	if err != nil {
 		return err
 	}
-	c.CliWrappers.BuildahCli = buildahCli
+	c.CliWrappers.SkopeoCli = skopeoCli
 
 	c.CliWrappers.BuildahUnshare = cliWrappers.NewWrapperCmd("buildah", "unshare")
 
@@ -649,14 +1142,57 @@ func (c *Build) initCliWrappers() error {
 		c.CliWrappers.SubscriptionManager = subman
 	}
 
-	if c.Params.SyftSourceOutput != "" || c.Params.SyftImageOutput != "" {
+	if c.Params.SyftSourceOutput != "" || c.Params.SyftImageOutput != "" || c.Params.SBOMOutput != "" {
 		syftCli, err := cliWrappers.NewSyftCli(executor)
 		if err != nil {
-			return fmt.Errorf("syft is required for --syft-source-output or --syft-image-output: %w", err)
+			return fmt.Errorf("syft is required for --syft-source-output, --syft-image-output or --sbom-output: %w", err)
 		}
 		c.CliWrappers.SyftCli = syftCli
 	}
 
+	if c.Params.NativePush {
+		c.CliWrappers.ImagePusher = clients.NewImagePusher()
+	}
+
+	if c.Params.PreBuildScript != "" || c.Params.PostBuildScript != "" {
+		c.CliWrappers.HookExecutor = executor
+	}
+
+	if c.Params.RemoteHost != "" {
+		sshCli, err := cliWrappers.NewSshCli(executor, c.Params.RemoteHost, c.Params.RemoteSshKey, c.Params.RemoteKnownHosts)
+		if err != nil {
+			return fmt.Errorf("ssh and rsync are required for --remote-host: %w", err)
+		}
+		c.CliWrappers.SshCli = sshCli
+		c.CliWrappers.RemoteBuildahCli = &cliWrappers.BuildahCli{Executor: cliWrappers.NewSshExecutor(sshCli)}
+	}
+
+	return nil
+}
+
+// Generate the Containerfile to build from the --containerfile-json input.
+func (c *Build) renderContainerfileJson() error {
+	l.Logger.Infof("Generating Containerfile from: %s", c.Params.ContainerfileJson)
+
+	data, err := os.ReadFile(c.Params.ContainerfileJson)
+	if err != nil {
+		return fmt.Errorf("reading containerfile JSON: %w", err)
+	}
+	content, err := renderContainerfileFromJson(data)
+	if err != nil {
+		return err
+	}
+
+	if err := c.ensureTempWorkdirExists(); err != nil {
+		return err
+	}
+	containerfile := filepath.Join(c.tempWorkdir, "Containerfile.generated")
+	if err := os.WriteFile(containerfile, []byte(content), 0644); err != nil {
+		return fmt.Errorf("writing generated containerfile: %w", err)
+	}
+	l.Logger.Debugf("Generated Containerfile:\n%s", content)
+
+	c.containerfilePath = containerfile
 	return nil
 }
 
@@ -672,6 +1208,39 @@ func (c *Build) ensureTempWorkdirExists() error {
 	return nil
 }
 
+// Set up the --cert-dir for buildah. The --ca-bundle-file is added to a copy of the --cert-dir
+// in the temporary workdir, buildah doesn't have an option for a CA bundle file.
+func (c *Build) prepareCertDir() error {
+	if c.Params.CABundleFile == "" {
+		c.certDir = c.Params.CertDir
+		return nil
+	}
+	if err := c.ensureTempWorkdirExists(); err != nil {
+		return err
+	}
+	certDir := filepath.Join(c.tempWorkdir, "certs")
+	if err := common.WriteCertDir(certDir, c.Params.CertDir, c.Params.CABundleFile); err != nil {
+		return fmt.Errorf("preparing cert dir: %w", err)
+	}
+	c.certDir = certDir
+	return nil
+}
+
+// Set the proxy environment variables, buildah passes them to the RUN instructions
+// and uses them for accessing the registries, as do the other tools the build runs.
+func (c *Build) configureProxy() error {
+	c.proxySettings = common.ResolveProxySettings(c.Params.Proxy, c.Params.NoProxy)
+	if !c.proxySettings.IsSet() {
+		return nil
+	}
+	if err := c.proxySettings.Apply(); err != nil {
+		return fmt.Errorf("configuring proxy: %w", err)
+	}
+	redacted := c.proxySettings.Redacted()
+	c.Results.Proxy = &redacted
+	return nil
+}
+
 func (c *Build) ensureContainerfileCopied() error {
 	if c.containerfileCopyPath != "" {
 		return nil
@@ -721,14 +1290,19 @@ func (c *Build) copyToTempWorkdir(filePath string) (copyPath string, err error)
 // Run re-execs the command inside a user namespace if not already in one,
 // then delegates to run() for the actual logic.
 func (c *Build) Run() error {
-	if os.Getenv(envVarInUserNamespace) == "" {
+	// podman sets up its own user namespace, docker builds in the daemon
+	if os.Getenv(envVarInUserNamespace) == "" && c.usesBuildah() {
 		err := c.reExecInUserNamespace()
 		if err != nil {
 			return fmt.Errorf("re-execing self in a user namespace: %w", err)
 		}
 		// unreachable; if reExecInUserNamespace succeeds it replaces the current process
 	}
-	return c.run()
+	err := c.run()
+	if err != nil && cliWrappers.Terminating() && c.Params.CheckpointFile != "" {
+		l.Logger.Infof("Build interrupted, re-run with --resume to continue from the checkpoint in %s", c.Params.CheckpointFile)
+	}
+	return err
 }
 
 func (c *Build) run() error {
@@ -739,31 +1313,113 @@ func (c *Build) run() error {
 
 	defer c.cleanup()
 
-	if err := c.validateParams(); err != nil {
+	if err := c.fetchRemoteContext(); err != nil {
+		return err
+	}
+
+	endStep := l.StartStep("validation")
+	err := c.validateParams()
+	endStep()
+	if err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	if err := c.prepareCertDir(); err != nil {
 		return err
 	}
 
-	if err := c.detectBuildahVersion(); err != nil {
+	if err := c.configureProxy(); err != nil {
 		return err
 	}
 
+	if len(c.buildPlatforms) > 0 {
+		c.targetPlatform = c.buildPlatforms[0]
+	}
+
+	if c.Params.Bootc {
+		c.setBootcDefaults()
+	}
+
+	if c.usesBuildah() {
+		if err := c.detectBuildahVersion(); err != nil {
+			return err
+		}
+	}
+
+	if c.cachePruneOlderThan > 0 {
+		endStep := l.StartStep("cache-prune")
+		err := c.pruneCache()
+		endStep()
+		if err != nil {
+			return err
+		}
+	}
+
 	if err := c.detectContainerfile(); err != nil {
 		return err
 	}
 
+	if err := c.initCheckpoint(); err != nil {
+		return err
+	}
+
+	endStep = l.StartStep("containerfile-parse")
 	containerfile, err := c.parseContainerfile()
+	endStep()
 	if err != nil {
 		return err
 	}
 
+	if err := checkContainerfilePlatforms(containerfile, c.buildBackend()); err != nil {
+		return err
+	}
+
+	if c.Params.Bootc {
+		checkBootcContainerfile(containerfile)
+	}
+
 	if err := c.processLabelsAndAnnotations(); err != nil {
 		return err
 	}
 
+	if c.Params.Bootc {
+		c.addBootcLabels()
+	}
+
+	if c.Params.RequirePinnedBaseImages {
+		if err := c.checkPinnedBaseImages(containerfile); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.Lint {
+		if err := c.lint(containerfile); err != nil {
+			return err
+		}
+	}
+
+	endStep = l.StartStep("base-images")
+	stageDigests, err := c.recordBaseImages(containerfile)
+	if err == nil && c.Params.ResolveBaseImages {
+		err = c.pinBaseImages(containerfile, stageDigests)
+	}
+	endStep()
+	if err != nil {
+		return err
+	}
+
 	if err := c.setSecretArgs(); err != nil {
 		return err
 	}
 
+	if err := c.setVolumeArgs(); err != nil {
+		return err
+	}
+
+	if err := c.verifySecretUsage(containerfile); err != nil {
+		return err
+	}
+
 	prefetchResources, err := c.integrateWithPrefetch()
 	if err != nil {
 		return fmt.Errorf("setting up prefetch integration: %w", err)
@@ -777,13 +1433,20 @@ func (c *Build) run() error {
 		return fmt.Errorf("setting up RHSM integration: %w", err)
 	}
 
-	pulledImages, err := c.prePullBaseImages(containerfile)
-	if err != nil {
-		return err
+	var pulledImages []BaseImage
+	if c.Params.RemoteHost == "" {
+		pulledImages, err = c.prePullAndVerifyBaseImages(containerfile)
+		if err != nil {
+			return err
+		}
+	} else {
+		l.Logger.Infof("The base images are pulled on %s", c.Params.RemoteHost)
 	}
 
-	if err := c.verifyBaseImageArchitectures(pulledImages); err != nil {
-		return err
+	if c.Params.Bootc {
+		if err := c.verifyBootcBaseImage(containerfile); err != nil {
+			return err
+		}
 	}
 
 	if !c.Params.SkipInjections {
@@ -801,22 +1464,99 @@ func (c *Build) run() error {
 		return fmt.Errorf("disabling RHSM host integration: %w", err)
 	}
 
-	if err := c.buildImage(); err != nil {
+	if c.Params.SanitizeContext || len(c.Params.SanitizeContextExcludes) > 0 {
+		if err := c.sanitizeContext(); err != nil {
+			return fmt.Errorf("sanitizing context directory: %w", err)
+		}
+	}
+
+	if c.Params.IgnoreFromGitignore {
+		if err := c.generateContainerignore(); err != nil {
+			return fmt.Errorf("generating containerignore from .gitignore: %w", err)
+		}
+	}
+
+	if err := c.checkContextSize(); err != nil {
 		return err
 	}
 
+	if c.resumeBuilt() {
+		l.Logger.Infof("Skipping build, image %s was built by the interrupted run", c.checkpoint.ImageId)
+	} else {
+		if c.Params.PreBuildScript != "" {
+			if err := c.runHook("pre-build-script", c.Params.PreBuildScript); err != nil {
+				return err
+			}
+		}
+
+		endStep := l.StartStep("build")
+		var err error
+		if c.Params.RemoteHost != "" {
+			err = c.buildImageRemotely()
+		} else {
+			err = c.buildImage()
+		}
+		endStep()
+		if err != nil {
+			return err
+		}
+		if err := c.checkpointBuilt(); err != nil {
+			return err
+		}
+	}
+
 	c.Results.ImageUrl = c.Params.OutputRef
+	c.Results.Tags = c.allTags()
+
+	if c.Params.Bootc {
+		boundImages, err := c.findBootcBoundImages()
+		if err != nil {
+			return fmt.Errorf("finding bootc logically bound images: %w", err)
+		}
+		c.Results.BoundImages = boundImages
+	}
 
 	if err := c.runSyftScans(); err != nil {
 		return err
 	}
 
-	if c.Params.Push {
-		digest, err := c.pushImage()
+	if c.Params.SBOMOutput != "" {
+		if err := c.writeSBOM(prefetchResources); err != nil {
+			return err
+		}
+	}
+
+	// With --native-push, the image is pushed from the --output layout, which is written first
+	if c.Params.Push && !c.Params.NativePush {
+		if err := c.pushStep(); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.Output != "" {
+		endStep := l.StartStep("output")
+		digest, err := c.writeOutput()
+		endStep()
 		if err != nil {
 			return err
 		}
-		c.Results.Digest = digest
+		c.Results.Output = c.Params.Output
+		// Pushing to the registry may change the manifest, report the digest of the pushed one then
+		if c.Results.Digest == "" {
+			c.Results.Digest = digest
+		}
+	}
+
+	if c.Params.Push && c.Params.NativePush {
+		if err := c.pushStep(); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.PostBuildScript != "" {
+		if err := c.runHook("post-build-script", c.Params.PostBuildScript); err != nil {
+			return err
+		}
 	}
 
 	if c.Params.BuilderMetadataOutput != "" {
@@ -836,12 +1576,14 @@ func (c *Build) run() error {
 		}
 	}
 
-	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
-		fmt.Print(resultJson)
-	} else {
+	endStep = l.StartStep("results")
+	resultJson, err := c.ResultsWriter.CreateResultJson(c.Results)
+	endStep()
+	if err != nil {
 		l.Logger.Errorf("failed to create results json: %s", err.Error())
 		return err
 	}
+	fmt.Print(resultJson)
 
 	return nil
 }
@@ -857,6 +1599,13 @@ func (c *Build) validateParams() error {
 		}
 	}
 
+	for _, label := range c.Params.Labels {
+		name, _, _ := strings.Cut(label, "=")
+		if !isImageLabelNameValid(name) {
+			return fmt.Errorf("label name '%s' is invalid", name)
+		}
+	}
+
 	if stat, err := os.Stat(c.effectiveContextDir()); err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("context directory '%s' does not exist", c.effectiveContextDir())
@@ -871,15 +1620,15 @@ func (c *Build) validateParams() error {
 		if err != nil {
 			return fmt.Errorf("resolving source directory: %w", err)
 		}
-		resolvedContext, err := common.ResolvePath(c.effectiveContextDir())
-		if err != nil {
-			return fmt.Errorf("resolving context directory: %w", err)
-		}
-		if !resolvedContext.IsRelativeTo(resolvedSource) {
-			return fmt.Errorf("context directory '%s' is outside source directory '%s'", c.Params.Context, c.Params.Source)
+		if err := common.CheckPathInside(resolvedSource, "context directory", c.effectiveContextDir()); err != nil {
+			return err
 		}
 	}
 
+	if c.Params.Containerfile != "" && c.Params.ContainerfileJson != "" {
+		return fmt.Errorf("containerfile and containerfile-json are mutually exclusive")
+	}
+
 	if c.Params.LegacyBuildTimestamp != "" && c.Params.SourceDateEpoch != "" {
 		return fmt.Errorf("legacy-build-timestamp and source-date-epoch are mutually exclusive")
 	}
@@ -894,6 +1643,18 @@ func (c *Build) validateParams() error {
 		}
 	}
 
+	if c.Params.RHSMActivationKeyDir != "" {
+		if c.Params.RHSMActivationKey != "" || c.Params.RHSMOrg != "" {
+			return fmt.Errorf("rhsm-activation-key-dir is mutually exclusive with rhsm-activation-key and rhsm-org")
+		}
+		// Same layout as the activation key secrets in Konflux
+		c.Params.RHSMActivationKey = filepath.Join(c.Params.RHSMActivationKeyDir, "activationkey")
+		c.Params.RHSMOrg = filepath.Join(c.Params.RHSMActivationKeyDir, "org")
+		if c.Params.RHSMActivationMount == "" && !c.Params.RHSMActivationPreregister {
+			c.Params.RHSMActivationMount = defaultRHSMActivationMount
+		}
+	}
+
 	if c.Params.RHSMEntitlements != "" && c.Params.RHSMActivationKey != "" {
 		return fmt.Errorf("rhsm-entitlements and rhsm-activation-key are mutually exclusive")
 	}
@@ -924,17 +1685,161 @@ func (c *Build) validateParams() error {
 			return fmt.Errorf("rhsm-mount-ca-certs must be one of 'always', 'auto', 'never', got '%s'", c.Params.RHSMMountCACerts)
 		}
 	}
-
-	if c.Params.RewriteTimestamp && c.Params.SourceDateEpoch == "" {
-		// Not an error, just a warning (buildah also doesn't error for this combination of flags)
-		l.Logger.Warn("RewriteTimestamp is enabled but SourceDateEpoch was not provided. Timestamps will not be re-written.")
+
+	if c.Params.InjectContentSets != "" {
+		validInjectContentSets := map[string]bool{"auto": true, "content-manifests": true, "never": true}
+		if !validInjectContentSets[c.Params.InjectContentSets] {
+			return fmt.Errorf("inject-content-sets must be one of 'auto', 'content-manifests', 'never', got '%s'", c.Params.InjectContentSets)
+		}
+	}
+
+	if c.Params.MaxContextSize != "" {
+		maxContextSize, err := units.RAMInBytes(c.Params.MaxContextSize)
+		if err != nil || maxContextSize <= 0 {
+			return fmt.Errorf("max-context-size '%s' is invalid, expected a size such as 500MiB or 2GiB", c.Params.MaxContextSize)
+		}
+		c.maxContextSize = maxContextSize
+	}
+	if c.Params.ContextReportTopFiles < 0 {
+		return fmt.Errorf("context-report-top-files must not be negative")
+	}
+
+	if c.Params.Resume && c.Params.CheckpointFile == "" {
+		return fmt.Errorf("resume requires checkpoint-file")
+	}
+
+	buildPlatforms, err := parseBuildPlatforms(c.Params.Platforms, c.buildBackend())
+	if err != nil {
+		return err
+	}
+	c.buildPlatforms = buildPlatforms
+	if c.isMultiPlatform() {
+		if err := validateMultiPlatformParams(c.Params); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.RewriteTimestamp && c.Params.SourceDateEpoch == "" {
+		// Not an error, just a warning (buildah also doesn't error for this combination of flags)
+		l.Logger.Warn("RewriteTimestamp is enabled but SourceDateEpoch was not provided. Timestamps will not be re-written.")
+	}
+
+	switch c.Params.OnDuplicateSecret {
+	case "", onDuplicateSecretError, onDuplicateSecretSuffix, onDuplicateSecretSkip:
+	default:
+		return fmt.Errorf("on-duplicate-secret must be 'error', 'suffix' or 'skip', got '%s'", c.Params.OnDuplicateSecret)
+	}
+
+	if c.Params.Output != "" {
+		if err := validateBuildOutput(c.Params.Output); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.Lint {
+		if err := validateLintParams(nil, c.Params.LintFailOn); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.Format != "" && c.Params.Format != "oci" && c.Params.Format != "docker" {
+		return fmt.Errorf("format must be 'oci' or 'docker', got '%s'", c.Params.Format)
+	}
+
+	for _, cacheRepo := range slices.Concat(c.Params.CacheFrom, c.Params.CacheTo) {
+		if err := validateCacheRepository(cacheRepo); err != nil {
+			return err
+		}
+	}
+
+	if c.buildTimeout, err = parseTimeout(c.Params.BuildTimeout); err != nil {
+		return fmt.Errorf("build-timeout '%s' is invalid: %w", c.Params.BuildTimeout, err)
+	}
+	if c.pushTimeout, err = parseTimeout(c.Params.PushTimeout); err != nil {
+		return fmt.Errorf("push-timeout '%s' is invalid: %w", c.Params.PushTimeout, err)
+	}
+
+	if c.Params.QuayImageExpiresAfter != "" {
+		if _, err := parseQuayExpiresAfter(c.Params.QuayImageExpiresAfter); err != nil {
+			return fmt.Errorf("quay-image-expires-after '%s' is invalid: %w", c.Params.QuayImageExpiresAfter, err)
+		}
+	}
+
+	validSBOMFormats := map[string]bool{"cyclonedx": true, "spdx": true}
+	if !validSBOMFormats[c.Params.SBOMFormat] {
+		return fmt.Errorf("sbom-format must be 'cyclonedx' or 'spdx', got '%s'", c.Params.SBOMFormat)
+	}
+
+	if err := validateIsolationParams(c.Params); err != nil {
+		return err
+	}
+
+	if err := c.validateStorageParams(); err != nil {
+		return err
+	}
+
+	if err := c.validateRemoteParams(); err != nil {
+		return err
+	}
+
+	if err := c.validateEngineParams(); err != nil {
+		return err
+	}
+
+	if err := c.validateNativePushParams(); err != nil {
+		return err
+	}
+
+	if err := c.validateHooksParams(); err != nil {
+		return err
+	}
+
+	if err := c.validateSandboxParams(); err != nil {
+		return err
+	}
+
+	if c.Params.Bootc {
+		if err := validateBootcParams(c.Params); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Parse a timeout in the time.ParseDuration format (e.g. 90m, 1h30m), zero if not set.
+func parseTimeout(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, err
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("must be positive")
 	}
+	return duration, nil
+}
 
-	validSBOMFormats := map[string]bool{"cyclonedx": true, "spdx": true}
-	if !validSBOMFormats[c.Params.SBOMFormat] {
-		return fmt.Errorf("sbom-format must be 'cyclonedx' or 'spdx', got '%s'", c.Params.SBOMFormat)
+// The output is a buildah push destination in the transport:path format, only the local OCI layouts are allowed.
+func validateBuildOutput(output string) error {
+	transport, path, found := strings.Cut(output, ":")
+	if !found || (transport != "oci-archive" && transport != "oci") {
+		return fmt.Errorf("output must be 'oci-archive:<path>' or 'oci:<dir>', got '%s'", output)
 	}
+	if path == "" {
+		return fmt.Errorf("output path is empty in '%s'", output)
+	}
+	return nil
+}
 
+// Buildah looks up and pushes the cached layers by their content, under tags it generates
+// itself, so the cache images are referenced by repository only.
+func validateCacheRepository(cacheRepo string) error {
+	if !common.IsImageNameValid(cacheRepo) {
+		return fmt.Errorf("cache repository '%s' is invalid, expected a repository without tag or digest", cacheRepo)
+	}
 	return nil
 }
 
@@ -954,6 +1859,10 @@ func (c *Build) detectBuildahVersion() error {
 }
 
 func (c *Build) detectContainerfile() error {
+	if c.Params.ContainerfileJson != "" {
+		return c.renderContainerfileJson()
+	}
+
 	source := c.Params.Source
 	if source == "" {
 		source = "."
@@ -975,15 +1884,15 @@ func (c *Build) detectContainerfile() error {
 		if err != nil {
 			return fmt.Errorf("resolving source directory: %w", err)
 		}
-		resolvedContainerfile, err := common.ResolvePath(containerfile)
-		if err != nil {
-			return fmt.Errorf("resolving containerfile path: %w", err)
-		}
-		if !resolvedContainerfile.IsRelativeTo(resolvedSource) {
-			return fmt.Errorf("containerfile '%s' is outside source directory '%s'", containerfile, c.Params.Source)
+		if err := common.CheckPathInside(resolvedSource, "containerfile", containerfile); err != nil {
+			return err
 		}
 	}
 
+	if err := c.checkSandboxed("containerfile", containerfile); err != nil {
+		return err
+	}
+
 	c.containerfilePath = containerfile
 	return nil
 }
@@ -1001,6 +1910,78 @@ func (c *Build) setSecretArgs() error {
 	return nil
 }
 
+type buildVolume struct {
+	volume   cliWrappers.BuildahVolume
+	optional bool
+}
+
+func parseVolumes(volumeArgs []string) ([]buildVolume, error) {
+	var volumes []buildVolume
+
+	for _, arg := range volumeArgs {
+		parts := strings.SplitN(arg, ":", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid volume: %s (expected host-dir:container-dir[:options])", arg)
+		}
+		if !filepath.IsAbs(parts[1]) {
+			return nil, fmt.Errorf("invalid volume: %s (container directory must be absolute)", arg)
+		}
+
+		volume := buildVolume{
+			volume: cliWrappers.BuildahVolume{HostDir: parts[0], ContainerDir: parts[1]},
+		}
+		if len(parts) == 3 {
+			var options []string
+			for _, option := range strings.Split(parts[2], ",") {
+				key, value, _ := strings.Cut(option, "=")
+				if key != "optional" {
+					options = append(options, option)
+					continue
+				}
+				switch value {
+				case "true":
+					volume.optional = true
+				case "false":
+					volume.optional = false
+				default:
+					return nil, fmt.Errorf("invalid argument: optional=%s (expected true|false)", value)
+				}
+			}
+			volume.volume.Options = strings.Join(options, ",")
+		}
+
+		volumes = append(volumes, volume)
+	}
+
+	return volumes, nil
+}
+
+// setVolumeArgs adds the --volumes to the volumes mounted into the build.
+// Relative host directories are made absolute by BuildahBuildArgs.MakePathsAbsolute.
+func (c *Build) setVolumeArgs() error {
+	volumes, err := parseVolumes(c.Params.Volumes)
+	if err != nil {
+		return fmt.Errorf("parsing --volumes: %w", err)
+	}
+	for _, volume := range volumes {
+		if _, err := os.Stat(volume.volume.HostDir); err != nil {
+			if volume.optional && errors.Is(err, fs.ErrNotExist) {
+				l.Logger.Debugf("volume directory %s doesn't exist but is marked optional, skipping", volume.volume.HostDir)
+				continue
+			}
+			return fmt.Errorf("processing --volumes: %w", err)
+		}
+		c.buildahVolumes = append(c.buildahVolumes, volume.volume)
+	}
+	return nil
+}
+
+const (
+	onDuplicateSecretError  = "error"
+	onDuplicateSecretSuffix = "suffix"
+	onDuplicateSecretSkip   = "skip"
+)
+
 type secretDir struct {
 	src      string
 	name     string
@@ -1050,6 +2031,136 @@ func parseSecretDirs(secretDirArgs []string) ([]secretDir, error) {
 }
 
 // processSecretDirs processes secret directories and returns buildah --secret arguments.
+// Compare the secrets used by the Containerfile with the ones provided via --secret-dirs
+// (and --secret in extra args), so that mismatches fail the build before it starts
+// instead of as obscure RUN failures.
+//
+// Missing required secrets are an error, missing optional secrets only a warning (buildah
+// skips the mount). Unused secrets are an error unless --allow-unused-secrets is set.
+func (c *Build) verifySecretUsage(df *dockerfile.Dockerfile) error {
+	provided := make(map[string]bool)
+	for _, secret := range c.buildahSecrets {
+		provided[secret.Id] = true
+	}
+	for _, id := range getSecretIdsFromArgs(c.Params.ExtraArgs) {
+		provided[id] = true
+	}
+
+	// id -> whether any of the mounts requires it
+	used := getContainerfileSecretIds(df)
+
+	var missingRequired, missingOptional, unused []string
+	for id, required := range used {
+		if provided[id] {
+			continue
+		}
+		if required {
+			missingRequired = append(missingRequired, id)
+		} else {
+			missingOptional = append(missingOptional, id)
+		}
+	}
+	for _, secret := range c.buildahSecrets {
+		if _, ok := used[secret.Id]; !ok {
+			unused = append(unused, secret.Id)
+		}
+	}
+	slices.Sort(missingRequired)
+	slices.Sort(missingOptional)
+	slices.Sort(unused)
+
+	if len(missingOptional) > 0 {
+		l.Logger.Warnf("Optional secrets used by the Containerfile are not provided: %s", strings.Join(missingOptional, ", "))
+	}
+	if len(unused) > 0 && c.Params.AllowUnusedSecrets {
+		l.Logger.Warnf("Secrets not used by the Containerfile: %s", strings.Join(unused, ", "))
+		unused = nil
+	}
+
+	var problems []string
+	if len(missingRequired) > 0 {
+		problems = append(problems, fmt.Sprintf("required secrets not provided by --secret-dirs: %s", strings.Join(missingRequired, ", ")))
+	}
+	if len(unused) > 0 {
+		problems = append(problems, fmt.Sprintf("secrets not used by the Containerfile: %s (use --allow-unused-secrets to ignore)", strings.Join(unused, ", ")))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("secrets mismatch: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Return the ids of the secrets mounted by RUN instructions, mapped to whether any
+// of the mounts requires the secret. The Containerfile must be expanded, the --mount
+// flags only get parsed during expansion.
+func getContainerfileSecretIds(df *dockerfile.Dockerfile) map[string]bool {
+	ids := make(map[string]bool)
+	for _, stage := range df.Stages {
+		for _, cmd := range stage.Commands {
+			runCmd, ok := cmd.Command.(*instructions.RunCommand)
+			if !ok {
+				continue
+			}
+			for _, mount := range instructions.GetMounts(runCmd) {
+				if mount.Type != instructions.MountTypeSecret {
+					continue
+				}
+				// Same defaults as in BuildKit: id, source, basename of target
+				id := mount.CacheID
+				if id == "" {
+					id = mount.Source
+				}
+				if id == "" {
+					id = path.Base(mount.Target)
+				}
+				ids[id] = ids[id] || mount.Required
+			}
+		}
+	}
+	return ids
+}
+
+// Return the ids of '--secret id=...' arguments.
+func getSecretIdsFromArgs(args []string) []string {
+	var ids []string
+	for i, arg := range args {
+		var value string
+		if v, ok := strings.CutPrefix(arg, "--secret="); ok {
+			value = v
+		} else if arg == "--secret" && i+1 < len(args) {
+			value = args[i+1]
+		} else {
+			continue
+		}
+		for _, opt := range strings.Split(value, ",") {
+			if id, ok := strings.CutPrefix(opt, "id="); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// Secret files up to this size are masked in the logs, larger ones are e.g. CA bundles.
+const maxRedactedSecretFileSize = 64 * 1024
+
+// Mask the content of the secret file in the logs, e.g. if a build arg or a command prints it.
+func registerSecretFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > maxRedactedSecretFileSize {
+		return nil
+	}
+	content, err := os.ReadFile(path) //nolint:gosec // secret file from the --secret-dirs directories
+	if err != nil {
+		return fmt.Errorf("reading secret file %s: %w", path, err)
+	}
+	l.RegisterSecret(string(content))
+	return nil
+}
+
 func (c *Build) processSecretDirs(secretDirs []secretDir) ([]cliWrappers.BuildahSecret, error) {
 	var buildahSecrets []cliWrappers.BuildahSecret
 	usedIDs := make(map[string]bool)
@@ -1081,16 +2192,35 @@ func (c *Build) processSecretDirs(secretDirs []secretDir) ([]cliWrappers.Buildah
 			filename := entry.Name()
 			fullID := filepath.Join(idPrefix, filename)
 
+			secretPath := filepath.Join(secretDir.src, filename)
+
 			// Check for ID conflicts
 			if usedIDs[fullID] {
-				return nil, fmt.Errorf("duplicate secret ID '%s': ensure unique basename/filename combinations", fullID)
+				switch c.Params.OnDuplicateSecret {
+				case onDuplicateSecretSkip:
+					l.Logger.Warnf("Duplicate secret ID '%s', skipping %s", fullID, secretPath)
+					continue
+				case onDuplicateSecretSuffix:
+					// Kubernetes projected volumes can legitimately produce colliding basenames,
+					// pick the first free ID-N in a deterministic way (order of --secret-dirs)
+					suffixedID := fullID
+					for n := 2; usedIDs[suffixedID]; n++ {
+						suffixedID = fmt.Sprintf("%s-%d", fullID, n)
+					}
+					l.Logger.Warnf("Duplicate secret ID '%s', renaming %s to '%s'", fullID, secretPath, suffixedID)
+					fullID = suffixedID
+				default:
+					return nil, fmt.Errorf("duplicate secret ID '%s': ensure unique basename/filename combinations", fullID)
+				}
 			}
 			usedIDs[fullID] = true
 
-			secretPath := filepath.Join(secretDir.src, filename)
 			buildahSecrets = append(
 				buildahSecrets, cliWrappers.BuildahSecret{Src: secretPath, Id: fullID},
 			)
+			if err := registerSecretFile(secretPath); err != nil {
+				return nil, err
+			}
 
 			l.Logger.Infof("Adding secret %s to the build, available with 'RUN --mount=type=secret,id=%s'", fullID, fullID)
 		}
@@ -1416,6 +2546,7 @@ func (c *Build) setPrefetchEnvSecrets(envJsonFile string) error {
 		if err := os.WriteFile(secretFile, []byte(ev.Value), 0600); err != nil {
 			return fmt.Errorf("writing secret file for %s: %w", ev.Name, err)
 		}
+		l.RegisterSecret(ev.Value)
 		c.buildahSecrets = append(c.buildahSecrets, cliWrappers.BuildahSecret{
 			Src: secretFile,
 			Id:  secretID,
@@ -1779,18 +2910,17 @@ func (c *Build) parseContainerfile() (*dockerfile.Dockerfile, error) {
 func (c *Build) createBuildArgExpander() (dockerfile.SingleWordExpander, error) {
 	// Define built-in ARG variables
 	// See https://docs.docker.com/build/building/variables/#multi-platform-build-arguments
-	platform := platforms.Normalize(platforms.DefaultSpec())
+	buildPlatform := platforms.Normalize(platforms.DefaultSpec())
+	targetPlatform := c.targetPlatformSpec()
 	args := map[string]string{
-		// We current don't explicitly expose the --platform flag, so the TARGET* values always
-		// match the BUILD* values. If we add --platform handling, we would want to respect it here.
-		"TARGETPLATFORM": platforms.Format(platform),
-		"TARGETOS":       platform.OS,
-		"TARGETARCH":     platform.Architecture,
-		"TARGETVARIANT":  platform.Variant,
-		"BUILDPLATFORM":  platforms.Format(platform),
-		"BUILDOS":        platform.OS,
-		"BUILDARCH":      platform.Architecture,
-		"BUILDVARIANT":   platform.Variant,
+		"TARGETPLATFORM": platforms.Format(targetPlatform),
+		"TARGETOS":       targetPlatform.OS,
+		"TARGETARCH":     targetPlatform.Architecture,
+		"TARGETVARIANT":  targetPlatform.Variant,
+		"BUILDPLATFORM":  platforms.Format(buildPlatform),
+		"BUILDOS":        buildPlatform.OS,
+		"BUILDARCH":      buildPlatform.Architecture,
+		"BUILDVARIANT":   buildPlatform.Variant,
 	}
 
 	// Load from --build-args-file, can override built-in args
@@ -1802,9 +2932,8 @@ func (c *Build) createBuildArgExpander() (dockerfile.SingleWordExpander, error)
 		maps.Copy(args, fileArgs)
 	}
 
-	// CLI --build-args take precedence over everything else
-	cliArgs := processKeyValueEnvs(c.Params.BuildArgs)
-	maps.Copy(args, cliArgs)
+	// CLI --build-args take precedence over everything else, same as the build args of buildahBuildArgs
+	cliWrappers.ApplyBuildArgs(args, c.buildArgs())
 
 	// Return the kind of "expander" function expected by the dockerfile-json API
 	// (takes the name of a build arg, returns the value or error for undefined build args)
@@ -1817,8 +2946,13 @@ func (c *Build) createBuildArgExpander() (dockerfile.SingleWordExpander, error)
 	return argExp, nil
 }
 
+// The --build-args passed to the build, after the proxy settings so the user can override them.
+func (c *Build) buildArgs() []string {
+	return slices.Concat(c.proxySettings.Env(), c.Params.BuildArgs)
+}
+
 // Parse an array of key[=value] args. If '=' is missing, look up the value in
-// environment variables. This is how buildah handles --build-arg and --env values.
+// environment variables. This is how buildah handles --env values, see ApplyBuildArgs for --build-arg.
 func processKeyValueEnvs(args []string) map[string]string {
 	values := make(map[string]string)
 	for _, arg := range args {
@@ -1870,6 +3004,12 @@ func (c *Build) processLabelsAndAnnotations() error {
 		defaultLabels = append(defaultLabels, ociRevision)
 	}
 
+	for _, cacheRepo := range slices.Concat(c.Params.CacheFrom, c.Params.CacheTo) {
+		if err := validateCacheRepository(cacheRepo); err != nil {
+			return err
+		}
+	}
+
 	if c.Params.QuayImageExpiresAfter != "" {
 		defaultLabels = append(defaultLabels, "quay.expires-after="+c.Params.QuayImageExpiresAfter)
 	}
@@ -1877,8 +3017,12 @@ func (c *Build) processLabelsAndAnnotations() error {
 	if c.Params.AddLegacyLabels {
 		defaultLabels = append(defaultLabels, "build-date="+buildTimeStr)
 
-		arch := goArchToRpmArch(runtime.GOARCH)
-		defaultLabels = append(defaultLabels, "architecture="+arch)
+		if c.isMultiPlatform() {
+			l.Logger.Warn("Not adding the legacy architecture label, it can't be set per platform in a multi-platform build.")
+		} else {
+			arch := goArchToRpmArch(c.targetPlatformSpec().Architecture)
+			defaultLabels = append(defaultLabels, "architecture="+arch)
+		}
 
 		if c.Params.ImageSource != "" {
 			defaultLabels = append(defaultLabels, "vcs-url="+c.Params.ImageSource)
@@ -1965,6 +3109,7 @@ func goArchToRpmArch(goarch string) string {
 // Injected files:
 // - labels.json: contains the labels of the resulting image, needed for the Clair scanning tool
 // - content-sets.json: contains the names of RPM repositories used for prefetching, needed for Clair
+// - content_manifests/<nvr>.json: the same as content-sets.json, with --inject-content-sets=content-manifests
 func (c *Build) injectBuildinfo(df *dockerfile.Dockerfile, userLabels []string, prefetchResources *prefetchResources) error {
 	// Create buildinfo directory in the temporary workdir and add it as a --build-context
 	buildinfoDir, err := c.createBuildinfoDir()
@@ -1987,7 +3132,9 @@ func (c *Build) injectBuildinfo(df *dockerfile.Dockerfile, userLabels []string,
 		return err
 	}
 
-	if prefetchResources != nil && prefetchResources.sbomFile != "" {
+	if c.Params.InjectContentSets == "never" {
+		l.Logger.Info("Injecting buildinfo: inject-content-sets=never, not adding content-sets.json")
+	} else if prefetchResources != nil && prefetchResources.sbomFile != "" {
 		// Create content-sets.json in buildinfo dir
 		contentSets, err := determineContentSets(prefetchResources.sbomFile)
 		if err != nil {
@@ -1997,6 +3144,18 @@ func (c *Build) injectBuildinfo(df *dockerfile.Dockerfile, userLabels []string,
 			return fmt.Errorf("writing content-sets.json to buildinfo dir: %w", err)
 		}
 		l.Logger.Info("Injecting buildinfo: added content-sets.json")
+
+		if c.Params.InjectContentSets == "content-manifests" {
+			contentManifestsDir := filepath.Join(buildinfoDir, "content_manifests")
+			if err := os.Mkdir(contentManifestsDir, 0755); err != nil {
+				return fmt.Errorf("creating content_manifests dir: %w", err)
+			}
+			filename := contentManifestFilename(labels)
+			if err := writeBuildinfoJSON(contentManifestsDir, contentSets, filename); err != nil {
+				return fmt.Errorf("writing %s to content_manifests dir: %w", filename, err)
+			}
+			l.Logger.Infof("Injecting buildinfo: added content_manifests/%s", filename)
+		}
 	} else {
 		l.Logger.Info("Injecting buildinfo: no prefetch SBOM found, not adding content-sets.json")
 	}
@@ -2018,6 +3177,22 @@ func (c *Build) injectBuildinfo(df *dockerfile.Dockerfile, userLabels []string,
 	return nil
 }
 
+// Name the content manifest like OSBS did, after the NVR of the image.
+// Fall back to content-sets.json if the labels don't provide the NVR.
+func contentManifestFilename(labels map[string]string) string {
+	component := labels["com.redhat.component"]
+	if component == "" {
+		component = labels["name"]
+	}
+	version := labels["version"]
+	release := labels["release"]
+	if component == "" || version == "" || release == "" {
+		return "content-sets.json"
+	}
+	nvr := strings.ReplaceAll(fmt.Sprintf("%s-%s-%s", component, version, release), "/", "-")
+	return nvr + ".json"
+}
+
 func (c *Build) createBuildinfoDir() (string, error) {
 	if err := c.ensureTempWorkdirExists(); err != nil {
 		return "", err
@@ -2170,7 +3345,8 @@ func (c *Build) determineFinalLabels(df *dockerfile.Dockerfile, userLabels []str
 	// Automatic buildah version label (highest precedence)
 	// Only injected if --source-date-epoch (or --timestamp, which we do not expose) are not used.
 	// See https://www.mankier.com/1/buildah-build#--identity-label.
-	if c.Params.SourceDateEpoch == "" {
+	// podman adds the version of the buildah library it embeds, which isn't known here.
+	if c.Params.SourceDateEpoch == "" && c.usesBuildah() {
 		labels["io.buildah.version"] = c.buildahVersion.Version
 	}
 
@@ -2246,7 +3422,7 @@ func isPullableImage(imageRef string) bool {
 		return false
 	}
 
-	transport, _ := splitTransport(imageRef)
+	transport, _ := common.SplitImageTransport(imageRef)
 	switch transport {
 	case "", "docker://", "containers-storage:":
 		return true
@@ -2255,35 +3431,11 @@ func isPullableImage(imageRef string) bool {
 	}
 }
 
-// Split an image ref that includes a transport into (transport, image ref).
-//
-// Example:
-// - "docker://registry.io/image:tag" -> ("docker://", "registry.io/image:tag")
-// - "registry.io/image:tag" -> ("", "registry.io/image:tag")
-func splitTransport(imageRef string) (string, string) {
-	transports := []string{
-		"docker://",
-		"containers-storage:",
-		"dir:",
-		"docker-archive:",
-		"docker-daemon:",
-		"oci:",
-		"oci-archive:",
-		"sif:",
-	}
-	for _, transport := range transports {
-		if imageRef, ok := strings.CutPrefix(imageRef, transport); ok {
-			return transport, imageRef
-		}
-	}
-	return "", imageRef
-}
-
 // Must be called after prePullBaseImages — the image is expected to be in local storage already.
 func (c *Build) getImageLabels(imageRef string) (map[string]string, error) {
 	// buildah inspect doesn't support the <transport>: prefix, strip it
-	_, inspectableRef := splitTransport(imageRef)
-	info, err := c.CliWrappers.BuildahCli.InspectImage(inspectableRef)
+	_, inspectableRef := common.SplitImageTransport(imageRef)
+	info, err := c.buildEngine().InspectImage(inspectableRef)
 	if err != nil {
 		return nil, fmt.Errorf("inspecting image %s: %w", inspectableRef, err)
 	}
@@ -2347,18 +3499,23 @@ func (c *Build) pullImage(imageRef string, platform string) error {
 		extraEnv = append(extraEnv, "_CONTAINERS_USERNS_CONFIGURED=done")
 	}
 
-	return c.CliWrappers.BuildahCli.Pull(&cliWrappers.BuildahPullArgs{
+	if platform == "" {
+		platform = c.targetPlatform
+	}
+
+	return c.buildEngine().Pull(&cliWrappers.BuildahPullArgs{
 		Image:     imageRef,
 		Platform:  platform,
 		HttpProxy: c.Params.ImagePullProxy,
 		NoProxy:   c.Params.ImagePullNoProxy,
 		TLSVerify: &c.Params.SrcTLSVerify,
+		CertDir:   c.certDir,
 		ExtraEnv:  extraEnv,
 	})
 }
 
-// Verify that each pre-pulled base image has an architecture matching the host
-// to prevent emulation builds, which are not allowed.
+// Verify that each pre-pulled base image has an architecture matching the target platform
+// (the host, unless --platform is set) to prevent building on top of the wrong architecture.
 //
 // If the image was a multi-arch index without the correct arch, buildah pull would
 // have already failed. This check catches single-arch references, where buildah
@@ -2370,19 +3527,22 @@ func (c *Build) pullImage(imageRef string, platform string) error {
 // When --allow-cross-platform-images is set, architecture mismatches are
 // downgraded from errors to warnings.
 func (c *Build) verifyBaseImageArchitectures(images []BaseImage) error {
-	hostArch := platforms.Normalize(platforms.DefaultSpec()).Architecture
+	expectedArch := c.targetPlatformSpec().Architecture
 
 	for _, image := range images {
-		_, inspectableRef := splitTransport(image.Ref)
-		info, err := c.CliWrappers.BuildahCli.InspectImage(inspectableRef)
+		_, inspectableRef := common.SplitImageTransport(image.Ref)
+		info, err := c.buildEngine().InspectImage(inspectableRef)
 		if err != nil {
 			return fmt.Errorf("inspecting base image %s: %w", image.Ref, err)
 		}
-		if info.OCIv1.Architecture != hostArch {
+		if err := checkBaseImageOS(image.Ref, info.OCIv1.OS, c.buildBackend()); err != nil {
+			return err
+		}
+		if info.OCIv1.Architecture != expectedArch {
 			if c.Params.AllowCrossPlatformImages {
 				l.Logger.Warnf(
 					"Base image %s has architecture '%s', expected '%s'. Cross-platform copy is a risky operation and we cannot guarantee expected results.",
-					image.Ref, info.OCIv1.Architecture, hostArch,
+					image.Ref, info.OCIv1.Architecture, expectedArch,
 				)
 				continue
 			}
@@ -2390,7 +3550,7 @@ func (c *Build) verifyBaseImageArchitectures(images []BaseImage) error {
 				"base image %s has architecture '%s', expected '%s'. "+
 					"Use a multi-arch image reference instead of a single-architecture reference, "+
 					"or explicitly allow cross-platform images in the build configuration",
-				image.Ref, info.OCIv1.Architecture, hostArch,
+				image.Ref, info.OCIv1.Architecture, expectedArch,
 			)
 		}
 	}
@@ -2559,6 +3719,104 @@ func (c *Build) allTags() []string {
 	return tags
 }
 
+// Files and directories that never belong in a sanitized build context.
+var defaultSanitizeContextExcludes = []string{
+	".git",
+	".idea",
+	".vscode",
+	".DS_Store",
+	"*.swp",
+	"*.swo",
+	"*~",
+}
+
+// Copy the context directory to the temporary workdir, leaving out VCS metadata and editor caches,
+// so that broad COPY instructions (e.g. 'COPY . .') can't leak the repository history into the image.
+// The containerignore file, if any, is copied along with the rest of the context.
+func (c *Build) sanitizeContext() error {
+	if err := c.ensureTempWorkdirExists(); err != nil {
+		return err
+	}
+	contextDir := filepath.Clean(c.effectiveContextDir())
+	sanitizedDir := filepath.Join(c.tempWorkdir, "context")
+
+	excludes := append(slices.Clone(defaultSanitizeContextExcludes), c.Params.SanitizeContextExcludes...)
+
+	l.Logger.Infof("Copying context directory %s to %s without %v", contextDir, sanitizedDir, excludes)
+
+	err := filepath.WalkDir(contextDir, func(srcPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(contextDir, srcPath)
+		if err != nil {
+			return err
+		}
+		if relPath != "." && isExcludedFromContext(relPath, excludes) {
+			l.Logger.Debugf("Excluding %s from the sanitized context", relPath)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		dstPath := filepath.Join(sanitizedDir, relPath)
+
+		switch d.Type() {
+		case os.ModeDir:
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			// Preserve the permissions, but the owner must be able to populate the directory
+			return os.Mkdir(dstPath, info.Mode().Perm()|0700)
+		case os.ModeSymlink:
+			target, err := os.Readlink(srcPath)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(target, dstPath) //nolint:gosec // G122: copying symlinks from context dir in WalkDir
+		case 0: // regular
+			return copyFile(srcPath, dstPath)
+		default:
+			l.Logger.Debugf("Skipping %s, unsupported file type bits: %#o", srcPath, d.Type())
+			return nil
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	c.sanitizedContextDir = sanitizedDir
+	return nil
+}
+
+// Check if the path (relative to the context directory) matches any of the exclude patterns.
+// Patterns without a '/' are matched against every path component.
+func isExcludedFromContext(relPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, "/") {
+			if matched, _ := filepath.Match(strings.Trim(pattern, "/"), relPath); matched {
+				return true
+			}
+			continue
+		}
+		if matched, _ := filepath.Match(pattern, filepath.Base(relPath)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// The context directory passed to buildah, i.e. the sanitized copy if --sanitize-context is in effect.
+func (c *Build) buildContextDir() string {
+	if c.sanitizedContextDir != "" {
+		return c.sanitizedContextDir
+	}
+	return c.effectiveContextDir()
+}
+
 func (c *Build) buildImage() (err error) {
 	l.Logger.Info("Building container image...")
 
@@ -2567,7 +3825,7 @@ func (c *Build) buildImage() (err error) {
 	if err != nil {
 		return err
 	}
-	if err := os.Chdir(c.effectiveContextDir()); err != nil {
+	if err := os.Chdir(c.buildContextDir()); err != nil {
 		return fmt.Errorf("couldn't cd to context directory: %w", err)
 	}
 	defer func() {
@@ -2576,6 +3834,21 @@ func (c *Build) buildImage() (err error) {
 		}
 	}()
 
+	buildArgs := c.buildahBuildArgs()
+	if err := buildArgs.MakePathsAbsolute(originalCwd); err != nil {
+		return err
+	}
+
+	if err := c.buildEngine().Build(buildArgs); err != nil {
+		return err
+	}
+
+	l.Logger.Info("Build completed successfully")
+	return nil
+}
+
+// The arguments of buildah build, the paths may be relative to the current directory.
+func (c *Build) buildahBuildArgs() *cliWrappers.BuildahBuildArgs {
 	containerfilePath := c.containerfilePath
 	if c.containerfileCopyPath != "" {
 		containerfilePath = c.containerfileCopyPath
@@ -2583,31 +3856,41 @@ func (c *Build) buildImage() (err error) {
 
 	buildArgs := &cliWrappers.BuildahBuildArgs{
 		Containerfile:    containerfilePath,
-		ContextDir:       c.effectiveContextDir(),
+		ContextDir:       c.buildContextDir(),
 		Tags:             c.allTags(),
 		Secrets:          c.buildahSecrets,
 		Mounts:           c.buildahMounts,
 		Volumes:          c.buildahVolumes,
-		BuildArgs:        c.Params.BuildArgs,
+		BuildArgs:        c.buildArgs(),
 		BuildArgsFile:    c.Params.BuildArgsFile,
+		IgnoreFile:       c.generatedIgnoreFile,
 		Envs:             c.Params.Envs,
 		Labels:           c.mergedLabels,
 		Annotations:      c.mergedAnnotations,
 		SourceDateEpoch:  c.Params.SourceDateEpoch,
 		RewriteTimestamp: c.Params.RewriteTimestamp,
 		ExtraArgs:        c.Params.ExtraArgs,
+		Timeout:          c.buildTimeout,
 		InheritLabels:    &c.Params.InheritLabels,
 		Target:           c.Params.Target,
 		SkipUnusedStages: &c.Params.SkipUnusedStages,
 		TLSVerify:        &c.Params.SrcTLSVerify,
+		CertDir:          c.certDir,
 		Squash:           c.Params.Squash,
 		OmitHistory:      c.Params.OmitHistory,
 		NoCache:          c.Params.NoCache,
+		Layers:           c.Params.Layers || len(c.Params.CacheFrom) > 0 || len(c.Params.CacheTo) > 0,
+		CacheFrom:        c.Params.CacheFrom,
+		CacheTo:          c.Params.CacheTo,
 		SecurityOpts:     c.Params.SecurityOpts,
+		Isolation:        c.Params.Isolation,
+		Userns:           c.Params.Userns,
 		CapAdd:           c.Params.CapAdd,
 		CapDrop:          c.Params.CapDrop,
 		Devices:          c.Params.Devices,
 		Ulimits:          c.Params.Ulimits,
+		Format:           c.Params.Format,
+		Platforms:        c.buildPlatforms,
 		SaveStages:       c.enableBuilderContentScanning(),
 		// Note: --stage-labels adds io.buildah.stage.{name,base} labels to all
 		// stages including the final image. These labels will be missing from
@@ -2629,22 +3912,17 @@ func (c *Build) buildImage() (err error) {
 	}
 	if c.Params.WorkdirMount != "" {
 		buildArgs.Volumes = append(buildArgs.Volumes, cliWrappers.BuildahVolume{
-			HostDir: c.effectiveContextDir(), ContainerDir: c.Params.WorkdirMount, Options: "z"})
+			HostDir: c.buildContextDir(), ContainerDir: c.Params.WorkdirMount, Options: "z"})
 	}
 	if c.buildinfoBuildContext != nil {
 		buildArgs.BuildContexts = []cliWrappers.BuildahBuildContext{*c.buildinfoBuildContext}
 	}
-
-	if err := buildArgs.MakePathsAbsolute(originalCwd); err != nil {
-		return err
-	}
-
-	if err := c.CliWrappers.BuildahCli.Build(buildArgs); err != nil {
-		return err
+	if c.isMultiPlatform() {
+		// Tagging would only tag the image of the last platform, collect them in a manifest list
+		buildArgs.Tags = nil
+		buildArgs.Manifest = c.Params.OutputRef
 	}
-
-	l.Logger.Info("Build completed successfully")
-	return nil
+	return buildArgs
 }
 
 func (c *Build) runSyftScans() (err error) {
@@ -2682,10 +3960,20 @@ func (c *Build) runSyftScans() (err error) {
 			return fmt.Errorf("syft source scan: %w", err)
 		}
 		l.Logger.Infof("Source SBOM written to %s", c.Params.SyftSourceOutput)
+		c.syftSourceSbom = syftSourceOutput
+	}
+
+	syftImageOutput := c.Params.SyftImageOutput
+	if syftImageOutput == "" && c.Params.SBOMOutput != "" {
+		// --sbom-output needs the image scan even if the plain output isn't requested
+		if err := c.ensureTempWorkdirExists(); err != nil {
+			return err
+		}
+		syftImageOutput = filepath.Join(c.tempWorkdir, "sbom-image.json")
 	}
 
-	if c.Params.SyftImageOutput != "" {
-		syftImageOutput, err := filepath.Abs(c.Params.SyftImageOutput)
+	if syftImageOutput != "" {
+		syftImageOutput, err := filepath.Abs(syftImageOutput)
 		if err != nil {
 			return fmt.Errorf("getting absolute syft-image-output path: %w", err)
 		}
@@ -2717,7 +4005,8 @@ func (c *Build) runSyftScans() (err error) {
 		if err != nil {
 			return fmt.Errorf("syft image scan: %w", err)
 		}
-		l.Logger.Infof("Image SBOM written to %s", c.Params.SyftImageOutput)
+		l.Logger.Infof("Image SBOM written to %s", syftImageOutput)
+		c.syftImageSbom = syftImageOutput
 	}
 
 	return nil
@@ -2728,49 +4017,143 @@ func (c *Build) enableBuilderContentScanning() bool {
 		slices.Compare(c.parsedBuildahVersion, []int{1, 44, 0}) >= 0
 }
 
+func (c *Build) pushStep() error {
+	endStep := l.StartStep("push")
+	digest, err := c.pushImage()
+	endStep()
+	if err != nil {
+		return err
+	}
+	c.Results.Digest = digest
+	return nil
+}
+
 func (c *Build) pushImage() (string, error) {
+	if c.isMultiPlatform() {
+		return c.pushManifestList()
+	}
+	if c.Params.RemotePush {
+		return c.pushImageRemotely()
+	}
 	l.Logger.Infof("Pushing image to registry: %s", c.Params.OutputRef)
 
-	pushArgs := &cliWrappers.BuildahPushArgs{
-		Image:     c.Params.OutputRef,
-		TLSVerify: &c.Params.DestTLSVerify,
-	}
+	var digest string
+	if c.checkpoint != nil && c.checkpoint.Digest != "" {
+		digest = c.checkpoint.Digest
+		l.Logger.Infof("Skipping push, the image was pushed by the interrupted run")
+	} else {
+		pushArgs := &cliWrappers.BuildahPushArgs{
+			Image:     c.Params.OutputRef,
+			TLSVerify: &c.Params.DestTLSVerify,
+			CertDir:   c.certDir,
+			Format:    c.Params.Format,
+			Timeout:   c.pushTimeout,
+		}
 
-	digest, err := c.CliWrappers.BuildahCli.Push(pushArgs)
-	if err != nil {
-		return "", fmt.Errorf("pushing image %s: %w", c.Params.OutputRef, err)
-	}
+		var err error
+		digest, err = c.push(pushArgs)
+		if err != nil {
+			return "", fmt.Errorf("pushing image %s: %w", c.Params.OutputRef, err)
+		}
 
-	l.Logger.Info("Push completed successfully")
+		l.Logger.Info("Push completed successfully")
+		if c.checkpoint != nil {
+			c.checkpoint.Digest = digest
+			if err := c.saveCheckpoint(); err != nil {
+				return "", err
+			}
+		}
+	}
 	l.Logger.Infof("Image digest: %s", digest)
 
 	imageName := common.GetImageName(c.Params.OutputRef)
 	for _, tag := range c.Params.AdditionalTags {
+		if c.checkpoint != nil && slices.Contains(c.checkpoint.PushedTags, tag) {
+			l.Logger.Infof("Skipping additional tag %s, pushed by the interrupted run", tag)
+			continue
+		}
 		additionalImage := imageName + ":" + tag
 		l.Logger.Infof("Pushing additional tag: %s", tag)
 
-		_, err := c.CliWrappers.BuildahCli.Push(&cliWrappers.BuildahPushArgs{
+		_, err := c.push(&cliWrappers.BuildahPushArgs{
 			Image:     additionalImage,
 			TLSVerify: &c.Params.DestTLSVerify,
+			CertDir:   c.certDir,
+			Format:    c.Params.Format,
+			Timeout:   c.pushTimeout,
 		})
 		if err != nil {
 			return "", fmt.Errorf("pushing additional tag %s: %w", tag, err)
 		}
 		l.Logger.Infof("Pushed additional tag successfully: %s", tag)
+		if c.checkpoint != nil {
+			c.checkpoint.PushedTags = append(c.checkpoint.PushedTags, tag)
+			if err := c.saveCheckpoint(); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return digest, nil
+}
+
+// Lint the parsed Containerfile, the labels set by the build satisfy the required labels.
+func (c *Build) lint(containerfile *dockerfile.Dockerfile) error {
+	c.Results.LintFindings = lintContainerfile(containerfile, &lintOptions{
+		labels:         c.mergedLabels,
+		requiredLabels: strings.Fields(defaultLintRequiredLabels),
+	})
+	logLintFindings(c.Results.LintFindings)
+	return checkLintFindings(c.Results.LintFindings, c.Params.LintFailOn)
+}
+
+// Write the built image (or manifest list) to the --output OCI layout. Return the digest of the written manifest.
+func (c *Build) writeOutput() (string, error) {
+	l.Logger.Infof("Writing image to %s", c.Params.Output)
+
+	var digest string
+	var err error
+	if c.isMultiPlatform() {
+		digest, err = c.CliWrappers.BuildahCli.ManifestPush(&cliWrappers.BuildahManifestPushArgs{
+			ManifestName: c.Params.OutputRef,
+			Destination:  c.Params.Output,
+			Format:       c.Params.Format,
+			TLSVerify:    true,
+		})
+	} else {
+		digest, err = c.buildEngine().Push(&cliWrappers.BuildahPushArgs{
+			Image:       c.Params.OutputRef,
+			Destination: c.Params.Output,
+			Format:      c.Params.Format,
+		})
+	}
+	if err != nil {
+		return "", fmt.Errorf("writing image to %s: %w", c.Params.Output, err)
 	}
 
+	l.Logger.Infof("Image digest: %s", digest)
 	return digest, nil
 }
 
 func (c *Build) writeContainerfileJson(containerfile *dockerfile.Dockerfile, outputPath string) error {
 	l.Logger.Infof("Writing parsed Containerfile to: %s", outputPath)
 
+	// The --mount flags of RUN instructions only get parsed when expanding the instructions,
+	// after dockerfile-json has already recorded the mounts. Refresh them to get the actual values.
+	for _, stage := range containerfile.Stages {
+		for _, cmd := range stage.Commands {
+			if runCmd, ok := cmd.Command.(*instructions.RunCommand); ok {
+				cmd.Mounts = instructions.GetMounts(runCmd)
+			}
+		}
+	}
+
 	jsonData, err := json.MarshalIndent(containerfile, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal Containerfile to JSON: %w", err)
 	}
 
-	if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
+	if err := common.WriteResultFile(outputPath, jsonData); err != nil {
 		return fmt.Errorf("failed to write Containerfile JSON: %w", err)
 	}
 
@@ -2799,7 +4182,7 @@ func (c *Build) writeResolvedBaseImages(pulledImages []BaseImage, outputPath str
 		s.WriteByte('\n')
 	}
 
-	err = os.WriteFile(outputPath, []byte(s.String()), 0644)
+	err = common.WriteResultFile(outputPath, []byte(s.String()))
 	if err != nil {
 		return fmt.Errorf("writing resolved base images: %w", err)
 	}
@@ -2891,7 +4274,7 @@ func (c *Build) scanBuilderContent() (err error) {
 		return fmt.Errorf("marshaling builder content: %w", err)
 	}
 
-	if err := os.WriteFile(c.Params.BuilderMetadataOutput, output, 0644); err != nil {
+	if err := common.WriteResultFile(c.Params.BuilderMetadataOutput, output); err != nil {
 		return fmt.Errorf("writing builder content output: %w", err)
 	}
 
@@ -2903,7 +4286,7 @@ func (c *Build) resolveBaseImages(pulledImages []BaseImage) ([]BaseImage, error)
 	var resolvedImages []BaseImage
 
 	for _, image := range pulledImages {
-		_, bareImage := splitTransport(image.Ref)
+		_, bareImage := common.SplitImageTransport(image.Ref)
 
 		inputRef, err := reference.Parse(bareImage)
 		if err != nil {
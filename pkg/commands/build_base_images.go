@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/platforms"
+	"github.com/keilerkonzept/dockerfile-json/pkg/dockerfile"
+	"github.com/opencontainers/go-digest"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// Resolve the FROM images of the Containerfile stages to the digests their references currently
+// point to and record them in the results. Stages built from other stages or from scratch are
+// not recorded. Short names and non-registry transports are recorded without a digest, the
+// registry of short names is only known to buildah (registries.conf).
+//
+// Returns the digests of the stages referencing their image by tag, by stage index.
+func (c *Build) recordBaseImages(containerfile *dockerfile.Dockerfile) (map[int]string, error) {
+	var baseImages []BuildBaseImage
+	stageDigests := make(map[int]string)
+	resolved := make(map[string]string)
+
+	for i, stage := range containerfile.Stages {
+		if stage.From.Image == nil {
+			continue
+		}
+		imageRef := *stage.From.Image
+		baseImage := BuildBaseImage{Stage: stage.Stage.Name, Name: imageRef}
+		if baseImage.Stage == "" {
+			baseImage.Stage = strconv.Itoa(i)
+		}
+
+		transport, bareImage := common.SplitImageTransport(imageRef)
+		switch {
+		case transport != "" && transport != "docker://":
+			l.Logger.Debugf("Not resolving %s: unsupported transport", imageRef)
+		case !common.IsNormalizedRef(bareImage):
+			l.Logger.Warnf("Not resolving %s: not a fully qualified image reference", imageRef)
+		case common.GetImageDigest(bareImage) != "":
+			baseImage.Digest = common.GetImageDigest(bareImage)
+		default:
+			imageDigest, ok := resolved[imageRef]
+			if !ok {
+				var err error
+				imageDigest, err = c.inspectBaseImageDigest(bareImage, stage.Platform)
+				if err != nil {
+					return nil, err
+				}
+				l.Logger.Infof("Resolved base image %s to %s", imageRef, imageDigest)
+				resolved[imageRef] = imageDigest
+			}
+			baseImage.Digest = imageDigest
+			stageDigests[i] = imageDigest
+		}
+
+		baseImages = append(baseImages, baseImage)
+	}
+
+	c.Results.BaseImages = baseImages
+	return stageDigests, nil
+}
+
+// For --require-pinned-base-images, fail if a FROM instruction references a base image by a tag
+// instead of a digest, reporting all the offending instructions.
+func (c *Build) checkPinnedBaseImages(containerfile *dockerfile.Dockerfile) error {
+	violations := findUnpinnedBaseImages(containerfile)
+	if len(violations) == 0 {
+		return nil
+	}
+	var report []string
+	for _, violation := range violations {
+		location := ""
+		if violation.line > 0 {
+			location = fmt.Sprintf("line %d: ", violation.line)
+		}
+		l.Logger.Errorf("%s: %s%s", c.containerfilePath, location, violation.message)
+		report = append(report, "  "+location+violation.message)
+	}
+	return fmt.Errorf("%d base image(s) of %s not pinned to a digest, required by --require-pinned-base-images:\n%s",
+		len(violations), c.containerfilePath, strings.Join(report, "\n"))
+}
+
+// Pin the FROM images of the Containerfile to the digests resolved by recordBaseImages.
+// The FROM instructions of the Containerfile copy are rewritten, so that a tag moving during
+// the build can't change the result.
+//
+// The parsed containerfile is updated to the pinned references.
+func (c *Build) pinBaseImages(containerfile *dockerfile.Dockerfile, stageDigests map[int]string) error {
+	if len(stageDigests) == 0 {
+		return nil
+	}
+
+	pinnedRefs := make(map[int]string, len(stageDigests))
+	for i, imageDigest := range stageDigests {
+		_, bareImage := common.SplitImageTransport(*containerfile.Stages[i].From.Image)
+		pinnedRefs[i] = bareImage + "@" + imageDigest
+	}
+
+	if err := c.rewriteFromInstructions(containerfile, pinnedRefs); err != nil {
+		return err
+	}
+	for i, pinnedRef := range pinnedRefs {
+		l.Logger.Infof("Pinned base image %s to %s", *containerfile.Stages[i].From.Image, pinnedRef)
+		stage := containerfile.Stages[i]
+		stage.BaseName = pinnedRef
+		stage.From.Image = &stage.BaseName
+	}
+	return nil
+}
+
+// Get the digest of the image manifest (or manifest list) the reference points to.
+func (c *Build) inspectBaseImageDigest(imageRef, stagePlatform string) (string, error) {
+	args := &cliWrappers.SkopeoInspectArgs{
+		ImageRef: imageRef,
+		NoTags:   true,
+		Format:   "{{.Digest}}",
+	}
+	// The digest is of the manifest list if there's one, but skopeo inspects an image of the list
+	platform := c.targetPlatformSpec()
+	if stagePlatform != "" {
+		if spec, err := platforms.Parse(stagePlatform); err == nil {
+			platform = platforms.Normalize(spec)
+		}
+	}
+	args.ExtraArgs = append(args.ExtraArgs, "--override-os", platform.OS, "--override-arch", platform.Architecture)
+	if platform.Variant != "" {
+		args.ExtraArgs = append(args.ExtraArgs, "--override-variant", platform.Variant)
+	}
+	if !c.Params.SrcTLSVerify {
+		args.ExtraArgs = append(args.ExtraArgs, "--tls-verify=false")
+	}
+	args.CertDir = c.certDir
+
+	output, err := c.CliWrappers.SkopeoCli.Inspect(args)
+	if err != nil {
+		return "", fmt.Errorf("resolving base image %s: %w", imageRef, err)
+	}
+	imageDigest := strings.TrimSpace(output)
+	if _, err := digest.Parse(imageDigest); err != nil {
+		return "", fmt.Errorf("resolving base image %s: invalid digest '%s': %w", imageRef, imageDigest, err)
+	}
+	return imageDigest, nil
+}
+
+// Replace the FROM instructions of the given stages with ones using the pinned references.
+// The instructions may span multiple lines, the replaced lines are kept empty so that
+// the line numbers of the other instructions don't change.
+func (c *Build) rewriteFromInstructions(containerfile *dockerfile.Dockerfile, pinnedRefs map[int]string) error {
+	if err := c.ensureContainerfileCopied(); err != nil {
+		return err
+	}
+	content, err := os.ReadFile(c.containerfileCopyPath)
+	if err != nil {
+		return fmt.Errorf("reading containerfile copy: %w", err)
+	}
+	lines := strings.Split(string(content), "\n")
+
+	for i, pinnedRef := range pinnedRefs {
+		stage := containerfile.Stages[i]
+		if len(stage.Location) == 0 {
+			return fmt.Errorf("location of the FROM instruction of stage %d is unknown", i)
+		}
+		start, end := stage.Location[0].Start.Line, stage.Location[len(stage.Location)-1].End.Line
+		if start < 1 || end > len(lines) {
+			return fmt.Errorf("location of the FROM instruction of stage %d is out of the Containerfile", i)
+		}
+
+		from := "FROM "
+		if stage.Platform != "" {
+			from += "--platform=" + stage.Platform + " "
+		}
+		from += pinnedRef
+		if stage.Stage.Name != "" {
+			from += " AS " + stage.Stage.Name
+		}
+
+		lines[start-1] = from
+		for line := start; line < end; line++ {
+			lines[line] = ""
+		}
+	}
+
+	if err := os.WriteFile(c.containerfileCopyPath, []byte(strings.Join(lines, "\n")), 0644); err != nil { //nolint:gosec // G703: path from build context
+		return fmt.Errorf("writing containerfile with pinned base images: %w", err)
+	}
+	return nil
+}
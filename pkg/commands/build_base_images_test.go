@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Build_recordBaseImages(t *testing.T) {
+	const digestA = "sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c"
+	const digestB = "sha256:0f6f1e8d0a8f1f8a3e0b2d8c7d6d5a4b3c2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a"
+
+	type inspectCall struct {
+		imageRef  string
+		extraArgs []string
+	}
+
+	newBuild := func(t *testing.T, g Gomega, content string, inspect func(args *cliwrappers.SkopeoInspectArgs) (string, error)) (*Build, *[]inspectCall) {
+		containerfile := filepath.Join(t.TempDir(), "Containerfile")
+		g.Expect(os.WriteFile(containerfile, []byte(content), 0644)).To(Succeed())
+
+		var calls []inspectCall
+		c := &Build{
+			Params: &BuildParams{SrcTLSVerify: true},
+			CliWrappers: BuildCliWrappers{
+				SkopeoCli: &mockSkopeoCli{
+					InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+						calls = append(calls, inspectCall{imageRef: args.ImageRef, extraArgs: args.ExtraArgs})
+						return inspect(args)
+					},
+				},
+			},
+			containerfilePath: containerfile,
+			targetPlatform:    "linux/amd64",
+		}
+		t.Cleanup(c.cleanup)
+		return c, &calls
+	}
+
+	inspectDigests := func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+		switch args.ImageRef {
+		case "quay.io/org/builder:1.0":
+			return digestA + "\n", nil
+		case "registry.access.redhat.com/ubi9/ubi-minimal:latest":
+			return digestB + "\n", nil
+		}
+		return "", errors.New("unexpected image")
+	}
+
+	t.Run("should pin the FROM instructions of the containerfile copy", func(t *testing.T) {
+		g := NewWithT(t)
+
+		content := "FROM quay.io/org/builder:1.0 AS builder\n" +
+			"RUN make\n" +
+			"FROM --platform=linux/arm64 \\\n" +
+			"    registry.access.redhat.com/ubi9/ubi-minimal:latest\n" +
+			"COPY --from=builder /app /app\n"
+		c, calls := newBuild(t, g, content, inspectDigests)
+
+		containerfile, err := c.parseContainerfile()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		stageDigests, err := c.recordBaseImages(containerfile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.pinBaseImages(containerfile, stageDigests)).To(Succeed())
+
+		copyContent, err := os.ReadFile(c.containerfileCopyPath)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(copyContent)).To(Equal(
+			"FROM quay.io/org/builder:1.0@" + digestA + " AS builder\n" +
+				"RUN make\n" +
+				"FROM --platform=linux/arm64 registry.access.redhat.com/ubi9/ubi-minimal:latest@" + digestB + "\n" +
+				"\n" +
+				"COPY --from=builder /app /app\n",
+		))
+		// Original is unchanged
+		originalContent, err := os.ReadFile(c.containerfilePath)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(originalContent)).To(Equal(content))
+
+		g.Expect(*calls).To(Equal([]inspectCall{
+			{imageRef: "quay.io/org/builder:1.0", extraArgs: []string{"--override-os", "linux", "--override-arch", "amd64"}},
+			{imageRef: "registry.access.redhat.com/ubi9/ubi-minimal:latest", extraArgs: []string{"--override-os", "linux", "--override-arch", "arm64"}},
+		}))
+
+		g.Expect(c.Results.BaseImages).To(Equal([]BuildBaseImage{
+			{Stage: "builder", Name: "quay.io/org/builder:1.0", Digest: digestA},
+			{Stage: "1", Name: "registry.access.redhat.com/ubi9/ubi-minimal:latest", Digest: digestB},
+		}))
+		g.Expect(*containerfile.Stages[0].From.Image).To(Equal("quay.io/org/builder:1.0@" + digestA))
+		g.Expect(*containerfile.Stages[1].From.Image).To(Equal("registry.access.redhat.com/ubi9/ubi-minimal:latest@" + digestB))
+	})
+
+	t.Run("should inspect an image used by multiple stages once", func(t *testing.T) {
+		g := NewWithT(t)
+
+		content := "FROM quay.io/org/builder:1.0 AS first\n" +
+			"FROM quay.io/org/builder:1.0\n"
+		c, calls := newBuild(t, g, content, inspectDigests)
+
+		containerfile, err := c.parseContainerfile()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		stageDigests, err := c.recordBaseImages(containerfile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.pinBaseImages(containerfile, stageDigests)).To(Succeed())
+
+		g.Expect(*calls).To(HaveLen(1))
+		copyContent, err := os.ReadFile(c.containerfileCopyPath)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(copyContent)).To(Equal(
+			"FROM quay.io/org/builder:1.0@" + digestA + " AS first\n" +
+				"FROM quay.io/org/builder:1.0@" + digestA + "\n",
+		))
+	})
+
+	t.Run("should not inspect images referenced by digest or not resolvable", func(t *testing.T) {
+		g := NewWithT(t)
+
+		content := "FROM quay.io/org/builder@" + digestA + "\n" +
+			"FROM scratch\n" +
+			"FROM ubi9\n" +
+			"FROM oci-archive:base.tar\n"
+		c, calls := newBuild(t, g, content, inspectDigests)
+
+		containerfile, err := c.parseContainerfile()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		stageDigests, err := c.recordBaseImages(containerfile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.pinBaseImages(containerfile, stageDigests)).To(Succeed())
+
+		g.Expect(*calls).To(BeEmpty())
+		g.Expect(stageDigests).To(BeEmpty())
+		// Nothing to pin, the containerfile is not copied
+		g.Expect(c.containerfileCopyPath).To(BeEmpty())
+		g.Expect(c.Results.BaseImages).To(Equal([]BuildBaseImage{
+			{Stage: "0", Name: "quay.io/org/builder@" + digestA, Digest: digestA},
+			{Stage: "2", Name: "ubi9"},
+			{Stage: "3", Name: "oci-archive:base.tar"},
+		}))
+	})
+
+	t.Run("should disable TLS verification when requested", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c, calls := newBuild(t, g, "FROM quay.io/org/builder:1.0\n", inspectDigests)
+		c.Params.SrcTLSVerify = false
+
+		containerfile, err := c.parseContainerfile()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		stageDigests, err := c.recordBaseImages(containerfile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.pinBaseImages(containerfile, stageDigests)).To(Succeed())
+		g.Expect((*calls)[0].extraArgs).To(ContainElement("--tls-verify=false"))
+	})
+
+	t.Run("should fail when the image can't be inspected", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c, _ := newBuild(t, g, "FROM quay.io/org/missing:1.0\n", inspectDigests)
+
+		containerfile, err := c.parseContainerfile()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.recordBaseImages(containerfile)
+		g.Expect(err).To(MatchError(ContainSubstring("resolving base image quay.io/org/missing:1.0: unexpected image")))
+	})
+
+	t.Run("should fail on an invalid digest", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c, _ := newBuild(t, g, "FROM quay.io/org/builder:1.0\n", func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			return "not-a-digest", nil
+		})
+
+		containerfile, err := c.parseContainerfile()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		_, err = c.recordBaseImages(containerfile)
+		g.Expect(err).To(MatchError(ContainSubstring("invalid digest 'not-a-digest'")))
+	})
+}
+
+func Test_Build_checkPinnedBaseImages(t *testing.T) {
+	t.Run("should report all the base images referenced by tag", func(t *testing.T) {
+		g := NewWithT(t)
+		containerfile := parseLintTestContainerfile(t, `FROM quay.io/org/builder:1.0 AS builder
+FROM scratch
+FROM builder
+FROM quay.io/org/base@sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c
+FROM registry.access.redhat.com/ubi9
+`)
+		c := &Build{Params: &BuildParams{}, containerfilePath: "/src/Containerfile"}
+
+		err := c.checkPinnedBaseImages(containerfile)
+		g.Expect(err).To(MatchError("2 base image(s) of /src/Containerfile not pinned to a digest, required by --require-pinned-base-images:\n" +
+			"  line 1: base image quay.io/org/builder:1.0 is not pinned to a digest\n" +
+			"  line 5: base image registry.access.redhat.com/ubi9 is not pinned to a digest"))
+	})
+
+	t.Run("should pass when all the base images are pinned", func(t *testing.T) {
+		g := NewWithT(t)
+		containerfile := parseLintTestContainerfile(t, `FROM quay.io/org/base:1.0@sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c AS base
+FROM base
+FROM scratch
+`)
+		c := &Build{Params: &BuildParams{}, containerfilePath: "/src/Containerfile"}
+
+		g.Expect(c.checkPinnedBaseImages(containerfile)).To(Succeed())
+	})
+}
@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/keilerkonzept/dockerfile-json/pkg/dockerfile"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// Labels that mark an image as a bootable container image. bootc base images set both,
+// bootc and ostree tooling look them up to tell bootable images from application images.
+var bootcRequiredLabels = []string{"containers.bootc=1", "ostree.bootable=1"}
+
+// Logically bound images are pulled by bootc together with the OS image. The directory contains
+// symlinks to the .image or .container quadlet files that reference the images.
+// https://bootc-dev.github.io/bootc/logically-bound-images.html
+const bootcBoundImagesDir = "usr/lib/bootc/bound-images.d"
+
+func validateBootcParams(params *BuildParams) error {
+	if params.Format == "docker" {
+		return fmt.Errorf("bootc images must use the oci format, got format 'docker'")
+	}
+	if params.Squash {
+		// The layers of bootc base images are split so that OS updates only download
+		// the changed content, squashing would turn every update into a full download.
+		return fmt.Errorf("squash is not supported for bootc images, it defeats the layer splitting of the base image")
+	}
+	return nil
+}
+
+// Set the build options that bootc images need, see validateBootcParams for the ones
+// that can't be overridden.
+func (c *Build) setBootcDefaults() {
+	if c.Params.Format == "" {
+		// ostree container encapsulation is defined for OCI images
+		c.Params.Format = "oci"
+	}
+}
+
+// Add the labels that mark the image as bootable. They normally come from the base image,
+// but aren't inherited with --inherit-labels=false.
+func (c *Build) addBootcLabels() {
+	if c.Params.InheritLabels {
+		return
+	}
+	c.mergedLabels = slices.Concat(bootcRequiredLabels, c.mergedLabels)
+}
+
+// Warn about the instructions in the final stage that don't have any effect on a booted system.
+func checkBootcContainerfile(df *dockerfile.Dockerfile) {
+	if df == nil || len(df.Stages) == 0 {
+		return
+	}
+	finalStage := df.Stages[len(df.Stages)-1]
+	for _, cmd := range finalStage.Commands {
+		switch cmd.Command.(type) {
+		case *instructions.VolumeCommand, *instructions.ExposeCommand, *instructions.CmdCommand,
+			*instructions.EntrypointCommand, *instructions.HealthCheckCommand, *instructions.StopSignalCommand:
+			l.Logger.Warnf("bootc: %s has no effect on a bootc system, the image boots systemd instead of running a command", strings.ToUpper(cmd.Name))
+		case *instructions.UserCommand:
+			l.Logger.Warnf("bootc: USER has no effect on a bootc system, configure users with systemd-sysusers or in the installer instead")
+		}
+	}
+}
+
+// Verify that the final stage is based on a bootc base image, or labels itself as one.
+// Must be called after prePullBaseImages.
+func (c *Build) verifyBootcBaseImage(df *dockerfile.Dockerfile) error {
+	baseImage, labels := processUntilBaseStage(df)
+	if isBootcImage(labels) {
+		return nil
+	}
+	if baseImage != "" && isPullableImage(baseImage) {
+		baseImageLabels, err := c.getImageLabels(baseImage)
+		if err != nil {
+			return fmt.Errorf("getting base image labels: %w", err)
+		}
+		if isBootcImage(baseImageLabels) {
+			return nil
+		}
+		return fmt.Errorf("%s is not a bootc base image, it doesn't have the %s label", baseImage, bootcRequiredLabels[0])
+	}
+	return fmt.Errorf("the final stage is not based on a bootc base image and doesn't set the %s label", bootcRequiredLabels[0])
+}
+
+func isBootcImage(labels map[string]string) bool {
+	for _, label := range bootcRequiredLabels {
+		key, value, _ := strings.Cut(label, "=")
+		if labels[key] == value || labels[key] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// Find the logically bound images of the built image. bootc pulls them at install
+// and update time, so they must be available to the systems that use the image.
+func (c *Build) findBootcBoundImages() ([]string, error) {
+	container, err := c.CliWrappers.BuildahCli.From(c.Params.OutputRef)
+	if err != nil {
+		return nil, fmt.Errorf("buildah from: %w", err)
+	}
+	defer func() {
+		if rmErr := c.CliWrappers.BuildahCli.Rm(container); rmErr != nil {
+			l.Logger.Warnf("Failed to clean up working container %q: %s", container, rmErr)
+		}
+	}()
+	mountPoint, err := c.CliWrappers.BuildahCli.Mount(container)
+	if err != nil {
+		return nil, fmt.Errorf("buildah mount: %w", err)
+	}
+
+	boundImages, err := readBootcBoundImages(mountPoint)
+	if err != nil {
+		return nil, err
+	}
+	for _, image := range boundImages {
+		l.Logger.Infof("bootc: logically bound image: %s", image)
+		if common.GetImageDigest(image) == "" {
+			l.Logger.Warnf("bootc: logically bound image %s is not pinned by digest, "+
+				"systems installed at different times may get different images", image)
+		}
+	}
+	return boundImages, nil
+}
+
+// Read the images referenced by the quadlet files in bound-images.d of an image filesystem.
+func readBootcBoundImages(rootfs string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(rootfs, bootcBoundImagesDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var images []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if ext != ".image" && ext != ".container" {
+			continue
+		}
+		path := filepath.Join(bootcBoundImagesDir, entry.Name())
+		if entry.Type()&os.ModeSymlink != 0 {
+			// The links are absolute paths within the image, resolve them inside the rootfs
+			target, err := os.Readlink(filepath.Join(rootfs, path))
+			if err != nil {
+				return nil, err
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(path), target)
+			}
+			path = target
+		}
+		image, err := readQuadletImage(filepath.Join(rootfs, filepath.Clean("/"+path)))
+		if err != nil {
+			return nil, fmt.Errorf("reading bound image %s: %w", entry.Name(), err)
+		}
+		if image == "" {
+			return nil, fmt.Errorf("bound image %s doesn't set Image=", entry.Name())
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+// Return the Image= value of the [Image] or [Container] section of a quadlet file.
+func readQuadletImage(path string) (string, error) {
+	f, err := os.Open(path) //nolint:gosec // path is within the mounted image
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var section string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line
+			continue
+		}
+		if section != "[Image]" && section != "[Container]" {
+			continue
+		}
+		if key, value, found := strings.Cut(line, "="); found && strings.TrimSpace(key) == "Image" {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	return "", scanner.Err()
+}
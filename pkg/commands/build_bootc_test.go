@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_validateBootcParams(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(validateBootcParams(&BuildParams{})).To(Succeed())
+	g.Expect(validateBootcParams(&BuildParams{Format: "oci"})).To(Succeed())
+	g.Expect(validateBootcParams(&BuildParams{Format: "docker"})).To(MatchError(ContainSubstring("must use the oci format")))
+	g.Expect(validateBootcParams(&BuildParams{Squash: true})).To(MatchError(ContainSubstring("squash is not supported")))
+}
+
+func Test_Build_setBootcDefaults(t *testing.T) {
+	g := NewWithT(t)
+
+	c := &Build{Params: &BuildParams{Bootc: true}}
+	c.setBootcDefaults()
+	g.Expect(c.Params.Format).To(Equal("oci"))
+}
+
+func Test_Build_addBootcLabels(t *testing.T) {
+	g := NewWithT(t)
+
+	c := &Build{Params: &BuildParams{InheritLabels: true}, mergedLabels: []string{"a=b"}}
+	c.addBootcLabels()
+	g.Expect(c.mergedLabels).To(Equal([]string{"a=b"}))
+
+	c = &Build{Params: &BuildParams{InheritLabels: false}, mergedLabels: []string{"a=b"}}
+	c.addBootcLabels()
+	g.Expect(c.mergedLabels).To(Equal([]string{"containers.bootc=1", "ostree.bootable=1", "a=b"}))
+}
+
+func Test_Build_verifyBootcBaseImage(t *testing.T) {
+	newBuild := func(baseImageLabels map[string]string) *Build {
+		mock := &mockBuildahCli{
+			InspectImageFunc: func(name string) (cliwrappers.BuildahImageInfo, error) {
+				info := cliwrappers.BuildahImageInfo{}
+				info.OCIv1.Config.Labels = baseImageLabels
+				return info, nil
+			},
+		}
+		return &Build{CliWrappers: BuildCliWrappers{BuildahCli: mock}, Params: &BuildParams{Bootc: true}}
+	}
+
+	t.Run("should accept bootc base image", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newBuild(map[string]string{"containers.bootc": "1"})
+		df := parseDockerfile(t, g, "FROM quay.io/fedora/fedora-bootc:42\nRUN dnf install -y httpd")
+
+		g.Expect(c.verifyBootcBaseImage(df)).To(Succeed())
+	})
+
+	t.Run("should accept bootc base image through intermediate stage", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newBuild(map[string]string{"ostree.bootable": "true"})
+		df := parseDockerfile(t, g, "FROM quay.io/fedora/fedora-bootc:42 AS base\nFROM base")
+
+		g.Expect(c.verifyBootcBaseImage(df)).To(Succeed())
+	})
+
+	t.Run("should accept scratch image with bootc label", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newBuild(nil)
+		df := parseDockerfile(t, g, "FROM scratch\nLABEL containers.bootc=1")
+
+		g.Expect(c.verifyBootcBaseImage(df)).To(Succeed())
+	})
+
+	t.Run("should reject non-bootc base image", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newBuild(map[string]string{"name": "ubi9"})
+		df := parseDockerfile(t, g, "FROM registry.access.redhat.com/ubi9")
+
+		err := c.verifyBootcBaseImage(df)
+		g.Expect(err).To(MatchError(ContainSubstring("registry.access.redhat.com/ubi9 is not a bootc base image")))
+	})
+
+	t.Run("should reject scratch image without bootc label", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newBuild(nil)
+		df := parseDockerfile(t, g, "FROM scratch")
+
+		err := c.verifyBootcBaseImage(df)
+		g.Expect(err).To(MatchError(ContainSubstring("doesn't set the containers.bootc=1 label")))
+	})
+}
+
+func Test_readBootcBoundImages(t *testing.T) {
+	writeFile := func(g Gomega, path string, content string) {
+		g.Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		g.Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+
+	t.Run("should return no images without bound-images.d", func(t *testing.T) {
+		g := NewWithT(t)
+
+		images, err := readBootcBoundImages(t.TempDir())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(images).To(BeEmpty())
+	})
+
+	t.Run("should read images from linked quadlet files", func(t *testing.T) {
+		g := NewWithT(t)
+		rootfs := t.TempDir()
+		quadletDir := filepath.Join(rootfs, "usr/share/containers/systemd")
+		writeFile(g, filepath.Join(quadletDir, "db.container"),
+			"[Unit]\nDescription=db\n\n[Container]\nImage=quay.io/org/db@sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c\n")
+		writeFile(g, filepath.Join(quadletDir, "proxy.image"), "[Image]\nImage = quay.io/org/proxy:v1\n")
+
+		boundImagesDir := filepath.Join(rootfs, bootcBoundImagesDir)
+		g.Expect(os.MkdirAll(boundImagesDir, 0755)).To(Succeed())
+		// Absolute links are relative to the image root
+		g.Expect(os.Symlink("/usr/share/containers/systemd/db.container", filepath.Join(boundImagesDir, "db.container"))).To(Succeed())
+		g.Expect(os.Symlink("../../../share/containers/systemd/proxy.image", filepath.Join(boundImagesDir, "proxy.image"))).To(Succeed())
+		writeFile(g, filepath.Join(boundImagesDir, "README"), "not a quadlet")
+
+		images, err := readBootcBoundImages(rootfs)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(images).To(Equal([]string{
+			"quay.io/org/db@sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c",
+			"quay.io/org/proxy:v1",
+		}))
+	})
+
+	t.Run("should fail on quadlet file without image", func(t *testing.T) {
+		g := NewWithT(t)
+		rootfs := t.TempDir()
+		writeFile(g, filepath.Join(rootfs, bootcBoundImagesDir, "app.container"), "[Container]\nExec=sleep\n")
+
+		_, err := readBootcBoundImages(rootfs)
+		g.Expect(err).To(MatchError(ContainSubstring("bound image app.container doesn't set Image=")))
+	})
+}
+
+func Test_Build_findBootcBoundImages(t *testing.T) {
+	g := NewWithT(t)
+
+	rootfs := t.TempDir()
+	g.Expect(os.MkdirAll(filepath.Join(rootfs, bootcBoundImagesDir), 0755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(rootfs, bootcBoundImagesDir, "app.image"), []byte("[Image]\nImage=quay.io/org/app:v1\n"), 0644)).To(Succeed())
+
+	removedContainer := ""
+	mock := &mockBuildahCli{
+		FromFunc: func(image string) (string, error) {
+			g.Expect(image).To(Equal("quay.io/org/os:latest"))
+			return "os-working-container", nil
+		},
+		MountFunc: func(container string) (string, error) {
+			return rootfs, nil
+		},
+		RmFunc: func(container string) error {
+			removedContainer = container
+			return nil
+		},
+	}
+	c := &Build{CliWrappers: BuildCliWrappers{BuildahCli: mock}, Params: &BuildParams{OutputRef: "quay.io/org/os:latest", Bootc: true}}
+
+	images, err := c.findBootcBoundImages()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(images).To(Equal([]string{"quay.io/org/app:v1"}))
+	g.Expect(removedContainer).To(Equal("os-working-container"))
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// buildCheckpoint records the phases of a build that completed, so that a re-run with --resume
+// (e.g. after the TaskRun got cancelled or the pod evicted) can skip them.
+//
+// Pushed blobs don't need to be tracked: buildah skips the blobs that the registry already has,
+// so re-pushing an image only uploads what a previous push didn't finish.
+type buildCheckpoint struct {
+	// Identifies the build inputs, a checkpoint of a different build is never resumed
+	InputsDigest string `json:"inputs_digest"`
+	OutputRef    string `json:"output_ref"`
+
+	// Set once the image is built
+	ImageId string `json:"image_id,omitempty"`
+	// Set once the image is pushed to OutputRef
+	Digest string `json:"digest,omitempty"`
+	// Additional tags pushed so far
+	PushedTags []string `json:"pushed_tags,omitempty"`
+}
+
+// Digest of everything that affects the built image: the Containerfile and the parameters.
+func (c *Build) buildInputsDigest() (string, error) {
+	containerfile, err := os.ReadFile(c.containerfilePath)
+	if err != nil {
+		return "", err
+	}
+	params := *c.Params
+	// Not inputs of the build itself
+	params.Resume = false
+	params.CheckpointFile = ""
+	paramsJson, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write(containerfile)
+	h.Write([]byte{0})
+	h.Write(paramsJson)
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
+
+// Start a new checkpoint, or continue the existing one if resuming. Must be called after
+// detectContainerfile. No-op without --checkpoint-file.
+func (c *Build) initCheckpoint() error {
+	if c.Params.CheckpointFile == "" {
+		return nil
+	}
+	inputsDigest, err := c.buildInputsDigest()
+	if err != nil {
+		return fmt.Errorf("computing build inputs digest: %w", err)
+	}
+
+	if c.Params.Resume {
+		previous, err := loadBuildCheckpoint(c.Params.CheckpointFile)
+		switch {
+		case os.IsNotExist(err):
+			l.Logger.Infof("No checkpoint found at %s, building from scratch", c.Params.CheckpointFile)
+		case err != nil:
+			l.Logger.Warnf("Ignoring unreadable checkpoint, building from scratch: %s", err)
+		case previous.InputsDigest != inputsDigest || previous.OutputRef != c.Params.OutputRef:
+			l.Logger.Warnf("Ignoring checkpoint of a different build, building from scratch")
+		case previous.ImageId != "" && !c.imageExists(previous.ImageId):
+			l.Logger.Warnf("Image %s from the checkpoint is no longer in local storage, building from scratch", previous.ImageId)
+		default:
+			c.checkpoint = previous
+			return nil
+		}
+	}
+
+	c.checkpoint = &buildCheckpoint{InputsDigest: inputsDigest, OutputRef: c.Params.OutputRef}
+	return c.saveCheckpoint()
+}
+
+func (c *Build) imageExists(imageId string) bool {
+	images, err := c.CliWrappers.BuildahCli.ImagesJson(&cliWrappers.BuildahImagesArgs{Image: c.Params.OutputRef})
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(images, func(image cliWrappers.BuildahImagesEntry) bool {
+		return image.ID == imageId
+	})
+}
+
+// Record the built image in the checkpoint.
+func (c *Build) checkpointBuilt() error {
+	if c.checkpoint == nil {
+		return nil
+	}
+	images, err := c.CliWrappers.BuildahCli.ImagesJson(&cliWrappers.BuildahImagesArgs{Image: c.Params.OutputRef})
+	if err != nil {
+		return fmt.Errorf("getting ID of the built image: %w", err)
+	}
+	if len(images) == 0 || images[0].ID == "" {
+		return fmt.Errorf("getting ID of the built image: %s not found in local storage", c.Params.OutputRef)
+	}
+	c.checkpoint.ImageId = images[0].ID
+	return c.saveCheckpoint()
+}
+
+func (c *Build) resumeBuilt() bool {
+	return c.checkpoint != nil && c.checkpoint.ImageId != ""
+}
+
+func (c *Build) saveCheckpoint() error {
+	data, err := json.MarshalIndent(c.checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first, a checkpoint must never be left half-written
+	tmpFile := c.Params.CheckpointFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	if err := os.Rename(tmpFile, c.Params.CheckpointFile); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	l.Logger.Debugf("Checkpoint saved to %s", filepath.Clean(c.Params.CheckpointFile))
+	return nil
+}
+
+func loadBuildCheckpoint(path string) (*buildCheckpoint, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // checkpoint path from controlled input
+	if err != nil {
+		return nil, err
+	}
+	var checkpoint buildCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint %s: %w", path, err)
+	}
+	return &checkpoint, nil
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Build_checkpoint(t *testing.T) {
+	const imageId = "0123456789abcdef"
+	const digest = "sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c"
+
+	var pushedImages []string
+	var storedImageId string
+	newBuild := func(t *testing.T, g Gomega, checkpointFile string, resume bool) *Build {
+		containerfile := filepath.Join(t.TempDir(), "Containerfile")
+		g.Expect(os.WriteFile(containerfile, []byte("FROM scratch\n"), 0644)).To(Succeed())
+
+		mock := &mockBuildahCli{
+			ImagesJsonFunc: func(args *cliwrappers.BuildahImagesArgs) ([]cliwrappers.BuildahImagesEntry, error) {
+				if storedImageId == "" {
+					return nil, nil
+				}
+				return []cliwrappers.BuildahImagesEntry{{ID: storedImageId}}, nil
+			},
+			PushFunc: func(args *cliwrappers.BuildahPushArgs) (string, error) {
+				pushedImages = append(pushedImages, args.Image)
+				return digest, nil
+			},
+		}
+		return &Build{
+			CliWrappers: BuildCliWrappers{BuildahCli: mock},
+			Params: &BuildParams{
+				OutputRef:      "quay.io/org/image:v1",
+				AdditionalTags: []string{"latest", "stable"},
+				CheckpointFile: checkpointFile,
+				Resume:         resume,
+			},
+			containerfilePath: containerfile,
+		}
+	}
+	beforeEach := func() {
+		pushedImages = nil
+		storedImageId = imageId
+	}
+
+	t.Run("should record built image and pushed tags", func(t *testing.T) {
+		beforeEach()
+		g := NewWithT(t)
+		checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+		c := newBuild(t, g, checkpointFile, false)
+
+		g.Expect(c.initCheckpoint()).To(Succeed())
+		g.Expect(c.resumeBuilt()).To(BeFalse())
+		g.Expect(c.checkpointBuilt()).To(Succeed())
+		_, err := c.pushImage()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		checkpoint, err := loadBuildCheckpoint(checkpointFile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(checkpoint.InputsDigest).To(HavePrefix("sha256:"))
+		g.Expect(checkpoint.ImageId).To(Equal(imageId))
+		g.Expect(checkpoint.Digest).To(Equal(digest))
+		g.Expect(checkpoint.PushedTags).To(Equal([]string{"latest", "stable"}))
+	})
+
+	t.Run("should skip completed phases when resuming", func(t *testing.T) {
+		beforeEach()
+		g := NewWithT(t)
+		checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+		c := newBuild(t, g, checkpointFile, false)
+		g.Expect(c.initCheckpoint()).To(Succeed())
+		g.Expect(c.checkpointBuilt()).To(Succeed())
+		c.checkpoint.Digest = digest
+		c.checkpoint.PushedTags = []string{"latest"}
+		g.Expect(c.saveCheckpoint()).To(Succeed())
+
+		resumed := newBuild(t, g, checkpointFile, true)
+		resumed.containerfilePath = c.containerfilePath
+		g.Expect(resumed.initCheckpoint()).To(Succeed())
+		g.Expect(resumed.resumeBuilt()).To(BeTrue())
+
+		pushedDigest, err := resumed.pushImage()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(pushedDigest).To(Equal(digest))
+		g.Expect(pushedImages).To(Equal([]string{"quay.io/org/image:stable"}))
+	})
+
+	t.Run("should ignore checkpoint of different inputs", func(t *testing.T) {
+		beforeEach()
+		g := NewWithT(t)
+		checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+		c := newBuild(t, g, checkpointFile, false)
+		g.Expect(c.initCheckpoint()).To(Succeed())
+		g.Expect(c.checkpointBuilt()).To(Succeed())
+
+		resumed := newBuild(t, g, checkpointFile, true)
+		resumed.containerfilePath = c.containerfilePath
+		resumed.Params.BuildArgs = []string{"VERSION=2"}
+		g.Expect(resumed.initCheckpoint()).To(Succeed())
+		g.Expect(resumed.resumeBuilt()).To(BeFalse())
+
+		checkpoint, err := loadBuildCheckpoint(checkpointFile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(checkpoint.ImageId).To(BeEmpty())
+	})
+
+	t.Run("should ignore checkpoint if the image is gone", func(t *testing.T) {
+		beforeEach()
+		g := NewWithT(t)
+		checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+		c := newBuild(t, g, checkpointFile, false)
+		g.Expect(c.initCheckpoint()).To(Succeed())
+		g.Expect(c.checkpointBuilt()).To(Succeed())
+
+		storedImageId = ""
+		resumed := newBuild(t, g, checkpointFile, true)
+		resumed.containerfilePath = c.containerfilePath
+		g.Expect(resumed.initCheckpoint()).To(Succeed())
+		g.Expect(resumed.resumeBuilt()).To(BeFalse())
+	})
+
+	t.Run("should build from scratch without checkpoint file", func(t *testing.T) {
+		beforeEach()
+		g := NewWithT(t)
+		c := newBuild(t, g, filepath.Join(t.TempDir(), "missing.json"), true)
+
+		g.Expect(c.initCheckpoint()).To(Succeed())
+		g.Expect(c.resumeBuilt()).To(BeFalse())
+	})
+
+	t.Run("should do nothing without checkpoint-file", func(t *testing.T) {
+		beforeEach()
+		g := NewWithT(t)
+		c := newBuild(t, g, "", false)
+
+		g.Expect(c.initCheckpoint()).To(Succeed())
+		g.Expect(c.checkpoint).To(BeNil())
+		g.Expect(c.checkpointBuilt()).To(Succeed())
+	})
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// For --containerignore-from-gitignore, generate an ignore file from the .gitignore files
+// of the context into the temporary workdir, unless the context has an ignore file of its own.
+func (c *Build) generateContainerignore() error {
+	contextDir := c.buildContextDir()
+	ignoreFile, err := findContextIgnoreFile(contextDir, c.containerfilePath)
+	if err != nil {
+		return err
+	}
+	if ignoreFile != "" {
+		l.Logger.Infof("Not generating an ignore file from .gitignore, the context has %s", ignoreFile)
+		return nil
+	}
+
+	patterns, err := containerignoreFromGitignore(contextDir)
+	if err != nil {
+		return err
+	}
+
+	if err := c.ensureTempWorkdirExists(); err != nil {
+		return err
+	}
+	generatedIgnoreFile := filepath.Join(c.tempWorkdir, "containerignore")
+	content := "# Generated from the .gitignore files of the context\n" + strings.Join(patterns, "\n") + "\n"
+	if err := os.WriteFile(generatedIgnoreFile, []byte(content), 0644); err != nil { //nolint:gosec // G306: the ignore file is not secret
+		return err
+	}
+	l.Logger.Infof("Generated ignore file %s with %d patterns from .gitignore", generatedIgnoreFile, len(patterns))
+	c.generatedIgnoreFile = generatedIgnoreFile
+	return nil
+}
+
+// Convert the .gitignore files of the context directory to containerignore patterns. The .git
+// directory is always excluded. The patterns of a .gitignore file in a subdirectory follow the ones
+// of its parent directories, so that they take precedence like in git.
+func containerignoreFromGitignore(contextDir string) ([]string, error) {
+	patterns := []string{"**/.git"}
+	err := filepath.WalkDir(contextDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != ".gitignore" || !d.Type().IsRegular() {
+			return nil
+		}
+
+		relDir, err := filepath.Rel(contextDir, filepath.Dir(filePath))
+		if err != nil {
+			return err
+		}
+		gitignorePatterns, err := readGitignore(filePath, filepath.ToSlash(relDir))
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", filePath, err)
+		}
+		patterns = append(patterns, gitignorePatterns...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
+
+// Read the patterns of a .gitignore file in the dir directory (relative to the context directory)
+// as containerignore patterns.
+func readGitignore(gitignorePath, dir string) ([]string, error) {
+	f, err := os.Open(gitignorePath) //nolint:gosec // .gitignore of the build context
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if pattern := gitignoreToContainerignore(scanner.Text(), dir); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns, scanner.Err()
+}
+
+// Convert a line of a .gitignore file in the dir directory to a containerignore pattern,
+// empty for blank lines and comments. Containerignore patterns are always relative to the context
+// directory, while a .gitignore pattern without a '/' (other than a trailing one) matches at any level
+// below the .gitignore. Containerignore has no directory-only patterns, a trailing '/' is dropped.
+func gitignoreToContainerignore(line, dir string) string {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ""
+	}
+
+	negate := strings.HasPrefix(line, "!")
+	if negate {
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+		line = line[1:]
+	}
+
+	pattern := strings.TrimSuffix(line, "/")
+	if pattern == "" {
+		return ""
+	}
+	if strings.Contains(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+	} else {
+		pattern = "**/" + pattern
+	}
+	if dir != "." {
+		pattern = path.Join(dir, pattern)
+	}
+
+	if negate {
+		return "!" + pattern
+	}
+	if strings.HasPrefix(pattern, "#") || strings.HasPrefix(pattern, "!") {
+		// Not a comment or an exclusion, the path is cleaned when the ignore file is read
+		return "./" + pattern
+	}
+	return pattern
+}
@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/testutil"
+)
+
+func Test_gitignoreToContainerignore(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		line string
+		dir  string
+		want string
+	}{
+		{"", ".", ""},
+		{"# comment", ".", ""},
+		{"*.log", ".", "**/*.log"},
+		{"node_modules/", ".", "**/node_modules"},
+		{"/build", ".", "build"},
+		{"docs/*.md", ".", "docs/*.md"},
+		{"!important.log", ".", "!**/important.log"},
+		{"*.tmp  ", ".", "**/*.tmp"},
+		{`\#notes`, ".", "**/#notes"},
+		{`\#notes/a`, ".", "./#notes/a"},
+		{"*.o", "src", "src/**/*.o"},
+		{"/out/", "src", "src/out"},
+		{"!keep.o", "src", "!src/**/keep.o"},
+	}
+	for _, tc := range tests {
+		g.Expect(gitignoreToContainerignore(tc.line, tc.dir)).To(Equal(tc.want), "line %q in %s", tc.line, tc.dir)
+	}
+}
+
+func Test_generateContainerignore(t *testing.T) {
+	t.Run("should exclude .git and the files ignored by git", func(t *testing.T) {
+		g := NewWithT(t)
+		contextDir := t.TempDir()
+		testutil.WriteFileTree(t, contextDir, map[string]string{
+			"Containerfile":      "FROM scratch\n",
+			".gitignore":         "*.log\n/dist/\n",
+			".git/HEAD":          "ref: refs/heads/main\n",
+			"main.go":            "package main\n",
+			"debug.log":          "x",
+			"dist/app":           "x",
+			"src/.gitignore":     "!keep.log\ngenerated/\n",
+			"src/lib.go":         "package lib\n",
+			"src/keep.log":       "x",
+			"src/generated/a.go": "package generated\n",
+			"src/sub/trace.log":  "x",
+		})
+		c := &Build{
+			Params:            &BuildParams{Context: contextDir},
+			containerfilePath: filepath.Join(contextDir, "Containerfile"),
+		}
+		defer c.cleanup()
+
+		g.Expect(c.generateContainerignore()).To(Succeed())
+		g.Expect(c.generatedIgnoreFile).To(HavePrefix(c.tempWorkdir))
+		g.Expect(c.buildahBuildArgs().IgnoreFile).To(Equal(c.generatedIgnoreFile))
+
+		contextSize, err := scanBuildContext(contextDir, c.generatedIgnoreFile, 100)
+		g.Expect(err).ToNot(HaveOccurred())
+		var files []string
+		for _, file := range contextSize.LargestFiles {
+			files = append(files, file.Path)
+		}
+		g.Expect(files).To(ConsistOf("Containerfile", ".gitignore", "main.go", "src/.gitignore", "src/lib.go", "src/keep.log"))
+	})
+
+	t.Run("should not replace the ignore file of the context", func(t *testing.T) {
+		g := NewWithT(t)
+		contextDir := t.TempDir()
+		testutil.WriteFileTree(t, contextDir, map[string]string{
+			"Containerfile": "FROM scratch\n",
+			".gitignore":    "*.log\n",
+			".dockerignore": "*.md\n",
+		})
+		c := &Build{
+			Params:            &BuildParams{Context: contextDir},
+			containerfilePath: filepath.Join(contextDir, "Containerfile"),
+		}
+		defer c.cleanup()
+
+		g.Expect(c.generateContainerignore()).To(Succeed())
+		g.Expect(c.generatedIgnoreFile).To(BeEmpty())
+		g.Expect(c.buildahBuildArgs().IgnoreFile).To(BeEmpty())
+	})
+
+	t.Run("should exclude .git without .gitignore", func(t *testing.T) {
+		g := NewWithT(t)
+		contextDir := t.TempDir()
+		testutil.WriteFileTree(t, contextDir, map[string]string{
+			"Containerfile": "FROM scratch\n",
+			".git/HEAD":     "ref: refs/heads/main\n",
+		})
+		c := &Build{
+			Params:            &BuildParams{Context: contextDir},
+			containerfilePath: filepath.Join(contextDir, "Containerfile"),
+		}
+		defer c.cleanup()
+
+		g.Expect(c.generateContainerignore()).To(Succeed())
+		content, err := os.ReadFile(c.generatedIgnoreFile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(content)).To(ContainSubstring("**/.git\n"))
+	})
+}
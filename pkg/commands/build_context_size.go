@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/docker/go-units"
+	"github.com/moby/patternmatcher"
+	"github.com/moby/patternmatcher/ignorefile"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+type BuildContextSize struct {
+	// The ignore file applied to the context, relative to the context directory if it's inside it
+	IgnoreFile string `json:"ignore_file,omitempty"`
+	// Total size of the files not excluded by the ignore file
+	SizeBytes int64 `json:"size_bytes"`
+	Files     int   `json:"files"`
+	// The --context-report-top-files largest files, largest first
+	LargestFiles []BuildContextFile `json:"largest_files,omitempty"`
+}
+
+type BuildContextFile struct {
+	Path      string `json:"path"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// Find the ignore file buildah applies to the context: the one next to the Containerfile
+// (e.g. Containerfile.containerignore) first, then .containerignore and .dockerignore of the context.
+func findContextIgnoreFile(contextDir, containerfilePath string) (string, error) {
+	candidates := []string{}
+	if containerfilePath != "" {
+		candidates = append(candidates, containerfilePath+".containerignore", containerfilePath+".dockerignore")
+	}
+	candidates = append(candidates,
+		filepath.Join(contextDir, ".containerignore"),
+		filepath.Join(contextDir, ".dockerignore"),
+	)
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// Walk the context directory the way buildah sends it, i.e. without the files excluded by the ignore file,
+// and sum up the size of the files. Symlinks count by their own size, they are not followed.
+func scanBuildContext(contextDir, ignoreFile string, topFiles int) (*BuildContextSize, error) {
+	result := &BuildContextSize{}
+
+	var matcher *patternmatcher.PatternMatcher
+	if ignoreFile != "" {
+		f, err := os.Open(ignoreFile) //nolint:gosec // ignore file of the build context
+		if err != nil {
+			return nil, err
+		}
+		patterns, err := ignorefile.ReadAll(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", ignoreFile, err)
+		}
+		matcher, err = patternmatcher.New(patterns)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", ignoreFile, err)
+		}
+
+		result.IgnoreFile = ignoreFile
+		if relPath, err := filepath.Rel(contextDir, ignoreFile); err == nil && filepath.IsLocal(relPath) {
+			result.IgnoreFile = relPath
+		}
+	}
+
+	files := []BuildContextFile{}
+	dirMatches := map[string]patternmatcher.MatchInfo{}
+	err := filepath.WalkDir(contextDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(contextDir, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		if matcher != nil {
+			excluded, matchInfo, err := matcher.MatchesUsingParentResults(relPath, dirMatches[filepath.Dir(relPath)])
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				// Files of an excluded directory may still be re-included by a !pattern
+				if excluded && !matcher.Exclusions() {
+					return filepath.SkipDir
+				}
+				dirMatches[relPath] = matchInfo
+			}
+			if excluded {
+				return nil
+			}
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		result.SizeBytes += info.Size()
+		result.Files++
+		if topFiles > 0 {
+			files = append(files, BuildContextFile{Path: relPath, SizeBytes: info.Size()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(files, func(a, b BuildContextFile) int {
+		return cmp.Compare(b.SizeBytes, a.SizeBytes)
+	})
+	if len(files) > topFiles {
+		files = files[:topFiles]
+	}
+	if len(files) > 0 {
+		result.LargestFiles = files
+	}
+	return result, nil
+}
+
+// Scan the context directory passed to buildah, report its size
+// and fail if it exceeds --max-context-size.
+func (c *Build) checkContextSize() error {
+	contextDir := c.buildContextDir()
+	ignoreFile := c.generatedIgnoreFile
+	if ignoreFile == "" {
+		var err error
+		if ignoreFile, err = findContextIgnoreFile(contextDir, c.containerfilePath); err != nil {
+			return err
+		}
+	}
+
+	contextSize, err := scanBuildContext(contextDir, ignoreFile, c.Params.ContextReportTopFiles)
+	if err != nil {
+		return fmt.Errorf("scanning context directory %s: %w", contextDir, err)
+	}
+	c.Results.ContextSize = contextSize
+
+	if ignoreFile != "" {
+		l.Logger.Infof("Applying ignore file %s to the context", ignoreFile)
+	}
+	l.Logger.Infof("Context size: %s in %d files", units.BytesSize(float64(contextSize.SizeBytes)), contextSize.Files)
+	for _, file := range contextSize.LargestFiles {
+		l.Logger.Infof("  %s %s", units.BytesSize(float64(file.SizeBytes)), file.Path)
+	}
+
+	if c.maxContextSize > 0 && contextSize.SizeBytes > c.maxContextSize {
+		return fmt.Errorf("context size %s exceeds --max-context-size %s, exclude the unneeded files in the ignore file",
+			units.BytesSize(float64(contextSize.SizeBytes)), units.BytesSize(float64(c.maxContextSize)))
+	}
+	return nil
+}
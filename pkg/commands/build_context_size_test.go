@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/testutil"
+)
+
+func Test_findContextIgnoreFile(t *testing.T) {
+	t.Run("should prefer the ignore file of the Containerfile", func(t *testing.T) {
+		g := NewWithT(t)
+		contextDir := t.TempDir()
+		testutil.WriteFileTree(t, contextDir, map[string]string{
+			"build/Containerfile":                 "FROM scratch",
+			"build/Containerfile.containerignore": "*.md",
+			".containerignore":                    "*.txt",
+		})
+
+		ignoreFile, err := findContextIgnoreFile(contextDir, filepath.Join(contextDir, "build/Containerfile"))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ignoreFile).To(Equal(filepath.Join(contextDir, "build/Containerfile.containerignore")))
+	})
+
+	t.Run("should prefer .containerignore over .dockerignore", func(t *testing.T) {
+		g := NewWithT(t)
+		contextDir := t.TempDir()
+		testutil.WriteFileTree(t, contextDir, map[string]string{
+			"Containerfile":    "FROM scratch",
+			".containerignore": "*.txt",
+			".dockerignore":    "*.md",
+		})
+
+		ignoreFile, err := findContextIgnoreFile(contextDir, filepath.Join(contextDir, "Containerfile"))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ignoreFile).To(Equal(filepath.Join(contextDir, ".containerignore")))
+	})
+
+	t.Run("should return empty without ignore file", func(t *testing.T) {
+		g := NewWithT(t)
+		ignoreFile, err := findContextIgnoreFile(t.TempDir(), "")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ignoreFile).To(BeEmpty())
+	})
+}
+
+func Test_scanBuildContext(t *testing.T) {
+	dockerignore := "node_modules\n*.log\n!important.log\ndocs/**\n"
+	contextDir := t.TempDir()
+	testutil.WriteFileTree(t, contextDir, map[string]string{
+		".dockerignore":         dockerignore,
+		"Containerfile":         "FROM scratch\n",
+		"main.go":               strings.Repeat("x", 100),
+		"debug.log":             strings.Repeat("x", 1000),
+		"important.log":         strings.Repeat("x", 50),
+		"node_modules/a/big.js": strings.Repeat("x", 5000),
+		"docs/guide.md":         strings.Repeat("x", 2000),
+		"src/data.bin":          strings.Repeat("x", 300),
+	})
+	ignoreFile := filepath.Join(contextDir, ".dockerignore")
+
+	t.Run("should exclude the ignored files and report the largest ones", func(t *testing.T) {
+		g := NewWithT(t)
+		contextSize, err := scanBuildContext(contextDir, ignoreFile, 2)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(contextSize.IgnoreFile).To(Equal(".dockerignore"))
+		g.Expect(contextSize.Files).To(Equal(5))
+		g.Expect(contextSize.SizeBytes).To(Equal(int64(len(dockerignore) + len("FROM scratch\n") + 100 + 50 + 300)))
+		g.Expect(contextSize.LargestFiles).To(Equal([]BuildContextFile{
+			{Path: "src/data.bin", SizeBytes: 300},
+			{Path: "main.go", SizeBytes: 100},
+		}))
+	})
+
+	t.Run("should count all files without ignore file", func(t *testing.T) {
+		g := NewWithT(t)
+		contextSize, err := scanBuildContext(contextDir, "", 0)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(contextSize.Files).To(Equal(8))
+		g.Expect(contextSize.LargestFiles).To(BeNil())
+	})
+}
+
+func Test_Build_checkContextSize(t *testing.T) {
+	newBuild := func(contextDir string, maxContextSize int64) *Build {
+		return &Build{
+			Params:            &BuildParams{Context: contextDir, ContextReportTopFiles: 10},
+			containerfilePath: filepath.Join(contextDir, "Containerfile"),
+			maxContextSize:    maxContextSize,
+		}
+	}
+
+	t.Run("should report the context size in the results", func(t *testing.T) {
+		g := NewWithT(t)
+		contextDir := t.TempDir()
+		testutil.WriteFileTree(t, contextDir, map[string]string{
+			"Containerfile": "FROM scratch\n",
+			"app.bin":       strings.Repeat("x", 1024),
+		})
+		c := newBuild(contextDir, 2048)
+
+		g.Expect(c.checkContextSize()).To(Succeed())
+		g.Expect(c.Results.ContextSize.SizeBytes).To(Equal(int64(1024 + len("FROM scratch\n"))))
+		g.Expect(c.Results.ContextSize.LargestFiles[0].Path).To(Equal("app.bin"))
+	})
+
+	t.Run("should fail when the context exceeds max-context-size", func(t *testing.T) {
+		g := NewWithT(t)
+		contextDir := t.TempDir()
+		testutil.WriteFileTree(t, contextDir, map[string]string{
+			"Containerfile": "FROM scratch\n",
+			"app.bin":       strings.Repeat("x", 4096),
+		})
+		c := newBuild(contextDir, 1024)
+
+		g.Expect(c.checkContextSize()).To(MatchError(ContainSubstring("exceeds --max-context-size 1KiB")))
+	})
+
+	t.Run("should not count the files excluded by the ignore file", func(t *testing.T) {
+		g := NewWithT(t)
+		contextDir := t.TempDir()
+		testutil.WriteFileTree(t, contextDir, map[string]string{
+			"Containerfile":    "FROM scratch\n",
+			".containerignore": "*.bin\n",
+			"app.bin":          strings.Repeat("x", 4096),
+		})
+		c := newBuild(contextDir, 1024)
+
+		g.Expect(c.checkContextSize()).To(Succeed())
+		g.Expect(c.Results.ContextSize.IgnoreFile).To(Equal(".containerignore"))
+		g.Expect(c.Results.ContextSize.Files).To(Equal(2))
+	})
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+// Values of --engine
+const (
+	engineBuildah = "buildah"
+	enginePodman  = "podman"
+	engineDocker  = "docker"
+)
+
+func (c *Build) usesBuildah() bool {
+	return c.Params.Engine == "" || c.Params.Engine == engineBuildah
+}
+
+// The engine building, pulling, inspecting and pushing the image.
+func (c *Build) buildEngine() cliWrappers.BuildEngine {
+	if c.CliWrappers.BuildEngine == nil {
+		return c.CliWrappers.BuildahCli
+	}
+	return c.CliWrappers.BuildEngine
+}
+
+func (c *Build) buildBackend() buildBackend {
+	switch c.Params.Engine {
+	case enginePodman:
+		return podmanBackend
+	case engineDocker:
+		return dockerBackend
+	default:
+		return buildahBackend
+	}
+}
+
+func newBuildEngine(engine string, executor cliWrappers.CliExecutorInterface) (cliWrappers.BuildEngine, error) {
+	switch engine {
+	case enginePodman:
+		return cliWrappers.NewPodmanCli(executor)
+	case engineDocker:
+		return cliWrappers.NewDockerCli(executor)
+	default:
+		return nil, fmt.Errorf("engine must be '%s', '%s' or '%s', got '%s'", engineBuildah, enginePodman, engineDocker, engine)
+	}
+}
+
+// Validate the --engine param, after the platforms are parsed. The engines other than buildah
+// only build, pull, inspect and push images, the options needing more of buildah are rejected.
+func (c *Build) validateEngineParams() error {
+	switch c.Params.Engine {
+	case "", engineBuildah:
+		return nil
+	case enginePodman, engineDocker:
+	default:
+		return fmt.Errorf("engine must be '%s', '%s' or '%s', got '%s'", engineBuildah, enginePodman, engineDocker, c.Params.Engine)
+	}
+
+	unsupported := []struct {
+		name string
+		set  bool
+	}{
+		{"platform with several platforms", c.isMultiPlatform()},
+		{"hermetic", c.Params.Hermetic},
+		{"bootc", c.Params.Bootc},
+		{"builder-metadata-output", c.Params.BuilderMetadataOutput != ""},
+		{"resolved-base-images-output", c.Params.ResolvedBaseImagesOutput != ""},
+		{"syft-image-output", c.Params.SyftImageOutput != ""},
+		{"sbom-output", c.Params.SBOMOutput != ""},
+		{"checkpoint-file", c.Params.CheckpointFile != ""},
+		{"storage-dir", c.Params.StorageDir != ""},
+		{"cache-prune-older-than", c.Params.CachePruneOlderThan != ""},
+		{"remote-host", c.Params.RemoteHost != ""},
+	}
+	if c.Params.Engine == engineDocker {
+		// The docker daemon has its own registry configuration and image store
+		unsupported = append(unsupported, []struct {
+			name string
+			set  bool
+		}{
+			{"output", c.Params.Output != ""},
+			{"cert-dir", c.Params.CertDir != ""},
+			{"ca-bundle-file", c.Params.CABundleFile != ""},
+			{"containerignore-from-gitignore", c.Params.IgnoreFromGitignore},
+		}...)
+	}
+	for _, param := range unsupported {
+		if param.set {
+			return fmt.Errorf("%s is not supported with engine %s", param.name, c.Params.Engine)
+		}
+	}
+	return nil
+}
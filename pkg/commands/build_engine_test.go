@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_Build_validateEngineParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      BuildParams
+		platforms   []string
+		errExpected string
+	}{
+		{
+			name:   "should accept default engine",
+			params: BuildParams{Hermetic: true, StorageDir: "/var/lib/containers"},
+		},
+		{
+			name:   "should accept buildah with all options",
+			params: BuildParams{Engine: "buildah", Bootc: true, CheckpointFile: "checkpoint.json"},
+		},
+		{
+			name:   "should accept podman",
+			params: BuildParams{Engine: "podman", Output: "oci:/tmp/layout", CertDir: "/certs"},
+		},
+		{
+			name:   "should accept docker",
+			params: BuildParams{Engine: "docker", Push: true, SyftSourceOutput: "sbom.json"},
+		},
+		{
+			name:        "should reject unknown engine",
+			params:      BuildParams{Engine: "kaniko"},
+			errExpected: "engine must be 'buildah', 'podman' or 'docker', got 'kaniko'",
+		},
+		{
+			name:        "should reject several platforms",
+			params:      BuildParams{Engine: "podman"},
+			platforms:   []string{"linux/amd64", "linux/arm64"},
+			errExpected: "platform with several platforms is not supported with engine podman",
+		},
+		{
+			name:        "should reject hermetic",
+			params:      BuildParams{Engine: "docker", Hermetic: true},
+			errExpected: "hermetic is not supported with engine docker",
+		},
+		{
+			name:        "should reject image SBOM",
+			params:      BuildParams{Engine: "podman", SBOMOutput: "sbom.json"},
+			errExpected: "sbom-output is not supported with engine podman",
+		},
+		{
+			name:        "should reject storage dir",
+			params:      BuildParams{Engine: "podman", StorageDir: "/var/lib/containers"},
+			errExpected: "storage-dir is not supported with engine podman",
+		},
+		{
+			name:        "should reject output with docker",
+			params:      BuildParams{Engine: "docker", Output: "oci:/tmp/layout"},
+			errExpected: "output is not supported with engine docker",
+		},
+		{
+			name:        "should reject cert dir with docker",
+			params:      BuildParams{Engine: "docker", CertDir: "/certs"},
+			errExpected: "cert-dir is not supported with engine docker",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &Build{Params: &tc.params, buildPlatforms: tc.platforms}
+
+			err := c.validateEngineParams()
+
+			if tc.errExpected == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(tc.errExpected))
+			}
+		})
+	}
+}
+
+func Test_Build_buildEngine(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should push with the build engine", func(t *testing.T) {
+		var pushed []string
+		engine := &mockBuildahCli{
+			PushFunc: func(args *cliWrappers.BuildahPushArgs) (string, error) {
+				pushed = append(pushed, args.Image)
+				return "sha256:1234", nil
+			},
+		}
+		c := &Build{
+			Params:      &BuildParams{Engine: "docker", OutputRef: "quay.io/org/app:v1", AdditionalTags: []string{"latest"}},
+			CliWrappers: BuildCliWrappers{BuildEngine: engine},
+		}
+
+		digest, err := c.pushImage()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(digest).To(Equal("sha256:1234"))
+		g.Expect(pushed).To(Equal([]string{"quay.io/org/app:v1", "quay.io/org/app:latest"}))
+	})
+
+	t.Run("should default to buildah", func(t *testing.T) {
+		buildahCli := &mockBuildahCli{}
+		c := &Build{Params: &BuildParams{}, CliWrappers: BuildCliWrappers{BuildahCli: buildahCli}}
+
+		g.Expect(c.buildEngine()).To(BeIdenticalTo(buildahCli))
+		g.Expect(c.usesBuildah()).To(BeTrue())
+		g.Expect(c.buildBackend()).To(Equal(buildahBackend))
+	})
+
+	t.Run("should name the engine in platform errors", func(t *testing.T) {
+		c := &Build{Params: &BuildParams{Engine: "podman"}}
+
+		_, err := parseBuildPlatforms([]string{"windows/amd64"}, c.buildBackend())
+
+		g.Expect(err).To(MatchError(ContainSubstring("podman")))
+	})
+}
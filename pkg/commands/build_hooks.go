@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// How many of the last stderr lines of a failed hook are included in the error.
+const hookErrorOutputLines = 20
+
+func (c *Build) validateHooksParams() error {
+	for _, hook := range []struct{ name, script string }{
+		{"pre-build-script", c.Params.PreBuildScript},
+		{"post-build-script", c.Params.PostBuildScript},
+	} {
+		if hook.script == "" {
+			continue
+		}
+		info, err := os.Stat(hook.script)
+		if err != nil {
+			return fmt.Errorf("%s: %w", hook.name, err)
+		}
+		if info.IsDir() || info.Mode().Perm()&0111 == 0 {
+			return fmt.Errorf("%s '%s' is not an executable file", hook.name, hook.script)
+		}
+	}
+
+	var err error
+	if c.hookTimeout, err = parseTimeout(c.Params.HookTimeout); err != nil {
+		return fmt.Errorf("hook-timeout '%s' is invalid: %w", c.Params.HookTimeout, err)
+	}
+	return nil
+}
+
+// Run the --pre-build-script or --post-build-script in the context directory.
+// The output of the script is logged, a failure or a timeout fails the build.
+func (c *Build) runHook(name, script string) error {
+	endStep := l.StartStep(name)
+	defer endStep()
+
+	scriptPath, err := filepath.Abs(script)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	env, err := c.hookEnv(name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if c.hookTimeout > 0 {
+		ctx, cancel = context.WithTimeoutCause(ctx, c.hookTimeout, fmt.Errorf("%s timed out after %s", name, c.hookTimeout))
+	}
+	defer cancel()
+
+	l.Logger.Infof("Running %s: %s", name, script)
+	_, stderr, _, err := c.CliWrappers.HookExecutor.ExecuteContext(ctx, cliWrappers.Cmd{
+		Name:       scriptPath,
+		Dir:        env.contextDir,
+		Env:        append(os.Environ(), env.vars...),
+		LogOutput:  true,
+		NameInLogs: name,
+	})
+	if err != nil {
+		if tail := lastLines(stderr, hookErrorOutputLines); tail != "" {
+			return fmt.Errorf("%s failed: %w\n%s", name, err, tail)
+		}
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
+
+type buildHookEnv struct {
+	contextDir string
+	vars       []string
+}
+
+// The KBC_HOOK_* environment variables describing the build to the hook scripts.
+// The image digest and the tags are known only after the build.
+func (c *Build) hookEnv(name string) (buildHookEnv, error) {
+	contextDir, err := filepath.Abs(c.effectiveContextDir())
+	if err != nil {
+		return buildHookEnv{}, err
+	}
+	containerfile, err := filepath.Abs(c.containerfilePath)
+	if err != nil {
+		return buildHookEnv{}, err
+	}
+
+	vars := []string{
+		"KBC_HOOK_NAME=" + name,
+		"KBC_HOOK_IMAGE_URL=" + c.Params.OutputRef,
+		"KBC_HOOK_CONTEXT_DIR=" + contextDir,
+		"KBC_HOOK_CONTAINERFILE=" + containerfile,
+	}
+	if c.Results.Digest != "" {
+		vars = append(vars, "KBC_HOOK_IMAGE_DIGEST="+c.Results.Digest)
+	}
+	if len(c.Results.Tags) > 0 {
+		vars = append(vars, "KBC_HOOK_TAGS="+strings.Join(c.Results.Tags, " "))
+	}
+	return buildHookEnv{contextDir: contextDir, vars: vars}, nil
+}
+
+// The last n lines of the output.
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
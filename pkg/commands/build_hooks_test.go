@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_Build_validateHooksParams(t *testing.T) {
+	tmpDir := t.TempDir()
+	script := filepath.Join(tmpDir, "hook.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	notExecutable := filepath.Join(tmpDir, "hook.txt")
+	if err := os.WriteFile(notExecutable, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		params          BuildParams
+		expectedTimeout time.Duration
+		errExpected     string
+	}{
+		{
+			name:            "should accept executable scripts",
+			params:          BuildParams{PreBuildScript: script, PostBuildScript: script, HookTimeout: "30s"},
+			expectedTimeout: 30 * time.Second,
+		},
+		{
+			name:   "should accept no timeout",
+			params: BuildParams{PreBuildScript: script},
+		},
+		{
+			name:        "should reject missing script",
+			params:      BuildParams{PreBuildScript: filepath.Join(tmpDir, "missing.sh")},
+			errExpected: "pre-build-script: stat " + filepath.Join(tmpDir, "missing.sh") + ": no such file or directory",
+		},
+		{
+			name:        "should reject not executable script",
+			params:      BuildParams{PostBuildScript: notExecutable},
+			errExpected: "post-build-script '" + notExecutable + "' is not an executable file",
+		},
+		{
+			name:        "should reject directory",
+			params:      BuildParams{PostBuildScript: tmpDir},
+			errExpected: "post-build-script '" + tmpDir + "' is not an executable file",
+		},
+		{
+			name:        "should reject invalid timeout",
+			params:      BuildParams{PreBuildScript: script, HookTimeout: "soon"},
+			errExpected: `hook-timeout 'soon' is invalid: time: invalid duration "soon"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &Build{Params: &tc.params}
+
+			err := c.validateHooksParams()
+
+			if tc.errExpected == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(c.hookTimeout).To(Equal(tc.expectedTimeout))
+			} else {
+				g.Expect(err).To(MatchError(tc.errExpected))
+			}
+		})
+	}
+}
+
+func Test_Build_runHook(t *testing.T) {
+	contextDir := t.TempDir()
+	containerfile := filepath.Join(contextDir, "Containerfile")
+
+	newBuild := func(executor cliWrappers.CliExecutorInterface) *Build {
+		return &Build{
+			Params:            &BuildParams{OutputRef: "quay.io/org/app:v1", Context: contextDir},
+			CliWrappers:       BuildCliWrappers{HookExecutor: executor},
+			containerfilePath: containerfile,
+			hookTimeout:       time.Minute,
+		}
+	}
+
+	t.Run("should expose the build metadata before the build", func(t *testing.T) {
+		g := NewWithT(t)
+		var executed cliWrappers.Cmd
+		executor := &mockCliExecutor{
+			ExecuteContextFunc: func(ctx context.Context, cmd cliWrappers.Cmd) (string, string, int, error) {
+				executed = cmd
+				_, hasDeadline := ctx.Deadline()
+				g.Expect(hasDeadline).To(BeTrue())
+				return "", "", 0, nil
+			},
+		}
+		c := newBuild(executor)
+
+		err := c.runHook("pre-build-script", "/hooks/pre.sh")
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(executed.Name).To(Equal("/hooks/pre.sh"))
+		g.Expect(executed.Dir).To(Equal(contextDir))
+		g.Expect(executed.LogOutput).To(BeTrue())
+		g.Expect(executed.NameInLogs).To(Equal("pre-build-script"))
+		g.Expect(executed.Env).To(ContainElements(
+			"KBC_HOOK_NAME=pre-build-script",
+			"KBC_HOOK_IMAGE_URL=quay.io/org/app:v1",
+			"KBC_HOOK_CONTEXT_DIR="+contextDir,
+			"KBC_HOOK_CONTAINERFILE="+containerfile,
+		))
+		g.Expect(executed.Env).ToNot(ContainElement(HavePrefix("KBC_HOOK_IMAGE_DIGEST=")))
+		g.Expect(executed.Env).ToNot(ContainElement(HavePrefix("KBC_HOOK_TAGS=")))
+	})
+
+	t.Run("should expose the digest and tags after the build", func(t *testing.T) {
+		g := NewWithT(t)
+		var executed cliWrappers.Cmd
+		executor := &mockCliExecutor{
+			ExecuteContextFunc: func(ctx context.Context, cmd cliWrappers.Cmd) (string, string, int, error) {
+				executed = cmd
+				return "", "", 0, nil
+			},
+		}
+		c := newBuild(executor)
+		c.Results.Digest = "sha256:1234"
+		c.Results.Tags = []string{"v1", "latest"}
+
+		err := c.runHook("post-build-script", "/hooks/post.sh")
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(executed.Env).To(ContainElements(
+			"KBC_HOOK_NAME=post-build-script",
+			"KBC_HOOK_IMAGE_DIGEST=sha256:1234",
+			"KBC_HOOK_TAGS=v1 latest",
+		))
+	})
+
+	t.Run("should not set a deadline without a timeout", func(t *testing.T) {
+		g := NewWithT(t)
+		executor := &mockCliExecutor{
+			ExecuteContextFunc: func(ctx context.Context, cmd cliWrappers.Cmd) (string, string, int, error) {
+				_, hasDeadline := ctx.Deadline()
+				g.Expect(hasDeadline).To(BeFalse())
+				return "", "", 0, nil
+			},
+		}
+		c := newBuild(executor)
+		c.hookTimeout = 0
+
+		g.Expect(c.runHook("pre-build-script", "/hooks/pre.sh")).To(Succeed())
+	})
+
+	t.Run("should include the end of stderr in the error", func(t *testing.T) {
+		g := NewWithT(t)
+		executor := &mockCliExecutor{
+			ExecuteContextFunc: func(ctx context.Context, cmd cliWrappers.Cmd) (string, string, int, error) {
+				return "", "checking\npolicy violated\n", 1, errors.New("exit status 1")
+			},
+		}
+		c := newBuild(executor)
+
+		err := c.runHook("post-build-script", "/hooks/post.sh")
+
+		g.Expect(err).To(MatchError("post-build-script failed: exit status 1\nchecking\npolicy violated"))
+	})
+}
+
+func Test_lastLines(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(lastLines("a\nb\nc\n", 2)).To(Equal("b\nc"))
+	g.Expect(lastLines("a\nb\n", 5)).To(Equal("a\nb"))
+	g.Expect(lastLines("", 5)).To(Equal(""))
+}
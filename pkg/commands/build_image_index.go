@@ -3,7 +3,6 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
 
@@ -11,6 +10,7 @@ import (
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -62,6 +62,13 @@ var BuildImageIndexParamsConfig = map[string]common.Parameter{
 		TypeKind:   reflect.Slice,
 		Usage:      "Additional tags to push the image index to (e.g., taskrun name, commit sha).",
 	},
+	"annotations": {
+		Name:       "annotations",
+		ShortName:  "",
+		EnvVarName: "KBC_BUILD_IMAGE_INDEX_ANNOTATIONS",
+		TypeKind:   reflect.Slice,
+		Usage:      "Annotations of the image index in the key=value format, e.g. org.opencontainers.image.revision=<sha>.",
+	},
 	"output-manifest-path": {
 		Name:       "output-manifest-path",
 		ShortName:  "",
@@ -106,6 +113,7 @@ type BuildImageIndexParams struct {
 	BuildahFormat         string   `paramName:"buildah-format"`
 	AlwaysBuildIndex      bool     `paramName:"always-build-index"`
 	AdditionalTags        []string `paramName:"additional-tags"`
+	Annotations           []string `paramName:"annotations"`
 	OutputManifestPath    string   `paramName:"output-manifest-path"`
 	ResultPathImageDigest string   `paramName:"result-path-image-digest"`
 	ResultPathImageURL    string   `paramName:"result-path-image-url"`
@@ -174,7 +182,7 @@ func (c *BuildImageIndex) Run() error {
 	common.LogParameters(BuildImageIndexParamsConfig, c.Params)
 
 	if err := c.validateParams(); err != nil {
-		return err
+		return kbcerrors.NewValidationError(err)
 	}
 
 	c.imageName = common.GetImageName(c.Params.Image)
@@ -257,6 +265,17 @@ func (c *BuildImageIndex) buildManifestIndex() error {
 		}
 	}
 
+	if len(c.Params.Annotations) > 0 {
+		l.Logger.Infof("Annotating manifest list: %s", c.Params.Image)
+		err = c.CliWrappers.BuildahCli.ManifestAnnotate(&cliwrappers.BuildahManifestAnnotateArgs{
+			ManifestName: c.Params.Image,
+			Annotations:  c.Params.Annotations,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to annotate manifest: %w", err)
+		}
+	}
+
 	manifestJson, err := c.CliWrappers.BuildahCli.ManifestInspect(&cliwrappers.BuildahManifestInspectArgs{
 		ManifestName: c.Params.Image,
 	})
@@ -309,7 +328,7 @@ func (c *BuildImageIndex) buildManifestIndex() error {
 	c.images = platformImages
 
 	if c.Params.OutputManifestPath != "" {
-		if err := os.WriteFile(c.Params.OutputManifestPath, []byte(manifestJson), 0644); err != nil {
+		if err := common.WriteResultFile(c.Params.OutputManifestPath, []byte(manifestJson)); err != nil {
 			return fmt.Errorf("failed to write manifest file: %w", err)
 		}
 		l.Logger.Infof("Manifest data saved to %s", c.Params.OutputManifestPath)
@@ -357,6 +376,10 @@ func (c *BuildImageIndex) validateParams() error {
 		}
 	}
 
+	if err := validateAnnotations(c.Params.Annotations); err != nil {
+		return err
+	}
+
 	validFormats := map[string]bool{"oci": true, "docker": true}
 	if !validFormats[c.Params.BuildahFormat] {
 		return fmt.Errorf("format must be 'oci' or 'docker', got '%s'", c.Params.BuildahFormat)
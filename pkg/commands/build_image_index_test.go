@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 )
 
 func Test_BuildImageIndex_validateParams(t *testing.T) {
@@ -153,6 +155,17 @@ func Test_BuildImageIndex_validateParams(t *testing.T) {
 			errExpected:  true,
 			errSubstring: "duplicate image reference",
 		},
+		{
+			name: "should fail on annotation without value",
+			params: BuildImageIndexParams{
+				Image:         "quay.io/org/myapp:latest",
+				Images:        []string{"quay.io/org/myapp@" + validDigest1},
+				BuildahFormat: "oci",
+				Annotations:   []string{"org.opencontainers.image.revision"},
+			},
+			errExpected:  true,
+			errSubstring: "annotation 'org.opencontainers.image.revision' is not in the key=value format",
+		},
 	}
 
 	for _, tc := range tests {
@@ -171,6 +184,52 @@ func Test_BuildImageIndex_validateParams(t *testing.T) {
 	}
 }
 
+func Test_BuildImageIndex_buildManifestIndex_annotations(t *testing.T) {
+	g := NewWithT(t)
+
+	const digest = "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	annotations := []string{
+		"org.opencontainers.image.revision=abc123",
+		"org.opencontainers.image.source=https://github.com/org/repo",
+	}
+
+	var calls []string
+	buildahCli := &mockBuildahCli{
+		ManifestAddFunc: func(args *cliwrappers.BuildahManifestAddArgs) error {
+			calls = append(calls, "add")
+			return nil
+		},
+		ManifestAnnotateFunc: func(args *cliwrappers.BuildahManifestAnnotateArgs) error {
+			calls = append(calls, "annotate")
+			g.Expect(args.ManifestName).To(Equal("quay.io/org/myapp:latest"))
+			g.Expect(args.Annotations).To(Equal(annotations))
+			return nil
+		},
+		ManifestInspectFunc: func(args *cliwrappers.BuildahManifestInspectArgs) (string, error) {
+			return `{"manifests":[{"mediaType":"application/vnd.oci.image.manifest.v1+json"}]}`, nil
+		},
+		ManifestPushFunc: func(args *cliwrappers.BuildahManifestPushArgs) (string, error) {
+			calls = append(calls, "push")
+			return digest, nil
+		},
+	}
+
+	c := &BuildImageIndex{
+		Params: &BuildImageIndexParams{
+			Image:            "quay.io/org/myapp:latest",
+			Images:           []string{"quay.io/org/myapp@" + digest},
+			BuildahFormat:    "oci",
+			AlwaysBuildIndex: true,
+			Annotations:      annotations,
+		},
+		CliWrappers: BuildImageIndexCliWrappers{BuildahCli: buildahCli},
+		imageName:   "quay.io/org/myapp",
+	}
+
+	g.Expect(c.buildManifestIndex()).To(Succeed())
+	g.Expect(calls).To(Equal([]string{"add", "annotate", "push"}))
+}
+
 func Test_BuildImageIndex_validateFormatConsistency(t *testing.T) {
 	g := NewWithT(t)
 
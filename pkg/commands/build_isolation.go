@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var validIsolations = []string{"chroot", "oci", "rootless"}
+
+// A capability name with or without the CAP_ prefix, or ALL, case insensitive like in buildah
+var capabilityRegex = regexp.MustCompile(`^(?i)(cap_)?[a-z][a-z0-9_]*$`)
+
+// A fully qualified CDI device name: vendor.com/class=name
+var cdiDeviceRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9.-]*/[a-zA-Z0-9][a-zA-Z0-9_.-]*=[a-zA-Z0-9_.:-]+$`)
+
+// Validate the parameters forwarded to buildah for isolating the RUN instructions,
+// so that a typo fails early instead of in the middle of the build.
+func validateIsolationParams(params *BuildParams) error {
+	if params.Isolation != "" && !slices.Contains(validIsolations, params.Isolation) {
+		return fmt.Errorf("isolation must be one of '%s', got '%s'", strings.Join(validIsolations, "', '"), params.Isolation)
+	}
+
+	switch {
+	case params.Userns == "", params.Userns == "private", params.Userns == "host":
+	case filepath.IsAbs(params.Userns):
+	default:
+		return fmt.Errorf("userns must be 'private', 'host' or the absolute path of a user namespace, got '%s'", params.Userns)
+	}
+
+	for _, capabilities := range slices.Concat(params.CapAdd, params.CapDrop) {
+		// buildah also takes comma separated lists
+		for _, capability := range strings.Split(capabilities, ",") {
+			if !capabilityRegex.MatchString(capability) {
+				return fmt.Errorf("capability '%s' is invalid", capability)
+			}
+		}
+	}
+
+	for _, device := range params.Devices {
+		if err := validateDevice(device); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate a buildah --device: HOST-DEVICE[:CONTAINER-DEVICE][:PERMISSIONS] or a CDI device name.
+func validateDevice(device string) error {
+	if !strings.HasPrefix(device, "/") {
+		if !cdiDeviceRegex.MatchString(device) {
+			return fmt.Errorf("device '%s' is invalid, expected an absolute device path or a CDI device name such as vendor.com/class=name", device)
+		}
+		return nil
+	}
+
+	parts := strings.Split(device, ":")
+	if len(parts) > 3 {
+		return fmt.Errorf("device '%s' is invalid, expected HOST-DEVICE[:CONTAINER-DEVICE][:PERMISSIONS]", device)
+	}
+	if len(parts) == 3 || (len(parts) == 2 && !strings.HasPrefix(parts[1], "/")) {
+		permissions := parts[len(parts)-1]
+		if permissions == "" || strings.Trim(permissions, "rwm") != "" {
+			return fmt.Errorf("device '%s' has invalid permissions '%s', expected a combination of r, w and m", device, permissions)
+		}
+	}
+	if len(parts) == 3 && !filepath.IsAbs(parts[1]) {
+		return fmt.Errorf("device '%s' is invalid, the container device must be an absolute path", device)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_validateIsolationParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      BuildParams
+		errExpected string
+	}{
+		{
+			name:   "should accept empty params",
+			params: BuildParams{},
+		},
+		{
+			name: "should accept valid params",
+			params: BuildParams{
+				Isolation: "oci",
+				Userns:    "host",
+				CapAdd:    []string{"CAP_SYS_ADMIN", "net_admin", "CAP_SETUID,CAP_SETGID"},
+				CapDrop:   []string{"all"},
+				Devices:   []string{"/dev/fuse", "/dev/fuse:rw", "/dev/kvm:/dev/kvm:rwm", "nvidia.com/gpu=all"},
+			},
+		},
+		{
+			name:   "should accept userns path",
+			params: BuildParams{Userns: "/proc/1234/ns/user"},
+		},
+		{
+			name:        "should fail on unknown isolation",
+			params:      BuildParams{Isolation: "vm"},
+			errExpected: "isolation must be one of 'chroot', 'oci', 'rootless', got 'vm'",
+		},
+		{
+			name:        "should fail on unknown userns",
+			params:      BuildParams{Userns: "container"},
+			errExpected: "userns must be 'private', 'host'",
+		},
+		{
+			name:        "should fail on invalid capability",
+			params:      BuildParams{CapAdd: []string{"CAP_SYS_ADMIN CAP_NET_ADMIN"}},
+			errExpected: "capability 'CAP_SYS_ADMIN CAP_NET_ADMIN' is invalid",
+		},
+		{
+			name:        "should fail on invalid dropped capability",
+			params:      BuildParams{CapDrop: []string{"-net_raw"}},
+			errExpected: "capability '-net_raw' is invalid",
+		},
+		{
+			name:        "should fail on relative device",
+			params:      BuildParams{Devices: []string{"dev/fuse"}},
+			errExpected: "expected an absolute device path or a CDI device name",
+		},
+		{
+			name:        "should fail on invalid device permissions",
+			params:      BuildParams{Devices: []string{"/dev/fuse:/dev/fuse:rx"}},
+			errExpected: "invalid permissions 'rx'",
+		},
+		{
+			name:        "should fail on relative container device",
+			params:      BuildParams{Devices: []string{"/dev/fuse:fuse:rw"}},
+			errExpected: "the container device must be an absolute path",
+		},
+		{
+			name:        "should fail on too many device parts",
+			params:      BuildParams{Devices: []string{"/dev/fuse:/dev/fuse:rw:x"}},
+			errExpected: "expected HOST-DEVICE[:CONTAINER-DEVICE][:PERMISSIONS]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := validateIsolationParams(&tc.params)
+			if tc.errExpected != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.errExpected)))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os/exec"
 
 	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 	"golang.org/x/sys/unix"
 )
@@ -55,6 +56,7 @@ func (c *Build) reExecInUserNamespace() error {
 	}
 
 	env := append(os.Environ(), envVarInUserNamespace+"=1")
+	env = append(env, common.RegistryAuthContextHandOverEnv()...)
 	return unix.Exec(binary, append([]string{name}, args...), env)
 }
 
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func (c *Build) validateNativePushParams() error {
+	if !c.Params.NativePush {
+		return nil
+	}
+	if !c.Params.Push || c.Params.Output == "" {
+		return errors.New("native-push requires push and output")
+	}
+	if c.Params.RemotePush {
+		return errors.New("native-push and remote-push are mutually exclusive")
+	}
+	if c.isMultiPlatform() {
+		return errors.New("native-push is not supported with several platforms")
+	}
+	return nil
+}
+
+// Push the image with the build engine, or from the --output layout with --native-push.
+func (c *Build) push(args *cliWrappers.BuildahPushArgs) (string, error) {
+	if !c.Params.NativePush {
+		return c.buildEngine().Push(args)
+	}
+	// The output is written in the --format already
+	return c.CliWrappers.ImagePusher.Push(&clients.ImagePushArgs{
+		Source:      c.Params.Output,
+		Destination: "docker://" + args.Image,
+		TLSVerify:   args.TLSVerify,
+		CertDir:     args.CertDir,
+		Timeout:     args.Timeout,
+	})
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_Build_validateNativePushParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      BuildParams
+		platforms   []string
+		errExpected string
+	}{
+		{
+			name:   "should accept push from output",
+			params: BuildParams{NativePush: true, Push: true, Output: "oci-archive:/tmp/image.tar"},
+		},
+		{
+			name:        "should require push",
+			params:      BuildParams{NativePush: true, Output: "oci:/tmp/layout"},
+			errExpected: "native-push requires push and output",
+		},
+		{
+			name:        "should require output",
+			params:      BuildParams{NativePush: true, Push: true},
+			errExpected: "native-push requires push and output",
+		},
+		{
+			name:        "should reject remote push",
+			params:      BuildParams{NativePush: true, Push: true, Output: "oci:/tmp/layout", RemotePush: true},
+			errExpected: "native-push and remote-push are mutually exclusive",
+		},
+		{
+			name:        "should reject several platforms",
+			params:      BuildParams{NativePush: true, Push: true, Output: "oci:/tmp/layout"},
+			platforms:   []string{"linux/amd64", "linux/arm64"},
+			errExpected: "native-push is not supported with several platforms",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &Build{Params: &tc.params, buildPlatforms: tc.platforms}
+
+			err := c.validateNativePushParams()
+
+			if tc.errExpected == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(tc.errExpected))
+			}
+		})
+	}
+}
+
+func Test_Build_pushImage_native(t *testing.T) {
+	g := NewWithT(t)
+
+	var pushed []*clients.ImagePushArgs
+	pusher := &mockImagePusher{
+		PushFunc: func(args *clients.ImagePushArgs) (string, error) {
+			pushed = append(pushed, args)
+			return "sha256:1234", nil
+		},
+	}
+	engine := &mockBuildahCli{
+		PushFunc: func(args *cliWrappers.BuildahPushArgs) (string, error) {
+			t.Fatal("the build engine should not push")
+			return "", nil
+		},
+	}
+	c := &Build{
+		Params: &BuildParams{
+			OutputRef:      "quay.io/org/app:v1",
+			AdditionalTags: []string{"latest"},
+			Output:         "oci-archive:/tmp/image.tar",
+			Push:           true,
+			NativePush:     true,
+			DestTLSVerify:  true,
+			Format:         "docker",
+		},
+		CliWrappers: BuildCliWrappers{BuildahCli: engine, ImagePusher: pusher},
+		certDir:     "/certs",
+		pushTimeout: time.Minute,
+	}
+
+	digest, err := c.pushImage()
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(digest).To(Equal("sha256:1234"))
+	g.Expect(pushed).To(HaveLen(2))
+	g.Expect(*pushed[0]).To(Equal(clients.ImagePushArgs{
+		Source:      "oci-archive:/tmp/image.tar",
+		Destination: "docker://quay.io/org/app:v1",
+		TLSVerify:   &c.Params.DestTLSVerify,
+		CertDir:     "/certs",
+		Timeout:     time.Minute,
+	}))
+	g.Expect(pushed[1].Destination).To(Equal("docker://quay.io/org/app:latest"))
+}
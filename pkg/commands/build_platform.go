@@ -0,0 +1,261 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/containerd/platforms"
+	"github.com/keilerkonzept/dockerfile-json/pkg/dockerfile"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// buildBackend describes the operating systems the tool that builds the image can target.
+// The backends are selected with --engine. A backend capable of building e.g. Windows images
+// would be added here and selected based on the platforms the Containerfile requests.
+type buildBackend struct {
+	name        string
+	supportedOS []string
+}
+
+var (
+	buildahBackend = buildBackend{name: "buildah", supportedOS: []string{"linux"}}
+	podmanBackend  = buildBackend{name: "podman", supportedOS: []string{"linux"}}
+	dockerBackend  = buildBackend{name: "docker", supportedOS: []string{"linux"}}
+)
+
+func (b buildBackend) supportsOS(os string) bool {
+	return slices.Contains(b.supportedOS, strings.ToLower(os))
+}
+
+// UnsupportedPlatformError is returned for builds that target an operating system
+// the build backend cannot build for, e.g. Windows containers.
+type UnsupportedPlatformError struct {
+	Backend     string
+	SupportedOS []string
+	OS          string
+	// What requested the platform, e.g. "FROM --platform=windows/amd64" or a base image reference
+	Source string
+}
+
+func (e *UnsupportedPlatformError) Error() string {
+	msg := fmt.Sprintf("building %s container images is not supported: %s requires the '%s' platform, but %s only builds %s images",
+		e.OS, e.Source, e.OS, e.Backend, strings.Join(e.SupportedOS, ", "))
+	if e.OS == "windows" {
+		msg += ". Windows images must be built on a Windows host, e.g. with docker on Windows Server. " +
+			"If the image is meant to be a Linux image, use a Linux base image or a multi-platform image reference"
+	}
+	return msg
+}
+
+// Windows base images are usually referenced by single-platform tags, which only fail
+// deep inside buildah (e.g. "no image found in manifest list for architecture"). Recognize
+// the common ones by name so that the build fails before pulling anything.
+var windowsImageMarkers = []string{"nanoserver", "servercore"}
+
+func isWindowsImageRef(imageRef string) bool {
+	ref := strings.ToLower(imageRef)
+	if strings.HasPrefix(ref, "mcr.microsoft.com/windows") || strings.HasPrefix(ref, "mcr.microsoft.com/dotnet/framework/") {
+		return true
+	}
+	// Check the path and tag of the reference, not the registry
+	_, pathAndTag, found := strings.Cut(ref, "/")
+	if !found {
+		pathAndTag = ref
+	}
+	return slices.ContainsFunc(windowsImageMarkers, func(marker string) bool {
+		return strings.Contains(pathAndTag, marker)
+	})
+}
+
+// Fail early if the Containerfile requests a platform the build backend doesn't support,
+// either explicitly (FROM --platform) or implicitly by using a Windows base image.
+func checkContainerfilePlatforms(df *dockerfile.Dockerfile, backend buildBackend) error {
+	for _, stage := range df.Stages {
+		// Unexpanded variables are left for buildah to resolve
+		if stage.Platform != "" && !strings.Contains(stage.Platform, "$") {
+			os, _, _ := strings.Cut(stage.Platform, "/")
+			if !backend.supportsOS(os) {
+				return &UnsupportedPlatformError{
+					Backend:     backend.name,
+					SupportedOS: backend.supportedOS,
+					OS:          strings.ToLower(os),
+					Source:      fmt.Sprintf("'FROM --platform=%s %s'", stage.Platform, stage.BaseName),
+				}
+			}
+		}
+		if stage.From.Image != nil && isWindowsImageRef(*stage.From.Image) && !backend.supportsOS("windows") {
+			return &UnsupportedPlatformError{
+				Backend:     backend.name,
+				SupportedOS: backend.supportedOS,
+				OS:          "windows",
+				Source:      fmt.Sprintf("base image %s", *stage.From.Image),
+			}
+		}
+	}
+	return nil
+}
+
+// Check the OS of a pulled base image, this catches the images not recognized by name.
+func checkBaseImageOS(imageRef string, imageOS string, backend buildBackend) error {
+	if imageOS == "" || backend.supportsOS(imageOS) {
+		return nil
+	}
+	return &UnsupportedPlatformError{
+		Backend:     backend.name,
+		SupportedOS: backend.supportedOS,
+		OS:          strings.ToLower(imageOS),
+		Source:      fmt.Sprintf("base image %s", imageRef),
+	}
+}
+
+// Parse and normalize the --platform values, each of which can be a comma-separated list.
+func parseBuildPlatforms(values []string, backend buildBackend) ([]string, error) {
+	var result []string
+	for _, value := range values {
+		for _, platform := range strings.Split(value, ",") {
+			platform = strings.TrimSpace(platform)
+			if platform == "" {
+				continue
+			}
+			spec, err := platforms.Parse(platform)
+			if err != nil {
+				return nil, fmt.Errorf("platform '%s' is invalid: %w", platform, err)
+			}
+			if !backend.supportsOS(spec.OS) {
+				return nil, &UnsupportedPlatformError{
+					Backend:     backend.name,
+					SupportedOS: backend.supportedOS,
+					OS:          spec.OS,
+					Source:      fmt.Sprintf("'--platform=%s'", platform),
+				}
+			}
+			normalized := platforms.Format(platforms.Normalize(spec))
+			if !slices.Contains(result, normalized) {
+				result = append(result, normalized)
+			}
+		}
+	}
+	return result, nil
+}
+
+// The features that work with a single image in local storage, which a multi-platform build
+// doesn't produce (the output is a manifest list).
+func validateMultiPlatformParams(params *BuildParams) error {
+	switch {
+	case params.Bootc:
+		return fmt.Errorf("bootc is not supported for multi-platform builds")
+	case params.SyftImageOutput != "":
+		return fmt.Errorf("syft-image-output is not supported for multi-platform builds")
+	case params.SBOMOutput != "":
+		return fmt.Errorf("sbom-output is not supported for multi-platform builds")
+	case params.CheckpointFile != "":
+		return fmt.Errorf("checkpoint-file is not supported for multi-platform builds")
+	case params.ResolveBaseImages:
+		return fmt.Errorf("resolve-base-images is not supported for multi-platform builds")
+	}
+	return nil
+}
+
+func (c *Build) isMultiPlatform() bool {
+	return len(c.buildPlatforms) > 1
+}
+
+func (c *Build) targetPlatformSpec() specs.Platform {
+	if c.targetPlatform != "" {
+		if spec, err := platforms.Parse(c.targetPlatform); err == nil {
+			return platforms.Normalize(spec)
+		}
+	}
+	return platforms.Normalize(platforms.DefaultSpec())
+}
+
+// Pre-pull the base images and verify their architectures, for each platform of the build.
+//
+// Buildah stores the pulled images by reference, pulling a base image for the next platform
+// re-tags the reference to the new image. The images of each platform are therefore verified
+// right after pulling them. The instances pulled earlier stay in local storage and buildah
+// finds them via the manifest list when building for their platform.
+func (c *Build) prePullAndVerifyBaseImages(containerfile *dockerfile.Dockerfile) ([]BaseImage, error) {
+	if !c.isMultiPlatform() {
+		pulledImages, err := c.prePullBaseImages(containerfile)
+		if err != nil {
+			return nil, err
+		}
+		return pulledImages, c.verifyBaseImageArchitectures(pulledImages)
+	}
+
+	defer func() { c.targetPlatform = c.buildPlatforms[0] }()
+
+	var pulledImages []BaseImage
+	for _, platform := range c.buildPlatforms {
+		l.Logger.Infof("Pre-pulling base images for platform %s", platform)
+		c.targetPlatform = platform
+		// The base images may depend on the TARGET* build args
+		platformContainerfile, err := c.parseContainerfile()
+		if err != nil {
+			return nil, err
+		}
+		images, err := c.prePullBaseImages(platformContainerfile)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.verifyBaseImageArchitectures(images); err != nil {
+			return nil, err
+		}
+		for _, image := range images {
+			if !slices.Contains(pulledImages, image) {
+				pulledImages = append(pulledImages, image)
+			}
+		}
+	}
+	return pulledImages, nil
+}
+
+// Push the manifest list of a multi-platform build, including the images of all platforms.
+func (c *Build) pushManifestList() (string, error) {
+	l.Logger.Infof("Pushing manifest list to registry: %s", c.Params.OutputRef)
+
+	pushResult, err := c.CliWrappers.BuildahCli.ManifestPushWithDigests(&cliWrappers.BuildahManifestPushArgs{
+		ManifestName: c.Params.OutputRef,
+		Destination:  "docker://" + c.Params.OutputRef,
+		Format:       c.Params.Format,
+		TLSVerify:    c.Params.DestTLSVerify,
+		CertDir:      c.certDir,
+		Timeout:      c.pushTimeout,
+	})
+	if err != nil {
+		return "", fmt.Errorf("pushing manifest list %s: %w", c.Params.OutputRef, err)
+	}
+	digest := pushResult.IndexDigest
+	l.Logger.Infof("Manifest list digest: %s", digest)
+	for _, manifest := range pushResult.Manifests {
+		l.Logger.Infof("Manifest digest of %s: %s", manifest.Platform, manifest.Digest)
+		c.Results.PlatformDigests = append(c.Results.PlatformDigests, BuildPlatformDigest{
+			Platform: manifest.Platform,
+			Digest:   manifest.Digest,
+		})
+	}
+
+	imageName := common.GetImageName(c.Params.OutputRef)
+	for _, tag := range c.Params.AdditionalTags {
+		l.Logger.Infof("Pushing additional tag: %s", tag)
+		_, err := c.CliWrappers.BuildahCli.ManifestPush(&cliWrappers.BuildahManifestPushArgs{
+			ManifestName: c.Params.OutputRef,
+			Destination:  "docker://" + imageName + ":" + tag,
+			Format:       c.Params.Format,
+			TLSVerify:    c.Params.DestTLSVerify,
+			CertDir:      c.certDir,
+			Timeout:      c.pushTimeout,
+		})
+		if err != nil {
+			return "", fmt.Errorf("pushing additional tag %s: %w", tag, err)
+		}
+	}
+
+	return digest, nil
+}
@@ -0,0 +1,291 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_checkContainerfilePlatforms(t *testing.T) {
+	tests := []struct {
+		name          string
+		containerfile string
+		errSubstring  string
+	}{
+		{
+			name:          "should accept linux images",
+			containerfile: "FROM --platform=linux/amd64 registry.access.redhat.com/ubi9 AS builder\nFROM scratch",
+		},
+		{
+			name:          "should leave unexpanded platform to buildah",
+			containerfile: "FROM --platform=$BUILDPLATFORM golang:1.25",
+		},
+		{
+			name:          "should reject windows platform",
+			containerfile: "FROM --platform=windows/amd64 golang:1.25",
+			errSubstring:  "building windows container images is not supported: 'FROM --platform=windows/amd64 golang:1.25'",
+		},
+		{
+			name:          "should reject windows base image",
+			containerfile: "FROM registry.access.redhat.com/ubi9\nFROM mcr.microsoft.com/windows/servercore:ltsc2022",
+			errSubstring:  "base image mcr.microsoft.com/windows/servercore:ltsc2022 requires the 'windows' platform",
+		},
+		{
+			name:          "should reject nanoserver based image",
+			containerfile: "FROM mcr.microsoft.com/dotnet/runtime:8.0-nanoserver-ltsc2022",
+			errSubstring:  "Windows images must be built on a Windows host",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			df := parseDockerfile(t, g, tc.containerfile)
+
+			err := checkContainerfilePlatforms(df, buildahBackend)
+
+			if tc.errSubstring != "" {
+				var platformErr *UnsupportedPlatformError
+				g.Expect(errors.As(err, &platformErr)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring(tc.errSubstring))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
+
+func Test_Build_verifyBaseImageArchitectures_windows(t *testing.T) {
+	g := NewWithT(t)
+
+	mock := &mockBuildahCli{
+		InspectImageFunc: func(name string) (cliwrappers.BuildahImageInfo, error) {
+			info := cliwrappers.BuildahImageInfo{}
+			info.OCIv1.OS = "windows"
+			info.OCIv1.Architecture = "amd64"
+			return info, nil
+		},
+	}
+	c := &Build{
+		CliWrappers: BuildCliWrappers{BuildahCli: mock},
+		Params:      &BuildParams{AllowCrossPlatformImages: true},
+	}
+
+	err := c.verifyBaseImageArchitectures([]BaseImage{{Ref: "quay.io/org/win-base:1"}})
+
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("base image quay.io/org/win-base:1 requires the 'windows' platform, but buildah only builds linux images"))
+}
+
+func Test_parseBuildPlatforms(t *testing.T) {
+	tests := []struct {
+		name         string
+		values       []string
+		expected     []string
+		errSubstring string
+	}{
+		{
+			name:     "should return nothing without platforms",
+			values:   nil,
+			expected: nil,
+		},
+		{
+			name:     "should normalize and deduplicate platforms",
+			values:   []string{"linux/amd64,linux/arm64", "linux/x86_64", " linux/arm64/v8 "},
+			expected: []string{"linux/amd64", "linux/arm64"},
+		},
+		{
+			name:     "should keep variants",
+			values:   []string{"linux/arm/v7"},
+			expected: []string{"linux/arm/v7"},
+		},
+		{
+			name:         "should reject invalid platform",
+			values:       []string{"linux/amd64/v2/extra"},
+			errSubstring: "platform 'linux/amd64/v2/extra' is invalid",
+		},
+		{
+			name:         "should reject windows platform",
+			values:       []string{"linux/amd64", "windows/amd64"},
+			errSubstring: "building windows container images is not supported: '--platform=windows/amd64'",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			result, err := parseBuildPlatforms(tc.values, buildahBackend)
+
+			if tc.errSubstring != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.errSubstring)))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result).To(Equal(tc.expected))
+			}
+		})
+	}
+}
+
+func Test_Build_createBuildArgExpander_targetPlatform(t *testing.T) {
+	g := NewWithT(t)
+	c := &Build{Params: &BuildParams{}, targetPlatform: "linux/arm/v7"}
+
+	expander, err := c.createBuildArgExpander()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	expected := map[string]string{
+		"TARGETPLATFORM": "linux/arm/v7",
+		"TARGETOS":       "linux",
+		"TARGETARCH":     "arm",
+		"TARGETVARIANT":  "v7",
+	}
+	for arg, value := range expected {
+		g.Expect(expander(arg)).To(Equal(value), arg)
+	}
+	hostArch, err := expander("BUILDARCH")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(hostArch).ToNot(BeEmpty())
+}
+
+func Test_Build_prePullAndVerifyBaseImages_multiPlatform(t *testing.T) {
+	g := NewWithT(t)
+	containerfile := filepath.Join(t.TempDir(), "Containerfile")
+	g.Expect(os.WriteFile(containerfile, []byte(strings.Join([]string{
+		"FROM --platform=$BUILDPLATFORM quay.io/org/builder:1 AS builder",
+		"FROM quay.io/org/base:1",
+		"COPY --from=builder /app /app",
+	}, "\n")), 0644)).To(Succeed())
+
+	var pulls []string
+	lastPulledArch := map[string]string{}
+	mock := &mockBuildahCli{
+		PullFunc: func(args *cliwrappers.BuildahPullArgs) error {
+			pulls = append(pulls, args.Image+" "+args.Platform)
+			lastPulledArch[args.Image] = strings.Split(args.Platform, "/")[1]
+			return nil
+		},
+		InspectImageFunc: func(name string) (cliwrappers.BuildahImageInfo, error) {
+			info := cliwrappers.BuildahImageInfo{}
+			info.OCIv1.OS = "linux"
+			info.OCIv1.Architecture = lastPulledArch[name]
+			return info, nil
+		},
+	}
+	c := &Build{
+		CliWrappers:       BuildCliWrappers{BuildahCli: mock},
+		Params:            &BuildParams{SkipUnusedStages: true, AllowCrossPlatformImages: true},
+		containerfilePath: containerfile,
+		buildPlatforms:    []string{"linux/amd64", "linux/arm64"},
+		targetPlatform:    "linux/amd64",
+	}
+	df, err := c.parseContainerfile()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	pulledImages, err := c.prePullAndVerifyBaseImages(df)
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(pulls).To(ContainElements(
+		"quay.io/org/base:1 linux/amd64",
+		"quay.io/org/base:1 linux/arm64",
+	))
+	g.Expect(pulls).To(HaveLen(4))
+	g.Expect(pulledImages).To(ContainElement(BaseImage{Ref: "quay.io/org/base:1"}))
+	g.Expect(c.targetPlatform).To(Equal("linux/amd64"))
+}
+
+func Test_Build_multiPlatform(t *testing.T) {
+	t.Run("should build a manifest list", func(t *testing.T) {
+		g := NewWithT(t)
+		var buildArgs *cliwrappers.BuildahBuildArgs
+		mock := &mockBuildahCli{
+			BuildFunc: func(args *cliwrappers.BuildahBuildArgs) error {
+				buildArgs = args
+				return nil
+			},
+		}
+		c := &Build{
+			CliWrappers:       BuildCliWrappers{BuildahCli: mock},
+			Params:            &BuildParams{OutputRef: "quay.io/org/app:v1", Context: t.TempDir()},
+			containerfilePath: "Containerfile",
+			buildPlatforms:    []string{"linux/amd64", "linux/arm64"},
+		}
+
+		g.Expect(c.buildImage()).To(Succeed())
+
+		g.Expect(buildArgs.Platforms).To(Equal([]string{"linux/amd64", "linux/arm64"}))
+		g.Expect(buildArgs.Manifest).To(Equal("quay.io/org/app:v1"))
+		g.Expect(buildArgs.Tags).To(BeEmpty())
+	})
+
+	t.Run("should push the manifest list and additional tags", func(t *testing.T) {
+		g := NewWithT(t)
+		const digest = "sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c"
+		var destinations []string
+		mock := &mockBuildahCli{
+			ManifestPushFunc: func(args *cliwrappers.BuildahManifestPushArgs) (string, error) {
+				g.Expect(args.ManifestName).To(Equal("quay.io/org/app:v1"))
+				destinations = append(destinations, args.Destination)
+				return digest, nil
+			},
+			PushFunc: func(args *cliwrappers.BuildahPushArgs) (string, error) {
+				return "", errors.New("unexpected push of a single image")
+			},
+		}
+		c := &Build{
+			CliWrappers:    BuildCliWrappers{BuildahCli: mock},
+			Params:         &BuildParams{OutputRef: "quay.io/org/app:v1", AdditionalTags: []string{"latest"}},
+			buildPlatforms: []string{"linux/amd64", "linux/arm64"},
+		}
+
+		pushedDigest, err := c.pushImage()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(pushedDigest).To(Equal(digest))
+		g.Expect(destinations).To(Equal([]string{"docker://quay.io/org/app:v1", "docker://quay.io/org/app:latest"}))
+	})
+
+	t.Run("should report the per-platform digests of the pushed manifest list", func(t *testing.T) {
+		g := NewWithT(t)
+		const indexDigest = "sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c"
+		mock := &mockBuildahCli{
+			ManifestPushWithDigestsFunc: func(args *cliwrappers.BuildahManifestPushArgs) (*cliwrappers.BuildahManifestPushResult, error) {
+				g.Expect(args.Destination).To(Equal("docker://quay.io/org/app:v1"))
+				return &cliwrappers.BuildahManifestPushResult{
+					IndexDigest: indexDigest,
+					Manifests: []cliwrappers.BuildahPlatformManifest{
+						{Platform: "linux/amd64", Digest: "sha256:1111"},
+						{Platform: "linux/arm64", Digest: "sha256:2222"},
+					},
+				}, nil
+			},
+		}
+		c := &Build{
+			CliWrappers:    BuildCliWrappers{BuildahCli: mock},
+			Params:         &BuildParams{OutputRef: "quay.io/org/app:v1"},
+			buildPlatforms: []string{"linux/amd64", "linux/arm64"},
+		}
+
+		pushedDigest, err := c.pushImage()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(pushedDigest).To(Equal(indexDigest))
+		g.Expect(c.Results.PlatformDigests).To(Equal([]BuildPlatformDigest{
+			{Platform: "linux/amd64", Digest: "sha256:1111"},
+			{Platform: "linux/arm64", Digest: "sha256:2222"},
+		}))
+	})
+
+	t.Run("should reject features that need a single image", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(validateMultiPlatformParams(&BuildParams{Bootc: true})).To(MatchError(ContainSubstring("bootc")))
+		g.Expect(validateMultiPlatformParams(&BuildParams{SyftImageOutput: "sbom.json"})).To(MatchError(ContainSubstring("syft-image-output")))
+		g.Expect(validateMultiPlatformParams(&BuildParams{CheckpointFile: "checkpoint.json"})).To(MatchError(ContainSubstring("checkpoint-file")))
+		g.Expect(validateMultiPlatformParams(&BuildParams{})).To(Succeed())
+	})
+}
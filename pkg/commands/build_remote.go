@@ -0,0 +1,299 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// Validate the --remote-* params, after the platforms are parsed.
+func (c *Build) validateRemoteParams() error {
+	if c.Params.RemoteHost == "" {
+		if c.Params.RemoteSshKey != "" || c.Params.RemoteKnownHosts != "" || c.Params.RemotePush {
+			return errors.New("remote-ssh-key, remote-known-hosts and remote-push require remote-host")
+		}
+		return nil
+	}
+
+	// Also rules out ssh options and rsync host:path ambiguities
+	if strings.HasPrefix(c.Params.RemoteHost, "-") || strings.ContainsAny(c.Params.RemoteHost, " \t\n:/") {
+		return fmt.Errorf("remote-host '%s' is invalid, expected [user@]host", c.Params.RemoteHost)
+	}
+	if c.Params.RemoteSshKey != "" {
+		if _, err := os.Stat(c.Params.RemoteSshKey); err != nil {
+			return fmt.Errorf("remote-ssh-key: %w", err)
+		}
+	}
+	// The context, secrets and registry credentials are sent to the host, it must not be trusted on first use
+	if c.Params.RemoteKnownHosts == "" {
+		return errors.New("remote-known-hosts is required with remote-host")
+	}
+	if _, err := os.Stat(c.Params.RemoteKnownHosts); err != nil {
+		return fmt.Errorf("remote-known-hosts: %w", err)
+	}
+	if c.isMultiPlatform() {
+		return errors.New("remote-host builds a single platform, build each platform on its own host and combine the images with build-image-index")
+	}
+
+	unsupported := []struct {
+		name string
+		set  bool
+	}{
+		{"hermetic", c.Params.Hermetic},
+		{"bootc", c.Params.Bootc},
+		{"builder-metadata-output", c.Params.BuilderMetadataOutput != ""},
+		{"resolved-base-images-output", c.Params.ResolvedBaseImagesOutput != ""},
+	}
+	if c.Params.RemotePush {
+		if !c.Params.Push {
+			return errors.New("remote-push requires push")
+		}
+		// These need the image in the local storage
+		unsupported = append(unsupported, []struct {
+			name string
+			set  bool
+		}{
+			{"output", c.Params.Output != ""},
+			{"syft-image-output", c.Params.SyftImageOutput != ""},
+			{"sbom-output", c.Params.SBOMOutput != ""},
+			{"checkpoint-file", c.Params.CheckpointFile != ""},
+		}...)
+	}
+	for _, param := range unsupported {
+		if param.set {
+			if c.Params.RemotePush {
+				return fmt.Errorf("%s is not supported with remote-host and remote-push", param.name)
+			}
+			return fmt.Errorf("%s is not supported with remote-host", param.name)
+		}
+	}
+	return nil
+}
+
+// Build the image on --remote-host. The local files the build uses are copied into a temporary
+// directory on the host under their full paths, e.g. the context /src/app is built from <workdir>/src/app.
+// Unless --remote-push is set, the built image is then copied back into the local storage.
+func (c *Build) buildImageRemotely() error {
+	l.Logger.Infof("Building container image on %s...", c.Params.RemoteHost)
+
+	stdout, _, _, err := c.CliWrappers.SshCli.Run([]string{"mktemp", "-d", "-t", "kbc-build-XXXXXXXX"}, false)
+	if err != nil {
+		return fmt.Errorf("creating temporary directory on %s: %w", c.Params.RemoteHost, err)
+	}
+	c.remoteWorkdir = strings.TrimSpace(stdout)
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	buildArgs := c.buildahBuildArgs()
+	buildArgs.AuthFile = registryAuthFile()
+	if err := buildArgs.MakePathsAbsolute(cwd); err != nil {
+		return err
+	}
+
+	localPaths := []string{}
+	forEachBuildPath(buildArgs, func(p *string) {
+		localPaths = append(localPaths, *p)
+	})
+	l.Logger.Infof("Copying the build inputs to %s", c.Params.RemoteHost)
+	if err := c.CliWrappers.SshCli.CopyTo(localPaths, c.remoteWorkdir); err != nil {
+		return fmt.Errorf("copying the build inputs to %s: %w", c.Params.RemoteHost, err)
+	}
+	forEachBuildPath(buildArgs, func(p *string) {
+		*p = c.remotePath(*p)
+	})
+
+	if err := c.CliWrappers.RemoteBuildahCli.Build(buildArgs); err != nil {
+		return err
+	}
+	l.Logger.Info("Build completed successfully")
+
+	if c.Params.RemotePush {
+		return nil
+	}
+	return c.fetchRemoteImage()
+}
+
+// Call fn with each non-empty local path in the buildah build arguments.
+func forEachBuildPath(args *cliWrappers.BuildahBuildArgs, fn func(p *string)) {
+	paths := []*string{&args.Containerfile, &args.ContextDir, &args.BuildArgsFile, &args.IgnoreFile, &args.CertDir, &args.AuthFile}
+	for i := range args.Secrets {
+		paths = append(paths, &args.Secrets[i].Src)
+	}
+	for i := range args.Volumes {
+		paths = append(paths, &args.Volumes[i].HostDir)
+	}
+	for i := range args.BuildContexts {
+		paths = append(paths, &args.BuildContexts[i].Location)
+	}
+	for _, p := range paths {
+		if *p != "" {
+			fn(p)
+		}
+	}
+}
+
+// The path the absolute local path is copied to on --remote-host.
+func (c *Build) remotePath(localPath string) string {
+	return path.Join(c.remoteWorkdir, localPath)
+}
+
+// The registry auth file buildah uses locally, empty if there's none.
+func registryAuthFile() string {
+	if authFile := os.Getenv("REGISTRY_AUTH_FILE"); authFile != "" {
+		return authFile
+	}
+	if authFile := common.GetDefaultAuthFile(); fileExists(authFile) {
+		return authFile
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// Copy the image built on --remote-host into the local storage, through an OCI layout,
+// and give it the tags of the build.
+func (c *Build) fetchRemoteImage() error {
+	l.Logger.Infof("Copying the built image from %s", c.Params.RemoteHost)
+
+	remoteLayout := path.Join(c.remoteWorkdir, "image")
+	_, _, _, err := c.CliWrappers.SshCli.Run([]string{"buildah", "push", c.Params.OutputRef, "oci:" + remoteLayout}, true)
+	if err != nil {
+		return fmt.Errorf("exporting the built image on %s: %w", c.Params.RemoteHost, err)
+	}
+
+	if err := c.ensureTempWorkdirExists(); err != nil {
+		return err
+	}
+	localLayout := filepath.Join(c.tempWorkdir, "remote-image")
+	if err := c.CliWrappers.SshCli.CopyFrom(remoteLayout+"/", localLayout); err != nil {
+		return fmt.Errorf("copying the built image from %s: %w", c.Params.RemoteHost, err)
+	}
+
+	if err := c.CliWrappers.BuildahCli.Pull(&cliWrappers.BuildahPullArgs{Image: "oci:" + localLayout}); err != nil {
+		return fmt.Errorf("loading the built image: %w", err)
+	}
+	imageId, err := ociLayoutImageId(localLayout)
+	if err != nil {
+		return fmt.Errorf("loading the built image: %w", err)
+	}
+	if err := c.CliWrappers.BuildahCli.Tag(imageId, c.allTags()...); err != nil {
+		return fmt.Errorf("tagging the built image: %w", err)
+	}
+	return nil
+}
+
+// The ID of the image in the OCI layout, i.e. the digest of its config, as in the local storage.
+func ociLayoutImageId(layoutDir string) (string, error) {
+	readBlob := func(d digest.Digest, v any) error {
+		if err := d.Validate(); err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filepath.Join(layoutDir, "blobs", d.Algorithm().String(), d.Encoded()))
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(content, v)
+	}
+
+	content, err := os.ReadFile(filepath.Join(layoutDir, "index.json")) //nolint:gosec // layout written by buildah
+	if err != nil {
+		return "", err
+	}
+	var index specs.Index
+	if err := json.Unmarshal(content, &index); err != nil {
+		return "", fmt.Errorf("parsing index.json: %w", err)
+	}
+	if len(index.Manifests) != 1 {
+		return "", fmt.Errorf("expected one image in %s, found %d", layoutDir, len(index.Manifests))
+	}
+
+	var manifest specs.Manifest
+	if err := readBlob(index.Manifests[0].Digest, &manifest); err != nil {
+		return "", fmt.Errorf("reading the image manifest: %w", err)
+	}
+	if err := manifest.Config.Digest.Validate(); err != nil {
+		return "", fmt.Errorf("reading the image config digest: %w", err)
+	}
+	return manifest.Config.Digest.Encoded(), nil
+}
+
+// Push the image and its additional tags from --remote-host. Returns the digest of the image.
+func (c *Build) pushImageRemotely() (string, error) {
+	imageName := common.GetImageName(c.Params.OutputRef)
+	images := []string{c.Params.OutputRef}
+	for _, tag := range c.Params.AdditionalTags {
+		images = append(images, imageName+":"+tag)
+	}
+
+	var imageDigest string
+	for _, image := range images {
+		l.Logger.Infof("Pushing image to registry from %s: %s", c.Params.RemoteHost, image)
+		d, err := c.pushFromRemoteHost(image)
+		if err != nil {
+			return "", fmt.Errorf("pushing image %s: %w", image, err)
+		}
+		if imageDigest == "" {
+			imageDigest = d
+		}
+	}
+	l.Logger.Info("Push completed successfully")
+	l.Logger.Infof("Image digest: %s", imageDigest)
+	return imageDigest, nil
+}
+
+func (c *Build) pushFromRemoteHost(image string) (string, error) {
+	digestFile := path.Join(c.remoteWorkdir, "digest")
+	command := []string{"buildah", "push", "--digestfile", digestFile, fmt.Sprintf("--tls-verify=%t", c.Params.DestTLSVerify)}
+	if authFile := registryAuthFile(); authFile != "" {
+		command = append(command, "--authfile", c.remotePath(absPath(authFile)))
+	}
+	if c.certDir != "" {
+		command = append(command, "--cert-dir", c.remotePath(absPath(c.certDir)))
+	}
+	if c.Params.Format != "" {
+		command = append(command, "--format", c.Params.Format)
+	}
+	command = append(command, image)
+
+	retryer := cliWrappers.NewRetryer(func() (string, string, int, error) {
+		return c.CliWrappers.SshCli.Run(command, true)
+	}).WithImageRegistryPreset()
+	if _, _, _, err := retryer.Run(); err != nil {
+		return "", err
+	}
+
+	stdout, _, _, err := c.CliWrappers.SshCli.Run([]string{"cat", digestFile}, false)
+	if err != nil {
+		return "", fmt.Errorf("reading the digest: %w", err)
+	}
+	return strings.TrimSpace(stdout), nil
+}
+
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+func (c *Build) cleanupRemoteWorkdir() {
+	if _, _, _, err := c.CliWrappers.SshCli.Run([]string{"rm", "-rf", c.remoteWorkdir}, false); err != nil {
+		l.Logger.Warnf("Failed to clean up temporary directory %s on %s: %s", c.remoteWorkdir, c.Params.RemoteHost, err)
+	}
+}
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands/gitclone"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// A --context given as a URL instead of a directory.
+type remoteContext struct {
+	url string
+	// the URL without credentials, for logs and errors
+	redactedURL string
+	isArchive   bool
+	// git only, from the #ref:subdir fragment
+	ref    string
+	subdir string
+}
+
+// parseRemoteContext parses a remote --context: an https:// git URL, optionally with #ref[:subdir],
+// or the https:// URL of a .tar.gz archive. Returns nil if the context is a local directory.
+func parseRemoteContext(context string) (*remoteContext, error) {
+	if strings.HasPrefix(context, "http://") {
+		return nil, errors.New("remote context must be an https:// URL")
+	}
+	if !strings.HasPrefix(context, "https://") {
+		return nil, nil
+	}
+
+	contextURL, fragment, _ := strings.Cut(context, "#")
+	parsedURL, err := url.Parse(contextURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing remote context URL: %w", err)
+	}
+	if parsedURL.Host == "" {
+		return nil, fmt.Errorf("remote context URL '%s' has no host", parsedURL.Redacted())
+	}
+	remote := &remoteContext{url: contextURL, redactedURL: parsedURL.Redacted()}
+
+	if strings.HasSuffix(parsedURL.Path, ".tar.gz") || strings.HasSuffix(parsedURL.Path, ".tgz") {
+		if fragment != "" {
+			return nil, fmt.Errorf("archive context '%s' doesn't support #ref:subdir", remote.redactedURL)
+		}
+		remote.isArchive = true
+		return remote, nil
+	}
+
+	remote.ref, remote.subdir, _ = strings.Cut(fragment, ":")
+	if remote.subdir != "" && !filepath.IsLocal(remote.subdir) {
+		return nil, fmt.Errorf("subdirectory '%s' of the remote context must be a relative path inside the repository", remote.subdir)
+	}
+	return remote, nil
+}
+
+// fetchRemoteContext fetches a remote --context into the temporary workdir
+// and points --context at the local copy, which is removed with the workdir after the build.
+func (c *Build) fetchRemoteContext() error {
+	remote, err := parseRemoteContext(c.Params.Context)
+	if err != nil || remote == nil {
+		return kbcerrors.NewValidationError(err)
+	}
+	if c.Params.Source != "" {
+		return kbcerrors.NewValidationError(errors.New("source must not be set with a remote context"))
+	}
+
+	if err := c.ensureTempWorkdirExists(); err != nil {
+		return err
+	}
+	contextDir := filepath.Join(c.tempWorkdir, "remote-context")
+
+	if remote.isArchive {
+		l.Logger.Infof("Downloading context archive %s", remote.redactedURL)
+		err = c.downloadContextArchive(remote.url, contextDir)
+	} else {
+		l.Logger.Infof("Cloning context repository %s", remote.redactedURL)
+		err = c.cloneContextRepository(remote, contextDir)
+	}
+	if err != nil {
+		return fmt.Errorf("fetching remote context %s: %w", remote.redactedURL, err)
+	}
+
+	c.Params.Context = filepath.Join(contextDir, remote.subdir)
+	l.Logger.Debugf("Using %s as the context directory", c.Params.Context)
+	return nil
+}
+
+func (c *Build) cloneContextRepository(remote *remoteContext, contextDir string) error {
+	params := &gitclone.Params{
+		URL:                remote.url,
+		Revision:           remote.ref,
+		Depth:              1,
+		ShortCommitLength:  7,
+		SSLVerify:          true,
+		OutputDir:          contextDir,
+		RetryMaxAttempts:   10,
+		BasicAuthDirectory: c.Params.GitBasicAuthDirectory,
+	}
+	if c.cloneGitContext != nil {
+		return c.cloneGitContext(params)
+	}
+	gitClone := &gitclone.GitClone{Params: params, ResultsWriter: common.NewResultsWriter()}
+	return gitClone.Clone()
+}
+
+func (c *Build) downloadContextArchive(archiveURL, contextDir string) error {
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Get(archiveURL) //nolint:gosec // URL given by the user
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	archivePath := filepath.Join(c.tempWorkdir, "remote-context.tar.gz")
+	archive, err := os.Create(archivePath) //nolint:gosec // path in the temporary workdir
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(archive, resp.Body)
+	if closeErr := archive.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Mkdir(contextDir, 0755); err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(contextDir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+	return common.ExtractTarball(root, archivePath, true)
+}
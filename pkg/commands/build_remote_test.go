@@ -0,0 +1,332 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_Build_validateRemoteParams(t *testing.T) {
+	sshKey := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(sshKey, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(knownHosts, []byte("arm64.example.com ssh-ed25519 AAAA\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		params      BuildParams
+		platforms   []string
+		errExpected string
+	}{
+		{
+			name:   "should accept local build",
+			params: BuildParams{},
+		},
+		{
+			name:   "should accept remote host with key",
+			params: BuildParams{RemoteHost: "builder@arm64.example.com", RemoteKnownHosts: knownHosts, RemoteSshKey: sshKey},
+		},
+		{
+			name:   "should accept remote push",
+			params: BuildParams{RemoteHost: "arm64.example.com", RemoteKnownHosts: knownHosts, RemotePush: true, Push: true},
+		},
+		{
+			name:        "should reject remote key without host",
+			params:      BuildParams{RemoteSshKey: sshKey},
+			errExpected: "remote-ssh-key, remote-known-hosts and remote-push require remote-host",
+		},
+		{
+			name:        "should reject remote push without host",
+			params:      BuildParams{RemotePush: true, Push: true},
+			errExpected: "remote-ssh-key, remote-known-hosts and remote-push require remote-host",
+		},
+		{
+			name:        "should reject known hosts without host",
+			params:      BuildParams{RemoteKnownHosts: knownHosts},
+			errExpected: "remote-ssh-key, remote-known-hosts and remote-push require remote-host",
+		},
+		{
+			name:        "should require known hosts",
+			params:      BuildParams{RemoteHost: "arm64.example.com"},
+			errExpected: "remote-known-hosts is required with remote-host",
+		},
+		{
+			name:        "should reject missing known hosts",
+			params:      BuildParams{RemoteHost: "arm64.example.com", RemoteKnownHosts: knownHosts + ".missing"},
+			errExpected: "remote-known-hosts:",
+		},
+		{
+			name:        "should reject host that looks like an option",
+			params:      BuildParams{RemoteHost: "-oProxyCommand=sh", RemoteKnownHosts: knownHosts},
+			errExpected: "remote-host '-oProxyCommand=sh' is invalid",
+		},
+		{
+			name:        "should reject host with a path",
+			params:      BuildParams{RemoteHost: "host:/tmp", RemoteKnownHosts: knownHosts},
+			errExpected: "remote-host 'host:/tmp' is invalid",
+		},
+		{
+			name:        "should reject missing key",
+			params:      BuildParams{RemoteHost: "host", RemoteKnownHosts: knownHosts, RemoteSshKey: sshKey + ".missing"},
+			errExpected: "remote-ssh-key:",
+		},
+		{
+			name:        "should reject multiple platforms",
+			params:      BuildParams{RemoteHost: "host", RemoteKnownHosts: knownHosts},
+			platforms:   []string{"linux/amd64", "linux/arm64"},
+			errExpected: "remote-host builds a single platform",
+		},
+		{
+			name:        "should reject hermetic",
+			params:      BuildParams{RemoteHost: "host", RemoteKnownHosts: knownHosts, Hermetic: true},
+			errExpected: "hermetic is not supported with remote-host",
+		},
+		{
+			name:        "should reject remote push without push",
+			params:      BuildParams{RemoteHost: "host", RemoteKnownHosts: knownHosts, RemotePush: true},
+			errExpected: "remote-push requires push",
+		},
+		{
+			name:        "should reject local outputs with remote push",
+			params:      BuildParams{RemoteHost: "host", RemoteKnownHosts: knownHosts, RemotePush: true, Push: true, SBOMOutput: "sbom.json"},
+			errExpected: "sbom-output is not supported with remote-host and remote-push",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &Build{Params: &tc.params, buildPlatforms: tc.platforms}
+
+			err := c.validateRemoteParams()
+
+			if tc.errExpected == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.errExpected)))
+			}
+		})
+	}
+}
+
+func Test_Build_buildImageRemotely(t *testing.T) {
+	g := NewWithT(t)
+	t.Setenv("REGISTRY_AUTH_FILE", "/auth/auth.json")
+
+	var remoteCommands [][]string
+	sshCli := &mockSshCli{
+		RunFunc: func(command []string, logOutput bool) (string, string, int, error) {
+			remoteCommands = append(remoteCommands, command)
+			if command[0] == "mktemp" {
+				return "/tmp/kbc-build-1234\n", "", 0, nil
+			}
+			return "", "", 0, nil
+		},
+	}
+	var copiedPaths []string
+	sshCli.CopyToFunc = func(localPaths []string, remoteDir string) error {
+		g.Expect(remoteDir).To(Equal("/tmp/kbc-build-1234"))
+		copiedPaths = localPaths
+		return nil
+	}
+	var remoteBuildArgs *cliWrappers.BuildahBuildArgs
+	remoteBuildahCli := &mockBuildahCli{
+		BuildFunc: func(args *cliWrappers.BuildahBuildArgs) error {
+			remoteBuildArgs = args
+			return nil
+		},
+	}
+
+	c := &Build{
+		Params: &BuildParams{
+			OutputRef:  "quay.io/org/app:v1",
+			Context:    "/src/app",
+			RemoteHost: "arm64.example.com",
+			RemotePush: true,
+		},
+		CliWrappers:       BuildCliWrappers{SshCli: sshCli, RemoteBuildahCli: remoteBuildahCli},
+		containerfilePath: "/src/app/Containerfile",
+		buildahSecrets:    []cliWrappers.BuildahSecret{{Src: "/secrets/token", Id: "token"}},
+	}
+
+	err := c.buildImageRemotely()
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(c.remoteWorkdir).To(Equal("/tmp/kbc-build-1234"))
+	g.Expect(copiedPaths).To(Equal([]string{"/src/app/Containerfile", "/src/app", "/auth/auth.json", "/secrets/token"}))
+	g.Expect(remoteBuildArgs.Containerfile).To(Equal("/tmp/kbc-build-1234/src/app/Containerfile"))
+	g.Expect(remoteBuildArgs.ContextDir).To(Equal("/tmp/kbc-build-1234/src/app"))
+	g.Expect(remoteBuildArgs.AuthFile).To(Equal("/tmp/kbc-build-1234/auth/auth.json"))
+	g.Expect(remoteBuildArgs.Secrets[0].Src).To(Equal("/tmp/kbc-build-1234/secrets/token"))
+	g.Expect(remoteBuildArgs.Tags).To(Equal([]string{"quay.io/org/app:v1"}))
+	// the image stays on the remote host with remote push
+	g.Expect(remoteCommands).To(HaveLen(1))
+}
+
+// Write an OCI layout with a single image to dir, returns the config digest.
+func writeOciLayout(t *testing.T, dir string) digest.Digest {
+	writeBlob := func(content []byte) digest.Digest {
+		d := digest.FromBytes(content)
+		blobDir := filepath.Join(dir, "blobs", d.Algorithm().String())
+		if err := os.MkdirAll(blobDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(blobDir, d.Encoded()), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	mustMarshal := func(v any) []byte {
+		content, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+
+	configDigest := writeBlob([]byte(`{"architecture":"arm64","os":"linux"}`))
+	manifestDigest := writeBlob(mustMarshal(specs.Manifest{
+		MediaType: specs.MediaTypeImageManifest,
+		Config:    specs.Descriptor{MediaType: specs.MediaTypeImageConfig, Digest: configDigest},
+	}))
+	index := mustMarshal(specs.Index{
+		MediaType: specs.MediaTypeImageIndex,
+		Manifests: []specs.Descriptor{{MediaType: specs.MediaTypeImageManifest, Digest: manifestDigest}},
+	})
+	if err := os.WriteFile(filepath.Join(dir, "index.json"), index, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return configDigest
+}
+
+func Test_Build_fetchRemoteImage(t *testing.T) {
+	g := NewWithT(t)
+
+	var remoteCommand []string
+	var configDigest digest.Digest
+	sshCli := &mockSshCli{
+		RunFunc: func(command []string, logOutput bool) (string, string, int, error) {
+			remoteCommand = command
+			return "", "", 0, nil
+		},
+		CopyFromFunc: func(remotePath, localPath string) error {
+			g.Expect(remotePath).To(Equal("/tmp/kbc-build-1234/image/"))
+			if err := os.MkdirAll(localPath, 0755); err != nil {
+				return err
+			}
+			configDigest = writeOciLayout(t, localPath)
+			return nil
+		},
+	}
+	var pulledImage, taggedImage string
+	var tags []string
+	buildahCli := &mockBuildahCli{
+		PullFunc: func(args *cliWrappers.BuildahPullArgs) error {
+			pulledImage = args.Image
+			return nil
+		},
+		TagFunc: func(image string, names ...string) error {
+			taggedImage = image
+			tags = names
+			return nil
+		},
+	}
+
+	c := &Build{
+		Params: &BuildParams{
+			OutputRef:      "quay.io/org/app:v1",
+			AdditionalTags: []string{"latest"},
+			RemoteHost:     "arm64.example.com",
+		},
+		CliWrappers:   BuildCliWrappers{SshCli: sshCli, BuildahCli: buildahCli},
+		tempWorkdir:   t.TempDir(),
+		remoteWorkdir: "/tmp/kbc-build-1234",
+	}
+
+	err := c.fetchRemoteImage()
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(remoteCommand).To(Equal([]string{"buildah", "push", "quay.io/org/app:v1", "oci:/tmp/kbc-build-1234/image"}))
+	g.Expect(pulledImage).To(Equal("oci:" + filepath.Join(c.tempWorkdir, "remote-image")))
+	g.Expect(taggedImage).To(Equal(configDigest.Encoded()))
+	g.Expect(tags).To(Equal([]string{"quay.io/org/app:v1", "quay.io/org/app:latest"}))
+}
+
+func Test_ociLayoutImageId(t *testing.T) {
+	t.Run("should error on layout with several images", func(t *testing.T) {
+		g := NewWithT(t)
+		dir := t.TempDir()
+		g.Expect(os.WriteFile(filepath.Join(dir, "index.json"), []byte(`{"schemaVersion":2,"manifests":[]}`), 0644)).To(Succeed())
+
+		_, err := ociLayoutImageId(dir)
+
+		g.Expect(err).To(MatchError(ContainSubstring("expected one image")))
+	})
+
+	t.Run("should error on missing manifest", func(t *testing.T) {
+		g := NewWithT(t)
+		dir := t.TempDir()
+		index := `{"schemaVersion":2,"manifests":[{"digest":"` + digest.FromString("missing").String() + `"}]}`
+		g.Expect(os.WriteFile(filepath.Join(dir, "index.json"), []byte(index), 0644)).To(Succeed())
+
+		_, err := ociLayoutImageId(dir)
+
+		g.Expect(err).To(MatchError(ContainSubstring("reading the image manifest")))
+	})
+}
+
+func Test_Build_pushImageRemotely(t *testing.T) {
+	g := NewWithT(t)
+	cliWrappers.DisableRetryer = true
+	t.Cleanup(func() { cliWrappers.DisableRetryer = false })
+	t.Setenv("REGISTRY_AUTH_FILE", "/auth/auth.json")
+
+	var pushed [][]string
+	sshCli := &mockSshCli{
+		RunFunc: func(command []string, logOutput bool) (string, string, int, error) {
+			switch command[0] {
+			case "buildah":
+				pushed = append(pushed, command)
+				return "", "", 0, nil
+			case "cat":
+				image := pushed[len(pushed)-1][len(pushed[len(pushed)-1])-1]
+				return "sha256:" + strings.Repeat(image[len(image)-1:], 4) + "\n", "", 0, nil
+			}
+			return "", "", 0, nil
+		},
+	}
+
+	c := &Build{
+		Params: &BuildParams{
+			OutputRef:      "quay.io/org/app:v1",
+			AdditionalTags: []string{"latest"},
+			RemoteHost:     "arm64.example.com",
+			DestTLSVerify:  true,
+		},
+		CliWrappers:   BuildCliWrappers{SshCli: sshCli},
+		remoteWorkdir: "/tmp/kbc-build-1234",
+	}
+
+	imageDigest, err := c.pushImageRemotely()
+
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(imageDigest).To(Equal("sha256:1111"))
+	g.Expect(pushed).To(Equal([][]string{
+		{"buildah", "push", "--digestfile", "/tmp/kbc-build-1234/digest", "--tls-verify=true",
+			"--authfile", "/tmp/kbc-build-1234/auth/auth.json", "quay.io/org/app:v1"},
+		{"buildah", "push", "--digestfile", "/tmp/kbc-build-1234/digest", "--tls-verify=true",
+			"--authfile", "/tmp/kbc-build-1234/auth/auth.json", "quay.io/org/app:latest"},
+	}))
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+)
+
+// Check that the user-provided host paths, read or written by the build, resolve inside --chroot-workdir.
+// The containerfile is checked once it's found, see detectContainerfile. The --workdir-mount
+// is a path in the build container, its host side is the context directory.
+func (c *Build) validateSandboxParams() error {
+	if c.Params.ChrootWorkdir == "" {
+		return nil
+	}
+	info, err := os.Stat(c.Params.ChrootWorkdir)
+	if err != nil {
+		return fmt.Errorf("chroot-workdir: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("chroot-workdir '%s' is not a directory", c.Params.ChrootWorkdir)
+	}
+	if c.sandboxRoot, err = common.ResolvePath(c.Params.ChrootWorkdir); err != nil {
+		return fmt.Errorf("resolving chroot-workdir: %w", err)
+	}
+
+	paths := [][2]string{
+		{"source", c.Params.Source},
+		{"context", c.effectiveContextDir()},
+		{"containerfile-json", c.Params.ContainerfileJson},
+		{"build-args-file", c.Params.BuildArgsFile},
+		{"annotations-file", c.Params.AnnotationsFile},
+		{"prefetch-dir", c.Params.PrefetchDir},
+		{"prefetch-dir-copy", c.Params.PrefetchDirCopy},
+		{"rhsm-activation-key-dir", c.Params.RHSMActivationKeyDir},
+		{"rhsm-activation-key", c.Params.RHSMActivationKey},
+		{"rhsm-org", c.Params.RHSMOrg},
+		{"rhsm-entitlements", c.Params.RHSMEntitlements},
+		{"cert-dir", c.Params.CertDir},
+		{"ca-bundle-file", c.Params.CABundleFile},
+		{"git-basic-auth-directory", c.Params.GitBasicAuthDirectory},
+		{"storage-dir", c.Params.StorageDir},
+		{"remote-ssh-key", c.Params.RemoteSshKey},
+		{"remote-known-hosts", c.Params.RemoteKnownHosts},
+		{"checkpoint-file", c.Params.CheckpointFile},
+		{"pre-build-script", c.Params.PreBuildScript},
+		{"post-build-script", c.Params.PostBuildScript},
+		// Written by the build
+		{"sbom-output", c.Params.SBOMOutput},
+		{"containerfile-json-output", c.Params.ContainerfileJsonOutput},
+		{"syft-source-output", c.Params.SyftSourceOutput},
+		{"syft-image-output", c.Params.SyftImageOutput},
+		{"builder-metadata-output", c.Params.BuilderMetadataOutput},
+		{"resolved-base-images-output", c.Params.ResolvedBaseImagesOutput},
+	}
+	if c.Params.Output != "" {
+		// transport:path, see validateBuildOutput
+		_, outputPath, _ := strings.Cut(c.Params.Output, ":")
+		paths = append(paths, [2]string{"output", outputPath})
+	}
+	for _, yumReposD := range c.Params.YumReposDSources {
+		paths = append(paths, [2]string{"yum-repos-d-sources", yumReposD})
+	}
+	secretDirs, err := parseSecretDirs(c.Params.SecretDirs)
+	if err != nil {
+		return fmt.Errorf("parsing --secret-dirs: %w", err)
+	}
+	for _, secretDir := range secretDirs {
+		paths = append(paths, [2]string{"secret dir", secretDir.src})
+	}
+	volumes, err := parseVolumes(c.Params.Volumes)
+	if err != nil {
+		return fmt.Errorf("parsing --volumes: %w", err)
+	}
+	for _, volume := range volumes {
+		paths = append(paths, [2]string{"volume", volume.volume.HostDir})
+	}
+
+	for _, path := range paths {
+		if err := c.checkSandboxed(path[0], path[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return an error if the path doesn't resolve inside --chroot-workdir. Paths in the temporary
+// workdir are allowed, they are created by the build itself, e.g. a fetched remote context.
+func (c *Build) checkSandboxed(name, path string) error {
+	if c.sandboxRoot == "" || path == "" {
+		return nil
+	}
+	if c.tempWorkdir != "" {
+		if tempWorkdir, err := common.ResolvePath(c.tempWorkdir); err == nil && common.CheckPathInside(tempWorkdir, name, path) == nil {
+			return nil
+		}
+	}
+	return common.CheckPathInside(c.sandboxRoot, name, path)
+}
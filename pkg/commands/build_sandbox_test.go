@@ -0,0 +1,354 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/testutil"
+)
+
+func Test_Build_validateSandboxParams(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	workspace := filepath.Join(dir, "workspace")
+	testutil.WriteFileTree(t, dir, map[string]string{
+		"workspace/source/Containerfile": "FROM scratch\n",
+		"workspace/build-args":           "A=1\n",
+		"workspace/secrets/token":        "secret",
+		"outside/build-args":             "A=1\n",
+		"outside/secrets/token":          "secret",
+	})
+	if err := os.Symlink(filepath.Join(dir, "outside", "secrets"), filepath.Join(workspace, "linked-secrets")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		params      BuildParams
+		errExpected string
+	}{
+		{
+			name: "should accept paths inside the workspace",
+			params: BuildParams{
+				ChrootWorkdir: workspace,
+				Context:       filepath.Join(workspace, "source"),
+				BuildArgsFile: filepath.Join(workspace, "build-args"),
+				SecretDirs:    []string{"src=" + filepath.Join(workspace, "secrets") + ",name=creds"},
+				Volumes:       []string{filepath.Join(workspace, "cache") + ":/cache:optional=true"},
+			},
+		},
+		{
+			name:   "should accept anything without chroot-workdir",
+			params: BuildParams{Context: dir, BuildArgsFile: filepath.Join(dir, "outside", "build-args")},
+		},
+		{
+			name:        "should reject context outside",
+			params:      BuildParams{ChrootWorkdir: workspace, Context: dir},
+			errExpected: "context '" + dir + "' resolves to '" + dir + "', outside of '" + workspace + "'",
+		},
+		{
+			name: "should reject build args file outside",
+			params: BuildParams{
+				ChrootWorkdir: workspace,
+				Context:       filepath.Join(workspace, "source"),
+				BuildArgsFile: filepath.Join(workspace, "..", "outside", "build-args"),
+			},
+			errExpected: "build-args-file '" + filepath.Join(dir, "outside", "build-args") + "' resolves to",
+		},
+		{
+			name: "should reject secret dir symlinked outside",
+			params: BuildParams{
+				ChrootWorkdir: workspace,
+				Context:       filepath.Join(workspace, "source"),
+				SecretDirs:    []string{filepath.Join(workspace, "linked-secrets")},
+			},
+			errExpected: "secret dir '" + filepath.Join(workspace, "linked-secrets") + "' resolves to '" + filepath.Join(dir, "outside", "secrets") + "'",
+		},
+		{
+			name: "should reject volume outside",
+			params: BuildParams{
+				ChrootWorkdir: workspace,
+				Context:       filepath.Join(workspace, "source"),
+				Volumes:       []string{"/etc:/host-etc"},
+			},
+			errExpected: "volume '/etc' resolves to '/etc', outside of",
+		},
+		{
+			name: "should accept the other path params inside the workspace",
+			params: BuildParams{
+				ChrootWorkdir:            workspace,
+				Context:                  filepath.Join(workspace, "source"),
+				PrefetchDir:              filepath.Join(workspace, "prefetch"),
+				PrefetchDirCopy:          filepath.Join(workspace, "prefetch-copy"),
+				YumReposDSources:         []string{filepath.Join(workspace, "yum.repos.d")},
+				RHSMActivationKeyDir:     filepath.Join(workspace, "activation-key"),
+				CertDir:                  filepath.Join(workspace, "certs"),
+				CABundleFile:             filepath.Join(workspace, "ca-bundle.crt"),
+				GitBasicAuthDirectory:    filepath.Join(workspace, "git-auth"),
+				StorageDir:               filepath.Join(workspace, "storage"),
+				RemoteSshKey:             filepath.Join(workspace, "id_rsa"),
+				RemoteKnownHosts:         filepath.Join(workspace, "known_hosts"),
+				CheckpointFile:           filepath.Join(workspace, "checkpoint.json"),
+				PreBuildScript:           filepath.Join(workspace, "pre-build.sh"),
+				PostBuildScript:          filepath.Join(workspace, "post-build.sh"),
+				WorkdirMount:             "/workdir",
+				RHSMActivationKey:        filepath.Join(workspace, "activation-key", "activationkey"),
+				RHSMOrg:                  filepath.Join(workspace, "activation-key", "org"),
+				RHSMEntitlements:         filepath.Join(workspace, "entitlements"),
+				Output:                   "oci-archive:" + filepath.Join(workspace, "image.tar"),
+				SBOMOutput:               filepath.Join(workspace, "sbom.json"),
+				ContainerfileJsonOutput:  filepath.Join(workspace, "containerfile.json"),
+				SyftSourceOutput:         filepath.Join(workspace, "syft-source.json"),
+				SyftImageOutput:          filepath.Join(workspace, "syft-image.json"),
+				BuilderMetadataOutput:    filepath.Join(workspace, "builder-metadata.json"),
+				ResolvedBaseImagesOutput: filepath.Join(workspace, "base-images.txt"),
+			},
+		},
+		{
+			name: "should reject prefetch-dir outside",
+			params: BuildParams{
+				ChrootWorkdir: workspace,
+				Context:       filepath.Join(workspace, "source"),
+				PrefetchDir:   "/etc",
+			},
+			errExpected: "prefetch-dir '",
+		},
+		{
+			name: "should reject prefetch-dir-copy outside",
+			params: BuildParams{
+				ChrootWorkdir:   workspace,
+				Context:         filepath.Join(workspace, "source"),
+				PrefetchDirCopy: filepath.Join(dir, "outside", "prefetch-copy"),
+			},
+			errExpected: "prefetch-dir-copy '",
+		},
+		{
+			name: "should reject yum-repos-d-sources outside",
+			params: BuildParams{
+				ChrootWorkdir:    workspace,
+				Context:          filepath.Join(workspace, "source"),
+				YumReposDSources: []string{filepath.Join(workspace, "yum.repos.d"), "/etc/yum.repos.d"},
+			},
+			errExpected: "yum-repos-d-sources '",
+		},
+		{
+			name: "should reject rhsm-activation-key-dir outside",
+			params: BuildParams{
+				ChrootWorkdir:        workspace,
+				Context:              filepath.Join(workspace, "source"),
+				RHSMActivationKeyDir: "/etc",
+			},
+			errExpected: "rhsm-activation-key-dir '",
+		},
+		{
+			name: "should reject cert-dir outside",
+			params: BuildParams{
+				ChrootWorkdir: workspace,
+				Context:       filepath.Join(workspace, "source"),
+				CertDir:       "/etc/pki",
+			},
+			errExpected: "cert-dir '",
+		},
+		{
+			name: "should reject ca-bundle-file outside",
+			params: BuildParams{
+				ChrootWorkdir: workspace,
+				Context:       filepath.Join(workspace, "source"),
+				CABundleFile:  "/etc/pki/tls/certs/ca-bundle.crt",
+			},
+			errExpected: "ca-bundle-file '",
+		},
+		{
+			name: "should reject git-basic-auth-directory outside",
+			params: BuildParams{
+				ChrootWorkdir:         workspace,
+				Context:               filepath.Join(workspace, "source"),
+				GitBasicAuthDirectory: filepath.Join(workspace, "..", "outside"),
+			},
+			errExpected: "git-basic-auth-directory '",
+		},
+		{
+			name: "should reject storage-dir outside",
+			params: BuildParams{
+				ChrootWorkdir: workspace,
+				Context:       filepath.Join(workspace, "source"),
+				StorageDir:    "/var/lib/containers/storage",
+			},
+			errExpected: "storage-dir '",
+		},
+		{
+			name: "should reject remote-known-hosts outside",
+			params: BuildParams{
+				ChrootWorkdir:    workspace,
+				Context:          filepath.Join(workspace, "source"),
+				RemoteKnownHosts: "/root/.ssh/known_hosts",
+			},
+			errExpected: "remote-known-hosts '",
+		},
+		{
+			name: "should reject remote-ssh-key outside",
+			params: BuildParams{
+				ChrootWorkdir: workspace,
+				Context:       filepath.Join(workspace, "source"),
+				RemoteSshKey:  "/root/.ssh/id_rsa",
+			},
+			errExpected: "remote-ssh-key '",
+		},
+		{
+			name: "should reject checkpoint-file outside",
+			params: BuildParams{
+				ChrootWorkdir:  workspace,
+				Context:        filepath.Join(workspace, "source"),
+				CheckpointFile: filepath.Join(dir, "checkpoint.json"),
+			},
+			errExpected: "checkpoint-file '",
+		},
+		{
+			name: "should reject pre-build-script outside",
+			params: BuildParams{
+				ChrootWorkdir:  workspace,
+				Context:        filepath.Join(workspace, "source"),
+				PreBuildScript: "/usr/bin/true",
+			},
+			errExpected: "pre-build-script '",
+		},
+		{
+			name: "should reject post-build-script outside",
+			params: BuildParams{
+				ChrootWorkdir:   workspace,
+				Context:         filepath.Join(workspace, "source"),
+				PostBuildScript: filepath.Join(workspace, "linked-secrets", "token"),
+			},
+			errExpected: "post-build-script '",
+		},
+		{
+			name: "should reject rhsm-activation-key outside",
+			params: BuildParams{
+				ChrootWorkdir:     workspace,
+				Context:           filepath.Join(workspace, "source"),
+				RHSMActivationKey: "/etc/rhsm/activationkey",
+			},
+			errExpected: "rhsm-activation-key '",
+		},
+		{
+			name: "should reject rhsm-org outside",
+			params: BuildParams{
+				ChrootWorkdir: workspace,
+				Context:       filepath.Join(workspace, "source"),
+				RHSMOrg:       "/etc/rhsm/org",
+			},
+			errExpected: "rhsm-org '",
+		},
+		{
+			name: "should reject rhsm-entitlements outside",
+			params: BuildParams{
+				ChrootWorkdir:    workspace,
+				Context:          filepath.Join(workspace, "source"),
+				RHSMEntitlements: "/etc/pki/entitlement",
+			},
+			errExpected: "rhsm-entitlements '",
+		},
+		{
+			name: "should reject output outside",
+			params: BuildParams{
+				ChrootWorkdir: workspace,
+				Context:       filepath.Join(workspace, "source"),
+				Output:        "oci:/var/lib/images",
+			},
+			errExpected: "output '",
+		},
+		{
+			name: "should reject sbom-output outside",
+			params: BuildParams{
+				ChrootWorkdir: workspace,
+				Context:       filepath.Join(workspace, "source"),
+				SBOMOutput:    "/tmp/sbom.json",
+			},
+			errExpected: "sbom-output '",
+		},
+		{
+			name: "should reject containerfile-json-output outside",
+			params: BuildParams{
+				ChrootWorkdir:           workspace,
+				Context:                 filepath.Join(workspace, "source"),
+				ContainerfileJsonOutput: filepath.Join(dir, "containerfile.json"),
+			},
+			errExpected: "containerfile-json-output '",
+		},
+		{
+			name: "should reject syft-source-output outside",
+			params: BuildParams{
+				ChrootWorkdir:    workspace,
+				Context:          filepath.Join(workspace, "source"),
+				SyftSourceOutput: filepath.Join(workspace, "..", "syft-source.json"),
+			},
+			errExpected: "syft-source-output '",
+		},
+		{
+			name: "should reject syft-image-output outside",
+			params: BuildParams{
+				ChrootWorkdir:   workspace,
+				Context:         filepath.Join(workspace, "source"),
+				SyftImageOutput: filepath.Join(workspace, "linked-secrets", "syft-image.json"),
+			},
+			errExpected: "syft-image-output '",
+		},
+		{
+			name: "should reject builder-metadata-output outside",
+			params: BuildParams{
+				ChrootWorkdir:         workspace,
+				Context:               filepath.Join(workspace, "source"),
+				BuilderMetadataOutput: "/etc/builder-metadata.json",
+			},
+			errExpected: "builder-metadata-output '",
+		},
+		{
+			name: "should reject resolved-base-images-output outside",
+			params: BuildParams{
+				ChrootWorkdir:            workspace,
+				Context:                  filepath.Join(workspace, "source"),
+				ResolvedBaseImagesOutput: "/etc/base-images.txt",
+			},
+			errExpected: "resolved-base-images-output '",
+		},
+		{
+			name:        "should reject missing workspace",
+			params:      BuildParams{ChrootWorkdir: filepath.Join(dir, "missing"), Context: filepath.Join(workspace, "source")},
+			errExpected: "chroot-workdir: stat " + filepath.Join(dir, "missing") + ": no such file or directory",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &Build{Params: &tc.params}
+
+			err := c.validateSandboxParams()
+
+			if tc.errExpected == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.errExpected)))
+			}
+		})
+	}
+}
+
+func Test_Build_checkSandboxed(t *testing.T) {
+	g := NewWithT(t)
+	workspace, err := filepath.EvalSymlinks(t.TempDir())
+	g.Expect(err).ToNot(HaveOccurred())
+	tempWorkdir := t.TempDir()
+
+	c := &Build{Params: &BuildParams{ChrootWorkdir: workspace, Context: workspace}, tempWorkdir: tempWorkdir}
+	g.Expect(c.validateSandboxParams()).To(Succeed())
+
+	// Created by the build itself, e.g. the Containerfile rendered from --containerfile-json
+	g.Expect(c.checkSandboxed("containerfile", filepath.Join(tempWorkdir, "Containerfile"))).To(Succeed())
+	g.Expect(c.checkSandboxed("containerfile", "/etc/passwd")).To(MatchError(ContainSubstring("containerfile '/etc/passwd' resolves to")))
+}
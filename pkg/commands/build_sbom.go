@@ -0,0 +1,243 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common/sbom"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// Write the SBOM of the built image to --sbom-output. Merges the syft scans with the SBOM
+// of the prefetched dependencies, which syft can't see (e.g. the sources of a Go module
+// that got compiled into a binary). Must be called after runSyftScans.
+func (c *Build) writeSBOM(prefetchResources *prefetchResources) error {
+	if c.syftImageSbom == "" {
+		return fmt.Errorf("writing SBOM: the built image was not scanned")
+	}
+	sboms := []string{c.syftImageSbom}
+	if c.syftSourceSbom != "" {
+		sboms = append(sboms, c.syftSourceSbom)
+	}
+	if prefetchResources != nil && prefetchResources.sbomFile != "" {
+		sboms = append(sboms, prefetchResources.sbomFile)
+	}
+
+	l.Logger.Infof("Merging SBOMs: %v", sboms)
+	merged, err := mergeSBOMFiles(c.Params.SBOMFormat, sboms)
+	if err != nil {
+		return fmt.Errorf("merging SBOMs: %w", err)
+	}
+	if err := common.WriteResultFile(c.Params.SBOMOutput, merged); err != nil {
+		return fmt.Errorf("writing SBOM: %w", err)
+	}
+	l.Logger.Infof("SBOM written to %s", c.Params.SBOMOutput)
+
+	c.Results.SBOMPath = c.Params.SBOMOutput
+	c.Results.SBOMDigest = fmt.Sprintf("sha256:%x", sha256.Sum256(merged))
+	return nil
+}
+
+// Merge the SBOMs into the first one, which describes the image. The SBOMs must all be
+// in the same format (cyclonedx or spdx) and are merged as generic JSON, so that
+// the fields this code doesn't know about are preserved.
+func mergeSBOMFiles(format string, paths []string) ([]byte, error) {
+	var docs []sbom.Document
+	for _, path := range paths {
+		doc, err := sbom.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if detected := sbom.Format(doc); detected != format {
+			return nil, fmt.Errorf("%s is not a %s SBOM", path, format)
+		}
+		docs = append(docs, doc)
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no SBOMs to merge")
+	}
+	return json.MarshalIndent(mergeSBOMDocuments(format, docs), "", "  ")
+}
+
+// Merge the documents of the given format into the first one.
+func mergeSBOMDocuments(format string, docs []sbom.Document) sbom.Document {
+	merged := docs[0]
+	for _, doc := range docs[1:] {
+		if format == "cyclonedx" {
+			mergeCycloneDX(merged, doc)
+		} else {
+			mergeSPDX(merged, doc)
+		}
+	}
+	return merged
+}
+
+// Add the components and dependencies of doc that target doesn't have yet. A component with
+// the purl of a target component is the same package, e.g. found by both syft and hermeto,
+// the dependencies of doc refer to the target component instead.
+func mergeCycloneDX(target, doc sbom.Document) {
+	componentKey := func(component map[string]any) string {
+		if ref, ok := component["bom-ref"].(string); ok && ref != "" {
+			return "ref:" + ref
+		}
+		if purl, ok := component["purl"].(string); ok && purl != "" {
+			return "purl:" + purl
+		}
+		return fmt.Sprintf("name:%v@%v", component["name"], component["version"])
+	}
+
+	purlRefs := make(map[string]string)
+	for _, item := range sbom.List(target, "components") {
+		if component, ok := item.(map[string]any); ok {
+			purl, _ := component["purl"].(string)
+			ref, _ := component["bom-ref"].(string)
+			if purl != "" && ref != "" {
+				purlRefs[purl] = ref
+			}
+		}
+	}
+	refReplacements := make(map[string]string)
+	var components []any
+	for _, item := range sbom.List(doc, "components") {
+		component, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		purl, _ := component["purl"].(string)
+		if targetRef, found := purlRefs[purl]; found && purl != "" {
+			if ref, ok := component["bom-ref"].(string); ok && ref != targetRef {
+				refReplacements[ref] = targetRef
+			}
+			continue
+		}
+		components = append(components, component)
+	}
+	mergeSBOMList(target, sbom.Document{"components": components}, "components", componentKey)
+
+	var dependencies []any
+	for _, item := range sbom.List(doc, "dependencies") {
+		dependency, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, replaced := refReplacements[fmt.Sprint(dependency["ref"])]; replaced {
+			// The target component has its own dependencies
+			continue
+		}
+		if dependsOn, ok := dependency["dependsOn"].([]any); ok && len(refReplacements) > 0 {
+			replacedDependsOn := make([]any, 0, len(dependsOn))
+			for _, ref := range dependsOn {
+				if targetRef, found := refReplacements[fmt.Sprint(ref)]; found {
+					ref = targetRef
+				}
+				replacedDependsOn = append(replacedDependsOn, ref)
+			}
+			dependency = maps.Clone(dependency)
+			dependency["dependsOn"] = replacedDependsOn
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	mergeSBOMList(target, sbom.Document{"dependencies": dependencies}, "dependencies", func(dependency map[string]any) string {
+		return fmt.Sprint(dependency["ref"])
+	})
+}
+
+// Add the packages, files and licenses of doc that target doesn't have yet. The elements
+// doc describes become contained in the elements target describes (the image).
+// A package with the purl of a target package is the same package, the relationships of doc
+// refer to the target package instead.
+func mergeSPDX(target, doc sbom.Document) {
+	purlIds := make(map[string]string)
+	for _, item := range sbom.List(target, "packages") {
+		if pkg, ok := item.(map[string]any); ok {
+			if purl := sbom.SPDXPackagePurl(pkg); purl != "" {
+				purlIds[purl] = fmt.Sprint(pkg["SPDXID"])
+			}
+		}
+	}
+	idReplacements := make(map[string]string)
+	var packages []any
+	for _, item := range sbom.List(doc, "packages") {
+		pkg, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if targetId, found := purlIds[sbom.SPDXPackagePurl(pkg)]; found && sbom.SPDXPackagePurl(pkg) != "" {
+			if id := fmt.Sprint(pkg["SPDXID"]); id != targetId {
+				idReplacements[id] = targetId
+			}
+			continue
+		}
+		packages = append(packages, pkg)
+	}
+	mergeSBOMList(target, sbom.Document{"packages": packages}, "packages", func(pkg map[string]any) string {
+		return fmt.Sprint(pkg["SPDXID"])
+	})
+	mergeSBOMList(target, doc, "files", func(file map[string]any) string {
+		return fmt.Sprint(file["SPDXID"])
+	})
+	mergeSBOMList(target, doc, "hasExtractedLicensingInfos", func(license map[string]any) string {
+		return fmt.Sprint(license["licenseId"])
+	})
+
+	root := sbom.SPDXDescribedElement(target)
+	var relationships []any
+	for _, item := range sbom.List(doc, "relationships") {
+		relationship, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if relationship["spdxElementId"] == sbom.SPDXDocumentID && relationship["relationshipType"] == "DESCRIBES" {
+			if root == "" {
+				continue
+			}
+			relationship = map[string]any{
+				"spdxElementId":      root,
+				"relationshipType":   "CONTAINS",
+				"relatedSpdxElement": relationship["relatedSpdxElement"],
+			}
+		}
+		if len(idReplacements) > 0 {
+			relationship = maps.Clone(relationship)
+			for _, field := range []string{"spdxElementId", "relatedSpdxElement"} {
+				if targetId, found := idReplacements[fmt.Sprint(relationship[field])]; found {
+					relationship[field] = targetId
+				}
+			}
+			if relationship["spdxElementId"] == relationship["relatedSpdxElement"] {
+				continue
+			}
+		}
+		relationships = append(relationships, relationship)
+	}
+	mergeSBOMList(target, sbom.Document{"relationships": relationships}, "relationships", func(relationship map[string]any) string {
+		return fmt.Sprintf("%v %v %v", relationship["spdxElementId"], relationship["relationshipType"], relationship["relatedSpdxElement"])
+	})
+}
+
+// Append the items of doc[field] to target[field], skipping the ones with a key already present.
+func mergeSBOMList(target, doc sbom.Document, field string, key func(map[string]any) string) {
+	targetList := sbom.List(target, field)
+	seen := make(map[string]bool, len(targetList))
+	for _, item := range targetList {
+		if object, ok := item.(map[string]any); ok {
+			seen[key(object)] = true
+		}
+	}
+	added := false
+	for _, item := range sbom.List(doc, field) {
+		object, ok := item.(map[string]any)
+		if !ok || seen[key(object)] {
+			continue
+		}
+		seen[key(object)] = true
+		targetList = append(targetList, object)
+		added = true
+	}
+	if added {
+		target[field] = targetList
+	}
+}
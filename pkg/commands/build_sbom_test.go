@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func writeSBOMFile(t *testing.T, g Gomega, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	g.Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	return path
+}
+
+func Test_mergeSBOMFiles(t *testing.T) {
+	t.Run("should merge cyclonedx components and dependencies", func(t *testing.T) {
+		g := NewWithT(t)
+		image := writeSBOMFile(t, g, "image.json", `{
+			"bomFormat": "CycloneDX",
+			"metadata": {"component": {"name": "quay.io/org/app"}},
+			"components": [{"bom-ref": "pkg:rpm/bash", "purl": "pkg:rpm/bash", "name": "bash"}],
+			"dependencies": [{"ref": "pkg:rpm/bash", "dependsOn": []}]
+		}`)
+		prefetch := writeSBOMFile(t, g, "bom.json", `{
+			"bomFormat": "CycloneDX",
+			"metadata": {"component": {"name": "hermeto"}},
+			"components": [
+				{"bom-ref": "pkg:rpm/bash", "purl": "pkg:rpm/bash", "name": "bash"},
+				{"purl": "pkg:golang/example.com/lib@v1", "name": "example.com/lib"}
+			]
+		}`)
+
+		merged, err := mergeSBOMFiles("cyclonedx", []string{image, prefetch})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		var doc map[string]any
+		g.Expect(json.Unmarshal(merged, &doc)).To(Succeed())
+		g.Expect(doc["metadata"]).To(HaveKeyWithValue("component", HaveKeyWithValue("name", "quay.io/org/app")))
+		g.Expect(doc["components"]).To(HaveLen(2))
+		g.Expect(doc["components"]).To(ContainElement(HaveKeyWithValue("purl", "pkg:golang/example.com/lib@v1")))
+		g.Expect(doc["dependencies"]).To(HaveLen(1))
+	})
+
+	t.Run("should merge spdx packages under the described image", func(t *testing.T) {
+		g := NewWithT(t)
+		image := writeSBOMFile(t, g, "image.json", `{
+			"spdxVersion": "SPDX-2.3",
+			"SPDXID": "SPDXRef-DOCUMENT",
+			"packages": [{"SPDXID": "SPDXRef-Image"}, {"SPDXID": "SPDXRef-Package-bash"}],
+			"relationships": [
+				{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Image"},
+				{"spdxElementId": "SPDXRef-Image", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-Package-bash"}
+			],
+			"hasExtractedLicensingInfos": [{"licenseId": "LicenseRef-A"}]
+		}`)
+		prefetch := writeSBOMFile(t, g, "bom.json", `{
+			"spdxVersion": "SPDX-2.3",
+			"SPDXID": "SPDXRef-DOCUMENT",
+			"packages": [{"SPDXID": "SPDXRef-Package-bash"}, {"SPDXID": "SPDXRef-Package-lib"}],
+			"relationships": [
+				{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Package-lib"}
+			],
+			"hasExtractedLicensingInfos": [{"licenseId": "LicenseRef-A"}, {"licenseId": "LicenseRef-B"}]
+		}`)
+
+		merged, err := mergeSBOMFiles("spdx", []string{image, prefetch})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		var doc map[string]any
+		g.Expect(json.Unmarshal(merged, &doc)).To(Succeed())
+		g.Expect(doc["packages"]).To(HaveLen(3))
+		g.Expect(doc["hasExtractedLicensingInfos"]).To(HaveLen(2))
+		g.Expect(doc["relationships"]).To(HaveLen(3))
+		g.Expect(doc["relationships"]).To(ContainElement(map[string]any{
+			"spdxElementId":      "SPDXRef-Image",
+			"relationshipType":   "CONTAINS",
+			"relatedSpdxElement": "SPDXRef-Package-lib",
+		}))
+	})
+
+	t.Run("should deduplicate spdx packages by purl", func(t *testing.T) {
+		g := NewWithT(t)
+		purlRef := func(purl string) string {
+			return `[{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "` + purl + `"}]`
+		}
+		image := writeSBOMFile(t, g, "image.json", `{
+			"spdxVersion": "SPDX-2.3",
+			"SPDXID": "SPDXRef-DOCUMENT",
+			"packages": [
+				{"SPDXID": "SPDXRef-Image"},
+				{"SPDXID": "SPDXRef-A", "externalRefs": `+purlRef("pkg:rpm/bash@5")+`}
+			],
+			"relationships": [
+				{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Image"},
+				{"spdxElementId": "SPDXRef-Image", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-A"}
+			]
+		}`)
+		prefetch := writeSBOMFile(t, g, "bom.json", `{
+			"spdxVersion": "SPDX-2.3",
+			"SPDXID": "SPDXRef-DOCUMENT",
+			"packages": [
+				{"SPDXID": "SPDXRef-B", "externalRefs": `+purlRef("pkg:rpm/bash@5")+`},
+				{"SPDXID": "SPDXRef-C", "externalRefs": `+purlRef("pkg:rpm/glibc@2")+`}
+			],
+			"relationships": [
+				{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-B"},
+				{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-C"},
+				{"spdxElementId": "SPDXRef-B", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-C"}
+			]
+		}`)
+
+		merged, err := mergeSBOMFiles("spdx", []string{image, prefetch})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		var doc map[string]any
+		g.Expect(json.Unmarshal(merged, &doc)).To(Succeed())
+		g.Expect(doc["packages"]).To(HaveLen(3))
+		g.Expect(doc["packages"]).ToNot(ContainElement(HaveKeyWithValue("SPDXID", "SPDXRef-B")))
+		g.Expect(doc["relationships"]).To(ContainElement(map[string]any{
+			"spdxElementId":      "SPDXRef-A",
+			"relationshipType":   "DEPENDS_ON",
+			"relatedSpdxElement": "SPDXRef-C",
+		}))
+		g.Expect(doc["relationships"]).ToNot(ContainElement(HaveKeyWithValue("relatedSpdxElement", "SPDXRef-B")))
+	})
+
+	t.Run("should deduplicate cyclonedx components by purl", func(t *testing.T) {
+		g := NewWithT(t)
+		image := writeSBOMFile(t, g, "image.json", `{
+			"bomFormat": "CycloneDX",
+			"components": [{"bom-ref": "bash-1", "purl": "pkg:rpm/bash@5", "name": "bash"}]
+		}`)
+		prefetch := writeSBOMFile(t, g, "bom.json", `{
+			"bomFormat": "CycloneDX",
+			"components": [
+				{"bom-ref": "bash-2", "purl": "pkg:rpm/bash@5", "name": "bash"},
+				{"bom-ref": "app", "purl": "pkg:golang/example.com/app@v1", "name": "app"}
+			],
+			"dependencies": [
+				{"ref": "bash-2", "dependsOn": []},
+				{"ref": "app", "dependsOn": ["bash-2"]}
+			]
+		}`)
+
+		merged, err := mergeSBOMFiles("cyclonedx", []string{image, prefetch})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		var doc map[string]any
+		g.Expect(json.Unmarshal(merged, &doc)).To(Succeed())
+		g.Expect(doc["components"]).To(HaveLen(2))
+		g.Expect(doc["dependencies"]).To(Equal([]any{
+			map[string]any{"ref": "app", "dependsOn": []any{"bash-1"}},
+		}))
+	})
+
+	t.Run("should reject SBOMs in a different format", func(t *testing.T) {
+		g := NewWithT(t)
+		image := writeSBOMFile(t, g, "image.json", `{"spdxVersion": "SPDX-2.3"}`)
+		prefetch := writeSBOMFile(t, g, "bom.json", `{"bomFormat": "CycloneDX"}`)
+
+		_, err := mergeSBOMFiles("spdx", []string{image, prefetch})
+		g.Expect(err).To(MatchError(ContainSubstring("bom.json is not a spdx SBOM")))
+	})
+}
+
+func Test_Build_writeSBOM(t *testing.T) {
+	g := NewWithT(t)
+	sbomOutput := filepath.Join(t.TempDir(), "sbom.json")
+	prefetchSbom := writeSBOMFile(t, g, "bom.json", `{"bomFormat": "CycloneDX", "components": [{"purl": "pkg:npm/lib@1"}]}`)
+
+	c := &Build{
+		Params: &BuildParams{
+			Context:    t.TempDir(),
+			OutputRef:  "localhost/test:latest",
+			SBOMFormat: "cyclonedx",
+			SBOMOutput: sbomOutput,
+		},
+		CliWrappers: BuildCliWrappers{
+			BuildahCli: &mockBuildahCli{
+				FromFunc:  func(image string) (string, error) { return "ctr", nil },
+				MountFunc: func(container string) (string, error) { return "/mnt", nil },
+				RmFunc:    func(container string) error { return nil },
+			},
+			SyftCli: &mockSyftCli{
+				ScanFunc: func(args *cliwrappers.SyftScanArgs) (string, error) {
+					g.Expect(args.Source).To(Equal("dir:/mnt"))
+					return "", os.WriteFile(args.OutputFile, []byte(`{"bomFormat": "CycloneDX", "components": [{"purl": "pkg:rpm/bash"}]}`), 0644)
+				},
+			},
+		},
+	}
+	defer c.cleanup()
+
+	g.Expect(c.runSyftScans()).To(Succeed())
+	g.Expect(c.syftImageSbom).To(HavePrefix(c.tempWorkdir))
+
+	g.Expect(c.writeSBOM(&prefetchResources{sbomFile: prefetchSbom})).To(Succeed())
+
+	content, err := os.ReadFile(sbomOutput)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).To(ContainSubstring("pkg:rpm/bash"))
+	g.Expect(string(content)).To(ContainSubstring("pkg:npm/lib@1"))
+	g.Expect(c.Results.SBOMPath).To(Equal(sbomOutput))
+	g.Expect(c.Results.SBOMDigest).To(MatchRegexp("^sha256:[0-9a-f]{64}$"))
+}
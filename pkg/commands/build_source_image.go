@@ -0,0 +1,498 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	specsgo "github.com/opencontainers/image-spec/specs-go"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+const sourceImageTagSuffix = ".src"
+
+// Directories of the source image layers, as in the source containers built by BuildSourceImage
+const (
+	sourceImageRpmDir      = "rpm_dir"
+	sourceImageExtraSrcDir = "extra_src_dir"
+)
+
+var BuildSourceImageParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_BUILD_SOURCE_IMAGE_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Binary image URL. The source image is pushed to the image repository where this binary image is.",
+		Required:   true,
+	},
+	"digest": {
+		Name:       "digest",
+		ShortName:  "d",
+		EnvVarName: "KBC_BUILD_SOURCE_IMAGE_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Digest of the binary image. The source image tag is derived from it.",
+		Required:   true,
+	},
+	"source-dir": {
+		Name:         "source-dir",
+		ShortName:    "s",
+		EnvVarName:   "KBC_BUILD_SOURCE_IMAGE_SOURCE_DIR",
+		TypeKind:     reflect.String,
+		DefaultValue: ".",
+		Usage:        "Directory with the application source code. Added to the source image as a tarball, without the .git directory.",
+	},
+	"srpm-dir": {
+		Name:       "srpm-dir",
+		EnvVarName: "KBC_BUILD_SOURCE_IMAGE_SRPM_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Directory with the source RPMs of the RPMs installed in the binary image.",
+	},
+	"prefetch-dir": {
+		Name:       "prefetch-dir",
+		EnvVarName: "KBC_BUILD_SOURCE_IMAGE_PREFETCH_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Prefetch directory, the dependencies in its output/deps directory are added to the source image.",
+	},
+	"tag-suffix": {
+		Name:         "tag-suffix",
+		ShortName:    "t",
+		EnvVarName:   "KBC_BUILD_SOURCE_IMAGE_TAG_SUFFIX",
+		TypeKind:     reflect.String,
+		DefaultValue: sourceImageTagSuffix,
+		Usage:        "Suffix to construct the source image tag.",
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_BUILD_SOURCE_IMAGE_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Require HTTPS and verify certificates when accessing the registry.",
+	},
+	"result-path-image-url": {
+		Name:       "result-path-image-url",
+		EnvVarName: "KBC_BUILD_SOURCE_IMAGE_RESULT_PATH_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Write the URL of the source image into this file.",
+	},
+	"result-path-image-digest": {
+		Name:       "result-path-image-digest",
+		EnvVarName: "KBC_BUILD_SOURCE_IMAGE_RESULT_PATH_IMAGE_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Write the digest of the source image into this file.",
+	},
+}
+
+type BuildSourceImageParams struct {
+	ImageUrl              string `paramName:"image-url"`
+	Digest                string `paramName:"digest"`
+	SourceDir             string `paramName:"source-dir"`
+	SRPMDir               string `paramName:"srpm-dir"`
+	PrefetchDir           string `paramName:"prefetch-dir"`
+	TagSuffix             string `paramName:"tag-suffix"`
+	TLSVerify             bool   `paramName:"tls-verify"`
+	ResultPathImageUrl    string `paramName:"result-path-image-url"`
+	ResultPathImageDigest string `paramName:"result-path-image-digest"`
+}
+
+type BuildSourceImageCliWrappers struct {
+	SkopeoCli cliWrappers.SkopeoCliInterface
+}
+
+type BuildSourceImageResults struct {
+	ImageUrl string `json:"image_url"`
+	Digest   string `json:"digest"`
+}
+
+type BuildSourceImage struct {
+	Params        *BuildSourceImageParams
+	CliWrappers   BuildSourceImageCliWrappers
+	Results       BuildSourceImageResults
+	ResultsWriter common.ResultsWriterInterface
+
+	imageName        string
+	destinationImage string
+}
+
+func NewBuildSourceImage(cmd *cobra.Command) (*BuildSourceImage, error) {
+	buildSourceImage := &BuildSourceImage{}
+
+	params := &BuildSourceImageParams{}
+	if err := common.ParseParameters(cmd, BuildSourceImageParamsConfig, params); err != nil {
+		return nil, err
+	}
+	buildSourceImage.Params = params
+
+	if err := buildSourceImage.initCliWrappers(); err != nil {
+		return nil, err
+	}
+
+	buildSourceImage.ResultsWriter = common.NewResultsWriter()
+
+	return buildSourceImage, nil
+}
+
+func (c *BuildSourceImage) initCliWrappers() error {
+	executor := cliWrappers.NewCliExecutor()
+
+	skopeoCli, err := cliWrappers.NewSkopeoCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.SkopeoCli = skopeoCli
+	return nil
+}
+
+// Run executes the command logic.
+func (c *BuildSourceImage) Run() error {
+	common.LogParameters(BuildSourceImageParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	workDir, err := os.MkdirTemp("", "kbc-source-image-")
+	if err != nil {
+		return fmt.Errorf("creating temporary workdir: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			l.Logger.Warnf("failed to remove %s: %s", workDir, err.Error())
+		}
+	}()
+
+	layoutDir := filepath.Join(workDir, "layout")
+	if err := c.assembleSourceImage(layoutDir, workDir); err != nil {
+		return err
+	}
+
+	l.Logger.Infof("Pushing source image %s", c.destinationImage)
+	var extraArgs []string
+	if !c.Params.TLSVerify {
+		extraArgs = append(extraArgs, "--dest-tls-verify=false")
+	}
+	digestFile := filepath.Join(workDir, "digest")
+	err = c.CliWrappers.SkopeoCli.Copy(&cliWrappers.SkopeoCopyArgs{
+		SourceImage:      "oci:" + layoutDir + ":latest",
+		DestinationImage: c.destinationImage,
+		DigestFile:       digestFile,
+		RetryTimes:       3,
+		ExtraArgs:        extraArgs,
+	})
+	if err != nil {
+		return fmt.Errorf("pushing source image: %w", err)
+	}
+	pushedDigest, err := os.ReadFile(digestFile)
+	if err != nil {
+		return fmt.Errorf("reading digest of the pushed source image: %w", err)
+	}
+
+	c.Results.ImageUrl = c.destinationImage
+	c.Results.Digest = strings.TrimSpace(string(pushedDigest))
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	if err := c.ResultsWriter.WriteResultString(c.Results.ImageUrl, c.Params.ResultPathImageUrl); err != nil {
+		return fmt.Errorf("failed to write image url result: %w", err)
+	}
+	if err := c.ResultsWriter.WriteResultString(c.Results.Digest, c.Params.ResultPathImageDigest); err != nil {
+		return fmt.Errorf("failed to write image digest result: %w", err)
+	}
+
+	return nil
+}
+
+// assembleSourceImage writes the source image into an OCI layout. Each SRPM gets its own layer
+// in rpm_dir, the application source and the prefetched dependencies are tarballs in extra_src_dir.
+func (c *BuildSourceImage) assembleSourceImage(layoutDir, workDir string) error {
+	layout, err := newOciLayoutWriter(layoutDir)
+	if err != nil {
+		return err
+	}
+
+	if c.Params.SRPMDir != "" {
+		srpms, err := filepath.Glob(filepath.Join(c.Params.SRPMDir, "*.src.rpm"))
+		if err != nil {
+			return fmt.Errorf("listing SRPMs: %w", err)
+		}
+		if len(srpms) == 0 {
+			l.Logger.Warnf("No SRPMs found in %s", c.Params.SRPMDir)
+		}
+		for _, srpm := range srpms {
+			name := filepath.Base(srpm)
+			l.Logger.Infof("Adding SRPM %s", name)
+			if err := layout.addFileLayer(srpm, sourceImageRpmDir+"/"+name); err != nil {
+				return fmt.Errorf("adding SRPM %s: %w", name, err)
+			}
+		}
+	}
+
+	sourceDir, err := filepath.Abs(c.Params.SourceDir)
+	if err != nil {
+		return fmt.Errorf("getting absolute source-dir path: %w", err)
+	}
+	sourceTarball := filepath.Base(sourceDir) + ".tar.gz"
+	l.Logger.Infof("Adding application source %s", sourceTarball)
+	if err := addDirTarballLayer(layout, workDir, sourceDir, sourceTarball); err != nil {
+		return fmt.Errorf("adding application source: %w", err)
+	}
+
+	if c.Params.PrefetchDir != "" {
+		depsDir := filepath.Join(c.Params.PrefetchDir, "output", "deps")
+		if _, err := os.Stat(depsDir); err == nil {
+			l.Logger.Infof("Adding prefetched dependencies from %s", depsDir)
+			if err := addDirTarballLayer(layout, workDir, depsDir, "prefetched-dependencies.tar.gz"); err != nil {
+				return fmt.Errorf("adding prefetched dependencies: %w", err)
+			}
+		} else if os.IsNotExist(err) {
+			l.Logger.Warnf("No prefetched dependencies found in %s", depsDir)
+		} else {
+			return err
+		}
+	}
+
+	annotations := map[string]string{
+		"org.opencontainers.image.ref.name": "latest",
+	}
+	return layout.finish(annotations)
+}
+
+// addDirTarballLayer adds a layer with a tarball of dir in extra_src_dir.
+func addDirTarballLayer(layout *ociLayoutWriter, workDir, dir, tarballName string) error {
+	tarballPath := filepath.Join(workDir, tarballName)
+	tarball, err := os.Create(tarballPath) //nolint:gosec // path in the temporary workdir
+	if err != nil {
+		return err
+	}
+	if err := common.WriteDirTarball(tarball, dir); err != nil {
+		_ = tarball.Close()
+		return err
+	}
+	if err := tarball.Close(); err != nil {
+		return err
+	}
+	if err := layout.addFileLayer(tarballPath, sourceImageExtraSrcDir+"/"+tarballName); err != nil {
+		return err
+	}
+	return os.Remove(tarballPath)
+}
+
+func (c *BuildSourceImage) validateParams() error {
+	c.imageName = common.GetImageName(c.Params.ImageUrl)
+	if !common.IsImageNameValid(c.imageName) {
+		return fmt.Errorf("image name '%s' is invalid", c.imageName)
+	}
+
+	if !common.IsImageDigestValid(c.Params.Digest) {
+		return fmt.Errorf("image digest '%s' is invalid", c.Params.Digest)
+	}
+
+	if !regexp.MustCompile(tagSuffixRegex).MatchString(c.Params.TagSuffix) {
+		return fmt.Errorf("tag suffix includes invalid characters or exceeds the max length of 57 characters")
+	}
+
+	if stat, err := os.Stat(c.Params.SourceDir); err != nil {
+		return fmt.Errorf("source-dir: %w", err)
+	} else if !stat.IsDir() {
+		return fmt.Errorf("source-dir '%s' is not a directory", c.Params.SourceDir)
+	}
+	if c.Params.SRPMDir != "" {
+		if stat, err := os.Stat(c.Params.SRPMDir); err != nil {
+			return fmt.Errorf("srpm-dir: %w", err)
+		} else if !stat.IsDir() {
+			return fmt.Errorf("srpm-dir '%s' is not a directory", c.Params.SRPMDir)
+		}
+	}
+
+	tag := strings.Replace(c.Params.Digest, ":", "-", 1) + c.Params.TagSuffix
+	c.destinationImage = c.imageName + ":" + tag
+
+	return nil
+}
+
+// ociLayoutWriter writes a single image into an OCI image layout directory.
+type ociLayoutWriter struct {
+	dir     string
+	layers  []specs.Descriptor
+	diffIds []digest.Digest
+	history []specs.History
+}
+
+func newOciLayoutWriter(dir string) (*ociLayoutWriter, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0755); err != nil {
+		return nil, fmt.Errorf("creating OCI layout: %w", err)
+	}
+	layout, err := json.Marshal(specs.ImageLayout{Version: specs.ImageLayoutVersion})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, specs.ImageLayoutFile), layout, 0644); err != nil { //nolint:gosec // not a secret
+		return nil, fmt.Errorf("creating OCI layout: %w", err)
+	}
+	return &ociLayoutWriter{dir: dir}, nil
+}
+
+// addFileLayer adds a layer containing a single file, the content of source stored at path.
+// The parent directories of the path are added to the layer too.
+func (o *ociLayoutWriter) addFileLayer(source, path string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+
+	var diffId digest.Digest
+	descriptor, err := o.writeBlob(specs.MediaTypeImageLayerGzip, func(w io.Writer) error {
+		uncompressed := digest.Canonical.Digester()
+		gzipWriter := gzip.NewWriter(w)
+		tarWriter := tar.NewWriter(io.MultiWriter(gzipWriter, uncompressed.Hash()))
+
+		var dirs []string
+		for dir := filepath.Dir(path); dir != "."; dir = filepath.Dir(dir) {
+			dirs = append(dirs, dir)
+		}
+		sort.Strings(dirs)
+		for _, dir := range dirs {
+			header := &tar.Header{Typeflag: tar.TypeDir, Name: dir + "/", Mode: 0755}
+			common.NormalizeTarHeader(header)
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return err
+			}
+		}
+		header := &tar.Header{Typeflag: tar.TypeReg, Name: path, Mode: 0644, Size: info.Size()}
+		common.NormalizeTarHeader(header)
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if err := common.CopyFileInto(tarWriter, source); err != nil {
+			return err
+		}
+
+		if err := tarWriter.Close(); err != nil {
+			return err
+		}
+		if err := gzipWriter.Close(); err != nil {
+			return err
+		}
+		diffId = uncompressed.Digest()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	o.layers = append(o.layers, descriptor)
+	o.diffIds = append(o.diffIds, diffId)
+	o.history = append(o.history, specs.History{CreatedBy: "ADD " + path})
+	return nil
+}
+
+// writeBlob writes a blob into the layout, the content is produced by write.
+func (o *ociLayoutWriter) writeBlob(mediaType string, write func(io.Writer) error) (specs.Descriptor, error) {
+	blobsDir := filepath.Join(o.dir, "blobs", "sha256")
+	tmpFile, err := os.CreateTemp(blobsDir, "blob-")
+	if err != nil {
+		return specs.Descriptor{}, err
+	}
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	if err := write(io.MultiWriter(tmpFile, hash, counter)); err != nil {
+		_ = tmpFile.Close()
+		return specs.Descriptor{}, err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return specs.Descriptor{}, err
+	}
+
+	blobDigest := digest.NewDigestFromBytes(digest.SHA256, hash.Sum(nil))
+	if err := os.Rename(tmpFile.Name(), filepath.Join(blobsDir, blobDigest.Encoded())); err != nil {
+		return specs.Descriptor{}, err
+	}
+	return specs.Descriptor{MediaType: mediaType, Digest: blobDigest, Size: counter.n}, nil
+}
+
+func (o *ociLayoutWriter) writeJsonBlob(mediaType string, content any) (specs.Descriptor, error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return specs.Descriptor{}, err
+	}
+	return o.writeBlob(mediaType, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// finish writes the image config and manifest, and the index of the layout.
+func (o *ociLayoutWriter) finish(annotations map[string]string) error {
+	config := specs.Image{
+		Platform: specs.Platform{OS: "linux", Architecture: runtime.GOARCH},
+		RootFS:   specs.RootFS{Type: "layers", DiffIDs: o.diffIds},
+		History:  o.history,
+	}
+	configDescriptor, err := o.writeJsonBlob(specs.MediaTypeImageConfig, config)
+	if err != nil {
+		return fmt.Errorf("writing image config: %w", err)
+	}
+
+	layers := o.layers
+	if layers == nil {
+		layers = []specs.Descriptor{}
+	}
+	manifest := specs.Manifest{
+		Versioned: specsgo.Versioned{SchemaVersion: 2},
+		MediaType: specs.MediaTypeImageManifest,
+		Config:    configDescriptor,
+		Layers:    layers,
+	}
+	manifestDescriptor, err := o.writeJsonBlob(specs.MediaTypeImageManifest, manifest)
+	if err != nil {
+		return fmt.Errorf("writing image manifest: %w", err)
+	}
+	manifestDescriptor.Annotations = annotations
+
+	index := specs.Index{
+		Versioned: specsgo.Versioned{SchemaVersion: 2},
+		MediaType: specs.MediaTypeImageIndex,
+		Manifests: []specs.Descriptor{manifestDescriptor},
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(o.dir, "index.json"), data, 0644); err != nil { //nolint:gosec // not a secret
+		return fmt.Errorf("writing OCI layout index: %w", err)
+	}
+	return nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
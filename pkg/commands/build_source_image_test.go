@@ -0,0 +1,221 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+const sourceImageTestDigest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+
+func Test_BuildSourceImage_validateParams(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should derive source image tag from the binary image digest", func(t *testing.T) {
+		c := &BuildSourceImage{Params: &BuildSourceImageParams{
+			ImageUrl:  "quay.io/org/app:v1",
+			Digest:    sourceImageTestDigest,
+			SourceDir: t.TempDir(),
+			TagSuffix: ".src",
+		}}
+
+		g.Expect(c.validateParams()).To(Succeed())
+		g.Expect(c.destinationImage).To(Equal("quay.io/org/app:sha256-e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b.src"))
+	})
+
+	t.Run("should fail on invalid digest", func(t *testing.T) {
+		c := &BuildSourceImage{Params: &BuildSourceImageParams{ImageUrl: "quay.io/org/app:v1", Digest: "sha256:123", SourceDir: t.TempDir(), TagSuffix: ".src"}}
+
+		err := c.validateParams()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("image digest 'sha256:123' is invalid"))
+	})
+
+	t.Run("should fail on invalid tag suffix", func(t *testing.T) {
+		c := &BuildSourceImage{Params: &BuildSourceImageParams{ImageUrl: "quay.io/org/app:v1", Digest: sourceImageTestDigest, SourceDir: t.TempDir(), TagSuffix: "/src"}}
+
+		err := c.validateParams()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("tag suffix includes invalid characters"))
+	})
+
+	t.Run("should fail on missing source directory", func(t *testing.T) {
+		c := &BuildSourceImage{Params: &BuildSourceImageParams{ImageUrl: "quay.io/org/app:v1", Digest: sourceImageTestDigest, SourceDir: "/non/existent", TagSuffix: ".src"}}
+
+		err := c.validateParams()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("source-dir"))
+	})
+
+	t.Run("should fail if srpm-dir is a file", func(t *testing.T) {
+		srpmDir := filepath.Join(t.TempDir(), "srpms")
+		g.Expect(os.WriteFile(srpmDir, nil, 0644)).To(Succeed())
+		c := &BuildSourceImage{Params: &BuildSourceImageParams{ImageUrl: "quay.io/org/app:v1", Digest: sourceImageTestDigest, SourceDir: t.TempDir(), SRPMDir: srpmDir, TagSuffix: ".src"}}
+
+		err := c.validateParams()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("is not a directory"))
+	})
+}
+
+// Names and contents of the regular files in a gzip compressed tarball.
+func readTarGz(g *WithT, r io.Reader) map[string]string {
+	gzipReader, err := gzip.NewReader(r)
+	g.Expect(err).ToNot(HaveOccurred())
+	tarReader := tar.NewReader(gzipReader)
+	files := map[string]string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(header.Uid).To(Equal(0))
+		g.Expect(header.ModTime.Unix()).To(Equal(int64(0)))
+		if header.Typeflag == tar.TypeReg {
+			content, err := io.ReadAll(tarReader)
+			g.Expect(err).ToNot(HaveOccurred())
+			files[header.Name] = string(content)
+		}
+	}
+	return files
+}
+
+func Test_BuildSourceImage_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	// Files in the layers of the source image in the OCI layout
+	readLayout := func(layoutRef string) (specs.Manifest, []map[string]string) {
+		layoutDir := strings.TrimSuffix(strings.TrimPrefix(layoutRef, "oci:"), ":latest")
+		readBlob := func(descriptor specs.Descriptor) *os.File {
+			f, err := os.Open(filepath.Join(layoutDir, "blobs", "sha256", descriptor.Digest.Encoded()))
+			g.Expect(err).ToNot(HaveOccurred())
+			return f
+		}
+
+		indexData, err := os.ReadFile(filepath.Join(layoutDir, "index.json"))
+		g.Expect(err).ToNot(HaveOccurred())
+		var index specs.Index
+		g.Expect(json.Unmarshal(indexData, &index)).To(Succeed())
+		g.Expect(index.Manifests).To(HaveLen(1))
+		g.Expect(index.Manifests[0].Annotations).To(HaveKeyWithValue(specs.AnnotationRefName, "latest"))
+
+		manifestFile := readBlob(index.Manifests[0])
+		defer manifestFile.Close()
+		var manifest specs.Manifest
+		g.Expect(json.NewDecoder(manifestFile).Decode(&manifest)).To(Succeed())
+
+		configFile := readBlob(manifest.Config)
+		defer configFile.Close()
+		var config specs.Image
+		g.Expect(json.NewDecoder(configFile).Decode(&config)).To(Succeed())
+		g.Expect(config.RootFS.DiffIDs).To(HaveLen(len(manifest.Layers)))
+
+		var layers []map[string]string
+		for _, layer := range manifest.Layers {
+			layerFile := readBlob(layer)
+			layers = append(layers, readTarGz(g, layerFile))
+			layerFile.Close()
+		}
+		return manifest, layers
+	}
+
+	t.Run("should assemble and push source image", func(t *testing.T) {
+		sourceDir := filepath.Join(t.TempDir(), "app")
+		g.Expect(os.MkdirAll(filepath.Join(sourceDir, ".git"), 0755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main"), 0644)).To(Succeed())
+
+		srpmDir := t.TempDir()
+		g.Expect(os.WriteFile(filepath.Join(srpmDir, "bash-5.2-1.src.rpm"), []byte("bash srpm"), 0644)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(srpmDir, "zlib-1.3-1.src.rpm"), []byte("zlib srpm"), 0644)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(srpmDir, "README"), []byte("not an srpm"), 0644)).To(Succeed())
+
+		prefetchDir := t.TempDir()
+		depsDir := filepath.Join(prefetchDir, "output", "deps", "gomod")
+		g.Expect(os.MkdirAll(depsDir, 0755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(depsDir, "module.zip"), []byte("module"), 0644)).To(Succeed())
+
+		_mockSkopeoCli := &mockSkopeoCli{}
+		_mockResultsWriter := &mockResultsWriter{}
+		c := &BuildSourceImage{
+			CliWrappers: BuildSourceImageCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params: &BuildSourceImageParams{
+				ImageUrl:              "quay.io/org/app:v1",
+				Digest:                sourceImageTestDigest,
+				SourceDir:             sourceDir,
+				SRPMDir:               srpmDir,
+				PrefetchDir:           prefetchDir,
+				TagSuffix:             ".src",
+				TLSVerify:             true,
+				ResultPathImageUrl:    "/tmp/url",
+				ResultPathImageDigest: "/tmp/digest",
+			},
+			ResultsWriter: _mockResultsWriter,
+		}
+
+		isCopyCalled := false
+		_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			isCopyCalled = true
+			g.Expect(args.DestinationImage).To(Equal("quay.io/org/app:sha256-e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b.src"))
+			g.Expect(args.SourceImage).To(HavePrefix("oci:"))
+			g.Expect(args.ExtraArgs).To(BeEmpty())
+
+			manifest, layers := readLayout(args.SourceImage)
+			g.Expect(manifest.MediaType).To(Equal(specs.MediaTypeImageManifest))
+			g.Expect(layers).To(HaveLen(4))
+			g.Expect(layers[0]).To(Equal(map[string]string{"rpm_dir/bash-5.2-1.src.rpm": "bash srpm"}))
+			g.Expect(layers[1]).To(Equal(map[string]string{"rpm_dir/zlib-1.3-1.src.rpm": "zlib srpm"}))
+			g.Expect(layers[2]).To(HaveKey("extra_src_dir/app.tar.gz"))
+			g.Expect(readTarGz(g, strings.NewReader(layers[2]["extra_src_dir/app.tar.gz"]))).To(Equal(map[string]string{"main.go": "package main"}))
+			g.Expect(layers[3]).To(HaveKey("extra_src_dir/prefetched-dependencies.tar.gz"))
+			g.Expect(readTarGz(g, strings.NewReader(layers[3]["extra_src_dir/prefetched-dependencies.tar.gz"]))).To(Equal(map[string]string{"gomod/module.zip": "module"}))
+
+			return os.WriteFile(args.DigestFile, []byte("sha256:abcdef"), 0644)
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(isCopyCalled).To(BeTrue())
+		g.Expect(c.Results).To(Equal(BuildSourceImageResults{
+			ImageUrl: "quay.io/org/app:sha256-e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b.src",
+			Digest:   "sha256:abcdef",
+		}))
+		g.Expect(_mockResultsWriter.WrittenResults).To(HaveKeyWithValue("/tmp/digest", "sha256:abcdef"))
+		g.Expect(_mockResultsWriter.WrittenResults).To(HaveKeyWithValue("/tmp/url", c.Results.ImageUrl))
+	})
+
+	t.Run("should error if push fails", func(t *testing.T) {
+		_mockSkopeoCli := &mockSkopeoCli{}
+		c := &BuildSourceImage{
+			CliWrappers: BuildSourceImageCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params: &BuildSourceImageParams{
+				ImageUrl:  "quay.io/org/app:v1",
+				Digest:    sourceImageTestDigest,
+				SourceDir: t.TempDir(),
+				TagSuffix: ".src",
+			},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			g.Expect(args.ExtraArgs).To(Equal([]string{"--dest-tls-verify=false"}))
+			return errors.New("unauthorized")
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("pushing source image"))
+	})
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+func (c *Build) validateStorageParams() error {
+	if c.Params.StorageDir != "" {
+		info, err := os.Stat(c.Params.StorageDir)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("checking storage-dir: %w", err)
+		}
+		// buildah creates the directory if it doesn't exist
+		if err == nil && !info.IsDir() {
+			return fmt.Errorf("storage-dir '%s' is not a directory", c.Params.StorageDir)
+		}
+	}
+
+	if c.Params.CachePruneOlderThan != "" {
+		age, err := parseQuayExpiresAfter(c.Params.CachePruneOlderThan)
+		if err != nil {
+			return fmt.Errorf("cache-prune-older-than '%s' is invalid: %w", c.Params.CachePruneOlderThan, err)
+		}
+		c.cachePruneOlderThan = age
+	}
+	return nil
+}
+
+// Remove the images created earlier than --cache-prune-older-than from the container storage.
+// The storage may be shared with other builds, so images that can't be removed, e.g. because
+// a container uses them, are skipped.
+func (c *Build) pruneCache() error {
+	images, err := c.CliWrappers.BuildahCli.ImagesJson(&cliWrappers.BuildahImagesArgs{All: true})
+	if err != nil {
+		return fmt.Errorf("listing images to prune: %w", err)
+	}
+
+	cutoff := time.Now().Add(-c.cachePruneOlderThan).Unix()
+	var stale []cliWrappers.BuildahImagesEntry
+	for _, image := range images {
+		if image.Created < cutoff {
+			stale = append(stale, image)
+		}
+	}
+	// Newest first, an image can't be removed before the intermediate images built on top of it
+	slices.SortStableFunc(stale, func(a, b cliWrappers.BuildahImagesEntry) int {
+		return cmp.Compare(b.Created, a.Created)
+	})
+
+	removed := 0
+	for _, image := range stale {
+		if err := c.CliWrappers.BuildahCli.Rmi(image.ID); err != nil {
+			l.Logger.Warnf("Not pruning image %s %v: %s", image.ID, image.Names, err)
+			continue
+		}
+		l.Logger.Debugf("Pruned image %s %v", image.ID, image.Names)
+		removed++
+	}
+	l.Logger.Infof("Pruned %d of %d images created more than %s ago", removed, len(stale), c.Params.CachePruneOlderThan)
+	return nil
+}
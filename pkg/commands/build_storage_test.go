@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_Build_validateStorageParams(t *testing.T) {
+	tempDir := t.TempDir()
+	file := filepath.Join(tempDir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		params        BuildParams
+		errExpected   string
+		wantPruneTime time.Duration
+	}{
+		{
+			name:   "should accept empty params",
+			params: BuildParams{},
+		},
+		{
+			name:          "should accept existing storage dir and age",
+			params:        BuildParams{StorageDir: tempDir, CachePruneOlderThan: "7d"},
+			wantPruneTime: 7 * 24 * time.Hour,
+		},
+		{
+			name:   "should accept storage dir that doesn't exist yet",
+			params: BuildParams{StorageDir: filepath.Join(tempDir, "storage")},
+		},
+		{
+			name:        "should fail if storage dir is a file",
+			params:      BuildParams{StorageDir: file},
+			errExpected: "is not a directory",
+		},
+		{
+			name:        "should fail on invalid age",
+			params:      BuildParams{CachePruneOlderThan: "1y"},
+			errExpected: "cache-prune-older-than '1y' is invalid",
+		},
+		{
+			name:        "should fail on zero age",
+			params:      BuildParams{CachePruneOlderThan: "0h"},
+			errExpected: "cache-prune-older-than '0h' is invalid",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &Build{Params: &tc.params}
+
+			err := c.validateStorageParams()
+
+			if tc.errExpected != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.errExpected)))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(c.cachePruneOlderThan).To(Equal(tc.wantPruneTime))
+			}
+		})
+	}
+}
+
+func Test_Build_pruneCache(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	images := []cliWrappers.BuildahImagesEntry{
+		{ID: "old-base", Names: []string{"registry.io/base:1"}, Created: now.Add(-30 * 24 * time.Hour).Unix()},
+		{ID: "new", Names: []string{"registry.io/app:1"}, Created: now.Add(-time.Hour).Unix()},
+		{ID: "old-layer", Created: now.Add(-10 * 24 * time.Hour).Unix()},
+		{ID: "in-use", Created: now.Add(-20 * 24 * time.Hour).Unix()},
+	}
+
+	var removed []string
+	buildahCli := &mockBuildahCli{
+		ImagesJsonFunc: func(args *cliWrappers.BuildahImagesArgs) ([]cliWrappers.BuildahImagesEntry, error) {
+			g.Expect(args.All).To(BeTrue())
+			return images, nil
+		},
+		RmiFunc: func(image string) error {
+			removed = append(removed, image)
+			if image == "in-use" {
+				return errors.New("image used by container")
+			}
+			return nil
+		},
+	}
+	c := &Build{
+		Params:              &BuildParams{CachePruneOlderThan: "7d"},
+		CliWrappers:         BuildCliWrappers{BuildahCli: buildahCli},
+		cachePruneOlderThan: 7 * 24 * time.Hour,
+	}
+
+	t.Run("should remove the old images, newest first", func(t *testing.T) {
+		removed = nil
+
+		g.Expect(c.pruneCache()).To(Succeed())
+
+		g.Expect(removed).To(Equal([]string{"old-layer", "in-use", "old-base"}))
+	})
+
+	t.Run("should fail if listing images fails", func(t *testing.T) {
+		buildahCli.ImagesJsonFunc = func(args *cliWrappers.BuildahImagesArgs) ([]cliWrappers.BuildahImagesEntry, error) {
+			return nil, errors.New("storage is locked")
+		}
+
+		g.Expect(c.pruneCache()).To(MatchError(ContainSubstring("listing images to prune")))
+	})
+}
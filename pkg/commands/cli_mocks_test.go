@@ -1,16 +1,21 @@
 package commands
 
 import (
+	"context"
 	"runtime"
 
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 )
 
 var _ cliwrappers.SkopeoCliInterface = &mockSkopeoCli{}
 
 type mockSkopeoCli struct {
-	CopyFunc    func(args *cliwrappers.SkopeoCopyArgs) error
-	InspectFunc func(args *cliwrappers.SkopeoInspectArgs) (string, error)
+	CopyFunc     func(args *cliwrappers.SkopeoCopyArgs) error
+	CopyAllFunc  func(args *cliwrappers.SkopeoCopyArgs) error
+	InspectFunc  func(args *cliwrappers.SkopeoInspectArgs) (string, error)
+	ListTagsFunc func(args *cliwrappers.SkopeoListTagsArgs) ([]string, error)
+	DeleteFunc   func(args *cliwrappers.SkopeoDeleteArgs) error
 }
 
 func (m *mockSkopeoCli) Copy(args *cliwrappers.SkopeoCopyArgs) error {
@@ -20,6 +25,13 @@ func (m *mockSkopeoCli) Copy(args *cliwrappers.SkopeoCopyArgs) error {
 	return nil
 }
 
+func (m *mockSkopeoCli) CopyAll(args *cliwrappers.SkopeoCopyArgs) error {
+	if m.CopyAllFunc != nil {
+		return m.CopyAllFunc(args)
+	}
+	return nil
+}
+
 func (m *mockSkopeoCli) Inspect(args *cliwrappers.SkopeoInspectArgs) (string, error) {
 	if m.InspectFunc != nil {
 		return m.InspectFunc(args)
@@ -27,24 +39,44 @@ func (m *mockSkopeoCli) Inspect(args *cliwrappers.SkopeoInspectArgs) (string, er
 	return "", nil
 }
 
+func (m *mockSkopeoCli) ListTags(args *cliwrappers.SkopeoListTagsArgs) ([]string, error) {
+	if m.ListTagsFunc != nil {
+		return m.ListTagsFunc(args)
+	}
+	return []string{}, nil
+}
+
+func (m *mockSkopeoCli) Delete(args *cliwrappers.SkopeoDeleteArgs) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(args)
+	}
+	return nil
+}
+
 var _ cliwrappers.BuildahCliInterface = &mockBuildahCli{}
 
 type mockBuildahCli struct {
-	BuildFunc           func(args *cliwrappers.BuildahBuildArgs) error
-	PushFunc            func(args *cliwrappers.BuildahPushArgs) (string, error)
-	PullFunc            func(args *cliwrappers.BuildahPullArgs) error
-	InspectFunc         func(args *cliwrappers.BuildahInspectArgs) (string, error)
-	InspectImageFunc    func(name string) (cliwrappers.BuildahImageInfo, error)
-	VersionFunc         func() (cliwrappers.BuildahVersionInfo, error)
-	ManifestCreateFunc  func(args *cliwrappers.BuildahManifestCreateArgs) error
-	ManifestAddFunc     func(args *cliwrappers.BuildahManifestAddArgs) error
-	ManifestInspectFunc func(args *cliwrappers.BuildahManifestInspectArgs) (string, error)
-	ManifestPushFunc    func(args *cliwrappers.BuildahManifestPushArgs) (string, error)
-	ImagesFunc          func(args *cliwrappers.BuildahImagesArgs) (string, error)
-	ImagesJsonFunc      func(args *cliwrappers.BuildahImagesArgs) ([]cliwrappers.BuildahImagesEntry, error)
-	FromFunc            func(image string) (string, error)
-	RmFunc              func(container string) error
-	MountFunc           func(container string) (string, error)
+	BuildFunc                   func(args *cliwrappers.BuildahBuildArgs) error
+	PushFunc                    func(args *cliwrappers.BuildahPushArgs) (string, error)
+	PullFunc                    func(args *cliwrappers.BuildahPullArgs) error
+	InspectFunc                 func(args *cliwrappers.BuildahInspectArgs) (string, error)
+	InspectImageFunc            func(name string) (cliwrappers.BuildahImageInfo, error)
+	VersionFunc                 func() (cliwrappers.BuildahVersionInfo, error)
+	ManifestCreateFunc          func(args *cliwrappers.BuildahManifestCreateArgs) error
+	ManifestAddFunc             func(args *cliwrappers.BuildahManifestAddArgs) error
+	ManifestAnnotateFunc        func(args *cliwrappers.BuildahManifestAnnotateArgs) error
+	ManifestInspectFunc         func(args *cliwrappers.BuildahManifestInspectArgs) (string, error)
+	ManifestPushWithDigestsFunc func(args *cliwrappers.BuildahManifestPushArgs) (*cliwrappers.BuildahManifestPushResult, error)
+	ManifestPushFunc            func(args *cliwrappers.BuildahManifestPushArgs) (string, error)
+	ImagesFunc                  func(args *cliwrappers.BuildahImagesArgs) (string, error)
+	ImagesJsonFunc              func(args *cliwrappers.BuildahImagesArgs) ([]cliwrappers.BuildahImagesEntry, error)
+	FromFunc                    func(image string) (string, error)
+	RmFunc                      func(container string) error
+	RmiFunc                     func(image string) error
+	TagFunc                     func(image string, names ...string) error
+	MountFunc                   func(container string) (string, error)
+	CommitFunc                  func(args *cliwrappers.BuildahCommitArgs) error
+	ConfigFunc                  func(args *cliwrappers.BuildahConfigArgs) error
 }
 
 func (m *mockBuildahCli) Build(args *cliwrappers.BuildahBuildArgs) error {
@@ -105,6 +137,13 @@ func (m *mockBuildahCli) ManifestAdd(args *cliwrappers.BuildahManifestAddArgs) e
 	return nil
 }
 
+func (m *mockBuildahCli) ManifestAnnotate(args *cliwrappers.BuildahManifestAnnotateArgs) error {
+	if m.ManifestAnnotateFunc != nil {
+		return m.ManifestAnnotateFunc(args)
+	}
+	return nil
+}
+
 func (m *mockBuildahCli) ManifestInspect(args *cliwrappers.BuildahManifestInspectArgs) (string, error) {
 	if m.ManifestInspectFunc != nil {
 		return m.ManifestInspectFunc(args)
@@ -119,6 +158,18 @@ func (m *mockBuildahCli) ManifestPush(args *cliwrappers.BuildahManifestPushArgs)
 	return "", nil
 }
 
+// Falls back to ManifestPush, without per-platform manifests
+func (m *mockBuildahCli) ManifestPushWithDigests(args *cliwrappers.BuildahManifestPushArgs) (*cliwrappers.BuildahManifestPushResult, error) {
+	if m.ManifestPushWithDigestsFunc != nil {
+		return m.ManifestPushWithDigestsFunc(args)
+	}
+	digest, err := m.ManifestPush(args)
+	if err != nil {
+		return nil, err
+	}
+	return &cliwrappers.BuildahManifestPushResult{IndexDigest: digest}, nil
+}
+
 func (m *mockBuildahCli) Images(args *cliwrappers.BuildahImagesArgs) (string, error) {
 	if m.ImagesFunc != nil {
 		return m.ImagesFunc(args)
@@ -154,11 +205,41 @@ func (m *mockBuildahCli) Mount(container string) (string, error) {
 	return "", nil
 }
 
+func (m *mockBuildahCli) Rmi(image string) error {
+	if m.RmiFunc != nil {
+		return m.RmiFunc(image)
+	}
+	return nil
+}
+
+func (m *mockBuildahCli) Tag(image string, names ...string) error {
+	if m.TagFunc != nil {
+		return m.TagFunc(image, names...)
+	}
+	return nil
+}
+
+func (m *mockBuildahCli) Commit(args *cliwrappers.BuildahCommitArgs) error {
+	if m.CommitFunc != nil {
+		return m.CommitFunc(args)
+	}
+	return nil
+}
+
+func (m *mockBuildahCli) Config(args *cliwrappers.BuildahConfigArgs) error {
+	if m.ConfigFunc != nil {
+		return m.ConfigFunc(args)
+	}
+	return nil
+}
+
 var _ cliwrappers.SubscriptionManagerCliInterface = &mockSubscriptionManagerCli{}
 
 type mockSubscriptionManagerCli struct {
-	RegisterFunc   func(params *cliwrappers.SubscriptionManagerRegisterParams) error
-	UnregisterFunc func()
+	RegisterFunc    func(params *cliwrappers.SubscriptionManagerRegisterParams) error
+	SetReleaseFunc  func(release string) error
+	EnableReposFunc func(repoIDs []string) error
+	UnregisterFunc  func()
 }
 
 func (m *mockSubscriptionManagerCli) Register(params *cliwrappers.SubscriptionManagerRegisterParams) error {
@@ -168,6 +249,20 @@ func (m *mockSubscriptionManagerCli) Register(params *cliwrappers.SubscriptionMa
 	return nil
 }
 
+func (m *mockSubscriptionManagerCli) SetRelease(release string) error {
+	if m.SetReleaseFunc != nil {
+		return m.SetReleaseFunc(release)
+	}
+	return nil
+}
+
+func (m *mockSubscriptionManagerCli) EnableRepos(repoIDs []string) error {
+	if m.EnableReposFunc != nil {
+		return m.EnableReposFunc(repoIDs)
+	}
+	return nil
+}
+
 func (m *mockSubscriptionManagerCli) Unregister() {
 	if m.UnregisterFunc != nil {
 		m.UnregisterFunc()
@@ -190,8 +285,12 @@ func (m *mockSyftCli) Scan(args *cliwrappers.SyftScanArgs) (string, error) {
 var _ cliwrappers.OrasCliInterface = &mockOrasCli{}
 
 type mockOrasCli struct {
-	Executor cliwrappers.CliExecutorInterface
-	PushFunc func(args *cliwrappers.OrasPushArgs) (string, string, error)
+	Executor          cliwrappers.CliExecutorInterface
+	PushFunc          func(args *cliwrappers.OrasPushArgs) (string, string, error)
+	AttachFunc        func(args *cliwrappers.OrasAttachArgs) (string, string, error)
+	DiscoverFunc      func(args *cliwrappers.OrasDiscoverArgs) ([]cliwrappers.OrasReferrer, error)
+	ManifestFetchFunc func(args *cliwrappers.OrasManifestFetchArgs) (string, error)
+	BlobFetchFunc     func(args *cliwrappers.OrasBlobFetchArgs) error
 }
 
 func (m *mockOrasCli) Push(args *cliwrappers.OrasPushArgs) (string, string, error) {
@@ -200,3 +299,140 @@ func (m *mockOrasCli) Push(args *cliwrappers.OrasPushArgs) (string, string, erro
 	}
 	return "", "", nil
 }
+
+func (m *mockOrasCli) Attach(args *cliwrappers.OrasAttachArgs) (string, string, error) {
+	if m.AttachFunc != nil {
+		return m.AttachFunc(args)
+	}
+	return "", "", nil
+}
+
+func (m *mockOrasCli) Discover(args *cliwrappers.OrasDiscoverArgs) ([]cliwrappers.OrasReferrer, error) {
+	if m.DiscoverFunc != nil {
+		return m.DiscoverFunc(args)
+	}
+	return nil, nil
+}
+
+func (m *mockOrasCli) ManifestFetch(args *cliwrappers.OrasManifestFetchArgs) (string, error) {
+	if m.ManifestFetchFunc != nil {
+		return m.ManifestFetchFunc(args)
+	}
+	return "", nil
+}
+
+func (m *mockOrasCli) BlobFetch(args *cliwrappers.OrasBlobFetchArgs) error {
+	if m.BlobFetchFunc != nil {
+		return m.BlobFetchFunc(args)
+	}
+	return nil
+}
+
+var _ cliwrappers.CosignCliInterface = &mockCosignCli{}
+
+type mockCosignCli struct {
+	SignFunc              func(args *cliwrappers.CosignSignArgs) error
+	AttestFunc            func(args *cliwrappers.CosignAttestArgs) error
+	VerifyFunc            func(args *cliwrappers.CosignVerifyArgs) error
+	VerifyAttestationFunc func(args *cliwrappers.CosignVerifyAttestationArgs) error
+}
+
+func (m *mockCosignCli) Sign(args *cliwrappers.CosignSignArgs) error {
+	if m.SignFunc != nil {
+		return m.SignFunc(args)
+	}
+	return nil
+}
+
+func (m *mockCosignCli) Attest(args *cliwrappers.CosignAttestArgs) error {
+	if m.AttestFunc != nil {
+		return m.AttestFunc(args)
+	}
+	return nil
+}
+
+func (m *mockCosignCli) Verify(args *cliwrappers.CosignVerifyArgs) error {
+	if m.VerifyFunc != nil {
+		return m.VerifyFunc(args)
+	}
+	return nil
+}
+
+func (m *mockCosignCli) VerifyAttestation(args *cliwrappers.CosignVerifyAttestationArgs) error {
+	if m.VerifyAttestationFunc != nil {
+		return m.VerifyAttestationFunc(args)
+	}
+	return nil
+}
+
+var _ cliwrappers.SshCliInterface = &mockSshCli{}
+
+type mockSshCli struct {
+	RunFunc      func(command []string, logOutput bool) (string, string, int, error)
+	CopyToFunc   func(localPaths []string, remoteDir string) error
+	CopyFromFunc func(remotePath, localPath string) error
+}
+
+func (m *mockSshCli) Run(command []string, logOutput bool) (string, string, int, error) {
+	if m.RunFunc != nil {
+		return m.RunFunc(command, logOutput)
+	}
+	return "", "", 0, nil
+}
+
+func (m *mockSshCli) CopyTo(localPaths []string, remoteDir string) error {
+	if m.CopyToFunc != nil {
+		return m.CopyToFunc(localPaths, remoteDir)
+	}
+	return nil
+}
+
+func (m *mockSshCli) CopyFrom(remotePath, localPath string) error {
+	if m.CopyFromFunc != nil {
+		return m.CopyFromFunc(remotePath, localPath)
+	}
+	return nil
+}
+
+var _ clients.ImagePusherInterface = &mockImagePusher{}
+
+type mockImagePusher struct {
+	PushFunc func(args *clients.ImagePushArgs) (string, error)
+}
+
+func (m *mockImagePusher) Push(args *clients.ImagePushArgs) (string, error) {
+	if m.PushFunc != nil {
+		return m.PushFunc(args)
+	}
+	return "", nil
+}
+
+var _ cliwrappers.CliExecutorInterface = &mockCliExecutor{}
+
+type mockCliExecutor struct {
+	ExecuteContextFunc func(ctx context.Context, cmd cliwrappers.Cmd) (string, string, int, error)
+}
+
+func (m *mockCliExecutor) Execute(cmd cliwrappers.Cmd) (string, string, int, error) {
+	return m.ExecuteContext(context.Background(), cmd)
+}
+
+func (m *mockCliExecutor) ExecuteContext(ctx context.Context, cmd cliwrappers.Cmd) (string, string, int, error) {
+	if m.ExecuteContextFunc != nil {
+		return m.ExecuteContextFunc(ctx, cmd)
+	}
+	return "", "", 0, nil
+}
+
+var _ clients.RegistryAuthCheckerInterface = &mockRegistryAuthChecker{}
+
+type mockRegistryAuthChecker struct {
+	CheckAuthFunc func(args *clients.RegistryAuthCheckArgs) error
+}
+
+func (m *mockRegistryAuthChecker) CheckAuth(args *clients.RegistryAuthCheckArgs) error {
+	if m.CheckAuthFunc != nil {
+		return m.CheckAuthFunc(args)
+	}
+	return nil
+}
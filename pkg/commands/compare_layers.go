@@ -0,0 +1,284 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var CompareLayersParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_COMPARE_LAYERS_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Image produced by the current build, referenced by tag and/or digest. Required.",
+		Required:   true,
+	},
+	"previous-image": {
+		Name:       "previous-image",
+		ShortName:  "p",
+		EnvVarName: "KBC_COMPARE_LAYERS_PREVIOUS_IMAGE",
+		TypeKind:   reflect.String,
+		Usage:      "Image produced by the previous build. A bare digest refers to the repository of --image-url.",
+	},
+	"previous-image-file": {
+		Name:       "previous-image-file",
+		EnvVarName: "KBC_COMPARE_LAYERS_PREVIOUS_IMAGE_FILE",
+		TypeKind:   reflect.String,
+		Usage:      "File containing the --previous-image value, e.g. the image digest result of the previous build.",
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_COMPARE_LAYERS_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Require HTTPS and verify certificates when accessing the registry.",
+	},
+	"result-path-reuse-percentage": {
+		Name:       "result-path-reuse-percentage",
+		EnvVarName: "KBC_COMPARE_LAYERS_RESULT_PATH_REUSE_PERCENTAGE",
+		TypeKind:   reflect.String,
+		Usage:      "Write the percentage of re-used layers into this file.",
+	},
+}
+
+type CompareLayersParams struct {
+	ImageUrl                  string `paramName:"image-url"`
+	PreviousImage             string `paramName:"previous-image"`
+	PreviousImageFile         string `paramName:"previous-image-file"`
+	TLSVerify                 bool   `paramName:"tls-verify"`
+	ResultPathReusePercentage string `paramName:"result-path-reuse-percentage"`
+}
+
+type CompareLayersCliWrappers struct {
+	SkopeoCli cliWrappers.SkopeoCliInterface
+}
+
+type CompareLayersResults struct {
+	TotalLayers     int     `json:"total_layers"`
+	ReusedLayers    int     `json:"reused_layers"`
+	ReusePercentage float64 `json:"reuse_percentage"`
+	// The first instruction that produced a different layer, i.e. where the build cache got invalidated.
+	FirstChangedInstruction string `json:"first_changed_instruction,omitempty"`
+	// All the instructions that produced a layer not present in the previous image.
+	ChangedInstructions []string `json:"changed_instructions,omitempty"`
+}
+
+type CompareLayers struct {
+	Params        *CompareLayersParams
+	CliWrappers   CompareLayersCliWrappers
+	Results       CompareLayersResults
+	ResultsWriter common.ResultsWriterInterface
+
+	previousImage string
+}
+
+func NewCompareLayers(cmd *cobra.Command) (*CompareLayers, error) {
+	compareLayers := &CompareLayers{}
+
+	params := &CompareLayersParams{}
+	if err := common.ParseParameters(cmd, CompareLayersParamsConfig, params); err != nil {
+		return nil, err
+	}
+	compareLayers.Params = params
+
+	if err := compareLayers.initCliWrappers(); err != nil {
+		return nil, err
+	}
+
+	compareLayers.ResultsWriter = common.NewResultsWriter()
+
+	return compareLayers, nil
+}
+
+func (c *CompareLayers) initCliWrappers() error {
+	executor := cliWrappers.NewCliExecutor()
+
+	skopeoCli, err := cliWrappers.NewSkopeoCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.SkopeoCli = skopeoCli
+	return nil
+}
+
+// Run executes the command logic.
+func (c *CompareLayers) Run() error {
+	common.LogParameters(CompareLayersParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	currentLayers, err := c.getLayers(c.Params.ImageUrl)
+	if err != nil {
+		return err
+	}
+	previousLayers, err := c.getLayers(c.previousImage)
+	if err != nil {
+		return err
+	}
+
+	c.Results = compareImageLayers(currentLayers, previousLayers)
+
+	l.Logger.Infof("Re-used %d of %d layers (%.1f%%)", c.Results.ReusedLayers, c.Results.TotalLayers, c.Results.ReusePercentage)
+	if c.Results.FirstChangedInstruction != "" {
+		l.Logger.Infof("Build cache invalidated by: %s", c.Results.FirstChangedInstruction)
+	}
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	percentage := fmt.Sprintf("%.1f", c.Results.ReusePercentage)
+	if err := c.ResultsWriter.WriteResultString(percentage, c.Params.ResultPathReusePercentage); err != nil {
+		return fmt.Errorf("failed to write reuse percentage result: %w", err)
+	}
+
+	return nil
+}
+
+// imageLayer is a layer of an image along with the instruction that created it.
+type imageLayer struct {
+	DiffId    string
+	CreatedBy string
+}
+
+// Subset of the OCI image config needed to match layers with instructions.
+type imageConfigLayers struct {
+	RootFS struct {
+		DiffIds []string `json:"diff_ids"`
+	} `json:"rootfs"`
+	History []struct {
+		CreatedBy  string `json:"created_by"`
+		EmptyLayer bool   `json:"empty_layer"`
+	} `json:"history"`
+}
+
+func (c *CompareLayers) getLayers(imageRef string) ([]imageLayer, error) {
+	var extraArgs []string
+	if !c.Params.TLSVerify {
+		extraArgs = append(extraArgs, "--tls-verify=false")
+	}
+
+	configJson, err := c.CliWrappers.SkopeoCli.Inspect(&cliWrappers.SkopeoInspectArgs{
+		// skopeo doesn't accept references with both a tag and a digest
+		ImageRef:   common.NormalizeImageRefWithDigest(imageRef),
+		Config:     true,
+		RetryTimes: 3,
+		ExtraArgs:  extraArgs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("inspecting config of %s: %w", imageRef, err)
+	}
+
+	var config imageConfigLayers
+	if err := json.Unmarshal([]byte(configJson), &config); err != nil {
+		return nil, fmt.Errorf("parsing config of %s: %w", imageRef, err)
+	}
+
+	// Each history entry without empty_layer corresponds to the next layer. Some tools don't
+	// record history for all the layers, in that case the instructions are unknown.
+	var createdBy []string
+	for _, h := range config.History {
+		if !h.EmptyLayer {
+			createdBy = append(createdBy, h.CreatedBy)
+		}
+	}
+	if len(createdBy) != len(config.RootFS.DiffIds) {
+		l.Logger.Warnf("History of %s doesn't match its layers, instructions will not be reported", imageRef)
+		createdBy = nil
+	}
+
+	layers := make([]imageLayer, len(config.RootFS.DiffIds))
+	for i, diffId := range config.RootFS.DiffIds {
+		layers[i].DiffId = diffId
+		if createdBy != nil {
+			layers[i].CreatedBy = strings.TrimSpace(createdBy[i])
+		}
+	}
+	return layers, nil
+}
+
+// compareImageLayers counts the layers of the current image that already existed in the previous one.
+// Layers are compared by their uncompressed digests (diff IDs), which don't depend on the compression.
+func compareImageLayers(current, previous []imageLayer) CompareLayersResults {
+	results := CompareLayersResults{TotalLayers: len(current)}
+
+	previousDiffIds := make(map[string]bool, len(previous))
+	for _, layer := range previous {
+		previousDiffIds[layer.DiffId] = true
+	}
+
+	for i, layer := range current {
+		if previousDiffIds[layer.DiffId] {
+			results.ReusedLayers++
+			continue
+		}
+		instruction := layer.CreatedBy
+		if instruction == "" {
+			instruction = fmt.Sprintf("<layer %d>", i)
+		}
+		if results.FirstChangedInstruction == "" {
+			results.FirstChangedInstruction = instruction
+		}
+		results.ChangedInstructions = append(results.ChangedInstructions, instruction)
+	}
+
+	if results.TotalLayers > 0 {
+		percentage := float64(results.ReusedLayers) * 100 / float64(results.TotalLayers)
+		results.ReusePercentage = math.Round(percentage*10) / 10
+	}
+	return results
+}
+
+func (c *CompareLayers) validateParams() error {
+	if !common.IsImageNameValid(common.GetImageName(c.Params.ImageUrl)) {
+		return fmt.Errorf("image '%s' is invalid", c.Params.ImageUrl)
+	}
+	if err := common.ValidateImageHasTagOrDigest(c.Params.ImageUrl); err != nil {
+		return err
+	}
+
+	previousImage := c.Params.PreviousImage
+	if c.Params.PreviousImageFile != "" {
+		if previousImage != "" {
+			return fmt.Errorf("previous-image and previous-image-file are mutually exclusive")
+		}
+		content, err := os.ReadFile(c.Params.PreviousImageFile)
+		if err != nil {
+			return fmt.Errorf("reading previous-image-file: %w", err)
+		}
+		previousImage = strings.TrimSpace(string(content))
+	}
+	if previousImage == "" {
+		return fmt.Errorf("previous-image or previous-image-file is required")
+	}
+
+	if common.IsImageDigestValid(previousImage) {
+		previousImage = common.GetImageName(c.Params.ImageUrl) + "@" + previousImage
+	}
+	if !common.IsImageNameValid(common.GetImageName(previousImage)) {
+		return fmt.Errorf("previous image '%s' is invalid", previousImage)
+	}
+	if err := common.ValidateImageHasTagOrDigest(previousImage); err != nil {
+		return err
+	}
+	c.previousImage = previousImage
+
+	return nil
+}
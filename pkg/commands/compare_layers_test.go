@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_compareImageLayers(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should report full reuse", func(t *testing.T) {
+		layers := []imageLayer{{DiffId: "sha256:a", CreatedBy: "FROM base"}, {DiffId: "sha256:b", CreatedBy: "RUN make"}}
+
+		results := compareImageLayers(layers, layers)
+
+		g.Expect(results).To(Equal(CompareLayersResults{TotalLayers: 2, ReusedLayers: 2, ReusePercentage: 100}))
+	})
+
+	t.Run("should report changed instructions", func(t *testing.T) {
+		previous := []imageLayer{
+			{DiffId: "sha256:a", CreatedBy: "FROM base"},
+			{DiffId: "sha256:b", CreatedBy: "COPY . ."},
+			{DiffId: "sha256:c", CreatedBy: "RUN make"},
+		}
+		current := []imageLayer{
+			{DiffId: "sha256:a", CreatedBy: "FROM base"},
+			{DiffId: "sha256:d", CreatedBy: "COPY . ."},
+			{DiffId: "sha256:e", CreatedBy: "RUN make"},
+		}
+
+		results := compareImageLayers(current, previous)
+
+		g.Expect(results).To(Equal(CompareLayersResults{
+			TotalLayers:             3,
+			ReusedLayers:            1,
+			ReusePercentage:         33.3,
+			FirstChangedInstruction: "COPY . .",
+			ChangedInstructions:     []string{"COPY . .", "RUN make"},
+		}))
+	})
+
+	t.Run("should identify layers without history by index", func(t *testing.T) {
+		results := compareImageLayers([]imageLayer{{DiffId: "sha256:a"}, {DiffId: "sha256:b"}}, []imageLayer{{DiffId: "sha256:a"}})
+
+		g.Expect(results.FirstChangedInstruction).To(Equal("<layer 1>"))
+	})
+
+	t.Run("should handle image without layers", func(t *testing.T) {
+		results := compareImageLayers(nil, nil)
+
+		g.Expect(results).To(Equal(CompareLayersResults{}))
+	})
+}
+
+func Test_CompareLayers_validateParams(t *testing.T) {
+	g := NewWithT(t)
+
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+
+	t.Run("should resolve bare digest against image-url repository", func(t *testing.T) {
+		c := &CompareLayers{Params: &CompareLayersParams{ImageUrl: "quay.io/org/app:v2", PreviousImage: digest}}
+
+		g.Expect(c.validateParams()).To(Succeed())
+		g.Expect(c.previousImage).To(Equal("quay.io/org/app@" + digest))
+	})
+
+	t.Run("should read previous image from file", func(t *testing.T) {
+		previousImageFile := filepath.Join(t.TempDir(), "IMAGE_DIGEST")
+		g.Expect(os.WriteFile(previousImageFile, []byte(digest+"\n"), 0644)).To(Succeed())
+		c := &CompareLayers{Params: &CompareLayersParams{ImageUrl: "quay.io/org/app:v2", PreviousImageFile: previousImageFile}}
+
+		g.Expect(c.validateParams()).To(Succeed())
+		g.Expect(c.previousImage).To(Equal("quay.io/org/app@" + digest))
+	})
+
+	t.Run("should accept full previous image reference", func(t *testing.T) {
+		c := &CompareLayers{Params: &CompareLayersParams{ImageUrl: "quay.io/org/app:v2", PreviousImage: "quay.io/org/app:v1"}}
+
+		g.Expect(c.validateParams()).To(Succeed())
+		g.Expect(c.previousImage).To(Equal("quay.io/org/app:v1"))
+	})
+
+	t.Run("should fail without previous image", func(t *testing.T) {
+		c := &CompareLayers{Params: &CompareLayersParams{ImageUrl: "quay.io/org/app:v2"}}
+
+		err := c.validateParams()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("previous-image or previous-image-file is required"))
+	})
+
+	t.Run("should fail if both previous image params are set", func(t *testing.T) {
+		c := &CompareLayers{Params: &CompareLayersParams{ImageUrl: "quay.io/org/app:v2", PreviousImage: digest, PreviousImageFile: "/some/file"}}
+
+		err := c.validateParams()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("mutually exclusive"))
+	})
+}
+
+func Test_CompareLayers_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	const previousConfig = `{
+		"rootfs": {"type": "layers", "diff_ids": ["sha256:base", "sha256:deps", "sha256:src"]},
+		"history": [
+			{"created_by": "/bin/sh -c #(nop) ADD file:123 in / "},
+			{"created_by": "/bin/sh -c #(nop) ENV FOO=bar", "empty_layer": true},
+			{"created_by": "/bin/sh -c dnf install -y make"},
+			{"created_by": "/bin/sh -c #(nop) COPY dir:abc in /src "}
+		]
+	}`
+	const currentConfig = `{
+		"rootfs": {"type": "layers", "diff_ids": ["sha256:base", "sha256:deps", "sha256:src2"]},
+		"history": [
+			{"created_by": "/bin/sh -c #(nop) ADD file:123 in / "},
+			{"created_by": "/bin/sh -c #(nop) ENV FOO=bar", "empty_layer": true},
+			{"created_by": "/bin/sh -c dnf install -y make"},
+			{"created_by": "/bin/sh -c #(nop) COPY dir:def in /src "}
+		]
+	}`
+
+	t.Run("should compare layers of the two images", func(t *testing.T) {
+		_mockSkopeoCli := &mockSkopeoCli{}
+		_mockResultsWriter := &mockResultsWriter{}
+		c := &CompareLayers{
+			CliWrappers: CompareLayersCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params: &CompareLayersParams{
+				ImageUrl:                  "quay.io/org/app:v2",
+				PreviousImage:             "quay.io/org/app:v1",
+				TLSVerify:                 true,
+				ResultPathReusePercentage: "/tmp/reuse",
+			},
+			ResultsWriter: _mockResultsWriter,
+		}
+
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			g.Expect(args.Config).To(BeTrue())
+			g.Expect(args.ExtraArgs).To(BeEmpty())
+			switch args.ImageRef {
+			case "quay.io/org/app:v1":
+				return previousConfig, nil
+			case "quay.io/org/app:v2":
+				return currentConfig, nil
+			}
+			return "", errors.New("unexpected image")
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Results).To(Equal(CompareLayersResults{
+			TotalLayers:             3,
+			ReusedLayers:            2,
+			ReusePercentage:         66.7,
+			FirstChangedInstruction: "/bin/sh -c #(nop) COPY dir:def in /src",
+			ChangedInstructions:     []string{"/bin/sh -c #(nop) COPY dir:def in /src"},
+		}))
+		g.Expect(_mockResultsWriter.WrittenResults).To(HaveKeyWithValue("/tmp/reuse", "66.7"))
+	})
+
+	t.Run("should error if inspect fails", func(t *testing.T) {
+		_mockSkopeoCli := &mockSkopeoCli{}
+		c := &CompareLayers{
+			CliWrappers:   CompareLayersCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params:        &CompareLayersParams{ImageUrl: "quay.io/org/app:v2", PreviousImage: "quay.io/org/app:v1"},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			g.Expect(args.ExtraArgs).To(Equal([]string{"--tls-verify=false"}))
+			return "", errors.New("manifest unknown")
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("inspecting config of quay.io/org/app:v2"))
+	})
+}
@@ -0,0 +1,347 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+)
+
+// The JSON representation of a Containerfile, as written by --containerfile-json-output.
+// The dockerfile-json library can only marshal the instructions, these types cover
+// the fields needed to turn the JSON back into a Containerfile.
+type containerfileJson struct {
+	MetaArgs []struct {
+		Key   string
+		Value *string
+	}
+	Stages []containerfileJsonStage
+}
+
+type containerfileJsonStage struct {
+	BaseName string
+	Platform string
+	As       string
+	Commands []containerfileJsonCommand
+}
+
+type containerfileJsonKeyValue struct {
+	Key   string
+	Value string
+}
+
+type containerfileJsonCommand struct {
+	Name string
+
+	// ARG
+	Args []struct {
+		Key   string
+		Value *string
+	}
+	// ENV, LABEL
+	Env    []containerfileJsonKeyValue
+	Labels []containerfileJsonKeyValue
+
+	// RUN, CMD, ENTRYPOINT
+	CmdLine      []string
+	PrependShell bool
+	Files        []json.RawMessage
+	Mounts       []*instructions.Mount
+	NetworkMode  string
+	Security     string
+
+	// COPY, ADD
+	SourcePaths     []string
+	SourceContents  []json.RawMessage
+	DestPath        string
+	From            string
+	Chown           string
+	Chmod           string
+	Checksum        string
+	Link            bool
+	Parents         bool
+	KeepGitDir      *bool
+	Unpack          *bool
+	ExcludePatterns []string
+
+	Path       string   // WORKDIR
+	User       string   // USER
+	Ports      []string // EXPOSE
+	Volumes    []string // VOLUME
+	Shell      []string // SHELL
+	Signal     string   // STOPSIGNAL
+	Expression string   // ONBUILD
+	Maintainer string   // MAINTAINER
+	Health     *struct {
+		Test          []string
+		Interval      time.Duration
+		Timeout       time.Duration
+		StartPeriod   time.Duration
+		StartInterval time.Duration
+		Retries       int
+	}
+}
+
+// renderContainerfileFromJson converts the JSON representation of a Containerfile back to
+// a Containerfile. The values in the JSON are already expanded, they are quoted so that
+// they don't get expanded again.
+func renderContainerfileFromJson(data []byte) (string, error) {
+	var cf containerfileJson
+	if err := json.Unmarshal(data, &cf); err != nil {
+		return "", fmt.Errorf("parsing Containerfile JSON: %w", err)
+	}
+	if len(cf.Stages) == 0 {
+		return "", fmt.Errorf("Containerfile JSON has no stages")
+	}
+
+	var sb strings.Builder
+	for _, arg := range cf.MetaArgs {
+		word, err := renderArgWord(arg.Key, arg.Value)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("ARG " + word + "\n")
+	}
+
+	for i, stage := range cf.Stages {
+		from := "FROM "
+		if stage.Platform != "" {
+			from += "--platform=" + stage.Platform + " "
+		}
+		from += stage.BaseName
+		if stage.As != "" {
+			from += " AS " + stage.As
+		}
+		sb.WriteString(from + "\n")
+
+		for j, cmd := range stage.Commands {
+			line, err := renderCommand(&cmd)
+			if err != nil {
+				return "", fmt.Errorf("stage %d, instruction %d: %w", i, j, err)
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+func renderCommand(cmd *containerfileJsonCommand) (string, error) {
+	switch cmd.Name {
+	case "ARG":
+		var words []string
+		for _, arg := range cmd.Args {
+			word, err := renderArgWord(arg.Key, arg.Value)
+			if err != nil {
+				return "", err
+			}
+			words = append(words, word)
+		}
+		return "ARG " + strings.Join(words, " "), nil
+	case "ENV", "LABEL":
+		pairs := cmd.Env
+		if cmd.Name == "LABEL" {
+			pairs = cmd.Labels
+		}
+		var words []string
+		for _, kv := range pairs {
+			key, err := quoteContainerfileWord(kv.Key)
+			if err != nil {
+				return "", err
+			}
+			value, err := quoteContainerfileWord(kv.Value)
+			if err != nil {
+				return "", err
+			}
+			words = append(words, key+"="+value)
+		}
+		return cmd.Name + " " + strings.Join(words, " "), nil
+	case "RUN":
+		if len(cmd.Files) > 0 {
+			return "", fmt.Errorf("RUN with heredocs is not supported")
+		}
+		var flags []string
+		for _, mount := range cmd.Mounts {
+			flags = append(flags, "--mount="+renderMount(mount))
+		}
+		if cmd.NetworkMode != "" && cmd.NetworkMode != instructions.NetworkDefault {
+			flags = append(flags, "--network="+cmd.NetworkMode)
+		}
+		if cmd.Security != "" && cmd.Security != "sandbox" {
+			flags = append(flags, "--security="+cmd.Security)
+		}
+		return renderCmdLine("RUN", flags, cmd.CmdLine, cmd.PrependShell)
+	case "CMD", "ENTRYPOINT":
+		return renderCmdLine(cmd.Name, nil, cmd.CmdLine, cmd.PrependShell)
+	case "COPY", "ADD":
+		if len(cmd.SourceContents) > 0 {
+			return "", fmt.Errorf("%s with heredocs is not supported", cmd.Name)
+		}
+		var flags []string
+		if cmd.From != "" {
+			flags = append(flags, "--from="+cmd.From)
+		}
+		if cmd.Chown != "" {
+			flags = append(flags, "--chown="+cmd.Chown)
+		}
+		if cmd.Chmod != "" {
+			flags = append(flags, "--chmod="+cmd.Chmod)
+		}
+		if cmd.Checksum != "" {
+			flags = append(flags, "--checksum="+cmd.Checksum)
+		}
+		if cmd.Link {
+			flags = append(flags, "--link")
+		}
+		if cmd.Parents {
+			flags = append(flags, "--parents")
+		}
+		if cmd.KeepGitDir != nil {
+			flags = append(flags, fmt.Sprintf("--keep-git-dir=%t", *cmd.KeepGitDir))
+		}
+		if cmd.Unpack != nil {
+			flags = append(flags, fmt.Sprintf("--unpack=%t", *cmd.Unpack))
+		}
+		for _, pattern := range cmd.ExcludePatterns {
+			flags = append(flags, "--exclude="+pattern)
+		}
+		return renderJsonForm(cmd.Name, flags, append(cmd.SourcePaths, cmd.DestPath))
+	case "WORKDIR":
+		return "WORKDIR " + cmd.Path, nil
+	case "USER":
+		return "USER " + cmd.User, nil
+	case "EXPOSE":
+		return "EXPOSE " + strings.Join(cmd.Ports, " "), nil
+	case "VOLUME":
+		return renderJsonForm("VOLUME", nil, cmd.Volumes)
+	case "SHELL":
+		return renderJsonForm("SHELL", nil, cmd.Shell)
+	case "STOPSIGNAL":
+		return "STOPSIGNAL " + cmd.Signal, nil
+	case "ONBUILD":
+		return "ONBUILD " + cmd.Expression, nil
+	case "MAINTAINER":
+		return "MAINTAINER " + cmd.Maintainer, nil
+	case "HEALTHCHECK":
+		return renderHealthcheck(cmd)
+	}
+	return "", fmt.Errorf("unsupported instruction %q", cmd.Name)
+}
+
+func renderArgWord(key string, value *string) (string, error) {
+	if value == nil {
+		return key, nil
+	}
+	quoted, err := quoteContainerfileWord(*value)
+	if err != nil {
+		return "", err
+	}
+	return key + "=" + quoted, nil
+}
+
+func renderCmdLine(name string, flags []string, cmdLine []string, prependShell bool) (string, error) {
+	if !prependShell {
+		return renderJsonForm(name, flags, cmdLine)
+	}
+	line := strings.Join(cmdLine, " ")
+	if strings.Contains(line, "\n") {
+		return "", fmt.Errorf("multi-line %s is not supported", name)
+	}
+	return strings.Join(append(append([]string{name}, flags...), line), " "), nil
+}
+
+func renderJsonForm(name string, flags []string, args []string) (string, error) {
+	if args == nil {
+		args = []string{}
+	}
+	jsonArgs, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append(append([]string{name}, flags...), string(jsonArgs)), " "), nil
+}
+
+func renderMount(mount *instructions.Mount) string {
+	opts := []string{"type=" + string(mount.Type)}
+	add := func(key, value string) {
+		if value != "" {
+			opts = append(opts, key+"="+value)
+		}
+	}
+
+	add("from", mount.From)
+	add("source", mount.Source)
+	add("target", mount.Target)
+	add("id", mount.CacheID)
+	add("sharing", string(mount.CacheSharing))
+	if mount.Env != nil {
+		add("env", *mount.Env)
+	}
+	if mount.Required {
+		opts = append(opts, "required=true")
+	}
+	if mount.SizeLimit > 0 {
+		opts = append(opts, fmt.Sprintf("size=%d", mount.SizeLimit))
+	}
+	if mount.Mode != nil {
+		opts = append(opts, fmt.Sprintf("mode=0%o", *mount.Mode))
+	}
+	if mount.UID != nil {
+		opts = append(opts, fmt.Sprintf("uid=%d", *mount.UID))
+	}
+	if mount.GID != nil {
+		opts = append(opts, fmt.Sprintf("gid=%d", *mount.GID))
+	}
+
+	// bind mounts are read-only by default, cache mounts are writable
+	switch {
+	case mount.Type == instructions.MountTypeBind && !mount.ReadOnly:
+		opts = append(opts, "rw")
+	case mount.Type == instructions.MountTypeCache && mount.ReadOnly:
+		opts = append(opts, "ro")
+	}
+	return strings.Join(opts, ",")
+}
+
+func renderHealthcheck(cmd *containerfileJsonCommand) (string, error) {
+	if cmd.Health == nil || len(cmd.Health.Test) == 0 {
+		return "", fmt.Errorf("HEALTHCHECK without test")
+	}
+	health := cmd.Health
+	if health.Test[0] == "NONE" {
+		return "HEALTHCHECK NONE", nil
+	}
+
+	var flags []string
+	addDuration := func(flag string, d time.Duration) {
+		if d != 0 {
+			flags = append(flags, "--"+flag+"="+d.String())
+		}
+	}
+	addDuration("interval", health.Interval)
+	addDuration("timeout", health.Timeout)
+	addDuration("start-period", health.StartPeriod)
+	addDuration("start-interval", health.StartInterval)
+	if health.Retries != 0 {
+		flags = append(flags, fmt.Sprintf("--retries=%d", health.Retries))
+	}
+
+	flags = append([]string{"HEALTHCHECK"}, flags...)
+	switch health.Test[0] {
+	case "CMD-SHELL":
+		return renderCmdLine(strings.Join(append(flags, "CMD"), " "), nil, health.Test[1:], true)
+	case "CMD":
+		return renderJsonForm(strings.Join(append(flags, "CMD"), " "), nil, health.Test[1:])
+	}
+	return "", fmt.Errorf("unsupported HEALTHCHECK test %q", health.Test[0])
+}
+
+// Quote a value for instructions that expand variables (ARG, ENV, LABEL).
+func quoteContainerfileWord(s string) (string, error) {
+	if strings.Contains(s, "\n") {
+		return "", fmt.Errorf("multi-line value %q is not supported", s)
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`).Replace(s)
+	return `"` + escaped + `"`, nil
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/keilerkonzept/dockerfile-json/pkg/dockerfile"
+	. "github.com/onsi/gomega"
+)
+
+func Test_renderContainerfileFromJson(t *testing.T) {
+	g := NewWithT(t)
+
+	noBuildArgs := func(string) (string, error) { return "", os.ErrNotExist }
+
+	parseToJson := func(content string) []byte {
+		tempDir := t.TempDir()
+		containerfilePath := filepath.Join(tempDir, "Containerfile")
+		g.Expect(os.WriteFile(containerfilePath, []byte(content), 0644)).To(Succeed())
+		df, err := dockerfile.Parse(containerfilePath)
+		g.Expect(err).ToNot(HaveOccurred())
+		df.Expand(noBuildArgs)
+
+		jsonPath := filepath.Join(tempDir, "containerfile.json")
+		g.Expect((&Build{}).writeContainerfileJson(df, jsonPath)).To(Succeed())
+		data, err := os.ReadFile(jsonPath)
+		g.Expect(err).ToNot(HaveOccurred())
+		return data
+	}
+
+	t.Run("should round-trip the Containerfile JSON", func(t *testing.T) {
+		original := parseToJson(`ARG BASE=registry.example.com/base:1
+FROM --platform=linux/amd64 $BASE AS builder
+ARG VERSION=1.0 EMPTY
+ENV PRICE='$5' QUOTED="a \"b\" c"
+LABEL version=$VERSION
+RUN --mount=type=secret,id=token,required=true --mount=type=cache,target=/root/.cache,id=pip --network=none make build
+RUN ["/bin/sh", "-c", "echo hi"]
+COPY --from=builder --chown=1001:0 --chmod=644 src/ lib/ /opt/
+ADD --checksum=sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d https://example.com/archive.tar.gz /tmp/
+WORKDIR /opt
+USER 1001
+EXPOSE 8080/tcp
+VOLUME /data
+HEALTHCHECK --interval=30s --retries=3 CMD curl -f http://localhost/
+SHELL ["/bin/bash", "-c"]
+STOPSIGNAL SIGTERM
+ONBUILD RUN echo onbuild
+ENTRYPOINT ["/opt/app"]
+CMD echo done
+
+FROM scratch
+COPY --from=builder /opt /opt
+`)
+
+		rendered, err := renderContainerfileFromJson(original)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		// Compare the fields that make up the build, the source locations naturally differ
+		var expected, actual containerfileJson
+		g.Expect(json.Unmarshal(original, &expected)).To(Succeed())
+		g.Expect(json.Unmarshal(parseToJson(rendered), &actual)).To(Succeed())
+		g.Expect(actual).To(Equal(expected))
+	})
+
+	t.Run("should fail on unsupported instructions", func(t *testing.T) {
+		_, err := renderContainerfileFromJson([]byte(`{"Stages": [{"BaseName": "scratch", "Commands": [{"Name": "FOO"}]}]}`))
+		g.Expect(err).To(MatchError(ContainSubstring(`unsupported instruction "FOO"`)))
+	})
+
+	t.Run("should fail on heredocs", func(t *testing.T) {
+		_, err := renderContainerfileFromJson([]byte(`{"Stages": [{"BaseName": "scratch", "Commands": [{"Name": "RUN", "Files": [{"Name": "EOF"}]}]}]}`))
+		g.Expect(err).To(MatchError(ContainSubstring("heredocs")))
+	})
+
+	t.Run("should fail without stages", func(t *testing.T) {
+		_, err := renderContainerfileFromJson([]byte(`{"Stages": []}`))
+		g.Expect(err).To(MatchError(ContainSubstring("no stages")))
+	})
+}
+
+func Test_Build_detectContainerfile_fromJson(t *testing.T) {
+	g := NewWithT(t)
+
+	jsonPath := filepath.Join(t.TempDir(), "containerfile.json")
+	g.Expect(os.WriteFile(jsonPath, []byte(`{"Stages": [{"BaseName": "registry.access.redhat.com/ubi9", "Commands": [{"Name": "USER", "User": "1001"}]}]}`), 0644)).To(Succeed())
+
+	c := &Build{Params: &BuildParams{Context: ".", ContainerfileJson: jsonPath}}
+	defer c.cleanup()
+
+	g.Expect(c.detectContainerfile()).To(Succeed())
+	g.Expect(c.containerfilePath).To(HavePrefix(c.tempWorkdir))
+	content, err := os.ReadFile(c.containerfilePath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).To(Equal("FROM registry.access.redhat.com/ubi9\nUSER 1001\n"))
+}
@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var ConvertParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_CONVERT_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Image to convert, referenced by tag and/or digest. Required.",
+		Required:   true,
+	},
+	"format": {
+		Name:       "format",
+		ShortName:  "f",
+		EnvVarName: "KBC_CONVERT_FORMAT",
+		TypeKind:   reflect.String,
+		Usage:      "Target manifest format: 'oci' or 'docker' (Docker schema2). Required.",
+		Required:   true,
+	},
+	"output-ref": {
+		Name:       "output-ref",
+		ShortName:  "t",
+		EnvVarName: "KBC_CONVERT_OUTPUT_REF",
+		TypeKind:   reflect.String,
+		Usage:      "Where to push the converted image - registry/namespace/name:tag.\nDefaults to --image-url without the digest, i.e. the image is re-pushed in place.",
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_CONVERT_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Require HTTPS and verify certificates when accessing the registries.",
+	},
+	"result-path-image-digest": {
+		Name:       "result-path-image-digest",
+		EnvVarName: "KBC_CONVERT_RESULT_PATH_IMAGE_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Write the digest of the converted image into this file.",
+	},
+}
+
+type ConvertParams struct {
+	ImageUrl              string `paramName:"image-url"`
+	Format                string `paramName:"format"`
+	OutputRef             string `paramName:"output-ref"`
+	TLSVerify             bool   `paramName:"tls-verify"`
+	ResultPathImageDigest string `paramName:"result-path-image-digest"`
+}
+
+type ConvertCliWrappers struct {
+	SkopeoCli cliWrappers.SkopeoCliInterface
+}
+
+type ConvertResults struct {
+	ImageUrl string `json:"image_url"`
+	Digest   string `json:"digest"`
+}
+
+type Convert struct {
+	Params        *ConvertParams
+	CliWrappers   ConvertCliWrappers
+	Results       ConvertResults
+	ResultsWriter common.ResultsWriterInterface
+
+	sourceImage      string
+	destinationImage string
+}
+
+func NewConvert(cmd *cobra.Command) (*Convert, error) {
+	convert := &Convert{}
+
+	params := &ConvertParams{}
+	if err := common.ParseParameters(cmd, ConvertParamsConfig, params); err != nil {
+		return nil, err
+	}
+	convert.Params = params
+
+	if err := convert.initCliWrappers(); err != nil {
+		return nil, err
+	}
+
+	convert.ResultsWriter = common.NewResultsWriter()
+
+	return convert, nil
+}
+
+func (c *Convert) initCliWrappers() error {
+	executor := cliWrappers.NewCliExecutor()
+
+	skopeoCli, err := cliWrappers.NewSkopeoCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.SkopeoCli = skopeoCli
+	return nil
+}
+
+// Run executes the command logic.
+func (c *Convert) Run() error {
+	common.LogParameters(ConvertParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	// skopeo doesn't accept references with both a tag and a digest
+	c.sourceImage = common.NormalizeImageRefWithDigest(c.Params.ImageUrl)
+
+	digest, err := c.convertImage()
+	if err != nil {
+		return err
+	}
+
+	c.Results.ImageUrl = c.destinationImage
+	c.Results.Digest = digest
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	if err := c.ResultsWriter.WriteResultString(digest, c.Params.ResultPathImageDigest); err != nil {
+		return fmt.Errorf("failed to write image digest result: %w", err)
+	}
+
+	return nil
+}
+
+// convertImage copies the image (all architectures, if it's an index) to the destination,
+// converting the manifests to the requested format. Returns the digest of the pushed manifest.
+func (c *Convert) convertImage() (string, error) {
+	digestFile, err := os.CreateTemp("", "skopeo-digest-")
+	if err != nil {
+		return "", err
+	}
+	if err := digestFile.Close(); err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(digestFile.Name()) }()
+
+	var extraArgs []string
+	if !c.Params.TLSVerify {
+		extraArgs = append(extraArgs, "--src-tls-verify=false", "--dest-tls-verify=false")
+	}
+
+	l.Logger.Infof("Converting %s to %s format: %s", c.sourceImage, c.Params.Format, c.destinationImage)
+
+	err = c.CliWrappers.SkopeoCli.Copy(&cliWrappers.SkopeoCopyArgs{
+		SourceImage:      c.sourceImage,
+		DestinationImage: c.destinationImage,
+		MultiArch:        cliWrappers.SkopeoCopyArgMultiArchAll,
+		Format:           skopeoFormat(c.Params.Format),
+		DigestFile:       digestFile.Name(),
+		RetryTimes:       3,
+		ExtraArgs:        extraArgs,
+	})
+	if err != nil {
+		return "", fmt.Errorf("converting %s: %w", c.sourceImage, err)
+	}
+
+	content, err := os.ReadFile(digestFile.Name()) //nolint:gosec // digestFile is a controlled temp file path
+	if err != nil {
+		return "", fmt.Errorf("reading digest of the converted image: %w", err)
+	}
+	digest := strings.TrimSpace(string(content))
+
+	l.Logger.Infof("Converted image pushed: %s@%s", common.GetImageName(c.destinationImage), digest)
+
+	return digest, nil
+}
+
+// skopeoFormat maps the user-facing format name (same as buildah's --format) to skopeo's --format.
+func skopeoFormat(format string) cliWrappers.SkopeoCopyArgFormat {
+	if format == "docker" {
+		return cliWrappers.SkopeoCopyArgFormatV2s2
+	}
+	return cliWrappers.SkopeoCopyArgFormatOCI
+}
+
+func (c *Convert) validateParams() error {
+	if c.Params.Format != "oci" && c.Params.Format != "docker" {
+		return fmt.Errorf("format must be 'oci' or 'docker', got '%s'", c.Params.Format)
+	}
+
+	if !common.IsImageNameValid(common.GetImageName(c.Params.ImageUrl)) {
+		return fmt.Errorf("image '%s' is invalid", c.Params.ImageUrl)
+	}
+	if err := common.ValidateImageHasTagOrDigest(c.Params.ImageUrl); err != nil {
+		return err
+	}
+
+	destination := c.Params.OutputRef
+	if destination == "" {
+		destination = common.GetImageURL(c.Params.ImageUrl)
+	}
+	ref, err := common.ParseReference(destination)
+	if err != nil {
+		return fmt.Errorf("output-ref '%s' is invalid: %w", destination, err)
+	}
+	if ref.Digest != "" {
+		return fmt.Errorf("output-ref '%s' must not contain a digest, conversion changes the digest", destination)
+	}
+	if ref.Tag == "" {
+		if c.Params.OutputRef == "" {
+			return fmt.Errorf("output-ref is required when image-url '%s' has no tag", c.Params.ImageUrl)
+		}
+		return fmt.Errorf("output-ref '%s' must have a tag", destination)
+	}
+	c.destinationImage = destination
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Convert_validateParams(t *testing.T) {
+	tests := []struct {
+		name                string
+		params              ConvertParams
+		expectedDestination string
+		errSubstring        string
+	}{
+		{
+			name:                "should re-push in place by default",
+			params:              ConvertParams{ImageUrl: "quay.io/org/app:v1", Format: "docker"},
+			expectedDestination: "quay.io/org/app:v1",
+		},
+		{
+			name:                "should strip digest from default destination",
+			params:              ConvertParams{ImageUrl: "quay.io/org/app:v1@sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b", Format: "oci"},
+			expectedDestination: "quay.io/org/app:v1",
+		},
+		{
+			name: "should use explicit output-ref",
+			params: ConvertParams{
+				ImageUrl:  "quay.io/org/app@sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b",
+				Format:    "docker",
+				OutputRef: "quay.io/org/app:v1-docker",
+			},
+			expectedDestination: "quay.io/org/app:v1-docker",
+		},
+		{
+			name:         "should fail on invalid format",
+			params:       ConvertParams{ImageUrl: "quay.io/org/app:v1", Format: "v2s2"},
+			errSubstring: "format must be 'oci' or 'docker'",
+		},
+		{
+			name:         "should fail if image has neither tag nor digest",
+			params:       ConvertParams{ImageUrl: "quay.io/org/app", Format: "oci"},
+			errSubstring: "must have a tag or digest",
+		},
+		{
+			name:         "should require output-ref for digest-only image",
+			params:       ConvertParams{ImageUrl: "quay.io/org/app@sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b", Format: "oci"},
+			errSubstring: "output-ref is required",
+		},
+		{
+			name: "should fail if output-ref has a digest",
+			params: ConvertParams{
+				ImageUrl:  "quay.io/org/app:v1",
+				Format:    "oci",
+				OutputRef: "quay.io/org/app@sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b",
+			},
+			errSubstring: "must not contain a digest",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &Convert{Params: &tc.params}
+
+			err := c.validateParams()
+
+			if tc.errSubstring != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.errSubstring))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(c.destinationImage).To(Equal(tc.expectedDestination))
+			}
+		})
+	}
+}
+
+func Test_Convert_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+
+	var _mockSkopeoCli *mockSkopeoCli
+	var _mockResultsWriter *mockResultsWriter
+	var c *Convert
+
+	beforeEach := func() {
+		_mockSkopeoCli = &mockSkopeoCli{}
+		_mockResultsWriter = &mockResultsWriter{}
+		c = &Convert{
+			CliWrappers: ConvertCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params: &ConvertParams{
+				ImageUrl:              "quay.io/org/app:v1@" + digest,
+				Format:                "docker",
+				TLSVerify:             true,
+				ResultPathImageDigest: "/tmp/digest-result",
+			},
+			ResultsWriter: _mockResultsWriter,
+		}
+	}
+
+	t.Run("should convert image to docker format", func(t *testing.T) {
+		beforeEach()
+
+		isCopyCalled := false
+		_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			isCopyCalled = true
+			g.Expect(args.SourceImage).To(Equal("quay.io/org/app@" + digest))
+			g.Expect(args.DestinationImage).To(Equal("quay.io/org/app:v1"))
+			g.Expect(args.Format).To(Equal(cliwrappers.SkopeoCopyArgFormatV2s2))
+			g.Expect(args.MultiArch).To(Equal(cliwrappers.SkopeoCopyArgMultiArchAll))
+			g.Expect(args.ExtraArgs).To(BeEmpty())
+			return os.WriteFile(args.DigestFile, []byte("sha256:abcdef\n"), 0644)
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(isCopyCalled).To(BeTrue())
+		g.Expect(c.Results.ImageUrl).To(Equal("quay.io/org/app:v1"))
+		g.Expect(c.Results.Digest).To(Equal("sha256:abcdef"))
+		g.Expect(_mockResultsWriter.WrittenResults).To(HaveKeyWithValue("/tmp/digest-result", "sha256:abcdef"))
+	})
+
+	t.Run("should disable TLS verification", func(t *testing.T) {
+		beforeEach()
+		c.Params.TLSVerify = false
+
+		_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			g.Expect(args.ExtraArgs).To(ConsistOf("--src-tls-verify=false", "--dest-tls-verify=false"))
+			return nil
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+	})
+
+	t.Run("should error if skopeo copy fails", func(t *testing.T) {
+		beforeEach()
+
+		_mockSkopeoCli.CopyFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			return errors.New("copy failed")
+		}
+
+		err := c.Run()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("copy failed"))
+	})
+}
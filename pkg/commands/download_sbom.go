@@ -0,0 +1,305 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// Where to look for the SBOM of an image (--source)
+const (
+	sbomSourceAuto      = "auto"
+	sbomSourceTag       = "tag"
+	sbomSourceReferrers = "referrers"
+)
+
+// Media types of SBOM layers, in addition to sbomMediaTypes. cosign attach sbom uses text/spdx+json.
+var sbomLayerMediaTypes = []string{"text/spdx+json"}
+
+var DownloadSBOMParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_DOWNLOAD_SBOM_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Binary image URL. The SBOM is looked up in the image repository where this binary image is.",
+		Required:   true,
+	},
+	"digest": {
+		Name:       "digest",
+		ShortName:  "d",
+		EnvVarName: "KBC_DOWNLOAD_SBOM_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Digest of the binary image the SBOM describes.",
+		Required:   true,
+	},
+	"output": {
+		Name:       "output",
+		ShortName:  "o",
+		EnvVarName: "KBC_DOWNLOAD_SBOM_OUTPUT",
+		TypeKind:   reflect.String,
+		Usage:      "Write the SBOM into this file and print the results JSON. By default, the SBOM is printed.",
+	},
+	"source": {
+		Name:         "source",
+		ShortName:    "s",
+		EnvVarName:   "KBC_DOWNLOAD_SBOM_SOURCE",
+		TypeKind:     reflect.String,
+		DefaultValue: sbomSourceAuto,
+		Usage: "Where to look for the SBOM: 'tag' for the artifact tagged after the image digest (push-sbom, cosign attach sbom), " +
+			"'referrers' for an OCI 1.1 referrer (push-sbom --attach), 'auto' to try the tag, then the referrers.",
+	},
+	"tag-suffix": {
+		Name:         "tag-suffix",
+		ShortName:    "t",
+		EnvVarName:   "KBC_DOWNLOAD_SBOM_TAG_SUFFIX",
+		TypeKind:     reflect.String,
+		DefaultValue: sbomArtifactTagSuffix,
+		Usage:        "Suffix of the SBOM artifact tag. Not used with --source referrers.",
+	},
+}
+
+type DownloadSBOMParams struct {
+	ImageUrl  string `paramName:"image-url"`
+	Digest    string `paramName:"digest"`
+	Output    string `paramName:"output"`
+	Source    string `paramName:"source"`
+	TagSuffix string `paramName:"tag-suffix"`
+}
+
+type DownloadSBOMResults struct {
+	// Digested reference of the SBOM artifact
+	SBOMRef string `json:"sbom_ref"`
+	// The SBOM format, cyclonedx or spdx
+	Format   string `json:"format"`
+	SBOMPath string `json:"sbom_path"`
+}
+
+type DownloadSBOMCliWrappers struct {
+	OrasCli cliwrappers.OrasCliInterface
+}
+
+type DownloadSBOM struct {
+	Params        *DownloadSBOMParams
+	CliWrappers   DownloadSBOMCliWrappers
+	Results       DownloadSBOMResults
+	ResultsWriter common.ResultsWriterInterface
+
+	imageName      string
+	registryConfig string
+}
+
+func NewDownloadSBOM(cmd *cobra.Command) (*DownloadSBOM, error) {
+	params := &DownloadSBOMParams{}
+	if err := common.ParseParameters(cmd, DownloadSBOMParamsConfig, params); err != nil {
+		return nil, err
+	}
+	downloadSBOM := &DownloadSBOM{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}
+	if err := downloadSBOM.initCliWrappers(); err != nil {
+		return nil, err
+	}
+	return downloadSBOM, nil
+}
+
+func (c *DownloadSBOM) initCliWrappers() error {
+	executor := cliwrappers.NewCliExecutor()
+	orasCli, err := cliwrappers.NewOrasCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.OrasCli = orasCli
+	return nil
+}
+
+func (c *DownloadSBOM) Run() error {
+	common.LogParameters(DownloadSBOMParamsConfig, c.Params)
+
+	c.imageName = common.GetImageName(c.Params.ImageUrl)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	registryConfig, err := common.WriteOrasRegistryConfig(c.Params.ImageUrl)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(registryConfig); err != nil {
+			l.Logger.Warnf("failed to remove %s: %s", registryConfig, err.Error())
+		}
+	}()
+	c.registryConfig = registryConfig
+
+	sbomRef, manifest, err := c.findSBOMArtifact()
+	if err != nil {
+		return err
+	}
+	layer, err := sbomLayer(manifest)
+	if err != nil {
+		return fmt.Errorf("SBOM artifact %s: %w", sbomRef, err)
+	}
+
+	sbomPath := c.Params.Output
+	if sbomPath == "" {
+		tmpDir, err := os.MkdirTemp("", "download-sbom-")
+		if err != nil {
+			return fmt.Errorf("error on creating temporary directory: %w", err)
+		}
+		defer func() {
+			if err := os.RemoveAll(tmpDir); err != nil {
+				l.Logger.Warnf("failed to remove '%s' directory: %s", tmpDir, err.Error())
+			}
+		}()
+		sbomPath = filepath.Join(tmpDir, "sbom.json")
+	}
+
+	err = c.CliWrappers.OrasCli.BlobFetch(&cliwrappers.OrasBlobFetchArgs{
+		Blob:           c.imageName + "@" + layer.Digest.String(),
+		OutputPath:     sbomPath,
+		RegistryConfig: c.registryConfig,
+	})
+	if err != nil {
+		return fmt.Errorf("error on downloading SBOM from %s: %w", sbomRef, err)
+	}
+
+	mediaType, err := detectSBOMMediaType(sbomPath)
+	if err != nil {
+		return err
+	}
+	l.Logger.Infof("Downloaded %s SBOM from %s", mediaType, sbomRef)
+
+	if c.Params.Output == "" {
+		content, err := os.ReadFile(sbomPath) //nolint:gosec // SBOM path is a controlled temp file
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(content))
+		return nil
+	}
+
+	c.Results.SBOMRef = sbomRef
+	c.Results.Format = sbomFormatOfMediaType(mediaType)
+	c.Results.SBOMPath = c.Params.Output
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	return nil
+}
+
+func (c *DownloadSBOM) validateParams() error {
+	if !common.IsImageNameValid(c.imageName) {
+		return fmt.Errorf("image name '%s' is invalid", c.imageName)
+	}
+
+	if !common.IsImageDigestValid(c.Params.Digest) {
+		return fmt.Errorf("image digest '%s' is invalid", c.Params.Digest)
+	}
+
+	if !slices.Contains([]string{sbomSourceAuto, sbomSourceTag, sbomSourceReferrers}, c.Params.Source) {
+		return fmt.Errorf("source must be '%s', '%s' or '%s', got '%s'", sbomSourceAuto, sbomSourceTag, sbomSourceReferrers, c.Params.Source)
+	}
+
+	if c.Params.Source != sbomSourceReferrers && !regexp.MustCompile(tagSuffixRegex).MatchString(c.Params.TagSuffix) {
+		return fmt.Errorf("tag suffix includes invalid characters or exceeds the max length of 57 characters")
+	}
+
+	return nil
+}
+
+// Find the SBOM artifact of the image. Return its digested reference and manifest.
+func (c *DownloadSBOM) findSBOMArtifact() (string, *specs.Manifest, error) {
+	if c.Params.Source != sbomSourceReferrers {
+		tag := strings.Replace(c.Params.Digest, ":", "-", 1) + c.Params.TagSuffix
+		ref, manifest, err := c.fetchManifest(c.imageName + ":" + tag)
+		if err == nil {
+			return ref, manifest, nil
+		}
+		if c.Params.Source == sbomSourceTag {
+			return "", nil, fmt.Errorf("error on fetching SBOM artifact %s:%s: %w", c.imageName, tag, err)
+		}
+		l.Logger.Infof("No SBOM artifact tagged %s, looking for referrers", tag)
+	}
+
+	subject := c.imageName + "@" + c.Params.Digest
+	referrers, err := c.CliWrappers.OrasCli.Discover(&cliwrappers.OrasDiscoverArgs{
+		Subject:        subject,
+		RegistryConfig: c.registryConfig,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("error on discovering referrers of %s: %w", subject, err)
+	}
+	for _, referrer := range referrers {
+		if !isSBOMMediaType(referrer.ArtifactType) {
+			continue
+		}
+		return c.fetchManifest(c.imageName + "@" + referrer.Digest)
+	}
+	return "", nil, fmt.Errorf("no SBOM found for %s", subject)
+}
+
+func (c *DownloadSBOM) fetchManifest(ref string) (string, *specs.Manifest, error) {
+	content, err := c.CliWrappers.OrasCli.ManifestFetch(&cliwrappers.OrasManifestFetchArgs{
+		Image:          ref,
+		RegistryConfig: c.registryConfig,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	var manifest specs.Manifest
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return "", nil, fmt.Errorf("parsing manifest of %s: %w", ref, err)
+	}
+	// oras prints the raw manifest, its digest is the digest of the artifact
+	return common.GetImageName(ref) + "@" + digest.FromString(content).String(), &manifest, nil
+}
+
+// The layer of an SBOM artifact with the SBOM, the only layer or the one with an SBOM media type.
+func sbomLayer(manifest *specs.Manifest) (specs.Descriptor, error) {
+	if len(manifest.Layers) == 1 {
+		return manifest.Layers[0], nil
+	}
+	for _, layer := range manifest.Layers {
+		if isSBOMMediaType(layer.MediaType) {
+			return layer, nil
+		}
+	}
+	if len(manifest.Layers) == 0 {
+		return specs.Descriptor{}, errors.New("no layers")
+	}
+	return specs.Descriptor{}, errors.New("no layer has an SBOM media type")
+}
+
+func isSBOMMediaType(mediaType string) bool {
+	return sbomFormatOfMediaType(mediaType) != "" || slices.Contains(sbomLayerMediaTypes, mediaType)
+}
+
+func sbomFormatOfMediaType(mediaType string) string {
+	for format, formatMediaType := range sbomMediaTypes {
+		if formatMediaType == mediaType {
+			return format
+		}
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_DownloadSBOM_Run(t *testing.T) {
+	const imageDigest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	const sbomTag = "quay.io/org/app:sha256-e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b.sbom"
+	const layerDigest = "sha256:a7c0071906a9c6b654760e44a1fc8226f8268c70848148f19c35b02788b272a5"
+	const manifest = `{"schemaVersion":2,"layers":[{"mediaType":"application/spdx+json","digest":"` + layerDigest + `","size":28}]}`
+	manifestDigest := digest.FromString(manifest).String()
+
+	workDir := t.TempDir()
+	t.Setenv("HOME", workDir)
+	os.Mkdir(filepath.Join(workDir, ".docker"), 0755)
+	os.WriteFile(filepath.Join(workDir, ".docker", "config.json"), []byte(`{"auths":{"quay.io":{"auth":"token"}}}`), 0644)
+
+	blobFetch := func(content string) func(args *cliwrappers.OrasBlobFetchArgs) error {
+		return func(args *cliwrappers.OrasBlobFetchArgs) error {
+			if args.Blob != "quay.io/org/app@"+layerDigest {
+				return errors.New("unexpected blob " + args.Blob)
+			}
+			return os.WriteFile(args.OutputPath, []byte(content), 0644)
+		}
+	}
+
+	newDownloadSBOM := func(orasCli *mockOrasCli) *DownloadSBOM {
+		return &DownloadSBOM{
+			Params: &DownloadSBOMParams{
+				ImageUrl:  "quay.io/org/app:v1",
+				Digest:    imageDigest,
+				Output:    filepath.Join(t.TempDir(), "sbom.json"),
+				Source:    sbomSourceAuto,
+				TagSuffix: sbomArtifactTagSuffix,
+			},
+			CliWrappers:   DownloadSBOMCliWrappers{OrasCli: orasCli},
+			ResultsWriter: &mockResultsWriter{},
+		}
+	}
+
+	t.Run("should download SBOM from the tag derived from the digest", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newDownloadSBOM(&mockOrasCli{
+			ManifestFetchFunc: func(args *cliwrappers.OrasManifestFetchArgs) (string, error) {
+				g.Expect(args.Image).To(Equal(sbomTag))
+				g.Expect(args.RegistryConfig).ToNot(BeEmpty())
+				return manifest, nil
+			},
+			BlobFetchFunc: blobFetch(`{"spdxVersion": "SPDX-2.3"}`),
+			DiscoverFunc: func(args *cliwrappers.OrasDiscoverArgs) ([]cliwrappers.OrasReferrer, error) {
+				return nil, errors.New("unexpected discover")
+			},
+		})
+
+		g.Expect(c.Run()).To(Succeed())
+
+		content, err := os.ReadFile(c.Params.Output)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(content)).To(Equal(`{"spdxVersion": "SPDX-2.3"}`))
+		g.Expect(c.Results).To(Equal(DownloadSBOMResults{
+			SBOMRef:  "quay.io/org/app@" + manifestDigest,
+			Format:   "spdx",
+			SBOMPath: c.Params.Output,
+		}))
+	})
+
+	t.Run("should fall back to the referrers", func(t *testing.T) {
+		g := NewWithT(t)
+		const referrerDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		var fetched []string
+		c := newDownloadSBOM(&mockOrasCli{
+			ManifestFetchFunc: func(args *cliwrappers.OrasManifestFetchArgs) (string, error) {
+				fetched = append(fetched, args.Image)
+				if args.Image == sbomTag {
+					return "", errors.New("not found")
+				}
+				return manifest, nil
+			},
+			DiscoverFunc: func(args *cliwrappers.OrasDiscoverArgs) ([]cliwrappers.OrasReferrer, error) {
+				g.Expect(args.Subject).To(Equal("quay.io/org/app@" + imageDigest))
+				return []cliwrappers.OrasReferrer{
+					{Digest: "sha256:0000", ArtifactType: "application/vnd.dev.cosign.artifact.sig.v1+json"},
+					{Digest: referrerDigest, ArtifactType: "application/spdx+json"},
+				}, nil
+			},
+			BlobFetchFunc: blobFetch(`{"spdxVersion": "SPDX-2.3"}`),
+		})
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(fetched).To(Equal([]string{sbomTag, "quay.io/org/app@" + referrerDigest}))
+		g.Expect(c.Results.SBOMRef).To(Equal("quay.io/org/app@" + manifestDigest))
+	})
+
+	t.Run("should not look for referrers with the tag source", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newDownloadSBOM(&mockOrasCli{
+			ManifestFetchFunc: func(args *cliwrappers.OrasManifestFetchArgs) (string, error) {
+				return "", errors.New("not found")
+			},
+			DiscoverFunc: func(args *cliwrappers.OrasDiscoverArgs) ([]cliwrappers.OrasReferrer, error) {
+				return nil, errors.New("unexpected discover")
+			},
+		})
+		c.Params.Source = sbomSourceTag
+
+		g.Expect(c.Run()).To(MatchError("error on fetching SBOM artifact " + sbomTag + ": not found"))
+	})
+
+	t.Run("should fail when no referrer is an SBOM", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newDownloadSBOM(&mockOrasCli{
+			DiscoverFunc: func(args *cliwrappers.OrasDiscoverArgs) ([]cliwrappers.OrasReferrer, error) {
+				return []cliwrappers.OrasReferrer{{Digest: "sha256:0000", ArtifactType: "application/vnd.dev.cosign.artifact.sig.v1+json"}}, nil
+			},
+		})
+		c.Params.Source = sbomSourceReferrers
+
+		g.Expect(c.Run()).To(MatchError("no SBOM found for quay.io/org/app@" + imageDigest))
+	})
+
+	t.Run("should fail when the downloaded file is not an SBOM", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newDownloadSBOM(&mockOrasCli{
+			ManifestFetchFunc: func(args *cliwrappers.OrasManifestFetchArgs) (string, error) {
+				return manifest, nil
+			},
+			BlobFetchFunc: blobFetch(`{"kind": "Pod"}`),
+		})
+
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("is neither a CycloneDX nor an SPDX document")))
+	})
+
+	t.Run("should fail for invalid source", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newDownloadSBOM(&mockOrasCli{})
+		c.Params.Source = "registry"
+
+		g.Expect(c.Run()).To(MatchError("source must be 'auto', 'tag' or 'referrers', got 'registry'"))
+	})
+}
+
+func Test_sbomLayer(t *testing.T) {
+	g := NewWithT(t)
+
+	layers := func(mediaTypes ...string) *specs.Manifest {
+		manifest := &specs.Manifest{}
+		for _, mediaType := range mediaTypes {
+			manifest.Layers = append(manifest.Layers, specs.Descriptor{MediaType: mediaType})
+		}
+		return manifest
+	}
+
+	layer, err := sbomLayer(layers("application/octet-stream"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(layer.MediaType).To(Equal("application/octet-stream"))
+
+	layer, err = sbomLayer(layers("text/plain", "text/spdx+json"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(layer.MediaType).To(Equal("text/spdx+json"))
+
+	_, err = sbomLayer(layers("text/plain", "text/html"))
+	g.Expect(err).To(MatchError("no layer has an SBOM media type"))
+
+	_, err = sbomLayer(layers())
+	g.Expect(err).To(MatchError("no layers"))
+}
@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var ExtractArtifactParamsConfig = map[string]common.Parameter{
+	"image-ref": {
+		Name:       "image-ref",
+		ShortName:  "i",
+		EnvVarName: "KBC_EXTRACT_ARTIFACT_IMAGE_REF",
+		TypeKind:   reflect.String,
+		Usage:      "Reference of the artifact to extract, by tag or by digest. The digest of the manifest is verified.",
+		Required:   true,
+	},
+	"output-dir": {
+		Name:       "output-dir",
+		ShortName:  "o",
+		EnvVarName: "KBC_EXTRACT_ARTIFACT_OUTPUT_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Directory to extract the artifact into. Created if it doesn't exist.",
+		Required:   true,
+	},
+	"unpack": {
+		Name:         "unpack",
+		EnvVarName:   "KBC_EXTRACT_ARTIFACT_UNPACK",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Unpack tarball layers into the output directory instead of saving them as files.",
+	},
+}
+
+type ExtractArtifactParams struct {
+	ImageRef  string `paramName:"image-ref"`
+	OutputDir string `paramName:"output-dir"`
+	Unpack    bool   `paramName:"unpack"`
+}
+
+type ExtractArtifactResults struct {
+	// Digested reference of the artifact
+	ImageRef     string `json:"image_ref"`
+	ArtifactType string `json:"artifact_type"`
+	// Titles of the extracted layers
+	Files []string `json:"files"`
+}
+
+type ExtractArtifactCliWrappers struct {
+	OrasCli cliwrappers.OrasCliInterface
+}
+
+type ExtractArtifact struct {
+	Params        *ExtractArtifactParams
+	CliWrappers   ExtractArtifactCliWrappers
+	Results       ExtractArtifactResults
+	ResultsWriter common.ResultsWriterInterface
+
+	imageName      string
+	registryConfig string
+}
+
+func NewExtractArtifact(cmd *cobra.Command) (*ExtractArtifact, error) {
+	params := &ExtractArtifactParams{}
+	if err := common.ParseParameters(cmd, ExtractArtifactParamsConfig, params); err != nil {
+		return nil, err
+	}
+	extractArtifact := &ExtractArtifact{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}
+	if err := extractArtifact.initCliWrappers(); err != nil {
+		return nil, err
+	}
+	return extractArtifact, nil
+}
+
+func (c *ExtractArtifact) initCliWrappers() error {
+	executor := cliwrappers.NewCliExecutor()
+	orasCli, err := cliwrappers.NewOrasCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.OrasCli = orasCli
+	return nil
+}
+
+func (c *ExtractArtifact) Run() error {
+	common.LogParameters(ExtractArtifactParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	registryConfig, err := common.WriteOrasRegistryConfig(c.Params.ImageRef)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(registryConfig); err != nil {
+			l.Logger.Warnf("failed to remove %s: %s", registryConfig, err.Error())
+		}
+	}()
+	c.registryConfig = registryConfig
+
+	artifactRef, manifest, err := c.fetchManifest()
+	if err != nil {
+		return err
+	}
+	l.Logger.Infof("Extracting %s into %s", artifactRef, c.Params.OutputDir)
+
+	blobsDir, err := os.MkdirTemp("", "extract-artifact-")
+	if err != nil {
+		return fmt.Errorf("error on creating temporary directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(blobsDir); err != nil {
+			l.Logger.Warnf("failed to remove '%s' directory: %s", blobsDir, err.Error())
+		}
+	}()
+
+	if err := os.MkdirAll(c.Params.OutputDir, 0755); err != nil {
+		return fmt.Errorf("error on creating output directory: %w", err)
+	}
+	// Confine the extracted files to the output directory, whatever the artifact contains
+	outputRoot, err := os.OpenRoot(c.Params.OutputDir)
+	if err != nil {
+		return fmt.Errorf("error on opening output directory: %w", err)
+	}
+	defer outputRoot.Close()
+
+	files := []string{}
+	for i, layer := range manifest.Layers {
+		title := layer.Annotations[specs.AnnotationTitle]
+		if title == "" {
+			return fmt.Errorf("layer %s has no %s annotation", layer.Digest, specs.AnnotationTitle)
+		}
+
+		blobPath := filepath.Join(blobsDir, fmt.Sprintf("blob-%d", i))
+		if err := c.fetchBlob(layer, blobPath); err != nil {
+			return err
+		}
+
+		if c.Params.Unpack && isTarballMediaType(layer.MediaType) {
+			err = common.ExtractTarball(outputRoot, blobPath, strings.HasSuffix(layer.MediaType, "+gzip"))
+		} else {
+			err = copyBlob(outputRoot, blobPath, title)
+		}
+		if err != nil {
+			return fmt.Errorf("error on extracting %s: %w", title, err)
+		}
+		l.Logger.Infof("Extracted %s", title)
+		files = append(files, title)
+	}
+
+	c.Results.ImageRef = artifactRef
+	c.Results.ArtifactType = manifest.ArtifactType
+	c.Results.Files = files
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	return nil
+}
+
+func (c *ExtractArtifact) validateParams() error {
+	c.imageName = common.GetImageName(c.Params.ImageRef)
+	if !common.IsImageNameValid(c.imageName) {
+		return fmt.Errorf("image '%s' is invalid", c.Params.ImageRef)
+	}
+	if imageDigest := common.GetImageDigest(c.Params.ImageRef); imageDigest != "" && !common.IsImageDigestValid(imageDigest) {
+		return fmt.Errorf("image digest '%s' is invalid", imageDigest)
+	}
+	return nil
+}
+
+// Fetch the manifest of the artifact, verifying it against the digest of --image-ref if it has one.
+// Return the digested reference of the artifact and its manifest.
+func (c *ExtractArtifact) fetchManifest() (string, *specs.Manifest, error) {
+	content, err := c.CliWrappers.OrasCli.ManifestFetch(&cliwrappers.OrasManifestFetchArgs{
+		Image:          c.Params.ImageRef,
+		RegistryConfig: c.registryConfig,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("error on fetching manifest of %s: %w", c.Params.ImageRef, err)
+	}
+
+	// oras prints the raw manifest, its digest is the digest of the artifact
+	manifestDigest := digest.FromString(content).String()
+	if expectedDigest := common.GetImageDigest(c.Params.ImageRef); expectedDigest != "" && expectedDigest != manifestDigest {
+		return "", nil, fmt.Errorf("digest of the manifest of %s is %s", c.Params.ImageRef, manifestDigest)
+	}
+
+	var manifest specs.Manifest
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return "", nil, fmt.Errorf("parsing manifest of %s: %w", c.Params.ImageRef, err)
+	}
+	return c.imageName + "@" + manifestDigest, &manifest, nil
+}
+
+// Download the blob of a layer and verify its digest and size.
+func (c *ExtractArtifact) fetchBlob(layer specs.Descriptor, blobPath string) error {
+	if err := layer.Digest.Validate(); err != nil {
+		return fmt.Errorf("layer digest '%s' is invalid: %w", layer.Digest, err)
+	}
+	err := c.CliWrappers.OrasCli.BlobFetch(&cliwrappers.OrasBlobFetchArgs{
+		Blob:           c.imageName + "@" + layer.Digest.String(),
+		OutputPath:     blobPath,
+		RegistryConfig: c.registryConfig,
+	})
+	if err != nil {
+		return fmt.Errorf("error on downloading layer %s: %w", layer.Digest, err)
+	}
+
+	blob, err := os.Open(blobPath) //nolint:gosec // blob path is in a controlled temp dir
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+
+	verifier := layer.Digest.Verifier()
+	size, err := io.Copy(verifier, blob)
+	if err != nil {
+		return err
+	}
+	if !verifier.Verified() {
+		return fmt.Errorf("layer %s doesn't match its digest", layer.Digest)
+	}
+	if size != layer.Size {
+		return fmt.Errorf("layer %s has size %d, expected %d", layer.Digest, size, layer.Size)
+	}
+	return nil
+}
+
+func isTarballMediaType(mediaType string) bool {
+	return strings.HasSuffix(mediaType, ".tar") || strings.HasSuffix(mediaType, ".tar+gzip")
+}
+
+func copyBlob(root *os.Root, blobPath, name string) error {
+	if err := root.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	blob, err := os.Open(blobPath) //nolint:gosec // blob path is in a controlled temp dir
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+
+	file, err := root.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, blob); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
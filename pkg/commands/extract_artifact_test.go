@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	"github.com/konflux-ci/konflux-build-cli/testutil"
+)
+
+func Test_ExtractArtifact_Run(t *testing.T) {
+	workDir := t.TempDir()
+	t.Setenv("HOME", workDir)
+	os.Mkdir(filepath.Join(workDir, ".docker"), 0755)
+	os.WriteFile(filepath.Join(workDir, ".docker", "config.json"), []byte(`{"auths":{"quay.io":{"auth":"token"}}}`), 0644)
+
+	sourceDir := t.TempDir()
+	testutil.WriteFileTree(t, sourceDir, map[string]string{
+		"deps/pip/package.tar.gz": "package",
+		"prefetch.env":            "export FOO=bar\n",
+	})
+	var tarball bytes.Buffer
+	if err := common.WriteDirTarball(&tarball, sourceDir); err != nil {
+		t.Fatal(err)
+	}
+	containerfile := []byte("FROM scratch\n")
+
+	blobs := map[digest.Digest][]byte{
+		digest.FromBytes(tarball.Bytes()): tarball.Bytes(),
+		digest.FromBytes(containerfile):   containerfile,
+	}
+	layer := func(content []byte, mediaType, title string) specs.Descriptor {
+		return specs.Descriptor{
+			MediaType:   mediaType,
+			Digest:      digest.FromBytes(content),
+			Size:        int64(len(content)),
+			Annotations: map[string]string{specs.AnnotationTitle: title},
+		}
+	}
+	marshalManifest := func(layers ...specs.Descriptor) string {
+		content, err := json.Marshal(specs.Manifest{
+			MediaType:    specs.MediaTypeImageManifest,
+			ArtifactType: prefetchOutputArtifactType,
+			Layers:       layers,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	newExtractArtifact := func(imageRef, manifest string) *ExtractArtifact {
+		return &ExtractArtifact{
+			Params: &ExtractArtifactParams{
+				ImageRef:  imageRef,
+				OutputDir: filepath.Join(t.TempDir(), "output"),
+				Unpack:    true,
+			},
+			CliWrappers: ExtractArtifactCliWrappers{OrasCli: &mockOrasCli{
+				ManifestFetchFunc: func(args *cliwrappers.OrasManifestFetchArgs) (string, error) {
+					return manifest, nil
+				},
+				BlobFetchFunc: func(args *cliwrappers.OrasBlobFetchArgs) error {
+					return os.WriteFile(args.OutputPath, blobs[digest.Digest(args.Blob[len("quay.io/org/app@"):])], 0644)
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+	}
+
+	t.Run("should unpack tarball layers and save other layers as files", func(t *testing.T) {
+		g := NewWithT(t)
+		manifest := marshalManifest(
+			layer(tarball.Bytes(), prefetchOutputMediaType, prefetchOutputTarball),
+			layer(containerfile, "application/vnd.konflux.containerfile", "Containerfile"),
+		)
+		manifestDigest := digest.FromString(manifest).String()
+		c := newExtractArtifact("quay.io/org/app@"+manifestDigest, manifest)
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(filepath.Join(c.Params.OutputDir, "deps/pip/package.tar.gz")).To(BeAnExistingFile())
+		g.Expect(filepath.Join(c.Params.OutputDir, "prefetch.env")).To(BeAnExistingFile())
+		content, err := os.ReadFile(filepath.Join(c.Params.OutputDir, "Containerfile"))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(content)).To(Equal("FROM scratch\n"))
+
+		g.Expect(c.Results).To(Equal(ExtractArtifactResults{
+			ImageRef:     "quay.io/org/app@" + manifestDigest,
+			ArtifactType: prefetchOutputArtifactType,
+			Files:        []string{prefetchOutputTarball, "Containerfile"},
+		}))
+	})
+
+	t.Run("should save tarball layers as files without unpack", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newExtractArtifact("quay.io/org/app:v1", marshalManifest(
+			layer(tarball.Bytes(), prefetchOutputMediaType, prefetchOutputTarball),
+		))
+		c.Params.Unpack = false
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(filepath.Join(c.Params.OutputDir, prefetchOutputTarball)).To(BeAnExistingFile())
+		g.Expect(filepath.Join(c.Params.OutputDir, "prefetch.env")).ToNot(BeAnExistingFile())
+	})
+
+	t.Run("should fail if the manifest doesn't match the digest", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newExtractArtifact("quay.io/org/app@"+digest.FromString("other").String(), marshalManifest(
+			layer(containerfile, "application/vnd.konflux.containerfile", "Containerfile"),
+		))
+
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("digest of the manifest of")))
+	})
+
+	t.Run("should fail if a layer doesn't match its digest", func(t *testing.T) {
+		g := NewWithT(t)
+		tampered := layer(containerfile, "application/vnd.konflux.containerfile", "Containerfile")
+		tampered.Digest = digest.FromString("other")
+		blobs[tampered.Digest] = containerfile
+		defer delete(blobs, tampered.Digest)
+		c := newExtractArtifact("quay.io/org/app:v1", marshalManifest(tampered))
+
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("doesn't match its digest")))
+		g.Expect(filepath.Join(c.Params.OutputDir, "Containerfile")).ToNot(BeAnExistingFile())
+	})
+
+	t.Run("should fail on layers without title", func(t *testing.T) {
+		g := NewWithT(t)
+		untitled := layer(containerfile, "application/vnd.konflux.containerfile", "")
+		c := newExtractArtifact("quay.io/org/app:v1", marshalManifest(untitled))
+
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("has no org.opencontainers.image.title annotation")))
+	})
+
+	t.Run("should fail on invalid image reference", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newExtractArtifact("quay.io/org/app@sha256:invalid", "")
+
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("is invalid")))
+	})
+}
@@ -0,0 +1,394 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+const (
+	provenanceBuildType      = "https://github.com/konflux-ci/konflux-build-cli/image-build/v1"
+	provenanceDefaultBuilder = "https://github.com/konflux-ci/konflux-build-cli"
+
+	provenancePredicateType = "https://slsa.dev/provenance/v1"
+	inTotoStatementType     = "https://in-toto.io/Statement/v1"
+)
+
+var GenerateProvenanceParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_GENERATE_PROVENANCE_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "The built image. Required.",
+		Required:   true,
+	},
+	"image-digest": {
+		Name:       "image-digest",
+		ShortName:  "d",
+		EnvVarName: "KBC_GENERATE_PROVENANCE_IMAGE_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Digest of the built image. Required.",
+		Required:   true,
+	},
+	"git-url": {
+		Name:       "git-url",
+		EnvVarName: "KBC_GENERATE_PROVENANCE_GIT_URL",
+		TypeKind:   reflect.String,
+		Usage:      "URL of the git repository the image was built from. Credentials in the URL are dropped.",
+	},
+	"git-commit": {
+		Name:       "git-commit",
+		EnvVarName: "KBC_GENERATE_PROVENANCE_GIT_COMMIT",
+		TypeKind:   reflect.String,
+		Usage:      "SHA of the git commit the image was built from.",
+	},
+	"build-params": {
+		Name:       "build-params",
+		EnvVarName: "KBC_GENERATE_PROVENANCE_BUILD_PARAMS",
+		TypeKind:   reflect.Slice,
+		Usage:      "Parameters of the build in the name=value format, recorded as the external parameters.",
+	},
+	"containerfile-json": {
+		Name:       "containerfile-json",
+		EnvVarName: "KBC_GENERATE_PROVENANCE_CONTAINERFILE_JSON",
+		TypeKind:   reflect.String,
+		Usage:      "Containerfile JSON written by image build --containerfile-json-output. The base images are recorded as resolved dependencies.",
+	},
+	"builder-id": {
+		Name:         "builder-id",
+		EnvVarName:   "KBC_GENERATE_PROVENANCE_BUILDER_ID",
+		TypeKind:     reflect.String,
+		DefaultValue: provenanceDefaultBuilder,
+		Usage:        "URI identifying the builder.",
+	},
+	"invocation-id": {
+		Name:       "invocation-id",
+		EnvVarName: "KBC_GENERATE_PROVENANCE_INVOCATION_ID",
+		TypeKind:   reflect.String,
+		Usage:      "Identifier of the build invocation, e.g. the PipelineRun or TaskRun UID.",
+	},
+	"started-on": {
+		Name:       "started-on",
+		EnvVarName: "KBC_GENERATE_PROVENANCE_STARTED_ON",
+		TypeKind:   reflect.String,
+		Usage:      "Time the build started, in the RFC 3339 format.",
+	},
+	"finished-on": {
+		Name:       "finished-on",
+		EnvVarName: "KBC_GENERATE_PROVENANCE_FINISHED_ON",
+		TypeKind:   reflect.String,
+		Usage:      "Time the build finished, in the RFC 3339 format.",
+	},
+	"output": {
+		Name:       "output",
+		ShortName:  "o",
+		EnvVarName: "KBC_GENERATE_PROVENANCE_OUTPUT",
+		TypeKind:   reflect.String,
+		Usage:      "Write the SLSA provenance predicate into this file. Required.",
+		Required:   true,
+	},
+	"statement-output": {
+		Name:       "statement-output",
+		EnvVarName: "KBC_GENERATE_PROVENANCE_STATEMENT_OUTPUT",
+		TypeKind:   reflect.String,
+		Usage:      "Write also the in-toto statement with the image as the subject into this file.",
+	},
+}
+
+type GenerateProvenanceParams struct {
+	ImageUrl          string   `paramName:"image-url"`
+	ImageDigest       string   `paramName:"image-digest"`
+	GitUrl            string   `paramName:"git-url"`
+	GitCommit         string   `paramName:"git-commit"`
+	BuildParams       []string `paramName:"build-params"`
+	ContainerfileJson string   `paramName:"containerfile-json"`
+	BuilderId         string   `paramName:"builder-id"`
+	InvocationId      string   `paramName:"invocation-id"`
+	StartedOn         string   `paramName:"started-on"`
+	FinishedOn        string   `paramName:"finished-on"`
+	Output            string   `paramName:"output"`
+	StatementOutput   string   `paramName:"statement-output"`
+}
+
+type GenerateProvenanceResults struct {
+	PredicatePath   string `json:"predicate_path"`
+	PredicateDigest string `json:"predicate_digest"`
+	StatementPath   string `json:"statement_path,omitempty"`
+}
+
+// SLSA v1 provenance predicate, https://slsa.dev/spec/v1.0/provenance
+type slsaProvenance struct {
+	BuildDefinition slsaBuildDefinition `json:"buildDefinition"`
+	RunDetails      slsaRunDetails      `json:"runDetails"`
+}
+
+type slsaBuildDefinition struct {
+	BuildType            string                   `json:"buildType"`
+	ExternalParameters   map[string]any           `json:"externalParameters"`
+	ResolvedDependencies []slsaResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+type slsaResourceDescriptor struct {
+	Uri    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+	Name   string            `json:"name,omitempty"`
+}
+
+type slsaRunDetails struct {
+	Builder  slsaBuilder        `json:"builder"`
+	Metadata *slsaBuildMetadata `json:"metadata,omitempty"`
+}
+
+type slsaBuilder struct {
+	Id string `json:"id"`
+}
+
+type slsaBuildMetadata struct {
+	InvocationId string `json:"invocationId,omitempty"`
+	StartedOn    string `json:"startedOn,omitempty"`
+	FinishedOn   string `json:"finishedOn,omitempty"`
+}
+
+type inTotoStatement struct {
+	Type          string                   `json:"_type"`
+	Subject       []slsaResourceDescriptor `json:"subject"`
+	PredicateType string                   `json:"predicateType"`
+	Predicate     slsaProvenance           `json:"predicate"`
+}
+
+type GenerateProvenance struct {
+	Params        *GenerateProvenanceParams
+	Results       GenerateProvenanceResults
+	ResultsWriter common.ResultsWriterInterface
+
+	imageName string
+}
+
+func NewGenerateProvenance(cmd *cobra.Command) (*GenerateProvenance, error) {
+	params := &GenerateProvenanceParams{}
+	if err := common.ParseParameters(cmd, GenerateProvenanceParamsConfig, params); err != nil {
+		return nil, err
+	}
+	return &GenerateProvenance{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}, nil
+}
+
+// Run executes the command logic.
+func (c *GenerateProvenance) Run() error {
+	common.LogParameters(GenerateProvenanceParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	provenance, err := c.generateProvenance()
+	if err != nil {
+		return err
+	}
+
+	predicate, err := canonicalJson(provenance)
+	if err != nil {
+		return fmt.Errorf("serializing provenance: %w", err)
+	}
+	if err := common.WriteResultFile(c.Params.Output, predicate); err != nil {
+		return fmt.Errorf("writing provenance to %s: %w", c.Params.Output, err)
+	}
+	l.Logger.Infof("Provenance of %s@%s is written to %s", c.imageName, c.Params.ImageDigest, c.Params.Output)
+
+	c.Results = GenerateProvenanceResults{
+		PredicatePath:   c.Params.Output,
+		PredicateDigest: fmt.Sprintf("sha256:%x", sha256.Sum256(predicate)),
+	}
+
+	if c.Params.StatementOutput != "" {
+		algorithm, hex, _ := strings.Cut(c.Params.ImageDigest, ":")
+		statement, err := canonicalJson(inTotoStatement{
+			Type:          inTotoStatementType,
+			Subject:       []slsaResourceDescriptor{{Name: c.imageName, Digest: map[string]string{algorithm: hex}}},
+			PredicateType: provenancePredicateType,
+			Predicate:     *provenance,
+		})
+		if err != nil {
+			return fmt.Errorf("serializing in-toto statement: %w", err)
+		}
+		if err := common.WriteResultFile(c.Params.StatementOutput, statement); err != nil {
+			return fmt.Errorf("writing in-toto statement to %s: %w", c.Params.StatementOutput, err)
+		}
+		c.Results.StatementPath = c.Params.StatementOutput
+	}
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *GenerateProvenance) generateProvenance() (*slsaProvenance, error) {
+	externalParameters := map[string]any{
+		"image": c.imageName,
+	}
+
+	var dependencies []slsaResourceDescriptor
+	if c.Params.GitUrl != "" {
+		gitUrl := stripUrlCredentials(c.Params.GitUrl)
+		source := map[string]any{"url": gitUrl}
+		dependency := slsaResourceDescriptor{Uri: "git+" + gitUrl}
+		if c.Params.GitCommit != "" {
+			source["commit"] = c.Params.GitCommit
+			dependency.Digest = map[string]string{"gitCommit": c.Params.GitCommit}
+		}
+		externalParameters["source"] = source
+		dependencies = append(dependencies, dependency)
+	}
+
+	if len(c.Params.BuildParams) > 0 {
+		buildParams := map[string]string{}
+		for _, param := range c.Params.BuildParams {
+			name, value, _ := strings.Cut(param, "=")
+			buildParams[name] = value
+		}
+		externalParameters["parameters"] = buildParams
+	}
+
+	if c.Params.ContainerfileJson != "" {
+		baseImages, err := containerfileJsonBaseImages(c.Params.ContainerfileJson)
+		if err != nil {
+			return nil, err
+		}
+		for _, baseImage := range baseImages {
+			dependency := slsaResourceDescriptor{Uri: "oci://" + baseImage, Name: common.GetImageName(baseImage)}
+			if digest := common.GetImageDigest(baseImage); digest != "" {
+				algorithm, hex, _ := strings.Cut(digest, ":")
+				dependency.Digest = map[string]string{algorithm: hex}
+			}
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	provenance := &slsaProvenance{
+		BuildDefinition: slsaBuildDefinition{
+			BuildType:            provenanceBuildType,
+			ExternalParameters:   externalParameters,
+			ResolvedDependencies: dependencies,
+		},
+		RunDetails: slsaRunDetails{
+			Builder: slsaBuilder{Id: c.Params.BuilderId},
+		},
+	}
+	if c.Params.InvocationId != "" || c.Params.StartedOn != "" || c.Params.FinishedOn != "" {
+		provenance.RunDetails.Metadata = &slsaBuildMetadata{
+			InvocationId: c.Params.InvocationId,
+			StartedOn:    c.Params.StartedOn,
+			FinishedOn:   c.Params.FinishedOn,
+		}
+	}
+	return provenance, nil
+}
+
+// The base images of the Containerfile stages, in the stage order without duplicates.
+// Stages built from other stages and scratch are not base images.
+func containerfileJsonBaseImages(path string) ([]string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Containerfile JSON path from controlled input
+	if err != nil {
+		return nil, fmt.Errorf("reading Containerfile JSON: %w", err)
+	}
+	var cf containerfileJson
+	if err := json.Unmarshal(data, &cf); err != nil {
+		return nil, fmt.Errorf("parsing Containerfile JSON: %w", err)
+	}
+
+	var stageNames, baseImages []string
+	for _, stage := range cf.Stages {
+		baseName := stage.BaseName
+		if baseName != "scratch" && !slices.Contains(stageNames, strings.ToLower(baseName)) && !slices.Contains(baseImages, baseName) {
+			baseImages = append(baseImages, baseName)
+		}
+		if stage.As != "" {
+			stageNames = append(stageNames, strings.ToLower(stage.As))
+		}
+	}
+	return baseImages, nil
+}
+
+func stripUrlCredentials(rawUrl string) string {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil || parsed.User == nil {
+		return rawUrl
+	}
+	parsed.User = nil
+	return parsed.String()
+}
+
+// Serialize the value to JSON with the object keys sorted and without insignificant whitespace,
+// so that the same input always results in the same bytes (and digest).
+func canonicalJson(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// Maps are marshalled with sorted keys, structs in the field order
+	var generic any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(generic); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func (c *GenerateProvenance) validateParams() error {
+	c.imageName = common.GetImageName(c.Params.ImageUrl)
+	if !common.IsImageNameValid(c.imageName) {
+		return fmt.Errorf("image '%s' is invalid", c.Params.ImageUrl)
+	}
+	if !common.IsImageDigestValid(c.Params.ImageDigest) {
+		return fmt.Errorf("image digest '%s' is invalid", c.Params.ImageDigest)
+	}
+	if c.Params.GitCommit != "" && c.Params.GitUrl == "" {
+		return fmt.Errorf("--git-commit requires --git-url")
+	}
+	for _, param := range c.Params.BuildParams {
+		if name, _, found := strings.Cut(param, "="); !found || name == "" {
+			return fmt.Errorf("build parameter '%s' is not in the name=value format", param)
+		}
+	}
+	if c.Params.BuilderId == "" {
+		return fmt.Errorf("builder id must not be empty")
+	}
+	for name, value := range map[string]string{"started-on": c.Params.StartedOn, "finished-on": c.Params.FinishedOn} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("--%s '%s' is not an RFC 3339 time", name, value)
+		}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 	"github.com/spf13/cobra"
 )
@@ -56,9 +57,19 @@ func (c *GitClone) Run() error {
 	c.logParams()
 
 	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	if err := c.Clone(); err != nil {
 		return err
 	}
 
+	return c.outputResults()
+}
+
+// Clone clones the repository according to the params and gathers the results,
+// without logging the params or printing the results. For use by other commands.
+func (c *GitClone) Clone() error {
 	if c.CliWrappers.GitCli == nil {
 		if err := c.initCliWrappers(); err != nil {
 			return err
@@ -125,7 +136,7 @@ func (c *GitClone) Run() error {
 		}
 	}
 
-	return c.outputResults()
+	return nil
 }
 
 func (c *GitClone) logParams() {
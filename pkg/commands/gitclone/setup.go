@@ -128,6 +128,7 @@ func (c *GitClone) setupBasicAuth() error {
 		if err != nil {
 			return fmt.Errorf("failed to read password file: %w", err)
 		}
+		l.RegisterSecret(string(password))
 
 		parsedURL, err := url.Parse(c.Params.URL)
 		if err != nil {
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var InitParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_INIT_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "The image the pipeline builds.",
+		Required:   true,
+	},
+	"digest": {
+		Name:       "digest",
+		ShortName:  "d",
+		EnvVarName: "KBC_INIT_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Check the image with this digest instead of the image URL tag.",
+	},
+	"rebuild": {
+		Name:         "rebuild",
+		ShortName:    "r",
+		EnvVarName:   "KBC_INIT_REBUILD",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Build the image even if it already exists.",
+	},
+}
+
+type InitParams struct {
+	ImageUrl string `paramName:"image-url"`
+	Digest   string `paramName:"digest"`
+	Rebuild  bool   `paramName:"rebuild"`
+}
+
+type InitResults struct {
+	// Whether the pipeline should build the image
+	Build bool `json:"build"`
+	// Digest of the existing image, empty if it doesn't exist or the check was skipped
+	ImageDigest string `json:"image_digest,omitempty"`
+}
+
+type InitCliWrappers struct {
+	SkopeoCli cliwrappers.SkopeoCliInterface
+}
+
+type Init struct {
+	Params        *InitParams
+	CliWrappers   InitCliWrappers
+	Results       InitResults
+	ResultsWriter common.ResultsWriterInterface
+}
+
+func NewInit(cmd *cobra.Command) (*Init, error) {
+	params := &InitParams{}
+	if err := common.ParseParameters(cmd, InitParamsConfig, params); err != nil {
+		return nil, err
+	}
+	initTask := &Init{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}
+	if err := initTask.initCliWrappers(); err != nil {
+		return nil, err
+	}
+	return initTask, nil
+}
+
+func (c *Init) initCliWrappers() error {
+	executor := cliwrappers.NewCliExecutor()
+	skopeoCli, err := cliwrappers.NewSkopeoCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.SkopeoCli = skopeoCli
+	return nil
+}
+
+func (c *Init) Run() error {
+	common.LogParameters(InitParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	if c.Params.Rebuild {
+		l.Logger.Info("Rebuild is requested, not checking whether the image exists")
+		c.Results.Build = true
+	} else {
+		imageRef := c.Params.ImageUrl
+		if c.Params.Digest != "" {
+			imageRef = common.GetImageName(c.Params.ImageUrl) + "@" + c.Params.Digest
+		}
+		rawManifest, err := c.CliWrappers.SkopeoCli.Inspect(&cliwrappers.SkopeoInspectArgs{
+			ImageRef:          imageRef,
+			Raw:               true,
+			NoRetryIfNotFound: true,
+		})
+		if cliwrappers.IsImageNotFound(err) {
+			l.Logger.Infof("Image %s doesn't exist, it will be built", imageRef)
+			c.Results.Build = true
+		} else if err != nil {
+			return fmt.Errorf("checking whether image %s exists: %w", imageRef, err)
+		} else {
+			c.Results.ImageDigest = digest.FromString(rawManifest).String()
+			l.Logger.Infof("Image %s already exists with digest %s, skipping the build", imageRef, c.Results.ImageDigest)
+		}
+	}
+
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	return nil
+}
+
+func (c *Init) validateParams() error {
+	if !common.IsImageNameValid(common.GetImageName(c.Params.ImageUrl)) {
+		return fmt.Errorf("image URL '%s' is invalid", c.Params.ImageUrl)
+	}
+
+	if c.Params.Digest != "" {
+		if !common.IsImageDigestValid(c.Params.Digest) {
+			return fmt.Errorf("image digest '%s' is invalid", c.Params.Digest)
+		}
+	} else if err := common.ValidateImageHasTagOrDigest(c.Params.ImageUrl); err != nil {
+		return fmt.Errorf("invalid image URL: %w", err)
+	}
+
+	return nil
+}
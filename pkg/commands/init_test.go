@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_Init_Run(t *testing.T) {
+	const rawManifest = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`
+	const imageDigest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+
+	newInit := func(skopeoCli *mockSkopeoCli) *Init {
+		return &Init{
+			Params:        &InitParams{ImageUrl: "quay.io/org/app:abc123"},
+			CliWrappers:   InitCliWrappers{SkopeoCli: skopeoCli},
+			ResultsWriter: &mockResultsWriter{},
+		}
+	}
+
+	t.Run("should skip the build if the image exists", func(t *testing.T) {
+		g := NewWithT(t)
+		var inspectArgs *cliwrappers.SkopeoInspectArgs
+		c := newInit(&mockSkopeoCli{
+			InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+				inspectArgs = args
+				return rawManifest, nil
+			},
+		})
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(inspectArgs.ImageRef).To(Equal("quay.io/org/app:abc123"))
+		g.Expect(inspectArgs.Raw).To(BeTrue())
+		g.Expect(inspectArgs.NoRetryIfNotFound).To(BeTrue())
+		g.Expect(c.Results).To(Equal(InitResults{Build: false, ImageDigest: digest.FromString(rawManifest).String()}))
+	})
+
+	t.Run("should check the image by digest", func(t *testing.T) {
+		g := NewWithT(t)
+		var imageRef string
+		c := newInit(&mockSkopeoCli{
+			InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+				imageRef = args.ImageRef
+				return rawManifest, nil
+			},
+		})
+		c.Params.Digest = imageDigest
+
+		g.Expect(c.Run()).To(Succeed())
+		g.Expect(imageRef).To(Equal("quay.io/org/app@" + imageDigest))
+	})
+
+	t.Run("should build if the image doesn't exist", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newInit(&mockSkopeoCli{
+			InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+				return "", errors.New("exit status 1: reading manifest abc123 in quay.io/org/app: manifest unknown")
+			},
+		})
+
+		g.Expect(c.Run()).To(Succeed())
+		g.Expect(c.Results).To(Equal(InitResults{Build: true}))
+	})
+
+	t.Run("should build without checking the image on rebuild", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newInit(&mockSkopeoCli{
+			InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+				return "", errors.New("unexpected inspect")
+			},
+		})
+		c.Params.Rebuild = true
+
+		g.Expect(c.Run()).To(Succeed())
+		g.Expect(c.Results).To(Equal(InitResults{Build: true}))
+	})
+
+	t.Run("should fail if the image can't be checked", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newInit(&mockSkopeoCli{
+			InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+				return "", errors.New("exit status 1: unauthorized")
+			},
+		})
+
+		g.Expect(c.Run()).To(MatchError("checking whether image quay.io/org/app:abc123 exists: exit status 1: unauthorized"))
+	})
+}
+
+func Test_Init_validateParams(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name         string
+		params       InitParams
+		errSubstring string
+	}{
+		{name: "should allow image with tag", params: InitParams{ImageUrl: "quay.io/org/app:v1"}},
+		{name: "should allow image without tag with digest", params: InitParams{ImageUrl: "quay.io/org/app", Digest: "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"}},
+		{name: "should fail on image without tag", params: InitParams{ImageUrl: "quay.io/org/app"}, errSubstring: "invalid image URL"},
+		{name: "should fail on invalid image", params: InitParams{ImageUrl: "Invalid Image"}, errSubstring: "image URL 'Invalid Image' is invalid"},
+		{name: "should fail on invalid digest", params: InitParams{ImageUrl: "quay.io/org/app", Digest: "sha256:abc"}, errSubstring: "image digest 'sha256:abc' is invalid"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Init{Params: &tc.params}
+			err := c.validateParams()
+			if tc.errSubstring == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.errSubstring)))
+			}
+		})
+	}
+}
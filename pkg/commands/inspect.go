@@ -0,0 +1,270 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var InspectParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_INSPECT_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Image to inspect, referenced by tag and/or digest. Required.",
+		Required:   true,
+	},
+	"digest": {
+		Name:       "digest",
+		ShortName:  "d",
+		EnvVarName: "KBC_INSPECT_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Digest of the image. Takes precedence over the tag and digest of --image-url.",
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_INSPECT_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Require HTTPS and verify certificates when accessing the registry.",
+	},
+}
+
+type InspectParams struct {
+	ImageUrl  string `paramName:"image-url"`
+	Digest    string `paramName:"digest"`
+	TLSVerify bool   `paramName:"tls-verify"`
+}
+
+type InspectCliWrappers struct {
+	SkopeoCli cliWrappers.SkopeoCliInterface
+}
+
+type InspectLayer struct {
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+	MediaType string `json:"media_type"`
+}
+
+type InspectManifest struct {
+	Digest    string `json:"digest"`
+	MediaType string `json:"media_type"`
+	// os/architecture[/variant], empty for artifacts in the index
+	Platform string `json:"platform,omitempty"`
+}
+
+type InspectResults struct {
+	ImageRef  string `json:"image_ref"`
+	Digest    string `json:"digest"`
+	MediaType string `json:"media_type"`
+
+	// Image manifest fields
+	Created      string            `json:"created,omitempty"`
+	Os           string            `json:"os,omitempty"`
+	Architecture string            `json:"architecture,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Env          []string          `json:"env,omitempty"`
+	Layers       []InspectLayer    `json:"layers,omitempty"`
+
+	// Image index fields
+	Architectures []string          `json:"architectures,omitempty"`
+	Manifests     []InspectManifest `json:"manifests,omitempty"`
+}
+
+type Inspect struct {
+	Params        *InspectParams
+	CliWrappers   InspectCliWrappers
+	Results       InspectResults
+	ResultsWriter common.ResultsWriterInterface
+
+	imageName string
+}
+
+func NewInspect(cmd *cobra.Command) (*Inspect, error) {
+	inspect := &Inspect{}
+
+	params := &InspectParams{}
+	if err := common.ParseParameters(cmd, InspectParamsConfig, params); err != nil {
+		return nil, err
+	}
+	inspect.Params = params
+
+	if err := inspect.initCliWrappers(); err != nil {
+		return nil, err
+	}
+
+	inspect.ResultsWriter = common.NewResultsWriter()
+
+	return inspect, nil
+}
+
+func (c *Inspect) initCliWrappers() error {
+	executor := cliWrappers.NewCliExecutor()
+
+	skopeoCli, err := cliWrappers.NewSkopeoCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.SkopeoCli = skopeoCli
+	return nil
+}
+
+// Run executes the command logic.
+func (c *Inspect) Run() error {
+	common.LogParameters(InspectParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	imageRef := common.NormalizeImageRefWithDigest(c.Params.ImageUrl)
+	if c.Params.Digest != "" {
+		imageRef = c.imageName + "@" + c.Params.Digest
+	}
+
+	rawManifest, err := c.inspect(imageRef, false)
+	if err != nil {
+		return fmt.Errorf("inspecting manifest of %s: %w", imageRef, err)
+	}
+
+	digest := c.Params.Digest
+	if digest == "" {
+		digest = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(rawManifest)))
+	}
+	c.Results = InspectResults{
+		ImageRef: c.imageName + "@" + digest,
+		Digest:   digest,
+	}
+
+	// Docker manifests and manifest lists are compatible with the OCI ones for the fields used here
+	var manifest struct {
+		specs.Manifest
+		Manifests []specs.Descriptor `json:"manifests"`
+	}
+	if err := json.Unmarshal([]byte(rawManifest), &manifest); err != nil {
+		return fmt.Errorf("parsing manifest of %s: %w", imageRef, err)
+	}
+	c.Results.MediaType = manifest.MediaType
+	if c.Results.MediaType == "" {
+		// The media type is optional in OCI manifests
+		if manifest.Manifests != nil {
+			c.Results.MediaType = specs.MediaTypeImageIndex
+		} else {
+			c.Results.MediaType = specs.MediaTypeImageManifest
+		}
+	}
+
+	if isImageIndexMediaType(c.Results.MediaType) {
+		l.Logger.Infof("%s is an image index of %d manifests", imageRef, len(manifest.Manifests))
+		c.Results.Architectures, c.Results.Manifests = normalizeIndexManifests(manifest.Manifests)
+	} else {
+		c.Results.Layers = normalizeLayers(manifest.Layers)
+
+		configJson, err := c.inspect(c.Results.ImageRef, true)
+		if err != nil {
+			return fmt.Errorf("inspecting config of %s: %w", imageRef, err)
+		}
+		var config specs.Image
+		if err := json.Unmarshal([]byte(configJson), &config); err != nil {
+			return fmt.Errorf("parsing config of %s: %w", imageRef, err)
+		}
+		if config.Created != nil {
+			c.Results.Created = config.Created.UTC().Format(time.RFC3339)
+		}
+		c.Results.Os = config.OS
+		c.Results.Architecture = config.Architecture
+		c.Results.Labels = config.Config.Labels
+		c.Results.Env = config.Config.Env
+	}
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Raw manifest or config of the image.
+func (c *Inspect) inspect(imageRef string, config bool) (string, error) {
+	var extraArgs []string
+	if !c.Params.TLSVerify {
+		extraArgs = append(extraArgs, "--tls-verify=false")
+	}
+	return c.CliWrappers.SkopeoCli.Inspect(&cliWrappers.SkopeoInspectArgs{
+		ImageRef:   imageRef,
+		Raw:        true,
+		Config:     config,
+		RetryTimes: 3,
+		ExtraArgs:  extraArgs,
+	})
+}
+
+func isImageIndexMediaType(mediaType string) bool {
+	return strings.Contains(mediaType, ".index.") || strings.Contains(mediaType, ".manifest.list.")
+}
+
+// Architectures of the images in the index, in the index order without duplicates,
+// and the manifests of the index.
+func normalizeIndexManifests(descriptors []specs.Descriptor) ([]string, []InspectManifest) {
+	var architectures []string
+	manifests := make([]InspectManifest, 0, len(descriptors))
+	for _, descriptor := range descriptors {
+		manifest := InspectManifest{
+			Digest:    descriptor.Digest.String(),
+			MediaType: descriptor.MediaType,
+		}
+		if p := descriptor.Platform; p != nil && p.Architecture != "" && p.Architecture != "unknown" {
+			manifest.Platform = p.OS + "/" + p.Architecture
+			if p.Variant != "" {
+				manifest.Platform += "/" + p.Variant
+			}
+			if !slices.Contains(architectures, p.Architecture) {
+				architectures = append(architectures, p.Architecture)
+			}
+		}
+		manifests = append(manifests, manifest)
+	}
+	return architectures, manifests
+}
+
+func normalizeLayers(descriptors []specs.Descriptor) []InspectLayer {
+	layers := make([]InspectLayer, 0, len(descriptors))
+	for _, descriptor := range descriptors {
+		layers = append(layers, InspectLayer{
+			Digest:    descriptor.Digest.String(),
+			Size:      descriptor.Size,
+			MediaType: descriptor.MediaType,
+		})
+	}
+	return layers
+}
+
+func (c *Inspect) validateParams() error {
+	c.imageName = common.GetImageName(c.Params.ImageUrl)
+	if !common.IsImageNameValid(c.imageName) {
+		return fmt.Errorf("image '%s' is invalid", c.Params.ImageUrl)
+	}
+	if c.Params.Digest != "" {
+		if !common.IsImageDigestValid(c.Params.Digest) {
+			return fmt.Errorf("image digest '%s' is invalid", c.Params.Digest)
+		}
+		return nil
+	}
+	return common.ValidateImageHasTagOrDigest(c.Params.ImageUrl)
+}
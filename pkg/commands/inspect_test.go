@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Inspect_validateParams(t *testing.T) {
+	g := NewWithT(t)
+
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+
+	t.Run("should accept image with tag", func(t *testing.T) {
+		c := &Inspect{Params: &InspectParams{ImageUrl: "quay.io/org/app:v1"}}
+
+		g.Expect(c.validateParams()).To(Succeed())
+		g.Expect(c.imageName).To(Equal("quay.io/org/app"))
+	})
+
+	t.Run("should accept image without tag if digest is set", func(t *testing.T) {
+		c := &Inspect{Params: &InspectParams{ImageUrl: "quay.io/org/app", Digest: digest}}
+
+		g.Expect(c.validateParams()).To(Succeed())
+	})
+
+	t.Run("should fail on image without tag and digest", func(t *testing.T) {
+		c := &Inspect{Params: &InspectParams{ImageUrl: "quay.io/org/app"}}
+
+		g.Expect(c.validateParams()).ToNot(Succeed())
+	})
+
+	t.Run("should fail on invalid digest", func(t *testing.T) {
+		c := &Inspect{Params: &InspectParams{ImageUrl: "quay.io/org/app:v1", Digest: "sha256:123"}}
+
+		err := c.validateParams()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("image digest 'sha256:123' is invalid"))
+	})
+
+	t.Run("should fail on invalid image", func(t *testing.T) {
+		c := &Inspect{Params: &InspectParams{ImageUrl: "quay.io/org/App:v1"}}
+
+		err := c.validateParams()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("is invalid"))
+	})
+}
+
+func Test_Inspect_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	const amd64Digest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	const arm64Digest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	const attestationDigest = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+
+	const imageManifest = `{
+		"schemaVersion": 2,
+		"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+		"config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 100, "digest": "sha256:4444444444444444444444444444444444444444444444444444444444444444"},
+		"layers": [
+			{"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 1024, "digest": "sha256:5555555555555555555555555555555555555555555555555555555555555555"},
+			{"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 2048, "digest": "sha256:6666666666666666666666666666666666666666666666666666666666666666"}
+		]
+	}`
+	const imageConfig = `{
+		"created": "2026-01-02T03:04:05.123456789+02:00",
+		"architecture": "amd64",
+		"os": "linux",
+		"config": {
+			"Env": ["PATH=/usr/bin", "LANG=C.utf8"],
+			"Labels": {"name": "app", "version": "1.0"}
+		},
+		"rootfs": {"type": "layers", "diff_ids": []}
+	}`
+	const imageIndex = `{
+		"schemaVersion": 2,
+		"manifests": [
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "size": 500, "digest": "` + amd64Digest + `", "platform": {"os": "linux", "architecture": "amd64"}},
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "size": 500, "digest": "` + arm64Digest + `", "platform": {"os": "linux", "architecture": "arm64", "variant": "v8"}},
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "size": 500, "digest": "` + attestationDigest + `", "platform": {"os": "unknown", "architecture": "unknown"}}
+		]
+	}`
+
+	t.Run("should report image manifest metadata", func(t *testing.T) {
+		_mockSkopeoCli := &mockSkopeoCli{}
+		c := &Inspect{
+			CliWrappers:   InspectCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params:        &InspectParams{ImageUrl: "quay.io/org/app:v1", Digest: digest, TLSVerify: true},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			g.Expect(args.ImageRef).To(Equal("quay.io/org/app@" + digest))
+			g.Expect(args.Raw).To(BeTrue())
+			g.Expect(args.ExtraArgs).To(BeEmpty())
+			if args.Config {
+				return imageConfig, nil
+			}
+			return imageManifest, nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Results).To(Equal(InspectResults{
+			ImageRef:     "quay.io/org/app@" + digest,
+			Digest:       digest,
+			MediaType:    "application/vnd.docker.distribution.manifest.v2+json",
+			Created:      "2026-01-02T01:04:05Z",
+			Os:           "linux",
+			Architecture: "amd64",
+			Labels:       map[string]string{"name": "app", "version": "1.0"},
+			Env:          []string{"PATH=/usr/bin", "LANG=C.utf8"},
+			Layers: []InspectLayer{
+				{Digest: "sha256:5555555555555555555555555555555555555555555555555555555555555555", Size: 1024, MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip"},
+				{Digest: "sha256:6666666666666666666666666666666666666666666666666666666666666666", Size: 2048, MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip"},
+			},
+		}))
+	})
+
+	t.Run("should report image index architectures", func(t *testing.T) {
+		_mockSkopeoCli := &mockSkopeoCli{}
+		c := &Inspect{
+			CliWrappers:   InspectCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params:        &InspectParams{ImageUrl: "quay.io/org/app:v1"},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			g.Expect(args.ImageRef).To(Equal("quay.io/org/app:v1"))
+			g.Expect(args.Config).To(BeFalse())
+			g.Expect(args.ExtraArgs).To(Equal([]string{"--tls-verify=false"}))
+			return imageIndex, nil
+		}
+
+		err := c.Run()
+
+		indexDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(imageIndex)))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Results).To(Equal(InspectResults{
+			ImageRef:      "quay.io/org/app@" + indexDigest,
+			Digest:        indexDigest,
+			MediaType:     "application/vnd.oci.image.index.v1+json",
+			Architectures: []string{"amd64", "arm64"},
+			Manifests: []InspectManifest{
+				{Digest: amd64Digest, MediaType: "application/vnd.oci.image.manifest.v1+json", Platform: "linux/amd64"},
+				{Digest: arm64Digest, MediaType: "application/vnd.oci.image.manifest.v1+json", Platform: "linux/arm64/v8"},
+				{Digest: attestationDigest, MediaType: "application/vnd.oci.image.manifest.v1+json"},
+			},
+		}))
+	})
+
+	t.Run("should error if inspect fails", func(t *testing.T) {
+		_mockSkopeoCli := &mockSkopeoCli{}
+		c := &Inspect{
+			CliWrappers:   InspectCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params:        &InspectParams{ImageUrl: "quay.io/org/app:v1", TLSVerify: true},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			return "", errors.New("manifest unknown")
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("inspecting manifest of quay.io/org/app:v1"))
+	})
+
+	t.Run("should error on invalid manifest", func(t *testing.T) {
+		_mockSkopeoCli := &mockSkopeoCli{}
+		c := &Inspect{
+			CliWrappers:   InspectCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params:        &InspectParams{ImageUrl: "quay.io/org/app:v1", TLSVerify: true},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			return "not json", nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("parsing manifest of quay.io/org/app:v1"))
+	})
+}
@@ -0,0 +1,561 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/keilerkonzept/dockerfile-json/pkg/buildargs"
+	"github.com/keilerkonzept/dockerfile-json/pkg/dockerfile"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/spf13/cobra"
+	"go.podman.io/image/v5/docker/reference"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+const (
+	LintSeverityInfo    = "info"
+	LintSeverityWarning = "warning"
+	LintSeverityError   = "error"
+
+	// --fail-on value to never fail on findings
+	lintFailOnNever = "never"
+)
+
+var lintSeverityLevels = map[string]int{
+	LintSeverityInfo:    1,
+	LintSeverityWarning: 2,
+	LintSeverityError:   3,
+}
+
+// Labels Konflux expects on the built images
+const defaultLintRequiredLabels = "name version summary description io.k8s.description io.k8s.display-name"
+
+var LintParamsConfig = map[string]common.Parameter{
+	"containerfile": {
+		Name:       "containerfile",
+		ShortName:  "f",
+		EnvVarName: "KBC_LINT_CONTAINERFILE",
+		TypeKind:   reflect.String,
+		Usage:      "Path to the Containerfile to lint. Required.",
+		Required:   true,
+	},
+	"build-args": {
+		Name:       "build-args",
+		EnvVarName: "KBC_LINT_BUILD_ARGS",
+		TypeKind:   reflect.Slice,
+		Usage:      "Build arguments to expand the Containerfile with, in the KEY[=VALUE] format.",
+	},
+	"build-args-file": {
+		Name:       "build-args-file",
+		EnvVarName: "KBC_LINT_BUILD_ARGS_FILE",
+		TypeKind:   reflect.String,
+		Usage:      "Path to a file with build arguments, see https://www.mankier.com/1/buildah-build#--build-arg-file.",
+	},
+	"labels": {
+		Name:       "labels",
+		EnvVarName: "KBC_LINT_LABELS",
+		TypeKind:   reflect.Slice,
+		Usage:      "Labels set at build time in the KEY=VALUE format, they satisfy the required labels.",
+	},
+	"required-labels": {
+		Name:         "required-labels",
+		EnvVarName:   "KBC_LINT_REQUIRED_LABELS",
+		TypeKind:     reflect.Slice,
+		DefaultValue: defaultLintRequiredLabels,
+		Usage:        "Labels the final stage must set.",
+	},
+	"disable-rules": {
+		Name:       "disable-rules",
+		EnvVarName: "KBC_LINT_DISABLE_RULES",
+		TypeKind:   reflect.Slice,
+		Usage:      "Rules to skip.",
+	},
+	"require-pinned-base-images": {
+		Name:       "require-pinned-base-images",
+		EnvVarName: "KBC_LINT_REQUIRE_PINNED_BASE_IMAGES",
+		TypeKind:   reflect.Bool,
+		Usage:      "Enable the unpinned-base-image rule, reporting the base images not pinned to a digest.",
+	},
+	"fail-on": {
+		Name:         "fail-on",
+		EnvVarName:   "KBC_LINT_FAIL_ON",
+		TypeKind:     reflect.String,
+		DefaultValue: LintSeverityError,
+		Usage:        "Fail if there's a finding of this severity or higher: 'info', 'warning', 'error' or 'never'.",
+	},
+}
+
+type LintParams struct {
+	Containerfile           string   `paramName:"containerfile"`
+	BuildArgs               []string `paramName:"build-args"`
+	BuildArgsFile           string   `paramName:"build-args-file"`
+	Labels                  []string `paramName:"labels"`
+	RequiredLabels          []string `paramName:"required-labels"`
+	DisableRules            []string `paramName:"disable-rules"`
+	FailOn                  string   `paramName:"fail-on"`
+	RequirePinnedBaseImages bool     `paramName:"require-pinned-base-images"`
+}
+
+type LintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// Line of the instruction in the Containerfile, 0 if not known
+	Line int `json:"line,omitempty"`
+}
+
+type LintResults struct {
+	Findings []LintFinding `json:"findings"`
+}
+
+type Lint struct {
+	Params        *LintParams
+	Results       LintResults
+	ResultsWriter common.ResultsWriterInterface
+}
+
+func NewLint(cmd *cobra.Command) (*Lint, error) {
+	params := &LintParams{}
+	if err := common.ParseParameters(cmd, LintParamsConfig, params); err != nil {
+		return nil, err
+	}
+	return &Lint{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}, nil
+}
+
+// Run executes the command logic.
+func (c *Lint) Run() error {
+	common.LogParameters(LintParamsConfig, c.Params)
+
+	if err := validateLintParams(c.Params.DisableRules, c.Params.FailOn); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	containerfile, err := dockerfile.Parse(c.Params.Containerfile)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", c.Params.Containerfile, err)
+	}
+
+	args := processKeyValueEnvs(c.Params.BuildArgs)
+	if c.Params.BuildArgsFile != "" {
+		fileArgs, err := buildargs.ParseBuildArgFile(c.Params.BuildArgsFile)
+		if err != nil {
+			return fmt.Errorf("failed to read build args file: %w", err)
+		}
+		// --build-args take precedence
+		maps.Copy(fileArgs, args)
+		args = fileArgs
+	}
+	containerfile.Expand(func(word string) (string, error) {
+		if value, ok := args[word]; ok {
+			return value, nil
+		}
+		return "", fmt.Errorf("not defined: $%s", word)
+	})
+
+	c.Results.Findings = lintContainerfile(containerfile, &lintOptions{
+		labels:                  c.Params.Labels,
+		requiredLabels:          c.Params.RequiredLabels,
+		disabledRules:           c.Params.DisableRules,
+		requirePinnedBaseImages: c.Params.RequirePinnedBaseImages,
+	})
+	logLintFindings(c.Results.Findings)
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	return checkLintFindings(c.Results.Findings, c.Params.FailOn)
+}
+
+func validateLintParams(disabledRules []string, failOn string) error {
+	for _, rule := range disabledRules {
+		if !slices.ContainsFunc(lintRules, func(r lintRule) bool { return r.name == rule }) {
+			return fmt.Errorf("unknown lint rule '%s'", rule)
+		}
+	}
+	if _, ok := lintSeverityLevels[failOn]; !ok && failOn != lintFailOnNever {
+		return fmt.Errorf("fail-on must be 'info', 'warning', 'error' or 'never', got '%s'", failOn)
+	}
+	return nil
+}
+
+func logLintFindings(findings []LintFinding) {
+	for _, finding := range findings {
+		location := ""
+		if finding.Line > 0 {
+			location = fmt.Sprintf("line %d: ", finding.Line)
+		}
+		l.Logger.Warnf("[%s] %s%s (%s)", finding.Severity, location, finding.Message, finding.Rule)
+	}
+	if len(findings) == 0 {
+		l.Logger.Info("No lint findings")
+	}
+}
+
+// Return an error if any of the findings is of the failOn severity or higher.
+func checkLintFindings(findings []LintFinding, failOn string) error {
+	if failOn == lintFailOnNever {
+		return nil
+	}
+	failing := 0
+	for _, finding := range findings {
+		if lintSeverityLevels[finding.Severity] >= lintSeverityLevels[failOn] {
+			failing++
+		}
+	}
+	if failing > 0 {
+		return fmt.Errorf("containerfile lint failed: %d finding(s) of severity '%s' or higher", failing, failOn)
+	}
+	return nil
+}
+
+type lintOptions struct {
+	// Labels set outside the Containerfile, KEY=VALUE
+	labels         []string
+	requiredLabels []string
+	disabledRules  []string
+	// Enables the opt-in unpinned-base-image rule
+	requirePinnedBaseImages bool
+}
+
+// A problem found by a rule, the rule fills in its name and severity
+type lintViolation struct {
+	line    int
+	message string
+}
+
+type lintRule struct {
+	name     string
+	severity string
+	check    func(containerfile *dockerfile.Dockerfile, opts *lintOptions) []lintViolation
+}
+
+var lintRules = []lintRule{
+	{name: "required-labels", severity: LintSeverityWarning, check: lintRequiredLabels},
+	{name: "latest-base-image", severity: LintSeverityWarning, check: lintLatestBaseImage},
+	{name: "unpinned-base-image", severity: LintSeverityError, check: lintUnpinnedBaseImage},
+	{name: "add-instead-of-copy", severity: LintSeverityInfo, check: lintAddInsteadOfCopy},
+	{name: "secret-in-env", severity: LintSeverityError, check: lintSecretInEnv},
+	{name: "secret-in-arg", severity: LintSeverityWarning, check: lintSecretInArg},
+	{name: "unpinned-packages", severity: LintSeverityWarning, check: lintUnpinnedPackages},
+}
+
+// lintContainerfile runs the lint rules on the parsed (and expanded) Containerfile.
+func lintContainerfile(containerfile *dockerfile.Dockerfile, opts *lintOptions) []LintFinding {
+	findings := []LintFinding{}
+	for _, rule := range lintRules {
+		if slices.Contains(opts.disabledRules, rule.name) {
+			continue
+		}
+		for _, violation := range rule.check(containerfile, opts) {
+			findings = append(findings, LintFinding{
+				Rule:     rule.name,
+				Severity: rule.severity,
+				Message:  violation.message,
+				Line:     violation.line,
+			})
+		}
+	}
+	return findings
+}
+
+func locationLine(location []parser.Range) int {
+	if len(location) == 0 {
+		return 0
+	}
+	return location[0].Start.Line
+}
+
+// The labels of the final stage, including the ones inherited from the stages it's based on.
+// The labels of the base images are not known.
+func lintRequiredLabels(containerfile *dockerfile.Dockerfile, opts *lintOptions) []lintViolation {
+	if len(containerfile.Stages) == 0 || len(opts.requiredLabels) == 0 {
+		return nil
+	}
+
+	labels := make(map[string]bool)
+	for _, label := range opts.labels {
+		key, _, _ := strings.Cut(label, "=")
+		labels[key] = true
+	}
+	finalStage := containerfile.Stages[len(containerfile.Stages)-1]
+	for stage := finalStage; stage != nil; {
+		for _, cmd := range stage.Commands {
+			if labelCmd, ok := cmd.Command.(*instructions.LabelCommand); ok {
+				for _, label := range labelCmd.Labels {
+					labels[label.Key] = true
+				}
+			}
+		}
+		if stage.From.Stage == nil {
+			break
+		}
+		stage = containerfile.Stages[stage.From.Stage.Index]
+	}
+
+	var missing []string
+	for _, label := range opts.requiredLabels {
+		if !labels[label] {
+			missing = append(missing, label)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return []lintViolation{{
+		line:    locationLine(finalStage.Location),
+		message: fmt.Sprintf("final stage does not set the required labels: %s", strings.Join(missing, ", ")),
+	}}
+}
+
+func lintLatestBaseImage(containerfile *dockerfile.Dockerfile, _ *lintOptions) []lintViolation {
+	var violations []lintViolation
+	for _, stage := range containerfile.Stages {
+		if stage.From.Image == nil || strings.Contains(*stage.From.Image, "$") {
+			continue
+		}
+		named, err := reference.ParseNormalizedNamed(*stage.From.Image)
+		if err != nil {
+			// Not the linter's job, the build reports it
+			continue
+		}
+		if _, ok := named.(reference.Digested); ok {
+			continue
+		}
+		if tagged, ok := named.(reference.Tagged); ok && tagged.Tag() != "latest" {
+			continue
+		}
+		violations = append(violations, lintViolation{
+			line:    locationLine(stage.Location),
+			message: fmt.Sprintf("base image %s uses the latest tag, pin it to a specific tag or digest", *stage.From.Image),
+		})
+	}
+	return violations
+}
+
+func lintUnpinnedBaseImage(containerfile *dockerfile.Dockerfile, opts *lintOptions) []lintViolation {
+	if !opts.requirePinnedBaseImages {
+		return nil
+	}
+	return findUnpinnedBaseImages(containerfile)
+}
+
+// Find the FROM instructions referencing a registry image by a tag, which may move, instead of a digest.
+// Stages built from other stages or from scratch and the images of non-registry transports are not checked.
+// The references that can't be parsed, e.g. because of an undefined build arg, are reported as well.
+func findUnpinnedBaseImages(containerfile *dockerfile.Dockerfile) []lintViolation {
+	var violations []lintViolation
+	for _, stage := range containerfile.Stages {
+		if stage.From.Image == nil {
+			continue
+		}
+		transport, bareImage := common.SplitImageTransport(*stage.From.Image)
+		if transport != "" && transport != "docker://" {
+			continue
+		}
+		if named, err := reference.ParseNormalizedNamed(bareImage); err == nil {
+			if _, ok := named.(reference.Digested); ok {
+				continue
+			}
+		}
+		violations = append(violations, lintViolation{
+			line:    locationLine(stage.Location),
+			message: fmt.Sprintf("base image %s is not pinned to a digest", *stage.From.Image),
+		})
+	}
+	return violations
+}
+
+var addArchiveSuffixes = []string{".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2", ".tar.xz", ".txz", ".tar.zst"}
+
+// ADD is only needed to fetch remote sources or to extract local archives
+func lintAddInsteadOfCopy(containerfile *dockerfile.Dockerfile, _ *lintOptions) []lintViolation {
+	var violations []lintViolation
+	for _, stage := range containerfile.Stages {
+		for _, cmd := range stage.Commands {
+			addCmd, ok := cmd.Command.(*instructions.AddCommand)
+			if !ok || len(addCmd.SourceContents) > 0 {
+				continue
+			}
+			needsAdd := slices.ContainsFunc(addCmd.SourcePaths, func(source string) bool {
+				if strings.Contains(source, "://") || strings.HasPrefix(source, "git@") {
+					return true
+				}
+				return slices.ContainsFunc(addArchiveSuffixes, func(suffix string) bool {
+					return strings.HasSuffix(strings.ToLower(source), suffix)
+				})
+			})
+			if !needsAdd {
+				violations = append(violations, lintViolation{
+					line:    locationLine(addCmd.Location()),
+					message: "use COPY instead of ADD for local files and directories",
+				})
+			}
+		}
+	}
+	return violations
+}
+
+var (
+	secretNameRegex = regexp.MustCompile(`(?i)(^|_)(password|passwd|pwd|secret|token|api_?key|access_?key|private_?key|credentials?)(_|$)`)
+	// Variables pointing to a secret rather than holding it
+	secretReferenceRegex = regexp.MustCompile(`(?i)_(file|path|dir)$`)
+)
+
+func isSecretName(name string) bool {
+	return secretNameRegex.MatchString(name) && !secretReferenceRegex.MatchString(name)
+}
+
+// ENV values are stored in the image config, anyone who can pull the image can read them
+func lintSecretInEnv(containerfile *dockerfile.Dockerfile, _ *lintOptions) []lintViolation {
+	var violations []lintViolation
+	for _, stage := range containerfile.Stages {
+		for _, cmd := range stage.Commands {
+			envCmd, ok := cmd.Command.(*instructions.EnvCommand)
+			if !ok {
+				continue
+			}
+			for _, env := range envCmd.Env {
+				if env.Value != "" && isSecretName(env.Key) {
+					violations = append(violations, lintViolation{
+						line:    locationLine(envCmd.Location()),
+						message: fmt.Sprintf("ENV %s looks like a secret, it is stored in the image, use a build secret instead", env.Key),
+					})
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// ARG values end up in the image history of the RUN instructions using them
+func lintSecretInArg(containerfile *dockerfile.Dockerfile, _ *lintOptions) []lintViolation {
+	var violations []lintViolation
+	check := func(key string, value *string, line int) {
+		if value != nil && *value != "" && isSecretName(key) {
+			violations = append(violations, lintViolation{
+				line:    line,
+				message: fmt.Sprintf("ARG %s looks like a secret, it may be recorded in the image history, use a build secret instead", key),
+			})
+		}
+	}
+	for _, metaArg := range containerfile.MetaArgs {
+		check(metaArg.Key, metaArg.Value, locationLine(metaArg.Location()))
+	}
+	for _, stage := range containerfile.Stages {
+		for _, cmd := range stage.Commands {
+			if argCmd, ok := cmd.Command.(*instructions.ArgCommand); ok {
+				for _, arg := range argCmd.Args {
+					check(arg.Key, arg.Value, locationLine(argCmd.Location()))
+				}
+			}
+		}
+	}
+	return violations
+}
+
+var (
+	rpmPackageManagers = []string{"dnf", "dnf5", "yum", "microdnf", "tdnf"}
+	debPackageManagers = []string{"apt-get", "apt"}
+	// Options of the package managers taking a value as the next argument
+	packageManagerValueOptions = []string{
+		"-c", "--config", "--enablerepo", "--disablerepo", "--repo", "--repoid", "--setopt",
+		"--installroot", "--releasever", "-x", "--exclude", "-o", "--option", "-t", "--target-release",
+	}
+	shellCommandSeparatorRegex = regexp.MustCompile(`&&|\|\||[;|\n]`)
+	// name-version[-release], the version and release start with a digit
+	rpmVersionRegex = regexp.MustCompile(`-\d[^-]*$`)
+)
+
+func lintUnpinnedPackages(containerfile *dockerfile.Dockerfile, _ *lintOptions) []lintViolation {
+	var violations []lintViolation
+	for _, stage := range containerfile.Stages {
+		for _, cmd := range stage.Commands {
+			runCmd, ok := cmd.Command.(*instructions.RunCommand)
+			if !ok {
+				continue
+			}
+			scripts := []string{strings.Join(runCmd.CmdLine, " ")}
+			for _, file := range runCmd.Files {
+				scripts = append(scripts, file.Data)
+			}
+			var unpinned []string
+			for _, script := range scripts {
+				unpinned = append(unpinned, findUnpinnedPackages(script)...)
+			}
+			if len(unpinned) > 0 {
+				violations = append(violations, lintViolation{
+					line:    locationLine(runCmd.Location()),
+					message: fmt.Sprintf("packages are installed without a pinned version: %s", strings.Join(unpinned, ", ")),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// Find the packages installed by dnf/yum/apt without a version in a shell script.
+// A best-effort check, the script is split into simple commands and words without a full shell parser.
+func findUnpinnedPackages(script string) []string {
+	script = strings.ReplaceAll(script, "\\\n", " ")
+
+	var unpinned []string
+	for _, command := range shellCommandSeparatorRegex.Split(script, -1) {
+		words := strings.Fields(command)
+		// Skip variable assignments and sudo in front of the command
+		for len(words) > 0 && (strings.Contains(words[0], "=") || words[0] == "sudo") {
+			words = words[1:]
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		manager := path.Base(words[0])
+		isRpm := slices.Contains(rpmPackageManagers, manager)
+		isDeb := slices.Contains(debPackageManagers, manager)
+		if !isRpm && !isDeb {
+			continue
+		}
+
+		// Skip the options in front of the subcommand
+		i := 1
+		for ; i < len(words) && strings.HasPrefix(words[i], "-"); i++ {
+			if slices.Contains(packageManagerValueOptions, words[i]) {
+				i++
+			}
+		}
+		if i >= len(words) || words[i] != "install" {
+			continue
+		}
+
+		for i++; i < len(words); i++ {
+			word := strings.Trim(words[i], `'"`)
+			switch {
+			case slices.Contains(packageManagerValueOptions, word):
+				i++
+			case word == "" || strings.HasPrefix(word, "-") || strings.ContainsAny(word, "$<>&/*@"):
+				// Options, variables, redirections, paths, URLs, globs and groups
+			case isRpm && !strings.HasSuffix(word, ".rpm") && !rpmVersionRegex.MatchString(word):
+				unpinned = append(unpinned, word)
+			case isDeb && !strings.HasSuffix(word, ".deb") && !strings.Contains(word, "="):
+				unpinned = append(unpinned, word)
+			}
+		}
+	}
+	return unpinned
+}
@@ -0,0 +1,243 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/keilerkonzept/dockerfile-json/pkg/dockerfile"
+	. "github.com/onsi/gomega"
+)
+
+func parseLintTestContainerfile(t *testing.T, content string) *dockerfile.Dockerfile {
+	containerfile, err := dockerfile.ParseReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("parsing Containerfile: %v", err)
+	}
+	containerfile.Expand(func(word string) (string, error) { return "", os.ErrNotExist })
+	return containerfile
+}
+
+func Test_lintContainerfile(t *testing.T) {
+	testCases := []struct {
+		name             string
+		containerfile    string
+		opts             lintOptions
+		expectedFindings []LintFinding
+	}{
+		{
+			name: "clean Containerfile",
+			containerfile: `FROM quay.io/org/base:1.0 AS builder
+COPY . /src
+RUN dnf install -y golang-1.22.1-1.el9 && dnf clean all
+FROM quay.io/org/base@sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b
+LABEL name=app version=1.0
+COPY --from=builder /src/app /app
+`,
+			opts:             lintOptions{requiredLabels: []string{"name", "version"}},
+			expectedFindings: []LintFinding{},
+		},
+		{
+			name: "required labels from the build and the parent stages",
+			containerfile: `FROM quay.io/org/base:1.0 AS base
+LABEL name=app
+FROM base
+LABEL version=1.0
+`,
+			opts: lintOptions{
+				labels:         []string{"summary=The app"},
+				requiredLabels: []string{"name", "version", "summary", "description"},
+			},
+			expectedFindings: []LintFinding{
+				{Rule: "required-labels", Severity: "warning", Line: 3, Message: "final stage does not set the required labels: description"},
+			},
+		},
+		{
+			name: "latest base images",
+			containerfile: `ARG BASE=quay.io/org/base
+FROM quay.io/org/builder AS builder
+FROM docker.io/library/alpine:latest AS alpine
+FROM scratch AS empty
+FROM $BASE
+FROM builder
+`,
+			expectedFindings: []LintFinding{
+				{Rule: "latest-base-image", Severity: "warning", Line: 2, Message: "base image quay.io/org/builder uses the latest tag, pin it to a specific tag or digest"},
+				{Rule: "latest-base-image", Severity: "warning", Line: 3, Message: "base image docker.io/library/alpine:latest uses the latest tag, pin it to a specific tag or digest"},
+				{Rule: "latest-base-image", Severity: "warning", Line: 5, Message: "base image quay.io/org/base uses the latest tag, pin it to a specific tag or digest"},
+			},
+		},
+		{
+			name: "unpinned base images",
+			containerfile: `FROM quay.io/org/builder:1.0 AS builder
+FROM scratch AS empty
+FROM builder
+FROM oci-archive:/tmp/base.tar
+FROM docker://quay.io/org/base@sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b
+FROM quay.io/org/base:1.0@sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b
+FROM registry.access.redhat.com/ubi9:9.4
+`,
+			opts: lintOptions{requirePinnedBaseImages: true},
+			expectedFindings: []LintFinding{
+				{Rule: "unpinned-base-image", Severity: "error", Line: 1, Message: "base image quay.io/org/builder:1.0 is not pinned to a digest"},
+				{Rule: "unpinned-base-image", Severity: "error", Line: 7, Message: "base image registry.access.redhat.com/ubi9:9.4 is not pinned to a digest"},
+			},
+		},
+		{
+			name: "ADD of local files",
+			containerfile: `FROM quay.io/org/base:1.0
+ADD app.conf /etc/app.conf
+ADD https://example.com/app.conf /etc/app.conf
+ADD rootfs.tar.gz /
+`,
+			expectedFindings: []LintFinding{
+				{Rule: "add-instead-of-copy", Severity: "info", Line: 2, Message: "use COPY instead of ADD for local files and directories"},
+			},
+		},
+		{
+			name: "secrets in ENV and ARG",
+			containerfile: `ARG GITHUB_TOKEN=ghp_123
+ARG NPM_TOKEN
+FROM quay.io/org/base:1.0
+ARG DB_USER=admin
+ENV DB_PASSWORD=hunter2 API_KEY_FILE=/run/secrets/api-key TOKENIZER=simple
+ENV SECRET=""
+`,
+			expectedFindings: []LintFinding{
+				{Rule: "secret-in-env", Severity: "error", Line: 5, Message: "ENV DB_PASSWORD looks like a secret, it is stored in the image, use a build secret instead"},
+				{Rule: "secret-in-arg", Severity: "warning", Line: 1, Message: "ARG GITHUB_TOKEN looks like a secret, it may be recorded in the image history, use a build secret instead"},
+			},
+		},
+		{
+			name: "unpinned packages",
+			containerfile: `FROM quay.io/org/base:1.0
+RUN microdnf install -y --setopt=install_weak_deps=0 curl-7.76.1 jq && microdnf clean all
+RUN apt-get update && \
+    apt-get install -y curl=7.88.1 jq
+RUN pip install requests
+`,
+			expectedFindings: []LintFinding{
+				{Rule: "unpinned-packages", Severity: "warning", Line: 2, Message: "packages are installed without a pinned version: jq"},
+				{Rule: "unpinned-packages", Severity: "warning", Line: 3, Message: "packages are installed without a pinned version: jq"},
+			},
+		},
+		{
+			name: "disabled rules",
+			containerfile: `FROM quay.io/org/builder
+ADD app.conf /etc/app.conf
+`,
+			opts:             lintOptions{disabledRules: []string{"latest-base-image", "add-instead-of-copy"}},
+			expectedFindings: []LintFinding{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			containerfile := parseLintTestContainerfile(t, tc.containerfile)
+
+			findings := lintContainerfile(containerfile, &tc.opts)
+
+			g.Expect(findings).To(Equal(tc.expectedFindings))
+		})
+	}
+}
+
+func Test_findUnpinnedPackages(t *testing.T) {
+	testCases := []struct {
+		script   string
+		expected []string
+	}{
+		{"dnf install -y curl", []string{"curl"}},
+		{"dnf -y --enablerepo crb install java-17-openjdk curl-7.76.1-26.el9 ./local.rpm @development", []string{"java-17-openjdk"}},
+		{"yum install -y 'git' \"make\" > /dev/null 2>&1", []string{"git", "make"}},
+		{"DEBIAN_FRONTEND=noninteractive sudo /usr/bin/apt-get -o Dpkg::Use-Pty=0 install -y curl jq=1.6", []string{"curl"}},
+		{"apt install -y $PACKAGES", nil},
+		{"dnf update -y && dnf remove -y vim", nil},
+		{"echo dnf install curl", nil},
+		{"dnf install -y \\\n  curl \\\n  jq-1.6", []string{"curl"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.script, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(findUnpinnedPackages(tc.script)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func Test_checkLintFindings(t *testing.T) {
+	g := NewWithT(t)
+	findings := []LintFinding{
+		{Rule: "add-instead-of-copy", Severity: LintSeverityInfo},
+		{Rule: "latest-base-image", Severity: LintSeverityWarning},
+	}
+
+	g.Expect(checkLintFindings(findings, "error")).To(Succeed())
+	g.Expect(checkLintFindings(findings, "never")).To(Succeed())
+	g.Expect(checkLintFindings(findings, "warning")).To(MatchError("containerfile lint failed: 1 finding(s) of severity 'warning' or higher"))
+	g.Expect(checkLintFindings(findings, "info")).To(MatchError("containerfile lint failed: 2 finding(s) of severity 'info' or higher"))
+}
+
+func Test_validateLintParams(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(validateLintParams([]string{"secret-in-env"}, "warning")).To(Succeed())
+	g.Expect(validateLintParams(nil, "never")).To(Succeed())
+	g.Expect(validateLintParams([]string{"no-such-rule"}, "error")).To(MatchError("unknown lint rule 'no-such-rule'"))
+	g.Expect(validateLintParams(nil, "fatal")).To(MatchError(ContainSubstring("fail-on must be")))
+}
+
+func Test_Lint_Run(t *testing.T) {
+	containerfilePath := filepath.Join(t.TempDir(), "Containerfile")
+	os.WriteFile(containerfilePath, []byte(`ARG BASE_TAG
+FROM quay.io/org/base:${BASE_TAG}
+ENV APP_TOKEN=abc
+`), 0644)
+
+	t.Run("should expand build args and fail on errors", func(t *testing.T) {
+		g := NewWithT(t)
+		var printedResults any
+		resultsWriter := &mockResultsWriter{
+			CreateResultJsonFunc: func(result any) (string, error) {
+				printedResults = result
+				return "", nil
+			},
+		}
+		c := &Lint{
+			Params: &LintParams{
+				Containerfile: containerfilePath,
+				BuildArgs:     []string{"BASE_TAG=latest"},
+				FailOn:        "error",
+			},
+			ResultsWriter: resultsWriter,
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(MatchError(ContainSubstring("1 finding(s) of severity 'error' or higher")))
+		g.Expect(c.Results.Findings).To(Equal([]LintFinding{
+			{Rule: "latest-base-image", Severity: "warning", Line: 2, Message: "base image quay.io/org/base:latest uses the latest tag, pin it to a specific tag or digest"},
+			{Rule: "secret-in-env", Severity: "error", Line: 3, Message: "ENV APP_TOKEN looks like a secret, it is stored in the image, use a build secret instead"},
+		}))
+		// The findings are printed before failing
+		g.Expect(printedResults).To(Equal(c.Results))
+	})
+
+	t.Run("should pass with the failing rule disabled", func(t *testing.T) {
+		g := NewWithT(t)
+		c := &Lint{
+			Params: &LintParams{
+				Containerfile: containerfilePath,
+				BuildArgs:     []string{"BASE_TAG=1.0"},
+				DisableRules:  []string{"secret-in-env"},
+				FailOn:        "warning",
+			},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+		g.Expect(c.Results.Findings).To(BeEmpty())
+	})
+}
@@ -0,0 +1,291 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// Kinds of the artifacts of an image
+const (
+	artifactKindSBOM           = "sbom"
+	artifactKindSignature      = "signature"
+	artifactKindAttestation    = "attestation"
+	artifactKindContainerfile  = "containerfile"
+	artifactKindPrefetchOutput = "prefetch-output"
+	artifactKindOther          = "other"
+)
+
+// Where an artifact of an image was found
+const (
+	artifactSourceReferrer = "referrer"
+	artifactSourceTag      = "tag"
+)
+
+// Suffixes of the tags named after the image digest (sha256-<digest><suffix>), used before
+// the OCI referrers API, and the kinds of the artifacts tagged so
+var legacyArtifactTagSuffixes = []struct {
+	suffix string
+	kind   string
+}{
+	{".sig", artifactKindSignature},
+	{".att", artifactKindAttestation},
+	{sbomArtifactTagSuffix, artifactKindSBOM},
+	{".dockerfile", artifactKindContainerfile},
+	{containerfileArtifactTagSuffix, artifactKindContainerfile},
+}
+
+var referrerArtifactTypeKinds = map[string]string{
+	containerfileArtifactType:                         artifactKindContainerfile,
+	prefetchOutputArtifactType:                        artifactKindPrefetchOutput,
+	"application/vnd.dev.cosign.artifact.sig.v1+json": artifactKindSignature,
+	"application/vnd.dsse.envelope.v1+json":           artifactKindAttestation,
+	"application/vnd.in-toto+json":                    artifactKindAttestation,
+}
+
+var ListArtifactsParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_LIST_ARTIFACTS_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Image to list the artifacts of, with a tag or digest. Required.",
+		Required:   true,
+	},
+	"digest": {
+		Name:       "digest",
+		ShortName:  "d",
+		EnvVarName: "KBC_LIST_ARTIFACTS_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Digest of the image. Overrides the tag and digest of --image-url.",
+	},
+}
+
+type ListArtifactsParams struct {
+	ImageUrl string `paramName:"image-url"`
+	Digest   string `paramName:"digest"`
+}
+
+type ImageArtifact struct {
+	// sbom, signature, attestation, containerfile, prefetch-output or other
+	Kind string `json:"kind"`
+	// referrer or tag
+	Source string `json:"source"`
+	// Digested reference of the artifact
+	Ref string `json:"ref"`
+	// The tag of the artifact found by tag
+	Tag          string `json:"tag,omitempty"`
+	Digest       string `json:"digest"`
+	MediaType    string `json:"media_type,omitempty"`
+	ArtifactType string `json:"artifact_type,omitempty"`
+}
+
+type ListArtifactsResults struct {
+	// Digested reference of the image
+	ImageRef  string          `json:"image_ref"`
+	Artifacts []ImageArtifact `json:"artifacts"`
+}
+
+type ListArtifactsCliWrappers struct {
+	OrasCli cliwrappers.OrasCliInterface
+}
+
+type ListArtifacts struct {
+	Params        *ListArtifactsParams
+	CliWrappers   ListArtifactsCliWrappers
+	Results       ListArtifactsResults
+	ResultsWriter common.ResultsWriterInterface
+
+	imageName      string
+	registryConfig string
+}
+
+func NewListArtifacts(cmd *cobra.Command) (*ListArtifacts, error) {
+	params := &ListArtifactsParams{}
+	if err := common.ParseParameters(cmd, ListArtifactsParamsConfig, params); err != nil {
+		return nil, err
+	}
+	listArtifacts := &ListArtifacts{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}
+	if err := listArtifacts.initCliWrappers(); err != nil {
+		return nil, err
+	}
+	return listArtifacts, nil
+}
+
+func (c *ListArtifacts) initCliWrappers() error {
+	executor := cliwrappers.NewCliExecutor()
+	orasCli, err := cliwrappers.NewOrasCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.OrasCli = orasCli
+	return nil
+}
+
+func (c *ListArtifacts) Run() error {
+	common.LogParameters(ListArtifactsParamsConfig, c.Params)
+
+	c.imageName = common.GetImageName(c.Params.ImageUrl)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	registryConfig, err := common.WriteOrasRegistryConfig(c.Params.ImageUrl)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(registryConfig); err != nil {
+			l.Logger.Warnf("failed to remove %s: %s", registryConfig, err.Error())
+		}
+	}()
+	c.registryConfig = registryConfig
+
+	imageDigest, err := c.resolveDigest()
+	if err != nil {
+		return err
+	}
+	c.Results.ImageRef = c.imageName + "@" + imageDigest
+
+	referrers, err := c.listReferrers(imageDigest)
+	if err != nil {
+		return err
+	}
+	tagged, err := c.listTaggedArtifacts(imageDigest)
+	if err != nil {
+		return err
+	}
+	c.Results.Artifacts = append(referrers, tagged...)
+	l.Logger.Infof("Found %d referrers and %d tagged artifacts of %s", len(referrers), len(tagged), c.Results.ImageRef)
+
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	return nil
+}
+
+// The digest from the params, or of the manifest the tag of --image-url points to.
+func (c *ListArtifacts) resolveDigest() (string, error) {
+	if c.Params.Digest != "" {
+		return c.Params.Digest, nil
+	}
+	if imageDigest := common.GetImageDigest(c.Params.ImageUrl); imageDigest != "" {
+		return imageDigest, nil
+	}
+	manifest, err := c.CliWrappers.OrasCli.ManifestFetch(&cliwrappers.OrasManifestFetchArgs{
+		Image:          c.Params.ImageUrl,
+		RegistryConfig: c.registryConfig,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error on fetching manifest of %s: %w", c.Params.ImageUrl, err)
+	}
+	// oras prints the raw manifest
+	return digest.FromString(manifest).String(), nil
+}
+
+func (c *ListArtifacts) listReferrers(imageDigest string) ([]ImageArtifact, error) {
+	subject := c.imageName + "@" + imageDigest
+	referrers, err := c.CliWrappers.OrasCli.Discover(&cliwrappers.OrasDiscoverArgs{
+		Subject:        subject,
+		RegistryConfig: c.registryConfig,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on discovering referrers of %s: %w", subject, err)
+	}
+
+	artifacts := []ImageArtifact{}
+	for _, referrer := range referrers {
+		artifacts = append(artifacts, ImageArtifact{
+			Kind:         referrerArtifactKind(referrer.ArtifactType),
+			Source:       artifactSourceReferrer,
+			Ref:          c.imageName + "@" + referrer.Digest,
+			Digest:       referrer.Digest,
+			MediaType:    referrer.MediaType,
+			ArtifactType: referrer.ArtifactType,
+		})
+	}
+	return artifacts, nil
+}
+
+// List the artifacts tagged after the image digest that exist.
+func (c *ListArtifacts) listTaggedArtifacts(imageDigest string) ([]ImageArtifact, error) {
+	digestTag := strings.Replace(imageDigest, ":", "-", 1)
+
+	artifacts := []ImageArtifact{}
+	for _, tagSuffix := range legacyArtifactTagSuffixes {
+		tag := digestTag + tagSuffix.suffix
+		content, err := c.CliWrappers.OrasCli.ManifestFetch(&cliwrappers.OrasManifestFetchArgs{
+			Image:          c.imageName + ":" + tag,
+			RegistryConfig: c.registryConfig,
+		})
+		if cliwrappers.IsOrasNotFound(err) {
+			l.Logger.Debugf("No artifact tagged %s", tag)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error on fetching manifest of %s:%s: %w", c.imageName, tag, err)
+		}
+
+		var manifest specs.Manifest
+		if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+			return nil, fmt.Errorf("parsing manifest of %s:%s: %w", c.imageName, tag, err)
+		}
+		artifactType := manifest.ArtifactType
+		if artifactType == "" {
+			artifactType = manifest.Config.MediaType
+		}
+		artifactDigest := digest.FromString(content).String()
+		artifacts = append(artifacts, ImageArtifact{
+			Kind:         tagSuffix.kind,
+			Source:       artifactSourceTag,
+			Ref:          c.imageName + "@" + artifactDigest,
+			Tag:          tag,
+			Digest:       artifactDigest,
+			MediaType:    manifest.MediaType,
+			ArtifactType: artifactType,
+		})
+	}
+	return artifacts, nil
+}
+
+func referrerArtifactKind(artifactType string) string {
+	if isSBOMMediaType(artifactType) {
+		return artifactKindSBOM
+	}
+	if kind, ok := referrerArtifactTypeKinds[artifactType]; ok {
+		return kind
+	}
+	return artifactKindOther
+}
+
+func (c *ListArtifacts) validateParams() error {
+	if !common.IsImageNameValid(c.imageName) {
+		return fmt.Errorf("image name '%s' is invalid", c.imageName)
+	}
+	if c.Params.Digest == "" {
+		if err := common.ValidateImageHasTagOrDigest(c.Params.ImageUrl); err != nil {
+			return err
+		}
+	} else if !common.IsImageDigestValid(c.Params.Digest) {
+		return fmt.Errorf("image digest '%s' is invalid", c.Params.Digest)
+	}
+	return nil
+}
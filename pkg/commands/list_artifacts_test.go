@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_ListArtifacts_Run(t *testing.T) {
+	const imageDigest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	const digestTag = "sha256-e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	const sbomReferrerDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	const sigReferrerDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	const sigManifest = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json",` +
+		`"config":{"mediaType":"application/vnd.oci.image.config.v1+json"}}`
+	const containerfileManifest = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json",` +
+		`"artifactType":"application/vnd.konflux.containerfile"}`
+
+	workDir := t.TempDir()
+	t.Setenv("HOME", workDir)
+	os.Mkdir(filepath.Join(workDir, ".docker"), 0755)
+	os.WriteFile(filepath.Join(workDir, ".docker", "config.json"), []byte(`{"auths":{"quay.io":{"auth":"token"}}}`), 0644)
+
+	newListArtifacts := func(orasCli *mockOrasCli) *ListArtifacts {
+		return &ListArtifacts{
+			Params:        &ListArtifactsParams{ImageUrl: "quay.io/org/app@" + imageDigest},
+			CliWrappers:   ListArtifactsCliWrappers{OrasCli: orasCli},
+			ResultsWriter: &mockResultsWriter{},
+		}
+	}
+	manifestFetch := func(manifests map[string]string) func(args *cliwrappers.OrasManifestFetchArgs) (string, error) {
+		return func(args *cliwrappers.OrasManifestFetchArgs) (string, error) {
+			if manifest, ok := manifests[args.Image]; ok {
+				return manifest, nil
+			}
+			return "", errors.New("exit status 1: Error: " + args.Image + ": not found")
+		}
+	}
+
+	t.Run("should list referrers and tagged artifacts", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newListArtifacts(&mockOrasCli{
+			DiscoverFunc: func(args *cliwrappers.OrasDiscoverArgs) ([]cliwrappers.OrasReferrer, error) {
+				g.Expect(args.Subject).To(Equal("quay.io/org/app@" + imageDigest))
+				g.Expect(args.RegistryConfig).ToNot(BeEmpty())
+				return []cliwrappers.OrasReferrer{
+					{Digest: sbomReferrerDigest, MediaType: "application/vnd.oci.image.manifest.v1+json", ArtifactType: "application/spdx+json"},
+					{Digest: sigReferrerDigest, MediaType: "application/vnd.oci.image.manifest.v1+json", ArtifactType: "application/vnd.example"},
+				}, nil
+			},
+			ManifestFetchFunc: manifestFetch(map[string]string{
+				"quay.io/org/app:" + digestTag + ".sig":           sigManifest,
+				"quay.io/org/app:" + digestTag + ".containerfile": containerfileManifest,
+			}),
+		})
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(c.Results).To(Equal(ListArtifactsResults{
+			ImageRef: "quay.io/org/app@" + imageDigest,
+			Artifacts: []ImageArtifact{
+				{
+					Kind:         artifactKindSBOM,
+					Source:       artifactSourceReferrer,
+					Ref:          "quay.io/org/app@" + sbomReferrerDigest,
+					Digest:       sbomReferrerDigest,
+					MediaType:    "application/vnd.oci.image.manifest.v1+json",
+					ArtifactType: "application/spdx+json",
+				},
+				{
+					Kind:         artifactKindOther,
+					Source:       artifactSourceReferrer,
+					Ref:          "quay.io/org/app@" + sigReferrerDigest,
+					Digest:       sigReferrerDigest,
+					MediaType:    "application/vnd.oci.image.manifest.v1+json",
+					ArtifactType: "application/vnd.example",
+				},
+				{
+					Kind:         artifactKindSignature,
+					Source:       artifactSourceTag,
+					Ref:          "quay.io/org/app@" + digest.FromString(sigManifest).String(),
+					Tag:          digestTag + ".sig",
+					Digest:       digest.FromString(sigManifest).String(),
+					MediaType:    "application/vnd.oci.image.manifest.v1+json",
+					ArtifactType: "application/vnd.oci.image.config.v1+json",
+				},
+				{
+					Kind:         artifactKindContainerfile,
+					Source:       artifactSourceTag,
+					Ref:          "quay.io/org/app@" + digest.FromString(containerfileManifest).String(),
+					Tag:          digestTag + ".containerfile",
+					Digest:       digest.FromString(containerfileManifest).String(),
+					MediaType:    "application/vnd.oci.image.manifest.v1+json",
+					ArtifactType: containerfileArtifactType,
+				},
+			},
+		}))
+	})
+
+	t.Run("should resolve the digest of the tag", func(t *testing.T) {
+		g := NewWithT(t)
+		const imageManifest = `{"schemaVersion":2}`
+		c := newListArtifacts(&mockOrasCli{
+			ManifestFetchFunc: manifestFetch(map[string]string{"quay.io/org/app:v1": imageManifest}),
+		})
+		c.Params.ImageUrl = "quay.io/org/app:v1"
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(c.Results.ImageRef).To(Equal("quay.io/org/app@" + digest.FromString(imageManifest).String()))
+		g.Expect(c.Results.Artifacts).To(BeEmpty())
+	})
+
+	t.Run("should fail if fetching a tagged artifact fails", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newListArtifacts(&mockOrasCli{
+			ManifestFetchFunc: func(args *cliwrappers.OrasManifestFetchArgs) (string, error) {
+				return "", errors.New("exit status 1: Error: unauthorized")
+			},
+		})
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("unauthorized")))
+	})
+
+	t.Run("should fail if image has no tag or digest", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newListArtifacts(&mockOrasCli{})
+		c.Params.ImageUrl = "quay.io/org/app"
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("must have a tag or digest")))
+	})
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common/sbom"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var MergeSBOMParamsConfig = map[string]common.Parameter{
+	"sbom": {
+		Name:       "sbom",
+		ShortName:  "s",
+		EnvVarName: "KBC_MERGE_SBOM_SBOM",
+		TypeKind:   reflect.Slice,
+		Usage: "SBOM files to merge, in CycloneDX or SPDX JSON format. The first one describes the image, " +
+			"the packages of the others are added to it. Required.",
+		Required: true,
+	},
+	"output": {
+		Name:       "output",
+		ShortName:  "o",
+		EnvVarName: "KBC_MERGE_SBOM_OUTPUT",
+		TypeKind:   reflect.String,
+		Usage:      "Path to write the merged SBOM to. Required.",
+		Required:   true,
+	},
+	"format": {
+		Name:       "format",
+		ShortName:  "f",
+		EnvVarName: "KBC_MERGE_SBOM_FORMAT",
+		TypeKind:   reflect.String,
+		Usage:      "Format of the merged SBOM: cyclonedx or spdx. Defaults to the format of the first SBOM.",
+	},
+}
+
+type MergeSBOMParams struct {
+	SBOMs  []string `paramName:"sbom"`
+	Output string   `paramName:"output"`
+	Format string   `paramName:"format"`
+}
+
+type MergeSBOMResults struct {
+	SBOMPath   string `json:"sbom_path"`
+	SBOMDigest string `json:"sbom_digest"`
+	Format     string `json:"format"`
+}
+
+type MergeSBOM struct {
+	Params        *MergeSBOMParams
+	Results       MergeSBOMResults
+	ResultsWriter common.ResultsWriterInterface
+}
+
+func NewMergeSBOM(cmd *cobra.Command) (*MergeSBOM, error) {
+	params := &MergeSBOMParams{}
+	if err := common.ParseParameters(cmd, MergeSBOMParamsConfig, params); err != nil {
+		return nil, err
+	}
+	return &MergeSBOM{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}, nil
+}
+
+// Run executes the command logic.
+func (c *MergeSBOM) Run() error {
+	common.LogParameters(MergeSBOMParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	var docs []sbom.Document
+	for _, path := range c.Params.SBOMs {
+		doc, err := sbom.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if sbom.Format(doc) == "" {
+			return fmt.Errorf("%s is not a CycloneDX or SPDX SBOM", path)
+		}
+		docs = append(docs, doc)
+	}
+
+	format := c.Params.Format
+	if format == "" {
+		format = sbom.Format(docs[0])
+	}
+	for i, doc := range docs {
+		if sbom.Format(doc) != format {
+			l.Logger.Infof("Converting %s to %s", c.Params.SBOMs[i], format)
+			docs[i] = sbom.Convert(doc, format)
+		}
+	}
+
+	l.Logger.Infof("Merging SBOMs: %v", c.Params.SBOMs)
+	merged, err := json.MarshalIndent(mergeSBOMDocuments(format, docs), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := common.WriteResultFile(c.Params.Output, merged); err != nil {
+		return fmt.Errorf("writing SBOM: %w", err)
+	}
+	l.Logger.Infof("SBOM written to %s", c.Params.Output)
+
+	c.Results = MergeSBOMResults{
+		SBOMPath:   c.Params.Output,
+		SBOMDigest: fmt.Sprintf("sha256:%x", sha256.Sum256(merged)),
+		Format:     format,
+	}
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *MergeSBOM) validateParams() error {
+	if len(c.Params.SBOMs) == 0 {
+		return fmt.Errorf("no SBOMs to merge")
+	}
+	if c.Params.Format != "" && c.Params.Format != "cyclonedx" && c.Params.Format != "spdx" {
+		return fmt.Errorf("format '%s' is invalid, use cyclonedx or spdx", c.Params.Format)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common/sbom"
+)
+
+func Test_MergeSBOM_Run(t *testing.T) {
+	image := func(t *testing.T, g Gomega) string {
+		return writeSBOMFile(t, g, "image.json", `{
+			"spdxVersion": "SPDX-2.3",
+			"SPDXID": "SPDXRef-DOCUMENT",
+			"packages": [
+				{"SPDXID": "SPDXRef-Image", "name": "quay.io/org/app"},
+				{
+					"SPDXID": "SPDXRef-bash", "name": "bash",
+					"externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:rpm/bash@5.1"}]
+				}
+			],
+			"relationships": [
+				{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Image"},
+				{"spdxElementId": "SPDXRef-Image", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-bash"}
+			]
+		}`)
+	}
+	prefetch := func(t *testing.T, g Gomega) string {
+		return writeSBOMFile(t, g, "bom.json", `{
+			"bomFormat": "CycloneDX",
+			"components": [
+				{"bom-ref": "bash", "name": "bash", "purl": "pkg:rpm/bash@5.1"},
+				{"bom-ref": "lib", "name": "example.com/lib", "purl": "pkg:golang/example.com/lib@v1"}
+			]
+		}`)
+	}
+
+	t.Run("should convert and merge SBOMs of different formats", func(t *testing.T) {
+		g := NewWithT(t)
+		output := filepath.Join(t.TempDir(), "sbom.json")
+		var printedResults any
+		c := &MergeSBOM{
+			Params: &MergeSBOMParams{
+				SBOMs:  []string{image(t, g), prefetch(t, g)},
+				Output: output,
+			},
+			ResultsWriter: &mockResultsWriter{
+				CreateResultJsonFunc: func(result any) (string, error) {
+					printedResults = result
+					return "", nil
+				},
+			},
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		content, err := os.ReadFile(output)
+		g.Expect(err).ToNot(HaveOccurred())
+		var doc map[string]any
+		g.Expect(json.Unmarshal(content, &doc)).To(Succeed())
+		g.Expect(sbom.Format(doc)).To(Equal("spdx"))
+		// bash is in both SBOMs
+		g.Expect(doc["packages"]).To(HaveLen(3))
+		g.Expect(sbom.SPDXDescribedElement(doc)).To(Equal("SPDXRef-Image"))
+
+		g.Expect(printedResults).To(Equal(MergeSBOMResults{
+			SBOMPath:   output,
+			SBOMDigest: fmt.Sprintf("sha256:%x", sha256.Sum256(content)),
+			Format:     "spdx",
+		}))
+	})
+
+	t.Run("should write the requested format", func(t *testing.T) {
+		g := NewWithT(t)
+		output := filepath.Join(t.TempDir(), "sbom.json")
+		c := &MergeSBOM{
+			Params: &MergeSBOMParams{
+				SBOMs:  []string{image(t, g), prefetch(t, g)},
+				Output: output,
+				Format: "cyclonedx",
+			},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		content, err := os.ReadFile(output)
+		g.Expect(err).ToNot(HaveOccurred())
+		var doc map[string]any
+		g.Expect(json.Unmarshal(content, &doc)).To(Succeed())
+		g.Expect(sbom.Format(doc)).To(Equal("cyclonedx"))
+		g.Expect(doc["metadata"]).To(HaveKeyWithValue("component", HaveKeyWithValue("name", "quay.io/org/app")))
+		g.Expect(doc["components"]).To(HaveLen(2))
+		g.Expect(c.Results.Format).To(Equal("cyclonedx"))
+	})
+
+	t.Run("should reject invalid input", func(t *testing.T) {
+		g := NewWithT(t)
+		c := &MergeSBOM{
+			Params:        &MergeSBOMParams{SBOMs: []string{image(t, g)}, Output: "sbom.json", Format: "syft"},
+			ResultsWriter: &mockResultsWriter{},
+		}
+		g.Expect(c.Run()).To(MatchError("format 'syft' is invalid, use cyclonedx or spdx"))
+
+		c.Params.Format = ""
+		c.Params.SBOMs = []string{writeSBOMFile(t, g, "other.json", `{"name": "other"}`)}
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("other.json is not a CycloneDX or SPDX SBOM")))
+	})
+}
@@ -0,0 +1,323 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// Suffixes of the tags cosign stores the signatures, attestations and SBOMs of an image in
+var cosignArtifactTagSuffixes = []string{".sig", ".att", sbomArtifactTagSuffix}
+
+var MirrorParamsConfig = map[string]common.Parameter{
+	"source": {
+		Name:       "source",
+		ShortName:  "s",
+		EnvVarName: "KBC_MIRROR_SOURCE",
+		TypeKind:   reflect.String,
+		Usage:      "Image to mirror, with a tag or digest, e.g. quay.io/staging/app:v1. Required.",
+		Required:   true,
+	},
+	"destination": {
+		Name:       "destination",
+		ShortName:  "d",
+		EnvVarName: "KBC_MIRROR_DESTINATION",
+		TypeKind:   reflect.String,
+		Usage: "Repository to mirror the image to, optionally with a tag, e.g. quay.io/prod/app. " +
+			"Without a tag, the tag of the source is used, or the image is pushed by digest only if the source has no tag. Required.",
+		Required: true,
+	},
+	"digest": {
+		Name:       "digest",
+		EnvVarName: "KBC_MIRROR_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Expected digest of the source image. The mirroring fails if the source points to another digest.",
+	},
+	"include-artifacts": {
+		Name:         "include-artifacts",
+		EnvVarName:   "KBC_MIRROR_INCLUDE_ARTIFACTS",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage: "Mirror the artifacts of the image as well: the cosign signature, attestation and SBOM tags " +
+			"(sha256-<digest>.sig, .att, .sbom) and the OCI referrers.",
+	},
+	"src-auth-file": {
+		Name:       "src-auth-file",
+		EnvVarName: "KBC_MIRROR_SRC_AUTH_FILE",
+		TypeKind:   reflect.String,
+		Usage: "Docker config file, or a directory containing config.json or .dockerconfigjson, with the credentials " +
+			"for the source registry. The default auth file is used if not set.",
+	},
+	"dest-auth-file": {
+		Name:       "dest-auth-file",
+		EnvVarName: "KBC_MIRROR_DEST_AUTH_FILE",
+		TypeKind:   reflect.String,
+		Usage: "Docker config file, or a directory containing config.json or .dockerconfigjson, with the credentials " +
+			"for the destination registry. The default auth file is used if not set.",
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_MIRROR_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Require HTTPS and verify certificates when accessing the registries.",
+	},
+	"cert-dir": {
+		Name:       "cert-dir",
+		EnvVarName: "KBC_MIRROR_CERT_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Directory with the certificates (*.crt CA certificates, *.cert and *.key client certificates) for accessing the registries.",
+	},
+}
+
+type MirrorParams struct {
+	Source           string `paramName:"source"`
+	Destination      string `paramName:"destination"`
+	Digest           string `paramName:"digest"`
+	IncludeArtifacts bool   `paramName:"include-artifacts"`
+	SrcAuthFile      string `paramName:"src-auth-file"`
+	DestAuthFile     string `paramName:"dest-auth-file"`
+	TLSVerify        bool   `paramName:"tls-verify"`
+	CertDir          string `paramName:"cert-dir"`
+}
+
+type MirrorCliWrappers struct {
+	SkopeoCli cliwrappers.SkopeoCliInterface
+	// Set with --include-artifacts only
+	OrasCli cliwrappers.OrasCliInterface
+}
+
+type MirroredReference struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+type MirrorResults struct {
+	// Digest of the mirrored image, the same in the source and the destination
+	ImageDigest string `json:"image_digest"`
+	// Digested reference of the mirrored image in the destination
+	ImageRef string `json:"image_ref"`
+	// The image first, then its artifacts
+	Mirrored []MirroredReference `json:"mirrored"`
+}
+
+type Mirror struct {
+	Params        *MirrorParams
+	CliWrappers   MirrorCliWrappers
+	Results       MirrorResults
+	ResultsWriter common.ResultsWriterInterface
+
+	sourceRepository      string
+	destinationRepository string
+	// The tag to push the image to, empty to push by digest only
+	destinationTag string
+	srcAuthFile    string
+	destAuthFile   string
+}
+
+func NewMirror(cmd *cobra.Command) (*Mirror, error) {
+	params := &MirrorParams{}
+	if err := common.ParseParameters(cmd, MirrorParamsConfig, params); err != nil {
+		return nil, err
+	}
+	mirror := &Mirror{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}
+	if err := mirror.initCliWrappers(); err != nil {
+		return nil, err
+	}
+	return mirror, nil
+}
+
+func (c *Mirror) initCliWrappers() error {
+	executor := cliwrappers.NewCliExecutor()
+
+	skopeoCli, err := cliwrappers.NewSkopeoCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.SkopeoCli = skopeoCli
+
+	if c.Params.IncludeArtifacts {
+		orasCli, err := cliwrappers.NewOrasCli(executor)
+		if err != nil {
+			return err
+		}
+		c.CliWrappers.OrasCli = orasCli
+	}
+	return nil
+}
+
+// Run executes the command logic.
+func (c *Mirror) Run() error {
+	common.LogParameters(MirrorParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	imageDigest, err := c.resolveSourceDigest()
+	if err != nil {
+		return err
+	}
+	c.Results.ImageDigest = imageDigest
+
+	destination := c.destinationRepository + "@" + imageDigest
+	if c.destinationTag != "" {
+		destination = c.destinationRepository + ":" + c.destinationTag
+	}
+	if err := c.copy(c.sourceRepository+"@"+imageDigest, destination); err != nil {
+		return err
+	}
+	c.Results.ImageRef = c.destinationRepository + "@" + imageDigest
+
+	if c.Params.IncludeArtifacts {
+		if err := c.mirrorArtifacts(imageDigest); err != nil {
+			return err
+		}
+	}
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Pin the source to the digest it points to now, so that the image and its artifacts
+// are mirrored consistently even if the source tag moves meanwhile.
+func (c *Mirror) resolveSourceDigest() (string, error) {
+	sourceDigest := common.GetImageDigest(c.Params.Source)
+	if sourceDigest == "" {
+		manifest, err := c.CliWrappers.SkopeoCli.Inspect(&cliwrappers.SkopeoInspectArgs{
+			ImageRef:   c.Params.Source,
+			RetryTimes: 3,
+			Raw:        true,
+			TLSVerify:  &c.Params.TLSVerify,
+			CertDir:    c.Params.CertDir,
+			AuthFile:   c.srcAuthFile,
+		})
+		if err != nil {
+			return "", fmt.Errorf("inspecting source image %s: %w", c.Params.Source, err)
+		}
+		sourceDigest = digest.FromString(manifest).String()
+		l.Logger.Infof("Source image %s points to %s", c.Params.Source, sourceDigest)
+	}
+
+	if c.Params.Digest != "" && c.Params.Digest != sourceDigest {
+		return "", fmt.Errorf("source image %s points to %s, expected %s", c.Params.Source, sourceDigest, c.Params.Digest)
+	}
+	return sourceDigest, nil
+}
+
+func (c *Mirror) copy(source, destination string) error {
+	l.Logger.Infof("Mirroring %s to %s", source, destination)
+	err := c.CliWrappers.SkopeoCli.CopyAll(&cliwrappers.SkopeoCopyArgs{
+		SourceImage:         source,
+		DestinationImage:    destination,
+		TLSVerify:           &c.Params.TLSVerify,
+		CertDir:             c.Params.CertDir,
+		SourceAuthFile:      c.srcAuthFile,
+		DestinationAuthFile: c.destAuthFile,
+		// The artifacts refer to the image by digest
+		PreserveDigests: true,
+		RetryTimes:      3,
+	})
+	if err != nil {
+		return fmt.Errorf("mirroring %s to %s: %w", source, destination, err)
+	}
+	c.Results.Mirrored = append(c.Results.Mirrored, MirroredReference{Source: source, Destination: destination})
+	return nil
+}
+
+// Mirror the cosign artifact tags of the image that exist and the direct OCI referrers of the image.
+func (c *Mirror) mirrorArtifacts(imageDigest string) error {
+	digestTag := strings.Replace(imageDigest, ":", "-", 1)
+	for _, suffix := range cosignArtifactTagSuffixes {
+		tag := digestTag + suffix
+		_, err := c.CliWrappers.SkopeoCli.Inspect(&cliwrappers.SkopeoInspectArgs{
+			ImageRef:          c.sourceRepository + ":" + tag,
+			Raw:               true,
+			TLSVerify:         &c.Params.TLSVerify,
+			CertDir:           c.Params.CertDir,
+			AuthFile:          c.srcAuthFile,
+			NoRetryIfNotFound: true,
+		})
+		if cliwrappers.IsImageNotFound(err) {
+			l.Logger.Debugf("No %s artifact tag", tag)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("inspecting artifact tag %s: %w", tag, err)
+		}
+		if err := c.copy(c.sourceRepository+":"+tag, c.destinationRepository+":"+tag); err != nil {
+			return err
+		}
+	}
+
+	subject := c.sourceRepository + "@" + imageDigest
+	referrers, err := c.CliWrappers.OrasCli.Discover(&cliwrappers.OrasDiscoverArgs{
+		Subject:        subject,
+		RegistryConfig: c.srcAuthFile,
+	})
+	if err != nil {
+		return fmt.Errorf("discovering referrers of %s: %w", subject, err)
+	}
+	for _, referrer := range referrers {
+		if err := c.copy(c.sourceRepository+"@"+referrer.Digest, c.destinationRepository+"@"+referrer.Digest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Mirror) validateParams() error {
+	if err := common.ValidateImageHasTagOrDigest(c.Params.Source); err != nil {
+		return fmt.Errorf("source image '%s' is invalid: %w", c.Params.Source, err)
+	}
+	c.sourceRepository = common.GetImageName(c.Params.Source)
+
+	destinationRef, err := common.ParseReference(c.Params.Destination)
+	if err != nil {
+		return fmt.Errorf("destination '%s' is invalid: %w", c.Params.Destination, err)
+	}
+	if destinationRef.Digest != "" {
+		return fmt.Errorf("destination '%s' must not have a digest, the digest of the source is kept", c.Params.Destination)
+	}
+	c.destinationRepository = common.GetImageName(c.Params.Destination)
+	c.destinationTag = destinationRef.Tag
+	if c.destinationTag == "" {
+		if sourceRef, err := common.ParseReference(c.Params.Source); err == nil {
+			c.destinationTag = sourceRef.Tag
+		}
+	}
+
+	if c.Params.Digest != "" && !common.IsImageDigestValid(c.Params.Digest) {
+		return fmt.Errorf("image digest '%s' is invalid", c.Params.Digest)
+	}
+
+	if c.Params.SrcAuthFile != "" {
+		if c.srcAuthFile, err = common.ResolveAuthFile(c.Params.SrcAuthFile); err != nil {
+			return err
+		}
+	}
+	if c.Params.DestAuthFile != "" {
+		if c.destAuthFile, err = common.ResolveAuthFile(c.Params.DestAuthFile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
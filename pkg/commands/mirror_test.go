@@ -0,0 +1,230 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_Mirror_validateParams(t *testing.T) {
+	authDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(authDir, ".dockerconfigjson"), []byte(`{"auths": {}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		params          MirrorParams
+		errExpected     string
+		wantRepository  string
+		wantTag         string
+		wantSrcAuthFile string
+	}{
+		{
+			name:           "should take the tag of the source",
+			params:         MirrorParams{Source: "quay.io/staging/app:v1", Destination: "quay.io/prod/app"},
+			wantRepository: "quay.io/prod/app",
+			wantTag:        "v1",
+		},
+		{
+			name:           "should use the tag of the destination",
+			params:         MirrorParams{Source: "quay.io/staging/app:v1", Destination: "quay.io/prod/app:stable"},
+			wantRepository: "quay.io/prod/app",
+			wantTag:        "stable",
+		},
+		{
+			name: "should push by digest if the source has no tag",
+			params: MirrorParams{
+				Source:      "quay.io/staging/app@sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c",
+				Destination: "quay.io/prod/app",
+			},
+			wantRepository: "quay.io/prod/app",
+		},
+		{
+			name:            "should resolve the auth directory",
+			params:          MirrorParams{Source: "quay.io/staging/app:v1", Destination: "quay.io/prod/app", SrcAuthFile: authDir},
+			wantRepository:  "quay.io/prod/app",
+			wantTag:         "v1",
+			wantSrcAuthFile: filepath.Join(authDir, ".dockerconfigjson"),
+		},
+		{
+			name:        "should fail if the source has no tag or digest",
+			params:      MirrorParams{Source: "quay.io/staging/app", Destination: "quay.io/prod/app"},
+			errExpected: "must have a tag or digest",
+		},
+		{
+			name: "should fail if the destination has a digest",
+			params: MirrorParams{
+				Source:      "quay.io/staging/app:v1",
+				Destination: "quay.io/prod/app@sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c",
+			},
+			errExpected: "must not have a digest",
+		},
+		{
+			name:        "should fail if the digest is invalid",
+			params:      MirrorParams{Source: "quay.io/staging/app:v1", Destination: "quay.io/prod/app", Digest: "sha256:123"},
+			errExpected: "image digest 'sha256:123' is invalid",
+		},
+		{
+			name:        "should fail if the auth file doesn't exist",
+			params:      MirrorParams{Source: "quay.io/staging/app:v1", Destination: "quay.io/prod/app", DestAuthFile: "/nonexistent"},
+			errExpected: "auth file /nonexistent",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &Mirror{Params: &tc.params}
+
+			err := c.validateParams()
+
+			if tc.errExpected != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.errExpected)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(c.destinationRepository).To(Equal(tc.wantRepository))
+			g.Expect(c.destinationTag).To(Equal(tc.wantTag))
+			g.Expect(c.srcAuthFile).To(Equal(tc.wantSrcAuthFile))
+		})
+	}
+}
+
+func Test_Mirror_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	const manifest = `{"schemaVersion": 2, "mediaType": "application/vnd.oci.image.index.v1+json", "manifests": []}`
+	imageDigest := digest.FromString(manifest).String()
+	digestTag := "sha256-" + imageDigest[len("sha256:"):]
+	const referrerDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+
+	var _mockSkopeoCli *mockSkopeoCli
+	var _mockOrasCli *mockOrasCli
+	var _mockResultsWriter *mockResultsWriter
+	var c *Mirror
+	var copied []*cliwrappers.SkopeoCopyArgs
+	beforeEach := func() {
+		copied = nil
+		_mockSkopeoCli = &mockSkopeoCli{
+			InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+				switch args.ImageRef {
+				case "quay.io/staging/app:v1":
+					return manifest, nil
+				case "quay.io/staging/app:" + digestTag + ".sig":
+					return "{}", nil
+				}
+				return "", errors.New("exit status 1: manifest unknown")
+			},
+			CopyAllFunc: func(args *cliwrappers.SkopeoCopyArgs) error {
+				copied = append(copied, args)
+				return nil
+			},
+		}
+		_mockOrasCli = &mockOrasCli{
+			DiscoverFunc: func(args *cliwrappers.OrasDiscoverArgs) ([]cliwrappers.OrasReferrer, error) {
+				g.Expect(args.Subject).To(Equal("quay.io/staging/app@" + imageDigest))
+				return []cliwrappers.OrasReferrer{{Digest: referrerDigest, ArtifactType: "application/spdx+json"}}, nil
+			},
+		}
+		_mockResultsWriter = &mockResultsWriter{}
+		c = &Mirror{
+			CliWrappers: MirrorCliWrappers{SkopeoCli: _mockSkopeoCli, OrasCli: _mockOrasCli},
+			Params: &MirrorParams{
+				Source:      "quay.io/staging/app:v1",
+				Destination: "registry.io/prod/app",
+				TLSVerify:   true,
+			},
+			ResultsWriter: _mockResultsWriter,
+		}
+	}
+
+	t.Run("should mirror image pinned to the digest", func(t *testing.T) {
+		beforeEach()
+		var results MirrorResults
+		_mockResultsWriter.CreateResultJsonFunc = func(result any) (string, error) {
+			results = result.(MirrorResults)
+			return "", nil
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(copied).To(HaveLen(1))
+		g.Expect(copied[0].SourceImage).To(Equal("quay.io/staging/app@" + imageDigest))
+		g.Expect(copied[0].DestinationImage).To(Equal("registry.io/prod/app:v1"))
+		g.Expect(copied[0].PreserveDigests).To(BeTrue())
+		g.Expect(results.ImageDigest).To(Equal(imageDigest))
+		g.Expect(results.ImageRef).To(Equal("registry.io/prod/app@" + imageDigest))
+		g.Expect(results.Mirrored).To(Equal([]MirroredReference{
+			{Source: "quay.io/staging/app@" + imageDigest, Destination: "registry.io/prod/app:v1"},
+		}))
+	})
+
+	t.Run("should mirror the artifacts of the image", func(t *testing.T) {
+		beforeEach()
+		c.Params.IncludeArtifacts = true
+		var results MirrorResults
+		_mockResultsWriter.CreateResultJsonFunc = func(result any) (string, error) {
+			results = result.(MirrorResults)
+			return "", nil
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(results.Mirrored).To(Equal([]MirroredReference{
+			{Source: "quay.io/staging/app@" + imageDigest, Destination: "registry.io/prod/app:v1"},
+			{Source: "quay.io/staging/app:" + digestTag + ".sig", Destination: "registry.io/prod/app:" + digestTag + ".sig"},
+			{Source: "quay.io/staging/app@" + referrerDigest, Destination: "registry.io/prod/app@" + referrerDigest},
+		}))
+	})
+
+	t.Run("should fail if the source points to another digest", func(t *testing.T) {
+		beforeEach()
+		c.Params.Digest = "sha256:806a5df5f70987524b87da868672ba1cec327b4d35eed01f71f2765177b7754c"
+
+		err := c.Run()
+
+		g.Expect(err).To(MatchError(ContainSubstring("expected sha256:806a5df5")))
+		g.Expect(copied).To(BeEmpty())
+	})
+
+	t.Run("should not inspect source given by digest", func(t *testing.T) {
+		beforeEach()
+		c.Params.Source = "quay.io/staging/app@" + imageDigest
+		_mockSkopeoCli.InspectFunc = func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+			return "", errors.New("unexpected inspect")
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(copied).To(HaveLen(1))
+		g.Expect(copied[0].DestinationImage).To(Equal("registry.io/prod/app@" + imageDigest))
+	})
+
+	t.Run("should pass the auth files", func(t *testing.T) {
+		beforeEach()
+		c.srcAuthFile = "/auth/src.json"
+		c.destAuthFile = "/auth/dest.json"
+
+		g.Expect(c.copy("quay.io/staging/app:v1", "registry.io/prod/app:v1")).To(Succeed())
+
+		g.Expect(copied[0].SourceAuthFile).To(Equal("/auth/src.json"))
+		g.Expect(copied[0].DestinationAuthFile).To(Equal("/auth/dest.json"))
+	})
+
+	t.Run("should fail if copy fails", func(t *testing.T) {
+		beforeEach()
+		_mockSkopeoCli.CopyAllFunc = func(args *cliwrappers.SkopeoCopyArgs) error {
+			return errors.New("unauthorized")
+		}
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("mirroring quay.io/staging/app@" + imageDigest)))
+	})
+}
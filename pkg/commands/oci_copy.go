@@ -0,0 +1,359 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+const (
+	ociCopyDefaultArtifactType = "application/vnd.konflux-ci.attached-artifact"
+	ociCopyDownloadAttempts    = 3
+)
+
+var sha256SumRegex = regexp.MustCompile("^[a-f0-9]{64}$")
+
+var OciCopyParamsConfig = map[string]common.Parameter{
+	"output-ref": {
+		Name:       "output-ref",
+		ShortName:  "t",
+		EnvVarName: "KBC_OCI_COPY_OUTPUT_REF",
+		TypeKind:   reflect.String,
+		Usage:      "The image reference to push the OCI artifact to, e.g. quay.io/org/app:v1.",
+		Required:   true,
+	},
+	"oci-copy-file": {
+		Name:         "oci-copy-file",
+		ShortName:    "f",
+		EnvVarName:   "KBC_OCI_COPY_OCI_COPY_FILE",
+		TypeKind:     reflect.String,
+		DefaultValue: "./oci-copy.yaml",
+		Usage:        "Path to the oci-copy.yaml file listing the artifacts to download.",
+	},
+	"download-dir": {
+		Name:       "download-dir",
+		EnvVarName: "KBC_OCI_COPY_DOWNLOAD_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Directory to download the artifacts to. A temporary directory is used if not set.",
+	},
+	"bearer-token-file": {
+		Name:       "bearer-token-file",
+		EnvVarName: "KBC_OCI_COPY_BEARER_TOKEN_FILE",
+		TypeKind:   reflect.String,
+		Usage:      "File containing a bearer token to send with the artifact download requests.",
+	},
+}
+
+type OciCopyParams struct {
+	OutputRef       string `paramName:"output-ref"`
+	OciCopyFile     string `paramName:"oci-copy-file"`
+	DownloadDir     string `paramName:"download-dir"`
+	BearerTokenFile string `paramName:"bearer-token-file"`
+}
+
+type OciCopyResults struct {
+	ImageUrl string `json:"image_url"`
+	Digest   string `json:"digest"`
+}
+
+type OciCopyCliWrappers struct {
+	OrasCli cliwrappers.OrasCliInterface
+}
+
+type OciCopy struct {
+	Params        *OciCopyParams
+	CliWrappers   OciCopyCliWrappers
+	Results       OciCopyResults
+	ResultsWriter common.ResultsWriterInterface
+
+	httpClient *http.Client
+	// Delay between download attempts, multiplied by the attempt number
+	retryDelay time.Duration
+}
+
+// The oci-copy.yaml file, same format as read by the oci-copy Tekton task.
+type ociCopyManifest struct {
+	ArtifactType string            `json:"oci-artifact-type"`
+	Artifacts    []ociCopyArtifact `json:"artifacts"`
+}
+
+type ociCopyArtifact struct {
+	Source    string `json:"source"`
+	Filename  string `json:"filename"`
+	Type      string `json:"type"`
+	Sha256Sum string `json:"sha256sum"`
+}
+
+func NewOciCopy(cmd *cobra.Command) (*OciCopy, error) {
+	params := &OciCopyParams{}
+	if err := common.ParseParameters(cmd, OciCopyParamsConfig, params); err != nil {
+		return nil, err
+	}
+	ociCopy := &OciCopy{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+		httpClient:    &http.Client{Timeout: 30 * time.Minute},
+		retryDelay:    5 * time.Second,
+	}
+	if err := ociCopy.initCliWrappers(); err != nil {
+		return nil, err
+	}
+	return ociCopy, nil
+}
+
+func (c *OciCopy) initCliWrappers() error {
+	executor := cliwrappers.NewCliExecutor()
+	orasCli, err := cliwrappers.NewOrasCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.OrasCli = orasCli
+	return nil
+}
+
+func (c *OciCopy) Run() error {
+	common.LogParameters(OciCopyParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	manifest, err := loadOciCopyManifest(c.Params.OciCopyFile)
+	if err != nil {
+		return err
+	}
+
+	downloadDir := c.Params.DownloadDir
+	if downloadDir == "" {
+		downloadDir, err = os.MkdirTemp("", "oci-copy-")
+		if err != nil {
+			return fmt.Errorf("error on creating temporary directory: %w", err)
+		}
+		defer func() {
+			if err := os.RemoveAll(downloadDir); err != nil {
+				l.Logger.Warnf("failed to remove '%s' directory: %s", downloadDir, err.Error())
+			}
+		}()
+	} else if err := os.MkdirAll(downloadDir, 0755); err != nil {
+		return fmt.Errorf("error on creating download directory %s: %w", downloadDir, err)
+	}
+
+	bearerToken := ""
+	if c.Params.BearerTokenFile != "" {
+		token, err := os.ReadFile(c.Params.BearerTokenFile)
+		if err != nil {
+			return fmt.Errorf("error on reading bearer token file: %w", err)
+		}
+		bearerToken = strings.TrimSpace(string(token))
+		l.RegisterSecret(bearerToken)
+	}
+
+	files := make([]cliwrappers.OrasPushFile, 0, len(manifest.Artifacts))
+	for _, artifact := range manifest.Artifacts {
+		if err := c.downloadArtifact(artifact, filepath.Join(downloadDir, artifact.Filename), bearerToken); err != nil {
+			return err
+		}
+		files = append(files, cliwrappers.OrasPushFile{Path: artifact.Filename, MediaType: artifact.Type})
+	}
+
+	registryConfig, err := common.WriteOrasRegistryConfig(c.Params.OutputRef)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(registryConfig); err != nil {
+			l.Logger.Warnf("failed to remove %s: %s", registryConfig, err.Error())
+		}
+	}()
+
+	// oras uses the file paths as layer titles, push from the download directory so that they
+	// are the plain file names
+	curDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error getting current directory: %w", err)
+	}
+	if err := os.Chdir(downloadDir); err != nil {
+		return fmt.Errorf("error on changing directory to %s: %w", downloadDir, err)
+	}
+	defer func() {
+		if err := os.Chdir(curDir); err != nil {
+			l.Logger.Warnf("failed to chdir to '%s' directory: %s", curDir, err.Error())
+		}
+	}()
+
+	artifactType := manifest.ArtifactType
+	if artifactType == "" {
+		artifactType = ociCopyDefaultArtifactType
+	}
+	stdout, _, err := c.CliWrappers.OrasCli.Push(&cliwrappers.OrasPushArgs{
+		ArtifactType:     artifactType,
+		RegistryConfig:   registryConfig,
+		Format:           "go-template",
+		Template:         "{{.reference}}",
+		DestinationImage: c.Params.OutputRef,
+		Files:            files,
+	})
+	if err != nil {
+		return fmt.Errorf("error on pushing artifacts to %s: %w", c.Params.OutputRef, err)
+	}
+
+	pushedRef := strings.TrimSpace(stdout)
+	digest := common.GetImageDigest(pushedRef)
+	if digest == "" {
+		return fmt.Errorf("failed to get digest of the pushed artifact from '%s'", pushedRef)
+	}
+	l.Logger.Infof("Pushed %d artifact(s) to %s", len(files), pushedRef)
+
+	c.Results.ImageUrl = c.Params.OutputRef
+	c.Results.Digest = digest
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	return nil
+}
+
+func (c *OciCopy) validateParams() error {
+	if !common.IsImageNameValid(common.GetImageName(c.Params.OutputRef)) {
+		return fmt.Errorf("output-ref '%s' is invalid", c.Params.OutputRef)
+	}
+	return nil
+}
+
+func loadOciCopyManifest(path string) (*ociCopyManifest, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // oci-copy file path from controlled input
+	if err != nil {
+		return nil, fmt.Errorf("error on reading %s: %w", path, err)
+	}
+	var manifest ociCopyManifest
+	if err := yaml.UnmarshalStrict(data, &manifest); err != nil {
+		return nil, fmt.Errorf("error on parsing %s: %w", path, err)
+	}
+	if err := manifest.validate(); err != nil {
+		return nil, fmt.Errorf("%s is invalid: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+func (m *ociCopyManifest) validate() error {
+	if len(m.Artifacts) == 0 {
+		return fmt.Errorf("no artifacts listed")
+	}
+	filenames := make(map[string]bool, len(m.Artifacts))
+	for i, artifact := range m.Artifacts {
+		sourceUrl, err := url.Parse(artifact.Source)
+		if err != nil || (sourceUrl.Scheme != "http" && sourceUrl.Scheme != "https") || sourceUrl.Host == "" {
+			return fmt.Errorf("artifact %d: source '%s' is not a http(s) URL", i, artifact.Source)
+		}
+		if artifact.Filename == "" || artifact.Filename == "." || artifact.Filename == ".." ||
+			strings.ContainsAny(artifact.Filename, "/:") {
+			return fmt.Errorf("artifact %d: filename '%s' is invalid", i, artifact.Filename)
+		}
+		if filenames[artifact.Filename] {
+			return fmt.Errorf("artifact %d: duplicate filename '%s'", i, artifact.Filename)
+		}
+		filenames[artifact.Filename] = true
+		if !sha256SumRegex.MatchString(artifact.Sha256Sum) {
+			return fmt.Errorf("artifact %d: sha256sum '%s' is invalid", i, artifact.Sha256Sum)
+		}
+	}
+	return nil
+}
+
+// errChecksumMismatch is not worth retrying, the source serves different content than declared.
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// Download the artifact to path and verify its checksum. An already downloaded file with the
+// expected checksum is reused.
+func (c *OciCopy) downloadArtifact(artifact ociCopyArtifact, path string, bearerToken string) error {
+	if sum, err := fileSha256Sum(path); err == nil && sum == artifact.Sha256Sum {
+		l.Logger.Infof("Reusing already downloaded %s", artifact.Filename)
+		return nil
+	}
+
+	var err error
+	for attempt := 1; attempt <= ociCopyDownloadAttempts; attempt++ {
+		l.Logger.Infof("Downloading %s to %s", artifact.Source, artifact.Filename)
+		err = c.download(artifact, path, bearerToken)
+		if err == nil || errors.Is(err, errChecksumMismatch) {
+			break
+		}
+		if attempt < ociCopyDownloadAttempts {
+			l.Logger.Warnf("Download of %s failed, retrying: %s", artifact.Source, err)
+			time.Sleep(time.Duration(attempt) * c.retryDelay)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error on downloading %s: %w", artifact.Source, err)
+	}
+	return nil
+}
+
+func (c *OciCopy) download(artifact ociCopyArtifact, path string, bearerToken string) error {
+	req, err := http.NewRequest(http.MethodGet, artifact.Source, nil)
+	if err != nil {
+		return err
+	}
+	if bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+	}
+	resp, err := c.httpClient.Do(req) //nolint:gosec // source URL from the oci-copy file
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	file, err := os.Create(path) //nolint:gosec // file name is validated
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, h), resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != artifact.Sha256Sum {
+		return fmt.Errorf("%w: expected sha256 %s, got %s", errChecksumMismatch, artifact.Sha256Sum, sum)
+	}
+	return nil
+}
+
+func fileSha256Sum(path string) (string, error) {
+	file, err := os.Open(path) //nolint:gosec // file name is validated
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
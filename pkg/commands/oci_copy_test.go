@@ -0,0 +1,296 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_OciCopy_Run(t *testing.T) {
+	const artifactDigest = "sha256:a7c0071906a9c6b654760e44a1fc8226f8268c70848148f19c35b02788b272a5"
+
+	contents := map[string]string{
+		"/disk.iso":  "iso content",
+		"/README.md": "readme content",
+	}
+	var requests []string
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		if r.URL.Path == "/flaky" && len(requests) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/flaky" {
+			fmt.Fprint(w, "iso content")
+			return
+		}
+		content, ok := contents[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+
+	sha := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
+	workDir := t.TempDir()
+	t.Setenv("HOME", workDir)
+	os.Mkdir(filepath.Join(workDir, ".docker"), 0755)
+	const authConfig = `{"auths":{"quay.io":{"auth":"token"}}}`
+	os.WriteFile(filepath.Join(workDir, ".docker", "config.json"), []byte(authConfig), 0644)
+
+	writeOciCopyFile := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "oci-copy.yaml")
+		os.WriteFile(path, []byte(content), 0644)
+		return path
+	}
+
+	var pushArgs *cliwrappers.OrasPushArgs
+	var pushedFiles map[string]string
+	newOciCopy := func(ociCopyFile, downloadDir string) (*OciCopy, *mockResultsWriter) {
+		pushArgs = nil
+		pushedFiles = map[string]string{}
+		requests = nil
+		authHeaders = nil
+		orasCli := &mockOrasCli{
+			PushFunc: func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+				pushArgs = args
+				for _, file := range args.Files {
+					content, err := os.ReadFile(file.Path)
+					if err != nil {
+						return "", "", err
+					}
+					pushedFiles[file.Path] = string(content)
+				}
+				authContent, _ := os.ReadFile(args.RegistryConfig)
+				if string(authContent) != authConfig {
+					return "", "", fmt.Errorf("unexpected registry config: %s", authContent)
+				}
+				return "quay.io/org/app@" + artifactDigest + "\n", "", nil
+			},
+		}
+		resultsWriter := &mockResultsWriter{}
+		return &OciCopy{
+			Params: &OciCopyParams{
+				OutputRef:   "quay.io/org/app:v1",
+				OciCopyFile: ociCopyFile,
+				DownloadDir: downloadDir,
+			},
+			CliWrappers:   OciCopyCliWrappers{OrasCli: orasCli},
+			ResultsWriter: resultsWriter,
+			httpClient:    server.Client(),
+		}, resultsWriter
+	}
+
+	t.Run("should download and push artifacts", func(t *testing.T) {
+		g := NewWithT(t)
+		ociCopyFile := writeOciCopyFile(t, fmt.Sprintf(`
+oci-artifact-type: application/vnd.test.artifact
+artifacts:
+  - source: %[1]s/disk.iso
+    filename: disk.iso
+    type: application/vnd.test.iso
+    sha256sum: %[2]s
+  - source: %[1]s/README.md
+    filename: README.md
+    type: text/markdown
+    sha256sum: %[3]s
+`, server.URL, sha("iso content"), sha("readme content")))
+		c, resultsWriter := newOciCopy(ociCopyFile, "")
+		var results OciCopyResults
+		resultsWriter.CreateResultJsonFunc = func(result any) (string, error) {
+			results = result.(OciCopyResults)
+			return "", nil
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(pushArgs.DestinationImage).To(Equal("quay.io/org/app:v1"))
+		g.Expect(pushArgs.ArtifactType).To(Equal("application/vnd.test.artifact"))
+		g.Expect(pushArgs.Files).To(Equal([]cliwrappers.OrasPushFile{
+			{Path: "disk.iso", MediaType: "application/vnd.test.iso"},
+			{Path: "README.md", MediaType: "text/markdown"},
+		}))
+		g.Expect(pushedFiles).To(Equal(map[string]string{"disk.iso": "iso content", "README.md": "readme content"}))
+		g.Expect(results).To(Equal(OciCopyResults{ImageUrl: "quay.io/org/app:v1", Digest: artifactDigest}))
+	})
+
+	t.Run("should use default artifact type and bearer token", func(t *testing.T) {
+		g := NewWithT(t)
+		ociCopyFile := writeOciCopyFile(t, fmt.Sprintf(`
+artifacts:
+  - source: %s/disk.iso
+    filename: disk.iso
+    type: application/vnd.test.iso
+    sha256sum: %s
+`, server.URL, sha("iso content")))
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		os.WriteFile(tokenFile, []byte("secret\n"), 0600)
+		c, _ := newOciCopy(ociCopyFile, "")
+		c.Params.BearerTokenFile = tokenFile
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(pushArgs.ArtifactType).To(Equal(ociCopyDefaultArtifactType))
+		g.Expect(authHeaders).To(Equal([]string{"Bearer secret"}))
+	})
+
+	t.Run("should retry failed downloads", func(t *testing.T) {
+		g := NewWithT(t)
+		ociCopyFile := writeOciCopyFile(t, fmt.Sprintf(`
+artifacts:
+  - source: %s/flaky
+    filename: disk.iso
+    type: application/vnd.test.iso
+    sha256sum: %s
+`, server.URL, sha("iso content")))
+		c, _ := newOciCopy(ociCopyFile, "")
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(requests).To(Equal([]string{"/flaky", "/flaky"}))
+		g.Expect(pushedFiles).To(Equal(map[string]string{"disk.iso": "iso content"}))
+	})
+
+	t.Run("should reuse already downloaded artifacts", func(t *testing.T) {
+		g := NewWithT(t)
+		downloadDir := t.TempDir()
+		os.WriteFile(filepath.Join(downloadDir, "disk.iso"), []byte("iso content"), 0644)
+		ociCopyFile := writeOciCopyFile(t, fmt.Sprintf(`
+artifacts:
+  - source: %s/disk.iso
+    filename: disk.iso
+    type: application/vnd.test.iso
+    sha256sum: %s
+`, server.URL, sha("iso content")))
+		c, _ := newOciCopy(ociCopyFile, downloadDir)
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(requests).To(BeEmpty())
+		g.Expect(pushedFiles).To(Equal(map[string]string{"disk.iso": "iso content"}))
+	})
+
+	t.Run("should fail on checksum mismatch without retrying", func(t *testing.T) {
+		g := NewWithT(t)
+		ociCopyFile := writeOciCopyFile(t, fmt.Sprintf(`
+artifacts:
+  - source: %s/disk.iso
+    filename: disk.iso
+    type: application/vnd.test.iso
+    sha256sum: %s
+`, server.URL, sha("other content")))
+		c, _ := newOciCopy(ociCopyFile, "")
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("checksum mismatch")))
+		g.Expect(requests).To(HaveLen(1))
+		g.Expect(pushArgs).To(BeNil())
+	})
+
+	t.Run("should fail if download keeps failing", func(t *testing.T) {
+		g := NewWithT(t)
+		ociCopyFile := writeOciCopyFile(t, fmt.Sprintf(`
+artifacts:
+  - source: %s/missing
+    filename: disk.iso
+    type: application/vnd.test.iso
+    sha256sum: %s
+`, server.URL, sha("iso content")))
+		c, _ := newOciCopy(ociCopyFile, "")
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("404 Not Found")))
+		g.Expect(requests).To(HaveLen(ociCopyDownloadAttempts))
+		g.Expect(pushArgs).To(BeNil())
+	})
+
+	t.Run("should fail if push fails", func(t *testing.T) {
+		g := NewWithT(t)
+		ociCopyFile := writeOciCopyFile(t, fmt.Sprintf(`
+artifacts:
+  - source: %s/disk.iso
+    filename: disk.iso
+    type: application/vnd.test.iso
+    sha256sum: %s
+`, server.URL, sha("iso content")))
+		c, _ := newOciCopy(ociCopyFile, "")
+		c.CliWrappers.OrasCli = &mockOrasCli{
+			PushFunc: func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+				return "", "", fmt.Errorf("unauthorized")
+			},
+		}
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("error on pushing artifacts to quay.io/org/app:v1")))
+	})
+}
+
+func Test_loadOciCopyManifest(t *testing.T) {
+	const sum = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	testCases := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{
+			name:          "no artifacts",
+			content:       "oci-artifact-type: application/vnd.test\n",
+			expectedError: "no artifacts listed",
+		},
+		{
+			name:          "unknown field",
+			content:       "artifacts: []\nunknown: true\n",
+			expectedError: "unknown field",
+		},
+		{
+			name:          "non-http source",
+			content:       fmt.Sprintf("artifacts:\n- source: file:///etc/passwd\n  filename: passwd\n  sha256sum: %s\n", sum),
+			expectedError: "is not a http(s) URL",
+		},
+		{
+			name:          "filename with path",
+			content:       fmt.Sprintf("artifacts:\n- source: https://example.com/a\n  filename: ../a\n  sha256sum: %s\n", sum),
+			expectedError: "filename '../a' is invalid",
+		},
+		{
+			name: "duplicate filename",
+			content: fmt.Sprintf("artifacts:\n- source: https://example.com/a\n  filename: a\n  sha256sum: %[1]s\n"+
+				"- source: https://example.com/b\n  filename: a\n  sha256sum: %[1]s\n", sum),
+			expectedError: "duplicate filename 'a'",
+		},
+		{
+			name:          "invalid checksum",
+			content:       "artifacts:\n- source: https://example.com/a\n  filename: a\n  sha256sum: abc\n",
+			expectedError: "sha256sum 'abc' is invalid",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			path := filepath.Join(t.TempDir(), "oci-copy.yaml")
+			g.Expect(os.WriteFile(path, []byte(tc.content), 0644)).To(Succeed())
+
+			_, err := loadOciCopyManifest(path)
+			g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+		})
+	}
+}
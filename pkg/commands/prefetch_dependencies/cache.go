@@ -0,0 +1,402 @@
+package prefetch_dependencies
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+)
+
+const (
+	// Bumped when the content of the cached outputs changes, so that the old entries aren't used
+	cacheFormatVersion = 1
+	// Name of the cache entries, followed by the cache key
+	cacheEntryPrefix  = "prefetch-"
+	cacheTarball      = "prefetch-output.tar.gz"
+	cacheArtifactType = "application/vnd.konflux.prefetch-cache"
+	cacheMediaType    = "application/vnd.konflux.prefetch-output.layer.v1.tar+gzip"
+)
+
+// Files that determine the dependencies Hermeto fetches for a package, by package manager type,
+// relative to the path of the package. The files that don't exist are skipped.
+var lockfilesByType = map[string][]string{
+	"bundler": {"Gemfile", "Gemfile.lock", ".bundle/config"},
+	"cargo":   {"Cargo.toml", "Cargo.lock", ".cargo/config.toml"},
+	"generic": {"artifacts.lock.yaml"},
+	"gomod":   {"go.mod", "go.sum", "go.work", "go.work.sum", "vendor/modules.txt"},
+	"npm":     {"package.json", "package-lock.json", "npm-shrinkwrap.json"},
+	"pip":     {"pyproject.toml", "setup.py", "setup.cfg"},
+	"pnpm":    {"package.json", "pnpm-lock.yaml", "pnpm-workspace.yaml"},
+	"rpm":     {"rpms.lock.yaml"},
+	"yarn":    {"package.json", "yarn.lock", ".yarnrc.yml", ".yarnrc"},
+}
+
+// Storage of the outputs of Hermeto runs, see --cache-dir and --cache-repository.
+type outputCache interface {
+	// Get the tarball of the output cached under the key, downloading it into the work directory
+	// if needed. Returns an empty path if there is none.
+	Fetch(key, workDir string) (string, error)
+	// Cache the tarball of an output under the key.
+	Store(key, tarballPath string) error
+}
+
+// Caches the outputs as tarballs in a directory.
+type dirCache struct {
+	dir string
+}
+
+func (c *dirCache) path(key string) string {
+	return filepath.Join(c.dir, cacheEntryPrefix+key+".tar.gz")
+}
+
+func (c *dirCache) Fetch(key, workDir string) (string, error) {
+	tarballPath := c.path(key)
+	if _, err := os.Stat(tarballPath); errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return tarballPath, nil
+}
+
+func (c *dirCache) Store(key, tarballPath string) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	tarball, err := os.Open(tarballPath) //nolint:gosec // tarball in the temporary workdir
+	if err != nil {
+		return err
+	}
+	defer tarball.Close()
+
+	// Written under a temporary name, concurrent runs never see a partial entry
+	entry, err := os.CreateTemp(c.dir, ".prefetch-*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, tarball)
+	if closeErr := entry.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(entry.Name(), c.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(entry.Name())
+	}
+	return err
+}
+
+// Caches the outputs as OCI artifacts tagged by the cache key in a repository.
+type registryCache struct {
+	repository string
+	orasCli    cliwrappers.OrasCliInterface
+}
+
+func (c *registryCache) reference(key string) string {
+	return c.repository + ":" + cacheEntryPrefix + key
+}
+
+func (c *registryCache) Fetch(key, workDir string) (string, error) {
+	registryConfig, err := common.WriteOrasRegistryConfig(c.repository)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(registryConfig) //nolint:errcheck
+
+	content, err := c.orasCli.ManifestFetch(&cliwrappers.OrasManifestFetchArgs{
+		Image:          c.reference(key),
+		RegistryConfig: registryConfig,
+	})
+	if cliwrappers.IsOrasNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("fetching manifest of %s: %w", c.reference(key), err)
+	}
+
+	var manifest specs.Manifest
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return "", fmt.Errorf("parsing manifest of %s: %w", c.reference(key), err)
+	}
+	if manifest.ArtifactType != cacheArtifactType || len(manifest.Layers) != 1 || manifest.Layers[0].MediaType != cacheMediaType {
+		return "", fmt.Errorf("%s is not a cached prefetch output", c.reference(key))
+	}
+	layer := manifest.Layers[0]
+	if err := layer.Digest.Validate(); err != nil {
+		return "", fmt.Errorf("layer digest '%s' is invalid: %w", layer.Digest, err)
+	}
+
+	tarballPath := filepath.Join(workDir, cacheTarball)
+	if err := c.orasCli.BlobFetch(&cliwrappers.OrasBlobFetchArgs{
+		Blob:           c.repository + "@" + layer.Digest.String(),
+		OutputPath:     tarballPath,
+		RegistryConfig: registryConfig,
+	}); err != nil {
+		return "", fmt.Errorf("downloading layer %s: %w", layer.Digest, err)
+	}
+	if err := verifyDigest(tarballPath, layer.Digest); err != nil {
+		return "", err
+	}
+	return tarballPath, nil
+}
+
+func (c *registryCache) Store(key, tarballPath string) error {
+	registryConfig, err := common.WriteOrasRegistryConfig(c.repository)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(registryConfig) //nolint:errcheck
+
+	_, _, err = c.orasCli.Push(&cliwrappers.OrasPushArgs{
+		DestinationImage:      c.reference(key),
+		Files:                 []cliwrappers.OrasPushFile{{Path: tarballPath, MediaType: cacheMediaType}},
+		ArtifactType:          cacheArtifactType,
+		RegistryConfig:        registryConfig,
+		DisablePathValidation: true,
+	})
+	return err
+}
+
+func verifyDigest(path string, expected digest.Digest) error {
+	file, err := os.Open(path) //nolint:gosec // blob in the temporary workdir
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	verifier := expected.Verifier()
+	if _, err := io.Copy(verifier, file); err != nil {
+		return err
+	}
+	if !verifier.Verified() {
+		return fmt.Errorf("%s doesn't match its digest %s", path, expected)
+	}
+	return nil
+}
+
+// The cache to use, nil if caching isn't enabled.
+func (pd *PrefetchDependencies) newOutputCache() outputCache {
+	switch {
+	case pd.Config.CacheDir != "":
+		return &dirCache{dir: pd.Config.CacheDir}
+	case pd.Config.CacheRepository != "":
+		return &registryCache{repository: pd.Config.CacheRepository, orasCli: pd.OrasCli}
+	}
+	return nil
+}
+
+// Everything the output of a Hermeto run depends on.
+type cacheKeyData struct {
+	Version int
+	// The input as given by the user: the RPM input injected later has the paths of the
+	// entitlement certificates, which change with every subscription-manager registration.
+	Input any
+	// The package manager types of the packages the run fetches
+	Types []string
+	// The paths of the files to modify with inject-files are absolute
+	SourceDir               string
+	ConfigFile              string
+	HermetoCommand          string
+	HermetoImage            string
+	Mode                    string
+	SBOMFormat              string
+	AllowDevPackageManagers bool
+	// The digests of the lockfiles by path, empty for the ones that don't exist
+	Lockfiles map[string]string
+}
+
+// Compute the cache key of a Hermeto run fetching the packages of the input. Returns an error if
+// the dependencies of a package aren't determined by its lockfiles.
+func (pd *PrefetchDependencies) cacheKey(input any) (string, error) {
+	sourceDir, err := filepath.Abs(pd.Config.SourceDir)
+	if err != nil {
+		return "", err
+	}
+	data := cacheKeyData{
+		Version:                 cacheFormatVersion,
+		Input:                   pd.cacheInput,
+		SourceDir:               sourceDir,
+		HermetoCommand:          pd.Config.HermetoCommand,
+		HermetoImage:            pd.Config.HermetoImage,
+		Mode:                    pd.Config.Mode,
+		SBOMFormat:              pd.Config.SBOMFormat,
+		AllowDevPackageManagers: pd.Config.AllowDevPackageManagers,
+		Lockfiles:               make(map[string]string),
+	}
+	if pd.Config.ConfigFile != "" {
+		if data.ConfigFile, err = fileDigest(pd.Config.ConfigFile); err != nil {
+			return "", err
+		}
+	}
+
+	for _, set := range splitInputByType(input) {
+		data.Types = append(data.Types, set.Type)
+		for _, pkg := range set.Input["packages"].([]any) {
+			lockfiles, err := packageLockfiles(pkg.(map[string]any))
+			if err != nil {
+				return "", err
+			}
+			for _, lockfile := range lockfiles {
+				if !filepath.IsAbs(lockfile) {
+					lockfile = filepath.Join(sourceDir, lockfile)
+				}
+				if data.Lockfiles[lockfile], err = fileDigest(lockfile); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return digest.FromBytes(encoded).Encoded(), nil
+}
+
+// The lockfiles of a package, relative to the source directory.
+func packageLockfiles(pkg map[string]any) ([]string, error) {
+	packageType, _ := pkg["type"].(string)
+	lockfiles, ok := lockfilesByType[packageType]
+	if !ok {
+		return nil, fmt.Errorf("the dependencies of %s packages are not determined by lockfiles", packageType)
+	}
+	lockfiles = append([]string{}, lockfiles...)
+
+	switch packageType {
+	case "pip":
+		requirementsFiles, ok := toStringSlice(pkg["requirements_files"])
+		if !ok {
+			requirementsFiles = []string{"requirements.txt"}
+		}
+		requirementsBuildFiles, ok := toStringSlice(pkg["requirements_build_files"])
+		if !ok {
+			requirementsBuildFiles = []string{"requirements-build.txt"}
+		}
+		lockfiles = append(lockfiles, requirementsFiles...)
+		lockfiles = append(lockfiles, requirementsBuildFiles...)
+	case "generic":
+		if lockfile, ok := pkg["lockfile"].(string); ok {
+			lockfiles = []string{lockfile}
+		}
+	}
+
+	packagePath, _ := pkg["path"].(string)
+	for i, lockfile := range lockfiles {
+		if !filepath.IsAbs(lockfile) {
+			lockfiles[i] = filepath.Join(packagePath, lockfile)
+		}
+	}
+	return lockfiles, nil
+}
+
+// The digest of the content of a file, empty if it doesn't exist.
+func fileDigest(path string) (string, error) {
+	file, err := os.Open(path) //nolint:gosec // lockfile of the source directory
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	fileDigest, err := digest.FromReader(file)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", path, err)
+	}
+	return fileDigest.String(), nil
+}
+
+// Restore the output of a Hermeto run fetching the packages of the input from the cache.
+// Returns the cache key to store the output under once fetched, empty if the run can't be cached.
+// Cache failures are only logged, the dependencies are fetched instead.
+func (pd *PrefetchDependencies) restoreFromCache(input any, outputDir string) (string, bool) {
+	key, err := pd.cacheKey(input)
+	if err != nil {
+		log.Warnf("Not caching the output of Hermeto: %s", err)
+		return "", false
+	}
+	if entries, err := os.ReadDir(outputDir); err == nil && len(entries) > 0 {
+		log.Warnf("Not caching the output of Hermeto: output directory %s is not empty", outputDir)
+		return "", false
+	}
+
+	workDir, err := os.MkdirTemp("", "prefetch-cache-")
+	if err != nil {
+		log.Warnf("Failed to create a temporary directory: %s", err)
+		return key, false
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			log.Warnf("Failed to remove %s: %s", workDir, err)
+		}
+	}()
+
+	tarballPath, err := pd.cache.Fetch(key, workDir)
+	if err != nil {
+		log.Warnf("Failed to look up the cached output of Hermeto %s: %s", key, err)
+		return key, false
+	}
+	if tarballPath == "" {
+		log.Infof("No cached output of Hermeto %s", key)
+		return key, false
+	}
+	if err := common.ExtractTarballFile(tarballPath, outputDir); err != nil {
+		log.Warnf("Failed to restore the cached output of Hermeto %s: %s", key, err)
+		if err := clearDir(outputDir); err != nil {
+			log.Warnf("Failed to clean up %s: %s", outputDir, err)
+		}
+		return key, false
+	}
+	log.Infof("Restored the cached output of Hermeto %s, not fetching the dependencies", key)
+	pd.cacheHits++
+	return key, true
+}
+
+// Store the output of a Hermeto run in the cache. Failures are only logged.
+func (pd *PrefetchDependencies) saveToCache(key, outputDir string) {
+	workDir, err := os.MkdirTemp("", "prefetch-cache-")
+	if err != nil {
+		log.Warnf("Failed to create a temporary directory: %s", err)
+		return
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			log.Warnf("Failed to remove %s: %s", workDir, err)
+		}
+	}()
+
+	tarballPath := filepath.Join(workDir, cacheTarball)
+	if _, err := common.WriteDirTarballFile(tarballPath, outputDir); err != nil {
+		log.Warnf("Failed to create a tarball of %s: %s", outputDir, err)
+		return
+	}
+	if err := pd.cache.Store(key, tarballPath); err != nil {
+		log.Warnf("Failed to cache the output of Hermeto %s: %s", key, err)
+		return
+	}
+	log.Infof("Cached the output of Hermeto %s", key)
+}
+
+// Remove the content of a directory.
+func clearDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
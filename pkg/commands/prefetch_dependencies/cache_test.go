@@ -0,0 +1,149 @@
+package prefetch_dependencies
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCacheKey(t *testing.T) {
+	setup := func(g *WithT, input string) (*PrefetchDependencies, string) {
+		sourceDir := t.TempDir()
+		g.Expect(os.MkdirAll(filepath.Join(sourceDir, "app"), 0755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(sourceDir, "app", "go.mod"), []byte("module app"), 0644)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(sourceDir, "app", "go.sum"), []byte("a v1"), 0644)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(sourceDir, "requirements.txt"), []byte("b==1"), 0644)).To(Succeed())
+		pd := &PrefetchDependencies{
+			Config:     &Params{SourceDir: sourceDir, Mode: "strict"},
+			cacheInput: parseInput(input),
+		}
+		return pd, sourceDir
+	}
+	const input = `{"packages": [{"type": "gomod", "path": "app"}, {"type": "pip", "requirements_files": ["requirements.txt"]}]}`
+
+	t.Run("should be stable", func(t *testing.T) {
+		g := NewWithT(t)
+		pd, _ := setup(g, input)
+
+		key, err := pd.cacheKey(pd.cacheInput)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(key).To(HaveLen(64))
+		g.Expect(pd.cacheKey(pd.cacheInput)).To(Equal(key))
+	})
+
+	t.Run("should change with the lockfiles", func(t *testing.T) {
+		g := NewWithT(t)
+		pd, sourceDir := setup(g, input)
+		key, err := pd.cacheKey(pd.cacheInput)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(os.WriteFile(filepath.Join(sourceDir, "app", "go.sum"), []byte("a v2"), 0644)).To(Succeed())
+		goKey, err := pd.cacheKey(pd.cacheInput)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(goKey).ToNot(Equal(key))
+
+		g.Expect(os.WriteFile(filepath.Join(sourceDir, "requirements.txt"), []byte("b==2"), 0644)).To(Succeed())
+		g.Expect(pd.cacheKey(pd.cacheInput)).ToNot(Equal(goKey))
+	})
+
+	t.Run("should not change with other files", func(t *testing.T) {
+		g := NewWithT(t)
+		pd, sourceDir := setup(g, input)
+		key, err := pd.cacheKey(pd.cacheInput)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(os.WriteFile(filepath.Join(sourceDir, "app", "main.go"), []byte("package main"), 0644)).To(Succeed())
+		g.Expect(pd.cacheKey(pd.cacheInput)).To(Equal(key))
+	})
+
+	t.Run("should change with the package set and the options", func(t *testing.T) {
+		g := NewWithT(t)
+		pd, _ := setup(g, input)
+		key, err := pd.cacheKey(pd.cacheInput)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(pd.cacheKey(parseInput(`{"packages": [{"type": "gomod", "path": "app"}]}`))).ToNot(Equal(key))
+
+		pd.Config.Mode = "permissive"
+		g.Expect(pd.cacheKey(pd.cacheInput)).ToNot(Equal(key))
+	})
+
+	t.Run("should fail for packages without lockfiles", func(t *testing.T) {
+		g := NewWithT(t)
+		pd, _ := setup(g, `{"packages": [{"type": "x-unknown"}]}`)
+
+		_, err := pd.cacheKey(pd.cacheInput)
+		g.Expect(err).To(MatchError(ContainSubstring("x-unknown packages are not determined by lockfiles")))
+	})
+}
+
+func TestPackageLockfiles(t *testing.T) {
+	g := NewWithT(t)
+
+	lockfiles, err := packageLockfiles(map[string]any{"type": "pip", "path": "py"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(lockfiles).To(ContainElements("py/pyproject.toml", "py/requirements.txt", "py/requirements-build.txt"))
+
+	lockfiles, err = packageLockfiles(map[string]any{"type": "generic", "lockfile": "/abs/artifacts.lock.yaml"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(lockfiles).To(Equal([]string{"/abs/artifacts.lock.yaml"}))
+}
+
+func TestFetchDepsCache(t *testing.T) {
+	setup := func(g *WithT) (*PrefetchDependencies, *fakeHermetoCli) {
+		sourceDir := t.TempDir()
+		g.Expect(os.WriteFile(filepath.Join(sourceDir, "go.sum"), []byte("a v1"), 0644)).To(Succeed())
+		hermetoCli := &fakeHermetoCli{}
+		pd := &PrefetchDependencies{
+			Config:     &Params{SourceDir: sourceDir, CacheDir: filepath.Join(t.TempDir(), "cache")},
+			HermetoCli: hermetoCli,
+		}
+		pd.cache = pd.newOutputCache()
+		pd.cacheInput = parseInput(`{"packages": [{"type": "gomod"}]}`)
+		return pd, hermetoCli
+	}
+
+	t.Run("should restore the cached output instead of fetching", func(t *testing.T) {
+		g := NewWithT(t)
+		pd, hermetoCli := setup(g)
+
+		outputDir := filepath.Join(t.TempDir(), "output")
+		g.Expect(pd.fetchDeps(pd.cacheInput, outputDir)).To(Succeed())
+		g.Expect(hermetoCli.fetchDepsInputs).To(HaveLen(1))
+		g.Expect(pd.cacheHits).To(Equal(0))
+
+		restoredDir := filepath.Join(t.TempDir(), "output")
+		g.Expect(pd.fetchDeps(pd.cacheInput, restoredDir)).To(Succeed())
+		g.Expect(hermetoCli.fetchDepsInputs).To(HaveLen(1))
+		g.Expect(pd.cacheHits).To(Equal(1))
+		g.Expect(os.ReadFile(filepath.Join(restoredDir, "bom.json"))).To(Equal([]byte("gomod")))
+		g.Expect(filepath.Join(restoredDir, "deps", "gomod")).To(BeADirectory())
+	})
+
+	t.Run("should fetch again when the lockfiles change", func(t *testing.T) {
+		g := NewWithT(t)
+		pd, hermetoCli := setup(g)
+
+		g.Expect(pd.fetchDeps(pd.cacheInput, filepath.Join(t.TempDir(), "output"))).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(pd.Config.SourceDir, "go.sum"), []byte("a v2"), 0644)).To(Succeed())
+		g.Expect(pd.fetchDeps(pd.cacheInput, filepath.Join(t.TempDir(), "output"))).To(Succeed())
+
+		g.Expect(hermetoCli.fetchDepsInputs).To(HaveLen(2))
+		g.Expect(pd.cacheHits).To(Equal(0))
+	})
+
+	t.Run("should not use the cache for a non-empty output directory", func(t *testing.T) {
+		g := NewWithT(t)
+		pd, hermetoCli := setup(g)
+		g.Expect(pd.fetchDeps(pd.cacheInput, filepath.Join(t.TempDir(), "output"))).To(Succeed())
+
+		outputDir := t.TempDir()
+		g.Expect(os.WriteFile(filepath.Join(outputDir, "leftover"), nil, 0644)).To(Succeed())
+		g.Expect(pd.fetchDeps(pd.cacheInput, outputDir)).To(Succeed())
+
+		g.Expect(hermetoCli.fetchDepsInputs).To(HaveLen(2))
+		g.Expect(pd.cacheHits).To(Equal(0))
+	})
+}
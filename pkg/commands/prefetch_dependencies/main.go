@@ -2,6 +2,7 @@ package prefetch_dependencies
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
 	cfg "github.com/konflux-ci/konflux-build-cli/pkg/config"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	"github.com/konflux-ci/konflux-build-cli/pkg/logger"
 
 	"github.com/spf13/cobra"
@@ -20,6 +22,16 @@ type PrefetchDependencies struct {
 	Config                 *Params
 	HermetoCli             cliwrappers.HermetoCliInterface
 	SubscriptionManagerCli cliwrappers.SubscriptionManagerCliInterface
+	// Set with --cache-repository
+	OrasCli       cliwrappers.OrasCliInterface
+	Results       Results
+	ResultsWriter common.ResultsWriterInterface
+
+	sideEffects *common.SideEffects
+	cache       outputCache
+	// The input before the RPM input is injected, for the cache keys
+	cacheInput any
+	cacheHits  int
 }
 
 func getPackageProxyConfiguration() ([]string, error) {
@@ -82,37 +94,110 @@ func New(cmd *cobra.Command) (*PrefetchDependencies, error) {
 			"on the pipeline level")
 	}
 
+	hermetoEnv = append(hermetoEnv, getGoPrivateEnv(local_config.GoPrivate, local_config.GoNoSumCheck)...)
+
+	// Passed explicitly to Hermeto, a Hermeto container doesn't inherit the environment
+	proxySettings := common.ResolveProxySettings(local_config.Proxy, local_config.NoProxy)
+	if proxySettings.IsSet() {
+		if err := proxySettings.Apply(); err != nil {
+			return nil, err
+		}
+		hermetoEnv = append(hermetoEnv, proxySettings.Env()...)
+	}
+
+	launcher, err := getHermetoLauncher(local_config.HermetoCommand, local_config.HermetoImage)
+	if err != nil {
+		return nil, kbcerrors.NewValidationError(err)
+	}
+
 	executor := cliwrappers.NewCliExecutor()
-	hermetoCli, err := cliwrappers.NewHermetoCli(executor, hermetoEnv)
+	hermetoCli, err := cliwrappers.NewHermetoCliWithLauncher(executor, hermetoEnv, launcher)
 	if err != nil {
 		return nil, err
 	}
 
-	prefetchDependencies := PrefetchDependencies{Config: &local_config, HermetoCli: hermetoCli}
+	prefetchDependencies := PrefetchDependencies{
+		Config:        &local_config,
+		HermetoCli:    hermetoCli,
+		ResultsWriter: common.NewResultsWriter(),
+	}
+	if local_config.CacheRepository != "" {
+		prefetchDependencies.OrasCli, err = cliwrappers.NewOrasCli(executor)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &prefetchDependencies, nil
 }
 
 func (pd *PrefetchDependencies) Run() error {
 	common.LogParameters(ParamsConfig, pd.Config)
 
+	if pd.Config.Undo {
+		return pd.undoSideEffects()
+	}
+
+	if pd.Config.SideEffectsFile != "" {
+		pd.sideEffects = common.NewSideEffects()
+		defer func() {
+			if err := pd.sideEffects.Save(pd.Config.SideEffectsFile); err != nil {
+				log.Warnf("Failed to save side effects: %v", err)
+			}
+		}()
+	}
+
 	if err := pd.HermetoCli.Version(); err != nil {
 		return fmt.Errorf("hermeto --version command failed: %w", err)
 	}
 
+	if pd.Config.InputFile != "" {
+		if pd.Config.Input != "" {
+			return kbcerrors.NewValidationError(errors.New("--input and --input-file are mutually exclusive"))
+		}
+		input, err := readInputFile(pd.Config.InputFile, os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read input file: %w", err)
+		}
+		pd.Config.Input = input
+	}
+
 	if pd.Config.Input == "" {
 		log.Warn("No input provided; skipping prefetch-dependencies")
 		return nil
 	}
 
-	if err := dropGoProxyFrom(pd.Config.ConfigFile); err != nil {
+	if pd.Config.CacheDir != "" && pd.Config.CacheRepository != "" {
+		return kbcerrors.NewValidationError(errors.New("--cache-dir and --cache-repository are mutually exclusive"))
+	}
+
+	// Validated before anything is changed outside of the output, e.g. the git credentials in HOME
+	decodedJSONInput := parseInput(pd.Config.Input)
+	if err := validateInput(decodedJSONInput, pd.Config.AllowDevPackageManagers); err != nil {
+		return kbcerrors.NewValidationError(fmt.Errorf("invalid input: %w", err))
+	}
+	if containsRPM(decodedJSONInput) {
+		if err := validateRPMLockfiles(decodedJSONInput, pd.Config.SourceDir, pd.Config.LockfileRefreshCheck); err != nil {
+			return kbcerrors.NewValidationError(err)
+		}
+	}
+	pd.cacheInput = decodedJSONInput
+
+	pd.cache = pd.newOutputCache()
+
+	if err := dropGoProxyFrom(pd.Config.ConfigFile, pd.sideEffects); err != nil {
 		return fmt.Errorf("failed to drop Go proxy from config file: %w", err)
 	}
 
-	if err := setupGitBasicAuth(pd.Config.GitAuthDirectory, pd.Config.SourceDir); err != nil {
+	if err := setupGitBasicAuth(pd.Config.GitAuthDirectory, pd.Config.SourceDir, pd.sideEffects); err != nil {
 		return fmt.Errorf("failed to setup Git authentication: %w", err)
 	}
 
-	decodedJSONInput := parseInput(pd.Config.Input)
+	if pd.Config.GoPrivate != "" {
+		if err := setupGoPrivateAuth(pd.Config.GoPrivate, pd.sideEffects); err != nil {
+			return fmt.Errorf("failed to setup authentication for private Go modules: %w", err)
+		}
+	}
+
 	if containsRPM(decodedJSONInput) {
 		registerRHSM := pd.Config.RHSMOrg != "" && pd.Config.RHSMActivationKey != ""
 		if registerRHSM {
@@ -120,16 +205,73 @@ func (pd *PrefetchDependencies) Run() error {
 				return fmt.Errorf("failed to register with subscription-manager: %w", err)
 			}
 			defer pd.unregisterRHSM()
+			if err := pd.configureRHSM(); err != nil {
+				return fmt.Errorf("failed to configure subscription-manager: %w", err)
+			}
 		}
 
-		modifiedInput, err := injectRPMInput(decodedJSONInput, registerRHSM)
+		modifiedInput, err := injectRPMInput(decodedJSONInput, registerRHSM, pd.Config.EntitlementDir, pd.Config.EntitlementRepoIDs)
 		if err != nil {
 			return fmt.Errorf("failed to inject RPM input: %w", err)
 		}
 		decodedJSONInput = modifiedInput
 	}
 
-	encodedJSONInput, err := json.Marshal(decodedJSONInput)
+	if pd.Config.SplitByType {
+		if err := pd.fetchDepsByType(decodedJSONInput); err != nil {
+			return err
+		}
+	} else {
+		if err := pd.fetchDeps(decodedJSONInput, pd.Config.OutputDir); err != nil {
+			return err
+		}
+		if err := pd.generateEnv(pd.Config.OutputDir, pd.Config.EnvFiles); err != nil {
+			return err
+		}
+		if err := pd.injectFiles(pd.Config.OutputDir); err != nil {
+			return err
+		}
+	}
+
+	if err := renameRepoFiles(pd.Config.OutputDir, pd.sideEffects); err != nil {
+		return fmt.Errorf("failed to rename hermeto.repo files: %w", err)
+	}
+
+	results, err := collectResults(pd.Config.OutputDir, pd.Config.EnvFiles)
+	if err != nil {
+		return fmt.Errorf("failed to collect the results: %w", err)
+	}
+	pd.Results = *results
+	pd.Results.CacheHits = pd.cacheHits
+
+	if pd.Config.SBOMOutputPath != "" {
+		pd.Results.SBOMPaths, err = writeSBOMFormats(pd.Results.SBOMPath, pd.Config.SBOMOutputPath)
+		if err != nil {
+			return fmt.Errorf("failed to write the SBOM formats: %w", err)
+		}
+	}
+	log.Infof("Prefetched %d packages, %d bytes", pd.Results.TotalPackages, pd.Results.DownloadSizeBytes)
+
+	if resultJson, err := pd.ResultsWriter.CreateResultJson(pd.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		log.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (pd *PrefetchDependencies) fetchDeps(input any, outputDir string) error {
+	var cacheKey string
+	if pd.cache != nil {
+		var restored bool
+		if cacheKey, restored = pd.restoreFromCache(input, outputDir); restored {
+			return nil
+		}
+	}
+
+	encodedJSONInput, err := json.Marshal(input)
 	if err != nil {
 		return err
 	}
@@ -137,20 +279,28 @@ func (pd *PrefetchDependencies) Run() error {
 	log.Debugf("Using modified input for Hermeto:\n%s", string(encodedJSONInput))
 
 	fetchDepsParams := cliwrappers.HermetoFetchDepsParams{
-		SourceDir:  pd.Config.SourceDir,
-		OutputDir:  pd.Config.OutputDir,
-		Input:      string(encodedJSONInput),
-		ConfigFile: pd.Config.ConfigFile,
-		SBOMFormat: pd.Config.SBOMFormat,
-		Mode:       pd.Config.Mode,
+		SourceDir:          pd.Config.SourceDir,
+		OutputDir:          outputDir,
+		Input:              string(encodedJSONInput),
+		ConfigFile:         pd.Config.ConfigFile,
+		SBOMFormat:         pd.Config.SBOMFormat,
+		Mode:               pd.Config.Mode,
+		DevPackageManagers: pd.Config.AllowDevPackageManagers,
 	}
 	if err := pd.HermetoCli.FetchDeps(&fetchDepsParams); err != nil {
 		return fmt.Errorf("hermeto fetch-deps command failed: %w", err)
 	}
 
-	for _, envFile := range pd.Config.EnvFiles {
+	if cacheKey != "" {
+		pd.saveToCache(cacheKey, outputDir)
+	}
+	return nil
+}
+
+func (pd *PrefetchDependencies) generateEnv(outputDir string, envFiles []string) error {
+	for _, envFile := range envFiles {
 		generateEnvParams := cliwrappers.HermetoGenerateEnvParams{
-			OutputDir:    pd.Config.OutputDir,
+			OutputDir:    outputDir,
 			ForOutputDir: pd.Config.OutputDirMountPoint,
 			Output:       envFile,
 		}
@@ -158,19 +308,33 @@ func (pd *PrefetchDependencies) Run() error {
 			return fmt.Errorf("hermeto generate-env command failed: %w", err)
 		}
 	}
+	return nil
+}
 
+func (pd *PrefetchDependencies) injectFiles(outputDir string) error {
 	injectFilesParams := cliwrappers.HermetoInjectFilesParams{
-		OutputDir:    pd.Config.OutputDir,
+		OutputDir:    outputDir,
 		ForOutputDir: pd.Config.OutputDirMountPoint,
 	}
 	if err := pd.HermetoCli.InjectFiles(&injectFilesParams); err != nil {
 		return fmt.Errorf("hermeto inject-files command failed: %w", err)
 	}
+	return nil
+}
 
-	if err := renameRepoFiles(pd.Config.OutputDir); err != nil {
-		return fmt.Errorf("failed to rename hermeto.repo files: %w", err)
+// Revert the file system changes recorded by a previous run.
+func (pd *PrefetchDependencies) undoSideEffects() error {
+	if pd.Config.SideEffectsFile == "" {
+		return kbcerrors.NewValidationError(errors.New("--undo requires --side-effects-file"))
 	}
-
+	sideEffects, err := common.LoadSideEffects(pd.Config.SideEffectsFile)
+	if err != nil {
+		return fmt.Errorf("failed to load side effects: %w", err)
+	}
+	if err := sideEffects.Undo(); err != nil {
+		return fmt.Errorf("failed to undo some side effects: %w", err)
+	}
+	log.Infof("Reverted %d side effect(s) recorded in %s", len(sideEffects.Entries), pd.Config.SideEffectsFile)
 	return nil
 }
 
@@ -196,6 +360,23 @@ func (pd *PrefetchDependencies) registerRHSM() error {
 	return pd.SubscriptionManagerCli.Register(params)
 }
 
+// Set the release and enable the repositories of the registered system, so that only the needed
+// repositories are visible to hermeto.
+func (pd *PrefetchDependencies) configureRHSM() error {
+	// The release determines the content of the repositories, set it first
+	if pd.Config.RHSMRelease != "" {
+		if err := pd.SubscriptionManagerCli.SetRelease(pd.Config.RHSMRelease); err != nil {
+			return fmt.Errorf("failed to set release: %w", err)
+		}
+	}
+	if len(pd.Config.RHSMEnableRepos) > 0 {
+		if err := pd.SubscriptionManagerCli.EnableRepos(pd.Config.RHSMEnableRepos); err != nil {
+			return fmt.Errorf("failed to enable repositories: %w", err)
+		}
+	}
+	return nil
+}
+
 func (pd *PrefetchDependencies) unregisterRHSM() {
 	if err := pd.initSubscriptionManager(); err != nil {
 		log.Warnf("Couldn't unregister with subscription-manager: %s", err)
@@ -15,6 +15,14 @@ var ParamsConfig = map[string]common.Parameter{
 		Usage:        "input data specifying package managers and various configuration",
 		Required:     false,
 	},
+	"input-file": {
+		Name:         "input-file",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_INPUT_FILE",
+		DefaultValue: "",
+		Usage:        "path to a JSON or YAML file with the input data, '-' to read it from stdin (mutually exclusive with --input)",
+		Required:     false,
+	},
 	"source-dir": {
 		Name:         "source-dir",
 		TypeKind:     reflect.String,
@@ -47,6 +55,14 @@ var ParamsConfig = map[string]common.Parameter{
 		Usage:        "SBOM format to generate (spdx or cyclonedx)",
 		Required:     false,
 	},
+	"sbom-output-path": {
+		Name:         "sbom-output-path",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_SBOM_OUTPUT_PATH",
+		DefaultValue: "",
+		Usage:        "directory to write the SBOM into in both formats, as bom.spdx.json and bom.cyclonedx.json, for merging with SBOMs of either format",
+		Required:     false,
+	},
 	"mode": {
 		Name:         "mode",
 		TypeKind:     reflect.String,
@@ -55,6 +71,31 @@ var ParamsConfig = map[string]common.Parameter{
 		Usage:        "how to handle input requirements: strict (fail) or permissive (warn)",
 		Required:     false,
 	},
+	"allow-dev-package-managers": {
+		Name:         "allow-dev-package-managers",
+		TypeKind:     reflect.Bool,
+		EnvVarName:   "KBC_PD_ALLOW_DEV_PACKAGE_MANAGERS",
+		DefaultValue: "false",
+		Usage:        "allow the package managers that are still in development in Hermeto (x-* types)",
+		Required:     false,
+	},
+	"split-by-type": {
+		Name:         "split-by-type",
+		TypeKind:     reflect.Bool,
+		EnvVarName:   "KBC_PD_SPLIT_BY_TYPE",
+		DefaultValue: "false",
+		Usage:        "run Hermeto fetch-deps once per package manager type and merge the prefetched dependencies, env files and SBOMs",
+		Required:     false,
+	},
+	"lockfile-refresh-check": {
+		Name:         "lockfile-refresh-check",
+		TypeKind:     reflect.Bool,
+		EnvVarName:   "KBC_PD_LOCKFILE_REFRESH_CHECK",
+		DefaultValue: "false",
+		Usage: "fail if an rpms.lock.yaml drifted from its rpms.in.yaml: a package of rpms.in.yaml is not locked, " +
+			"or a package URL is not in the baseurl of its repo anymore",
+		Required: false,
+	},
 	"output-dir-mount-point": {
 		Name:         "output-dir-mount-point",
 		TypeKind:     reflect.String,
@@ -87,12 +128,92 @@ var ParamsConfig = map[string]common.Parameter{
 		Usage:        "path to file containing Red Hat Subscription Manager activation key",
 		Required:     false,
 	},
+	"rhsm-enable-repos": {
+		Name:         "rhsm-enable-repos",
+		TypeKind:     reflect.Slice,
+		EnvVarName:   "KBC_PD_RHSM_ENABLE_REPOS",
+		DefaultValue: "",
+		Usage:        "ids of the Red Hat Subscription Manager repositories to enable after registration, all the other repositories are disabled",
+		Required:     false,
+	},
+	"rhsm-release": {
+		Name:         "rhsm-release",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_RHSM_RELEASE",
+		DefaultValue: "",
+		Usage:        "release to set after registration with Red Hat Subscription Manager, e.g. 9.4",
+		Required:     false,
+	},
+	"entitlement-dir": {
+		Name:         "entitlement-dir",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_ENTITLEMENT_DIR",
+		DefaultValue: "/etc/pki/entitlement",
+		Usage:        "directory where subscription-manager writes the entitlement certificates (<serial>.pem and <serial>-key.pem)",
+		Required:     false,
+	},
+	"entitlement-repo-ids": {
+		Name:         "entitlement-repo-ids",
+		TypeKind:     reflect.Slice,
+		EnvVarName:   "KBC_PD_ENTITLEMENT_REPO_IDS",
+		DefaultValue: "",
+		Usage:        "ids of the RPM repositories that require the entitlement, the SSL options are set for these repositories only instead of for all of them",
+		Required:     false,
+	},
 	"git-auth-directory": {
 		Name:         "git-auth-directory",
 		TypeKind:     reflect.String,
 		EnvVarName:   "KBC_PD_GIT_AUTH_DIRECTORY",
 		DefaultValue: "",
-		Usage:        "directory with git auth credentials (.git-credentials, .gitconfig or username/password)",
+		Usage:        "directory with git auth credentials (.git-credentials, .gitconfig or username/password, or id_rsa with an optional known_hosts for SSH)",
+		Required:     false,
+	},
+	"go-private": {
+		Name:         "go-private",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_GO_PRIVATE",
+		DefaultValue: "",
+		Usage:        "comma-separated glob patterns of private Go module paths (GOPRIVATE), fetched directly with the git auth credentials",
+		Required:     false,
+	},
+	"go-nosumcheck": {
+		Name:         "go-nosumcheck",
+		TypeKind:     reflect.Bool,
+		EnvVarName:   "KBC_PD_GO_NOSUMCHECK",
+		DefaultValue: "false",
+		Usage:        "disable checksum database verification for all Go modules (GONOSUMCHECK=1, GONOSUMDB=*), e.g. when the checksum database is not reachable",
+		Required:     false,
+	},
+	"side-effects-file": {
+		Name:         "side-effects-file",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_SIDE_EFFECTS_FILE",
+		DefaultValue: "",
+		Usage:        "path to a JSON file (e.g. side_effects.json) where to record files written or renamed outside the output directory",
+		Required:     false,
+	},
+	"undo": {
+		Name:         "undo",
+		TypeKind:     reflect.Bool,
+		EnvVarName:   "KBC_PD_UNDO",
+		DefaultValue: "false",
+		Usage:        "revert the side effects recorded in --side-effects-file by a previous run instead of prefetching",
+		Required:     false,
+	},
+	"hermeto-command": {
+		Name:         "hermeto-command",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_HERMETO_COMMAND",
+		DefaultValue: "",
+		Usage:        "command that runs Hermeto, e.g. /opt/venv/bin/hermeto or 'python3 -m hermeto' (defaults to hermeto from PATH)",
+		Required:     false,
+	},
+	"hermeto-image": {
+		Name:         "hermeto-image",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_HERMETO_IMAGE",
+		DefaultValue: "",
+		Usage:        "run Hermeto in a container from this image using podman, for environments without Hermeto installed (mutually exclusive with --hermeto-command)",
 		Required:     false,
 	},
 	"enable-package-registry-proxy": { // Pipeline-level registry proxy switch.
@@ -103,19 +224,71 @@ var ParamsConfig = map[string]common.Parameter{
 		DefaultValue: "true", // A pipeline will use a proxy unless explicitly told otherwise.
 		Required:     false,
 	},
+	"proxy": {
+		Name:         "proxy",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_PROXY",
+		DefaultValue: "",
+		Usage:        "HTTP(S) proxy for Hermeto, set as HTTP_PROXY and HTTPS_PROXY (defaults to the HTTPS_PROXY or HTTP_PROXY environment variable)",
+		Required:     false,
+	},
+	"no-proxy": {
+		Name:         "no-proxy",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_NO_PROXY",
+		DefaultValue: "",
+		Usage:        "hosts to access without the --proxy, set as NO_PROXY (defaults to the NO_PROXY environment variable)",
+		Required:     false,
+	},
+	"cache-dir": {
+		Name:         "cache-dir",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_CACHE_DIR",
+		DefaultValue: "",
+		Usage: "directory to cache the Hermeto outputs in, keyed by the hashes of the lockfiles of the packages; " +
+			"Hermeto doesn't run again when the lockfiles didn't change",
+		Required: false,
+	},
+	"cache-repository": {
+		Name:         "cache-repository",
+		TypeKind:     reflect.String,
+		EnvVarName:   "KBC_PD_CACHE_REPOSITORY",
+		DefaultValue: "",
+		Usage:        "repository to cache the Hermeto outputs in as OCI artifacts, like --cache-dir",
+		Required:     false,
+	},
 }
 
 type Params struct {
 	Input                      string   `paramName:"input"`
+	InputFile                  string   `paramName:"input-file"`
 	SourceDir                  string   `paramName:"source-dir"`
 	OutputDir                  string   `paramName:"output-dir"`
 	ConfigFile                 string   `paramName:"config-file"`
 	SBOMFormat                 string   `paramName:"sbom-format"`
+	SBOMOutputPath             string   `paramName:"sbom-output-path"`
 	Mode                       string   `paramName:"mode"`
+	AllowDevPackageManagers    bool     `paramName:"allow-dev-package-managers"`
+	SplitByType                bool     `paramName:"split-by-type"`
+	LockfileRefreshCheck       bool     `paramName:"lockfile-refresh-check"`
 	OutputDirMountPoint        string   `paramName:"output-dir-mount-point"`
 	EnvFiles                   []string `paramName:"env-files"`
 	RHSMOrg                    string   `paramName:"rhsm-org"`
 	RHSMActivationKey          string   `paramName:"rhsm-activation-key"`
+	RHSMEnableRepos            []string `paramName:"rhsm-enable-repos"`
+	RHSMRelease                string   `paramName:"rhsm-release"`
+	EntitlementDir             string   `paramName:"entitlement-dir"`
+	EntitlementRepoIDs         []string `paramName:"entitlement-repo-ids"`
 	GitAuthDirectory           string   `paramName:"git-auth-directory"`
+	GoPrivate                  string   `paramName:"go-private"`
+	GoNoSumCheck               bool     `paramName:"go-nosumcheck"`
+	SideEffectsFile            string   `paramName:"side-effects-file"`
+	Undo                       bool     `paramName:"undo"`
+	HermetoCommand             string   `paramName:"hermeto-command"`
+	HermetoImage               string   `paramName:"hermeto-image"`
 	EnablePackageRegistryProxy bool     `paramName:"enable-package-registry-proxy"`
+	Proxy                      string   `paramName:"proxy"`
+	NoProxy                    string   `paramName:"no-proxy"`
+	CacheDir                   string   `paramName:"cache-dir"`
+	CacheRepository            string   `paramName:"cache-repository"`
 }
@@ -0,0 +1,120 @@
+package prefetch_dependencies
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Results struct {
+	// The SBOM of the prefetched dependencies written by Hermeto
+	SBOMPath string `json:"sbom_path"`
+	// The SBOM in both formats by format (spdx, cyclonedx), see --sbom-output-path
+	SBOMPaths map[string]string `json:"sbom_paths,omitempty"`
+	EnvFiles  []string          `json:"env_files"`
+	// Number of packages in the SBOM by purl type, e.g. golang, npm, pypi, rpm
+	PackagesByType map[string]int `json:"packages_by_type"`
+	TotalPackages  int            `json:"total_packages"`
+	// Total size of the files in the deps directory of the output directory
+	DownloadSizeBytes int64 `json:"download_size_bytes"`
+	// Number of Hermeto runs whose output was restored from --cache-dir or --cache-repository
+	CacheHits int `json:"cache_hits,omitempty"`
+}
+
+// Collect the results of a prefetch from the output directory Hermeto wrote.
+func collectResults(outputDir string, envFiles []string) (*Results, error) {
+	results := &Results{
+		SBOMPath: filepath.Join(outputDir, "bom.json"),
+		EnvFiles: envFiles,
+	}
+	if results.EnvFiles == nil {
+		results.EnvFiles = []string{}
+	}
+
+	purls, err := readSBOMPurls(results.SBOMPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading SBOM %s: %w", results.SBOMPath, err)
+	}
+	results.PackagesByType = countPackagesByType(purls)
+	results.TotalPackages = len(purls)
+
+	results.DownloadSizeBytes, err = dirSize(filepath.Join(outputDir, "deps"))
+	if err != nil {
+		return nil, fmt.Errorf("measuring downloaded dependencies: %w", err)
+	}
+	return results, nil
+}
+
+// Read the purls of the packages of a CycloneDX or SPDX SBOM.
+func readSBOMPurls(sbomPath string) ([]string, error) {
+	content, err := os.ReadFile(sbomPath) //nolint:gosec // SBOM generated in the output directory
+	if err != nil {
+		return nil, err
+	}
+	var sbom struct {
+		Components []struct {
+			Purl string `json:"purl"`
+		} `json:"components"`
+		Packages []struct {
+			ExternalRefs []struct {
+				ReferenceType    string `json:"referenceType"`
+				ReferenceLocator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(content, &sbom); err != nil {
+		return nil, err
+	}
+
+	purls := []string{}
+	for _, component := range sbom.Components {
+		if component.Purl != "" {
+			purls = append(purls, component.Purl)
+		}
+	}
+	for _, pkg := range sbom.Packages {
+		for _, ref := range pkg.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				purls = append(purls, ref.ReferenceLocator)
+				break
+			}
+		}
+	}
+	return purls, nil
+}
+
+// Count the packages by the type of their purl, pkg:<type>/<namespace>/<name>@<version>.
+func countPackagesByType(purls []string) map[string]int {
+	counts := map[string]int{}
+	for _, purl := range purls {
+		purlType, _, _ := strings.Cut(strings.TrimPrefix(purl, "pkg:"), "/")
+		counts[purlType]++
+	}
+	return counts
+}
+
+// The total size of the regular files in the directory, zero if it doesn't exist.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	return size, err
+}
@@ -0,0 +1,77 @@
+package prefetch_dependencies
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCollectResults(t *testing.T) {
+	writeOutput := func(g *WithT, sbom string) string {
+		outputDir := t.TempDir()
+		g.Expect(os.WriteFile(filepath.Join(outputDir, "bom.json"), []byte(sbom), 0644)).To(Succeed())
+		g.Expect(os.MkdirAll(filepath.Join(outputDir, "deps", "gomod", "cache"), 0755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(outputDir, "deps", "gomod", "cache", "a.zip"), make([]byte, 100), 0644)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(outputDir, "deps", "gomod", "b.zip"), make([]byte, 23), 0644)).To(Succeed())
+		return outputDir
+	}
+
+	t.Run("should count the packages of a CycloneDX SBOM", func(t *testing.T) {
+		g := NewWithT(t)
+		outputDir := writeOutput(g, `{"components": [
+			{"name": "a", "purl": "pkg:golang/github.com/a/a@v1.0.0"},
+			{"name": "b", "purl": "pkg:golang/github.com/b/b@v1.0.0"},
+			{"name": "c", "purl": "pkg:npm/c@1.0.0"},
+			{"name": "no-purl"}
+		]}`)
+
+		results, err := collectResults(outputDir, []string{"/tmp/prefetch.env"})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results.SBOMPath).To(Equal(filepath.Join(outputDir, "bom.json")))
+		g.Expect(results.EnvFiles).To(Equal([]string{"/tmp/prefetch.env"}))
+		g.Expect(results.PackagesByType).To(Equal(map[string]int{"golang": 2, "npm": 1}))
+		g.Expect(results.TotalPackages).To(Equal(3))
+		g.Expect(results.DownloadSizeBytes).To(Equal(int64(123)))
+	})
+
+	t.Run("should count the packages of an SPDX SBOM", func(t *testing.T) {
+		g := NewWithT(t)
+		outputDir := writeOutput(g, `{"packages": [
+			{"name": "a", "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:rpm/redhat/a@1.0"}]},
+			{"name": "b", "externalRefs": [{"referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a"},
+				{"referenceType": "purl", "referenceLocator": "pkg:pypi/b@2.0"}]},
+			{"name": "root"}
+		]}`)
+
+		results, err := collectResults(outputDir, nil)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results.EnvFiles).To(BeEmpty())
+		g.Expect(results.PackagesByType).To(Equal(map[string]int{"rpm": 1, "pypi": 1}))
+		g.Expect(results.TotalPackages).To(Equal(2))
+	})
+
+	t.Run("should report no download size without deps", func(t *testing.T) {
+		g := NewWithT(t)
+		outputDir := t.TempDir()
+		g.Expect(os.WriteFile(filepath.Join(outputDir, "bom.json"), []byte(`{"components": []}`), 0644)).To(Succeed())
+
+		results, err := collectResults(outputDir, nil)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(results.DownloadSizeBytes).To(BeZero())
+		g.Expect(results.PackagesByType).To(BeEmpty())
+	})
+
+	t.Run("should fail if the SBOM is invalid", func(t *testing.T) {
+		g := NewWithT(t)
+		outputDir := writeOutput(g, "not json")
+
+		_, err := collectResults(outputDir, nil)
+
+		g.Expect(err).To(MatchError(ContainSubstring("reading SBOM")))
+	})
+}
@@ -0,0 +1,342 @@
+package prefetch_dependencies
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/ini.v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// The lockfile Hermeto reads in the directory of an rpm package
+	rpmLockfileName = "rpms.lock.yaml"
+	// The input of rpm-lockfile-prototype the lockfile is generated from, optional
+	rpmInputFileName = "rpms.in.yaml"
+)
+
+// The rpms.lock.yaml format read by Hermeto.
+type rpmLockfile struct {
+	LockfileVersion int               `json:"lockfileVersion"`
+	LockfileVendor  string            `json:"lockfileVendor"`
+	Arches          []rpmLockfileArch `json:"arches"`
+}
+
+type rpmLockfileArch struct {
+	Arch           string            `json:"arch"`
+	Packages       []rpmLockfileItem `json:"packages"`
+	Source         []rpmLockfileItem `json:"source"`
+	ModuleMetadata []rpmLockfileItem `json:"module_metadata"`
+}
+
+type rpmLockfileItem struct {
+	URL      string `json:"url"`
+	RepoID   string `json:"repoid"`
+	Name     string `json:"name"`
+	Checksum string `json:"checksum"`
+}
+
+// The parts of the rpms.in.yaml format of rpm-lockfile-prototype the lockfile is checked against.
+type rpmInputFile struct {
+	Arches []string `json:"arches"`
+	// Package names, or objects with the name and the arches to install the package on
+	Packages      []any `json:"packages"`
+	ContentOrigin struct {
+		// Paths relative to the rpms.in.yaml or URLs, or objects with the location
+		Repofiles []any `json:"repofiles"`
+		Repos     []struct {
+			RepoID  string `json:"repoid"`
+			BaseURL string `json:"baseurl"`
+		} `json:"repos"`
+	} `json:"contentOrigin"`
+}
+
+var rpmChecksumPattern = regexp.MustCompile(`^[a-z0-9]+:[0-9a-f]+$`)
+
+// Package names that are not provides (e.g. '/usr/bin/python3' or 'perl(Carp)') nor globs
+var rpmPackageNamePattern = regexp.MustCompile(`^[A-Za-z0-9._+-]+$`)
+
+// Validate the lockfiles of the rpm packages of the (validated) input before running Hermeto,
+// and check them against their rpms.in.yaml, if any. With refreshCheck, also report the drift of
+// the lockfiles from their rpms.in.yaml and repo files, i.e. the lockfiles that need regenerating.
+func validateRPMLockfiles(input any, sourceDir string, refreshCheck bool) error {
+	for _, set := range splitInputByType(input) {
+		if set.Type != "rpm" {
+			continue
+		}
+		for _, pkg := range set.Input["packages"].([]any) {
+			packagePath, _ := pkg.(map[string]any)["path"].(string)
+			packageDir := filepath.Join(sourceDir, packagePath)
+			if err := validateRPMLockfile(packageDir, refreshCheck); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateRPMLockfile(packageDir string, refreshCheck bool) error {
+	lockfilePath := filepath.Join(packageDir, rpmLockfileName)
+	content, err := os.ReadFile(lockfilePath) //nolint:gosec // lockfile of the source directory
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s not found, generate it with rpm-lockfile-prototype", lockfilePath)
+	}
+	if err != nil {
+		return err
+	}
+	var lockfile rpmLockfile
+	if err := yaml.Unmarshal(content, &lockfile); err != nil {
+		return fmt.Errorf("parsing %s: %w", lockfilePath, err)
+	}
+
+	problems := lockfile.validate()
+
+	inputFilePath := filepath.Join(packageDir, rpmInputFileName)
+	inputFile, err := readRPMInputFile(inputFilePath)
+	if err != nil {
+		return err
+	}
+	var repos map[string][]string
+	if inputFile != nil {
+		for _, arch := range inputFile.Arches {
+			if !slices.ContainsFunc(lockfile.Arches, func(a rpmLockfileArch) bool { return a.Arch == arch }) {
+				problems = append(problems, fmt.Sprintf("arch %s of %s is missing, regenerate the lockfile", arch, rpmInputFileName))
+			}
+		}
+
+		repos, err = inputFile.repos(packageDir)
+		if err != nil {
+			return err
+		}
+		if repos != nil {
+			problems = append(problems, lockfile.unresolvedRepoIDs(repos)...)
+		}
+	}
+
+	if refreshCheck {
+		if inputFile == nil {
+			log.Warnf("Not checking %s for drift: %s not found", lockfilePath, inputFilePath)
+		} else {
+			problems = append(problems, lockfile.drift(inputFile, repos)...)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid %s:\n  %s", lockfilePath, strings.Join(problems, "\n  "))
+	}
+	log.Infof("Validated %s", lockfilePath)
+	return nil
+}
+
+// Check the lockfile against the schema Hermeto expects.
+func (lockfile *rpmLockfile) validate() []string {
+	var problems []string
+	if lockfile.LockfileVersion != 1 {
+		problems = append(problems, fmt.Sprintf("lockfileVersion must be 1, got %d", lockfile.LockfileVersion))
+	}
+	if lockfile.LockfileVendor != "redhat" {
+		problems = append(problems, fmt.Sprintf("lockfileVendor must be 'redhat', got '%s'", lockfile.LockfileVendor))
+	}
+	if len(lockfile.Arches) == 0 {
+		problems = append(problems, "no arches, regenerate the lockfile with the arches to build for")
+	}
+
+	seen := make(map[string]bool)
+	for i, arch := range lockfile.Arches {
+		if arch.Arch == "" {
+			problems = append(problems, fmt.Sprintf("arches[%d]: missing arch", i))
+			continue
+		}
+		if seen[arch.Arch] {
+			problems = append(problems, fmt.Sprintf("arch %s: listed more than once", arch.Arch))
+		}
+		seen[arch.Arch] = true
+
+		for _, items := range arch.items() {
+			for j, item := range items.items {
+				location := fmt.Sprintf("arch %s: %s[%d]", arch.Arch, items.field, j)
+				if item.URL == "" {
+					problems = append(problems, location+": missing url")
+				}
+				if item.Checksum != "" && !rpmChecksumPattern.MatchString(item.Checksum) {
+					problems = append(problems, fmt.Sprintf("%s: checksum '%s' is not in the algorithm:digest format", location, item.Checksum))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// The items of an arch listed in one field of the lockfile.
+type rpmLockfileItems struct {
+	field string
+	items []rpmLockfileItem
+}
+
+func (arch *rpmLockfileArch) items() []rpmLockfileItems {
+	return []rpmLockfileItems{
+		{"packages", arch.Packages},
+		{"source", arch.Source},
+		{"module_metadata", arch.ModuleMetadata},
+	}
+}
+
+// Report the repoids of the lockfile that aren't defined by the repos of rpms.in.yaml.
+func (lockfile *rpmLockfile) unresolvedRepoIDs(repos map[string][]string) []string {
+	var problems []string
+	for _, arch := range lockfile.Arches {
+		var unresolved []string
+		for _, items := range arch.items() {
+			for _, item := range items.items {
+				if item.RepoID == "" || slices.Contains(unresolved, item.RepoID) {
+					continue
+				}
+				if _, ok := findRepo(repos, item.RepoID, arch.Arch); !ok {
+					unresolved = append(unresolved, item.RepoID)
+				}
+			}
+		}
+		slices.Sort(unresolved)
+		for _, repoID := range unresolved {
+			problems = append(problems, fmt.Sprintf("arch %s: repoid %s is not defined by the repos of %s", arch.Arch, repoID, rpmInputFileName))
+		}
+	}
+	return problems
+}
+
+// Report the packages of rpms.in.yaml missing from the lockfile and the URLs that don't match
+// the baseurls of their repos anymore. The repos are nil if they aren't all known.
+func (lockfile *rpmLockfile) drift(inputFile *rpmInputFile, repos map[string][]string) []string {
+	var problems []string
+	for _, arch := range lockfile.Arches {
+		for _, name := range inputFile.packageNames(arch.Arch) {
+			if !slices.ContainsFunc(arch.Packages, func(item rpmLockfileItem) bool { return item.Name == name }) {
+				problems = append(problems, fmt.Sprintf("arch %s: package %s of %s is not locked", arch.Arch, name, rpmInputFileName))
+			}
+		}
+
+		if repos == nil {
+			continue
+		}
+		for _, items := range arch.items() {
+			for _, item := range items.items {
+				baseURLs, ok := findRepo(repos, item.RepoID, arch.Arch)
+				if !ok || len(baseURLs) == 0 {
+					continue
+				}
+				if !slices.ContainsFunc(baseURLs, func(baseURL string) bool {
+					return repoVarPattern(strings.TrimSuffix(baseURL, "/")+"/", arch.Arch, true).MatchString(item.URL)
+				}) {
+					problems = append(problems, fmt.Sprintf("arch %s: %s is not in the baseurl of repo %s", arch.Arch, item.URL, item.RepoID))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// Find the baseurls of the repo with the id the lockfile has for the arch.
+func findRepo(repos map[string][]string, repoID, arch string) ([]string, bool) {
+	for id, baseURLs := range repos {
+		if repoVarPattern(id, arch, false).MatchString(repoID) {
+			return baseURLs, true
+		}
+	}
+	return nil, false
+}
+
+var repoVariable = regexp.MustCompile(`\\\$(\\\{)?[A-Za-z0-9_]+(\\\})?`)
+
+// A pattern matching the repo id or baseurl with the $basearch variable expanded, the other
+// variables (e.g. $releasever) match anything.
+func repoVarPattern(value, arch string, prefix bool) *regexp.Regexp {
+	value = strings.NewReplacer("${basearch}", arch, "$basearch", arch).Replace(value)
+	pattern := repoVariable.ReplaceAllString(regexp.QuoteMeta(value), `[^/]+`)
+	if prefix {
+		return regexp.MustCompile("^" + pattern)
+	}
+	return regexp.MustCompile("^" + pattern + "$")
+}
+
+// Read the rpms.in.yaml, nil if there is none.
+func readRPMInputFile(path string) (*rpmInputFile, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // rpms.in.yaml of the source directory
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var inputFile rpmInputFile
+	if err := yaml.Unmarshal(content, &inputFile); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &inputFile, nil
+}
+
+// The names of the packages to install on the arch. Provides and globs are skipped,
+// the package names they resolve to are not known.
+func (inputFile *rpmInputFile) packageNames(arch string) []string {
+	var names []string
+	for _, pkg := range inputFile.Packages {
+		var name string
+		switch value := pkg.(type) {
+		case string:
+			name = value
+		case map[string]any:
+			name, _ = value["name"].(string)
+			if arches, ok := value["arches"].(map[string]any); ok {
+				if only, ok := toStringSlice(arches["only"]); ok && !slices.Contains(only, arch) {
+					continue
+				}
+				if exclude, ok := toStringSlice(arches["exclude"]); ok && slices.Contains(exclude, arch) {
+					continue
+				}
+			}
+		}
+		if rpmPackageNamePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// The baseurls of the repos of rpms.in.yaml by repo id. Returns nil if some repo files are not local,
+// the repos are not all known then.
+func (inputFile *rpmInputFile) repos(inputFileDir string) (map[string][]string, error) {
+	repos := make(map[string][]string)
+	for _, repo := range inputFile.ContentOrigin.Repos {
+		repos[repo.RepoID] = append(repos[repo.RepoID], repo.BaseURL)
+	}
+
+	for _, repofile := range inputFile.ContentOrigin.Repofiles {
+		location, ok := repofile.(string)
+		if !ok {
+			location, _ = repofile.(map[string]any)["location"].(string)
+		}
+		if location == "" || strings.Contains(location, "://") {
+			log.Infof("Not checking the repoids of %s: repo file %v is not local", rpmInputFileName, repofile)
+			return nil, nil
+		}
+		if !filepath.IsAbs(location) {
+			location = filepath.Join(inputFileDir, location)
+		}
+
+		cfg, err := ini.Load(location)
+		if err != nil {
+			return nil, fmt.Errorf("reading repo file %s: %w", location, err)
+		}
+		for _, section := range cfg.Sections() {
+			if section.Name() == ini.DefaultSection {
+				continue
+			}
+			repos[section.Name()] = append(repos[section.Name()], strings.Fields(section.Key("baseurl").String())...)
+		}
+	}
+	return repos, nil
+}
@@ -0,0 +1,142 @@
+package prefetch_dependencies
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const testRPMLockfile = `lockfileVersion: 1
+lockfileVendor: redhat
+arches:
+  - arch: x86_64
+    packages:
+      - url: https://cdn.example.com/ubi9/x86_64/baseos/os/Packages/j/jq-1.6-17.el9.x86_64.rpm
+        repoid: ubi-9-for-x86_64-baseos-rpms
+        size: 190000
+        checksum: sha256:0f6f1e8d0a8f1f8a3e0b2d8c7d6d5a4b3c2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a
+        name: jq
+        evr: 1.6-17.el9
+    source: []
+    module_metadata: []
+`
+
+const testRPMRepoFile = `[ubi-9-for-$basearch-baseos-rpms]
+name = UBI 9 BaseOS
+baseurl = https://cdn.example.com/ubi9/$basearch/baseos/os
+enabled = 1
+`
+
+func writeRPMPackage(t *testing.T, g *WithT, files map[string]string) string {
+	sourceDir := t.TempDir()
+	for name, content := range files {
+		g.Expect(os.WriteFile(filepath.Join(sourceDir, name), []byte(content), 0644)).To(Succeed())
+	}
+	return sourceDir
+}
+
+func TestValidateRPMLockfiles(t *testing.T) {
+	input := parseInput(`{"packages": [{"type": "gomod"}, {"type": "rpm"}]}`)
+
+	t.Run("should accept a valid lockfile", func(t *testing.T) {
+		g := NewWithT(t)
+		sourceDir := writeRPMPackage(t, g, map[string]string{
+			"rpms.lock.yaml": testRPMLockfile,
+			"rpms.in.yaml":   "packages: [jq]\narches: [x86_64]\ncontentOrigin:\n  repofiles: [./ubi.repo]\n",
+			"ubi.repo":       testRPMRepoFile,
+		})
+
+		g.Expect(validateRPMLockfiles(input, sourceDir, true)).To(Succeed())
+	})
+
+	t.Run("should report a missing lockfile", func(t *testing.T) {
+		g := NewWithT(t)
+		sourceDir := t.TempDir()
+
+		err := validateRPMLockfiles(input, sourceDir, false)
+		g.Expect(err).To(MatchError(filepath.Join(sourceDir, "rpms.lock.yaml") + " not found, generate it with rpm-lockfile-prototype"))
+	})
+
+	t.Run("should report the schema problems", func(t *testing.T) {
+		g := NewWithT(t)
+		sourceDir := writeRPMPackage(t, g, map[string]string{
+			"rpms.lock.yaml": `lockfileVersion: 2
+lockfileVendor: redhat
+arches:
+  - packages: []
+  - arch: aarch64
+    packages:
+      - repoid: ubi
+        checksum: "1234"
+`,
+		})
+
+		err := validateRPMLockfiles(input, sourceDir, false)
+		g.Expect(err).To(MatchError("invalid " + filepath.Join(sourceDir, "rpms.lock.yaml") + ":\n" +
+			"  lockfileVersion must be 1, got 2\n" +
+			"  arches[0]: missing arch\n" +
+			"  arch aarch64: packages[0]: missing url\n" +
+			"  arch aarch64: packages[0]: checksum '1234' is not in the algorithm:digest format"))
+	})
+
+	t.Run("should report the missing arches and the unresolved repoids", func(t *testing.T) {
+		g := NewWithT(t)
+		sourceDir := writeRPMPackage(t, g, map[string]string{
+			"rpms.lock.yaml": testRPMLockfile,
+			"rpms.in.yaml": "packages: [jq]\narches: [x86_64, aarch64]\ncontentOrigin:\n  repofiles: [./ubi.repo]\n" +
+				"  repos:\n    - repoid: extra\n      baseurl: https://example.com/extra\n",
+			"ubi.repo": "[ubi-9-appstream-rpms]\nbaseurl = https://cdn.example.com/ubi9/appstream\n",
+		})
+
+		err := validateRPMLockfiles(input, sourceDir, false)
+		g.Expect(err).To(MatchError(ContainSubstring("arch aarch64 of rpms.in.yaml is missing, regenerate the lockfile")))
+		g.Expect(err).To(MatchError(ContainSubstring("arch x86_64: repoid ubi-9-for-x86_64-baseos-rpms is not defined by the repos of rpms.in.yaml")))
+	})
+
+	t.Run("should not check the repoids of remote repo files", func(t *testing.T) {
+		g := NewWithT(t)
+		sourceDir := writeRPMPackage(t, g, map[string]string{
+			"rpms.lock.yaml": testRPMLockfile,
+			"rpms.in.yaml":   "packages: [jq]\ncontentOrigin:\n  repofiles: [https://example.com/ubi.repo]\n",
+		})
+
+		g.Expect(validateRPMLockfiles(input, sourceDir, true)).To(Succeed())
+	})
+
+	t.Run("should report the drift with the refresh check", func(t *testing.T) {
+		g := NewWithT(t)
+		sourceDir := writeRPMPackage(t, g, map[string]string{
+			"rpms.lock.yaml": testRPMLockfile,
+			"rpms.in.yaml": `packages:
+  - jq
+  - python3
+  - /usr/bin/less
+  - name: grub2-efi-aa64
+    arches:
+      only: [aarch64]
+contentOrigin:
+  repofiles: [./ubi.repo]
+`,
+			"ubi.repo": "[ubi-9-for-$basearch-baseos-rpms]\nbaseurl = https://cdn.example.com/ubi9.4/$basearch/baseos/os\n",
+		})
+
+		g.Expect(validateRPMLockfiles(input, sourceDir, false)).To(Succeed())
+
+		err := validateRPMLockfiles(input, sourceDir, true)
+		g.Expect(err).To(MatchError("invalid " + filepath.Join(sourceDir, "rpms.lock.yaml") + ":\n" +
+			"  arch x86_64: package python3 of rpms.in.yaml is not locked\n" +
+			"  arch x86_64: https://cdn.example.com/ubi9/x86_64/baseos/os/Packages/j/jq-1.6-17.el9.x86_64.rpm " +
+			"is not in the baseurl of repo ubi-9-for-x86_64-baseos-rpms"))
+	})
+}
+
+func TestRepoVarPattern(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(repoVarPattern("ubi-9-for-$basearch-rpms", "aarch64", false).MatchString("ubi-9-for-aarch64-rpms")).To(BeTrue())
+	g.Expect(repoVarPattern("ubi-9-for-$basearch-rpms", "aarch64", false).MatchString("ubi-9-for-x86_64-rpms")).To(BeFalse())
+	g.Expect(repoVarPattern("https://cdn/${releasever}/${basearch}/", "s390x", true).MatchString("https://cdn/9/s390x/Packages/a.rpm")).To(BeTrue())
+	g.Expect(repoVarPattern("https://cdn/$releasever/os/", "s390x", true).MatchString("https://cdn/9/other/a.rpm")).To(BeFalse())
+}
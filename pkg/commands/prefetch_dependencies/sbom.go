@@ -0,0 +1,43 @@
+package prefetch_dependencies
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common/sbom"
+)
+
+// The SBOM files written into --sbom-output-path by format, at fixed names so that the step
+// merging them with the image SBOM finds the one in its format.
+var sbomOutputFileNames = map[string]string{
+	"spdx":      "bom.spdx.json",
+	"cyclonedx": "bom.cyclonedx.json",
+}
+
+// Write the SBOM Hermeto generated into dir in both formats, converting it into the other one.
+// Returns the paths by format.
+func writeSBOMFormats(sbomPath, dir string) (map[string]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	paths := map[string]string{}
+	for _, format := range slices.Sorted(maps.Keys(sbomOutputFileNames)) {
+		fileName := sbomOutputFileNames[format]
+		content, err := sbom.ConvertFile(sbomPath, format)
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, fileName)
+		if err := common.WriteResultFile(path, content); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", path, err)
+		}
+		log.Infof("Wrote %s SBOM to %s", format, path)
+		paths[format] = path
+	}
+	return paths, nil
+}
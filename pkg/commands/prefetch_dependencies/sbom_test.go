@@ -0,0 +1,59 @@
+package prefetch_dependencies
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestWriteSBOMFormats(t *testing.T) {
+	const cycloneDX = `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.6",
+		"metadata": {},
+		"components": [
+			{"bom-ref": "pkg:golang/github.com/a/a@v1.0.0", "type": "library", "name": "github.com/a/a", "version": "v1.0.0", "purl": "pkg:golang/github.com/a/a@v1.0.0"}
+		]
+	}`
+
+	t.Run("should write the SBOM in both formats", func(t *testing.T) {
+		g := NewWithT(t)
+		sbomPath := filepath.Join(t.TempDir(), "bom.json")
+		g.Expect(os.WriteFile(sbomPath, []byte(cycloneDX), 0644)).To(Succeed())
+		outputDir := filepath.Join(t.TempDir(), "sboms")
+
+		paths, err := writeSBOMFormats(sbomPath, outputDir)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(paths).To(Equal(map[string]string{
+			"spdx":      filepath.Join(outputDir, "bom.spdx.json"),
+			"cyclonedx": filepath.Join(outputDir, "bom.cyclonedx.json"),
+		}))
+
+		// The SBOM in the format Hermeto wrote is copied as it is
+		content, err := os.ReadFile(paths["cyclonedx"])
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(content)).To(Equal(cycloneDX))
+
+		content, err = os.ReadFile(paths["spdx"])
+		g.Expect(err).ToNot(HaveOccurred())
+		var spdx map[string]any
+		g.Expect(json.Unmarshal(content, &spdx)).To(Succeed())
+		g.Expect(spdx["spdxVersion"]).To(Equal("SPDX-2.3"))
+
+		purls, err := readSBOMPurls(paths["spdx"])
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(purls).To(Equal([]string{"pkg:golang/github.com/a/a@v1.0.0"}))
+	})
+
+	t.Run("should fail on an unknown SBOM format", func(t *testing.T) {
+		g := NewWithT(t)
+		sbomPath := filepath.Join(t.TempDir(), "bom.json")
+		g.Expect(os.WriteFile(sbomPath, []byte(`{"components": []}`), 0644)).To(Succeed())
+
+		_, err := writeSBOMFormats(sbomPath, t.TempDir())
+		g.Expect(err).To(MatchError(ContainSubstring("is not a CycloneDX or SPDX SBOM")))
+	})
+}
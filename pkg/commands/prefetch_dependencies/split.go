@@ -0,0 +1,201 @@
+package prefetch_dependencies
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+// Directory in the output directory where the package sets are prefetched with --split-by-type.
+// It's removed once the outputs are merged.
+const splitOutputDirName = ".split"
+
+// The packages of one package manager type, as a Hermeto input.
+type packageSet struct {
+	Type  string
+	Input map[string]any
+}
+
+// Group the packages of the (validated) input by package manager type, in the order of the first
+// package of each type. The flags of the input apply to all the package sets.
+func splitInputByType(input any) []packageSet {
+	var packages []any
+	var flags any
+	switch data := input.(type) {
+	case string:
+		// JSON string input: "gomod"
+		packages = []any{map[string]any{"type": data}}
+
+	case []any:
+		// Array format: [{"type": "rpm"}]
+		packages = data
+
+	case map[string]any:
+		flags = data["flags"]
+		if packagesValue, ok := data["packages"].([]any); ok {
+			// Object format with "packages" field: {"packages": [{"type": "rpm"}]}
+			packages = packagesValue
+		} else {
+			// Object format with "type" field: {"type": "rpm"}
+			packages = []any{data}
+		}
+	}
+
+	var sets []packageSet
+	setIndexes := make(map[string]int)
+	for _, pkg := range packages {
+		packageType, _ := pkg.(map[string]any)["type"].(string)
+		i, ok := setIndexes[packageType]
+		if !ok {
+			i = len(sets)
+			setIndexes[packageType] = i
+			setInput := map[string]any{"packages": []any{}}
+			if flags != nil {
+				setInput["flags"] = flags
+			}
+			sets = append(sets, packageSet{Type: packageType, Input: setInput})
+		}
+		sets[i].Input["packages"] = append(sets[i].Input["packages"].([]any), pkg)
+	}
+	return sets
+}
+
+// Run Hermeto once per package manager type, each into its own output directory, and merge
+// the outputs: the dependencies are moved to the output directory, the env files and the SBOMs
+// are merged into the ones a single Hermeto run would have written.
+func (pd *PrefetchDependencies) fetchDepsByType(input any) error {
+	splitDir := filepath.Join(pd.Config.OutputDir, splitOutputDirName)
+	if err := os.RemoveAll(splitDir); err != nil {
+		return err
+	}
+
+	sets := splitInputByType(input)
+	var sbomFiles []string
+	envFileParts := make([][]string, len(pd.Config.EnvFiles))
+	for _, set := range sets {
+		log.Infof("Prefetching %s dependencies", set.Type)
+		setOutputDir := filepath.Join(splitDir, set.Type)
+
+		if err := pd.fetchDeps(set.Input, setOutputDir); err != nil {
+			return fmt.Errorf("prefetching %s dependencies: %w", set.Type, err)
+		}
+
+		// The format of the env file is inferred from its suffix, keep the base name
+		var setEnvFiles []string
+		for i, envFile := range pd.Config.EnvFiles {
+			setEnvFile := filepath.Join(setOutputDir, fmt.Sprintf("%d-%s", i, filepath.Base(envFile)))
+			setEnvFiles = append(setEnvFiles, setEnvFile)
+			envFileParts[i] = append(envFileParts[i], setEnvFile)
+		}
+		// The dependencies end up in the output directory, the paths are the same for the mount point
+		if err := pd.generateEnv(setOutputDir, setEnvFiles); err != nil {
+			return err
+		}
+		if err := pd.injectFiles(setOutputDir); err != nil {
+			return err
+		}
+
+		if err := moveDeps(filepath.Join(setOutputDir, "deps"), filepath.Join(pd.Config.OutputDir, "deps")); err != nil {
+			return fmt.Errorf("moving %s dependencies: %w", set.Type, err)
+		}
+		sbomFiles = append(sbomFiles, filepath.Join(setOutputDir, "bom.json"))
+	}
+
+	for i, envFile := range pd.Config.EnvFiles {
+		if err := mergeEnvFiles(envFileParts[i], envFile); err != nil {
+			return fmt.Errorf("merging env files into %s: %w", envFile, err)
+		}
+	}
+
+	sbomFile := filepath.Join(pd.Config.OutputDir, "bom.json")
+	if len(sbomFiles) == 1 {
+		if err := os.Rename(sbomFiles[0], sbomFile); err != nil {
+			return err
+		}
+	} else {
+		mergeSBOMsParams := cliwrappers.HermetoMergeSBOMsParams{
+			SBOMFiles:  sbomFiles,
+			SBOMFormat: pd.Config.SBOMFormat,
+			Output:     sbomFile,
+		}
+		if err := pd.HermetoCli.MergeSBOMs(&mergeSBOMsParams); err != nil {
+			return fmt.Errorf("hermeto merge-sboms command failed: %w", err)
+		}
+	}
+
+	return os.RemoveAll(splitDir)
+}
+
+// Move the entries of the dependencies directory of a package set to the merged one.
+// The package managers write to their own subdirectories, so the entries don't collide.
+func moveDeps(setDepsDir, depsDir string) error {
+	entries, err := os.ReadDir(setDepsDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(depsDir, 0755); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		destination := filepath.Join(depsDir, entry.Name())
+		if _, err := os.Lstat(destination); err == nil {
+			return fmt.Errorf("%s already exists", destination)
+		}
+		if err := os.Rename(filepath.Join(setDepsDir, entry.Name()), destination); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge the env files generated for the package sets. JSON env files are arrays of variables,
+// the arrays are concatenated. Other formats are line based, the lines are concatenated
+// without duplicates.
+func mergeEnvFiles(parts []string, output string) error {
+	var merged []byte
+	if strings.HasSuffix(output, ".json") {
+		variables := []any{}
+		for _, part := range parts {
+			content, err := os.ReadFile(part) //nolint:gosec // env file generated in the output directory
+			if err != nil {
+				return err
+			}
+			var partVariables []any
+			if err := json.Unmarshal(content, &partVariables); err != nil {
+				return fmt.Errorf("parsing %s: %w", part, err)
+			}
+			variables = append(variables, partVariables...)
+		}
+		content, err := json.MarshalIndent(variables, "", "  ")
+		if err != nil {
+			return err
+		}
+		merged = append(content, '\n')
+	} else {
+		var lines []string
+		seen := make(map[string]bool)
+		for _, part := range parts {
+			content, err := os.ReadFile(part) //nolint:gosec // env file generated in the output directory
+			if err != nil {
+				return err
+			}
+			for _, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+				if line == "" || seen[line] {
+					continue
+				}
+				seen[line] = true
+				lines = append(lines, line)
+			}
+		}
+		merged = []byte(strings.Join(lines, "\n") + "\n")
+	}
+
+	return os.WriteFile(output, merged, 0644) //nolint:gosec // G703: env file path from controlled input
+}
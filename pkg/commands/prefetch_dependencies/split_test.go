@@ -0,0 +1,188 @@
+package prefetch_dependencies
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+// Writes the files Hermeto would write, for the package managers of the input.
+type fakeHermetoCli struct {
+	fetchDepsInputs []string
+	mergedSBOMs     []string
+}
+
+func (f *fakeHermetoCli) Version() error { return nil }
+
+func (f *fakeHermetoCli) FetchDeps(params *cliwrappers.HermetoFetchDepsParams) error {
+	f.fetchDepsInputs = append(f.fetchDepsInputs, params.Input)
+	var input struct {
+		Packages []struct {
+			Type string `json:"type"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal([]byte(params.Input), &input); err != nil {
+		return err
+	}
+	for _, pkg := range input.Packages {
+		if err := os.MkdirAll(filepath.Join(params.OutputDir, "deps", pkg.Type), 0755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filepath.Join(params.OutputDir, "bom.json"), []byte(input.Packages[0].Type), 0644)
+}
+
+func (f *fakeHermetoCli) GenerateEnv(params *cliwrappers.HermetoGenerateEnvParams) error {
+	setType := filepath.Base(params.OutputDir)
+	content := "export " + setType + "=" + params.ForOutputDir + "/deps/" + setType + "\nexport COMMON=1\n"
+	if filepath.Ext(params.Output) == ".json" {
+		content = `[{"name": "` + setType + `", "value": "` + params.ForOutputDir + `/deps/` + setType + `"}]`
+	}
+	return os.WriteFile(params.Output, []byte(content), 0644)
+}
+
+func (f *fakeHermetoCli) InjectFiles(params *cliwrappers.HermetoInjectFilesParams) error { return nil }
+
+func (f *fakeHermetoCli) MergeSBOMs(params *cliwrappers.HermetoMergeSBOMsParams) error {
+	f.mergedSBOMs = params.SBOMFiles
+	return os.WriteFile(params.Output, []byte("merged"), 0644)
+}
+
+func TestSplitInputByType(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should group packages by type", func(t *testing.T) {
+		sets := splitInputByType(parseInput(`{
+			"packages": [
+				{"type": "gomod", "path": "backend"},
+				{"type": "npm", "path": "frontend"},
+				{"type": "gomod", "path": "tools"}
+			],
+			"flags": ["cgo-disable"]
+		}`))
+
+		g.Expect(sets).To(HaveLen(2))
+		g.Expect(sets[0].Type).To(Equal("gomod"))
+		g.Expect(sets[0].Input).To(Equal(map[string]any{
+			"packages": []any{
+				map[string]any{"type": "gomod", "path": "backend"},
+				map[string]any{"type": "gomod", "path": "tools"},
+			},
+			"flags": []any{"cgo-disable"},
+		}))
+		g.Expect(sets[1].Type).To(Equal("npm"))
+		g.Expect(sets[1].Input).To(Equal(map[string]any{
+			"packages": []any{map[string]any{"type": "npm", "path": "frontend"}},
+			"flags":    []any{"cgo-disable"},
+		}))
+	})
+
+	t.Run("should handle the other input forms", func(t *testing.T) {
+		sets := splitInputByType(parseInput(`gomod`))
+		g.Expect(sets).To(Equal([]packageSet{
+			{Type: "gomod", Input: map[string]any{"packages": []any{map[string]any{"type": "gomod"}}}},
+		}))
+
+		sets = splitInputByType(parseInput(`{"type": "pip", "path": "app"}`))
+		g.Expect(sets).To(Equal([]packageSet{
+			{Type: "pip", Input: map[string]any{"packages": []any{map[string]any{"type": "pip", "path": "app"}}}},
+		}))
+
+		sets = splitInputByType(parseInput(`[{"type": "rpm"}, {"type": "pip"}]`))
+		g.Expect(sets).To(HaveLen(2))
+		g.Expect(sets[0].Type).To(Equal("rpm"))
+		g.Expect(sets[1].Type).To(Equal("pip"))
+	})
+}
+
+func TestFetchDepsByType(t *testing.T) {
+	g := NewWithT(t)
+
+	newPrefetchDependencies := func(envFiles ...string) (*PrefetchDependencies, *fakeHermetoCli) {
+		hermetoCli := &fakeHermetoCli{}
+		return &PrefetchDependencies{
+			Config: &Params{
+				SourceDir:           t.TempDir(),
+				OutputDir:           filepath.Join(t.TempDir(), "output"),
+				SBOMFormat:          "spdx",
+				Mode:                "strict",
+				OutputDirMountPoint: "/tmp",
+				EnvFiles:            envFiles,
+			},
+			HermetoCli: hermetoCli,
+		}, hermetoCli
+	}
+
+	t.Run("should prefetch each type separately and merge the outputs", func(t *testing.T) {
+		envDir := t.TempDir()
+		envFile := filepath.Join(envDir, "prefetch.env")
+		jsonEnvFile := filepath.Join(envDir, "prefetch.json")
+		pd, hermetoCli := newPrefetchDependencies(envFile, jsonEnvFile)
+
+		err := pd.fetchDepsByType(parseInput(`[{"type": "gomod", "path": "backend"}, {"type": "npm", "path": "frontend"}]`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(hermetoCli.fetchDepsInputs).To(Equal([]string{
+			`{"packages":[{"path":"backend","type":"gomod"}]}`,
+			`{"packages":[{"path":"frontend","type":"npm"}]}`,
+		}))
+		g.Expect(filepath.Join(pd.Config.OutputDir, "deps", "gomod")).To(BeADirectory())
+		g.Expect(filepath.Join(pd.Config.OutputDir, "deps", "npm")).To(BeADirectory())
+
+		splitDir := filepath.Join(pd.Config.OutputDir, splitOutputDirName)
+		g.Expect(hermetoCli.mergedSBOMs).To(Equal([]string{
+			filepath.Join(splitDir, "gomod", "bom.json"),
+			filepath.Join(splitDir, "npm", "bom.json"),
+		}))
+		g.Expect(os.ReadFile(filepath.Join(pd.Config.OutputDir, "bom.json"))).To(Equal([]byte("merged")))
+		g.Expect(splitDir).ToNot(BeAnExistingFile())
+
+		g.Expect(os.ReadFile(envFile)).To(Equal([]byte(
+			"export gomod=/tmp/deps/gomod\nexport COMMON=1\nexport npm=/tmp/deps/npm\n",
+		)))
+		var variables []map[string]string
+		content, err := os.ReadFile(jsonEnvFile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(json.Unmarshal(content, &variables)).To(Succeed())
+		g.Expect(variables).To(Equal([]map[string]string{
+			{"name": "gomod", "value": "/tmp/deps/gomod"},
+			{"name": "npm", "value": "/tmp/deps/npm"},
+		}))
+	})
+
+	t.Run("should not merge the SBOM of a single type", func(t *testing.T) {
+		pd, hermetoCli := newPrefetchDependencies()
+
+		err := pd.fetchDepsByType(parseInput(`{"packages": [{"type": "gomod", "path": "a"}, {"type": "gomod", "path": "b"}]}`))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(hermetoCli.fetchDepsInputs).To(HaveLen(1))
+		g.Expect(hermetoCli.mergedSBOMs).To(BeNil())
+		g.Expect(os.ReadFile(filepath.Join(pd.Config.OutputDir, "bom.json"))).To(Equal([]byte("gomod")))
+	})
+}
+
+func TestMoveDeps(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should fail if the dependencies of two sets collide", func(t *testing.T) {
+		setDepsDir := filepath.Join(t.TempDir(), "deps")
+		depsDir := filepath.Join(t.TempDir(), "deps")
+		g.Expect(os.MkdirAll(filepath.Join(setDepsDir, "generic"), 0755)).To(Succeed())
+		g.Expect(os.MkdirAll(filepath.Join(depsDir, "generic"), 0755)).To(Succeed())
+
+		err := moveDeps(setDepsDir, depsDir)
+		g.Expect(err).To(MatchError(ContainSubstring("already exists")))
+	})
+
+	t.Run("should do nothing if the set has no dependencies", func(t *testing.T) {
+		depsDir := filepath.Join(t.TempDir(), "deps")
+		g.Expect(moveDeps(filepath.Join(t.TempDir(), "deps"), depsDir)).To(Succeed())
+		g.Expect(depsDir).ToNot(BeAnExistingFile())
+	})
+}
@@ -4,20 +4,26 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"maps"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	"github.com/konflux-ci/konflux-build-cli/pkg/logger"
+	"sigs.k8s.io/yaml"
 )
 
 const readOnlyFileMode = os.FileMode(0444)
 
 // Rename repo files in the output directory to expected cachi2.repo.
-func renameRepoFiles(outputDir string) error {
+func renameRepoFiles(outputDir string, sideEffects *common.SideEffects) error {
 	var repoFiles []string
 
 	log.Debugf("Searching for repo files in %s", outputDir)
@@ -42,6 +48,9 @@ func renameRepoFiles(outputDir string) error {
 		if err := os.Rename(repoFile, newRepoFile); err != nil {
 			return err
 		}
+		if err := sideEffects.RecordRename(repoFile, newRepoFile); err != nil {
+			return err
+		}
 		log.Debugf("Successfully renamed %s to %s", repoFile, newRepoFile)
 	}
 
@@ -63,6 +72,31 @@ func parseInput(input string) any {
 	return result
 }
 
+// Read the user input from a JSON or YAML file, or from stdin if path is "-".
+// The content is returned as JSON, so it can be processed the same way as --input.
+func readInputFile(path string, stdin io.Reader) (string, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(stdin)
+	} else {
+		content, err = os.ReadFile(path) //nolint:gosec // input file path from controlled input
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// JSON is a subset of YAML, so this handles both formats.
+	jsonContent, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s as JSON or YAML: %w", path, err)
+	}
+	if string(jsonContent) == "null" {
+		return "", nil
+	}
+	return string(jsonContent), nil
+}
+
 // Check if the user input contains an RPM package.
 func containsRPM(input any) bool {
 	switch data := input.(type) {
@@ -85,33 +119,189 @@ func containsRPM(input any) bool {
 	return false
 }
 
+// Package manager types supported by Hermeto. The types of the package managers that are still
+// in development are prefixed with "x-" and require the dev-package-managers flag.
+var knownPackageManagers = []string{"bundler", "cargo", "generic", "gomod", "npm", "pip", "pnpm", "rpm", "yarn"}
+
+const devPackageManagerPrefix = "x-"
+
+// Flags accepted by Hermeto in the "flags" field of the input.
+var knownInputFlags = []string{"cgo-disable", "dev-package-managers", "force-gomod-tidy", "gomod-vendor", "gomod-vendor-check"}
+
+// Expected JSON types of the package fields common to several package managers.
+// The fields specific to a package manager are left to Hermeto.
+var packageFieldTypes = map[string]string{
+	"type":                     "string",
+	"path":                     "string",
+	"options":                  "object",
+	"allow_binary":             "boolean",
+	"include_summary_in_sbom":  "boolean",
+	"requirements_files":       "array of strings",
+	"requirements_build_files": "array of strings",
+}
+
+// Validate the decoded user input before passing it to Hermeto, so that the mistakes are reported
+// with a clear message instead of a Hermeto validation error.
+func validateInput(input any, allowDevPackageManagers bool) error {
+	var packages []any
+	switch data := input.(type) {
+	case string:
+		// JSON string input: "gomod"
+		packages = []any{map[string]any{"type": data}}
+
+	case []any:
+		// Array format: [{"type": "rpm"}]
+		packages = data
+
+	case map[string]any:
+		if flagsValue, ok := data["flags"]; ok {
+			flags, ok := toStringSlice(flagsValue)
+			if !ok {
+				return fmt.Errorf(`field "flags" must be an array of strings, got %s`, jsonTypeName(flagsValue))
+			}
+			for _, flag := range flags {
+				if !slices.Contains(knownInputFlags, flag) {
+					return fmt.Errorf("unknown flag %q, expected one of: %s", flag, strings.Join(knownInputFlags, ", "))
+				}
+			}
+			if slices.Contains(flags, "dev-package-managers") {
+				allowDevPackageManagers = true
+			}
+		}
+
+		if packagesValue, ok := data["packages"]; ok {
+			// Object format with "packages" field: {"packages": [{"type": "rpm"}]}
+			if packages, ok = packagesValue.([]any); !ok {
+				return fmt.Errorf(`field "packages" must be an array, got %s`, jsonTypeName(packagesValue))
+			}
+		} else {
+			// Object format with "type" field: {"type": "rpm"}
+			packages = []any{data}
+		}
+
+	default:
+		return fmt.Errorf("input must be a package manager type, an object or an array, got %s", jsonTypeName(input))
+	}
+
+	if len(packages) == 0 {
+		return errors.New("no packages to prefetch in the input")
+	}
+	for i, pkg := range packages {
+		if err := validatePackageInput(pkg, allowDevPackageManagers); err != nil {
+			return fmt.Errorf("package %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validatePackageInput(input any, allowDevPackageManagers bool) error {
+	pkg, ok := input.(map[string]any)
+	if !ok {
+		return fmt.Errorf("must be an object, got %s", jsonTypeName(input))
+	}
+
+	typeValue, ok := pkg["type"]
+	if !ok {
+		return errors.New(`missing field "type"`)
+	}
+	for _, field := range slices.Sorted(maps.Keys(packageFieldTypes)) {
+		value, ok := pkg[field]
+		if !ok {
+			continue
+		}
+		expectedType := packageFieldTypes[field]
+		valid := jsonTypeName(value) == expectedType
+		if expectedType == "array of strings" {
+			_, valid = toStringSlice(value)
+		}
+		if !valid {
+			return fmt.Errorf("field %q must be %s %s, got %s", field, articleFor(expectedType), expectedType, jsonTypeName(value))
+		}
+	}
+
+	// Each package can be in its own subdirectory of the source directory, e.g. in a monorepo
+	if packagePath, ok := pkg["path"].(string); ok && !filepath.IsLocal(packagePath) {
+		return fmt.Errorf("field \"path\" must be a path relative to the source directory, got %q", packagePath)
+	}
+
+	packageType := typeValue.(string)
+	if strings.HasPrefix(packageType, devPackageManagerPrefix) {
+		if !allowDevPackageManagers {
+			return fmt.Errorf("package manager %q is in development, use --allow-dev-package-managers to enable it", packageType)
+		}
+		return nil
+	}
+	if !slices.Contains(knownPackageManagers, packageType) {
+		return fmt.Errorf("unknown package manager type %q, expected one of: %s", packageType, strings.Join(knownPackageManagers, ", "))
+	}
+	return nil
+}
+
+// Name of the JSON type of the decoded value, as used in the error messages.
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func articleFor(typeName string) string {
+	if strings.ContainsAny(typeName[:1], "aeiou") {
+		return "an"
+	}
+	return "a"
+}
+
+func toStringSlice(value any) ([]string, bool) {
+	items, ok := value.([]any)
+	if !ok {
+		return nil, false
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		str, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, str)
+	}
+	return result, true
+}
+
 // Modify the user input for RPM packages.
-func injectRPMInput(input any, registeredWithRHSM bool) (any, error) {
+// If repoIDs is not empty, the entitlement is used only for the RPM repositories with those ids.
+func injectRPMInput(input any, registeredWithRHSM bool, entitlementDir string, repoIDs []string) (any, error) {
 	withSummary := injectSummaryInSBOMField(input)
 
 	if !registeredWithRHSM {
 		return withSummary, nil
 	}
 
-	// Glob ignores file system errors such as I/O errors reading directories.
-	// The only possible returned error is ErrBadPattern, when pattern is malformed.
-	entitlementFiles, _ := filepath.Glob("/etc/pki/entitlement/*.pem")
-
-	// Expect exactly one client key and one client cert file.
-	var clientKeyPath, clientCertPath string
-	for _, file := range entitlementFiles {
-		if strings.HasSuffix(file, "-key.pem") {
-			clientKeyPath = file
-		} else {
-			clientCertPath = file
-		}
+	clientCertPath, clientKeyPath, err := findEntitlementPair(entitlementDir)
+	if err != nil {
+		return withSummary, err
 	}
 
-	if clientKeyPath == "" || clientCertPath == "" {
-		return withSummary, errors.New("no entitlement certificate files found")
+	rhsmCaBundlePath := "/etc/rhsm/ca/redhat-uep.pem"
+	if len(repoIDs) > 0 {
+		return injectRepoSSLOptions(withSummary, repoIDs, map[string]any{
+			"sslclientkey":  clientKeyPath,
+			"sslclientcert": clientCertPath,
+			"sslcacert":     rhsmCaBundlePath,
+		}), nil
 	}
 
-	rhsmCaBundlePath := "/etc/rhsm/ca/redhat-uep.pem"
 	ssl := map[string]any{
 		"client_key":  clientKeyPath,
 		"client_cert": clientCertPath,
@@ -120,6 +310,51 @@ func injectRPMInput(input any, registeredWithRHSM bool) (any, error) {
 	return injectSSLOptions(withSummary, ssl), nil
 }
 
+// Find the entitlement certificate and its key in the entitlement directory.
+// The files are named <serial>.pem and <serial>-key.pem, the certificate and the key are matched
+// by the serial. If there are more entitlements, e.g. left over from a previous registration,
+// the most recently written one is used.
+func findEntitlementPair(entitlementDir string) (string, string, error) {
+	entries, err := os.ReadDir(entitlementDir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("failed to read entitlement directory %s: %w", entitlementDir, err)
+	}
+
+	var certPath, keyPath string
+	var certModTime time.Time
+	pairs := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".pem") || strings.HasSuffix(name, "-key.pem") {
+			continue
+		}
+		serial := strings.TrimSuffix(name, ".pem")
+		candidateKeyPath := filepath.Join(entitlementDir, serial+"-key.pem")
+		if !fileExists(candidateKeyPath) {
+			log.Debugf("Skipping entitlement certificate %s without a key", name)
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return "", "", err
+		}
+		pairs++
+		if certPath == "" || info.ModTime().After(certModTime) {
+			certPath = filepath.Join(entitlementDir, name)
+			keyPath = candidateKeyPath
+			certModTime = info.ModTime()
+		}
+	}
+
+	if pairs == 0 {
+		return "", "", fmt.Errorf("no entitlement certificate and key pair found in %s", entitlementDir)
+	}
+	if pairs > 1 {
+		log.Warnf("Found %d entitlements in %s, using the most recent one: %s", pairs, entitlementDir, certPath)
+	}
+	return certPath, keyPath, nil
+}
+
 // Inject a flag to enable RPM summary in the SBOM.
 func injectSummaryInSBOMField(input any) any {
 	switch data := input.(type) {
@@ -182,7 +417,61 @@ func injectSSLOptions(input any, ssl map[string]any) any {
 	return input
 }
 
-func cpFile(sourcePath, destinationPath string) error {
+// Inject dnf SSL options for the given repository ids of all RPM packages.
+// Options already set for a repository take precedence, the rest of the RPM options are kept as they are.
+func injectRepoSSLOptions(input any, repoIDs []string, ssl map[string]any) any {
+	switch data := input.(type) {
+	case []any:
+		// Array format: [{"type": "rpm"}]
+		for i, item := range data {
+			data[i] = injectRepoSSLOptions(item, repoIDs, ssl)
+		}
+		return data
+
+	case map[string]any:
+		// Object format with "packages" field: {"packages": [{"type": "rpm"}]}
+		if packages, ok := data["packages"].([]any); ok {
+			for i, item := range packages {
+				packages[i] = injectRepoSSLOptions(item, repoIDs, ssl)
+			}
+			return data
+		}
+
+		// Object format with "type" field: {"type": "rpm"}
+		if typeValue, ok := data["type"]; ok && typeValue == "rpm" {
+			options, ok := data["options"].(map[string]any)
+			if !ok {
+				options = map[string]any{}
+				data["options"] = options
+			}
+			dnf, ok := options["dnf"].(map[string]any)
+			if !ok {
+				dnf = map[string]any{}
+				options["dnf"] = dnf
+			}
+			for _, repoID := range repoIDs {
+				repoOptions, ok := dnf[repoID].(map[string]any)
+				if !ok {
+					repoOptions = map[string]any{}
+					dnf[repoID] = repoOptions
+				}
+				for key, value := range ssl {
+					if _, exists := repoOptions[key]; !exists {
+						repoOptions[key] = value
+					}
+				}
+			}
+			return data
+		}
+	}
+	return input
+}
+
+func cpFile(sourcePath, destinationPath string, sideEffects *common.SideEffects) error {
+	return cpFileWithMode(sourcePath, destinationPath, readOnlyFileMode, sideEffects)
+}
+
+func cpFileWithMode(sourcePath, destinationPath string, mode os.FileMode, sideEffects *common.SideEffects) error {
 	if err := os.MkdirAll(filepath.Dir(destinationPath), 0755); err != nil { //nolint:gosec // G703: path from controlled prefetch directory
 		return err
 	}
@@ -192,7 +481,14 @@ func cpFile(sourcePath, destinationPath string) error {
 		return err
 	}
 
-	return os.WriteFile(destinationPath, data, readOnlyFileMode) //nolint:gosec // G703: path from controlled prefetch directory
+	if err := sideEffects.RecordWrite(destinationPath); err != nil {
+		return err
+	}
+	if err := os.WriteFile(destinationPath, data, mode); err != nil { //nolint:gosec // G703: path from controlled prefetch directory
+		return err
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(destinationPath, mode) //nolint:gosec // G703: path from controlled prefetch directory
 }
 
 func fileExists(path string) bool {
@@ -203,26 +499,49 @@ func fileExists(path string) bool {
 	return err == nil && info.Mode().IsRegular()
 }
 
-// TODO: This will be shared with the git-clone command.
 // Copy git credentials and config files from the workspace to the home directory.
-func setupGitBasicAuth(authDir, sourceDir string) error {
+// The git-clone command has its own variant (gitclone.setupBasicAuth) that keeps the
+// credentials out of HOME, here they go to HOME where the git processes run by Hermeto find them.
+// Besides the basic auth formats, an SSH private key (id_rsa, optionally with known_hosts) is accepted,
+// both are set up if the workspace has both.
+func setupGitBasicAuth(authDir, sourceDir string, sideEffects *common.SideEffects) error {
 	if authDir == "" {
 		return nil
 	}
 
 	home := os.Getenv("HOME")
 
+	basicAuth, err := setupGitCredentials(authDir, sourceDir, home, sideEffects)
+	if err != nil {
+		return err
+	}
+
+	sshAuth := fileExists(filepath.Join(authDir, "id_rsa"))
+	if sshAuth {
+		if err := setupGitSSHAuth(authDir, home, sideEffects); err != nil {
+			return err
+		}
+	}
+
+	if !basicAuth && !sshAuth {
+		return errors.New("unknown git auth workspace format: expected .git-credentials/.gitconfig, username/password or id_rsa files")
+	}
+	return nil
+}
+
+// Set up the basic auth formats of the workspace, returns false if the workspace has none of them.
+func setupGitCredentials(authDir, sourceDir, home string, sideEffects *common.SideEffects) (bool, error) {
 	gitCredentialsPath := filepath.Join(authDir, ".git-credentials")
 	gitConfigPath := filepath.Join(authDir, ".gitconfig")
 
 	if fileExists(gitCredentialsPath) && fileExists(gitConfigPath) {
-		if err := cpFile(gitCredentialsPath, filepath.Join(home, ".git-credentials")); err != nil {
-			return err
+		if err := cpFile(gitCredentialsPath, filepath.Join(home, ".git-credentials"), sideEffects); err != nil {
+			return false, err
 		}
-		if err := cpFile(gitConfigPath, filepath.Join(home, ".gitconfig")); err != nil {
-			return err
+		if err := cpFile(gitConfigPath, filepath.Join(home, ".gitconfig"), sideEffects); err != nil {
+			return false, err
 		}
-		return nil
+		return true, nil
 	}
 
 	usernamePath := filepath.Join(authDir, "username")
@@ -231,33 +550,188 @@ func setupGitBasicAuth(authDir, sourceDir string) error {
 	if fileExists(usernamePath) && fileExists(passwordPath) {
 		rawUsername, err := os.ReadFile(usernamePath) //nolint:gosec // auth file from controlled workspace
 		if err != nil {
-			return err
+			return false, err
 		}
 		rawPassword, err := os.ReadFile(passwordPath) //nolint:gosec // auth file from controlled workspace
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		username := strings.TrimSpace(string(rawUsername))
 		password := strings.TrimSpace(string(rawPassword))
+		logger.RegisterSecret(password)
 		hostname, err := getHostnameFromRemoteOriginURL(sourceDir)
 		if err != nil {
-			return err
+			return false, err
 		}
 
+		if err := sideEffects.RecordWrite(filepath.Join(home, ".git-credentials")); err != nil {
+			return false, err
+		}
 		gitCredentialsContent := fmt.Sprintf("https://%s:%s@%s", username, password, hostname)
 		if err := os.WriteFile(filepath.Join(home, ".git-credentials"), []byte(gitCredentialsContent), readOnlyFileMode); err != nil { //nolint:gosec // G703: writing git credentials to HOME
-			return err
+			return false, err
+		}
+		if err := sideEffects.RecordWrite(filepath.Join(home, ".gitconfig")); err != nil {
+			return false, err
 		}
 		gitConfigContent := fmt.Sprintf("[credential \"https://%s\"]\nhelper = store", hostname)
 		if err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte(gitConfigContent), readOnlyFileMode); err != nil { //nolint:gosec // G703: writing git config to HOME
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Install the SSH private key (and known_hosts if present) of the workspace in ~/.ssh
+// and make git use them with core.sshCommand, set in the existing ~/.gitconfig if any.
+// Unlike gitclone.setupSSH, the SSH command is configured in ~/.gitconfig instead of GIT_SSH_COMMAND,
+// because git is run by Hermeto, possibly in a container sharing only the home directory.
+func setupGitSSHAuth(authDir, home string, sideEffects *common.SideEffects) error {
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(sshDir, 0700); err != nil {
+		return err
+	}
+
+	keyPath := filepath.Join(sshDir, "id_rsa")
+	if err := cpFileWithMode(filepath.Join(authDir, "id_rsa"), keyPath, 0600, sideEffects); err != nil {
+		return err
+	}
+	sshCommand := fmt.Sprintf(`ssh -i "%s" -o IdentitiesOnly=yes`, keyPath)
+
+	if fileExists(filepath.Join(authDir, "known_hosts")) {
+		knownHostsPath := filepath.Join(sshDir, "known_hosts")
+		if err := cpFileWithMode(filepath.Join(authDir, "known_hosts"), knownHostsPath, 0644, sideEffects); err != nil {
 			return err
 		}
+		sshCommand += fmt.Sprintf(` -o UserKnownHostsFile="%s" -o StrictHostKeyChecking=yes`, knownHostsPath)
+	} else {
+		logger.Logger.Warn("No known_hosts in the git auth directory, the host keys of the git servers must be known to ssh already")
+	}
+
+	gitConfigPath := filepath.Join(home, ".gitconfig")
+	if err := sideEffects.RecordWrite(gitConfigPath); err != nil {
+		return err
+	}
+	gitConfig := cliwrappers.Cmd{Name: "git", Args: []string{"config", "--file", gitConfigPath, "core.sshCommand", sshCommand}}
+	if _, stderr, _, err := cliwrappers.NewCliExecutor().Execute(gitConfig); err != nil {
+		return fmt.Errorf("failed to set core.sshCommand in %s: %w: %s", gitConfigPath, err, stderr)
+	}
+
+	logger.Logger.Debugf("Git SSH auth configured (core.sshCommand=%s)", sshCommand)
+	return nil
+}
+
+// Select how to run Hermeto. The command is split on whitespace, e.g. "python3 -m hermeto".
+func getHermetoLauncher(command string, image string) (cliwrappers.HermetoLauncher, error) {
+	if command != "" && image != "" {
+		return cliwrappers.HermetoLauncher{}, errors.New("--hermeto-command and --hermeto-image are mutually exclusive")
+	}
+	return cliwrappers.HermetoLauncher{Command: strings.Fields(command), Image: image}, nil
+}
+
+// Environment variables for the Go commands run by Hermeto to fetch private modules.
+// GOPRIVATE implies GONOPROXY and GONOSUMDB for the matching modules.
+func getGoPrivateEnv(goPrivate string, noSumCheck bool) []string {
+	var env []string
+	if goPrivate != "" {
+		env = append(env, "GOPRIVATE="+goPrivate)
+	}
+	if noSumCheck {
+		env = append(env, "GONOSUMCHECK=1", "GONOSUMDB=*")
+	}
+	return env
+}
+
+// Make the git credentials usable for private Go modules.
+//
+// Before cloning a module with git, Go resolves the repository root with a plain HTTPS request
+// (https://<module path>?go-get=1). That request doesn't use git credential helpers, only ~/.netrc.
+// Without credentials, GitLab answers with a wrong repository root for modules in subgroups
+// (gitlab.com/group/subgroup/repo), so the .netrc file is generated from ~/.git-credentials.
+func setupGoPrivateAuth(goPrivate string, sideEffects *common.SideEffects) error {
+	home := os.Getenv("HOME")
+
+	credentials, err := readGitCredentials(filepath.Join(home, ".git-credentials"))
+	if err != nil {
+		return err
+	}
+
+	// Verify that every private module pattern is covered by some credentials.
+	for _, pattern := range strings.Split(goPrivate, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		patternHost, _, _ := strings.Cut(pattern, "/")
+		if !slices.ContainsFunc(credentials, func(c *url.URL) bool {
+			matched, _ := path.Match(patternHost, c.Hostname())
+			return matched
+		}) {
+			log.Warnf("No git credentials for %s, fetching the matching private Go modules will likely fail", pattern)
+		}
+	}
 
+	if len(credentials) == 0 {
 		return nil
 	}
 
-	return errors.New("unknown git basic auth workspace format")
+	netrcPath := filepath.Join(home, ".netrc")
+	existing, err := os.ReadFile(netrcPath) //nolint:gosec // .netrc in HOME
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var netrc strings.Builder
+	netrc.Write(existing)
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		netrc.WriteString("\n")
+	}
+	for _, c := range credentials {
+		password, _ := c.User.Password()
+		fmt.Fprintf(&netrc, "machine %s login %s password %s\n", c.Hostname(), c.User.Username(), password)
+	}
+
+	if err := sideEffects.RecordWrite(netrcPath); err != nil {
+		return err
+	}
+	// The file may be read-only if it was written before, replace it
+	if err := os.Remove(netrcPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(netrcPath, []byte(netrc.String()), 0600) //nolint:gosec // G703: writing .netrc to HOME
+}
+
+// Parse the git credentials store file, return the entries that have a username and password.
+func readGitCredentials(credentialsPath string) ([]*url.URL, error) {
+	content, err := os.ReadFile(credentialsPath) //nolint:gosec // .git-credentials in HOME
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var credentials []*url.URL
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parsed, err := url.Parse(line)
+		if err != nil || parsed.User == nil || parsed.Hostname() == "" {
+			log.Debug("Skipping unparsable line in git credentials")
+			continue
+		}
+		if _, hasPassword := parsed.User.Password(); !hasPassword {
+			continue
+		}
+		credentials = append(credentials, parsed)
+	}
+	return credentials, nil
 }
 
 // Parse the hostname from the git remote origin URL.
@@ -278,7 +752,7 @@ func getHostnameFromRemoteOriginURL(sourceDir string) (string, error) {
 
 // Temporarily drop Go proxy URL.
 // https://github.com/hermetoproject/hermeto/issues/577
-func dropGoProxyFrom(configFile string) error {
+func dropGoProxyFrom(configFile string, sideEffects *common.SideEffects) error {
 	if configFile == "" {
 		return nil
 	}
@@ -319,5 +793,8 @@ func dropGoProxyFrom(configFile string) error {
 
 	result := strings.Join(modifiedConfigFileContent, "\n")
 	log.Debugf("Using modified config file content:\n%s", result)
+	if err := sideEffects.RecordWrite(configFile); err != nil {
+		return err
+	}
 	return os.WriteFile(configFile, []byte(result), readOnlyFileMode) //nolint:gosec // G703: configFile path from controlled input
 }
@@ -0,0 +1,279 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var PruneTagsParamsConfig = map[string]common.Parameter{
+	"repository": {
+		Name:       "repository",
+		ShortName:  "r",
+		EnvVarName: "KBC_PRUNE_TAGS_REPOSITORY",
+		TypeKind:   reflect.String,
+		Usage:      "Repository to delete the tags from, without a tag or digest, e.g. quay.io/org/app. Required.",
+		Required:   true,
+	},
+	"tag-pattern": {
+		Name:       "tag-pattern",
+		ShortName:  "p",
+		EnvVarName: "KBC_PRUNE_TAGS_TAG_PATTERN",
+		TypeKind:   reflect.String,
+		Usage:      "Shell glob pattern of the tags to delete, e.g. 'pr-*' or 'on-pr-*-amd64'. Required.",
+		Required:   true,
+	},
+	"older-than": {
+		Name:         "older-than",
+		EnvVarName:   "KBC_PRUNE_TAGS_OLDER_THAN",
+		TypeKind:     reflect.String,
+		DefaultValue: "",
+		Usage: "Only delete the tags of images created longer ago than this (e.g. 1h, 2d, 3w). " +
+			"Tags of images without a creation time are kept. All the matching tags are deleted if empty.",
+	},
+	"dry-run": {
+		Name:         "dry-run",
+		EnvVarName:   "KBC_PRUNE_TAGS_DRY_RUN",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Log the tags that would be deleted, without deleting them.",
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_PRUNE_TAGS_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Require HTTPS and verify certificates when accessing the registry.",
+	},
+	"cert-dir": {
+		Name:       "cert-dir",
+		EnvVarName: "KBC_PRUNE_TAGS_CERT_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Directory with the certificates (*.crt CA certificates, *.cert and *.key client certificates) for accessing the registry.",
+	},
+}
+
+type PruneTagsParams struct {
+	Repository string `paramName:"repository"`
+	TagPattern string `paramName:"tag-pattern"`
+	OlderThan  string `paramName:"older-than"`
+	DryRun     bool   `paramName:"dry-run"`
+	TLSVerify  bool   `paramName:"tls-verify"`
+	CertDir    string `paramName:"cert-dir"`
+}
+
+type PruneTagsCliWrappers struct {
+	SkopeoCli cliWrappers.SkopeoCliInterface
+}
+
+type PruneTagsResults struct {
+	// Deleted tags, or the tags that would be deleted with --dry-run
+	DeletedTags []string `json:"deleted_tags"`
+	// Matching tags kept because the creation time of their image is unknown
+	SkippedTags []string `json:"skipped_tags,omitempty"`
+	// No tags were deleted, set with --dry-run
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+type PruneTags struct {
+	Params        *PruneTagsParams
+	CliWrappers   PruneTagsCliWrappers
+	Results       PruneTagsResults
+	ResultsWriter common.ResultsWriterInterface
+
+	olderThan time.Duration
+	now       func() time.Time
+}
+
+func NewPruneTags(cmd *cobra.Command) (*PruneTags, error) {
+	pruneTags := &PruneTags{now: time.Now}
+
+	params := &PruneTagsParams{}
+	if err := common.ParseParameters(cmd, PruneTagsParamsConfig, params); err != nil {
+		return nil, err
+	}
+	pruneTags.Params = params
+
+	if err := pruneTags.initCliWrappers(); err != nil {
+		return nil, err
+	}
+
+	pruneTags.ResultsWriter = common.NewResultsWriter()
+
+	return pruneTags, nil
+}
+
+func (c *PruneTags) initCliWrappers() error {
+	executor := cliWrappers.NewCliExecutor()
+
+	skopeoCli, err := cliWrappers.NewSkopeoCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.SkopeoCli = skopeoCli
+	return nil
+}
+
+// Run executes the command logic.
+func (c *PruneTags) Run() error {
+	common.LogParameters(PruneTagsParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	tags, err := c.CliWrappers.SkopeoCli.ListTags(&cliWrappers.SkopeoListTagsArgs{
+		Repository: c.Params.Repository,
+		RetryTimes: 3,
+		TLSVerify:  &c.Params.TLSVerify,
+		CertDir:    c.Params.CertDir,
+	})
+	if err != nil {
+		return fmt.Errorf("listing tags of %s: %w", c.Params.Repository, err)
+	}
+
+	tagsToDelete, err := c.selectTags(tags)
+	if err != nil {
+		return err
+	}
+
+	if c.Params.DryRun {
+		for _, tag := range tagsToDelete {
+			l.Logger.Infof("Would delete %s:%s", c.Params.Repository, tag)
+		}
+		c.Results.DryRun = true
+	} else {
+		if err := c.deleteTags(tagsToDelete); err != nil {
+			return err
+		}
+	}
+
+	c.Results.DeletedTags = tagsToDelete
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// selectTags returns the tags matching --tag-pattern, of images older than --older-than if set.
+func (c *PruneTags) selectTags(tags []string) ([]string, error) {
+	selected := []string{}
+	for _, tag := range tags {
+		// The pattern is validated, matching can't fail
+		if matched, _ := path.Match(c.Params.TagPattern, tag); !matched {
+			continue
+		}
+		if c.olderThan == 0 {
+			selected = append(selected, tag)
+			continue
+		}
+
+		created, err := c.imageCreated(tag)
+		if err != nil {
+			return nil, err
+		}
+		if created == nil {
+			l.Logger.Warnf("Keeping tag %s, the creation time of its image is unknown", tag)
+			c.Results.SkippedTags = append(c.Results.SkippedTags, tag)
+			continue
+		}
+		if c.now().Sub(*created) > c.olderThan {
+			selected = append(selected, tag)
+		} else {
+			l.Logger.Debugf("Keeping tag %s, its image was created at %s", tag, created.Format(time.RFC3339))
+		}
+	}
+	slices.Sort(selected)
+	return selected, nil
+}
+
+// imageCreated returns the creation time of the image the tag points to, nil if unknown.
+func (c *PruneTags) imageCreated(tag string) (*time.Time, error) {
+	inspectOutput, err := c.CliWrappers.SkopeoCli.Inspect(&cliWrappers.SkopeoInspectArgs{
+		ImageRef:   c.Params.Repository + ":" + tag,
+		RetryTimes: 3,
+		NoTags:     true,
+		TLSVerify:  &c.Params.TLSVerify,
+		CertDir:    c.Params.CertDir,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("inspecting tag %s: %w", tag, err)
+	}
+
+	var inspect struct {
+		Created *time.Time `json:"Created"`
+	}
+	if err := json.Unmarshal([]byte(inspectOutput), &inspect); err != nil {
+		return nil, fmt.Errorf("parsing inspect output of tag %s: %w", tag, err)
+	}
+	if inspect.Created == nil || inspect.Created.IsZero() {
+		return nil, nil
+	}
+	return inspect.Created, nil
+}
+
+func (c *PruneTags) deleteTags(tags []string) error {
+	for _, tag := range tags {
+		imageRef := c.Params.Repository + ":" + tag
+		l.Logger.Infof("Deleting %s", imageRef)
+		err := c.CliWrappers.SkopeoCli.Delete(&cliWrappers.SkopeoDeleteArgs{
+			ImageRef:   imageRef,
+			RetryTimes: 3,
+			TLSVerify:  &c.Params.TLSVerify,
+			CertDir:    c.Params.CertDir,
+		})
+		if cliWrappers.IsImageNotFound(err) {
+			// Deleted with a previous tag pointing to the same manifest
+			l.Logger.Infof("Tag %s is already gone", tag)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("deleting tag %s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+func (c *PruneTags) validateParams() error {
+	ref, err := common.ParseReference(c.Params.Repository)
+	if err != nil {
+		return fmt.Errorf("repository '%s' is invalid: %w", c.Params.Repository, err)
+	}
+	if !ref.IsNameOnly() {
+		return fmt.Errorf("repository '%s' must not have a tag or digest", c.Params.Repository)
+	}
+
+	if _, err := path.Match(c.Params.TagPattern, ""); err != nil {
+		return fmt.Errorf("tag pattern '%s' is invalid: %w", c.Params.TagPattern, err)
+	}
+
+	if c.Params.OlderThan != "" {
+		olderThan, err := parseQuayExpiresAfter(c.Params.OlderThan)
+		if err != nil {
+			return fmt.Errorf("age '%s' is invalid: %w", c.Params.OlderThan, err)
+		}
+		c.olderThan = olderThan
+	}
+
+	if c.Params.TagPattern == "*" && c.olderThan == 0 {
+		return errors.New("tag pattern '*' deletes all the tags of the repository, set --older-than or a narrower pattern")
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_PruneTags_validateParams(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        PruneTagsParams
+		errExpected   string
+		wantOlderThan time.Duration
+	}{
+		{
+			name:   "should accept repository and pattern",
+			params: PruneTagsParams{Repository: "quay.io/org/app", TagPattern: "on-pr-*"},
+		},
+		{
+			name:          "should parse the age",
+			params:        PruneTagsParams{Repository: "quay.io/org/app", TagPattern: "*", OlderThan: "2d"},
+			wantOlderThan: 48 * time.Hour,
+		},
+		{
+			name:        "should fail if repository has a tag",
+			params:      PruneTagsParams{Repository: "quay.io/org/app:v1", TagPattern: "on-pr-*"},
+			errExpected: "must not have a tag or digest",
+		},
+		{
+			name:        "should fail if repository is invalid",
+			params:      PruneTagsParams{Repository: "quay.io/Org/App", TagPattern: "on-pr-*"},
+			errExpected: "repository 'quay.io/Org/App' is invalid",
+		},
+		{
+			name:        "should fail if pattern is invalid",
+			params:      PruneTagsParams{Repository: "quay.io/org/app", TagPattern: "on-pr-["},
+			errExpected: "tag pattern 'on-pr-[' is invalid",
+		},
+		{
+			name:        "should fail if age is invalid",
+			params:      PruneTagsParams{Repository: "quay.io/org/app", TagPattern: "on-pr-*", OlderThan: "1y"},
+			errExpected: "age '1y' is invalid",
+		},
+		{
+			name:        "should fail if all tags would be deleted",
+			params:      PruneTagsParams{Repository: "quay.io/org/app", TagPattern: "*"},
+			errExpected: "deletes all the tags",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &PruneTags{Params: &tc.params}
+
+			err := c.validateParams()
+
+			if tc.errExpected != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.errExpected)))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(c.olderThan).To(Equal(tc.wantOlderThan))
+			}
+		})
+	}
+}
+
+func Test_PruneTags_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	created := map[string]string{
+		"on-pr-old":     `{"Created": "2025-06-01T00:00:00Z"}`,
+		"on-pr-new":     `{"Created": "2025-06-15T00:00:00Z"}`,
+		"on-pr-unknown": `{"Created": null}`,
+	}
+
+	var _mockSkopeoCli *mockSkopeoCli
+	var _mockResultsWriter *mockResultsWriter
+	var c *PruneTags
+	var deleted []string
+	beforeEach := func() {
+		deleted = nil
+		_mockSkopeoCli = &mockSkopeoCli{
+			ListTagsFunc: func(args *cliwrappers.SkopeoListTagsArgs) ([]string, error) {
+				g.Expect(args.Repository).To(Equal("quay.io/org/app"))
+				return []string{"v1", "on-pr-old", "on-pr-new", "on-pr-unknown", "latest"}, nil
+			},
+			InspectFunc: func(args *cliwrappers.SkopeoInspectArgs) (string, error) {
+				g.Expect(args.NoTags).To(BeTrue())
+				for tag, output := range created {
+					if args.ImageRef == "quay.io/org/app:"+tag {
+						return output, nil
+					}
+				}
+				return "", fmt.Errorf("unexpected inspect of %s", args.ImageRef)
+			},
+			DeleteFunc: func(args *cliwrappers.SkopeoDeleteArgs) error {
+				deleted = append(deleted, args.ImageRef)
+				return nil
+			},
+		}
+		_mockResultsWriter = &mockResultsWriter{}
+		c = &PruneTags{
+			CliWrappers: PruneTagsCliWrappers{SkopeoCli: _mockSkopeoCli},
+			Params: &PruneTagsParams{
+				Repository: "quay.io/org/app",
+				TagPattern: "on-pr-*",
+				TLSVerify:  true,
+			},
+			ResultsWriter: _mockResultsWriter,
+			now:           func() time.Time { return now },
+		}
+	}
+
+	t.Run("should delete all matching tags", func(t *testing.T) {
+		beforeEach()
+		var results PruneTagsResults
+		_mockResultsWriter.CreateResultJsonFunc = func(result any) (string, error) {
+			results = result.(PruneTagsResults)
+			return "", nil
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(deleted).To(Equal([]string{"quay.io/org/app:on-pr-new", "quay.io/org/app:on-pr-old", "quay.io/org/app:on-pr-unknown"}))
+		g.Expect(results.DeletedTags).To(Equal([]string{"on-pr-new", "on-pr-old", "on-pr-unknown"}))
+		g.Expect(results.DryRun).To(BeFalse())
+	})
+
+	t.Run("should delete only tags of old images", func(t *testing.T) {
+		beforeEach()
+		c.Params.OlderThan = "1w"
+		var results PruneTagsResults
+		_mockResultsWriter.CreateResultJsonFunc = func(result any) (string, error) {
+			results = result.(PruneTagsResults)
+			return "", nil
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(deleted).To(Equal([]string{"quay.io/org/app:on-pr-old"}))
+		g.Expect(results.DeletedTags).To(Equal([]string{"on-pr-old"}))
+		g.Expect(results.SkippedTags).To(Equal([]string{"on-pr-unknown"}))
+	})
+
+	t.Run("should not delete tags in dry run", func(t *testing.T) {
+		beforeEach()
+		c.Params.DryRun = true
+		var results PruneTagsResults
+		_mockResultsWriter.CreateResultJsonFunc = func(result any) (string, error) {
+			results = result.(PruneTagsResults)
+			return "", nil
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(deleted).To(BeEmpty())
+		g.Expect(results.DeletedTags).To(HaveLen(3))
+		g.Expect(results.DryRun).To(BeTrue())
+	})
+
+	t.Run("should ignore tags already deleted with another tag", func(t *testing.T) {
+		beforeEach()
+		_mockSkopeoCli.DeleteFunc = func(args *cliwrappers.SkopeoDeleteArgs) error {
+			deleted = append(deleted, args.ImageRef)
+			if args.ImageRef == "quay.io/org/app:on-pr-old" {
+				return errors.New("exit status 1: manifest unknown")
+			}
+			return nil
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+		g.Expect(deleted).To(HaveLen(3))
+	})
+
+	t.Run("should fail if deleting a tag fails", func(t *testing.T) {
+		beforeEach()
+		_mockSkopeoCli.DeleteFunc = func(args *cliwrappers.SkopeoDeleteArgs) error {
+			return errors.New("exit status 1: unauthorized")
+		}
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("deleting tag on-pr-new")))
+	})
+
+	t.Run("should fail if listing tags fails", func(t *testing.T) {
+		beforeEach()
+		_mockSkopeoCli.ListTagsFunc = func(args *cliwrappers.SkopeoListTagsArgs) ([]string, error) {
+			return nil, errors.New("exit status 1: unauthorized")
+		}
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("listing tags of quay.io/org/app")))
+	})
+}
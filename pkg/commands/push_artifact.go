@@ -0,0 +1,294 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var PushArtifactParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_PUSH_ARTIFACT_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Repository to push the artifact to, optionally with the tag of the artifact.",
+		Required:   true,
+	},
+	"files": {
+		Name:       "files",
+		ShortName:  "f",
+		EnvVarName: "KBC_PUSH_ARTIFACT_FILES",
+		TypeKind:   reflect.Slice,
+		Usage:      "Files or directories to push, each one is a layer of the artifact. Optionally with a media type: path:mediatype.",
+		Required:   true,
+	},
+	"artifact-type": {
+		Name:       "artifact-type",
+		ShortName:  "a",
+		EnvVarName: "KBC_PUSH_ARTIFACT_ARTIFACT_TYPE",
+		TypeKind:   reflect.String,
+		Usage:      "Artifact type of the artifact image.",
+		Required:   true,
+	},
+	"annotations": {
+		Name:       "annotations",
+		EnvVarName: "KBC_PUSH_ARTIFACT_ANNOTATIONS",
+		TypeKind:   reflect.Slice,
+		Usage:      "Annotations of the artifact manifest in the key=value format.",
+	},
+	"tag": {
+		Name:       "tag",
+		ShortName:  "t",
+		EnvVarName: "KBC_PUSH_ARTIFACT_TAG",
+		TypeKind:   reflect.String,
+		Usage:      "Tag of the artifact image.",
+	},
+	"tag-suffix": {
+		Name:       "tag-suffix",
+		EnvVarName: "KBC_PUSH_ARTIFACT_TAG_SUFFIX",
+		TypeKind:   reflect.String,
+		Usage:      "Suffix to construct the artifact image tag from the --subject digest: sha256-<digest><suffix>.",
+	},
+	"subject": {
+		Name:       "subject",
+		ShortName:  "s",
+		EnvVarName: "KBC_PUSH_ARTIFACT_SUBJECT",
+		TypeKind:   reflect.String,
+		Usage:      "Digest of the image in the --image-url repository the artifact belongs to. Without a tag, the artifact is attached to the image as an OCI 1.1 referrer.",
+	},
+	"result-path-image-ref": {
+		Name:       "result-path-image-ref",
+		ShortName:  "r",
+		EnvVarName: "KBC_PUSH_ARTIFACT_RESULT_PATH_IMAGE_REF",
+		TypeKind:   reflect.String,
+		Usage:      "Write digested image reference of the pushed artifact image into this file.",
+	},
+}
+
+type PushArtifactParams struct {
+	ImageUrl           string   `paramName:"image-url"`
+	Files              []string `paramName:"files"`
+	ArtifactType       string   `paramName:"artifact-type"`
+	Annotations        []string `paramName:"annotations"`
+	Tag                string   `paramName:"tag"`
+	TagSuffix          string   `paramName:"tag-suffix"`
+	Subject            string   `paramName:"subject"`
+	ResultPathImageRef string   `paramName:"result-path-image-ref"`
+}
+
+type PushArtifactResults struct {
+	ImageRef string `json:"image_ref"`
+}
+
+type PushArtifactCliWrappers struct {
+	OrasCli cliwrappers.OrasCliInterface
+}
+
+type PushArtifact struct {
+	Params        *PushArtifactParams
+	CliWrappers   PushArtifactCliWrappers
+	Results       PushArtifactResults
+	ResultsWriter common.ResultsWriterInterface
+
+	imageName string
+	// Empty when the artifact is attached to the subject
+	tag string
+}
+
+func NewPushArtifact(cmd *cobra.Command) (*PushArtifact, error) {
+	params := &PushArtifactParams{}
+	if err := common.ParseParameters(cmd, PushArtifactParamsConfig, params); err != nil {
+		return nil, err
+	}
+	pushArtifact := &PushArtifact{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}
+	if err := pushArtifact.initCliWrappers(); err != nil {
+		return nil, err
+	}
+	return pushArtifact, nil
+}
+
+func (c *PushArtifact) initCliWrappers() error {
+	executor := cliwrappers.NewCliExecutor()
+	orasCli, err := cliwrappers.NewOrasCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.OrasCli = orasCli
+	return nil
+}
+
+func (c *PushArtifact) Run() error {
+	common.LogParameters(PushArtifactParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	files, err := parseArtifactFiles(c.Params.Files)
+	if err != nil {
+		return err
+	}
+	disablePathValidation := slices.ContainsFunc(files, func(f cliwrappers.OrasPushFile) bool {
+		return filepath.IsAbs(f.Path)
+	})
+
+	registryConfig, err := common.WriteOrasRegistryConfig(c.Params.ImageUrl)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(registryConfig); err != nil {
+			l.Logger.Warnf("failed to remove %s: %s", registryConfig, err.Error())
+		}
+	}()
+
+	var stdout string
+	if c.tag == "" {
+		subject := c.imageName + "@" + c.Params.Subject
+		stdout, _, err = c.CliWrappers.OrasCli.Attach(&cliwrappers.OrasAttachArgs{
+			Subject:               subject,
+			Files:                 files,
+			ArtifactType:          c.Params.ArtifactType,
+			RegistryConfig:        registryConfig,
+			Format:                "go-template",
+			Template:              "{{.reference}}",
+			Annotations:           c.Params.Annotations,
+			DisablePathValidation: disablePathValidation,
+		})
+		if err != nil {
+			return fmt.Errorf("error on attaching artifact to %s: %w", subject, err)
+		}
+		l.Logger.Infof("Artifact is attached to %s", subject)
+	} else {
+		stdout, _, err = c.CliWrappers.OrasCli.Push(&cliwrappers.OrasPushArgs{
+			DestinationImage:      c.imageName + ":" + c.tag,
+			Files:                 files,
+			ArtifactType:          c.Params.ArtifactType,
+			RegistryConfig:        registryConfig,
+			Format:                "go-template",
+			Template:              "{{.reference}}",
+			Annotations:           c.Params.Annotations,
+			DisablePathValidation: disablePathValidation,
+		})
+		if err != nil {
+			return fmt.Errorf("error on pushing artifact: %w", err)
+		}
+		l.Logger.Infof("Artifact is pushed to registry with tag: %s", c.tag)
+	}
+
+	c.Results.ImageRef = strings.TrimSpace(stdout)
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	if c.Params.ResultPathImageRef != "" {
+		if err := c.ResultsWriter.WriteResultString(c.Results.ImageRef, c.Params.ResultPathImageRef); err != nil {
+			return fmt.Errorf("error on writing result image ref: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Parse the path[:mediatype] file arguments and check the files exist.
+func parseArtifactFiles(fileArgs []string) ([]cliwrappers.OrasPushFile, error) {
+	files := make([]cliwrappers.OrasPushFile, 0, len(fileArgs))
+	for _, fileArg := range fileArgs {
+		path, mediaType, _ := strings.Cut(fileArg, ":")
+		if path == "" {
+			return nil, fmt.Errorf("file path is empty in '%s'", fileArg)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("cannot push '%s': %w", path, err)
+		}
+		files = append(files, cliwrappers.OrasPushFile{Path: path, MediaType: mediaType})
+	}
+	return files, nil
+}
+
+func (c *PushArtifact) validateParams() error {
+	c.imageName = common.GetImageName(c.Params.ImageUrl)
+	if !common.IsImageNameValid(c.imageName) {
+		return fmt.Errorf("image '%s' is invalid", c.Params.ImageUrl)
+	}
+	if common.GetImageDigest(c.Params.ImageUrl) != "" {
+		return fmt.Errorf("image '%s' must not have a digest, use --subject to refer to an image", c.Params.ImageUrl)
+	}
+
+	if len(c.Params.Files) == 0 {
+		return fmt.Errorf("no files to push")
+	}
+
+	if err := validateAnnotations(c.Params.Annotations); err != nil {
+		return err
+	}
+
+	if c.Params.Subject != "" && !common.IsImageDigestValid(c.Params.Subject) {
+		return fmt.Errorf("subject digest '%s' is invalid", c.Params.Subject)
+	}
+
+	urlTag := strings.TrimPrefix(common.GetImageURL(c.Params.ImageUrl), c.imageName)
+	urlTag = strings.TrimPrefix(urlTag, ":")
+
+	tagOptions := 0
+	for _, option := range []string{urlTag, c.Params.Tag, c.Params.TagSuffix} {
+		if option != "" {
+			tagOptions++
+		}
+	}
+	if tagOptions > 1 {
+		return fmt.Errorf("only one of the --image-url tag, --tag and --tag-suffix can be used")
+	}
+	if c.Params.Subject != "" && (urlTag != "" || c.Params.Tag != "") {
+		return fmt.Errorf("--subject cannot be used with a tag, use --tag-suffix to push the artifact to a tag derived from the subject")
+	}
+
+	switch {
+	case urlTag != "":
+		c.tag = urlTag
+	case c.Params.Tag != "":
+		if !common.IsImageTagValid(c.Params.Tag) {
+			return fmt.Errorf("tag '%s' is invalid", c.Params.Tag)
+		}
+		c.tag = c.Params.Tag
+	case c.Params.TagSuffix != "":
+		if c.Params.Subject == "" {
+			return fmt.Errorf("--tag-suffix requires --subject")
+		}
+		if !regexp.MustCompile(tagSuffixRegex).MatchString(c.Params.TagSuffix) {
+			return fmt.Errorf("tag suffix includes invalid characters or exceeds the max length of 57 characters")
+		}
+		c.tag = strings.Replace(c.Params.Subject, ":", "-", 1) + c.Params.TagSuffix
+	case c.Params.Subject == "":
+		return fmt.Errorf("one of the --image-url tag, --tag, --tag-suffix or --subject is required")
+	}
+
+	return nil
+}
+
+// Check that the annotations are in the key=value format.
+func validateAnnotations(annotations []string) error {
+	for _, annotation := range annotations {
+		if key, _, found := strings.Cut(annotation, "="); !found || key == "" {
+			return fmt.Errorf("annotation '%s' is not in the key=value format", annotation)
+		}
+	}
+	return nil
+}
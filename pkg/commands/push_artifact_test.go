@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_PushArtifact_validateParams(t *testing.T) {
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+
+	testCases := []struct {
+		name          string
+		params        PushArtifactParams
+		expectedTag   string
+		expectedError string
+	}{
+		{
+			name:        "tag from image url",
+			params:      PushArtifactParams{ImageUrl: "quay.io/org/app:report", Files: []string{"a"}},
+			expectedTag: "report",
+		},
+		{
+			name:        "tag",
+			params:      PushArtifactParams{ImageUrl: "quay.io/org/app", Files: []string{"a"}, Tag: "report"},
+			expectedTag: "report",
+		},
+		{
+			name:        "tag suffix",
+			params:      PushArtifactParams{ImageUrl: "quay.io/org/app", Files: []string{"a"}, TagSuffix: ".report", Subject: digest},
+			expectedTag: "sha256-e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b.report",
+		},
+		{
+			name:        "subject only",
+			params:      PushArtifactParams{ImageUrl: "quay.io/org/app", Files: []string{"a"}, Subject: digest, Annotations: []string{"a=b=c"}},
+			expectedTag: "",
+		},
+		{
+			name:          "invalid image",
+			params:        PushArtifactParams{ImageUrl: "quay.io/org/App", Files: []string{"a"}, Tag: "report"},
+			expectedError: "image 'quay.io/org/App' is invalid",
+		},
+		{
+			name:          "image with digest",
+			params:        PushArtifactParams{ImageUrl: "quay.io/org/app@" + digest, Files: []string{"a"}},
+			expectedError: "must not have a digest",
+		},
+		{
+			name:          "no tag nor subject",
+			params:        PushArtifactParams{ImageUrl: "quay.io/org/app", Files: []string{"a"}},
+			expectedError: "one of the --image-url tag, --tag, --tag-suffix or --subject is required",
+		},
+		{
+			name:          "tag and tag suffix",
+			params:        PushArtifactParams{ImageUrl: "quay.io/org/app:report", Files: []string{"a"}, TagSuffix: ".report", Subject: digest},
+			expectedError: "only one of the --image-url tag, --tag and --tag-suffix can be used",
+		},
+		{
+			name:          "tag and subject",
+			params:        PushArtifactParams{ImageUrl: "quay.io/org/app", Files: []string{"a"}, Tag: "report", Subject: digest},
+			expectedError: "--subject cannot be used with a tag",
+		},
+		{
+			name:          "tag suffix without subject",
+			params:        PushArtifactParams{ImageUrl: "quay.io/org/app", Files: []string{"a"}, TagSuffix: ".report"},
+			expectedError: "--tag-suffix requires --subject",
+		},
+		{
+			name:          "invalid tag",
+			params:        PushArtifactParams{ImageUrl: "quay.io/org/app", Files: []string{"a"}, Tag: "re/port"},
+			expectedError: "tag 're/port' is invalid",
+		},
+		{
+			name:          "invalid subject",
+			params:        PushArtifactParams{ImageUrl: "quay.io/org/app", Files: []string{"a"}, Subject: "sha256:1234"},
+			expectedError: "subject digest 'sha256:1234' is invalid",
+		},
+		{
+			name:          "invalid annotation",
+			params:        PushArtifactParams{ImageUrl: "quay.io/org/app:report", Files: []string{"a"}, Annotations: []string{"=b"}},
+			expectedError: "annotation '=b' is not in the key=value format",
+		},
+		{
+			name:          "no files",
+			params:        PushArtifactParams{ImageUrl: "quay.io/org/app:report"},
+			expectedError: "no files to push",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &PushArtifact{Params: &tc.params}
+
+			err := c.validateParams()
+
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(c.imageName).To(Equal("quay.io/org/app"))
+			g.Expect(c.tag).To(Equal(tc.expectedTag))
+		})
+	}
+}
+
+func Test_PushArtifact_Run(t *testing.T) {
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	const artifactDigest = "sha256:a7c0071906a9c6b654760e44a1fc8226f8268c70848148f19c35b02788b272a5"
+
+	workDir := t.TempDir()
+	t.Setenv("HOME", workDir)
+	os.Mkdir(filepath.Join(workDir, ".docker"), 0755)
+	os.WriteFile(filepath.Join(workDir, ".docker", "config.json"), []byte(`{"auths":{"quay.io":{"auth":"token"}}}`), 0644)
+
+	reportPath := filepath.Join(workDir, "report.json")
+	os.WriteFile(reportPath, []byte(`{}`), 0644)
+	docsPath := filepath.Join(workDir, "docs")
+	os.Mkdir(docsPath, 0755)
+
+	t.Run("should push files to a tag", func(t *testing.T) {
+		g := NewWithT(t)
+		var pushArgs *cliwrappers.OrasPushArgs
+		resultsWriter := &mockResultsWriter{}
+		c := &PushArtifact{
+			Params: &PushArtifactParams{
+				ImageUrl:           "quay.io/org/app",
+				Files:              []string{reportPath + ":application/json", docsPath},
+				ArtifactType:       "application/vnd.org.report",
+				Annotations:        []string{"a=b"},
+				TagSuffix:          ".report",
+				Subject:            digest,
+				ResultPathImageRef: "/results/image-ref",
+			},
+			CliWrappers: PushArtifactCliWrappers{OrasCli: &mockOrasCli{
+				PushFunc: func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+					pushArgs = args
+					return "quay.io/org/app@" + artifactDigest + "\n", "", nil
+				},
+			}},
+			ResultsWriter: resultsWriter,
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(pushArgs.DestinationImage).To(Equal("quay.io/org/app:sha256-e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b.report"))
+		g.Expect(pushArgs.ArtifactType).To(Equal("application/vnd.org.report"))
+		g.Expect(pushArgs.Annotations).To(Equal([]string{"a=b"}))
+		g.Expect(pushArgs.Files).To(Equal([]cliwrappers.OrasPushFile{{Path: reportPath, MediaType: "application/json"}, {Path: docsPath}}))
+		g.Expect(pushArgs.DisablePathValidation).To(BeTrue())
+		g.Expect(pushArgs.RegistryConfig).ToNot(BeEmpty())
+		g.Expect(c.Results.ImageRef).To(Equal("quay.io/org/app@" + artifactDigest))
+		g.Expect(resultsWriter.WrittenResults).To(HaveKeyWithValue("/results/image-ref", "quay.io/org/app@"+artifactDigest))
+	})
+
+	t.Run("should attach files to the subject", func(t *testing.T) {
+		g := NewWithT(t)
+		var attachArgs *cliwrappers.OrasAttachArgs
+		c := &PushArtifact{
+			Params: &PushArtifactParams{
+				ImageUrl:     "quay.io/org/app",
+				Files:        []string{reportPath},
+				ArtifactType: "application/vnd.org.report",
+				Subject:      digest,
+			},
+			CliWrappers: PushArtifactCliWrappers{OrasCli: &mockOrasCli{
+				AttachFunc: func(args *cliwrappers.OrasAttachArgs) (string, string, error) {
+					attachArgs = args
+					return "quay.io/org/app@" + artifactDigest, "", nil
+				},
+				PushFunc: func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+					return "", "", fmt.Errorf("unexpected push")
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(attachArgs.Subject).To(Equal("quay.io/org/app@" + digest))
+		g.Expect(attachArgs.ArtifactType).To(Equal("application/vnd.org.report"))
+		g.Expect(attachArgs.Files).To(Equal([]cliwrappers.OrasPushFile{{Path: reportPath}}))
+		g.Expect(c.Results.ImageRef).To(Equal("quay.io/org/app@" + artifactDigest))
+	})
+
+	t.Run("should fail if a file does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+		c := &PushArtifact{
+			Params: &PushArtifactParams{
+				ImageUrl:     "quay.io/org/app:report",
+				Files:        []string{filepath.Join(workDir, "nonexistent")},
+				ArtifactType: "application/vnd.org.report",
+			},
+			CliWrappers:   PushArtifactCliWrappers{OrasCli: &mockOrasCli{}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("cannot push")))
+	})
+
+	t.Run("should fail if push fails", func(t *testing.T) {
+		g := NewWithT(t)
+		c := &PushArtifact{
+			Params: &PushArtifactParams{
+				ImageUrl:     "quay.io/org/app:report",
+				Files:        []string{reportPath},
+				ArtifactType: "application/vnd.org.report",
+			},
+			CliWrappers: PushArtifactCliWrappers{OrasCli: &mockOrasCli{
+				PushFunc: func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+					return "", "", fmt.Errorf("unauthorized")
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("error on pushing artifact")))
+	})
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
@@ -101,22 +102,59 @@ var PushContainerfileParamsConfig = map[string]common.Parameter{
 		Usage:      "Alternative file name in the artifact image, e.g. Dockerfile.",
 		Required:   false,
 	},
+	"extra-files": {
+		Name:       "extra-files",
+		ShortName:  "e",
+		EnvVarName: "KBC_PUSH_CONTAINERFILE_EXTRA_FILES",
+		TypeKind:   reflect.Slice,
+		Usage: "Glob patterns, relative to the build context, of additional files to push with the Containerfile, " +
+			"e.g. .dockerignore or scripts/*.sh. Each file is a layer titled by its path relative to the source directory.",
+		Required: false,
+	},
+	"annotations": {
+		Name:       "annotations",
+		ShortName:  "",
+		EnvVarName: "KBC_PUSH_CONTAINERFILE_ANNOTATIONS",
+		TypeKind:   reflect.Slice,
+		Usage:      "Annotations of the Containerfile artifact manifest in the key=value format, e.g. org.opencontainers.image.revision=<sha>.",
+		Required:   false,
+	},
+	"proxy": {
+		Name:       "proxy",
+		EnvVarName: "KBC_PUSH_CONTAINERFILE_PROXY",
+		TypeKind:   reflect.String,
+		Usage:      "HTTP(S) proxy for the registries, set as HTTP_PROXY and HTTPS_PROXY. Defaults to the HTTPS_PROXY or HTTP_PROXY environment variable.",
+	},
+	"no-proxy": {
+		Name:       "no-proxy",
+		EnvVarName: "KBC_PUSH_CONTAINERFILE_NO_PROXY",
+		TypeKind:   reflect.String,
+		Usage:      "Hosts to access without the --proxy, set as NO_PROXY. Defaults to the NO_PROXY environment variable.",
+	},
 }
 
 type PushContainerfileParams struct {
-	ImageUrl            string `paramName:"image-url"`
-	ImageDigest         string `paramName:"image-digest"`
-	Containerfile       string `paramName:"containerfile"`
-	Context             string `paramName:"context"`
-	TagSuffix           string `paramName:"tag-suffix"`
-	ArtifactType        string `paramName:"artifact-type"`
-	Source              string `paramName:"source"`
-	ResultPathImageRef  string `paramName:"result-path-image-ref"`
-	AlternativeFilename string `paramName:"alternative-filename"`
+	ImageUrl            string   `paramName:"image-url"`
+	ImageDigest         string   `paramName:"image-digest"`
+	Containerfile       string   `paramName:"containerfile"`
+	Context             string   `paramName:"context"`
+	TagSuffix           string   `paramName:"tag-suffix"`
+	ArtifactType        string   `paramName:"artifact-type"`
+	Source              string   `paramName:"source"`
+	ResultPathImageRef  string   `paramName:"result-path-image-ref"`
+	AlternativeFilename string   `paramName:"alternative-filename"`
+	ExtraFiles          []string `paramName:"extra-files"`
+	Annotations         []string `paramName:"annotations"`
+	Proxy               string   `paramName:"proxy"`
+	NoProxy             string   `paramName:"no-proxy"`
 }
 
 type PushContainerfileResults struct {
 	ImageRef string `json:"image_ref"`
+	// Titles of the layers pushed in addition to the Containerfile
+	AdditionalFiles []string `json:"additional_files,omitempty"`
+	// The effective --proxy and --no-proxy, the proxy password is masked
+	Proxy *common.ProxySettings `json:"proxy,omitempty"`
 }
 
 type PushContainerfileCliWrappers struct {
@@ -164,7 +202,16 @@ func (c *PushContainerfile) Run() error {
 	c.imageName = common.GetImageName(imageUrl)
 
 	if err := c.validateParams(); err != nil {
-		return err
+		return kbcerrors.NewValidationError(err)
+	}
+
+	proxySettings := common.ResolveProxySettings(c.Params.Proxy, c.Params.NoProxy)
+	if proxySettings.IsSet() {
+		if err := proxySettings.Apply(); err != nil {
+			return fmt.Errorf("configuring proxy: %w", err)
+		}
+		redacted := proxySettings.Redacted()
+		c.Results.Proxy = &redacted
 	}
 
 	curDir, err := os.Getwd()
@@ -194,26 +241,18 @@ func (c *PushContainerfile) Run() error {
 
 	l.Logger.Debugf("Got Containerfile: %s", containerfilePath)
 
-	l.Logger.Debugf("Select registry authentication for %s", imageUrl)
-	registryAuth, err := common.SelectRegistryAuthFromDefaultAuthFile(imageUrl)
+	additionalFiles, err := c.collectAdditionalFiles(containerfilePath)
 	if err != nil {
-		return fmt.Errorf("cannot select registry authentication for image %s: %w", imageUrl, err)
+		return fmt.Errorf("collecting additional files: %w", err)
 	}
 
-	registryConfigFile, err := os.CreateTemp("", "oras-push-registry-config-*")
+	registryConfig, err := common.WriteOrasRegistryConfig(imageUrl)
 	if err != nil {
-		return fmt.Errorf("error on creating temporary file for registry config: %w", err)
-	}
-	_, err = fmt.Fprintf(registryConfigFile, `{"auths":{"%s":{"auth":"%s"}}}`, registryAuth.Registry, registryAuth.Token)
-	if err != nil {
-		return fmt.Errorf("error on writing registry config file: %w", err)
-	}
-	if err = registryConfigFile.Close(); err != nil {
-		return fmt.Errorf("error on closing registry config file after write: %w", err)
+		return err
 	}
 	defer func() {
-		if err := os.Remove(registryConfigFile.Name()); err != nil {
-			l.Logger.Warnf("failed to remove %s: %s", registryConfigFile.Name(), err.Error())
+		if err := os.Remove(registryConfig); err != nil {
+			l.Logger.Warnf("failed to remove %s: %s", registryConfig, err.Error())
 		}
 	}()
 
@@ -227,8 +266,11 @@ func (c *PushContainerfile) Run() error {
 	var pushFilename string
 	var workDir string
 
-	if c.Params.AlternativeFilename != "" {
-		pushFilename = filepath.Base(c.Params.AlternativeFilename)
+	if c.Params.AlternativeFilename != "" || len(additionalFiles) > 0 {
+		pushFilename = filepath.Base(absContainerfilePath)
+		if c.Params.AlternativeFilename != "" {
+			pushFilename = filepath.Base(c.Params.AlternativeFilename)
+		}
 		workDir, err = os.MkdirTemp("", "push-containerfile-")
 		if err != nil {
 			return fmt.Errorf("error on creating temporary directory: %w", err)
@@ -245,6 +287,20 @@ func (c *PushContainerfile) Run() error {
 		if err := os.WriteFile(filepath.Join(workDir, pushFilename), content, 0644); err != nil { //nolint:gosec // G703: path from controlled work directory
 			return fmt.Errorf("error on writing file: %w", err)
 		}
+		// The layers are titled by the relative paths oras is given
+		for _, file := range additionalFiles {
+			if file.title == pushFilename {
+				return fmt.Errorf("additional file '%s' has the same name as the Containerfile", file.title)
+			}
+			dst := filepath.Join(workDir, filepath.FromSlash(file.title))
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return err
+			}
+			if err := copyFile(file.path, dst); err != nil {
+				return fmt.Errorf("error on copying %s: %w", file.path, err)
+			}
+			c.Results.AdditionalFiles = append(c.Results.AdditionalFiles, file.title)
+		}
 	} else {
 		pushFilename = filepath.Base(absContainerfilePath)
 		workDir = filepath.Dir(absContainerfilePath)
@@ -261,11 +317,13 @@ func (c *PushContainerfile) Run() error {
 
 	stdout, _, err := c.CliWrappers.OrasCli.Push(&cliwrappers.OrasPushArgs{
 		ArtifactType:     c.Params.ArtifactType,
-		RegistryConfig:   registryConfigFile.Name(),
+		RegistryConfig:   registryConfig,
 		Format:           "go-template",
 		Template:         "{{.reference}}",
 		DestinationImage: fmt.Sprintf("%s:%s", c.imageName, tag),
 		FileName:         pushFilename,
+		Files:            orasPushFiles(c.Results.AdditionalFiles),
+		Annotations:      c.Params.Annotations,
 	})
 	if err != nil {
 		return fmt.Errorf("error on pushing Containerfile %s: %w", containerfilePath, err)
@@ -292,19 +350,20 @@ func (c *PushContainerfile) Run() error {
 	return nil
 }
 
+func orasPushFiles(paths []string) []cliwrappers.OrasPushFile {
+	var files []cliwrappers.OrasPushFile
+	for _, path := range paths {
+		files = append(files, cliwrappers.OrasPushFile{Path: path})
+	}
+	return files
+}
+
 func (c *PushContainerfile) verifyContainerfileIsInSourceDir(containerfilePath string) error {
 	resolvedSource, err := common.ResolvePath(c.Params.Source)
 	if err != nil {
 		return fmt.Errorf("resolving source path: %w", err)
 	}
-	resolvedContainerfile, err := common.ResolvePath(containerfilePath)
-	if err != nil {
-		return fmt.Errorf("resolving containerfile path: %w", err)
-	}
-	if !resolvedContainerfile.IsRelativeTo(resolvedSource) {
-		return fmt.Errorf("'%s' is outside '%s'", containerfilePath, c.Params.Source)
-	}
-	return nil
+	return common.CheckPathInside(resolvedSource, "containerfile", containerfilePath)
 }
 
 func (c *PushContainerfile) generateContainerfileImageTag() string {
@@ -334,5 +393,18 @@ func (c *PushContainerfile) validateParams() error {
 		return fmt.Errorf("alternative file name exceeds 100 characters")
 	}
 
+	for _, pattern := range c.Params.ExtraFiles {
+		if filepath.IsAbs(pattern) {
+			return fmt.Errorf("extra files pattern '%s' must be relative to the build context", pattern)
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid extra files pattern '%s': %w", pattern, err)
+		}
+	}
+
+	if err := validateAnnotations(c.Params.Annotations); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// Containerfiles with the .in suffix are preprocessed by buildah with cpp, they can include other files.
+var containerfileIncludeRegex = regexp.MustCompile(`^\s*#\s*include\s+["<]([^">]+)[">]`)
+
+// A file pushed along with the Containerfile.
+type containerfileArtifactFile struct {
+	path string
+	// The path relative to the source directory, the title of the artifact layer
+	title string
+}
+
+// Collect the files included by the Containerfile and the files matching --extra-files.
+// All the files must be in the source directory, they are titled by their path relative to it.
+func (c *PushContainerfile) collectAdditionalFiles(containerfilePath string) ([]containerfileArtifactFile, error) {
+	resolvedSource, err := common.ResolvePath(c.Params.Source)
+	if err != nil {
+		return nil, fmt.Errorf("resolving source path: %w", err)
+	}
+	resolvedContainerfile, err := common.ResolvePath(containerfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("resolving containerfile path: %w", err)
+	}
+
+	includes, err := containerfileIncludes(containerfilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	contextDir := filepath.Join(c.Params.Source, c.Params.Context)
+	for _, pattern := range c.Params.ExtraFiles {
+		patternMatches, err := filepath.Glob(filepath.Join(contextDir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid extra files pattern '%s': %w", pattern, err)
+		}
+		if len(patternMatches) == 0 {
+			l.Logger.Warnf("No files match the extra files pattern '%s'", pattern)
+		}
+		matches = append(matches, patternMatches...)
+	}
+
+	var files []containerfileArtifactFile
+	seen := map[string]bool{resolvedContainerfile.String(): true}
+	for _, path := range append(includes, matches...) {
+		if err := common.CheckPathInside(resolvedSource, "file", path); err != nil {
+			return nil, err
+		}
+		resolved, err := common.ResolvePath(path)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", path, err)
+		}
+		if seen[resolved.String()] {
+			continue
+		}
+		seen[resolved.String()] = true
+
+		info, err := os.Stat(resolved.String())
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			l.Logger.Debugf("Skipping %s, not a regular file", path)
+			continue
+		}
+
+		title, err := filepath.Rel(resolvedSource.String(), resolved.String())
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, containerfileArtifactFile{path: resolved.String(), title: filepath.ToSlash(title)})
+	}
+	return files, nil
+}
+
+// The files included by the .in Containerfile, recursively. The included paths are relative
+// to the including file.
+func containerfileIncludes(containerfilePath string) ([]string, error) {
+	if !strings.HasSuffix(containerfilePath, ".in") {
+		return nil, nil
+	}
+	return scanIncludes(containerfilePath, map[string]bool{})
+}
+
+func scanIncludes(path string, visited map[string]bool) ([]string, error) {
+	if visited[path] {
+		return nil, nil
+	}
+	visited[path] = true
+
+	file, err := os.Open(path) //nolint:gosec // containerfile path is validated
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var includes []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		match := containerfileIncludeRegex.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		include := filepath.Join(filepath.Dir(path), match[1])
+		if _, err := os.Stat(include); err != nil {
+			return nil, fmt.Errorf("file included by %s: %w", path, err)
+		}
+		includes = append(includes, include)
+		nested, err := scanIncludes(include, visited)
+		if err != nil {
+			return nil, err
+		}
+		includes = append(includes, nested...)
+	}
+	return includes, scanner.Err()
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
 	"github.com/konflux-ci/konflux-build-cli/pkg/common"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+	"github.com/konflux-ci/konflux-build-cli/testutil"
 )
 
 const imageDigest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
@@ -82,6 +83,42 @@ func TestValidateParams(t *testing.T) {
 	})
 }
 
+func TestValidateParams_extraFiles(t *testing.T) {
+	g := NewWithT(t)
+
+	for pattern, errSubstring := range map[string]string{
+		"/etc/passwd": "must be relative to the build context",
+		"scripts/[":   "invalid extra files pattern 'scripts/['",
+	} {
+		cmd := PushContainerfile{
+			Params: &PushContainerfileParams{
+				ImageDigest: imageDigest,
+				TagSuffix:   ".containerfile",
+				ExtraFiles:  []string{pattern},
+			},
+			imageName: "localhost.reg.io/app",
+		}
+		g.Expect(cmd.validateParams()).To(MatchError(ContainSubstring(errSubstring)))
+	}
+}
+
+func TestValidateParams_annotations(t *testing.T) {
+	g := NewWithT(t)
+
+	cmd := PushContainerfile{
+		Params: &PushContainerfileParams{
+			ImageDigest: imageDigest,
+			TagSuffix:   ".containerfile",
+			Annotations: []string{"org.opencontainers.image.revision=abc123"},
+		},
+		imageName: "localhost.reg.io/app",
+	}
+	g.Expect(cmd.validateParams()).To(Succeed())
+
+	cmd.Params.Annotations = append(cmd.Params.Annotations, "=value")
+	g.Expect(cmd.validateParams()).To(MatchError("annotation '=value' is not in the key=value format"))
+}
+
 func TestGenerateContainerfileImageTag(t *testing.T) {
 	cmd := PushContainerfile{
 		Params: &PushContainerfileParams{
@@ -233,6 +270,81 @@ func TestRun(t *testing.T) {
 		}
 	})
 
+	t.Run("should push the included and extra files with the Containerfile", func(t *testing.T) {
+		testutil.WriteFileTree(t, filepath.Join(workDir, "multi"), map[string]string{
+			"app/Containerfile.in":     "#include \"../common/base.in\"\nRUN ./scripts/build.sh\n",
+			"app/.dockerignore":        "*.md\n",
+			"app/scripts/build.sh":     "make\n",
+			"app/scripts/lib/util.sh":  "true\n",
+			"common/base.in":           "#include <versions.h>\nFROM fedora\n",
+			"common/versions.h":        "#define VERSION 1\n",
+			"common/unused.Dockerfile": "FROM scratch\n",
+		})
+
+		var pushedFiles map[string]string
+		orasCli := &mockOrasCli{}
+		orasCli.PushFunc = func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+			g.Expect(args.FileName).Should(Equal("Containerfile.in"))
+			g.Expect(args.Annotations).Should(Equal([]string{"org.opencontainers.image.revision=abc123"}))
+			pushedFiles = map[string]string{}
+			for _, file := range append([]cliwrappers.OrasPushFile{{Path: args.FileName}}, args.Files...) {
+				content, err := os.ReadFile(file.Path)
+				g.Expect(err).ShouldNot(HaveOccurred())
+				pushedFiles[file.Path] = string(content)
+			}
+			return "localhost.reg.io/app@sha256:a7c0071906a9c6b654760e44a1fc8226f8268c70848148f19c35b02788b272a5", "", nil
+		}
+
+		cmd := &PushContainerfile{
+			Params: &PushContainerfileParams{
+				ImageUrl:      "localhost.reg.io/app",
+				ImageDigest:   imageDigest,
+				Source:        "multi",
+				Containerfile: "Containerfile.in",
+				Context:       "app",
+				TagSuffix:     ".containerfile",
+				ExtraFiles:    []string{".dockerignore", "scripts/*"},
+				Annotations:   []string{"org.opencontainers.image.revision=abc123"},
+			},
+			ResultsWriter: &common.ResultsWriter{},
+			CliWrappers:   PushContainerfileCliWrappers{OrasCli: orasCli},
+		}
+
+		err := cmd.Run()
+		g.Expect(err).ShouldNot(HaveOccurred())
+		g.Expect(cmd.Results.AdditionalFiles).Should(Equal([]string{
+			"common/base.in", "common/versions.h", "app/.dockerignore", "app/scripts/build.sh",
+		}))
+		g.Expect(pushedFiles).Should(Equal(map[string]string{
+			"Containerfile.in":     "#include \"../common/base.in\"\nRUN ./scripts/build.sh\n",
+			"common/base.in":       "#include <versions.h>\nFROM fedora\n",
+			"common/versions.h":    "#define VERSION 1\n",
+			"app/.dockerignore":    "*.md\n",
+			"app/scripts/build.sh": "make\n",
+		}))
+	})
+
+	t.Run("should return error when an included file is outside source directory", func(t *testing.T) {
+		testutil.WriteFileTree(t, workDir, map[string]string{
+			"escape/Containerfile.in": "#include \"../source/Containerfile\"\n",
+		})
+
+		cmd := &PushContainerfile{
+			Params: &PushContainerfileParams{
+				ImageUrl:      "localhost.reg.io/app",
+				ImageDigest:   imageDigest,
+				Source:        "escape",
+				Containerfile: "Containerfile.in",
+				Context:       ".",
+				TagSuffix:     ".containerfile",
+			},
+			ResultsWriter: &common.ResultsWriter{},
+		}
+
+		err := cmd.Run()
+		g.Expect(err).Should(MatchError(ContainSubstring("file 'source/Containerfile' resolves to '" + filepath.Join(workDir, "source", "Containerfile") + "', outside of '" + filepath.Join(workDir, "escape") + "'")))
+	})
+
 	t.Run("should return error when containerfile resolves outside source directory", func(t *testing.T) {
 		outsideDir := filepath.Join(workDir, "outside")
 		os.MkdirAll(outsideDir, 0755)
@@ -251,7 +363,7 @@ func TestRun(t *testing.T) {
 		}
 
 		err := cmd.Run()
-		g.Expect(err).Should(MatchError(ContainSubstring("'outside/Containerfile' is outside 'source'")))
+		g.Expect(err).Should(MatchError(ContainSubstring("containerfile 'outside/Containerfile' resolves to '" + filepath.Join(workDir, "outside", "Containerfile") + "', outside of '" + filepath.Join(workDir, "source") + "'")))
 	})
 
 	t.Run("should not push and exits as normal if specified Containerfile is not found", func(t *testing.T) {
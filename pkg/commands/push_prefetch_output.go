@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+const (
+	prefetchOutputArtifactTagSuffix = ".prefetch"
+	prefetchOutputArtifactType      = "application/vnd.konflux.prefetch-output"
+	prefetchOutputTarball           = "prefetch-output.tar.gz"
+	prefetchOutputMediaType         = "application/vnd.konflux.prefetch-output.layer.v1.tar+gzip"
+)
+
+var PushPrefetchOutputParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_PUSH_PREFETCH_OUTPUT_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Repository to push the prefetch output to, optionally with the tag of the artifact.",
+		Required:   true,
+	},
+	"output-dir": {
+		Name:       "output-dir",
+		ShortName:  "o",
+		EnvVarName: "KBC_PUSH_PREFETCH_OUTPUT_OUTPUT_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "The output directory of prefetch-dependencies to push.",
+		Required:   true,
+	},
+	"tag-suffix": {
+		Name:         "tag-suffix",
+		ShortName:    "t",
+		EnvVarName:   "KBC_PUSH_PREFETCH_OUTPUT_TAG_SUFFIX",
+		TypeKind:     reflect.String,
+		DefaultValue: prefetchOutputArtifactTagSuffix,
+		Usage:        "Suffix to construct the artifact tag from the digest of the output tarball, if --image-url has no tag.",
+	},
+	"annotations": {
+		Name:       "annotations",
+		EnvVarName: "KBC_PUSH_PREFETCH_OUTPUT_ANNOTATIONS",
+		TypeKind:   reflect.Slice,
+		Usage:      "Annotations of the artifact manifest in the key=value format.",
+	},
+	"result-path-image-ref": {
+		Name:       "result-path-image-ref",
+		ShortName:  "r",
+		EnvVarName: "KBC_PUSH_PREFETCH_OUTPUT_RESULT_PATH_IMAGE_REF",
+		TypeKind:   reflect.String,
+		Usage:      "Write digested image reference of the pushed artifact into this file.",
+	},
+}
+
+type PushPrefetchOutputParams struct {
+	ImageUrl           string   `paramName:"image-url"`
+	OutputDir          string   `paramName:"output-dir"`
+	TagSuffix          string   `paramName:"tag-suffix"`
+	Annotations        []string `paramName:"annotations"`
+	ResultPathImageRef string   `paramName:"result-path-image-ref"`
+}
+
+type PushPrefetchOutputResults struct {
+	ImageRef string `json:"image_ref"`
+	// Digest of the tarball of the output directory, the only layer of the artifact
+	TarballDigest string `json:"tarball_digest"`
+}
+
+type PushPrefetchOutputCliWrappers struct {
+	OrasCli cliwrappers.OrasCliInterface
+}
+
+type PushPrefetchOutput struct {
+	Params        *PushPrefetchOutputParams
+	CliWrappers   PushPrefetchOutputCliWrappers
+	Results       PushPrefetchOutputResults
+	ResultsWriter common.ResultsWriterInterface
+
+	imageName string
+}
+
+func NewPushPrefetchOutput(cmd *cobra.Command) (*PushPrefetchOutput, error) {
+	params := &PushPrefetchOutputParams{}
+	if err := common.ParseParameters(cmd, PushPrefetchOutputParamsConfig, params); err != nil {
+		return nil, err
+	}
+	pushPrefetchOutput := &PushPrefetchOutput{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}
+	if err := pushPrefetchOutput.initCliWrappers(); err != nil {
+		return nil, err
+	}
+	return pushPrefetchOutput, nil
+}
+
+func (c *PushPrefetchOutput) initCliWrappers() error {
+	executor := cliwrappers.NewCliExecutor()
+	orasCli, err := cliwrappers.NewOrasCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.OrasCli = orasCli
+	return nil
+}
+
+func (c *PushPrefetchOutput) Run() error {
+	common.LogParameters(PushPrefetchOutputParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	workDir, err := os.MkdirTemp("", "push-prefetch-output-")
+	if err != nil {
+		return fmt.Errorf("error on creating temporary directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			l.Logger.Warnf("failed to remove '%s' directory: %s", workDir, err.Error())
+		}
+	}()
+
+	tarballDigest, err := common.WriteDirTarballFile(filepath.Join(workDir, prefetchOutputTarball), c.Params.OutputDir)
+	if err != nil {
+		return fmt.Errorf("error on creating tarball of %s: %w", c.Params.OutputDir, err)
+	}
+	l.Logger.Infof("Created tarball of %s with digest %s", c.Params.OutputDir, tarballDigest)
+
+	tag := strings.TrimPrefix(strings.TrimPrefix(common.GetImageURL(c.Params.ImageUrl), c.imageName), ":")
+	if tag == "" {
+		// The same dependencies are pushed to the same tag
+		tag = strings.Replace(tarballDigest.String(), ":", "-", 1) + c.Params.TagSuffix
+	}
+
+	registryConfig, err := common.WriteOrasRegistryConfig(c.Params.ImageUrl)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(registryConfig); err != nil {
+			l.Logger.Warnf("failed to remove %s: %s", registryConfig, err.Error())
+		}
+	}()
+
+	curDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error getting current directory: %w", err)
+	}
+	// The layer is titled by the relative path oras is given
+	if err := os.Chdir(workDir); err != nil {
+		return fmt.Errorf("error on changing directory to %s: %w", workDir, err)
+	}
+	defer func() {
+		if err := os.Chdir(curDir); err != nil {
+			l.Logger.Warnf("failed to chdir to '%s' directory: %s", curDir, err.Error())
+		}
+	}()
+
+	stdout, _, err := c.CliWrappers.OrasCli.Push(&cliwrappers.OrasPushArgs{
+		DestinationImage: c.imageName + ":" + tag,
+		Files:            []cliwrappers.OrasPushFile{{Path: prefetchOutputTarball, MediaType: prefetchOutputMediaType}},
+		ArtifactType:     prefetchOutputArtifactType,
+		RegistryConfig:   registryConfig,
+		Format:           "go-template",
+		Template:         "{{.reference}}",
+		Annotations:      c.Params.Annotations,
+	})
+	if err != nil {
+		return fmt.Errorf("error on pushing prefetch output: %w", err)
+	}
+	l.Logger.Infof("Prefetch output is pushed to registry with tag: %s", tag)
+
+	c.Results.ImageRef = strings.TrimSpace(stdout)
+	c.Results.TarballDigest = tarballDigest.String()
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	if c.Params.ResultPathImageRef != "" {
+		if err := c.ResultsWriter.WriteResultString(c.Results.ImageRef, c.Params.ResultPathImageRef); err != nil {
+			return fmt.Errorf("error on writing result image ref: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *PushPrefetchOutput) validateParams() error {
+	c.imageName = common.GetImageName(c.Params.ImageUrl)
+	if !common.IsImageNameValid(c.imageName) {
+		return fmt.Errorf("image '%s' is invalid", c.Params.ImageUrl)
+	}
+	if common.GetImageDigest(c.Params.ImageUrl) != "" {
+		return fmt.Errorf("image '%s' must not have a digest", c.Params.ImageUrl)
+	}
+
+	if !regexp.MustCompile(tagSuffixRegex).MatchString(c.Params.TagSuffix) {
+		return fmt.Errorf("tag suffix includes invalid characters or exceeds the max length of 57 characters")
+	}
+
+	if stat, err := os.Stat(c.Params.OutputDir); err != nil {
+		return fmt.Errorf("output-dir: %w", err)
+	} else if !stat.IsDir() {
+		return fmt.Errorf("output-dir '%s' is not a directory", c.Params.OutputDir)
+	}
+
+	return validateAnnotations(c.Params.Annotations)
+}
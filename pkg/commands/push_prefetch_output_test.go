@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/testutil"
+)
+
+func Test_PushPrefetchOutput_Run(t *testing.T) {
+	const artifactDigest = "sha256:a7c0071906a9c6b654760e44a1fc8226f8268c70848148f19c35b02788b272a5"
+
+	workDir := t.TempDir()
+	t.Setenv("HOME", workDir)
+	testutil.WriteFileTree(t, workDir, map[string]string{
+		".docker/config.json":            `{"auths":{"quay.io":{"auth":"token"}}}`,
+		"output/bom.json":                `{"bomFormat": "CycloneDX"}`,
+		"output/deps/gomod/pkg/mod/a.go": `package a`,
+	})
+	outputDir := filepath.Join(workDir, "output")
+
+	newPushPrefetchOutput := func(imageUrl string, push func(args *cliwrappers.OrasPushArgs) (string, string, error)) (*PushPrefetchOutput, *mockResultsWriter) {
+		resultsWriter := &mockResultsWriter{}
+		return &PushPrefetchOutput{
+			Params: &PushPrefetchOutputParams{
+				ImageUrl:           imageUrl,
+				OutputDir:          outputDir,
+				TagSuffix:          prefetchOutputArtifactTagSuffix,
+				Annotations:        []string{"org.opencontainers.image.revision=abc"},
+				ResultPathImageRef: "/results/image-ref",
+			},
+			CliWrappers:   PushPrefetchOutputCliWrappers{OrasCli: &mockOrasCli{PushFunc: push}},
+			ResultsWriter: resultsWriter,
+		}, resultsWriter
+	}
+
+	t.Run("should push the tarball of the output directory to a tag derived from its digest", func(t *testing.T) {
+		g := NewWithT(t)
+		var pushArgs *cliwrappers.OrasPushArgs
+		var tarballNames []string
+		c, resultsWriter := newPushPrefetchOutput("quay.io/org/app", func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+			pushArgs = args
+			tarballNames = readTarballNames(g, args.Files[0].Path)
+			return "quay.io/org/app@" + artifactDigest + "\n", "", nil
+		})
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(c.Results.TarballDigest).To(HavePrefix("sha256:"))
+		g.Expect(pushArgs.DestinationImage).To(Equal("quay.io/org/app:sha256-" + c.Results.TarballDigest[len("sha256:"):] + ".prefetch"))
+		g.Expect(pushArgs.Files).To(Equal([]cliwrappers.OrasPushFile{{Path: prefetchOutputTarball, MediaType: prefetchOutputMediaType}}))
+		g.Expect(pushArgs.ArtifactType).To(Equal(prefetchOutputArtifactType))
+		g.Expect(pushArgs.Annotations).To(Equal([]string{"org.opencontainers.image.revision=abc"}))
+		g.Expect(pushArgs.RegistryConfig).ToNot(BeEmpty())
+		g.Expect(tarballNames).To(ConsistOf("bom.json", "deps/", "deps/gomod/", "deps/gomod/pkg/", "deps/gomod/pkg/mod/", "deps/gomod/pkg/mod/a.go"))
+		g.Expect(c.Results.ImageRef).To(Equal("quay.io/org/app@" + artifactDigest))
+		g.Expect(resultsWriter.WrittenResults).To(HaveKeyWithValue("/results/image-ref", "quay.io/org/app@"+artifactDigest))
+	})
+
+	t.Run("should push the same tarball for the same output", func(t *testing.T) {
+		g := NewWithT(t)
+		var destinations []string
+		push := func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+			destinations = append(destinations, args.DestinationImage)
+			return "quay.io/org/app@" + artifactDigest, "", nil
+		}
+		c1, _ := newPushPrefetchOutput("quay.io/org/app", push)
+		c2, _ := newPushPrefetchOutput("quay.io/org/app", push)
+
+		g.Expect(c1.Run()).To(Succeed())
+		g.Expect(c2.Run()).To(Succeed())
+
+		g.Expect(c2.Results.TarballDigest).To(Equal(c1.Results.TarballDigest))
+		g.Expect(destinations[1]).To(Equal(destinations[0]))
+	})
+
+	t.Run("should push to the image url tag", func(t *testing.T) {
+		g := NewWithT(t)
+		var pushArgs *cliwrappers.OrasPushArgs
+		c, _ := newPushPrefetchOutput("quay.io/org/app:deps", func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+			pushArgs = args
+			return "quay.io/org/app@" + artifactDigest, "", nil
+		})
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(pushArgs.DestinationImage).To(Equal("quay.io/org/app:deps"))
+	})
+
+	t.Run("should fail if push fails", func(t *testing.T) {
+		g := NewWithT(t)
+		c, _ := newPushPrefetchOutput("quay.io/org/app", func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+			return "", "", errors.New("unauthorized")
+		})
+
+		g.Expect(c.Run()).To(MatchError("error on pushing prefetch output: unauthorized"))
+	})
+
+	t.Run("should fail if the output directory does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+		c, _ := newPushPrefetchOutput("quay.io/org/app", nil)
+		c.Params.OutputDir = filepath.Join(workDir, "nonexistent")
+
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("output-dir:")))
+	})
+
+	t.Run("should fail for an image with digest", func(t *testing.T) {
+		g := NewWithT(t)
+		c, _ := newPushPrefetchOutput("quay.io/org/app@"+artifactDigest, nil)
+
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("must not have a digest")))
+	})
+}
+
+func readTarballNames(g *WithT, path string) []string {
+	f, err := os.Open(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	defer f.Close()
+	gzipReader, err := gzip.NewReader(f)
+	g.Expect(err).ToNot(HaveOccurred())
+	tarReader := tar.NewReader(gzipReader)
+
+	var names []string
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		g.Expect(err).ToNot(HaveOccurred())
+		names = append(names, header.Name)
+	}
+	return names
+}
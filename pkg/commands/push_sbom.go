@@ -0,0 +1,265 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common/sbom"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+const sbomArtifactTagSuffix = ".sbom"
+
+// Media types of the SBOM formats produced by the build (--sbom-format)
+var sbomMediaTypes = map[string]string{
+	"cyclonedx": "application/vnd.cyclonedx+json",
+	"spdx":      "application/spdx+json",
+}
+
+var PushSBOMParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_PUSH_SBOM_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Binary image URL. The SBOM is pushed to the image repository where this binary image is.",
+		Required:   true,
+	},
+	"digest": {
+		Name:       "digest",
+		ShortName:  "d",
+		EnvVarName: "KBC_PUSH_SBOM_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Digest of the binary image the SBOM describes.",
+		Required:   true,
+	},
+	"sbom-path": {
+		Name:       "sbom-path",
+		ShortName:  "s",
+		EnvVarName: "KBC_PUSH_SBOM_SBOM_PATH",
+		TypeKind:   reflect.String,
+		Usage:      "Path to the SBOM file, in CycloneDX or SPDX JSON format.",
+		Required:   true,
+	},
+	"artifact-type": {
+		Name:       "artifact-type",
+		ShortName:  "a",
+		EnvVarName: "KBC_PUSH_SBOM_ARTIFACT_TYPE",
+		TypeKind:   reflect.String,
+		Usage:      "Artifact type of the SBOM artifact image. Defaults to the media type of the SBOM format.",
+	},
+	"attach": {
+		Name:         "attach",
+		EnvVarName:   "KBC_PUSH_SBOM_ATTACH",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Attach the SBOM to the binary image as an OCI 1.1 referrer instead of pushing it to a tag.",
+	},
+	"tag-suffix": {
+		Name:         "tag-suffix",
+		ShortName:    "t",
+		EnvVarName:   "KBC_PUSH_SBOM_TAG_SUFFIX",
+		TypeKind:     reflect.String,
+		DefaultValue: sbomArtifactTagSuffix,
+		Usage:        "Suffix to construct artifact image tag. Not used with --attach.",
+	},
+	"result-path-image-ref": {
+		Name:       "result-path-image-ref",
+		ShortName:  "r",
+		EnvVarName: "KBC_PUSH_SBOM_RESULT_PATH_IMAGE_REF",
+		TypeKind:   reflect.String,
+		Usage:      "Write digested image reference of the pushed SBOM artifact into this file.",
+	},
+}
+
+type PushSBOMParams struct {
+	ImageUrl           string `paramName:"image-url"`
+	Digest             string `paramName:"digest"`
+	SBOMPath           string `paramName:"sbom-path"`
+	ArtifactType       string `paramName:"artifact-type"`
+	Attach             bool   `paramName:"attach"`
+	TagSuffix          string `paramName:"tag-suffix"`
+	ResultPathImageRef string `paramName:"result-path-image-ref"`
+}
+
+type PushSBOMResults struct {
+	ImageRef string `json:"image_ref"`
+}
+
+type PushSBOMCliWrappers struct {
+	OrasCli cliwrappers.OrasCliInterface
+}
+
+type PushSBOM struct {
+	Params        *PushSBOMParams
+	CliWrappers   PushSBOMCliWrappers
+	Results       PushSBOMResults
+	ResultsWriter common.ResultsWriterInterface
+
+	imageName string
+}
+
+func NewPushSBOM(cmd *cobra.Command) (*PushSBOM, error) {
+	params := &PushSBOMParams{}
+	if err := common.ParseParameters(cmd, PushSBOMParamsConfig, params); err != nil {
+		return nil, err
+	}
+	pushSBOM := &PushSBOM{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}
+	if err := pushSBOM.initCliWrappers(); err != nil {
+		return nil, err
+	}
+	return pushSBOM, nil
+}
+
+func (c *PushSBOM) initCliWrappers() error {
+	executor := cliwrappers.NewCliExecutor()
+	orasCli, err := cliwrappers.NewOrasCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.OrasCli = orasCli
+	return nil
+}
+
+func (c *PushSBOM) Run() error {
+	common.LogParameters(PushSBOMParamsConfig, c.Params)
+
+	c.imageName = common.GetImageName(c.Params.ImageUrl)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	mediaType, err := detectSBOMMediaType(c.Params.SBOMPath)
+	if err != nil {
+		return err
+	}
+	artifactType := c.Params.ArtifactType
+	if artifactType == "" {
+		artifactType = mediaType
+	}
+
+	registryConfig, err := common.WriteOrasRegistryConfig(c.Params.ImageUrl)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(registryConfig); err != nil {
+			l.Logger.Warnf("failed to remove %s: %s", registryConfig, err.Error())
+		}
+	}()
+
+	absSBOMPath, err := filepath.Abs(c.Params.SBOMPath)
+	if err != nil {
+		return fmt.Errorf("error on getting absolute path of %s: %w", c.Params.SBOMPath, err)
+	}
+
+	// oras uses the file path as the layer title, push from the SBOM directory so that it's
+	// the plain file name
+	curDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error getting current directory: %w", err)
+	}
+	workDir := filepath.Dir(absSBOMPath)
+	if err := os.Chdir(workDir); err != nil {
+		return fmt.Errorf("error on changing directory to %s: %w", workDir, err)
+	}
+	defer func() {
+		if err := os.Chdir(curDir); err != nil {
+			l.Logger.Warnf("failed to chdir to '%s' directory: %s", curDir, err.Error())
+		}
+	}()
+
+	files := []cliwrappers.OrasPushFile{{Path: filepath.Base(absSBOMPath), MediaType: mediaType}}
+	var stdout string
+	if c.Params.Attach {
+		subject := c.imageName + "@" + c.Params.Digest
+		stdout, _, err = c.CliWrappers.OrasCli.Attach(&cliwrappers.OrasAttachArgs{
+			Subject:        subject,
+			Files:          files,
+			ArtifactType:   artifactType,
+			RegistryConfig: registryConfig,
+			Format:         "go-template",
+			Template:       "{{.reference}}",
+		})
+		if err != nil {
+			return fmt.Errorf("error on attaching SBOM %s to %s: %w", c.Params.SBOMPath, subject, err)
+		}
+		l.Logger.Infof("SBOM '%s' is attached to %s", c.Params.SBOMPath, subject)
+	} else {
+		tag := strings.Replace(c.Params.Digest, ":", "-", 1) + c.Params.TagSuffix
+		stdout, _, err = c.CliWrappers.OrasCli.Push(&cliwrappers.OrasPushArgs{
+			DestinationImage: c.imageName + ":" + tag,
+			Files:            files,
+			ArtifactType:     artifactType,
+			RegistryConfig:   registryConfig,
+			Format:           "go-template",
+			Template:         "{{.reference}}",
+		})
+		if err != nil {
+			return fmt.Errorf("error on pushing SBOM %s: %w", c.Params.SBOMPath, err)
+		}
+		l.Logger.Infof("SBOM '%s' is pushed to registry with tag: %s", c.Params.SBOMPath, tag)
+	}
+
+	c.Results.ImageRef = strings.TrimSpace(stdout)
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	if c.Params.ResultPathImageRef != "" {
+		if err := c.ResultsWriter.WriteResultString(c.Results.ImageRef, c.Params.ResultPathImageRef); err != nil {
+			return fmt.Errorf("error on writing result image ref: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *PushSBOM) validateParams() error {
+	if !common.IsImageNameValid(c.imageName) {
+		return fmt.Errorf("image name '%s' is invalid", c.imageName)
+	}
+
+	if !common.IsImageDigestValid(c.Params.Digest) {
+		return fmt.Errorf("image digest '%s' is invalid", c.Params.Digest)
+	}
+
+	if !c.Params.Attach && !regexp.MustCompile(tagSuffixRegex).MatchString(c.Params.TagSuffix) {
+		return fmt.Errorf("tag suffix includes invalid characters or exceeds the max length of 57 characters")
+	}
+
+	return nil
+}
+
+// Media type of the SBOM file, based on its format.
+func detectSBOMMediaType(sbomPath string) (string, error) {
+	data, err := os.ReadFile(sbomPath) //nolint:gosec // SBOM path from controlled input
+	if err != nil {
+		return "", fmt.Errorf("error on reading SBOM: %w", err)
+	}
+	var doc sbom.Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("SBOM %s is not a JSON document: %w", sbomPath, err)
+	}
+	mediaType, ok := sbomMediaTypes[sbom.Format(doc)]
+	if !ok {
+		return "", fmt.Errorf("SBOM %s is neither a CycloneDX nor an SPDX document", sbomPath)
+	}
+	return mediaType, nil
+}
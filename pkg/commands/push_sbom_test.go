@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_PushSBOM_Run(t *testing.T) {
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	const sbomDigest = "sha256:a7c0071906a9c6b654760e44a1fc8226f8268c70848148f19c35b02788b272a5"
+
+	workDir := t.TempDir()
+	t.Setenv("HOME", workDir)
+	os.Mkdir(filepath.Join(workDir, ".docker"), 0755)
+	os.WriteFile(filepath.Join(workDir, ".docker", "config.json"), []byte(`{"auths":{"quay.io":{"auth":"token"}}}`), 0644)
+
+	sbomPath := filepath.Join(workDir, "sbom.json")
+	os.WriteFile(sbomPath, []byte(`{"spdxVersion": "SPDX-2.3"}`), 0644)
+
+	newPushSBOM := func(orasCli *mockOrasCli) (*PushSBOM, *mockResultsWriter) {
+		resultsWriter := &mockResultsWriter{}
+		return &PushSBOM{
+			Params: &PushSBOMParams{
+				ImageUrl:           "quay.io/org/app:v1",
+				Digest:             digest,
+				SBOMPath:           sbomPath,
+				TagSuffix:          sbomArtifactTagSuffix,
+				ResultPathImageRef: "/results/image-ref",
+			},
+			CliWrappers:   PushSBOMCliWrappers{OrasCli: orasCli},
+			ResultsWriter: resultsWriter,
+		}, resultsWriter
+	}
+
+	t.Run("should push SBOM to a tag derived from the digest", func(t *testing.T) {
+		g := NewWithT(t)
+		var pushArgs *cliwrappers.OrasPushArgs
+		c, resultsWriter := newPushSBOM(&mockOrasCli{
+			PushFunc: func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+				pushArgs = args
+				return "quay.io/org/app@" + sbomDigest + "\n", "", nil
+			},
+		})
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(pushArgs.DestinationImage).To(Equal("quay.io/org/app:sha256-e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b.sbom"))
+		g.Expect(pushArgs.ArtifactType).To(Equal("application/spdx+json"))
+		g.Expect(pushArgs.Files).To(Equal([]cliwrappers.OrasPushFile{{Path: "sbom.json", MediaType: "application/spdx+json"}}))
+		g.Expect(c.Results.ImageRef).To(Equal("quay.io/org/app@" + sbomDigest))
+		g.Expect(resultsWriter.WrittenResults).To(HaveKeyWithValue("/results/image-ref", "quay.io/org/app@"+sbomDigest))
+	})
+
+	t.Run("should attach SBOM as a referrer", func(t *testing.T) {
+		g := NewWithT(t)
+		var attachArgs *cliwrappers.OrasAttachArgs
+		c, _ := newPushSBOM(&mockOrasCli{
+			AttachFunc: func(args *cliwrappers.OrasAttachArgs) (string, string, error) {
+				attachArgs = args
+				return "quay.io/org/app@" + sbomDigest, "", nil
+			},
+			PushFunc: func(args *cliwrappers.OrasPushArgs) (string, string, error) {
+				return "", "", fmt.Errorf("unexpected push")
+			},
+		})
+		c.Params.Attach = true
+		c.Params.ArtifactType = "application/vnd.example.sbom"
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(attachArgs.Subject).To(Equal("quay.io/org/app@" + digest))
+		g.Expect(attachArgs.ArtifactType).To(Equal("application/vnd.example.sbom"))
+		g.Expect(attachArgs.Files).To(Equal([]cliwrappers.OrasPushFile{{Path: "sbom.json", MediaType: "application/spdx+json"}}))
+		g.Expect(c.Results.ImageRef).To(Equal("quay.io/org/app@" + sbomDigest))
+	})
+
+	t.Run("should fail for unknown SBOM format", func(t *testing.T) {
+		g := NewWithT(t)
+		c, _ := newPushSBOM(&mockOrasCli{})
+		c.Params.SBOMPath = filepath.Join(t.TempDir(), "sbom.json")
+		os.WriteFile(c.Params.SBOMPath, []byte(`{"packages": []}`), 0644)
+
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("is neither a CycloneDX nor an SPDX document")))
+	})
+
+	t.Run("should fail for invalid digest", func(t *testing.T) {
+		g := NewWithT(t)
+		c, _ := newPushSBOM(&mockOrasCli{})
+		c.Params.Digest = "1234"
+
+		g.Expect(c.Run()).To(MatchError("image digest '1234' is invalid"))
+	})
+}
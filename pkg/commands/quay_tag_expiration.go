@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+const (
+	quayRegistry      = "quay.io"
+	quayApiUrl        = "https://quay.io/api/v1"
+	quayTokenFileName = "token"
+)
+
+// The same format as the quay.expires-after label, e.g. 1h, 2d, 3w.
+var quayExpiresAfterRegex = regexp.MustCompile(`^([1-9][0-9]*)([smhdw])$`)
+
+var quayExpiresAfterUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+func parseQuayExpiresAfter(expiresAfter string) (time.Duration, error) {
+	match := quayExpiresAfterRegex.FindStringSubmatch(expiresAfter)
+	if match == nil {
+		return 0, fmt.Errorf("expected a number followed by one of s, m, h, d, w (e.g. 1h, 2d, 3w)")
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(n) * quayExpiresAfterUnits[match[2]], nil
+}
+
+// Read the Quay OAuth access token from a directory, e.g. a mounted secret.
+func readQuayToken(tokenDir string) (string, error) {
+	tokenPath := filepath.Join(tokenDir, quayTokenFileName)
+	data, err := os.ReadFile(tokenPath) //nolint:gosec // path is from controlled config
+	if err != nil {
+		return "", fmt.Errorf("reading Quay token: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("Quay token file %s is empty", tokenPath)
+	}
+	l.RegisterSecret(token)
+	return token, nil
+}
+
+// quayTagRef returns the Quay repository (namespace/name) and the tag of an image reference.
+// Returns empty strings if the reference is not a tag on quay.io.
+func quayTagRef(imageRef string) (string, string) {
+	ref, err := common.ParseReference(imageRef)
+	if err != nil || ref.Tag == "" || ref.Registry != quayRegistry {
+		return "", ""
+	}
+	return ref.Repository, ref.Tag
+}
+
+// quayClient sets tag expiration using the Quay API:
+// https://docs.quay.io/api/swagger/#!/tag/changeTag
+type quayClient struct {
+	apiUrl     string
+	token      string
+	httpClient *http.Client
+}
+
+func (q *quayClient) setTagExpiration(repository string, tag string, expiration time.Time) error {
+	body, err := json.Marshal(map[string]int64{"expiration": expiration.Unix()})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/repository/%s/tag/%s", strings.TrimSuffix(q.apiUrl, "/"), repository, tag)
+
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+q.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := q.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("setting expiration of %s:%s: %w", repository, tag, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("setting expiration of %s:%s: Quay API returned %s: %s",
+			repository, tag, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	l.Logger.Debugf("Tag %s:%s expires at %s", repository, tag, expiration.UTC().Format(time.RFC3339))
+	return nil
+}
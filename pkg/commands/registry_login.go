@@ -0,0 +1,310 @@
+package commands
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// Username stored with --token when no --username is given.
+// Registries authenticating by the token alone, e.g. the OpenShift image registry, accept any username.
+const registryLoginTokenUsername = "token"
+
+// How long --check waits for the registry to respond.
+const registryLoginCheckTimeout = time.Minute
+
+var RegistryLoginParamsConfig = map[string]common.Parameter{
+	"registry": {
+		Name:       "registry",
+		ShortName:  "r",
+		EnvVarName: "KBC_REGISTRY_LOGIN_REGISTRY",
+		TypeKind:   reflect.String,
+		Usage: "Registry to log in to, e.g. quay.io. May include a namespace or repository, e.g. quay.io/org, " +
+			"for credentials that apply only to it. Required.",
+		Required: true,
+	},
+	"username": {
+		Name:       "username",
+		ShortName:  "u",
+		EnvVarName: "KBC_REGISTRY_LOGIN_USERNAME",
+		TypeKind:   reflect.String,
+		Usage:      "Username, required with --password. Defaults to '" + registryLoginTokenUsername + "' with --token.",
+	},
+	"password": {
+		Name:       "password",
+		ShortName:  "p",
+		EnvVarName: "KBC_REGISTRY_LOGIN_PASSWORD",
+		TypeKind:   reflect.String,
+		Usage:      "Password. Prefer the environment variable or --secret-dir over the command line.",
+		NoLog:      true,
+	},
+	"token": {
+		Name:       "token",
+		EnvVarName: "KBC_REGISTRY_LOGIN_TOKEN",
+		TypeKind:   reflect.String,
+		Usage:      "Token used as the password, e.g. of a robot or service account. Mutually exclusive with --password.",
+		NoLog:      true,
+	},
+	"secret-dir": {
+		Name:       "secret-dir",
+		EnvVarName: "KBC_REGISTRY_LOGIN_SECRET_DIR",
+		TypeKind:   reflect.String,
+		Usage: "Directory with the credentials in the username and password files (e.g. a mounted kubernetes.io/basic-auth secret), " +
+			"or in a token file and optionally a username file. Mutually exclusive with --username, --password and --token.",
+	},
+	"target-auth-file": {
+		Name:       "target-auth-file",
+		EnvVarName: "KBC_REGISTRY_LOGIN_TARGET_AUTH_FILE",
+		TypeKind:   reflect.String,
+		Usage: "Auth file to write the credentials into, created if it doesn't exist. Defaults to $REGISTRY_AUTH_FILE, " +
+			"or $DOCKER_CONFIG/config.json, or ~/.docker/config.json, which the other commands read.",
+	},
+	"check": {
+		Name:         "check",
+		EnvVarName:   "KBC_REGISTRY_LOGIN_CHECK",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage: "Verify the credentials with the registry before writing them. " +
+			"Without credentials, verify the ones already in the auth file and don't write anything.",
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_REGISTRY_LOGIN_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Require HTTPS and verify certificates when checking the credentials with the registry.",
+	},
+	"cert-dir": {
+		Name:       "cert-dir",
+		EnvVarName: "KBC_REGISTRY_LOGIN_CERT_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Directory with the certificates for accessing the registry when checking the credentials.",
+	},
+}
+
+type RegistryLoginParams struct {
+	Registry       string `paramName:"registry"`
+	Username       string `paramName:"username"`
+	Password       string `paramName:"password"`
+	Token          string `paramName:"token"`
+	SecretDir      string `paramName:"secret-dir"`
+	TargetAuthFile string `paramName:"target-auth-file"`
+	Check          bool   `paramName:"check"`
+	TLSVerify      bool   `paramName:"tls-verify"`
+	CertDir        string `paramName:"cert-dir"`
+}
+
+type RegistryLoginCliWrappers struct {
+	// Set with --check
+	AuthChecker clients.RegistryAuthCheckerInterface
+}
+
+type RegistryLoginResults struct {
+	Registry string `json:"registry"`
+	// The auth file the credentials were written into, empty if only checked
+	AuthFile string `json:"auth_file,omitempty"`
+	// The registry accepted the credentials, see --check
+	Checked bool `json:"checked"`
+}
+
+type RegistryLogin struct {
+	Params        *RegistryLoginParams
+	CliWrappers   RegistryLoginCliWrappers
+	Results       RegistryLoginResults
+	ResultsWriter common.ResultsWriterInterface
+
+	username string
+	password string
+}
+
+func NewRegistryLogin(cmd *cobra.Command) (*RegistryLogin, error) {
+	registryLogin := &RegistryLogin{}
+
+	params := &RegistryLoginParams{}
+	if err := common.ParseParameters(cmd, RegistryLoginParamsConfig, params); err != nil {
+		return nil, err
+	}
+	registryLogin.Params = params
+
+	if params.Check {
+		registryLogin.CliWrappers.AuthChecker = clients.NewRegistryAuthChecker()
+	}
+
+	registryLogin.ResultsWriter = common.NewResultsWriter()
+
+	return registryLogin, nil
+}
+
+// Run executes the command logic.
+func (c *RegistryLogin) Run() error {
+	common.LogParameters(RegistryLoginParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	authFile := c.Params.TargetAuthFile
+	if authFile == "" {
+		authFile = common.UserAuthFile()
+	}
+	c.Results.Registry = c.Params.Registry
+
+	checkOnly := c.username == ""
+	if checkOnly {
+		if err := c.readCredentials(authFile); err != nil {
+			return err
+		}
+	}
+
+	if c.Params.Check {
+		registry, _, _ := strings.Cut(c.Params.Registry, "/")
+		if err := c.CliWrappers.AuthChecker.CheckAuth(&clients.RegistryAuthCheckArgs{
+			Registry:  registry,
+			Username:  c.username,
+			Password:  c.password,
+			TLSVerify: &c.Params.TLSVerify,
+			CertDir:   c.Params.CertDir,
+			Timeout:   registryLoginCheckTimeout,
+		}); err != nil {
+			return &kbcerrors.RegistryAuthError{Err: err}
+		}
+		l.Logger.Infof("Credentials of %s for %s are valid", c.username, c.Params.Registry)
+		c.Results.Checked = true
+	}
+
+	if !checkOnly {
+		authEntry := common.AuthEntry{Auth: base64.StdEncoding.EncodeToString([]byte(c.username + ":" + c.password))}
+		if err := common.WriteRegistryAuth(authFile, c.Params.Registry, authEntry); err != nil {
+			return fmt.Errorf("writing credentials into %s: %w", authFile, err)
+		}
+		l.Logger.Infof("Wrote credentials of %s for %s into %s", c.username, c.Params.Registry, authFile)
+		c.Results.AuthFile = authFile
+	}
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *RegistryLogin) validateParams() error {
+	registry := c.Params.Registry
+	if strings.Contains(registry, "://") {
+		return fmt.Errorf("registry '%s' must not include the scheme", registry)
+	}
+	if host, _, _ := strings.Cut(registry, "/"); host == "" || strings.ContainsAny(registry, "@ ") {
+		return fmt.Errorf("registry '%s' is invalid", registry)
+	}
+
+	if c.Params.SecretDir != "" {
+		if c.Params.Username != "" || c.Params.Password != "" || c.Params.Token != "" {
+			return errors.New("secret-dir is mutually exclusive with username, password and token")
+		}
+		return c.readSecretDir()
+	}
+
+	if c.Params.Password != "" && c.Params.Token != "" {
+		return errors.New("password and token are mutually exclusive")
+	}
+	switch {
+	case c.Params.Token != "":
+		c.username = c.Params.Username
+		if c.username == "" {
+			c.username = registryLoginTokenUsername
+		}
+		c.password = c.Params.Token
+	case c.Params.Password != "":
+		if c.Params.Username == "" {
+			return errors.New("password requires username")
+		}
+		c.username = c.Params.Username
+		c.password = c.Params.Password
+	case c.Params.Username != "":
+		return errors.New("username requires password or token")
+	case !c.Params.Check:
+		return errors.New("credentials are required: username and password, token or secret-dir")
+	}
+	return nil
+}
+
+// Read the credentials from the username and password, or token files of --secret-dir.
+func (c *RegistryLogin) readSecretDir() error {
+	readSecret := func(name string) (string, error) {
+		data, err := os.ReadFile(filepath.Join(c.Params.SecretDir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("reading %s from secret-dir: %w", name, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	username, err := readSecret("username")
+	if err != nil {
+		return err
+	}
+	password, err := readSecret("password")
+	if err != nil {
+		return err
+	}
+	l.RegisterSecret(password)
+	token, err := readSecret("token")
+	if err != nil {
+		return err
+	}
+	l.RegisterSecret(token)
+
+	switch {
+	case password != "" && token != "":
+		return fmt.Errorf("secret-dir '%s' contains both password and token", c.Params.SecretDir)
+	case token != "":
+		if username == "" {
+			username = registryLoginTokenUsername
+		}
+		c.username, c.password = username, token
+	case password != "" && username != "":
+		c.username, c.password = username, password
+	default:
+		return fmt.Errorf("secret-dir '%s' contains neither username and password nor token", c.Params.SecretDir)
+	}
+	return nil
+}
+
+// Read the credentials of the registry from the auth file, for --check without credentials.
+func (c *RegistryLogin) readCredentials(authFile string) error {
+	registryAuth, err := common.SelectRegistryAuth(c.Params.Registry, authFile)
+	if err != nil {
+		return fmt.Errorf("reading credentials for %s from %s: %w", c.Params.Registry, authFile, err)
+	}
+	if registryAuth.Token == "" {
+		return fmt.Errorf("credentials for %s in %s are tokens, only a username and a password can be checked", c.Params.Registry, authFile)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(registryAuth.Token)
+	if err != nil {
+		return fmt.Errorf("credentials for %s in %s are invalid: %w", c.Params.Registry, authFile, err)
+	}
+	username, password, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return fmt.Errorf("credentials for %s in %s are invalid: not username:password", c.Params.Registry, authFile)
+	}
+	l.RegisterSecret(password)
+	c.username, c.password = username, password
+	return nil
+}
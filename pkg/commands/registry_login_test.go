@@ -0,0 +1,265 @@
+package commands
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/clients"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+	"github.com/konflux-ci/konflux-build-cli/testutil"
+)
+
+func Test_RegistryLogin_validateParams(t *testing.T) {
+	secretDirs := t.TempDir()
+	testutil.WriteFileTree(t, secretDirs, map[string]string{
+		"basic-auth/username": "user\n",
+		"basic-auth/password": "secret\n",
+		"token/token":         "robot-token",
+		"both/password":       "secret",
+		"both/token":          "robot-token",
+		"empty/.keep":         "",
+	})
+
+	testCases := []struct {
+		name             string
+		params           RegistryLoginParams
+		expectedUsername string
+		expectedPassword string
+		expectedError    string
+	}{
+		{
+			name:             "username and password",
+			params:           RegistryLoginParams{Registry: "quay.io", Username: "user", Password: "secret"},
+			expectedUsername: "user",
+			expectedPassword: "secret",
+		},
+		{
+			name:             "token with username",
+			params:           RegistryLoginParams{Registry: "quay.io/org", Username: "org+robot", Token: "robot-token"},
+			expectedUsername: "org+robot",
+			expectedPassword: "robot-token",
+		},
+		{
+			name:             "token without username",
+			params:           RegistryLoginParams{Registry: "image-registry.openshift-image-registry.svc:5000", Token: "sa-token"},
+			expectedUsername: "token",
+			expectedPassword: "sa-token",
+		},
+		{
+			name:             "basic-auth secret dir",
+			params:           RegistryLoginParams{Registry: "quay.io", SecretDir: filepath.Join(secretDirs, "basic-auth")},
+			expectedUsername: "user",
+			expectedPassword: "secret",
+		},
+		{
+			name:             "token secret dir",
+			params:           RegistryLoginParams{Registry: "quay.io", SecretDir: filepath.Join(secretDirs, "token")},
+			expectedUsername: "token",
+			expectedPassword: "robot-token",
+		},
+		{
+			name:   "check without credentials",
+			params: RegistryLoginParams{Registry: "quay.io", Check: true},
+		},
+		{
+			name:          "no credentials",
+			params:        RegistryLoginParams{Registry: "quay.io"},
+			expectedError: "credentials are required",
+		},
+		{
+			name:          "password without username",
+			params:        RegistryLoginParams{Registry: "quay.io", Password: "secret"},
+			expectedError: "password requires username",
+		},
+		{
+			name:          "username without password",
+			params:        RegistryLoginParams{Registry: "quay.io", Username: "user"},
+			expectedError: "username requires password or token",
+		},
+		{
+			name:          "password and token",
+			params:        RegistryLoginParams{Registry: "quay.io", Username: "user", Password: "secret", Token: "token"},
+			expectedError: "password and token are mutually exclusive",
+		},
+		{
+			name:          "secret dir and username",
+			params:        RegistryLoginParams{Registry: "quay.io", Username: "user", SecretDir: filepath.Join(secretDirs, "token")},
+			expectedError: "secret-dir is mutually exclusive with username, password and token",
+		},
+		{
+			name:          "secret dir with password and token",
+			params:        RegistryLoginParams{Registry: "quay.io", SecretDir: filepath.Join(secretDirs, "both")},
+			expectedError: "contains both password and token",
+		},
+		{
+			name:          "secret dir without credentials",
+			params:        RegistryLoginParams{Registry: "quay.io", SecretDir: filepath.Join(secretDirs, "empty")},
+			expectedError: "contains neither username and password nor token",
+		},
+		{
+			name:          "registry with scheme",
+			params:        RegistryLoginParams{Registry: "https://quay.io", Username: "user", Password: "secret"},
+			expectedError: "registry 'https://quay.io' must not include the scheme",
+		},
+		{
+			name:          "registry with digest",
+			params:        RegistryLoginParams{Registry: "quay.io/org/app@sha256:1234", Username: "user", Password: "secret"},
+			expectedError: "registry 'quay.io/org/app@sha256:1234' is invalid",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &RegistryLogin{Params: &tc.params}
+
+			err := c.validateParams()
+
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(c.username).To(Equal(tc.expectedUsername))
+			g.Expect(c.password).To(Equal(tc.expectedPassword))
+		})
+	}
+}
+
+func Test_RegistryLogin_readSecretDir(t *testing.T) {
+	g := NewWithT(t)
+	secretDir := t.TempDir()
+	testutil.WriteFileTree(t, secretDir, map[string]string{
+		"username": "user\n",
+		"password": "secret-dir-password\n",
+	})
+
+	c := &RegistryLogin{Params: &RegistryLoginParams{Registry: "quay.io", SecretDir: secretDir}}
+	g.Expect(c.readSecretDir()).To(Succeed())
+
+	g.Expect(l.Redact("podman login -p secret-dir-password")).To(Equal("podman login -p ***"))
+}
+
+func Test_RegistryLogin_Run(t *testing.T) {
+	basicAuth := func(username, password string) string {
+		return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	}
+
+	t.Run("should check and write the credentials", func(t *testing.T) {
+		g := NewWithT(t)
+		authFile := filepath.Join(t.TempDir(), "auth.json")
+		g.Expect(common.WriteRegistryAuth(authFile, "reg.io", common.AuthEntry{Auth: "other"})).To(Succeed())
+
+		var checkArgs *clients.RegistryAuthCheckArgs
+		c := &RegistryLogin{
+			Params: &RegistryLoginParams{
+				Registry:       "quay.io/org",
+				Username:       "org+robot",
+				Token:          "robot-token",
+				TargetAuthFile: authFile,
+				Check:          true,
+				TLSVerify:      true,
+			},
+			CliWrappers: RegistryLoginCliWrappers{AuthChecker: &mockRegistryAuthChecker{
+				CheckAuthFunc: func(args *clients.RegistryAuthCheckArgs) error {
+					checkArgs = args
+					return nil
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(checkArgs.Registry).To(Equal("quay.io"))
+		g.Expect(checkArgs.Username).To(Equal("org+robot"))
+		g.Expect(checkArgs.Password).To(Equal("robot-token"))
+		g.Expect(*checkArgs.TLSVerify).To(BeTrue())
+
+		registryAuth, err := common.SelectRegistryAuth("quay.io/org/app", authFile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registryAuth.Token).To(Equal(basicAuth("org+robot", "robot-token")))
+		registryAuth, err = common.SelectRegistryAuth("reg.io/app", authFile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registryAuth.Token).To(Equal("other"))
+
+		g.Expect(c.Results).To(Equal(RegistryLoginResults{Registry: "quay.io/org", AuthFile: authFile, Checked: true}))
+	})
+
+	t.Run("should write into the user auth file by default", func(t *testing.T) {
+		g := NewWithT(t)
+		dockerConfig := t.TempDir()
+		t.Setenv("DOCKER_CONFIG", dockerConfig)
+		t.Setenv("REGISTRY_AUTH_FILE", "")
+		os.Unsetenv("REGISTRY_AUTH_FILE")
+
+		c := &RegistryLogin{
+			Params:        &RegistryLoginParams{Registry: "quay.io", Username: "user", Password: "secret"},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		authFile := filepath.Join(dockerConfig, "config.json")
+		registryAuth, err := common.SelectRegistryAuth("quay.io/org/app", authFile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registryAuth.Token).To(Equal(basicAuth("user", "secret")))
+		g.Expect(c.Results).To(Equal(RegistryLoginResults{Registry: "quay.io", AuthFile: authFile}))
+	})
+
+	t.Run("should only check the existing credentials without credentials", func(t *testing.T) {
+		g := NewWithT(t)
+		authFile := filepath.Join(t.TempDir(), "auth.json")
+		g.Expect(common.WriteRegistryAuth(authFile, "quay.io", common.AuthEntry{Auth: basicAuth("user", "secret")})).To(Succeed())
+		before, err := os.ReadFile(authFile)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		var checkArgs *clients.RegistryAuthCheckArgs
+		c := &RegistryLogin{
+			Params: &RegistryLoginParams{Registry: "quay.io", TargetAuthFile: authFile, Check: true},
+			CliWrappers: RegistryLoginCliWrappers{AuthChecker: &mockRegistryAuthChecker{
+				CheckAuthFunc: func(args *clients.RegistryAuthCheckArgs) error {
+					checkArgs = args
+					return nil
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(checkArgs.Username).To(Equal("user"))
+		g.Expect(checkArgs.Password).To(Equal("secret"))
+		after, err := os.ReadFile(authFile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(after).To(Equal(before))
+		g.Expect(c.Results).To(Equal(RegistryLoginResults{Registry: "quay.io", Checked: true}))
+	})
+
+	t.Run("should not write rejected credentials", func(t *testing.T) {
+		g := NewWithT(t)
+		authFile := filepath.Join(t.TempDir(), "auth.json")
+
+		c := &RegistryLogin{
+			Params: &RegistryLoginParams{Registry: "quay.io", Username: "user", Password: "wrong", TargetAuthFile: authFile, Check: true},
+			CliWrappers: RegistryLoginCliWrappers{AuthChecker: &mockRegistryAuthChecker{
+				CheckAuthFunc: func(args *clients.RegistryAuthCheckArgs) error {
+					return errors.New("checking credentials for quay.io: invalid username/password")
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("invalid username/password")))
+		g.Expect(kbcerrors.ExitCode(err)).To(Equal(kbcerrors.ExitCodeRegistryAuth))
+		g.Expect(authFile).ToNot(BeAnExistingFile())
+	})
+}
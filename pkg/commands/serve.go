@@ -0,0 +1,654 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/commands/prefetch_dependencies"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var ServeParamsConfig = map[string]common.Parameter{
+	"listen": {
+		Name:         "listen",
+		ShortName:    "l",
+		EnvVarName:   "KBC_SERVE_LISTEN",
+		TypeKind:     reflect.String,
+		DefaultValue: "127.0.0.1:8080",
+		Usage:        "Address to listen on. Don't expose the API outside of the pod.",
+	},
+	"token-file": {
+		Name:       "token-file",
+		EnvVarName: "KBC_SERVE_TOKEN_FILE",
+		TypeKind:   reflect.String,
+		Usage:      "File with the token the API requests must have in the 'Authorization: Bearer <token>' header.",
+		Required:   true,
+	},
+}
+
+type ServeParams struct {
+	Listen    string `paramName:"listen"`
+	TokenFile string `paramName:"token-file"`
+}
+
+// A command that can be run through the API.
+type serveCommand struct {
+	// CLI subcommand names, e.g. ["image", "build"]
+	command []string
+	params  map[string]common.Parameter
+	// Flags of params the jobs may be run with. The flags that mount, read or write arbitrary host paths,
+	// run commands, select credentials or relax the TLS verification are left out.
+	allowedFlags []string
+	// Allowed flags taking a local path, it must resolve inside the working directory of serve
+	pathFlags []string
+	// Path flags that also accept an https:// URL, e.g. a remote build context
+	urlFlags []string
+}
+
+var serveCommands = []serveCommand{
+	{
+		command: []string{"image", "build"},
+		params:  BuildParamsConfig,
+		allowedFlags: []string{
+			"add-legacy-labels", "additional-tags", "allow-cross-platform-images", "annotations", "annotations-file",
+			"build-args", "build-args-file", "build-timeout", "cache-from", "cache-to", "containerfile",
+			"containerfile-json", "containerignore-from-gitignore", "context", "context-report-top-files", "envs",
+			"format", "hermetic", "image-revision", "image-source", "include-legacy-buildinfo-path", "inherit-labels",
+			"labels", "layers", "legacy-build-timestamp", "lint", "lint-fail-on", "max-context-size", "no-cache",
+			"omit-history", "output-ref", "platform", "push", "push-timeout", "quay-image-expires-after",
+			"require-pinned-base-images", "resolve-base-images", "rewrite-timestamp", "sanitize-context",
+			"sanitize-context-excludes", "skip-injections", "skip-unused-stages", "source", "source-date-epoch",
+			"squash", "target", "workdir-mount",
+		},
+		pathFlags: []string{"annotations-file", "build-args-file", "containerfile", "containerfile-json", "context", "source"},
+		urlFlags:  []string{"context"},
+	},
+	{
+		command: []string{"image", "apply-tags"},
+		params:  ApplyTagsParamsConfig,
+		allowedFlags: []string{
+			"certificate-identity", "certificate-identity-regexp", "certificate-oidc-issuer",
+			"certificate-oidc-issuer-regexp", "destination-repo", "digest", "dry-run", "image-url",
+			"require-signature", "skip-existing", "tag-expires-after", "tags", "tags-from-image-label", "verify",
+		},
+	},
+	{
+		command: []string{"prefetch-dependencies"},
+		params:  prefetch_dependencies.ParamsConfig,
+		allowedFlags: []string{
+			"allow-dev-package-managers", "config-file", "enable-package-registry-proxy", "entitlement-repo-ids",
+			"go-nosumcheck", "go-private", "input", "lockfile-refresh-check", "mode", "output-dir",
+			"output-dir-mount-point", "rhsm-enable-repos", "rhsm-release", "sbom-format", "sbom-output-path",
+			"source-dir", "split-by-type",
+		},
+		pathFlags: []string{"config-file", "output-dir", "sbom-output-path", "source-dir"},
+	},
+}
+
+// Flags of the root command the jobs may be run with.
+var serveGlobalParams = map[string]common.Parameter{
+	"loglevel":             {Name: "loglevel", TypeKind: reflect.String},
+	"log-format":           {Name: "log-format", TypeKind: reflect.String},
+	"registry-retries":     {Name: "registry-retries", TypeKind: reflect.Int},
+	"registry-retry-delay": {Name: "registry-retry-delay", TypeKind: reflect.String},
+	"validate-results":     {Name: "validate-results", TypeKind: reflect.Bool},
+}
+
+// How many finished jobs are kept for the status and logs requests, the oldest ones are dropped first.
+const serveMaxFinishedJobs = 100
+
+// Maximum size of the output kept for a job, the rest of the output is dropped.
+const serveMaxJobOutputSize = 10 * 1024 * 1024
+
+// How long a cancelled job has to stop after SIGTERM before it's killed,
+// within the default 30s termination grace period of a pod.
+const serveJobStopTimeout = 20 * time.Second
+
+// How long to wait for the open requests (e.g. log streams) when shutting down.
+const serveShutdownTimeout = 5 * time.Second
+
+type JobState string
+
+const (
+	JobStateRunning   JobState = "running"
+	JobStateSucceeded JobState = "succeeded"
+	JobStateFailed    JobState = "failed"
+)
+
+// JobRunner runs the CLI with the given arguments, writing the combined output into out.
+// Returns the exit code of the process.
+type JobRunner func(ctx context.Context, args []string, out io.Writer) (int, error)
+
+type jobRequest struct {
+	// CLI subcommand, e.g. ["image", "build"]
+	Command []string `json:"command"`
+	// Flags and arguments of the subcommand
+	Args []string `json:"args"`
+}
+
+type JobStatus struct {
+	Id         string     `json:"id"`
+	Command    []string   `json:"command"`
+	Args       []string   `json:"args"`
+	State      JobState   `json:"state"`
+	ExitCode   *int       `json:"exit_code,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type job struct {
+	mu     sync.Mutex
+	status JobStatus
+	output bytes.Buffer
+	// serveMaxJobOutputSize, the output over it is dropped
+	maxOutputSize int
+	truncated     bool
+	// closed and replaced whenever new output is written or the job finishes
+	updated chan struct{}
+	cancel  context.CancelFunc
+}
+
+func (j *job) Write(p []byte) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.truncated {
+		return len(p), nil
+	}
+	if j.output.Len()+len(p) > j.maxOutputSize {
+		j.output.Write(p[:j.maxOutputSize-j.output.Len()])
+		j.output.WriteString("\n[output truncated]\n")
+		j.truncated = true
+	} else {
+		j.output.Write(p)
+	}
+	j.notifyLocked()
+	// The process output must not fail, the dropped part is reported as written
+	return len(p), nil
+}
+
+func (j *job) notifyLocked() {
+	close(j.updated)
+	j.updated = make(chan struct{})
+}
+
+func (j *job) finish(exitCode int, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.status.FinishedAt = &now
+	if err != nil {
+		j.status.State = JobStateFailed
+		j.status.Error = err.Error()
+	} else {
+		j.status.ExitCode = &exitCode
+		if exitCode == 0 {
+			j.status.State = JobStateSucceeded
+		} else {
+			j.status.State = JobStateFailed
+		}
+	}
+	j.notifyLocked()
+}
+
+// Return the output written since offset, whether the job is finished
+// and a channel that gets closed on the next update.
+func (j *job) outputSince(offset int) ([]byte, bool, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	data := slices.Clone(j.output.Bytes()[offset:])
+	return data, j.status.State != JobStateRunning, j.updated
+}
+
+func (j *job) getStatus() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+type Serve struct {
+	Params *ServeParams
+	Runner JobRunner
+	// Token the API requests must have, see --token-file
+	Token string
+
+	mu     sync.Mutex
+	jobs   map[string]*job
+	nextId int
+	// Set when shutting down, no new jobs are started
+	stopping bool
+	running  sync.WaitGroup
+	// serveMaxFinishedJobs if 0
+	maxFinishedJobs int
+}
+
+func NewServe(cmd *cobra.Command) (*Serve, error) {
+	params := &ServeParams{}
+	if err := common.ParseParameters(cmd, ServeParamsConfig, params); err != nil {
+		return nil, err
+	}
+
+	token, err := readServeToken(params.TokenFile)
+	if err != nil {
+		return nil, kbcerrors.NewValidationError(err)
+	}
+
+	selfPath, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Serve{Params: params, Runner: newSelfRunner(selfPath), Token: token}, nil
+}
+
+func readServeToken(tokenFile string) (string, error) {
+	content, err := os.ReadFile(tokenFile) //nolint:gosec // token file given by the user
+	if err != nil {
+		return "", fmt.Errorf("reading token file: %w", err)
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", tokenFile)
+	}
+	l.RegisterSecret(token)
+	return token, nil
+}
+
+// Run each job as a separate process of this CLI. The commands keep global state
+// (logger, working directory, user namespace), running them in-process would not be safe.
+func newSelfRunner(selfPath string) JobRunner {
+	return func(ctx context.Context, args []string, out io.Writer) (int, error) {
+		cmd := exec.CommandContext(ctx, selfPath, args...) //nolint:gosec // only allow-listed subcommands are run
+		// Let the job stop its child processes cleanly, e.g. buildah releasing its storage locks
+		cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+		cmd.WaitDelay = serveJobStopTimeout
+		cmd.Stdout = out
+		cmd.Stderr = out
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		if err != nil {
+			return -1, err
+		}
+		return 0, nil
+	}
+}
+
+// Run executes the command logic.
+func (c *Serve) Run() error {
+	common.LogParameters(ServeParamsConfig, c.Params)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	return c.serve(ctx)
+}
+
+// Serve the API until ctx is done, then cancel the running jobs and wait for them to stop.
+func (c *Serve) serve(ctx context.Context) error {
+	listener, err := net.Listen("tcp", c.Params.Listen)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Handler:           c.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	l.Logger.Infof("Listening on %s", listener.Addr())
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	l.Logger.Info("Shutting down, cancelling the running jobs")
+	c.cancelJobs()
+	c.running.Wait()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutting down the server: %w", err)
+	}
+	return nil
+}
+
+// Cancel the running jobs and don't start new ones.
+func (c *Serve) cancelJobs() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopping = true
+	for _, j := range c.jobs {
+		j.cancel()
+	}
+}
+
+// Handler returns the HTTP API, all requests must have the 'Authorization: Bearer <token>' header:
+//
+//	POST   /jobs            start a job, body: {"command": ["image", "build"], "args": ["--output-ref", "..."]}
+//	GET    /jobs            list jobs
+//	GET    /jobs/{id}       job status
+//	GET    /jobs/{id}/logs  job output, streamed until the job finishes
+//	DELETE /jobs/{id}       cancel a running job
+func (c *Serve) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs", c.handleCreateJob)
+	mux.HandleFunc("GET /jobs", c.handleListJobs)
+	mux.HandleFunc("GET /jobs/{id}", c.handleGetJob)
+	mux.HandleFunc("GET /jobs/{id}/logs", c.handleJobLogs)
+	mux.HandleFunc("DELETE /jobs/{id}", c.handleCancelJob)
+	return c.requireToken(mux)
+}
+
+func (c *Serve) requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if c.Token == "" || !found || subtle.ConstantTimeCompare([]byte(token), []byte(c.Token)) != 1 {
+			writeJsonError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c *Serve) handleCreateJob(w http.ResponseWriter, r *http.Request) {
+	// Browsers send cross-site requests without a preflight only for form content types
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeJsonError(w, http.StatusUnsupportedMediaType, errors.New("the request body must be application/json"))
+		return
+	}
+
+	var req jobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJsonError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	idx := slices.IndexFunc(serveCommands, func(allowed serveCommand) bool {
+		return slices.Equal(allowed.command, req.Command)
+	})
+	if idx == -1 {
+		writeJsonError(w, http.StatusBadRequest, fmt.Errorf("command '%s' is not allowed", strings.Join(req.Command, " ")))
+		return
+	}
+	if err := serveCommands[idx].checkArgs(req.Args); err != nil {
+		writeJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	j := c.startJob(req)
+	if j == nil {
+		writeJsonError(w, http.StatusServiceUnavailable, errors.New("shutting down"))
+		return
+	}
+	writeJson(w, http.StatusCreated, j.getStatus())
+}
+
+// Return an error if args have a flag that is not allowed, a path outside of the working directory
+// or a positional argument. The arguments after "--" would be passed to the tools as they are.
+func (sc serveCommand) checkArgs(args []string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	workdir, err := common.ResolvePath(wd)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return errors.New("arguments after '--' are not allowed")
+		}
+		name, value, hasValue, ok := parseServeFlag(arg)
+		if !ok {
+			return fmt.Errorf("positional argument '%s' is not allowed", arg)
+		}
+		param, found := sc.lookupParam(name)
+		if !found {
+			return fmt.Errorf("flag '%s' is not allowed", arg)
+		}
+
+		var values []string
+		if hasValue {
+			values = append(values, value)
+		}
+		switch {
+		case param.TypeKind == reflect.Array || param.TypeKind == reflect.Slice:
+			// Takes all the arguments up to the next flag, see common.ExpandArrayParameters
+			for i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				i++
+				values = append(values, args[i])
+			}
+		case hasValue, param.TypeKind == reflect.Bool:
+		case i+1 < len(args):
+			i++
+			values = append(values, args[i])
+		}
+
+		if !slices.Contains(sc.pathFlags, param.Name) {
+			continue
+		}
+		for _, value := range values {
+			if value == "--" {
+				return errors.New("arguments after '--' are not allowed")
+			}
+			if slices.Contains(sc.urlFlags, param.Name) && strings.HasPrefix(value, "https://") {
+				continue
+			}
+			if err := common.CheckPathInside(workdir, param.Name, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Split a flag argument, e.g. "--name=value" or "-n", into its name and value.
+// Not ok if the argument is not a flag.
+func parseServeFlag(arg string) (name, value string, hasValue, ok bool) {
+	if long, isLong := strings.CutPrefix(arg, "--"); isLong {
+		name, value, hasValue = strings.Cut(long, "=")
+		return name, value, hasValue, name != ""
+	}
+	if !strings.HasPrefix(arg, "-") || len(arg) < 2 {
+		return "", "", false, false
+	}
+	// The shorthand value may follow with or without "=", e.g. "-tvalue"
+	name, value = arg[1:2], strings.TrimPrefix(arg[2:], "=")
+	return name, value, value != "", true
+}
+
+// Find the allowed flag by its name or shorthand.
+func (sc serveCommand) lookupParam(name string) (common.Parameter, bool) {
+	if param, found := serveGlobalParams[name]; found {
+		return param, true
+	}
+	for _, allowed := range sc.allowedFlags {
+		param := sc.params[allowed]
+		if param.Name == name || (param.ShortName != "" && param.ShortName == name) {
+			return param, true
+		}
+	}
+	return common.Parameter{}, false
+}
+
+// Start a job, nil if serve is shutting down.
+func (c *Serve) startJob(req jobRequest) *job {
+	c.mu.Lock()
+	if c.stopping {
+		c.mu.Unlock()
+		return nil
+	}
+	// The job outlives the HTTP request, don't derive from the request context
+	ctx, cancel := context.WithCancel(context.Background())
+	if c.jobs == nil {
+		c.jobs = make(map[string]*job)
+	}
+	c.nextId++
+	id := strconv.Itoa(c.nextId)
+	j := &job{
+		status: JobStatus{
+			Id:        id,
+			Command:   req.Command,
+			Args:      req.Args,
+			State:     JobStateRunning,
+			StartedAt: time.Now(),
+		},
+		maxOutputSize: serveMaxJobOutputSize,
+		updated:       make(chan struct{}),
+		cancel:        cancel,
+	}
+	c.jobs[id] = j
+	c.running.Add(1)
+	c.mu.Unlock()
+
+	l.Logger.Infof("Starting job %s: %s", id, strings.Join(req.Command, " "))
+	go func() {
+		defer c.running.Done()
+		defer cancel()
+		exitCode, err := c.Runner(ctx, slices.Concat(req.Command, req.Args), j)
+		j.finish(exitCode, err)
+		l.Logger.Infof("Job %s finished: %s", id, j.getStatus().State)
+		c.dropOldJobs()
+	}()
+
+	return j
+}
+
+// Drop the oldest finished jobs over serveMaxFinishedJobs.
+func (c *Serve) dropOldJobs() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	maxFinishedJobs := c.maxFinishedJobs
+	if maxFinishedJobs == 0 {
+		maxFinishedJobs = serveMaxFinishedJobs
+	}
+	var finished []JobStatus
+	for _, j := range c.jobs {
+		if status := j.getStatus(); status.State != JobStateRunning {
+			finished = append(finished, status)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+	slices.SortFunc(finished, func(a, b JobStatus) int { return a.FinishedAt.Compare(*b.FinishedAt) })
+	for _, status := range finished[:len(finished)-maxFinishedJobs] {
+		delete(c.jobs, status.Id)
+	}
+}
+
+func (c *Serve) getJob(id string) *job {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.jobs[id]
+}
+
+func (c *Serve) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	statuses := make([]JobStatus, 0, len(c.jobs))
+	for _, j := range c.jobs {
+		statuses = append(statuses, j.getStatus())
+	}
+	c.mu.Unlock()
+
+	slices.SortFunc(statuses, func(a, b JobStatus) int {
+		idA, _ := strconv.Atoi(a.Id)
+		idB, _ := strconv.Atoi(b.Id)
+		return idA - idB
+	})
+	writeJson(w, http.StatusOK, statuses)
+}
+
+func (c *Serve) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	j := c.getJob(r.PathValue("id"))
+	if j == nil {
+		writeJsonError(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+	writeJson(w, http.StatusOK, j.getStatus())
+}
+
+func (c *Serve) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	j := c.getJob(r.PathValue("id"))
+	if j == nil {
+		writeJsonError(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+	j.cancel()
+	writeJson(w, http.StatusAccepted, j.getStatus())
+}
+
+func (c *Serve) handleJobLogs(w http.ResponseWriter, r *http.Request) {
+	j := c.getJob(r.PathValue("id"))
+	if j == nil {
+		writeJsonError(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	flusher, _ := w.(http.Flusher)
+
+	offset := 0
+	for {
+		data, finished, updated := j.outputSince(offset)
+		if len(data) > 0 {
+			if _, err := w.Write(data); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			offset += len(data)
+		}
+		if finished {
+			return
+		}
+		select {
+		case <-updated:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeJson(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		l.Logger.Warnf("Failed to write response: %s", err)
+	}
+}
+
+func writeJsonError(w http.ResponseWriter, statusCode int, err error) {
+	writeJson(w, statusCode, map[string]string{"error": err.Error()})
+}
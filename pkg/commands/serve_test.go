@@ -0,0 +1,315 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_Serve(t *testing.T) {
+	g := NewWithT(t)
+
+	var server *httptest.Server
+	var serve *Serve
+	var release chan struct{}
+	var capturedArgs chan []string
+
+	beforeEach := func() {
+		// jobs of the previous subtests may still be running, they must not see the new channels
+		jobRelease := make(chan struct{})
+		jobArgs := make(chan []string, 10)
+		release = jobRelease
+		capturedArgs = jobArgs
+		serve = &Serve{
+			Params: &ServeParams{},
+			Token:  "secret",
+			Runner: func(ctx context.Context, args []string, out io.Writer) (int, error) {
+				jobArgs <- args
+				fmt.Fprintln(out, "building...")
+				select {
+				case <-jobRelease:
+				case <-ctx.Done():
+					return -1, ctx.Err()
+				}
+				fmt.Fprintln(out, "done")
+				return 0, nil
+			},
+		}
+		server = httptest.NewServer(serve.Handler())
+		t.Cleanup(server.Close)
+	}
+
+	do := func(method, path, contentType, body string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		g.Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Authorization", "Bearer secret")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		g.Expect(err).ToNot(HaveOccurred())
+		return resp
+	}
+
+	createJob := func(body string) (*http.Response, JobStatus) {
+		resp := do(http.MethodPost, "/jobs", "application/json", body)
+		defer resp.Body.Close()
+		var status JobStatus
+		_ = json.NewDecoder(resp.Body).Decode(&status)
+		return resp, status
+	}
+
+	getStatus := func(id string) JobStatus {
+		resp := do(http.MethodGet, "/jobs/"+id, "", "")
+		defer resp.Body.Close()
+		g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var status JobStatus
+		g.Expect(json.NewDecoder(resp.Body).Decode(&status)).To(Succeed())
+		return status
+	}
+
+	t.Run("should run job and stream its logs", func(t *testing.T) {
+		beforeEach()
+
+		resp, status := createJob(`{"command": ["image", "build"], "args": ["--output-ref", "quay.io/org/app:v1"]}`)
+		g.Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+		g.Expect(status.Id).To(Equal("1"))
+		g.Expect(status.State).To(Equal(JobStateRunning))
+
+		logsResp := do(http.MethodGet, "/jobs/1/logs", "", "")
+		defer logsResp.Body.Close()
+
+		close(release)
+		logs, err := io.ReadAll(logsResp.Body)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(logs)).To(Equal("building...\ndone\n"))
+
+		g.Expect(<-capturedArgs).To(Equal([]string{"image", "build", "--output-ref", "quay.io/org/app:v1"}))
+		status = getStatus("1")
+		g.Expect(status.State).To(Equal(JobStateSucceeded))
+		g.Expect(*status.ExitCode).To(Equal(0))
+		g.Expect(status.FinishedAt).ToNot(BeNil())
+	})
+
+	t.Run("should cancel running job", func(t *testing.T) {
+		beforeEach()
+
+		_, status := createJob(`{"command": ["prefetch-dependencies"]}`)
+
+		resp := do(http.MethodDelete, "/jobs/"+status.Id, "", "")
+		resp.Body.Close()
+		g.Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+
+		g.Eventually(func() JobState { return getStatus(status.Id).State }, time.Second).Should(Equal(JobStateFailed))
+		g.Expect(getStatus(status.Id).Error).To(ContainSubstring("context canceled"))
+	})
+
+	t.Run("should list jobs", func(t *testing.T) {
+		beforeEach()
+		defer close(release)
+
+		createJob(`{"command": ["image", "build"]}`)
+		createJob(`{"command": ["image", "apply-tags"]}`)
+
+		resp := do(http.MethodGet, "/jobs", "", "")
+		defer resp.Body.Close()
+		var statuses []JobStatus
+		g.Expect(json.NewDecoder(resp.Body).Decode(&statuses)).To(Succeed())
+		g.Expect(statuses).To(HaveLen(2))
+		g.Expect(statuses[0].Command).To(Equal([]string{"image", "build"}))
+		g.Expect(statuses[1].Command).To(Equal([]string{"image", "apply-tags"}))
+	})
+
+	t.Run("should reject commands that are not allowed", func(t *testing.T) {
+		beforeEach()
+
+		resp, _ := createJob(`{"command": ["serve"]}`)
+		g.Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+		resp, _ = createJob(`not json`)
+		g.Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	t.Run("should reject the flags that are not allowed", func(t *testing.T) {
+		beforeEach()
+
+		for _, job := range []string{
+			`{"command": ["image", "build"], "args": ["--results-file", "/tmp/results.json"]}`,
+			`{"command": ["image", "build"], "args": ["--authfile=/root/auth.json"]}`,
+			`{"command": ["image", "build"], "args": ["-pre-build-script", "x.sh"]}`,
+			`{"command": ["image", "build"], "args": ["--output", "oci:/etc"]}`,
+			`{"command": ["image", "build"], "args": ["--volumes", "/:/host"]}`,
+			`{"command": ["image", "build"], "args": ["--storage-dir=/var/lib/containers"]}`,
+			`{"command": ["image", "build"], "args": ["--output-ref", "quay.io/org/app:v1", "--", "-v", "/:/host"]}`,
+			`{"command": ["image", "build"], "args": ["--context", "/etc"]}`,
+			`{"command": ["image", "build"], "args": ["-c", "../"]}`,
+			`{"command": ["image", "build"], "args": ["--containerfile=../Containerfile"]}`,
+			`{"command": ["image", "build"], "args": ["quay.io/org/app:v1"]}`,
+			`{"command": ["image", "apply-tags"], "args": ["--dest-auth-file", "/root/auth.json"]}`,
+			`{"command": ["prefetch-dependencies"], "args": ["--input-file", "/etc/shadow"]}`,
+			`{"command": ["prefetch-dependencies"], "args": ["--output-dir", "/tmp"]}`,
+			`{"command": ["prefetch-dependencies"], "args": ["--undo", "--side-effects-file", "x.json"]}`,
+		} {
+			resp, _ := createJob(job)
+			g.Expect(resp.StatusCode).To(Equal(http.StatusBadRequest), job)
+		}
+		g.Expect(capturedArgs).To(BeEmpty())
+	})
+
+	t.Run("should allow the allow-listed flags", func(t *testing.T) {
+		beforeEach()
+		defer close(release)
+
+		for _, job := range []string{
+			`{"command": ["image", "build"], "args": ["-t", "quay.io/org/app:v1", "--push", "-c", "src", "--containerfile=src/Containerfile", "--loglevel", "debug"]}`,
+			`{"command": ["image", "build"], "args": ["--context", "https://github.com/org/app.git#main", "--build-args", "A=1", "B=2"]}`,
+			`{"command": ["image", "apply-tags"], "args": ["--image-url", "quay.io/org/app", "--tags", "v1", "latest", "--dry-run"]}`,
+			`{"command": ["prefetch-dependencies"], "args": ["--input", "{}", "--output-dir=prefetch-output"]}`,
+		} {
+			resp, _ := createJob(job)
+			g.Expect(resp.StatusCode).To(Equal(http.StatusCreated), job)
+		}
+	})
+
+	t.Run("should require the token and a JSON body", func(t *testing.T) {
+		beforeEach()
+
+		for _, authorization := range []string{"", "Bearer wrong", "secret"} {
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/jobs", nil)
+			req.Header.Set("Authorization", authorization)
+			resp, err := http.DefaultClient.Do(req)
+			g.Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			g.Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized), authorization)
+		}
+
+		// What a cross-site form would send
+		resp := do(http.MethodPost, "/jobs", "text/plain", `{"command": ["image", "build"]}`)
+		resp.Body.Close()
+		g.Expect(resp.StatusCode).To(Equal(http.StatusUnsupportedMediaType))
+		g.Expect(capturedArgs).To(BeEmpty())
+	})
+
+	t.Run("should drop the oldest finished jobs", func(t *testing.T) {
+		beforeEach()
+		serve.maxFinishedJobs = 2
+		close(release)
+
+		for range 3 {
+			_, status := createJob(`{"command": ["image", "build"]}`)
+			g.Eventually(func() JobState { return getStatus(status.Id).State }, time.Second).Should(Equal(JobStateSucceeded))
+		}
+
+		// The jobs are dropped after finishing
+		g.Eventually(func() int {
+			resp := do(http.MethodGet, "/jobs/1", "", "")
+			resp.Body.Close()
+			return resp.StatusCode
+		}, time.Second).Should(Equal(http.StatusNotFound))
+		g.Expect(getStatus("2").State).To(Equal(JobStateSucceeded))
+		g.Expect(getStatus("3").State).To(Equal(JobStateSucceeded))
+	})
+
+	t.Run("should return 404 for unknown job", func(t *testing.T) {
+		beforeEach()
+
+		for _, path := range []string{"/jobs/42", "/jobs/42/logs"} {
+			resp := do(http.MethodGet, path, "", "")
+			resp.Body.Close()
+			g.Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		}
+	})
+}
+
+func Test_job_Write(t *testing.T) {
+	g := NewWithT(t)
+
+	j := &job{maxOutputSize: 10, updated: make(chan struct{})}
+	for _, line := range []string{"building\n", "still building\n", "done\n"} {
+		n, err := j.Write([]byte(line))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(n).To(Equal(len(line)))
+	}
+
+	output, _, _ := j.outputSince(0)
+	g.Expect(string(output)).To(Equal("building\ns\n[output truncated]\n"))
+}
+
+func Test_readServeToken(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+
+	tokenFile := filepath.Join(dir, "token")
+	g.Expect(os.WriteFile(tokenFile, []byte("secret\n"), 0600)).To(Succeed())
+	g.Expect(readServeToken(tokenFile)).To(Equal("secret"))
+
+	emptyFile := filepath.Join(dir, "empty")
+	g.Expect(os.WriteFile(emptyFile, []byte("\n"), 0600)).To(Succeed())
+	_, err := readServeToken(emptyFile)
+	g.Expect(err).To(MatchError(ContainSubstring("is empty")))
+}
+
+func Test_Serve_serve(t *testing.T) {
+	g := NewWithT(t)
+
+	started := make(chan struct{})
+	serve := &Serve{
+		Params: &ServeParams{Listen: "127.0.0.1:0"},
+		Token:  "secret",
+		Runner: func(ctx context.Context, args []string, out io.Writer) (int, error) {
+			close(started)
+			<-ctx.Done()
+			return -1, ctx.Err()
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve.serve(ctx)
+	}()
+
+	j := serve.startJob(jobRequest{Command: []string{"image", "build"}})
+	<-started
+	cancel()
+
+	g.Eventually(serveErr, 5*time.Second).Should(Receive(BeNil()))
+	g.Expect(j.getStatus().State).To(Equal(JobStateFailed))
+	g.Expect(j.getStatus().Error).To(ContainSubstring("context canceled"))
+	g.Expect(serve.startJob(jobRequest{Command: []string{"image", "build"}})).To(BeNil())
+}
+
+func Test_newSelfRunner(t *testing.T) {
+	g := NewWithT(t)
+
+	// Stands in for the CLI, stops cleanly on SIGTERM
+	script := filepath.Join(t.TempDir(), "kbc")
+	g.Expect(os.WriteFile(script, []byte("#!/bin/sh\ntrap 'echo terminated; exit 3' TERM\necho started\nwhile :; do sleep 0.1; done\n"), 0755)).To(Succeed())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{maxOutputSize: serveMaxJobOutputSize, updated: make(chan struct{})}
+	result := make(chan int, 1)
+	go func() {
+		exitCode, _ := newSelfRunner(script)(ctx, nil, j)
+		result <- exitCode
+	}()
+
+	g.Eventually(func() string {
+		output, _, _ := j.outputSince(0)
+		return string(output)
+	}, 5*time.Second).Should(Equal("started\n"))
+	cancel()
+
+	g.Eventually(result, 5*time.Second).Should(Receive(Equal(3)))
+	output, _, _ := j.outputSince(0)
+	g.Expect(string(output)).To(Equal("started\nterminated\n"))
+}
@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var SetLabelsParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_SET_LABELS_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Image to update - registry/namespace/name:tag, optionally with a digest. Required.",
+		Required:   true,
+	},
+	"labels": {
+		Name:       "labels",
+		EnvVarName: "KBC_SET_LABELS_LABELS",
+		TypeKind:   reflect.Slice,
+		Usage:      "KEY=VALUE labels to add to the image config. Existing labels with the same key are replaced.",
+	},
+	"annotations": {
+		Name:       "annotations",
+		EnvVarName: "KBC_SET_LABELS_ANNOTATIONS",
+		TypeKind:   reflect.Slice,
+		Usage:      "KEY=VALUE annotations to add to the image manifest. Existing annotations with the same key are replaced.",
+	},
+	"destination": {
+		Name:       "destination",
+		ShortName:  "d",
+		EnvVarName: "KBC_SET_LABELS_DESTINATION",
+		TypeKind:   reflect.String,
+		Usage:      "Image to push the updated image to, with a tag. Defaults to --image-url, i.e. its tag is moved to the updated image.",
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_SET_LABELS_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Require HTTPS and verify certificates when accessing the registry.",
+	},
+	"result-path-image-url": {
+		Name:       "result-path-image-url",
+		EnvVarName: "KBC_SET_LABELS_RESULT_PATH_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Write the URL of the updated image into this file.",
+	},
+	"result-path-image-digest": {
+		Name:       "result-path-image-digest",
+		EnvVarName: "KBC_SET_LABELS_RESULT_PATH_IMAGE_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Write the digest of the updated image into this file.",
+	},
+}
+
+type SetLabelsParams struct {
+	ImageUrl              string   `paramName:"image-url"`
+	Labels                []string `paramName:"labels"`
+	Annotations           []string `paramName:"annotations"`
+	Destination           string   `paramName:"destination"`
+	TLSVerify             bool     `paramName:"tls-verify"`
+	ResultPathImageUrl    string   `paramName:"result-path-image-url"`
+	ResultPathImageDigest string   `paramName:"result-path-image-digest"`
+}
+
+type SetLabelsCliWrappers struct {
+	BuildahCli cliWrappers.BuildahCliInterface
+}
+
+type SetLabelsResults struct {
+	ImageUrl string `json:"image_url"`
+	// Digest of the updated image, different from the digest of --image-url
+	Digest string `json:"digest"`
+}
+
+type SetLabels struct {
+	Params        *SetLabelsParams
+	CliWrappers   SetLabelsCliWrappers
+	Results       SetLabelsResults
+	ResultsWriter common.ResultsWriterInterface
+
+	sourceImage      string
+	destinationImage string
+}
+
+func NewSetLabels(cmd *cobra.Command) (*SetLabels, error) {
+	setLabels := &SetLabels{}
+
+	params := &SetLabelsParams{}
+	if err := common.ParseParameters(cmd, SetLabelsParamsConfig, params); err != nil {
+		return nil, err
+	}
+	setLabels.Params = params
+
+	if err := setLabels.initCliWrappers(); err != nil {
+		return nil, err
+	}
+
+	setLabels.ResultsWriter = common.NewResultsWriter()
+
+	return setLabels, nil
+}
+
+func (c *SetLabels) initCliWrappers() error {
+	executor := cliWrappers.NewCliExecutor()
+
+	buildahCli, err := cliWrappers.NewBuildahCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.BuildahCli = buildahCli
+	return nil
+}
+
+// Run executes the command logic.
+func (c *SetLabels) Run() error {
+	common.LogParameters(SetLabelsParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	// buildah doesn't accept references with both a tag and a digest
+	c.sourceImage = common.NormalizeImageRefWithDigest(c.Params.ImageUrl)
+
+	if err := c.updateImage(); err != nil {
+		return err
+	}
+
+	l.Logger.Infof("Pushing updated image %s", c.destinationImage)
+	digest, err := c.CliWrappers.BuildahCli.Push(&cliWrappers.BuildahPushArgs{
+		Image:     c.destinationImage,
+		TLSVerify: &c.Params.TLSVerify,
+	})
+	if err != nil {
+		return fmt.Errorf("pushing updated image: %w", err)
+	}
+
+	c.Results.ImageUrl = c.destinationImage
+	c.Results.Digest = digest
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	if err := c.ResultsWriter.WriteResultString(c.destinationImage, c.Params.ResultPathImageUrl); err != nil {
+		return fmt.Errorf("failed to write image url result: %w", err)
+	}
+	if err := c.ResultsWriter.WriteResultString(digest, c.Params.ResultPathImageDigest); err != nil {
+		return fmt.Errorf("failed to write image digest result: %w", err)
+	}
+
+	return nil
+}
+
+// updateImage pulls the source image and commits it again with the labels and annotations added.
+// The layers and the rest of the image config are carried over by the working container.
+func (c *SetLabels) updateImage() (err error) {
+	err = c.CliWrappers.BuildahCli.Pull(&cliWrappers.BuildahPullArgs{
+		Image:     c.sourceImage,
+		TLSVerify: &c.Params.TLSVerify,
+	})
+	if err != nil {
+		return fmt.Errorf("pulling %s: %w", c.sourceImage, err)
+	}
+
+	container, err := c.CliWrappers.BuildahCli.From(c.sourceImage)
+	if err != nil {
+		return fmt.Errorf("buildah from: %w", err)
+	}
+	defer func() {
+		if rmErr := c.CliWrappers.BuildahCli.Rm(container); rmErr != nil {
+			l.Logger.Warnf("Failed to clean up working container %q: %s", container, rmErr)
+		}
+	}()
+
+	l.Logger.Infof("Setting %d labels and %d annotations on %s", len(c.Params.Labels), len(c.Params.Annotations), c.sourceImage)
+	err = c.CliWrappers.BuildahCli.Config(&cliWrappers.BuildahConfigArgs{
+		Container:   container,
+		Labels:      c.Params.Labels,
+		Annotations: c.Params.Annotations,
+	})
+	if err != nil {
+		return fmt.Errorf("buildah config: %w", err)
+	}
+
+	err = c.CliWrappers.BuildahCli.Commit(&cliWrappers.BuildahCommitArgs{
+		Container: container,
+		Image:     c.destinationImage,
+	})
+	if err != nil {
+		return fmt.Errorf("buildah commit: %w", err)
+	}
+
+	return nil
+}
+
+func (c *SetLabels) validateParams() error {
+	if !common.IsImageNameValid(common.GetImageName(c.Params.ImageUrl)) {
+		return fmt.Errorf("image '%s' is invalid", c.Params.ImageUrl)
+	}
+	if err := common.ValidateImageHasTagOrDigest(c.Params.ImageUrl); err != nil {
+		return fmt.Errorf("image '%s' is invalid: %w", c.Params.ImageUrl, err)
+	}
+
+	if len(c.Params.Labels) == 0 && len(c.Params.Annotations) == 0 {
+		return fmt.Errorf("no labels nor annotations to set")
+	}
+	for _, label := range c.Params.Labels {
+		name, _, found := strings.Cut(label, "=")
+		if !found || !isImageLabelNameValid(name) {
+			return fmt.Errorf("label '%s' is invalid, expected KEY=VALUE", label)
+		}
+	}
+	for _, annotation := range c.Params.Annotations {
+		if name, _, found := strings.Cut(annotation, "="); !found || name == "" {
+			return fmt.Errorf("annotation '%s' is invalid, expected KEY=VALUE", annotation)
+		}
+	}
+
+	destination := c.Params.Destination
+	if destination == "" {
+		destination = c.Params.ImageUrl
+	}
+	ref, err := common.ParseReference(destination)
+	if err != nil {
+		return fmt.Errorf("destination '%s' is invalid: %w", destination, err)
+	}
+	if ref.Tag == "" {
+		return fmt.Errorf("destination '%s' must have a tag, set --destination when --image-url has only a digest", destination)
+	}
+	if ref.Digest != "" && c.Params.Destination != "" {
+		return fmt.Errorf("destination '%s' must not have a digest, the updated image gets a new one", destination)
+	}
+	c.destinationImage = common.GetImageName(destination) + ":" + ref.Tag
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_SetLabels_validateParams(t *testing.T) {
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+
+	tests := []struct {
+		name                string
+		params              SetLabelsParams
+		expectedDestination string
+		errSubstring        string
+	}{
+		{
+			name:                "should push to the tag of the image by default",
+			params:              SetLabelsParams{ImageUrl: "quay.io/org/app:v1@" + digest, Labels: []string{"release=1"}},
+			expectedDestination: "quay.io/org/app:v1",
+		},
+		{
+			name:                "should push to destination",
+			params:              SetLabelsParams{ImageUrl: "quay.io/org/app@" + digest, Annotations: []string{"a=b"}, Destination: "quay.io/org/other:v2"},
+			expectedDestination: "quay.io/org/other:v2",
+		},
+		{
+			name:                "should allow empty label value",
+			params:              SetLabelsParams{ImageUrl: "quay.io/org/app:v1", Labels: []string{"release="}},
+			expectedDestination: "quay.io/org/app:v1",
+		},
+		{
+			name:         "should fail on invalid image",
+			params:       SetLabelsParams{ImageUrl: "quay.io/org/App:v1", Labels: []string{"release=1"}},
+			errSubstring: "is invalid",
+		},
+		{
+			name:         "should fail without labels and annotations",
+			params:       SetLabelsParams{ImageUrl: "quay.io/org/app:v1"},
+			errSubstring: "no labels nor annotations",
+		},
+		{
+			name:         "should fail on label without value",
+			params:       SetLabelsParams{ImageUrl: "quay.io/org/app:v1", Labels: []string{"release"}},
+			errSubstring: "label 'release' is invalid",
+		},
+		{
+			name:         "should fail on invalid label name",
+			params:       SetLabelsParams{ImageUrl: "quay.io/org/app:v1", Labels: []string{"re lease=1"}},
+			errSubstring: "label 're lease=1' is invalid",
+		},
+		{
+			name:         "should fail on annotation without name",
+			params:       SetLabelsParams{ImageUrl: "quay.io/org/app:v1", Annotations: []string{"=value"}},
+			errSubstring: "annotation '=value' is invalid",
+		},
+		{
+			name:         "should fail if image has only digest and no destination",
+			params:       SetLabelsParams{ImageUrl: "quay.io/org/app@" + digest, Labels: []string{"release=1"}},
+			errSubstring: "must have a tag",
+		},
+		{
+			name:         "should fail if destination has digest",
+			params:       SetLabelsParams{ImageUrl: "quay.io/org/app:v1", Labels: []string{"release=1"}, Destination: "quay.io/org/app:v2@" + digest},
+			errSubstring: "must not have a digest",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &SetLabels{Params: &tc.params}
+
+			err := c.validateParams()
+
+			if tc.errSubstring != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.errSubstring))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(c.destinationImage).To(Equal(tc.expectedDestination))
+			}
+		})
+	}
+}
+
+func Test_SetLabels_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	const container = "app-working-container"
+
+	var _mockBuildahCli *mockBuildahCli
+	var _mockResultsWriter *mockResultsWriter
+	var c *SetLabels
+
+	beforeEach := func() {
+		_mockBuildahCli = &mockBuildahCli{}
+		_mockResultsWriter = &mockResultsWriter{}
+		c = &SetLabels{
+			CliWrappers: SetLabelsCliWrappers{BuildahCli: _mockBuildahCli},
+			Params: &SetLabelsParams{
+				ImageUrl:              "quay.io/org/app:v1@" + digest,
+				Labels:                []string{"release=1"},
+				Annotations:           []string{"org.opencontainers.image.url=https://example.com"},
+				TLSVerify:             true,
+				ResultPathImageUrl:    "/tmp/url-result",
+				ResultPathImageDigest: "/tmp/digest-result",
+			},
+			ResultsWriter: _mockResultsWriter,
+		}
+	}
+
+	t.Run("should set labels and push image", func(t *testing.T) {
+		beforeEach()
+
+		var calls []string
+		_mockBuildahCli.PullFunc = func(args *cliwrappers.BuildahPullArgs) error {
+			calls = append(calls, "pull")
+			g.Expect(args.Image).To(Equal("quay.io/org/app@" + digest))
+			g.Expect(*args.TLSVerify).To(BeTrue())
+			return nil
+		}
+		_mockBuildahCli.FromFunc = func(image string) (string, error) {
+			calls = append(calls, "from")
+			g.Expect(image).To(Equal("quay.io/org/app@" + digest))
+			return container, nil
+		}
+		_mockBuildahCli.ConfigFunc = func(args *cliwrappers.BuildahConfigArgs) error {
+			calls = append(calls, "config")
+			g.Expect(args.Container).To(Equal(container))
+			g.Expect(args.Labels).To(Equal([]string{"release=1"}))
+			g.Expect(args.Annotations).To(Equal([]string{"org.opencontainers.image.url=https://example.com"}))
+			return nil
+		}
+		_mockBuildahCli.CommitFunc = func(args *cliwrappers.BuildahCommitArgs) error {
+			calls = append(calls, "commit")
+			g.Expect(args.Container).To(Equal(container))
+			g.Expect(args.Image).To(Equal("quay.io/org/app:v1"))
+			g.Expect(args.Squash).To(BeFalse())
+			return nil
+		}
+		_mockBuildahCli.RmFunc = func(name string) error {
+			calls = append(calls, "rm")
+			g.Expect(name).To(Equal(container))
+			return nil
+		}
+		_mockBuildahCli.PushFunc = func(args *cliwrappers.BuildahPushArgs) (string, error) {
+			calls = append(calls, "push")
+			g.Expect(args.Image).To(Equal("quay.io/org/app:v1"))
+			return "sha256:abcdef", nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(calls).To(Equal([]string{"pull", "from", "config", "commit", "rm", "push"}))
+		g.Expect(c.Results).To(Equal(SetLabelsResults{ImageUrl: "quay.io/org/app:v1", Digest: "sha256:abcdef"}))
+		g.Expect(_mockResultsWriter.WrittenResults).To(Equal(map[string]string{
+			"/tmp/url-result":    "quay.io/org/app:v1",
+			"/tmp/digest-result": "sha256:abcdef",
+		}))
+	})
+
+	t.Run("should remove working container if config fails", func(t *testing.T) {
+		beforeEach()
+
+		isRmCalled := false
+		_mockBuildahCli.FromFunc = func(image string) (string, error) {
+			return container, nil
+		}
+		_mockBuildahCli.ConfigFunc = func(args *cliwrappers.BuildahConfigArgs) error {
+			return errors.New("config failed")
+		}
+		_mockBuildahCli.CommitFunc = func(args *cliwrappers.BuildahCommitArgs) error {
+			t.Fatal("commit should not be called")
+			return nil
+		}
+		_mockBuildahCli.RmFunc = func(name string) error {
+			isRmCalled = true
+			return nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("config failed"))
+		g.Expect(isRmCalled).To(BeTrue())
+	})
+
+	t.Run("should error if push fails", func(t *testing.T) {
+		beforeEach()
+
+		_mockBuildahCli.FromFunc = func(image string) (string, error) {
+			return container, nil
+		}
+		_mockBuildahCli.PushFunc = func(args *cliwrappers.BuildahPushArgs) (string, error) {
+			return "", errors.New("unauthorized")
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("pushing updated image"))
+		g.Expect(_mockResultsWriter.WrittenResults).To(BeEmpty())
+	})
+}
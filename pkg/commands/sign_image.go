@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/cobra"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// cosign predicate types of the attestations, by the SBOM media type
+var cosignSBOMPredicateTypes = map[string]string{
+	"application/vnd.cyclonedx+json": "cyclonedx",
+	"application/spdx+json":          "spdxjson",
+}
+
+const cosignProvenancePredicateType = "slsaprovenance1"
+
+var SignImageParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_SIGN_IMAGE_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Image to sign. Required.",
+		Required:   true,
+	},
+	"digest": {
+		Name:       "digest",
+		ShortName:  "d",
+		EnvVarName: "KBC_SIGN_IMAGE_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Digest of the image to sign. Required unless --image-url is referenced by digest.",
+	},
+	"key": {
+		Name:       "key",
+		ShortName:  "k",
+		EnvVarName: "KBC_SIGN_IMAGE_KEY",
+		TypeKind:   reflect.String,
+		Usage:      "Path or KMS URI of the private key, the key password is read from COSIGN_PASSWORD. Without a key, the image is signed keyless.",
+	},
+	"fulcio-url": {
+		Name:       "fulcio-url",
+		EnvVarName: "KBC_SIGN_IMAGE_FULCIO_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Fulcio instance to get the keyless signing certificate from. Defaults to the public Sigstore instance.",
+	},
+	"rekor-url": {
+		Name:       "rekor-url",
+		EnvVarName: "KBC_SIGN_IMAGE_REKOR_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Rekor transparency log instance. Defaults to the public Sigstore instance.",
+	},
+	"identity-token": {
+		Name:       "identity-token",
+		EnvVarName: "KBC_SIGN_IMAGE_IDENTITY_TOKEN",
+		TypeKind:   reflect.String,
+		Usage:      "Path to the OIDC identity token for keyless signing.",
+	},
+	"tlog-upload": {
+		Name:         "tlog-upload",
+		EnvVarName:   "KBC_SIGN_IMAGE_TLOG_UPLOAD",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Record the signatures in the Rekor transparency log. Keyless signing requires it.",
+	},
+	"sbom": {
+		Name:       "sbom",
+		EnvVarName: "KBC_SIGN_IMAGE_SBOM",
+		TypeKind:   reflect.String,
+		Usage:      "Path to a CycloneDX or SPDX SBOM of the image to attach as a signed attestation.",
+	},
+	"provenance": {
+		Name:       "provenance",
+		EnvVarName: "KBC_SIGN_IMAGE_PROVENANCE",
+		TypeKind:   reflect.String,
+		Usage:      "Path to a SLSA v1 provenance predicate of the image, e.g. written by image generate-provenance, to attach as a signed attestation.",
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_SIGN_IMAGE_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Require HTTPS and verify certificates when accessing the registry.",
+	},
+}
+
+type SignImageParams struct {
+	ImageUrl      string `paramName:"image-url"`
+	Digest        string `paramName:"digest"`
+	Key           string `paramName:"key"`
+	FulcioURL     string `paramName:"fulcio-url"`
+	RekorURL      string `paramName:"rekor-url"`
+	IdentityToken string `paramName:"identity-token"`
+	TlogUpload    bool   `paramName:"tlog-upload"`
+	SBOM          string `paramName:"sbom"`
+	Provenance    string `paramName:"provenance"`
+	TLSVerify     bool   `paramName:"tls-verify"`
+}
+
+type SignImageCliWrappers struct {
+	CosignCli cliWrappers.CosignCliInterface
+}
+
+type SignImageResults struct {
+	ImageRef string `json:"image_ref"`
+	// cosign predicate types of the attached attestations
+	Attestations []string `json:"attestations,omitempty"`
+}
+
+type SignImage struct {
+	Params        *SignImageParams
+	CliWrappers   SignImageCliWrappers
+	Results       SignImageResults
+	ResultsWriter common.ResultsWriterInterface
+
+	imageRef string
+}
+
+func NewSignImage(cmd *cobra.Command) (*SignImage, error) {
+	signImage := &SignImage{}
+
+	params := &SignImageParams{}
+	if err := common.ParseParameters(cmd, SignImageParamsConfig, params); err != nil {
+		return nil, err
+	}
+	signImage.Params = params
+
+	if err := signImage.initCliWrappers(); err != nil {
+		return nil, err
+	}
+
+	signImage.ResultsWriter = common.NewResultsWriter()
+
+	return signImage, nil
+}
+
+func (c *SignImage) initCliWrappers() error {
+	executor := cliWrappers.NewCliExecutor()
+
+	cosignCli, err := cliWrappers.NewCosignCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.CosignCli = cosignCli
+	return nil
+}
+
+// Run executes the command logic.
+func (c *SignImage) Run() error {
+	common.LogParameters(SignImageParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	options := cliWrappers.CosignSigningOptions{
+		Key:                   c.Params.Key,
+		FulcioURL:             c.Params.FulcioURL,
+		RekorURL:              c.Params.RekorURL,
+		IdentityToken:         c.Params.IdentityToken,
+		NoTlogUpload:          !c.Params.TlogUpload,
+		AllowInsecureRegistry: !c.Params.TLSVerify,
+	}
+	if c.Params.Key == "" {
+		l.Logger.Infof("Signing %s keyless", c.imageRef)
+	}
+
+	if err := c.CliWrappers.CosignCli.Sign(&cliWrappers.CosignSignArgs{
+		CosignSigningOptions: options,
+		ImageRef:             c.imageRef,
+	}); err != nil {
+		return fmt.Errorf("signing %s: %w", c.imageRef, err)
+	}
+	l.Logger.Infof("Signed %s", c.imageRef)
+
+	c.Results.ImageRef = c.imageRef
+
+	if c.Params.SBOM != "" {
+		mediaType, err := detectSBOMMediaType(c.Params.SBOM)
+		if err != nil {
+			return err
+		}
+		if err := c.attest(options, c.Params.SBOM, cosignSBOMPredicateTypes[mediaType]); err != nil {
+			return err
+		}
+	}
+	if c.Params.Provenance != "" {
+		if err := c.attest(options, c.Params.Provenance, cosignProvenancePredicateType); err != nil {
+			return err
+		}
+	}
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *SignImage) attest(options cliWrappers.CosignSigningOptions, predicate, predicateType string) error {
+	if err := c.CliWrappers.CosignCli.Attest(&cliWrappers.CosignAttestArgs{
+		CosignSigningOptions: options,
+		ImageRef:             c.imageRef,
+		Predicate:            predicate,
+		Type:                 predicateType,
+	}); err != nil {
+		return fmt.Errorf("attaching %s attestation to %s: %w", predicateType, c.imageRef, err)
+	}
+	l.Logger.Infof("Attached %s attestation %s to %s", predicateType, predicate, c.imageRef)
+	c.Results.Attestations = append(c.Results.Attestations, predicateType)
+	return nil
+}
+
+func (c *SignImage) validateParams() error {
+	imageName := common.GetImageName(c.Params.ImageUrl)
+	if !common.IsImageNameValid(imageName) {
+		return fmt.Errorf("image '%s' is invalid", c.Params.ImageUrl)
+	}
+
+	digest := c.Params.Digest
+	if digest == "" {
+		digest = common.GetImageDigest(c.Params.ImageUrl)
+	}
+	if digest == "" {
+		return fmt.Errorf("image must be signed by digest, use --digest or reference --image-url by digest")
+	}
+	if !common.IsImageDigestValid(digest) {
+		return fmt.Errorf("image digest '%s' is invalid", digest)
+	}
+	c.imageRef = imageName + "@" + digest
+
+	if c.Params.Key == "" && !c.Params.TlogUpload {
+		return fmt.Errorf("keyless signing requires the transparency log upload, provide --key to sign without it")
+	}
+	return nil
+}
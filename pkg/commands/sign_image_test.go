@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_SignImage_validateParams(t *testing.T) {
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+
+	testCases := []struct {
+		name             string
+		params           SignImageParams
+		expectedImageRef string
+		expectedError    string
+	}{
+		{
+			name:             "digest param",
+			params:           SignImageParams{ImageUrl: "quay.io/org/app:v1", Digest: digest, TlogUpload: true},
+			expectedImageRef: "quay.io/org/app@" + digest,
+		},
+		{
+			name:             "digest in image url",
+			params:           SignImageParams{ImageUrl: "quay.io/org/app:v1@" + digest, Key: "cosign.key"},
+			expectedImageRef: "quay.io/org/app@" + digest,
+		},
+		{
+			name:          "invalid image",
+			params:        SignImageParams{ImageUrl: "quay.io/org/App", Digest: digest},
+			expectedError: "image 'quay.io/org/App' is invalid",
+		},
+		{
+			name:          "no digest",
+			params:        SignImageParams{ImageUrl: "quay.io/org/app:v1"},
+			expectedError: "image must be signed by digest",
+		},
+		{
+			name:          "invalid digest",
+			params:        SignImageParams{ImageUrl: "quay.io/org/app", Digest: "sha256:1234"},
+			expectedError: "image digest 'sha256:1234' is invalid",
+		},
+		{
+			name:          "keyless without transparency log",
+			params:        SignImageParams{ImageUrl: "quay.io/org/app", Digest: digest, TlogUpload: false},
+			expectedError: "keyless signing requires the transparency log upload",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &SignImage{Params: &tc.params}
+
+			err := c.validateParams()
+
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(c.imageRef).To(Equal(tc.expectedImageRef))
+		})
+	}
+}
+
+func Test_SignImage_Run(t *testing.T) {
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	const imageRef = "quay.io/org/app@" + digest
+
+	workDir := t.TempDir()
+	sbomPath := filepath.Join(workDir, "sbom.json")
+	os.WriteFile(sbomPath, []byte(`{"spdxVersion": "SPDX-2.3"}`), 0644)
+	provenancePath := filepath.Join(workDir, "provenance.json")
+	os.WriteFile(provenancePath, []byte(`{}`), 0644)
+
+	t.Run("should sign with a key and attach attestations", func(t *testing.T) {
+		g := NewWithT(t)
+		var signArgs *cliwrappers.CosignSignArgs
+		var attestArgs []*cliwrappers.CosignAttestArgs
+		c := &SignImage{
+			Params: &SignImageParams{
+				ImageUrl:   "quay.io/org/app:v1",
+				Digest:     digest,
+				Key:        "/keys/cosign.key",
+				TlogUpload: false,
+				SBOM:       sbomPath,
+				Provenance: provenancePath,
+				TLSVerify:  true,
+			},
+			CliWrappers: SignImageCliWrappers{CosignCli: &mockCosignCli{
+				SignFunc: func(args *cliwrappers.CosignSignArgs) error {
+					signArgs = args
+					return nil
+				},
+				AttestFunc: func(args *cliwrappers.CosignAttestArgs) error {
+					attestArgs = append(attestArgs, args)
+					return nil
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		expectedOptions := cliwrappers.CosignSigningOptions{Key: "/keys/cosign.key", NoTlogUpload: true}
+		g.Expect(signArgs).To(Equal(&cliwrappers.CosignSignArgs{CosignSigningOptions: expectedOptions, ImageRef: imageRef}))
+		g.Expect(attestArgs).To(Equal([]*cliwrappers.CosignAttestArgs{
+			{CosignSigningOptions: expectedOptions, ImageRef: imageRef, Predicate: sbomPath, Type: "spdxjson"},
+			{CosignSigningOptions: expectedOptions, ImageRef: imageRef, Predicate: provenancePath, Type: "slsaprovenance1"},
+		}))
+		g.Expect(c.Results).To(Equal(SignImageResults{ImageRef: imageRef, Attestations: []string{"spdxjson", "slsaprovenance1"}}))
+	})
+
+	t.Run("should sign keyless", func(t *testing.T) {
+		g := NewWithT(t)
+		var signArgs *cliwrappers.CosignSignArgs
+		c := &SignImage{
+			Params: &SignImageParams{
+				ImageUrl:      imageRef,
+				FulcioURL:     "https://fulcio.example.com",
+				RekorURL:      "https://rekor.example.com",
+				IdentityToken: "/var/run/sigstore/token",
+				TlogUpload:    true,
+			},
+			CliWrappers: SignImageCliWrappers{CosignCli: &mockCosignCli{
+				SignFunc: func(args *cliwrappers.CosignSignArgs) error {
+					signArgs = args
+					return nil
+				},
+				AttestFunc: func(args *cliwrappers.CosignAttestArgs) error {
+					return errors.New("unexpected attest")
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		g.Expect(signArgs.CosignSigningOptions).To(Equal(cliwrappers.CosignSigningOptions{
+			FulcioURL:             "https://fulcio.example.com",
+			RekorURL:              "https://rekor.example.com",
+			IdentityToken:         "/var/run/sigstore/token",
+			AllowInsecureRegistry: true,
+		}))
+		g.Expect(c.Results).To(Equal(SignImageResults{ImageRef: imageRef}))
+	})
+
+	t.Run("should fail if signing fails", func(t *testing.T) {
+		g := NewWithT(t)
+		c := &SignImage{
+			Params: &SignImageParams{ImageUrl: imageRef, TlogUpload: true},
+			CliWrappers: SignImageCliWrappers{CosignCli: &mockCosignCli{
+				SignFunc: func(args *cliwrappers.CosignSignArgs) error {
+					return errors.New("exit status 1")
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("signing " + imageRef)))
+	})
+
+	t.Run("should fail on unknown SBOM format", func(t *testing.T) {
+		g := NewWithT(t)
+		unknownSBOM := filepath.Join(t.TempDir(), "sbom.json")
+		os.WriteFile(unknownSBOM, []byte(`{"packages": []}`), 0644)
+		c := &SignImage{
+			Params:        &SignImageParams{ImageUrl: imageRef, TlogUpload: true, SBOM: unknownSBOM},
+			CliWrappers:   SignImageCliWrappers{CosignCli: &mockCosignCli{}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(MatchError(ContainSubstring("is neither a CycloneDX nor an SPDX document")))
+	})
+}
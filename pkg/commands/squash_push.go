@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/cobra"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var SquashPushParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_SQUASH_PUSH_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Image to flatten - registry/namespace/name:tag, optionally with a digest. Required.",
+		Required:   true,
+	},
+	"tag-suffix": {
+		Name:         "tag-suffix",
+		EnvVarName:   "KBC_SQUASH_PUSH_TAG_SUFFIX",
+		TypeKind:     reflect.String,
+		DefaultValue: "-squashed",
+		Usage:        "Suffix appended to the tag of --image-url to form the tag of the flattened image.",
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_SQUASH_PUSH_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Require HTTPS and verify certificates when accessing the registry.",
+	},
+	"result-path-image-url": {
+		Name:       "result-path-image-url",
+		EnvVarName: "KBC_SQUASH_PUSH_RESULT_PATH_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Write the URL of the flattened image into this file.",
+	},
+	"result-path-image-digest": {
+		Name:       "result-path-image-digest",
+		EnvVarName: "KBC_SQUASH_PUSH_RESULT_PATH_IMAGE_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Write the digest of the flattened image into this file.",
+	},
+}
+
+type SquashPushParams struct {
+	ImageUrl              string `paramName:"image-url"`
+	TagSuffix             string `paramName:"tag-suffix"`
+	TLSVerify             bool   `paramName:"tls-verify"`
+	ResultPathImageUrl    string `paramName:"result-path-image-url"`
+	ResultPathImageDigest string `paramName:"result-path-image-digest"`
+}
+
+type SquashPushCliWrappers struct {
+	BuildahCli cliWrappers.BuildahCliInterface
+}
+
+type SquashPushResults struct {
+	ImageUrl string `json:"image_url"`
+	Digest   string `json:"digest"`
+}
+
+type SquashPush struct {
+	Params        *SquashPushParams
+	CliWrappers   SquashPushCliWrappers
+	Results       SquashPushResults
+	ResultsWriter common.ResultsWriterInterface
+
+	sourceImage      string
+	destinationImage string
+}
+
+func NewSquashPush(cmd *cobra.Command) (*SquashPush, error) {
+	squashPush := &SquashPush{}
+
+	params := &SquashPushParams{}
+	if err := common.ParseParameters(cmd, SquashPushParamsConfig, params); err != nil {
+		return nil, err
+	}
+	squashPush.Params = params
+
+	if err := squashPush.initCliWrappers(); err != nil {
+		return nil, err
+	}
+
+	squashPush.ResultsWriter = common.NewResultsWriter()
+
+	return squashPush, nil
+}
+
+func (c *SquashPush) initCliWrappers() error {
+	executor := cliWrappers.NewCliExecutor()
+
+	buildahCli, err := cliWrappers.NewBuildahCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.BuildahCli = buildahCli
+	return nil
+}
+
+// Run executes the command logic.
+func (c *SquashPush) Run() error {
+	common.LogParameters(SquashPushParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	// buildah doesn't accept references with both a tag and a digest
+	c.sourceImage = common.NormalizeImageRefWithDigest(c.Params.ImageUrl)
+
+	if err := c.squashImage(); err != nil {
+		return err
+	}
+
+	l.Logger.Infof("Pushing flattened image %s", c.destinationImage)
+	digest, err := c.CliWrappers.BuildahCli.Push(&cliWrappers.BuildahPushArgs{
+		Image:     c.destinationImage,
+		TLSVerify: &c.Params.TLSVerify,
+	})
+	if err != nil {
+		return fmt.Errorf("pushing flattened image: %w", err)
+	}
+
+	c.Results.ImageUrl = c.destinationImage
+	c.Results.Digest = digest
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	if err := c.ResultsWriter.WriteResultString(c.destinationImage, c.Params.ResultPathImageUrl); err != nil {
+		return fmt.Errorf("failed to write image url result: %w", err)
+	}
+	if err := c.ResultsWriter.WriteResultString(digest, c.Params.ResultPathImageDigest); err != nil {
+		return fmt.Errorf("failed to write image digest result: %w", err)
+	}
+
+	return nil
+}
+
+// squashImage pulls the source image and commits it again with all the layers squashed into one.
+// The image config (entrypoint, env, labels, ...) is carried over by the working container.
+func (c *SquashPush) squashImage() (err error) {
+	err = c.CliWrappers.BuildahCli.Pull(&cliWrappers.BuildahPullArgs{
+		Image:     c.sourceImage,
+		TLSVerify: &c.Params.TLSVerify,
+	})
+	if err != nil {
+		return fmt.Errorf("pulling %s: %w", c.sourceImage, err)
+	}
+
+	container, err := c.CliWrappers.BuildahCli.From(c.sourceImage)
+	if err != nil {
+		return fmt.Errorf("buildah from: %w", err)
+	}
+	defer func() {
+		if rmErr := c.CliWrappers.BuildahCli.Rm(container); rmErr != nil {
+			l.Logger.Warnf("Failed to clean up working container %q: %s", container, rmErr)
+		}
+	}()
+
+	l.Logger.Infof("Squashing %s into a single layer", c.sourceImage)
+	err = c.CliWrappers.BuildahCli.Commit(&cliWrappers.BuildahCommitArgs{
+		Container: container,
+		Image:     c.destinationImage,
+		Squash:    true,
+	})
+	if err != nil {
+		return fmt.Errorf("buildah commit: %w", err)
+	}
+
+	return nil
+}
+
+func (c *SquashPush) validateParams() error {
+	if !common.IsImageNameValid(common.GetImageName(c.Params.ImageUrl)) {
+		return fmt.Errorf("image '%s' is invalid", c.Params.ImageUrl)
+	}
+
+	ref, err := common.ParseReference(c.Params.ImageUrl)
+	if err != nil {
+		return fmt.Errorf("image '%s' is invalid: %w", c.Params.ImageUrl, err)
+	}
+	if ref.Tag == "" {
+		return fmt.Errorf("image '%s' must have a tag, the flattened image tag is derived from it", c.Params.ImageUrl)
+	}
+
+	if c.Params.TagSuffix == "" {
+		return fmt.Errorf("tag-suffix must not be empty, the original image would be overwritten")
+	}
+	tag := ref.Tag + c.Params.TagSuffix
+	if !common.IsImageTagValid(tag) {
+		return fmt.Errorf("tag '%s' of the flattened image is invalid", tag)
+	}
+	c.destinationImage = common.GetImageName(c.Params.ImageUrl) + ":" + tag
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	. "github.com/onsi/gomega"
+)
+
+func Test_SquashPush_validateParams(t *testing.T) {
+	tests := []struct {
+		name                string
+		params              SquashPushParams
+		expectedDestination string
+		errSubstring        string
+	}{
+		{
+			name:                "should append tag suffix",
+			params:              SquashPushParams{ImageUrl: "quay.io/org/app:v1", TagSuffix: "-squashed"},
+			expectedDestination: "quay.io/org/app:v1-squashed",
+		},
+		{
+			name:                "should drop digest from destination",
+			params:              SquashPushParams{ImageUrl: "quay.io/org/app:v1@sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b", TagSuffix: "-flat"},
+			expectedDestination: "quay.io/org/app:v1-flat",
+		},
+		{
+			name:         "should fail on invalid image",
+			params:       SquashPushParams{ImageUrl: "quay.io/org/App:v1", TagSuffix: "-squashed"},
+			errSubstring: "is invalid",
+		},
+		{
+			name:         "should fail if image has no tag",
+			params:       SquashPushParams{ImageUrl: "quay.io/org/app@sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b", TagSuffix: "-squashed"},
+			errSubstring: "must have a tag",
+		},
+		{
+			name:         "should fail on empty tag suffix",
+			params:       SquashPushParams{ImageUrl: "quay.io/org/app:v1"},
+			errSubstring: "tag-suffix must not be empty",
+		},
+		{
+			name:         "should fail on invalid resulting tag",
+			params:       SquashPushParams{ImageUrl: "quay.io/org/app:v1", TagSuffix: "/squashed"},
+			errSubstring: "tag 'v1/squashed' of the flattened image is invalid",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &SquashPush{Params: &tc.params}
+
+			err := c.validateParams()
+
+			if tc.errSubstring != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.errSubstring))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(c.destinationImage).To(Equal(tc.expectedDestination))
+			}
+		})
+	}
+}
+
+func Test_SquashPush_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	const container = "app-working-container"
+
+	var _mockBuildahCli *mockBuildahCli
+	var _mockResultsWriter *mockResultsWriter
+	var c *SquashPush
+
+	beforeEach := func() {
+		_mockBuildahCli = &mockBuildahCli{}
+		_mockResultsWriter = &mockResultsWriter{}
+		c = &SquashPush{
+			CliWrappers: SquashPushCliWrappers{BuildahCli: _mockBuildahCli},
+			Params: &SquashPushParams{
+				ImageUrl:              "quay.io/org/app:v1@" + digest,
+				TagSuffix:             "-squashed",
+				TLSVerify:             true,
+				ResultPathImageUrl:    "/tmp/url-result",
+				ResultPathImageDigest: "/tmp/digest-result",
+			},
+			ResultsWriter: _mockResultsWriter,
+		}
+	}
+
+	t.Run("should squash and push image", func(t *testing.T) {
+		beforeEach()
+
+		var calls []string
+		_mockBuildahCli.PullFunc = func(args *cliwrappers.BuildahPullArgs) error {
+			calls = append(calls, "pull")
+			g.Expect(args.Image).To(Equal("quay.io/org/app@" + digest))
+			g.Expect(*args.TLSVerify).To(BeTrue())
+			return nil
+		}
+		_mockBuildahCli.FromFunc = func(image string) (string, error) {
+			calls = append(calls, "from")
+			g.Expect(image).To(Equal("quay.io/org/app@" + digest))
+			return container, nil
+		}
+		_mockBuildahCli.CommitFunc = func(args *cliwrappers.BuildahCommitArgs) error {
+			calls = append(calls, "commit")
+			g.Expect(args.Container).To(Equal(container))
+			g.Expect(args.Image).To(Equal("quay.io/org/app:v1-squashed"))
+			g.Expect(args.Squash).To(BeTrue())
+			return nil
+		}
+		_mockBuildahCli.RmFunc = func(name string) error {
+			calls = append(calls, "rm")
+			g.Expect(name).To(Equal(container))
+			return nil
+		}
+		_mockBuildahCli.PushFunc = func(args *cliwrappers.BuildahPushArgs) (string, error) {
+			calls = append(calls, "push")
+			g.Expect(args.Image).To(Equal("quay.io/org/app:v1-squashed"))
+			return "sha256:abcdef", nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(calls).To(Equal([]string{"pull", "from", "commit", "rm", "push"}))
+		g.Expect(c.Results).To(Equal(SquashPushResults{ImageUrl: "quay.io/org/app:v1-squashed", Digest: "sha256:abcdef"}))
+		g.Expect(_mockResultsWriter.WrittenResults).To(Equal(map[string]string{
+			"/tmp/url-result":    "quay.io/org/app:v1-squashed",
+			"/tmp/digest-result": "sha256:abcdef",
+		}))
+	})
+
+	t.Run("should remove working container if commit fails", func(t *testing.T) {
+		beforeEach()
+
+		isRmCalled := false
+		_mockBuildahCli.FromFunc = func(image string) (string, error) {
+			return container, nil
+		}
+		_mockBuildahCli.CommitFunc = func(args *cliwrappers.BuildahCommitArgs) error {
+			return errors.New("commit failed")
+		}
+		_mockBuildahCli.RmFunc = func(name string) error {
+			isRmCalled = true
+			return nil
+		}
+		_mockBuildahCli.PushFunc = func(args *cliwrappers.BuildahPushArgs) (string, error) {
+			t.Fatal("push should not be called")
+			return "", nil
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("commit failed"))
+		g.Expect(isRmCalled).To(BeTrue())
+	})
+
+	t.Run("should error if pull fails", func(t *testing.T) {
+		beforeEach()
+
+		_mockBuildahCli.PullFunc = func(args *cliwrappers.BuildahPullArgs) error {
+			return errors.New("unauthorized")
+		}
+
+		err := c.Run()
+
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("pulling quay.io/org/app@" + digest))
+	})
+}
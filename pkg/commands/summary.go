@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var SummaryParamsConfig = map[string]common.Parameter{
+	"results-dir": {
+		Name:       "results-dir",
+		ShortName:  "d",
+		EnvVarName: "KBC_SUMMARY_RESULTS_DIR",
+		TypeKind:   reflect.String,
+		Usage:      "Directory with the results JSON files of the pipeline tasks, e.g. written with --results-file. Each file is named after its task.",
+		Required:   true,
+	},
+	"summary-path": {
+		Name:       "summary-path",
+		ShortName:  "s",
+		EnvVarName: "KBC_SUMMARY_SUMMARY_PATH",
+		TypeKind:   reflect.String,
+		Usage:      "Write the human-readable summary into this file.",
+	},
+}
+
+type SummaryParams struct {
+	ResultsDir  string `paramName:"results-dir"`
+	SummaryPath string `paramName:"summary-path"`
+}
+
+type SummaryResults struct {
+	// The results of each task, by the name of its results file without the .json extension
+	Results map[string]map[string]any `json:"results"`
+}
+
+type Summary struct {
+	Params        *SummaryParams
+	Results       SummaryResults
+	ResultsWriter common.ResultsWriterInterface
+}
+
+func NewSummary(cmd *cobra.Command) (*Summary, error) {
+	params := &SummaryParams{}
+	if err := common.ParseParameters(cmd, SummaryParamsConfig, params); err != nil {
+		return nil, err
+	}
+	return &Summary{
+		Params:        params,
+		ResultsWriter: common.NewResultsWriter(),
+	}, nil
+}
+
+func (c *Summary) Run() error {
+	common.LogParameters(SummaryParamsConfig, c.Params)
+
+	results, err := readResultsFiles(c.Params.ResultsDir)
+	if err != nil {
+		return err
+	}
+	c.Results.Results = results
+
+	summary := formatSummary(results)
+	l.Logger.Infof("Pipeline results summary:\n%s", summary)
+
+	if c.Params.SummaryPath != "" {
+		if err := c.ResultsWriter.WriteResultString(summary, c.Params.SummaryPath); err != nil {
+			return fmt.Errorf("error on writing summary: %w", err)
+		}
+	}
+
+	if resultsJson, err := c.ResultsWriter.CreateResultJson(c.Results); err != nil {
+		return fmt.Errorf("error on creating results JSON: %w", err)
+	} else {
+		fmt.Print(resultsJson)
+	}
+
+	return nil
+}
+
+// Read the results JSON files in the directory. The files that are not a JSON object are skipped,
+// a task that failed may have left a partial or an empty file.
+func readResultsFiles(dir string) (map[string]map[string]any, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("results directory: %w", err)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]map[string]any)
+	for _, path := range paths {
+		content, err := os.ReadFile(path) //nolint:gosec // results file in the given directory
+		if err != nil {
+			return nil, err
+		}
+		var taskResults map[string]any
+		if err := json.Unmarshal(content, &taskResults); err != nil {
+			l.Logger.Warnf("Skipping %s, not a results JSON object: %s", path, err)
+			continue
+		}
+		results[strings.TrimSuffix(filepath.Base(path), ".json")] = taskResults
+	}
+	if len(results) == 0 {
+		l.Logger.Warnf("No results files found in %s", dir)
+	}
+	return results, nil
+}
+
+// Format the results as indented name: value lines, one section per task, sorted by name.
+func formatSummary(results map[string]map[string]any) string {
+	var sb strings.Builder
+	for _, task := range slices.Sorted(maps.Keys(results)) {
+		fmt.Fprintf(&sb, "%s:\n", task)
+		taskResults := results[task]
+		for _, name := range slices.Sorted(maps.Keys(taskResults)) {
+			fmt.Fprintf(&sb, "  %s: %s\n", name, formatResultValue(taskResults[name]))
+		}
+	}
+	return sb.String()
+}
+
+func formatResultValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		return v
+	case []any:
+		var items []string
+		for _, item := range v {
+			items = append(items, formatResultValue(item))
+		}
+		return strings.Join(items, ", ")
+	case map[string]any:
+		content, _ := json.Marshal(v)
+		return string(content)
+	default:
+		return fmt.Sprint(v)
+	}
+}
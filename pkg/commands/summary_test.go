@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/testutil"
+)
+
+func Test_Summary_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	resultsDir := t.TempDir()
+	testutil.WriteFileTree(t, resultsDir, map[string]string{
+		"build.json":              `{"image_url":"quay.io/org/app:v1","image_digest":"sha256:1234","skipped":false}`,
+		"apply-tags.json":         `{"tags":["latest","v1.0"]}`,
+		"push-containerfile.json": `{"image_ref":"quay.io/org/app@sha256:5678","additional_files":null}`,
+		"sbom.json":               ``,
+		"notes.txt":               `not a results file`,
+	})
+
+	resultsWriter := &mockResultsWriter{}
+	c := &Summary{
+		Params: &SummaryParams{
+			ResultsDir:  resultsDir,
+			SummaryPath: "/results/summary",
+		},
+		ResultsWriter: resultsWriter,
+	}
+
+	logOutput := testutil.CaptureLogOutput(func() {
+		g.Expect(c.Run()).To(Succeed())
+	})
+
+	g.Expect(c.Results.Results).To(Equal(map[string]map[string]any{
+		"build":              {"image_url": "quay.io/org/app:v1", "image_digest": "sha256:1234", "skipped": false},
+		"apply-tags":         {"tags": []any{"latest", "v1.0"}},
+		"push-containerfile": {"image_ref": "quay.io/org/app@sha256:5678", "additional_files": nil},
+	}))
+	g.Expect(resultsWriter.WrittenResults).To(HaveKeyWithValue("/results/summary", `apply-tags:
+  tags: latest, v1.0
+build:
+  image_digest: sha256:1234
+  image_url: quay.io/org/app:v1
+  skipped: false
+push-containerfile:
+  additional_files: -
+  image_ref: quay.io/org/app@sha256:5678
+`))
+	g.Expect(logOutput).To(ContainSubstring("Skipping " + filepath.Join(resultsDir, "sbom.json")))
+}
+
+func Test_Summary_Run_missingDir(t *testing.T) {
+	g := NewWithT(t)
+
+	c := &Summary{
+		Params:        &SummaryParams{ResultsDir: filepath.Join(t.TempDir(), "missing")},
+		ResultsWriter: &mockResultsWriter{},
+	}
+
+	g.Expect(c.Run()).To(MatchError(ContainSubstring("results directory")))
+}
+
+func Test_formatResultValue(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(formatResultValue("text")).To(Equal("text"))
+	g.Expect(formatResultValue(nil)).To(Equal("-"))
+	g.Expect(formatResultValue(true)).To(Equal("true"))
+	g.Expect(formatResultValue(float64(3))).To(Equal("3"))
+	g.Expect(formatResultValue([]any{"a", float64(1)})).To(Equal("a, 1"))
+	g.Expect(formatResultValue(map[string]any{"b": "x", "a": float64(1)})).To(Equal(`{"a":1,"b":"x"}`))
+}
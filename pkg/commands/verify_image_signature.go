@@ -0,0 +1,275 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/cobra"
+
+	cliWrappers "github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+var VerifyImageSignatureParamsConfig = map[string]common.Parameter{
+	"image-url": {
+		Name:       "image-url",
+		ShortName:  "i",
+		EnvVarName: "KBC_VERIFY_IMAGE_SIGNATURE_IMAGE_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Image to verify. Required.",
+		Required:   true,
+	},
+	"digest": {
+		Name:       "digest",
+		ShortName:  "d",
+		EnvVarName: "KBC_VERIFY_IMAGE_SIGNATURE_DIGEST",
+		TypeKind:   reflect.String,
+		Usage:      "Digest of the image to verify. Required unless --image-url is referenced by digest.",
+	},
+	"key": {
+		Name:       "key",
+		ShortName:  "k",
+		EnvVarName: "KBC_VERIFY_IMAGE_SIGNATURE_KEY",
+		TypeKind:   reflect.String,
+		Usage:      "Path or KMS URI of the public key. Without a key, the keyless signing certificate must match the certificate identity and OIDC issuer.",
+	},
+	"certificate-identity": {
+		Name:       "certificate-identity",
+		EnvVarName: "KBC_VERIFY_IMAGE_SIGNATURE_CERTIFICATE_IDENTITY",
+		TypeKind:   reflect.String,
+		Usage:      "Identity (e.g. email or workflow URI) the keyless signing certificate must be issued for.",
+	},
+	"certificate-identity-regexp": {
+		Name:       "certificate-identity-regexp",
+		EnvVarName: "KBC_VERIFY_IMAGE_SIGNATURE_CERTIFICATE_IDENTITY_REGEXP",
+		TypeKind:   reflect.String,
+		Usage:      "Regular expression the identity of the keyless signing certificate must match.",
+	},
+	"certificate-oidc-issuer": {
+		Name:       "certificate-oidc-issuer",
+		EnvVarName: "KBC_VERIFY_IMAGE_SIGNATURE_CERTIFICATE_OIDC_ISSUER",
+		TypeKind:   reflect.String,
+		Usage:      "OIDC issuer of the identity of the keyless signing certificate.",
+	},
+	"certificate-oidc-issuer-regexp": {
+		Name:       "certificate-oidc-issuer-regexp",
+		EnvVarName: "KBC_VERIFY_IMAGE_SIGNATURE_CERTIFICATE_OIDC_ISSUER_REGEXP",
+		TypeKind:   reflect.String,
+		Usage:      "Regular expression the OIDC issuer of the keyless signing certificate must match.",
+	},
+	"rekor-url": {
+		Name:       "rekor-url",
+		EnvVarName: "KBC_VERIFY_IMAGE_SIGNATURE_REKOR_URL",
+		TypeKind:   reflect.String,
+		Usage:      "Rekor transparency log instance. Defaults to the public Sigstore instance.",
+	},
+	"ignore-tlog": {
+		Name:         "ignore-tlog",
+		EnvVarName:   "KBC_VERIFY_IMAGE_SIGNATURE_IGNORE_TLOG",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "false",
+		Usage:        "Don't require the signatures to be recorded in the transparency log, for images signed with a key and --tlog-upload=false.",
+	},
+	"signature": {
+		Name:         "signature",
+		EnvVarName:   "KBC_VERIFY_IMAGE_SIGNATURE_SIGNATURE",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Verify the signature of the image. Set to false to verify only the --attestation-types.",
+	},
+	"attestation-types": {
+		Name:       "attestation-types",
+		EnvVarName: "KBC_VERIFY_IMAGE_SIGNATURE_ATTESTATION_TYPES",
+		TypeKind:   reflect.Slice,
+		Usage:      "Predicate types of the attestations to verify, e.g. slsaprovenance1, spdxjson or cyclonedx.",
+	},
+	"tls-verify": {
+		Name:         "tls-verify",
+		EnvVarName:   "KBC_VERIFY_IMAGE_SIGNATURE_TLS_VERIFY",
+		TypeKind:     reflect.Bool,
+		DefaultValue: "true",
+		Usage:        "Require HTTPS and verify certificates when accessing the registry.",
+	},
+}
+
+type VerifyImageSignatureParams struct {
+	ImageUrl                    string   `paramName:"image-url"`
+	Digest                      string   `paramName:"digest"`
+	Key                         string   `paramName:"key"`
+	CertificateIdentity         string   `paramName:"certificate-identity"`
+	CertificateIdentityRegexp   string   `paramName:"certificate-identity-regexp"`
+	CertificateOIDCIssuer       string   `paramName:"certificate-oidc-issuer"`
+	CertificateOIDCIssuerRegexp string   `paramName:"certificate-oidc-issuer-regexp"`
+	RekorURL                    string   `paramName:"rekor-url"`
+	IgnoreTlog                  bool     `paramName:"ignore-tlog"`
+	Signature                   bool     `paramName:"signature"`
+	AttestationTypes            []string `paramName:"attestation-types"`
+	TLSVerify                   bool     `paramName:"tls-verify"`
+}
+
+type VerifyImageSignatureCliWrappers struct {
+	CosignCli cliWrappers.CosignCliInterface
+}
+
+type VerifyImageSignatureResults struct {
+	ImageRef string `json:"image_ref"`
+	// The signature of the image was verified
+	SignatureVerified bool `json:"signature_verified"`
+	// Predicate types of the verified attestations
+	Attestations []string `json:"attestations,omitempty"`
+}
+
+type VerifyImageSignature struct {
+	Params        *VerifyImageSignatureParams
+	CliWrappers   VerifyImageSignatureCliWrappers
+	Results       VerifyImageSignatureResults
+	ResultsWriter common.ResultsWriterInterface
+
+	imageRef string
+}
+
+func NewVerifyImageSignature(cmd *cobra.Command) (*VerifyImageSignature, error) {
+	verifyImageSignature := &VerifyImageSignature{}
+
+	params := &VerifyImageSignatureParams{}
+	if err := common.ParseParameters(cmd, VerifyImageSignatureParamsConfig, params); err != nil {
+		return nil, err
+	}
+	verifyImageSignature.Params = params
+
+	if err := verifyImageSignature.initCliWrappers(); err != nil {
+		return nil, err
+	}
+
+	verifyImageSignature.ResultsWriter = common.NewResultsWriter()
+
+	return verifyImageSignature, nil
+}
+
+func (c *VerifyImageSignature) initCliWrappers() error {
+	executor := cliWrappers.NewCliExecutor()
+
+	cosignCli, err := cliWrappers.NewCosignCli(executor)
+	if err != nil {
+		return err
+	}
+	c.CliWrappers.CosignCli = cosignCli
+	return nil
+}
+
+// Run executes the command logic.
+func (c *VerifyImageSignature) Run() error {
+	common.LogParameters(VerifyImageSignatureParamsConfig, c.Params)
+
+	if err := c.validateParams(); err != nil {
+		return kbcerrors.NewValidationError(err)
+	}
+
+	options := c.verifyOptions()
+	c.Results.ImageRef = c.imageRef
+
+	if c.Params.Signature {
+		if err := verifyImageSignature(c.CliWrappers.CosignCli, options, c.imageRef); err != nil {
+			return err
+		}
+		c.Results.SignatureVerified = true
+	}
+
+	for _, predicateType := range c.Params.AttestationTypes {
+		if err := c.CliWrappers.CosignCli.VerifyAttestation(&cliWrappers.CosignVerifyAttestationArgs{
+			CosignVerifyOptions: options,
+			ImageRef:            c.imageRef,
+			Type:                predicateType,
+		}); err != nil {
+			return fmt.Errorf("verifying %s attestation of %s: %w", predicateType, c.imageRef, err)
+		}
+		l.Logger.Infof("Verified %s attestation of %s", predicateType, c.imageRef)
+		c.Results.Attestations = append(c.Results.Attestations, predicateType)
+	}
+
+	if resultJson, err := c.ResultsWriter.CreateResultJson(c.Results); err == nil {
+		fmt.Print(resultJson)
+	} else {
+		l.Logger.Errorf("failed to create results json: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *VerifyImageSignature) verifyOptions() cliWrappers.CosignVerifyOptions {
+	return cliWrappers.CosignVerifyOptions{
+		Key:                         c.Params.Key,
+		CertificateIdentity:         c.Params.CertificateIdentity,
+		CertificateIdentityRegexp:   c.Params.CertificateIdentityRegexp,
+		CertificateOIDCIssuer:       c.Params.CertificateOIDCIssuer,
+		CertificateOIDCIssuerRegexp: c.Params.CertificateOIDCIssuerRegexp,
+		RekorURL:                    c.Params.RekorURL,
+		IgnoreTlog:                  c.Params.IgnoreTlog,
+		AllowInsecureRegistry:       !c.Params.TLSVerify,
+	}
+}
+
+func (c *VerifyImageSignature) validateParams() error {
+	imageName := common.GetImageName(c.Params.ImageUrl)
+	if !common.IsImageNameValid(imageName) {
+		return fmt.Errorf("image '%s' is invalid", c.Params.ImageUrl)
+	}
+
+	digest := c.Params.Digest
+	if digest == "" {
+		digest = common.GetImageDigest(c.Params.ImageUrl)
+	}
+	if digest == "" {
+		return fmt.Errorf("image must be verified by digest, use --digest or reference --image-url by digest")
+	}
+	if !common.IsImageDigestValid(digest) {
+		return fmt.Errorf("image digest '%s' is invalid", digest)
+	}
+	c.imageRef = imageName + "@" + digest
+
+	if !c.Params.Signature && len(c.Params.AttestationTypes) == 0 {
+		return fmt.Errorf("nothing to verify, --signature=false requires --attestation-types")
+	}
+
+	return validateCosignVerifyOptions(c.verifyOptions())
+}
+
+// Check that the signatures are verified either with a key or with complete keyless identity constraints.
+// Used also by apply-tags --require-signature.
+func validateCosignVerifyOptions(options cliWrappers.CosignVerifyOptions) error {
+	if options.CertificateIdentity != "" && options.CertificateIdentityRegexp != "" {
+		return errors.New("certificate-identity and certificate-identity-regexp are mutually exclusive")
+	}
+	if options.CertificateOIDCIssuer != "" && options.CertificateOIDCIssuerRegexp != "" {
+		return errors.New("certificate-oidc-issuer and certificate-oidc-issuer-regexp are mutually exclusive")
+	}
+
+	hasIdentity := options.CertificateIdentity != "" || options.CertificateIdentityRegexp != ""
+	hasIssuer := options.CertificateOIDCIssuer != "" || options.CertificateOIDCIssuerRegexp != ""
+	if options.Key != "" {
+		if hasIdentity || hasIssuer {
+			return errors.New("the certificate identity constraints apply to keyless signatures, they can't be used with a key")
+		}
+		return nil
+	}
+	if !hasIdentity || !hasIssuer {
+		return errors.New("keyless verification requires the certificate identity and the certificate OIDC issuer, or provide a key")
+	}
+	return nil
+}
+
+// Verify the cosign signature of the image, the error fails the command.
+func verifyImageSignature(cosignCli cliWrappers.CosignCliInterface, options cliWrappers.CosignVerifyOptions, imageRef string) error {
+	if err := cosignCli.Verify(&cliWrappers.CosignVerifyArgs{
+		CosignVerifyOptions: options,
+		ImageRef:            imageRef,
+	}); err != nil {
+		return fmt.Errorf("verifying signature of %s: %w", imageRef, err)
+	}
+	l.Logger.Infof("Verified signature of %s", imageRef)
+	return nil
+}
@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/cliwrappers"
+)
+
+func Test_VerifyImageSignature_validateParams(t *testing.T) {
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+
+	testCases := []struct {
+		name             string
+		params           VerifyImageSignatureParams
+		expectedImageRef string
+		expectedError    string
+	}{
+		{
+			name:             "key and digest param",
+			params:           VerifyImageSignatureParams{ImageUrl: "quay.io/org/app:v1", Digest: digest, Key: "cosign.pub", Signature: true},
+			expectedImageRef: "quay.io/org/app@" + digest,
+		},
+		{
+			name: "keyless with digest in image url",
+			params: VerifyImageSignatureParams{
+				ImageUrl:                  "quay.io/org/app:v1@" + digest,
+				CertificateIdentityRegexp: "^https://github.com/org/",
+				CertificateOIDCIssuer:     "https://token.actions.githubusercontent.com",
+				Signature:                 true,
+			},
+			expectedImageRef: "quay.io/org/app@" + digest,
+		},
+		{
+			name:          "invalid image",
+			params:        VerifyImageSignatureParams{ImageUrl: "quay.io/org/App", Digest: digest, Key: "cosign.pub", Signature: true},
+			expectedError: "image 'quay.io/org/App' is invalid",
+		},
+		{
+			name:          "no digest",
+			params:        VerifyImageSignatureParams{ImageUrl: "quay.io/org/app:v1", Key: "cosign.pub", Signature: true},
+			expectedError: "image must be verified by digest",
+		},
+		{
+			name:          "invalid digest",
+			params:        VerifyImageSignatureParams{ImageUrl: "quay.io/org/app", Digest: "sha256:1234", Key: "cosign.pub", Signature: true},
+			expectedError: "image digest 'sha256:1234' is invalid",
+		},
+		{
+			name:          "nothing to verify",
+			params:        VerifyImageSignatureParams{ImageUrl: "quay.io/org/app", Digest: digest, Key: "cosign.pub"},
+			expectedError: "nothing to verify",
+		},
+		{
+			name:          "keyless without issuer",
+			params:        VerifyImageSignatureParams{ImageUrl: "quay.io/org/app", Digest: digest, CertificateIdentity: "me@example.com", Signature: true},
+			expectedError: "keyless verification requires the certificate identity and the certificate OIDC issuer",
+		},
+		{
+			name: "key with identity",
+			params: VerifyImageSignatureParams{
+				ImageUrl: "quay.io/org/app", Digest: digest, Key: "cosign.pub", CertificateIdentity: "me@example.com", Signature: true,
+			},
+			expectedError: "can't be used with a key",
+		},
+		{
+			name: "identity and identity regexp",
+			params: VerifyImageSignatureParams{
+				ImageUrl: "quay.io/org/app", Digest: digest, Signature: true,
+				CertificateIdentity: "me@example.com", CertificateIdentityRegexp: ".*", CertificateOIDCIssuer: "https://issuer",
+			},
+			expectedError: "certificate-identity and certificate-identity-regexp are mutually exclusive",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := &VerifyImageSignature{Params: &tc.params}
+
+			err := c.validateParams()
+
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(c.imageRef).To(Equal(tc.expectedImageRef))
+		})
+	}
+}
+
+func Test_VerifyImageSignature_Run(t *testing.T) {
+	const digest = "sha256:e7afdb605d0685d214876ae9d13ae0cc15da3a766be86e919fecee4032b9783b"
+	const imageRef = "quay.io/org/app@" + digest
+
+	t.Run("should verify the signature and the attestations", func(t *testing.T) {
+		g := NewWithT(t)
+		var verifyArgs *cliwrappers.CosignVerifyArgs
+		var attestationArgs []*cliwrappers.CosignVerifyAttestationArgs
+		c := &VerifyImageSignature{
+			Params: &VerifyImageSignatureParams{
+				ImageUrl:         "quay.io/org/app:v1",
+				Digest:           digest,
+				Key:              "/keys/cosign.pub",
+				IgnoreTlog:       true,
+				Signature:        true,
+				AttestationTypes: []string{"slsaprovenance1", "spdxjson"},
+				TLSVerify:        true,
+			},
+			CliWrappers: VerifyImageSignatureCliWrappers{CosignCli: &mockCosignCli{
+				VerifyFunc: func(args *cliwrappers.CosignVerifyArgs) error {
+					verifyArgs = args
+					return nil
+				},
+				VerifyAttestationFunc: func(args *cliwrappers.CosignVerifyAttestationArgs) error {
+					attestationArgs = append(attestationArgs, args)
+					return nil
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+
+		expectedOptions := cliwrappers.CosignVerifyOptions{Key: "/keys/cosign.pub", IgnoreTlog: true}
+		g.Expect(verifyArgs).To(Equal(&cliwrappers.CosignVerifyArgs{CosignVerifyOptions: expectedOptions, ImageRef: imageRef}))
+		g.Expect(attestationArgs).To(Equal([]*cliwrappers.CosignVerifyAttestationArgs{
+			{CosignVerifyOptions: expectedOptions, ImageRef: imageRef, Type: "slsaprovenance1"},
+			{CosignVerifyOptions: expectedOptions, ImageRef: imageRef, Type: "spdxjson"},
+		}))
+		g.Expect(c.Results).To(Equal(VerifyImageSignatureResults{
+			ImageRef:          imageRef,
+			SignatureVerified: true,
+			Attestations:      []string{"slsaprovenance1", "spdxjson"},
+		}))
+	})
+
+	t.Run("should verify only the attestations", func(t *testing.T) {
+		g := NewWithT(t)
+		c := &VerifyImageSignature{
+			Params: &VerifyImageSignatureParams{
+				ImageUrl:                    imageRef,
+				CertificateIdentity:         "https://github.com/org/app/.github/workflows/build.yaml@refs/heads/main",
+				CertificateOIDCIssuerRegexp: "^https://token",
+				AttestationTypes:            []string{"cyclonedx"},
+			},
+			CliWrappers: VerifyImageSignatureCliWrappers{CosignCli: &mockCosignCli{
+				VerifyFunc: func(args *cliwrappers.CosignVerifyArgs) error {
+					return errors.New("unexpected verify")
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		g.Expect(c.Run()).To(Succeed())
+		g.Expect(c.Results).To(Equal(VerifyImageSignatureResults{ImageRef: imageRef, Attestations: []string{"cyclonedx"}}))
+	})
+
+	t.Run("should fail if the signature verification fails", func(t *testing.T) {
+		g := NewWithT(t)
+		c := &VerifyImageSignature{
+			Params: &VerifyImageSignatureParams{
+				ImageUrl:         imageRef,
+				Key:              "/keys/cosign.pub",
+				Signature:        true,
+				AttestationTypes: []string{"slsaprovenance1"},
+			},
+			CliWrappers: VerifyImageSignatureCliWrappers{CosignCli: &mockCosignCli{
+				VerifyFunc: func(args *cliwrappers.CosignVerifyArgs) error {
+					return errors.New("no matching signatures")
+				},
+				VerifyAttestationFunc: func(args *cliwrappers.CosignVerifyAttestationArgs) error {
+					return errors.New("unexpected verify-attestation")
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("verifying signature of " + imageRef + ": no matching signatures")))
+	})
+
+	t.Run("should fail if an attestation verification fails", func(t *testing.T) {
+		g := NewWithT(t)
+		c := &VerifyImageSignature{
+			Params: &VerifyImageSignatureParams{
+				ImageUrl:         imageRef,
+				Key:              "/keys/cosign.pub",
+				AttestationTypes: []string{"slsaprovenance1"},
+			},
+			CliWrappers: VerifyImageSignatureCliWrappers{CosignCli: &mockCosignCli{
+				VerifyAttestationFunc: func(args *cliwrappers.CosignVerifyAttestationArgs) error {
+					return errors.New("none of the attestations matched the predicate type")
+				},
+			}},
+			ResultsWriter: &mockResultsWriter{},
+		}
+
+		err := c.Run()
+		g.Expect(err).To(MatchError(ContainSubstring("verifying slsaprovenance1 attestation of " + imageRef)))
+	})
+}
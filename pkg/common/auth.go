@@ -1,11 +1,16 @@
 package common
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
 
 const (
@@ -13,17 +18,69 @@ const (
 	registryIndexDockerIO = "https://index.docker.io/v1/"
 )
 
+// Username returned by credential helpers for an identity token instead of a password
+const credentialHelperTokenUsername = "<token>"
+
 type RegistryAuth struct {
 	Registry string
-	Token    string
+	// Base64 encoded username:password
+	Token string
+	// OAuth2 refresh token, exchanged for an access token by the client
+	IdentityToken string
+	// Bearer token sent to the registry as is
+	RegistryToken string
+}
+
+// AuthEntry returns the auth file entry of the credential.
+func (a *RegistryAuth) AuthEntry() AuthEntry {
+	return AuthEntry{Auth: a.Token, IdentityToken: a.IdentityToken, RegistryToken: a.RegistryToken}
 }
 
 type RegistryAuths struct {
-	Auths map[string]AuthEntry `json:"auths"`
+	Auths       map[string]AuthEntry `json:"auths"`
+	CredHelpers map[string]string    `json:"credHelpers,omitempty"`
+	CredsStore  string               `json:"credsStore,omitempty"`
 }
 
 type AuthEntry struct {
-	Auth string `json:"auth"`
+	Auth          string `json:"auth,omitempty"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+	RegistryToken string `json:"registrytoken,omitempty"`
+}
+
+// Docker writes empty entries for the registries whose credentials are kept by a credential helper
+func (e *AuthEntry) isEmpty() bool {
+	return e.Auth == "" && e.Username == "" && e.IdentityToken == "" && e.RegistryToken == ""
+}
+
+func (e *AuthEntry) toRegistryAuth(registry string) *RegistryAuth {
+	token := e.Auth
+	if token == "" && e.Username != "" {
+		token = base64.StdEncoding.EncodeToString([]byte(e.Username + ":" + e.Password))
+	}
+	return &RegistryAuth{
+		Registry:      registry,
+		Token:         token,
+		IdentityToken: e.IdentityToken,
+		RegistryToken: e.RegistryToken,
+	}
+}
+
+// Runs docker-credential-<helper> get for serverURL and returns its output. Replaced in tests.
+var runCredentialHelper = func(helper, serverURL string) ([]byte, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get") //nolint:gosec // helper is from the auth file
+	cmd.Stdin = strings.NewReader(serverURL)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		// The helpers print the error message to stdout
+		message := strings.TrimSpace(string(output) + " " + stderr.String())
+		return nil, fmt.Errorf("docker-credential-%s get %s: %w: %s", helper, serverURL, err, message)
+	}
+	return output, nil
 }
 
 // SelectRegistryAuth selects registry authentication credential from an authentication file.
@@ -50,32 +107,36 @@ func SelectRegistryAuth(imageRef string, authFilePath string) (*RegistryAuth, er
 		return nil, err
 	}
 
-	token := findAuth(registryAuths, imageRepo)
-	if token == "" {
-		return nil, fmt.Errorf("registry authentication is not configured for %s", imageRepo)
+	registry := strings.Split(imageRepo, "/")[0]
+	if authEntry := findAuth(registryAuths, imageRepo); authEntry != nil {
+		return authEntry.toRegistryAuth(registry), nil
 	}
 
-	return &RegistryAuth{
-		Registry: strings.Split(imageRepo, "/")[0],
-		Token:    token,
-	}, nil
+	registryAuth, err := getCredentialHelperAuth(registryAuths, registry)
+	if err != nil {
+		return nil, err
+	}
+	if registryAuth == nil {
+		return nil, fmt.Errorf("registry authentication is not configured for %s", imageRepo)
+	}
+	return registryAuth, nil
 }
 
 // SelectRegistryAuthFromDefaultAuthFile selects authentication credential from default
-// authentication file ~/.docker/config.json. Refer to SelectRegistryAuth for more details.
+// authentication file ~/.docker/config.json (or $DOCKER_CONFIG/config.json). Refer to SelectRegistryAuth for more details.
 func SelectRegistryAuthFromDefaultAuthFile(imageRef string) (*RegistryAuth, error) {
 	authFile := GetDefaultAuthFile()
 	return SelectRegistryAuth(imageRef, authFile)
 }
 
-// findAuth finds out authentication credential string by image repository.
+// findAuth finds out authentication credential entry by image repository.
 // Argument registryAuths contains loaded authentication credentials loaded from authfile.
-// If nothing is found, returns an empty string.
-func findAuth(registryAuths *RegistryAuths, imageRepo string) string {
+// If nothing is found, returns nil.
+func findAuth(registryAuths *RegistryAuths, imageRepo string) *AuthEntry {
 	authKey := imageRepo
 	for {
-		if authEntry, exists := registryAuths.Auths[authKey]; exists {
-			return authEntry.Auth
+		if authEntry, exists := registryAuths.Auths[authKey]; exists && !authEntry.isEmpty() {
+			return &authEntry
 		}
 		index := strings.LastIndex(authKey, "/")
 		if index < 0 {
@@ -86,14 +147,65 @@ func findAuth(registryAuths *RegistryAuths, imageRepo string) string {
 	// When log into dockerhub, oras-login writes https://index.docker.io/v1/ as registry into authfile.
 	registry := strings.Split(imageRepo, "/")[0]
 	if registry == registryDockerIO {
-		if authEntry, exists := registryAuths.Auths[registryIndexDockerIO]; exists {
-			return authEntry.Auth
+		if authEntry, exists := registryAuths.Auths[registryIndexDockerIO]; exists && !authEntry.isEmpty() {
+			return &authEntry
 		}
 	}
-	return ""
+	return nil
 }
 
+// getCredentialHelperAuth gets the credential of the registry from the credential helper configured
+// for it in credHelpers, or from the default credsStore. Returns nil if there's no helper or the helper
+// has no credential for the registry.
+func getCredentialHelperAuth(registryAuths *RegistryAuths, registry string) (*RegistryAuth, error) {
+	// Docker stores the dockerhub credential under the index URL
+	serverURL := registry
+	if registry == registryDockerIO {
+		serverURL = registryIndexDockerIO
+	}
+
+	helper, exists := registryAuths.CredHelpers[registry]
+	if !exists {
+		helper, exists = registryAuths.CredHelpers[serverURL]
+	}
+	if !exists {
+		helper = registryAuths.CredsStore
+	}
+	if helper == "" {
+		return nil, nil
+	}
+
+	output, err := runCredentialHelper(helper, serverURL)
+	if err != nil {
+		// The helpers report a missing credential as an error, with this message
+		if strings.Contains(err.Error(), "credentials not found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var credential struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(output, &credential); err != nil {
+		return nil, fmt.Errorf("parsing output of docker-credential-%s: %w", helper, err)
+	}
+	if credential.Secret == "" {
+		return nil, nil
+	}
+	if credential.Username == credentialHelperTokenUsername {
+		return &RegistryAuth{Registry: registry, IdentityToken: credential.Secret}, nil
+	}
+	authEntry := AuthEntry{Username: credential.Username, Password: credential.Secret}
+	return authEntry.toRegistryAuth(registry), nil
+}
+
+// GetDefaultAuthFile returns the docker config file, $DOCKER_CONFIG/config.json if DOCKER_CONFIG is set.
 func GetDefaultAuthFile() string {
+	if dockerConfig := os.Getenv("DOCKER_CONFIG"); dockerConfig != "" {
+		return filepath.Join(dockerConfig, "config.json")
+	}
 	homeDir, _ := os.UserHomeDir()
 	return filepath.Join(homeDir, ".docker", "config.json")
 }
@@ -112,3 +224,84 @@ func readAuthFile(authFilePath string) (*RegistryAuths, error) {
 
 	return &registryAuths, nil
 }
+
+// WriteRegistryAuth sets the auth entry of the registry in the auth file, keeping the other entries
+// and settings. The file and its directory are created if they don't exist.
+//
+// The registry may include a namespace or repository, e.g. quay.io/org, for repository specific credentials.
+func WriteRegistryAuth(authFilePath string, registry string, entry AuthEntry) error {
+	authFile := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(authFilePath) //nolint:gosec // auth file path is from controlled config
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &authFile); err != nil {
+			return fmt.Errorf("parsing auth file %s: %w", authFilePath, err)
+		}
+	}
+
+	auths := make(map[string]json.RawMessage)
+	if rawAuths, ok := authFile["auths"]; ok {
+		if err := json.Unmarshal(rawAuths, &auths); err != nil {
+			return fmt.Errorf("parsing auths of auth file %s: %w", authFilePath, err)
+		}
+	}
+	if auths[registry], err = json.Marshal(entry); err != nil {
+		return err
+	}
+	if authFile["auths"], err = json.Marshal(auths); err != nil {
+		return err
+	}
+
+	data, err = json.MarshalIndent(authFile, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(authFilePath), 0700); err != nil {
+		return err
+	}
+	// Replace the file atomically, other processes may be reading it
+	tmpFile, err := os.CreateTemp(filepath.Dir(authFilePath), filepath.Base(authFilePath)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), authFilePath)
+}
+
+// WriteOrasRegistryConfig writes a registry config for oras with the credentials for imageUrl selected from the default
+// auth file. Returns the path of the config, the caller is responsible for removing it.
+func WriteOrasRegistryConfig(imageUrl string) (string, error) {
+	l.Logger.Debugf("Select registry authentication for %s", imageUrl)
+	registryAuth, err := SelectRegistryAuthFromDefaultAuthFile(imageUrl)
+	if err != nil {
+		return "", fmt.Errorf("cannot select registry authentication for image %s: %w", imageUrl, err)
+	}
+
+	registryConfigFile, err := os.CreateTemp("", "oras-push-registry-config-*")
+	if err != nil {
+		return "", fmt.Errorf("error on creating temporary file for registry config: %w", err)
+	}
+	registryConfig, err := json.Marshal(RegistryAuths{
+		Auths: map[string]AuthEntry{registryAuth.Registry: registryAuth.AuthEntry()},
+	})
+	if err == nil {
+		_, err = registryConfigFile.Write(registryConfig)
+	}
+	if closeErr := registryConfigFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(registryConfigFile.Name())
+		return "", fmt.Errorf("error on writing registry config file: %w", err)
+	}
+	return registryConfigFile.Name(), nil
+}
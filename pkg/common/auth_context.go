@@ -0,0 +1,222 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Set for a process that replaces the current one (e.g. image build re-executing itself
+// in a user namespace), so that it takes over the auth context including its cleanup.
+const envVarAuthContextDir = "_KBC_AUTH_CONTEXT_DIR"
+
+// The auth context of the running command, see SetupRegistryAuthContext.
+var registryAuthContext *RegistryAuthContext
+
+// RegistryAuthContext is a temporary DOCKER_CONFIG directory that exists only for the lifetime
+// of the command. It merges the authentication files given to the command with the ones
+// configured in the environment, without ever modifying $HOME/.docker.
+//
+// DOCKER_CONFIG is honored by oras, REGISTRY_AUTH_FILE by buildah and skopeo. Both are set
+// in the environment of the process, so all the CLI wrappers pick them up.
+type RegistryAuthContext struct {
+	Dir string
+
+	previousEnv map[string]*string
+}
+
+// NewRegistryAuthContext creates the temporary DOCKER_CONFIG, merging the authentication files
+// configured in the environment with authFiles. Entries from later files take precedence.
+//
+// Each element of authFiles is a docker config file or a directory containing config.json
+// or .dockerconfigjson (e.g. a mounted kubernetes.io/dockerconfigjson secret).
+func NewRegistryAuthContext(authFiles []string) (*RegistryAuthContext, error) {
+	if dir := os.Getenv(envVarAuthContextDir); dir != "" {
+		// Don't pass it on to child processes, they must not remove the directory
+		_ = os.Unsetenv(envVarAuthContextDir)
+		if _, err := os.Stat(filepath.Join(dir, "config.json")); err == nil {
+			// Created by the process that was replaced by this one
+			return &RegistryAuthContext{Dir: dir}, nil
+		}
+	}
+
+	sources := make([]string, 0, len(authFiles)+2)
+	sources = append(sources, GetDefaultAuthFile())
+	if registryAuthFile := os.Getenv("REGISTRY_AUTH_FILE"); registryAuthFile != "" {
+		sources = append(sources, registryAuthFile)
+	}
+	for _, authFile := range authFiles {
+		resolved, err := ResolveAuthFile(authFile)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, resolved)
+	}
+
+	merged := rawAuthFile{Auths: make(map[string]json.RawMessage), CredHelpers: make(map[string]string)}
+	for i, source := range sources {
+		sourceAuthFile, err := readRawAuthFile(source)
+		if err != nil {
+			// The files from the environment are optional
+			if i < len(sources)-len(authFiles) && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("reading auth file %s: %w", source, err)
+		}
+		for registry, entry := range sourceAuthFile.Auths {
+			merged.Auths[registry] = entry
+		}
+		for registry, helper := range sourceAuthFile.CredHelpers {
+			merged.CredHelpers[registry] = helper
+		}
+		if sourceAuthFile.CredsStore != "" {
+			merged.CredsStore = sourceAuthFile.CredsStore
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "kbc-docker-config-")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary docker config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), data, 0600); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("writing temporary docker config: %w", err)
+	}
+
+	authContext := &RegistryAuthContext{Dir: dir, previousEnv: make(map[string]*string)}
+	for name, value := range map[string]string{
+		"DOCKER_CONFIG":      dir,
+		"REGISTRY_AUTH_FILE": filepath.Join(dir, "config.json"),
+	} {
+		if previous, ok := os.LookupEnv(name); ok {
+			authContext.previousEnv[name] = &previous
+		} else {
+			authContext.previousEnv[name] = nil
+		}
+		if err := os.Setenv(name, value); err != nil {
+			_ = authContext.Close()
+			return nil, err
+		}
+	}
+	return authContext, nil
+}
+
+// Close restores the environment and removes the temporary DOCKER_CONFIG. Safe to call on nil.
+func (a *RegistryAuthContext) Close() error {
+	if a == nil {
+		return nil
+	}
+	var errs []error
+	for name, value := range a.previousEnv {
+		if value == nil {
+			errs = append(errs, os.Unsetenv(name))
+		} else {
+			errs = append(errs, os.Setenv(name, *value))
+		}
+	}
+	errs = append(errs, os.RemoveAll(a.Dir))
+	return errors.Join(errs...)
+}
+
+// SetupRegistryAuthContext creates the auth context for the running command. No-op if authFiles
+// is empty and REGISTRY_AUTH_FILE is not set, unless the process took over the auth context
+// of the process it replaced.
+//
+// REGISTRY_AUTH_FILE alone is honored by buildah and skopeo only, merging it into the auth context
+// makes oras and SelectRegistryAuthFromDefaultAuthFile use the same credentials.
+func SetupRegistryAuthContext(authFiles []string) error {
+	if len(authFiles) == 0 && os.Getenv("REGISTRY_AUTH_FILE") == "" && os.Getenv(envVarAuthContextDir) == "" {
+		return nil
+	}
+	authContext, err := NewRegistryAuthContext(authFiles)
+	if err != nil {
+		return err
+	}
+	registryAuthContext = authContext
+	return nil
+}
+
+// CleanupRegistryAuthContext removes the auth context of the running command, if any.
+func CleanupRegistryAuthContext() error {
+	err := registryAuthContext.Close()
+	registryAuthContext = nil
+	return err
+}
+
+// RegistryAuthContextHandOverEnv returns the environment for a process that replaces
+// the current one via exec, letting it take over the auth context of the running command.
+func RegistryAuthContextHandOverEnv() []string {
+	if registryAuthContext == nil {
+		return nil
+	}
+	return []string{envVarAuthContextDir + "=" + registryAuthContext.Dir}
+}
+
+// UserAuthFile returns the auth file of the environment the command was started in, i.e. not
+// the temporary one of the auth context: REGISTRY_AUTH_FILE if set, or the default docker config file.
+func UserAuthFile() string {
+	lookupEnv := func(name string) string {
+		if registryAuthContext != nil {
+			if previous, ok := registryAuthContext.previousEnv[name]; ok {
+				if previous == nil {
+					return ""
+				}
+				return *previous
+			}
+		}
+		return os.Getenv(name)
+	}
+	if registryAuthFile := lookupEnv("REGISTRY_AUTH_FILE"); registryAuthFile != "" {
+		return registryAuthFile
+	}
+	if dockerConfig := lookupEnv("DOCKER_CONFIG"); dockerConfig != "" {
+		return filepath.Join(dockerConfig, "config.json")
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".docker", "config.json")
+}
+
+// ResolveAuthFile returns the docker config file of path, which is either the file itself
+// or a directory containing config.json or .dockerconfigjson.
+func ResolveAuthFile(path string) (string, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("auth file %s: %w", path, err)
+	}
+	if !stat.IsDir() {
+		return path, nil
+	}
+	for _, name := range []string{"config.json", ".dockerconfigjson"} {
+		if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+			return filepath.Join(path, name), nil
+		}
+	}
+	return "", fmt.Errorf("auth directory %s contains neither config.json nor .dockerconfigjson", path)
+}
+
+// The parts of an auth file that are merged, the auth entries are kept as-is since they may
+// contain more than just the auth token.
+type rawAuthFile struct {
+	Auths       map[string]json.RawMessage `json:"auths"`
+	CredHelpers map[string]string          `json:"credHelpers,omitempty"`
+	CredsStore  string                     `json:"credsStore,omitempty"`
+}
+
+func readRawAuthFile(authFilePath string) (*rawAuthFile, error) {
+	data, err := os.ReadFile(authFilePath) //nolint:gosec // auth file path is from controlled config
+	if err != nil {
+		return nil, err
+	}
+	var authFile rawAuthFile
+	if err := json.Unmarshal(data, &authFile); err != nil {
+		return nil, err
+	}
+	return &authFile, nil
+}
@@ -0,0 +1,158 @@
+package common
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRegistryAuthContext(t *testing.T) {
+	g := NewWithT(t)
+
+	writeAuthFile := func(path string, auths map[string]any) {
+		data, err := json.Marshal(map[string]any{"auths": auths})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		g.Expect(os.WriteFile(path, data, 0600)).To(Succeed())
+	}
+
+	t.Run("should merge auth files into a temporary DOCKER_CONFIG", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("DOCKER_CONFIG", "")
+		t.Setenv("REGISTRY_AUTH_FILE", "")
+		os.Unsetenv("DOCKER_CONFIG")
+		os.Unsetenv("REGISTRY_AUTH_FILE")
+
+		homeAuthFile := filepath.Join(home, ".docker", "config.json")
+		writeAuthFile(homeAuthFile, map[string]any{
+			"quay.io":  map[string]string{"auth": "home-quay"},
+			"other.io": map[string]string{"auth": "home-other"},
+		})
+		secretDir := filepath.Join(t.TempDir(), "secret")
+		writeAuthFile(filepath.Join(secretDir, ".dockerconfigjson"), map[string]any{
+			"quay.io": map[string]string{"auth": "secret-quay", "identitytoken": "id"},
+		})
+		helperAuthFile := filepath.Join(t.TempDir(), "helpers.json")
+		g.Expect(os.WriteFile(helperAuthFile, []byte(`{"credHelpers": {"gcr.io": "gcloud"}, "credsStore": "pass"}`), 0600)).To(Succeed())
+
+		authContext, err := NewRegistryAuthContext([]string{secretDir, helperAuthFile})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		mergedAuthFile, err := readRawAuthFile(filepath.Join(authContext.Dir, "config.json"))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(mergedAuthFile.CredHelpers).To(Equal(map[string]string{"gcr.io": "gcloud"}))
+		g.Expect(mergedAuthFile.CredsStore).To(Equal("pass"))
+
+		g.Expect(os.Getenv("DOCKER_CONFIG")).To(Equal(authContext.Dir))
+		g.Expect(os.Getenv("REGISTRY_AUTH_FILE")).To(Equal(filepath.Join(authContext.Dir, "config.json")))
+		g.Expect(GetDefaultAuthFile()).To(Equal(filepath.Join(authContext.Dir, "config.json")))
+
+		registryAuth, err := SelectRegistryAuthFromDefaultAuthFile("quay.io/org/app")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registryAuth.Token).To(Equal("secret-quay"))
+		registryAuth, err = SelectRegistryAuthFromDefaultAuthFile("other.io/org/app")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registryAuth.Token).To(Equal("home-other"))
+
+		g.Expect(authContext.Close()).To(Succeed())
+
+		g.Expect(authContext.Dir).ToNot(BeADirectory())
+		_, isSet := os.LookupEnv("DOCKER_CONFIG")
+		g.Expect(isSet).To(BeFalse())
+		_, isSet = os.LookupEnv("REGISTRY_AUTH_FILE")
+		g.Expect(isSet).To(BeFalse())
+		// $HOME/.docker is never modified
+		homeAuths, err := readRawAuthFile(homeAuthFile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(homeAuths.Auths).To(HaveLen(2))
+	})
+
+	t.Run("should fail on missing auth file", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+
+		_, err := NewRegistryAuthContext([]string{"/nonexistent/config.json"})
+		g.Expect(err).To(MatchError(ContainSubstring("/nonexistent/config.json")))
+	})
+
+	t.Run("should merge REGISTRY_AUTH_FILE into the auth context", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("DOCKER_CONFIG", "")
+		os.Unsetenv("DOCKER_CONFIG")
+
+		writeAuthFile(filepath.Join(home, ".docker", "config.json"), map[string]any{
+			"quay.io":  map[string]string{"auth": "home-quay"},
+			"other.io": map[string]string{"auth": "home-other"},
+		})
+		registryAuthFile := filepath.Join(t.TempDir(), "auth.json")
+		writeAuthFile(registryAuthFile, map[string]any{"quay.io": map[string]string{"auth": "registry-quay"}})
+		t.Setenv("REGISTRY_AUTH_FILE", registryAuthFile)
+
+		g.Expect(SetupRegistryAuthContext(nil)).To(Succeed())
+		defer CleanupRegistryAuthContext()
+
+		g.Expect(registryAuthContext).ToNot(BeNil())
+		registryAuth, err := SelectRegistryAuthFromDefaultAuthFile("quay.io/org/app")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registryAuth.Token).To(Equal("registry-quay"))
+		registryAuth, err = SelectRegistryAuthFromDefaultAuthFile("other.io/org/app")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registryAuth.Token).To(Equal("home-other"))
+	})
+
+	t.Run("should take over the auth context of the replaced process", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		t.Setenv("DOCKER_CONFIG", "")
+		t.Setenv("REGISTRY_AUTH_FILE", "")
+
+		g.Expect(SetupRegistryAuthContext([]string{})).To(Succeed())
+		g.Expect(RegistryAuthContextHandOverEnv()).To(BeEmpty())
+
+		authFile := filepath.Join(t.TempDir(), "config.json")
+		writeAuthFile(authFile, map[string]any{"quay.io": map[string]string{"auth": "token"}})
+		g.Expect(SetupRegistryAuthContext([]string{authFile})).To(Succeed())
+		dir := registryAuthContext.Dir
+		handOverEnv := RegistryAuthContextHandOverEnv()
+		g.Expect(handOverEnv).To(Equal([]string{envVarAuthContextDir + "=" + dir}))
+
+		// Simulate the new process
+		registryAuthContext = nil
+		t.Setenv(envVarAuthContextDir, dir)
+		g.Expect(SetupRegistryAuthContext(nil)).To(Succeed())
+		g.Expect(registryAuthContext.Dir).To(Equal(dir))
+		g.Expect(os.Getenv(envVarAuthContextDir)).To(BeEmpty())
+
+		g.Expect(CleanupRegistryAuthContext()).To(Succeed())
+		g.Expect(dir).ToNot(BeADirectory())
+	})
+
+	t.Run("should return the auth file of the environment from UserAuthFile", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("DOCKER_CONFIG", "")
+		t.Setenv("REGISTRY_AUTH_FILE", "")
+		os.Unsetenv("DOCKER_CONFIG")
+		os.Unsetenv("REGISTRY_AUTH_FILE")
+
+		g.Expect(UserAuthFile()).To(Equal(filepath.Join(home, ".docker", "config.json")))
+
+		dockerConfig := t.TempDir()
+		t.Setenv("DOCKER_CONFIG", dockerConfig)
+		g.Expect(UserAuthFile()).To(Equal(filepath.Join(dockerConfig, "config.json")))
+
+		registryAuthFile := filepath.Join(t.TempDir(), "auth.json")
+		writeAuthFile(registryAuthFile, map[string]any{"quay.io": map[string]string{"auth": "token"}})
+		t.Setenv("REGISTRY_AUTH_FILE", registryAuthFile)
+		g.Expect(UserAuthFile()).To(Equal(registryAuthFile))
+
+		// Not the temporary auth file of the auth context
+		g.Expect(SetupRegistryAuthContext([]string{registryAuthFile})).To(Succeed())
+		g.Expect(os.Getenv("REGISTRY_AUTH_FILE")).ToNot(Equal(registryAuthFile))
+		g.Expect(UserAuthFile()).To(Equal(registryAuthFile))
+		g.Expect(CleanupRegistryAuthContext()).To(Succeed())
+	})
+}
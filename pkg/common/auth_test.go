@@ -1,10 +1,13 @@
 package common
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -140,3 +143,206 @@ func TestFallbackSelectionForDockerIO(t *testing.T) {
 		return
 	}
 }
+
+func TestSelectAuthEntryFormats(t *testing.T) {
+	auths := map[string]interface{}{
+		"auths": map[string]interface{}{
+			"quay.io":  map[string]string{"username": "user", "password": "pass"},
+			"reg.io":   map[string]string{"identitytoken": "refresh-token"},
+			"other.io": map[string]string{"registrytoken": "bearer-token"},
+		},
+	}
+
+	authFile, err := createAuthFile(auths)
+	if err != nil {
+		t.Fatalf("Failed to create auth file: %v", err)
+	}
+	defer os.Remove(authFile)
+
+	testCases := []struct {
+		imageRef     string
+		expectedAuth RegistryAuth
+	}{
+		{"quay.io/foo", RegistryAuth{Registry: "quay.io", Token: base64.StdEncoding.EncodeToString([]byte("user:pass"))}},
+		{"reg.io/foo", RegistryAuth{Registry: "reg.io", IdentityToken: "refresh-token"}},
+		{"other.io/foo", RegistryAuth{Registry: "other.io", RegistryToken: "bearer-token"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.imageRef, func(t *testing.T) {
+			registryAuth, err := SelectRegistryAuth(tc.imageRef, authFile)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if *registryAuth != tc.expectedAuth {
+				t.Errorf("Expected auth %+v, got %+v", tc.expectedAuth, *registryAuth)
+			}
+		})
+	}
+}
+
+func TestSelectAuthFromCredentialHelpers(t *testing.T) {
+	auths := map[string]interface{}{
+		"auths": map[string]interface{}{
+			// Written by docker login for credentials kept by the helper
+			"quay.io": map[string]string{},
+			"reg.io":  map[string]string{"auth": regIOToken},
+		},
+		"credHelpers": map[string]string{
+			"quay.io":  "quay-helper",
+			"token.io": "token-helper",
+		},
+		"credsStore": "default-store",
+	}
+
+	authFile, err := createAuthFile(auths)
+	if err != nil {
+		t.Fatalf("Failed to create auth file: %v", err)
+	}
+	defer os.Remove(authFile)
+
+	originalRunCredentialHelper := runCredentialHelper
+	defer func() { runCredentialHelper = originalRunCredentialHelper }()
+	var helperCalls []string
+	runCredentialHelper = func(helper, serverURL string) ([]byte, error) {
+		helperCalls = append(helperCalls, helper+" "+serverURL)
+		switch helper {
+		case "quay-helper":
+			return []byte(`{"ServerURL": "quay.io", "Username": "user", "Secret": "pass"}`), nil
+		case "token-helper":
+			return []byte(`{"ServerURL": "token.io", "Username": "<token>", "Secret": "refresh-token"}`), nil
+		case "default-store":
+			if serverURL == registryIndexDockerIO {
+				return []byte(`{"ServerURL": "https://index.docker.io/v1/", "Username": "hub", "Secret": "hub-pass"}`), nil
+			}
+			return nil, errors.New("docker-credential-default-store get: exit status 1: credentials not found in native keychain")
+		}
+		return nil, errors.New("unexpected helper " + helper)
+	}
+
+	testCases := []struct {
+		imageRef     string
+		expectedAuth *RegistryAuth
+		expectedCall string
+	}{
+		{"quay.io/foo", &RegistryAuth{Registry: "quay.io", Token: base64.StdEncoding.EncodeToString([]byte("user:pass"))}, "quay-helper quay.io"},
+		{"token.io/foo", &RegistryAuth{Registry: "token.io", IdentityToken: "refresh-token"}, "token-helper token.io"},
+		{"docker.io/library/debian", &RegistryAuth{Registry: "docker.io", Token: base64.StdEncoding.EncodeToString([]byte("hub:hub-pass"))}, "default-store " + registryIndexDockerIO},
+		{"reg.io/foo", &RegistryAuth{Registry: "reg.io", Token: regIOToken}, ""},
+		{"new-reg.io/foo", nil, "default-store new-reg.io"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.imageRef, func(t *testing.T) {
+			helperCalls = nil
+			registryAuth, err := SelectRegistryAuth(tc.imageRef, authFile)
+
+			if tc.expectedAuth == nil {
+				if err == nil || !strings.Contains(err.Error(), "registry authentication is not configured") {
+					t.Errorf("Expected error about missing authentication, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			} else if *registryAuth != *tc.expectedAuth {
+				t.Errorf("Expected auth %+v, got %+v", *tc.expectedAuth, *registryAuth)
+			}
+
+			var expectedCalls []string
+			if tc.expectedCall != "" {
+				expectedCalls = []string{tc.expectedCall}
+			}
+			if strings.Join(helperCalls, ",") != strings.Join(expectedCalls, ",") {
+				t.Errorf("Expected credential helper calls %v, got %v", expectedCalls, helperCalls)
+			}
+		})
+	}
+}
+
+func TestSelectAuthCredentialHelperFailure(t *testing.T) {
+	authFile, err := createAuthFile(map[string]interface{}{"credsStore": "broken"})
+	if err != nil {
+		t.Fatalf("Failed to create auth file: %v", err)
+	}
+	defer os.Remove(authFile)
+
+	originalRunCredentialHelper := runCredentialHelper
+	defer func() { runCredentialHelper = originalRunCredentialHelper }()
+	runCredentialHelper = func(helper, serverURL string) ([]byte, error) {
+		return nil, errors.New("docker-credential-broken get quay.io: executable file not found in $PATH")
+	}
+
+	_, err = SelectRegistryAuth("quay.io/foo", authFile)
+	if err == nil || !strings.Contains(err.Error(), "executable file not found") {
+		t.Errorf("Expected credential helper error, got %v", err)
+	}
+}
+
+func TestWriteRegistryAuth(t *testing.T) {
+	t.Run("should create the auth file", func(t *testing.T) {
+		authFile := filepath.Join(t.TempDir(), "containers", "auth.json")
+
+		if err := WriteRegistryAuth(authFile, "quay.io/org", AuthEntry{Auth: quayIOToken}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		registryAuth, err := SelectRegistryAuth("quay.io/org/app", authFile)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if registryAuth.Token != quayIOToken {
+			t.Errorf("Expected token %s, got %s", quayIOToken, registryAuth.Token)
+		}
+		info, err := os.Stat(authFile)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("Expected auth file mode 0600, got %o", info.Mode().Perm())
+		}
+	})
+
+	t.Run("should keep the other entries and settings", func(t *testing.T) {
+		authFile := filepath.Join(t.TempDir(), "config.json")
+		existing := `{"auths": {"quay.io": {"auth": "old"}, "reg.io": {"auth": "` + regIOToken + `", "email": "me@reg.io"}},` +
+			` "credHelpers": {"gcr.io": "gcloud"}, "HttpHeaders": {"User-Agent": "test"}}`
+		if err := os.WriteFile(authFile, []byte(existing), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := WriteRegistryAuth(authFile, "quay.io", AuthEntry{Auth: quayIOToken}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		data, err := os.ReadFile(authFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var written map[string]any
+		if err := json.Unmarshal(data, &written); err != nil {
+			t.Fatal(err)
+		}
+		expected := map[string]any{
+			"auths": map[string]any{
+				"quay.io": map[string]any{"auth": quayIOToken},
+				"reg.io":  map[string]any{"auth": regIOToken, "email": "me@reg.io"},
+			},
+			"credHelpers": map[string]any{"gcr.io": "gcloud"},
+			"HttpHeaders": map[string]any{"User-Agent": "test"},
+		}
+		if !reflect.DeepEqual(written, expected) {
+			t.Errorf("Expected auth file %v, got %v", expected, written)
+		}
+	})
+
+	t.Run("should fail on an invalid auth file", func(t *testing.T) {
+		authFile := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(authFile, []byte("not json"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		err := WriteRegistryAuth(authFile, "quay.io", AuthEntry{Auth: quayIOToken})
+		if err == nil || !strings.Contains(err.Error(), "parsing auth file") {
+			t.Errorf("Expected parsing error, got %v", err)
+		}
+	})
+}
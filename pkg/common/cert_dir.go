@@ -0,0 +1,72 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extensions of the files containers/image loads from a --cert-dir directory:
+// *.crt are CA certificates, *.cert and *.key client certificates and keys.
+var certDirExtensions = []string{".crt", ".cert", ".key"}
+
+// WriteCertDir populates destDir with a --cert-dir directory for buildah and skopeo,
+// made of the certificates in certDir (if set) and of the CA bundle in caBundleFile.
+// The CA certificates of a --cert-dir are trusted in addition to the system trust store,
+// this lets users trust a custom CA without adding it to the system trust store.
+func WriteCertDir(destDir, certDir, caBundleFile string) error {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+
+	if certDir != "" {
+		entries, err := os.ReadDir(certDir)
+		if err != nil {
+			return fmt.Errorf("reading cert dir: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !isCertDirFile(entry.Name()) {
+				continue
+			}
+			if err := copyCertFile(filepath.Join(certDir, entry.Name()), filepath.Join(destDir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	if caBundleFile != "" {
+		// Don't overwrite a file of the same name copied from certDir
+		name := "ca-bundle.crt"
+		for i := 1; fileExists(filepath.Join(destDir, name)); i++ {
+			name = fmt.Sprintf("ca-bundle-%d.crt", i)
+		}
+		if err := copyCertFile(caBundleFile, filepath.Join(destDir, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isCertDirFile(name string) bool {
+	for _, ext := range certDirExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func copyCertFile(src, dest string) error {
+	content, err := os.ReadFile(src) //nolint:gosec // certificate file given by the user
+	if err != nil {
+		return fmt.Errorf("reading certificate file: %w", err)
+	}
+	return os.WriteFile(dest, content, 0600)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
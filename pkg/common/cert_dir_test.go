@@ -0,0 +1,69 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestWriteCertDir(t *testing.T) {
+	readDir := func(g *WithT, dir string) map[string]string {
+		files := map[string]string{}
+		entries, err := os.ReadDir(dir)
+		g.Expect(err).ToNot(HaveOccurred())
+		for _, entry := range entries {
+			content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			g.Expect(err).ToNot(HaveOccurred())
+			files[entry.Name()] = string(content)
+		}
+		return files
+	}
+
+	t.Run("should combine the cert dir and the CA bundle", func(t *testing.T) {
+		g := NewWithT(t)
+
+		certDir := t.TempDir()
+		g.Expect(os.WriteFile(filepath.Join(certDir, "ca.crt"), []byte("ca"), 0644)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(certDir, "client.cert"), []byte("cert"), 0644)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(certDir, "client.key"), []byte("key"), 0644)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(certDir, "README"), []byte("readme"), 0644)).To(Succeed())
+		caBundle := filepath.Join(t.TempDir(), "bundle.pem")
+		g.Expect(os.WriteFile(caBundle, []byte("bundle"), 0644)).To(Succeed())
+
+		destDir := filepath.Join(t.TempDir(), "certs")
+		g.Expect(WriteCertDir(destDir, certDir, caBundle)).To(Succeed())
+
+		g.Expect(readDir(g, destDir)).To(Equal(map[string]string{
+			"ca.crt":        "ca",
+			"client.cert":   "cert",
+			"client.key":    "key",
+			"ca-bundle.crt": "bundle",
+		}))
+	})
+
+	t.Run("should not overwrite a cert dir file with the CA bundle", func(t *testing.T) {
+		g := NewWithT(t)
+
+		certDir := t.TempDir()
+		g.Expect(os.WriteFile(filepath.Join(certDir, "ca-bundle.crt"), []byte("ca"), 0644)).To(Succeed())
+		caBundle := filepath.Join(t.TempDir(), "bundle.pem")
+		g.Expect(os.WriteFile(caBundle, []byte("bundle"), 0644)).To(Succeed())
+
+		destDir := t.TempDir()
+		g.Expect(WriteCertDir(destDir, certDir, caBundle)).To(Succeed())
+
+		g.Expect(readDir(g, destDir)).To(Equal(map[string]string{
+			"ca-bundle.crt":   "ca",
+			"ca-bundle-1.crt": "bundle",
+		}))
+	})
+
+	t.Run("should fail if the CA bundle doesn't exist", func(t *testing.T) {
+		g := NewWithT(t)
+
+		err := WriteCertDir(t.TempDir(), "", filepath.Join(t.TempDir(), "missing.pem"))
+		g.Expect(err).To(MatchError(ContainSubstring("reading certificate file")))
+	})
+}
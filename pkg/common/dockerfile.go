@@ -37,7 +37,7 @@ func joinOrReplace(pathElem ...string) string {
 // If Dockerfile is not specified, search ./Containerfile then ./Dockerfile.
 //
 // Note that the result path is not guaranteed to be a subpath of the source directory.
-// If that is important, check with [RealPath.IsRelativeTo].
+// If that is important, check with [CheckPathInside].
 //
 // Return an empty string if nothing is found.
 func SearchDockerfile(opts DockerfileSearchOpts) (string, error) {
@@ -3,11 +3,69 @@ package common
 import (
 	_ "crypto/sha256"
 	"fmt"
+	"strings"
 
-	"github.com/containers/image/v5/docker/reference"
 	go_digest "github.com/opencontainers/go-digest"
+	"go.podman.io/image/v5/docker/reference"
 )
 
+// ImageReference is a parsed image reference with normalized components.
+// For Quay.io:443/org/app:v1@sha256:1234..., Registry is quay.io:443, Repository is org/app,
+// Tag is v1 and Digest is sha256:1234.... A reference without a registry refers to Docker Hub,
+// e.g. ubuntu is docker.io/library/ubuntu.
+type ImageReference struct {
+	// Registry host with an optional port, lowercase.
+	Registry   string
+	Repository string
+	// Empty if the reference has no tag.
+	Tag string
+	// Empty if the reference has no digest.
+	Digest string
+}
+
+// ParseReference parses an image reference (without a transport) using containers/image library.
+func ParseReference(imageRef string) (*ImageReference, error) {
+	named, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := &ImageReference{
+		// Host names are case-insensitive, normalize them for comparisons and auth lookups
+		Registry:   strings.ToLower(reference.Domain(named)),
+		Repository: reference.Path(named),
+	}
+	if tagged, ok := named.(reference.Tagged); ok {
+		ref.Tag = tagged.Tag()
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		ref.Digest = digested.Digest().String()
+	}
+	return ref, nil
+}
+
+// Name returns the registry/repository part of the reference.
+func (r *ImageReference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// IsNameOnly returns true if the reference has neither a tag nor a digest.
+func (r *ImageReference) IsNameOnly() bool {
+	return r.Tag == "" && r.Digest == ""
+}
+
+// String returns the normalized reference, registry/repository[:tag][@digest].
+func (r *ImageReference) String() string {
+	ref := r.Name()
+	if r.Tag != "" {
+		ref += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		ref += "@" + r.Digest
+	}
+	return ref
+}
+
 // GetImageName trims tag and/or digest from given image reference using containers/image library.
 func GetImageName(imageURL string) string {
 	ref, err := reference.Parse(imageURL)
@@ -145,3 +203,27 @@ func IsNormalizedRef(imageRef string) bool {
 	}
 	return ref.String() == normalizedRef.String()
 }
+
+// SplitImageTransport splits an image ref that includes a transport into (transport, image ref).
+//
+// Example:
+// - "docker://registry.io/image:tag" -> ("docker://", "registry.io/image:tag")
+// - "registry.io/image:tag" -> ("", "registry.io/image:tag")
+func SplitImageTransport(imageRef string) (string, string) {
+	transports := []string{
+		"docker://",
+		"containers-storage:",
+		"dir:",
+		"docker-archive:",
+		"docker-daemon:",
+		"oci:",
+		"oci-archive:",
+		"sif:",
+	}
+	for _, transport := range transports {
+		if imageRef, ok := strings.CutPrefix(imageRef, transport); ok {
+			return transport, imageRef
+		}
+	}
+	return "", imageRef
+}
@@ -524,6 +524,94 @@ func Test_ImageRefUntils_IsNormalizedRef(t *testing.T) {
 	}
 }
 
+func Test_ImageRefUtils_ParseReference(t *testing.T) {
+	const digest = "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+	tests := []struct {
+		name       string
+		imageRef   string
+		want       common.ImageReference
+		wantString string
+		wantError  bool
+	}{
+		{
+			name:       "should parse image with tag",
+			imageRef:   "quay.io/org/app:v1",
+			want:       common.ImageReference{Registry: "quay.io", Repository: "org/app", Tag: "v1"},
+			wantString: "quay.io/org/app:v1",
+		},
+		{
+			name:       "should parse image with tag and digest",
+			imageRef:   "quay.io/org/app:v1@" + digest,
+			want:       common.ImageReference{Registry: "quay.io", Repository: "org/app", Tag: "v1", Digest: digest},
+			wantString: "quay.io/org/app:v1@" + digest,
+		},
+		{
+			name:       "should parse registry with port",
+			imageRef:   "registry.io:5000/app@" + digest,
+			want:       common.ImageReference{Registry: "registry.io:5000", Repository: "app", Digest: digest},
+			wantString: "registry.io:5000/app@" + digest,
+		},
+		{
+			name:       "should parse localhost",
+			imageRef:   "localhost/app:latest",
+			want:       common.ImageReference{Registry: "localhost", Repository: "app", Tag: "latest"},
+			wantString: "localhost/app:latest",
+		},
+		{
+			name:       "should lowercase registry host",
+			imageRef:   "Registry.IO/org/app",
+			want:       common.ImageReference{Registry: "registry.io", Repository: "org/app"},
+			wantString: "registry.io/org/app",
+		},
+		{
+			name:       "should normalize docker hub image",
+			imageRef:   "ubuntu:22.04",
+			want:       common.ImageReference{Registry: "docker.io", Repository: "library/ubuntu", Tag: "22.04"},
+			wantString: "docker.io/library/ubuntu:22.04",
+		},
+		{
+			name:      "should fail on uppercase repository",
+			imageRef:  "quay.io/org/App:v1",
+			wantError: true,
+		},
+		{
+			name:      "should fail on invalid digest",
+			imageRef:  "quay.io/org/app@sha256:1234",
+			wantError: true,
+		},
+		{
+			name:      "should fail on empty reference",
+			imageRef:  "",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, err := common.ParseReference(tc.imageRef)
+			if tc.wantError {
+				if err == nil {
+					t.Errorf("Expected error for %s, but got nil", tc.imageRef)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error for %s, but got: %v", tc.imageRef, err)
+			}
+			if *ref != tc.want {
+				t.Errorf("ParseReference(%s) = %+v, want %+v", tc.imageRef, *ref, tc.want)
+			}
+			if ref.String() != tc.wantString {
+				t.Errorf("String() = %s, want %s", ref.String(), tc.wantString)
+			}
+			if ref.IsNameOnly() != (tc.want.Tag == "" && tc.want.Digest == "") {
+				t.Errorf("IsNameOnly() = %t for %s", ref.IsNameOnly(), tc.imageRef)
+			}
+		})
+	}
+}
+
 func Test_ValidateImageHasTagOrDigest(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -574,3 +662,36 @@ func Test_ValidateImageHasTagOrDigest(t *testing.T) {
 		})
 	}
 }
+
+func Test_ImageRefUtils_SplitImageTransport(t *testing.T) {
+	tests := []struct {
+		input             string
+		expectedTransport string
+		expectedImageRef  string
+	}{
+		// No ref
+		{"", "", ""},
+		// Plain image refs (no transport)
+		{"registry.io/image:tag", "", "registry.io/image:tag"},
+		{"ubuntu:latest", "", "ubuntu:latest"},
+		// Unknown transport (treated the same as no transport, no way to know this isn't a valid image:tag)
+		{"made-up-transport:ubuntu", "", "made-up-transport:ubuntu"},
+		// Known transports
+		{"docker://registry.io/image:tag", "docker://", "registry.io/image:tag"},
+		{"containers-storage:localhost/image:tag", "containers-storage:", "localhost/image:tag"},
+		{"dir:/path/to/dir", "dir:", "/path/to/dir"},
+		{"docker-archive:/path/to/archive.tar", "docker-archive:", "/path/to/archive.tar"},
+		{"docker-daemon:image:tag", "docker-daemon:", "image:tag"},
+		{"oci:/path/to/dir", "oci:", "/path/to/dir"},
+		{"oci-archive:/path/to/archive.tar", "oci-archive:", "/path/to/archive.tar"},
+		{"sif:/path/to/file.sif", "sif:", "/path/to/file.sif"},
+	}
+
+	for _, tc := range tests {
+		transport, imageRef := common.SplitImageTransport(tc.input)
+		if transport != tc.expectedTransport || imageRef != tc.expectedImageRef {
+			t.Errorf("SplitImageTransport(%q) = (%q, %q), expected (%q, %q)",
+				tc.input, transport, imageRef, tc.expectedTransport, tc.expectedImageRef)
+		}
+	}
+}
@@ -3,11 +3,14 @@ package common
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 
+	kbcerrors "github.com/konflux-ci/konflux-build-cli/pkg/errors"
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 	"github.com/spf13/cobra"
 )
@@ -26,6 +29,28 @@ type Parameter struct {
 	NoLog bool
 }
 
+var paramsConfigsOfCommands = map[*cobra.Command]map[string]Parameter{}
+
+// recordParamsConfigForCommand saves the parameters of the command for introspection, see RegisteredParameters.
+func recordParamsConfigForCommand(cmd *cobra.Command, paramsConfig map[string]Parameter) {
+	paramsConfigsOfCommands[cmd] = paramsConfig
+}
+
+// RegisteredParameters returns the parameters config registered for the command with RegisterParameters.
+func RegisteredParameters(cmd *cobra.Command) (map[string]Parameter, bool) {
+	paramsConfig, ok := paramsConfigsOfCommands[cmd]
+	return paramsConfig, ok
+}
+
+// CommandsWithParameters returns the commands with registered parameters, sorted by their command path.
+func CommandsWithParameters() []*cobra.Command {
+	cmds := slices.Collect(maps.Keys(paramsConfigsOfCommands))
+	slices.SortFunc(cmds, func(a, b *cobra.Command) int {
+		return strings.Compare(a.CommandPath(), b.CommandPath())
+	})
+	return cmds
+}
+
 // RegisterParameters configures Cobra CLI parameters based on given Parameters data.
 func RegisterParameters(cmd *cobra.Command, paramsConfig map[string]Parameter) {
 	getMessageInvalidParameterDefaultValue := func(p Parameter) string {
@@ -33,6 +58,8 @@ func RegisterParameters(cmd *cobra.Command, paramsConfig map[string]Parameter) {
 	}
 	var err error
 
+	recordParamsConfigForCommand(cmd, paramsConfig)
+
 	for pName, p := range paramsConfig {
 		if pName != p.Name {
 			panic(fmt.Sprintf("parameter name '%s' and tag '%s' must be equal", p.Name, pName))
@@ -97,8 +124,13 @@ func RegisterParameters(cmd *cobra.Command, paramsConfig map[string]Parameter) {
 	}
 }
 
-// ParseParameters populates parameters structure with provided values based on parameters configuration
+// ParseParameters populates parameters structure with provided values based on parameters configuration.
+// The returned errors are validation errors.
 func ParseParameters(cmd *cobra.Command, paramsConfig map[string]Parameter, params interface{}) error {
+	return kbcerrors.NewValidationError(parseParameters(cmd, paramsConfig, params))
+}
+
+func parseParameters(cmd *cobra.Command, paramsConfig map[string]Parameter, params interface{}) error {
 	getMessageRequiredParameterMissing := func(p Parameter) string {
 		return fmt.Sprintf("required parameter '%s' is not set", p.Name)
 	}
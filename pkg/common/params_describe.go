@@ -0,0 +1,150 @@
+package common
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// CommandDescription is the machine-readable interface description of a command.
+type CommandDescription struct {
+	// The command path without the root command, e.g. "image build"
+	Command     string                 `json:"command"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  []ParameterDescription `json:"parameters"`
+}
+
+type ParameterDescription struct {
+	Name      string `json:"name"`
+	Flag      string `json:"flag"`
+	ShortFlag string `json:"short_flag,omitempty"`
+	EnvVar    string `json:"env_var,omitempty"`
+	// string, int, bool or array
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	// Typed default value, omitted for required parameters
+	Default any    `json:"default,omitempty"`
+	Usage   string `json:"usage"`
+}
+
+// DescribeCommand describes the parameters registered for the command with RegisterParameters, sorted by name.
+func DescribeCommand(cmd *cobra.Command) (*CommandDescription, error) {
+	paramsConfig, ok := RegisteredParameters(cmd)
+	if !ok {
+		return nil, fmt.Errorf("command '%s' has no registered parameters", cmd.CommandPath())
+	}
+
+	description := &CommandDescription{
+		Command:     strings.Join(strings.Fields(cmd.CommandPath())[1:], " "),
+		Description: cmd.Short,
+		Parameters:  []ParameterDescription{},
+	}
+	for _, name := range slices.Sorted(maps.Keys(paramsConfig)) {
+		p := paramsConfig[name]
+		param := ParameterDescription{
+			Name:     p.Name,
+			Flag:     "--" + p.Name,
+			EnvVar:   p.EnvVarName,
+			Required: p.Required,
+			Usage:    p.Usage,
+		}
+		if p.ShortName != "" {
+			param.ShortFlag = "-" + p.ShortName
+		}
+
+		// Same defaults as RegisterParameters
+		switch p.TypeKind {
+		case reflect.String:
+			param.Type = "string"
+			param.Default = p.DefaultValue
+		case reflect.Int:
+			param.Type = "int"
+			param.Default = 0
+			if p.DefaultValue != "" {
+				value, err := strconv.Atoi(p.DefaultValue)
+				if err != nil {
+					return nil, fmt.Errorf("parameter '%s' has invalid default value '%s'", p.Name, p.DefaultValue)
+				}
+				param.Default = value
+			}
+		case reflect.Bool:
+			param.Type = "bool"
+			param.Default = false
+			if p.DefaultValue != "" {
+				value, err := strconv.ParseBool(p.DefaultValue)
+				if err != nil {
+					return nil, fmt.Errorf("parameter '%s' has invalid default value '%s'", p.Name, p.DefaultValue)
+				}
+				param.Default = value
+			}
+		case reflect.Array, reflect.Slice:
+			param.Type = "array"
+			param.Default = strings.Fields(p.DefaultValue)
+		default:
+			return nil, fmt.Errorf("parameter '%s' has unknown type %s", p.Name, p.TypeKind)
+		}
+		if p.Required {
+			param.Default = nil
+		}
+
+		description.Parameters = append(description.Parameters, param)
+	}
+	return description, nil
+}
+
+// Markdown renders the description as a markdown section with a table of the parameters.
+func (d *CommandDescription) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s\n\n", d.Command)
+	if d.Description != "" {
+		fmt.Fprintf(&sb, "%s\n\n", d.Description)
+	}
+	sb.WriteString("| Flag | Env var | Type | Default | Required | Description |\n")
+	sb.WriteString("|------|---------|------|---------|----------|-------------|\n")
+	for _, p := range d.Parameters {
+		flag := "`" + p.Flag + "`"
+		if p.ShortFlag != "" {
+			flag += ", `" + p.ShortFlag + "`"
+		}
+		envVar := ""
+		if p.EnvVar != "" {
+			envVar = "`" + p.EnvVar + "`"
+		}
+		required := ""
+		if p.Required {
+			required = "yes"
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s |\n",
+			flag, envVar, p.Type, markdownDefault(p.Default), required, markdownTableCell(p.Usage))
+	}
+	return sb.String()
+}
+
+func markdownDefault(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		if v == "" {
+			return ""
+		}
+		return "`" + v + "`"
+	case []string:
+		if len(v) == 0 {
+			return ""
+		}
+		return "`" + strings.Join(v, " ") + "`"
+	default:
+		return fmt.Sprintf("`%v`", v)
+	}
+}
+
+func markdownTableCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
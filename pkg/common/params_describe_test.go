@@ -0,0 +1,87 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+func TestDescribeCommand(t *testing.T) {
+	newCommand := func() *cobra.Command {
+		rootCmd := &cobra.Command{Use: "konflux-build-cli"}
+		cmd := &cobra.Command{Use: "do-things", Short: "Does things"}
+		rootCmd.AddCommand(cmd)
+		RegisterParameters(cmd, map[string]Parameter{
+			"image-url": {
+				Name:       "image-url",
+				ShortName:  "i",
+				EnvVarName: "KBC_DO_THINGS_IMAGE_URL",
+				TypeKind:   reflect.String,
+				Usage:      "Image to do things with.",
+				Required:   true,
+			},
+			"tags": {
+				Name:         "tags",
+				EnvVarName:   "KBC_DO_THINGS_TAGS",
+				TypeKind:     reflect.Slice,
+				DefaultValue: "latest stable",
+				Usage:        "Tags, e.g. latest|stable.\nCan be repeated.",
+			},
+			"retries": {
+				Name:         "retries",
+				EnvVarName:   "KBC_DO_THINGS_RETRIES",
+				TypeKind:     reflect.Int,
+				DefaultValue: "3",
+				Usage:        "Retries.",
+			},
+			"verify": {
+				Name:       "verify",
+				EnvVarName: "KBC_DO_THINGS_VERIFY",
+				TypeKind:   reflect.Bool,
+				Usage:      "Verify.",
+			},
+		})
+		return cmd
+	}
+
+	t.Run("should describe the parameters with typed defaults", func(t *testing.T) {
+		g := NewWithT(t)
+
+		description, err := DescribeCommand(newCommand())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(description).To(Equal(&CommandDescription{
+			Command:     "do-things",
+			Description: "Does things",
+			Parameters: []ParameterDescription{
+				{Name: "image-url", Flag: "--image-url", ShortFlag: "-i", EnvVar: "KBC_DO_THINGS_IMAGE_URL", Type: "string", Required: true, Usage: "Image to do things with."},
+				{Name: "retries", Flag: "--retries", EnvVar: "KBC_DO_THINGS_RETRIES", Type: "int", Default: 3, Usage: "Retries."},
+				{Name: "tags", Flag: "--tags", EnvVar: "KBC_DO_THINGS_TAGS", Type: "array", Default: []string{"latest", "stable"}, Usage: "Tags, e.g. latest|stable.\nCan be repeated."},
+				{Name: "verify", Flag: "--verify", EnvVar: "KBC_DO_THINGS_VERIFY", Type: "bool", Default: false, Usage: "Verify."},
+			},
+		}))
+	})
+
+	t.Run("should render markdown", func(t *testing.T) {
+		g := NewWithT(t)
+
+		description, err := DescribeCommand(newCommand())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(description.Markdown()).To(Equal("## do-things\n\n" +
+			"Does things\n\n" +
+			"| Flag | Env var | Type | Default | Required | Description |\n" +
+			"|------|---------|------|---------|----------|-------------|\n" +
+			"| `--image-url`, `-i` | `KBC_DO_THINGS_IMAGE_URL` | string |  | yes | Image to do things with. |\n" +
+			"| `--retries` | `KBC_DO_THINGS_RETRIES` | int | `3` |  | Retries. |\n" +
+			"| `--tags` | `KBC_DO_THINGS_TAGS` | array | `latest stable` |  | Tags, e.g. latest\\|stable.<br>Can be repeated. |\n" +
+			"| `--verify` | `KBC_DO_THINGS_VERIFY` | bool | `false` |  | Verify. |\n"))
+	})
+
+	t.Run("should fail for commands without registered parameters", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := DescribeCommand(&cobra.Command{Use: "nothing"})
+		g.Expect(err).To(MatchError(ContainSubstring("has no registered parameters")))
+	})
+}
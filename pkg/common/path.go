@@ -1,6 +1,10 @@
 package common
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 )
@@ -34,3 +38,47 @@ func (p ResolvedPath) IsRelativeTo(base ResolvedPath) bool {
 	}
 	return !strings.HasPrefix(rel, "..")
 }
+
+// CheckPathInside returns an error if path doesn't resolve to root or a path within it,
+// e.g. because of ".." components or symlinks pointing outside of root.
+//
+// Unlike ResolvePath, ".." components are resolved after the symlinks before them, as the kernel
+// does when opening the path. A path that doesn't exist is checked by its nearest existing parent,
+// the missing rest of the path can't contain symlinks. The name of the path is used in the error.
+func CheckPathInside(root ResolvedPath, name, path string) error {
+	resolved, err := resolvePathAllowMissing(path)
+	if err != nil {
+		return fmt.Errorf("resolving %s '%s': %w", name, path, err)
+	}
+	if !resolved.IsRelativeTo(root) {
+		return fmt.Errorf("%s '%s' resolves to '%s', outside of '%s'", name, path, resolved, root)
+	}
+	return nil
+}
+
+func resolvePathAllowMissing(path string) (ResolvedPath, error) {
+	if !filepath.IsAbs(path) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		// Not filepath.Join, it would clean the ".." components before the symlinks are resolved
+		path = wd + string(filepath.Separator) + path
+	}
+
+	missing := ""
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return ResolvedPath(filepath.Join(resolved, missing)), nil
+		}
+		// Not filepath.Dir either, for the same reason
+		trimmed := strings.TrimRight(path, string(filepath.Separator))
+		i := strings.LastIndex(trimmed, string(filepath.Separator))
+		if !errors.Is(err, fs.ErrNotExist) || i < 0 || trimmed == "" {
+			return "", err
+		}
+		missing = filepath.Join(trimmed[i+1:], missing)
+		path = trimmed[:i+1]
+	}
+}
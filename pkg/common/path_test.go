@@ -105,3 +105,79 @@ func TestIsRelativeTo(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckPathInside(t *testing.T) {
+	g := NewWithT(t)
+
+	// .
+	// ├── outside/
+	// │   └── secret
+	// └── workspace/
+	//     ├── src/
+	//     │   └── Containerfile
+	//     ├── escape -> ../outside
+	//     └── nested -> src/
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	g.Expect(err).ToNot(HaveOccurred())
+	workspace := filepath.Join(dir, "workspace")
+	g.Expect(os.MkdirAll(filepath.Join(workspace, "src"), 0755)).To(Succeed())
+	g.Expect(os.MkdirAll(filepath.Join(dir, "outside"), 0755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(workspace, "src", "Containerfile"), nil, 0644)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dir, "outside", "secret"), nil, 0644)).To(Succeed())
+	g.Expect(os.Symlink("../outside", filepath.Join(workspace, "escape"))).To(Succeed())
+	g.Expect(os.Symlink("src", filepath.Join(workspace, "nested"))).To(Succeed())
+
+	origDir, err := os.Getwd()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(os.Chdir(workspace)).To(Succeed())
+	t.Cleanup(func() { os.Chdir(origDir) })
+
+	root := ResolvedPath(workspace)
+
+	tests := []struct {
+		name        string
+		path        string
+		errExpected string
+	}{
+		{name: "root itself", path: workspace},
+		{name: "relative path inside", path: "src/Containerfile"},
+		{name: "symlink inside", path: "nested/Containerfile"},
+		{name: "missing path inside", path: "src/missing/file"},
+		{name: "dot dot staying inside", path: "src/../nested"},
+		{
+			name:        "dot dot escaping",
+			path:        "../outside/secret",
+			errExpected: "input '../outside/secret' resolves to '" + filepath.Join(dir, "outside", "secret") + "', outside of '" + workspace + "'",
+		},
+		{
+			name:        "symlink escaping",
+			path:        "escape/secret",
+			errExpected: "input 'escape/secret' resolves to '" + filepath.Join(dir, "outside", "secret") + "', outside of '" + workspace + "'",
+		},
+		{
+			name:        "missing path behind escaping symlink",
+			path:        filepath.Join(workspace, "escape", "missing"),
+			errExpected: "resolves to '" + filepath.Join(dir, "outside", "missing") + "'",
+		},
+		{
+			// Lexically escape/.. is the workspace, the kernel resolves the symlink first
+			name:        "dot dot after escaping symlink",
+			path:        "escape/../outside/secret",
+			errExpected: "resolves to '" + filepath.Join(dir, "outside", "secret") + "'",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := CheckPathInside(root, "input", tc.path)
+
+			if tc.errExpected == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.errExpected)))
+			}
+		})
+	}
+}
@@ -1,5 +1,14 @@
 package common
 
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
 // Given an http_proxy URL and a no_proxy string,
 // return all the environment variables needed to ensure the proxy is configured.
 //
@@ -22,3 +31,65 @@ func ProxyEnvVars(httpProxy string, noProxy string) []string {
 	}
 	return env
 }
+
+// ProxySettings are the effective proxy settings of a command.
+type ProxySettings struct {
+	HttpProxy string `json:"http_proxy,omitempty"`
+	NoProxy   string `json:"no_proxy,omitempty"`
+}
+
+// ResolveProxySettings returns the proxy settings given by the --proxy and --no-proxy params.
+// A param that isn't set falls back to the environment: HTTPS_PROXY or HTTP_PROXY and NO_PROXY,
+// uppercase or lowercase.
+func ResolveProxySettings(httpProxy string, noProxy string) ProxySettings {
+	if httpProxy == "" {
+		httpProxy = firstEnv("HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy")
+	}
+	if noProxy == "" {
+		noProxy = firstEnv("NO_PROXY", "no_proxy")
+	}
+	return ProxySettings{HttpProxy: httpProxy, NoProxy: noProxy}
+}
+
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func (p ProxySettings) IsSet() bool {
+	return p.HttpProxy != "" || p.NoProxy != ""
+}
+
+// Env returns the environment variables configuring the proxy, see ProxyEnvVars.
+func (p ProxySettings) Env() []string {
+	return ProxyEnvVars(p.HttpProxy, p.NoProxy)
+}
+
+// Apply sets the proxy environment variables of the current process,
+// the CLI tools the command runs (buildah, skopeo, oras, ...) inherit them.
+func (p ProxySettings) Apply() error {
+	for _, envVar := range p.Env() {
+		name, value, _ := strings.Cut(envVar, "=")
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("setting %s: %w", name, err)
+		}
+	}
+	redacted := p.Redacted()
+	l.Logger.Infof("Using proxy settings: HTTP(S)_PROXY=%s NO_PROXY=%s", redacted.HttpProxy, redacted.NoProxy)
+	return nil
+}
+
+// Redacted returns the settings with the password in the proxy URL masked, for logs and results.
+func (p ProxySettings) Redacted() ProxySettings {
+	if proxyUrl, err := url.Parse(p.HttpProxy); err == nil && proxyUrl.User != nil {
+		if _, hasPassword := proxyUrl.User.Password(); hasPassword {
+			proxyUrl.User = url.UserPassword(proxyUrl.User.Username(), "xxxxx")
+			p.HttpProxy = proxyUrl.String()
+		}
+	}
+	return p
+}
@@ -3,7 +3,12 @@ package common
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
 
 	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
 )
@@ -27,7 +32,7 @@ func (r *ResultsWriter) WriteResultString(result, path string) error {
 		return nil
 	}
 
-	if err := os.WriteFile(path, []byte(result), 0644); err != nil {
+	if err := WriteResultFile(path, []byte(result)); err != nil {
 		return fmt.Errorf("failed to write into result file '%s': %w", path, err)
 	}
 
@@ -39,11 +44,203 @@ func (r *ResultsWriter) WriteResultString(result, path string) error {
 // CreateResultJson converts a struct with results into JSON string.
 // Mostly used by tasks to output results into stdout.
 // Note, for Tekton results, the JSON must be escaped.
+// In the Tekton results mode, each result field is also written into its own file, see SetTektonResultsDir.
+// With a results schema set, see SetResultsSchema, the JSON is validated before it's written anywhere.
+// With a size limit set, see SetResultsSizeLimit, the largest fields may be replaced by references after the validation.
 func (r *ResultsWriter) CreateResultJson(result any) (string, error) {
 	resultJson, err := json.Marshal(result)
 	if err != nil {
 		return "", err
 	}
 
+	if resultsSchema != nil {
+		if err := validateResults(resultJson, resultsSchema); err != nil {
+			return "", err
+		}
+	}
+
+	if resultsSizeLimit > 0 {
+		resultJson, err = offloadLargeResults(resultJson, resultsSizeLimit, resultsOverflowStore)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if tektonResultsDir != "" {
+		if err := writeTektonResults(resultJson, tektonResultsDir); err != nil {
+			return "", err
+		}
+	}
+
+	if resultsFile != "" {
+		if err := writeResultFileAtomically(resultsFile, resultJson); err != nil {
+			return "", fmt.Errorf("failed to write results file '%s': %w", resultsFile, err)
+		}
+		l.Logger.Debugf("Wrote results into '%s'", resultsFile)
+	}
+
 	return string(resultJson), nil
 }
+
+// File where to write the results JSON, empty to only print it.
+var resultsFile string
+
+// SetResultsFile makes CreateResultJson write the results JSON also into the given file
+// for the rest of the process. An empty path disables it.
+func SetResultsFile(path string) {
+	resultsFile = path
+}
+
+// Write the file through a temporary file in the same directory renamed over the target,
+// so that readers never observe partially written content.
+func writeResultFileAtomically(path string, data []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	if err := WriteResultFile(tmpPath, data); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// DefaultTektonResultsDir is where Tekton expects the step results.
+const DefaultTektonResultsDir = "/tekton/results"
+
+// Directory for the Tekton results, empty if the Tekton results mode is disabled.
+var tektonResultsDir string
+
+// SetTektonResultsDir enables the Tekton results mode for the rest of the process:
+// every top-level field of the results is written into dir/<json field name>, so that a Tekton
+// task can declare the results without a wrapper script parsing the JSON output.
+// An empty dir disables the mode.
+func SetTektonResultsDir(dir string) {
+	tektonResultsDir = dir
+}
+
+// Write the fields of the results JSON object into individual files.
+// String values are written as they are, other values as JSON. Null values are skipped.
+func writeTektonResults(resultJson []byte, dir string) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(resultJson, &fields); err != nil {
+		return fmt.Errorf("tekton results must be a JSON object: %w", err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		value := []byte(fields[name])
+		if string(value) == "null" {
+			continue
+		}
+		var str string
+		if err := json.Unmarshal(value, &str); err == nil {
+			value = []byte(str)
+		}
+
+		path := filepath.Join(dir, name)
+		if err := WriteResultFile(path, value); err != nil {
+			return fmt.Errorf("failed to write tekton result '%s': %w", path, err)
+		}
+		l.Logger.Debugf("Wrote tekton result '%s'", path)
+	}
+	return nil
+}
+
+// ResultFileOptions control how result and artifact files are written.
+// Tekton steps may run as different UIDs, the defaults may not allow the next step to read the outputs.
+type ResultFileOptions struct {
+	Mode os.FileMode
+	// Group ID to assign to the files, -1 keeps the default group.
+	Gid int
+	// Flush the file content to disk before returning.
+	Fsync bool
+}
+
+func DefaultResultFileOptions() ResultFileOptions {
+	return ResultFileOptions{Mode: 0644, Gid: -1}
+}
+
+var resultFileOptions = DefaultResultFileOptions()
+
+// SetResultFileOptions sets the options used by WriteResultFile for the rest of the process.
+func SetResultFileOptions(options ResultFileOptions) {
+	resultFileOptions = options
+}
+
+// ParseResultFileOptions parses the user-provided values of the result file options.
+// mode is an octal permission string, e.g. 0640. group is a group name or a numeric GID, empty to keep the default.
+func ParseResultFileOptions(mode, group string, fsync bool) (ResultFileOptions, error) {
+	options := DefaultResultFileOptions()
+	options.Fsync = fsync
+
+	if mode != "" {
+		parsedMode, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil || parsedMode > 0777 {
+			return options, fmt.Errorf("invalid result file mode '%s': expected octal permissions, e.g. 0644", mode)
+		}
+		options.Mode = os.FileMode(parsedMode)
+	}
+
+	if group != "" {
+		gid, err := strconv.Atoi(group)
+		if err != nil {
+			g, lookupErr := user.LookupGroup(group)
+			if lookupErr != nil {
+				return options, fmt.Errorf("invalid result file group '%s': %w", group, lookupErr)
+			}
+			gid, err = strconv.Atoi(g.Gid)
+			if err != nil {
+				return options, fmt.Errorf("invalid GID of group '%s': %w", group, err)
+			}
+		}
+		if gid < 0 {
+			return options, fmt.Errorf("invalid result file group '%s'", group)
+		}
+		options.Gid = gid
+	}
+
+	return options, nil
+}
+
+// WriteResultFile writes a result or artifact file according to the configured ResultFileOptions.
+// Unlike os.WriteFile, the mode is applied regardless of umask and of whether the file already exists.
+func WriteResultFile(path string, data []byte) (err error) {
+	options := resultFileOptions
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, options.Mode) //nolint:gosec // result path from controlled input
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	if err := f.Chmod(options.Mode); err != nil {
+		return err
+	}
+	if options.Gid >= 0 {
+		if err := f.Chown(-1, options.Gid); err != nil {
+			return fmt.Errorf("changing group of %s: %w", path, err)
+		}
+	}
+	if options.Fsync {
+		if err := f.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package common
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// ResultReference replaces the value of a result field that was too large to be kept in the results JSON.
+type ResultReference struct {
+	// Where the value is stored, a file path or an OCI artifact reference.
+	Ref string `json:"ref"`
+	// Digest of the stored value, sha256:<hex>.
+	Digest string `json:"digest"`
+	// Size of the stored value in bytes.
+	Size int `json:"size"`
+}
+
+// ResultsOverflowStore stores the values of the result fields exceeding the results size limit.
+type ResultsOverflowStore interface {
+	// Store saves the value of the named result field and returns where it's stored.
+	Store(name string, value []byte) (string, error)
+}
+
+var _ ResultsOverflowStore = &ResultsDirStore{}
+
+// ResultsDirStore stores the values of large result fields as dir/<json field name>.json files.
+type ResultsDirStore struct {
+	Dir string
+}
+
+func NewResultsDirStore(dir string) *ResultsDirStore {
+	return &ResultsDirStore{Dir: dir}
+}
+
+func (s *ResultsDirStore) Store(name string, value []byte) (string, error) {
+	path, err := filepath.Abs(filepath.Join(s.Dir, name+".json"))
+	if err != nil {
+		return "", err
+	}
+	if err := WriteResultFile(path, value); err != nil {
+		return "", fmt.Errorf("failed to write result '%s' into '%s': %w", name, path, err)
+	}
+	return path, nil
+}
+
+// Maximum size of the results JSON in bytes, 0 for no limit.
+var resultsSizeLimit int
+
+// Where to store the result fields over the size limit.
+var resultsOverflowStore ResultsOverflowStore
+
+// SetResultsSizeLimit makes CreateResultJson keep the results JSON within limit bytes for the rest of the process.
+// Tekton caps the size of the results, so the largest result fields are saved into the store
+// and replaced in the JSON by a ResultReference until the JSON fits. A zero limit disables it.
+func SetResultsSizeLimit(limit int, store ResultsOverflowStore) {
+	resultsSizeLimit = limit
+	resultsOverflowStore = store
+}
+
+type resultField struct {
+	name  string
+	value json.RawMessage
+}
+
+// Replace the largest fields of the results JSON object with references to their stored values,
+// until the JSON is within the limit.
+// As in the Tekton results, string values are stored as they are, other values as JSON.
+func offloadLargeResults(resultJson []byte, limit int, store ResultsOverflowStore) ([]byte, error) {
+	if len(resultJson) <= limit {
+		return resultJson, nil
+	}
+
+	fields, err := parseResultFields(resultJson)
+	if err != nil {
+		return nil, err
+	}
+
+	bySize := make([]int, 0, len(fields))
+	for i, field := range fields {
+		if string(field.value) != "null" {
+			bySize = append(bySize, i)
+		}
+	}
+	// Stable to keep the field order deterministic for fields of the same size
+	slices.SortStableFunc(bySize, func(a, b int) int {
+		return cmp.Compare(len(fields[b].value), len(fields[a].value))
+	})
+
+	// A reference to a value smaller than this would only make the JSON larger
+	minReferenceJson, _ := json.Marshal(ResultReference{Digest: "sha256:" + strings.Repeat("0", 64)})
+
+	size := len(resultJson)
+	for _, i := range bySize {
+		field := &fields[i]
+		if size <= limit || len(field.value) <= len(minReferenceJson) {
+			break
+		}
+
+		value := []byte(field.value)
+		var str string
+		if err := json.Unmarshal(value, &str); err == nil {
+			value = []byte(str)
+		}
+		digest := sha256.Sum256(value)
+		ref := ResultReference{
+			Digest: "sha256:" + hex.EncodeToString(digest[:]),
+			Size:   len(value),
+		}
+		ref.Ref, err = store.Store(field.name, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store result '%s': %w", field.name, err)
+		}
+		refJson, err := json.Marshal(ref)
+		if err != nil {
+			return nil, err
+		}
+		l.Logger.Infof("Result '%s' has %d bytes, stored it in %s", field.name, len(value), ref.Ref)
+
+		size += len(refJson) - len(field.value)
+		field.value = refJson
+	}
+
+	if size > limit {
+		return nil, fmt.Errorf("results JSON has %d bytes with the large results stored externally, over the limit of %d bytes", size, limit)
+	}
+	return marshalResultFields(fields), nil
+}
+
+// Split a JSON object into its fields, keeping their order.
+func parseResultFields(resultJson []byte) ([]resultField, error) {
+	dec := json.NewDecoder(bytes.NewReader(resultJson))
+	if token, err := dec.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("results must be a JSON object to limit their size")
+	}
+
+	var fields []resultField
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := token.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, resultField{name: name, value: value})
+	}
+	return fields, nil
+}
+
+func marshalResultFields(fields []resultField) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(field.name)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(field.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
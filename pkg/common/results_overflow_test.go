@@ -0,0 +1,142 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type mockResultsStore struct {
+	stored map[string]string
+	err    error
+}
+
+func (s *mockResultsStore) Store(name string, value []byte) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	if s.stored == nil {
+		s.stored = map[string]string{}
+	}
+	s.stored[name] = string(value)
+	return "store/" + name, nil
+}
+
+func sha256Hex(value string) string {
+	digest := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(digest[:])
+}
+
+func TestOffloadLargeResults(t *testing.T) {
+	largeValue := strings.Repeat("x", 200)
+
+	t.Run("should keep results within the limit as they are", func(t *testing.T) {
+		g := NewWithT(t)
+
+		store := &mockResultsStore{}
+		resultJson := []byte(`{"image_url":"quay.io/org/app:v1","tags":["v1"]}`)
+		result, err := offloadLargeResults(resultJson, len(resultJson), store)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(result)).To(Equal(string(resultJson)))
+		g.Expect(store.stored).To(BeEmpty())
+	})
+
+	t.Run("should replace the largest fields with references", func(t *testing.T) {
+		g := NewWithT(t)
+
+		store := &mockResultsStore{}
+		resultJson, err := json.Marshal(map[string]any{
+			"a_small": "v1",
+			"b_large": largeValue,
+			"c_list":  []string{largeValue[:150]},
+			"d_null":  nil,
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+
+		result, err := offloadLargeResults(resultJson, 350, store)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(len(result)).To(BeNumerically("<=", 350))
+
+		// Only the largest field had to go, the string is stored without the quotes
+		g.Expect(store.stored).To(Equal(map[string]string{"b_large": largeValue}))
+		g.Expect(string(result)).To(Equal(`{"a_small":"v1","b_large":{"ref":"store/b_large",` +
+			`"digest":"sha256:` + sha256Hex(largeValue) + `","size":200},"c_list":["` + largeValue[:150] + `"],"d_null":null}`))
+	})
+
+	t.Run("should store other values as JSON", func(t *testing.T) {
+		g := NewWithT(t)
+
+		store := &mockResultsStore{}
+		resultJson := []byte(`{"a":"v1","list":["` + largeValue + `"]}`)
+		result, err := offloadLargeResults(resultJson, 150, store)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(store.stored).To(HaveKeyWithValue("list", `["`+largeValue+`"]`))
+
+		var fields map[string]json.RawMessage
+		g.Expect(json.Unmarshal(result, &fields)).To(Succeed())
+		var ref ResultReference
+		g.Expect(json.Unmarshal(fields["list"], &ref)).To(Succeed())
+		g.Expect(ref).To(Equal(ResultReference{Ref: "store/list", Digest: "sha256:" + sha256Hex(`["`+largeValue+`"]`), Size: 204}))
+	})
+
+	t.Run("should error if the results don't fit with the large fields stored", func(t *testing.T) {
+		g := NewWithT(t)
+
+		resultJson := []byte(`{"a":"` + largeValue + `","b":"v1"}`)
+		_, err := offloadLargeResults(resultJson, 20, &mockResultsStore{})
+		g.Expect(err).To(MatchError(ContainSubstring("over the limit of 20 bytes")))
+	})
+
+	t.Run("should error if a value cannot be stored", func(t *testing.T) {
+		g := NewWithT(t)
+
+		resultJson := []byte(`{"a":"` + largeValue + `"}`)
+		_, err := offloadLargeResults(resultJson, 100, &mockResultsStore{err: errors.New("registry down")})
+		g.Expect(err).To(MatchError(ContainSubstring("failed to store result 'a': registry down")))
+	})
+
+	t.Run("should error if results are not an object", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := offloadLargeResults([]byte(`["`+largeValue+`"]`), 100, &mockResultsStore{})
+		g.Expect(err).To(MatchError(ContainSubstring("must be a JSON object")))
+	})
+}
+
+func TestResultsWriter_SizeLimit(t *testing.T) {
+	g := NewWithT(t)
+
+	overflowDir := t.TempDir()
+	tektonDir := t.TempDir()
+	SetResultsSizeLimit(240, NewResultsDirStore(overflowDir))
+	SetTektonResultsDir(tektonDir)
+	t.Cleanup(func() {
+		SetResultsSizeLimit(0, nil)
+		SetTektonResultsDir("")
+	})
+
+	largeValue := strings.Repeat("x", 200)
+	writer := NewResultsWriter()
+	result, err := writer.CreateResultJson(map[string]string{"image_url": "quay.io/org/app:v1", "sbom": largeValue})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	sbomPath := filepath.Join(overflowDir, "sbom.json")
+	content, err := os.ReadFile(sbomPath)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(content)).To(Equal(largeValue))
+
+	reference := `{"ref":"` + sbomPath + `","digest":"sha256:` + sha256Hex(largeValue) + `","size":200}`
+	g.Expect(result).To(Equal(`{"image_url":"quay.io/org/app:v1","sbom":` + reference + `}`))
+
+	// The Tekton results get the reference too
+	tektonSbom, err := os.ReadFile(filepath.Join(tektonDir, "sbom"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(tektonSbom)).To(Equal(reference))
+}
@@ -0,0 +1,184 @@
+package common
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/xeipuuv/gojsonschema"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// RegisteredResults returns the results struct type registered for the command with RegisterResults.
+func RegisteredResults(cmd *cobra.Command) (reflect.Type, bool) {
+	resultsType, ok := resultsTypesOfCommands[cmd]
+	return resultsType, ok
+}
+
+// ResultsSchema returns the JSON schema of the results JSON the command prints,
+// generated from the results struct registered with RegisterResults.
+//
+// Fields without omitempty are required, fields not declared in the struct are not allowed.
+// Slices, maps and pointers may be null, the same as they are marshalled when nil.
+func ResultsSchema(cmd *cobra.Command) ([]byte, error) {
+	resultsType, ok := RegisteredResults(cmd)
+	if !ok {
+		return nil, fmt.Errorf("command '%s' has no registered results", cmd.CommandPath())
+	}
+
+	schema := jsonSchemaOf(resultsType, map[reflect.Type]bool{})
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = fmt.Sprintf("Results of %s", cmd.CommandPath())
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generate the schema of the JSON encoding/json produces for the type.
+// visiting holds the structs being generated, to stop at recursive types.
+func jsonSchemaOf(t reflect.Type, visiting map[reflect.Type]bool) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		return nullable(jsonSchemaOf(t.Elem(), visiting))
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// Custom encoding, e.g. json.RawMessage, can be anything
+		return map[string]any{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is marshalled as a base64 string
+			return nullable(map[string]any{"type": "string"})
+		}
+		return nullable(map[string]any{"type": "array", "items": jsonSchemaOf(t.Elem(), visiting)})
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": jsonSchemaOf(t.Elem(), visiting)}
+	case reflect.Map:
+		return nullable(map[string]any{"type": "object", "additionalProperties": jsonSchemaOf(t.Elem(), visiting)})
+	case reflect.Struct:
+		if visiting[t] {
+			return map[string]any{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		properties := map[string]any{}
+		required := []string{}
+		addStructFields(t, properties, &required, visiting)
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	default:
+		// Interfaces can hold anything
+		return map[string]any{}
+	}
+}
+
+// Add the JSON fields of the struct, including the inlined fields of embedded structs.
+func addStructFields(t reflect.Type, properties map[string]any, required *[]string, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				addStructFields(fieldType, properties, required, visiting)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := jsonSchemaOf(fieldType, visiting)
+		if hasJsonOption(options, "string") {
+			fieldSchema = map[string]any{"type": "string"}
+		}
+		properties[name] = fieldSchema
+		if !hasJsonOption(options, "omitempty") && !hasJsonOption(options, "omitzero") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func hasJsonOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func nullable(schema map[string]any) map[string]any {
+	if t, ok := schema["type"].(string); ok {
+		schema["type"] = []string{t, "null"}
+	}
+	return schema
+}
+
+// Schema the results JSON is validated against, nil if the validation is disabled.
+var resultsSchema []byte
+
+// SetResultsSchema makes CreateResultJson validate the results JSON against the given schema
+// before printing or writing it, for the rest of the process. A nil schema disables the validation.
+func SetResultsSchema(schema []byte) {
+	resultsSchema = schema
+}
+
+// Validate the results JSON against the JSON schema.
+func validateResults(resultJson, schema []byte) error {
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(resultJson))
+	if err != nil {
+		return fmt.Errorf("failed to validate results: %w", err)
+	}
+	if !result.Valid() {
+		violations := []string{}
+		for _, e := range result.Errors() {
+			violations = append(violations, e.String())
+		}
+		return fmt.Errorf("results don't match the results schema of the command: %s", strings.Join(violations, "; "))
+	}
+	l.Logger.Debug("Results match the results schema of the command")
+	return nil
+}
@@ -0,0 +1,148 @@
+package common
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+func TestResultsSchema(t *testing.T) {
+	type layer struct {
+		Digest string `json:"digest"`
+		Size   int64  `json:"size"`
+	}
+	type embeddedResults struct {
+		Extra bool `json:"extra"`
+	}
+	type testResults struct {
+		embeddedResults
+		ImageUrl string            `json:"image_url"`
+		Tags     []string          `json:"tags,omitempty"`
+		Labels   map[string]string `json:"labels"`
+		Layers   []layer           `json:"layers"`
+		Created  time.Time         `json:"created"`
+		Ratio    float64           `json:"ratio,omitzero"`
+		Raw      json.RawMessage   `json:"raw,omitempty"`
+		Internal string            `json:"-"`
+	}
+
+	newCommand := func() *cobra.Command {
+		rootCmd := &cobra.Command{Use: "konflux-build-cli"}
+		cmd := &cobra.Command{Use: "do-things"}
+		rootCmd.AddCommand(cmd)
+		return cmd
+	}
+
+	t.Run("should generate schema from results struct", func(t *testing.T) {
+		g := NewWithT(t)
+		cmd := newCommand()
+		RegisterResults(cmd, testResults{})
+
+		schema, err := ResultsSchema(cmd)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(schema).To(MatchJSON(`{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"title": "Results of konflux-build-cli do-things",
+			"type": "object",
+			"additionalProperties": false,
+			"properties": {
+				"extra": {"type": "boolean"},
+				"image_url": {"type": "string"},
+				"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+				"labels": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+				"layers": {"type": ["array", "null"], "items": {
+					"type": "object",
+					"additionalProperties": false,
+					"properties": {"digest": {"type": "string"}, "size": {"type": "integer"}},
+					"required": ["digest", "size"]
+				}},
+				"created": {"type": "string", "format": "date-time"},
+				"ratio": {"type": "number"},
+				"raw": {}
+			},
+			"required": ["extra", "image_url", "labels", "layers", "created"]
+		}`))
+	})
+
+	t.Run("should stop at recursive types", func(t *testing.T) {
+		type node struct {
+			Name     string  `json:"name"`
+			Children []*node `json:"children"`
+		}
+		g := NewWithT(t)
+		cmd := newCommand()
+		RegisterResults(cmd, node{})
+
+		schema, err := ResultsSchema(cmd)
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(schema)).To(ContainSubstring(`"children"`))
+	})
+
+	t.Run("should fail for command without results", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := ResultsSchema(newCommand())
+
+		g.Expect(err).To(MatchError(ContainSubstring("has no registered results")))
+	})
+}
+
+func TestResultsWriter_CreateResultJson_schema(t *testing.T) {
+	type testResults struct {
+		ImageUrl string   `json:"image_url"`
+		Tags     []string `json:"tags,omitempty"`
+	}
+	type otherResults struct {
+		Digest string `json:"digest"`
+	}
+
+	cmd := &cobra.Command{Use: "do-things"}
+	RegisterResults(cmd, testResults{})
+	schema, err := ResultsSchema(cmd)
+	NewWithT(t).Expect(err).ToNot(HaveOccurred())
+
+	t.Cleanup(func() {
+		SetResultsSchema(nil)
+		SetResultsFile("")
+	})
+
+	t.Run("should print results matching the schema", func(t *testing.T) {
+		g := NewWithT(t)
+		SetResultsSchema(schema)
+
+		resultJson, err := NewResultsWriter().CreateResultJson(testResults{ImageUrl: "quay.io/org/app:v1", Tags: []string{"v1"}})
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(resultJson).To(Equal(`{"image_url":"quay.io/org/app:v1","tags":["v1"]}`))
+	})
+
+	t.Run("should fail before writing results not matching the schema", func(t *testing.T) {
+		g := NewWithT(t)
+		resultsFile := filepath.Join(t.TempDir(), "results.json")
+		SetResultsFile(resultsFile)
+		SetResultsSchema(schema)
+
+		_, err := NewResultsWriter().CreateResultJson(otherResults{Digest: "sha256:1234"})
+
+		g.Expect(err).To(MatchError(ContainSubstring("don't match the results schema")))
+		g.Expect(err.Error()).To(ContainSubstring("image_url is required"))
+		g.Expect(err.Error()).To(ContainSubstring("Additional property digest is not allowed"))
+		g.Expect(resultsFile).ToNot(BeAnExistingFile())
+	})
+
+	t.Run("should not validate without schema", func(t *testing.T) {
+		g := NewWithT(t)
+		SetResultsFile("")
+		SetResultsSchema(nil)
+
+		_, err := NewResultsWriter().CreateResultJson(otherResults{Digest: "sha256:1234"})
+
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+}
@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"math"
 	"os"
+	"os/user"
 	"path/filepath"
+	"strconv"
+	"syscall"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -196,4 +199,190 @@ func TestResultsWriter_CreateResultJson(t *testing.T) {
 		_, err := writer.CreateResultJson(nanFloat)
 		g.Expect(err).To(HaveOccurred())
 	})
+
+	t.Run("should write each result field into a file in tekton results mode", func(t *testing.T) {
+		g := NewWithT(t)
+
+		resultsDir := t.TempDir()
+		SetTektonResultsDir(resultsDir)
+		t.Cleanup(func() { SetTektonResultsDir("") })
+
+		type TestResult struct {
+			ImageUrl    string            `json:"image_url"`
+			Digests     []string          `json:"digests"`
+			Annotations map[string]string `json:"annotations"`
+			Count       int               `json:"count"`
+			Empty       *string           `json:"empty"`
+			Omitted     string            `json:"omitted,omitempty"`
+		}
+
+		writer := NewResultsWriter()
+		result, err := writer.CreateResultJson(TestResult{
+			ImageUrl:    "quay.io/org/app:v1",
+			Digests:     []string{"sha256:1234"},
+			Annotations: map[string]string{"a": "b"},
+			Count:       2,
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result).To(ContainSubstring(`"image_url":"quay.io/org/app:v1"`))
+
+		expectedFiles := map[string]string{
+			"image_url":   "quay.io/org/app:v1",
+			"digests":     `["sha256:1234"]`,
+			"annotations": `{"a":"b"}`,
+			"count":       "2",
+		}
+		for name, expectedContent := range expectedFiles {
+			content, err := os.ReadFile(filepath.Join(resultsDir, name))
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(string(content)).To(Equal(expectedContent))
+		}
+		entries, err := os.ReadDir(resultsDir)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(entries).To(HaveLen(len(expectedFiles)))
+	})
+
+	t.Run("should error in tekton results mode if results are not an object", func(t *testing.T) {
+		g := NewWithT(t)
+
+		SetTektonResultsDir(t.TempDir())
+		t.Cleanup(func() { SetTektonResultsDir("") })
+
+		writer := NewResultsWriter()
+		_, err := writer.CreateResultJson("result")
+		g.Expect(err).To(MatchError(ContainSubstring("tekton results must be a JSON object")))
+	})
+
+	t.Run("should error in tekton results mode if results directory does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+
+		SetTektonResultsDir(filepath.Join(t.TempDir(), "nonexistent"))
+		t.Cleanup(func() { SetTektonResultsDir("") })
+
+		writer := NewResultsWriter()
+		_, err := writer.CreateResultJson(map[string]string{"image_url": "quay.io/org/app:v1"})
+		g.Expect(err).To(MatchError(ContainSubstring("failed to write tekton result")))
+	})
+}
+
+func TestResultsWriter_ResultsFile(t *testing.T) {
+	t.Run("should write results json into results file", func(t *testing.T) {
+		g := NewWithT(t)
+
+		resultsDir := t.TempDir()
+		resultsPath := filepath.Join(resultsDir, "results.json")
+		g.Expect(os.WriteFile(resultsPath, []byte("old results"), 0644)).To(Succeed())
+		SetResultsFile(resultsPath)
+		t.Cleanup(func() { SetResultsFile("") })
+
+		writer := NewResultsWriter()
+		result, err := writer.CreateResultJson(map[string]string{"image_url": "quay.io/org/app:v1"})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(result).To(Equal(`{"image_url":"quay.io/org/app:v1"}`))
+
+		content, err := os.ReadFile(resultsPath)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(content)).To(Equal(result))
+
+		info, err := os.Stat(resultsPath)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(info.Mode().Perm()).To(Equal(os.FileMode(0644)))
+
+		// No temporary files are left behind
+		entries, err := os.ReadDir(resultsDir)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(entries).To(HaveLen(1))
+	})
+
+	t.Run("should error if results file cannot be written", func(t *testing.T) {
+		g := NewWithT(t)
+
+		SetResultsFile(filepath.Join(t.TempDir(), "nonexistent", "results.json"))
+		t.Cleanup(func() { SetResultsFile("") })
+
+		writer := NewResultsWriter()
+		_, err := writer.CreateResultJson(map[string]string{"image_url": "quay.io/org/app:v1"})
+		g.Expect(err).To(MatchError(ContainSubstring("failed to write results file")))
+	})
+}
+
+func TestParseResultFileOptions(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should return defaults", func(t *testing.T) {
+		options, err := ParseResultFileOptions("", "", false)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(options).To(Equal(DefaultResultFileOptions()))
+	})
+
+	t.Run("should parse mode, numeric group and fsync", func(t *testing.T) {
+		options, err := ParseResultFileOptions("0640", "1000", true)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(options).To(Equal(ResultFileOptions{Mode: 0640, Gid: 1000, Fsync: true}))
+	})
+
+	t.Run("should look up group by name", func(t *testing.T) {
+		group, err := user.LookupGroupId(strconv.Itoa(os.Getgid()))
+		if err != nil {
+			t.Skipf("current group can't be looked up: %s", err)
+		}
+
+		options, err := ParseResultFileOptions("", group.Name, false)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(options.Gid).To(Equal(os.Getgid()))
+	})
+
+	t.Run("should fail on invalid mode", func(t *testing.T) {
+		_, err := ParseResultFileOptions("0999", "", false)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("invalid result file mode"))
+
+		_, err = ParseResultFileOptions("01777", "", false)
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("should fail on unknown group", func(t *testing.T) {
+		_, err := ParseResultFileOptions("", "no-such-group-kbc", false)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("invalid result file group"))
+	})
+}
+
+func TestWriteResultFile(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Cleanup(func() { SetResultFileOptions(DefaultResultFileOptions()) })
+
+	t.Run("should apply configured mode and group", func(t *testing.T) {
+		SetResultFileOptions(ResultFileOptions{Mode: 0640, Gid: os.Getgid(), Fsync: true})
+
+		filePath := filepath.Join(t.TempDir(), "result")
+		g.Expect(WriteResultFile(filePath, []byte("content"))).To(Succeed())
+
+		content, err := os.ReadFile(filePath)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(content)).To(Equal("content"))
+
+		fileInfo, err := os.Stat(filePath)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(fileInfo.Mode().Perm()).To(Equal(os.FileMode(0640)))
+		g.Expect(int(fileInfo.Sys().(*syscall.Stat_t).Gid)).To(Equal(os.Getgid()))
+	})
+
+	t.Run("should change mode of existing file", func(t *testing.T) {
+		SetResultFileOptions(ResultFileOptions{Mode: 0666, Gid: -1})
+
+		filePath := filepath.Join(t.TempDir(), "result")
+		g.Expect(os.WriteFile(filePath, []byte("old content"), 0600)).To(Succeed())
+
+		g.Expect(WriteResultFile(filePath, []byte("new"))).To(Succeed())
+
+		content, err := os.ReadFile(filePath)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(content)).To(Equal("new"))
+
+		fileInfo, err := os.Stat(filePath)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(fileInfo.Mode().Perm()).To(Equal(os.FileMode(0666)))
+	})
 }
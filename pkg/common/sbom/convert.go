@@ -0,0 +1,313 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ConvertFile returns a CycloneDX or SPDX SBOM file as JSON in the given format (cyclonedx or spdx).
+// An SBOM already in the format is returned as it is.
+func ConvertFile(path, format string) ([]byte, error) {
+	doc, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch Format(doc) {
+	case "":
+		return nil, fmt.Errorf("%s is not a CycloneDX or SPDX SBOM", path)
+	case format:
+		return os.ReadFile(path) //nolint:gosec // same file as above
+	}
+	return json.MarshalIndent(Convert(doc, format), "", "  ")
+}
+
+// Convert converts the SBOM to the other format. Only the packages, their identification (name, version,
+// purl, checksums, licenses) and their dependencies are converted, SPDX files are dropped.
+func Convert(doc Document, format string) Document {
+	if format == "spdx" {
+		return cycloneDXToSPDX(doc)
+	}
+	return spdxToCycloneDX(doc)
+}
+
+// Hash algorithms of CycloneDX => SPDX checksum algorithms
+var cycloneDXHashAlgorithms = map[string]string{
+	"MD5":     "MD5",
+	"SHA-1":   "SHA1",
+	"SHA-256": "SHA256",
+	"SHA-384": "SHA384",
+	"SHA-512": "SHA512",
+}
+
+func cycloneDXToSPDX(doc Document) Document {
+	var components []map[string]any
+	var collect func(items []any)
+	collect = func(items []any) {
+		for _, item := range items {
+			if component, ok := item.(map[string]any); ok {
+				components = append(components, component)
+				nested, _ := component["components"].([]any)
+				collect(nested)
+			}
+		}
+	}
+	collect(List(doc, "components"))
+
+	refIds := make(map[string]string)
+	toPackage := func(component map[string]any) map[string]any {
+		purl, _ := component["purl"].(string)
+		ref, _ := component["bom-ref"].(string)
+		key := purl
+		if key == "" {
+			key = ref
+		}
+		if key == "" {
+			key = fmt.Sprintf("%v@%v", component["name"], component["version"])
+		}
+		id := fmt.Sprintf("SPDXRef-Package-%x", sha256.Sum256([]byte(key)))[:len("SPDXRef-Package-")+16]
+		if ref != "" {
+			refIds[ref] = id
+		}
+
+		pkg := map[string]any{
+			"SPDXID":           id,
+			"name":             fmt.Sprint(component["name"]),
+			"downloadLocation": "NOASSERTION",
+			"filesAnalyzed":    false,
+			"licenseConcluded": "NOASSERTION",
+			"licenseDeclared":  cycloneDXLicenseExpression(component),
+			"copyrightText":    "NOASSERTION",
+		}
+		if version, ok := component["version"].(string); ok && version != "" {
+			pkg["versionInfo"] = version
+		}
+		if purl != "" {
+			pkg["externalRefs"] = []any{map[string]any{
+				"referenceCategory": "PACKAGE-MANAGER",
+				"referenceType":     "purl",
+				"referenceLocator":  purl,
+			}}
+		}
+		var checksums []any
+		for _, item := range List(component, "hashes") {
+			hash, _ := item.(map[string]any)
+			if algorithm, ok := cycloneDXHashAlgorithms[fmt.Sprint(hash["alg"])]; ok {
+				checksums = append(checksums, map[string]any{"algorithm": algorithm, "checksumValue": hash["content"]})
+			}
+		}
+		if len(checksums) > 0 {
+			pkg["checksums"] = checksums
+		}
+		return pkg
+	}
+
+	var packages, relationships []any
+	root := ""
+	name := "sbom"
+	metadata, _ := doc["metadata"].(map[string]any)
+	if rootComponent, ok := metadata["component"].(map[string]any); ok {
+		rootPackage := toPackage(rootComponent)
+		root = rootPackage["SPDXID"].(string)
+		name = rootPackage["name"].(string)
+		packages = append(packages, rootPackage)
+		relationships = append(relationships, map[string]any{
+			"spdxElementId":      SPDXDocumentID,
+			"relationshipType":   "DESCRIBES",
+			"relatedSpdxElement": root,
+		})
+	}
+	for _, component := range components {
+		pkg := toPackage(component)
+		packages = append(packages, pkg)
+		relationship := map[string]any{
+			"spdxElementId":      SPDXDocumentID,
+			"relationshipType":   "DESCRIBES",
+			"relatedSpdxElement": pkg["SPDXID"],
+		}
+		if root != "" {
+			relationship["spdxElementId"] = root
+			relationship["relationshipType"] = "CONTAINS"
+		}
+		relationships = append(relationships, relationship)
+	}
+	for _, item := range List(doc, "dependencies") {
+		dependency, _ := item.(map[string]any)
+		from, ok := refIds[fmt.Sprint(dependency["ref"])]
+		if !ok {
+			continue
+		}
+		dependsOn, _ := dependency["dependsOn"].([]any)
+		for _, ref := range dependsOn {
+			if to, ok := refIds[fmt.Sprint(ref)]; ok {
+				relationships = append(relationships, map[string]any{
+					"spdxElementId":      from,
+					"relationshipType":   "DEPENDS_ON",
+					"relatedSpdxElement": to,
+				})
+			}
+		}
+	}
+
+	created, _ := metadata["timestamp"].(string)
+	if created == "" {
+		created = time.Now().UTC().Format(time.RFC3339)
+	}
+	content, _ := json.Marshal(doc)
+	return Document{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            SPDXDocumentID,
+		"name":              name,
+		"documentNamespace": fmt.Sprintf("https://konflux-ci.dev/spdxdocs/%s-%x", strings.ReplaceAll(name, "/", "-"), sha256.Sum256(content)),
+		"creationInfo": map[string]any{
+			"created":  created,
+			"creators": []any{"Tool: konflux-build-cli"},
+		},
+		"packages":      packages,
+		"relationships": relationships,
+	}
+}
+
+// The SPDX license expression of the CycloneDX component licenses, NOASSERTION if there are none.
+// Licenses given by name only have no SPDX identifier and are skipped.
+func cycloneDXLicenseExpression(component map[string]any) string {
+	var expressions []string
+	for _, item := range List(component, "licenses") {
+		choice, _ := item.(map[string]any)
+		if expression, ok := choice["expression"].(string); ok && expression != "" {
+			expressions = append(expressions, expression)
+		} else if license, ok := choice["license"].(map[string]any); ok {
+			if id, ok := license["id"].(string); ok && id != "" {
+				expressions = append(expressions, id)
+			}
+		}
+	}
+	switch len(expressions) {
+	case 0:
+		return "NOASSERTION"
+	case 1:
+		return expressions[0]
+	}
+	for i, expression := range expressions {
+		if strings.Contains(expression, " ") {
+			expressions[i] = "(" + expression + ")"
+		}
+	}
+	return strings.Join(expressions, " AND ")
+}
+
+func spdxToCycloneDX(doc Document) Document {
+	root := SPDXDescribedElement(doc)
+
+	idRefs := make(map[string]string)
+	toComponent := func(pkg map[string]any) map[string]any {
+		id := fmt.Sprint(pkg["SPDXID"])
+		ref := SPDXPackagePurl(pkg)
+		if ref == "" {
+			ref = id
+		}
+		idRefs[id] = ref
+
+		component := map[string]any{
+			"type":    "library",
+			"bom-ref": ref,
+			"name":    fmt.Sprint(pkg["name"]),
+		}
+		if version, ok := pkg["versionInfo"].(string); ok && version != "" {
+			component["version"] = version
+		}
+		if purl := SPDXPackagePurl(pkg); purl != "" {
+			component["purl"] = purl
+		}
+		for _, field := range []string{"licenseDeclared", "licenseConcluded"} {
+			if license, ok := pkg[field].(string); ok && license != "" && license != "NOASSERTION" && license != "NONE" {
+				component["licenses"] = []any{map[string]any{"expression": license}}
+				break
+			}
+		}
+		var hashes []any
+		for _, item := range List(pkg, "checksums") {
+			checksum, _ := item.(map[string]any)
+			for alg, algorithm := range cycloneDXHashAlgorithms {
+				if checksum["algorithm"] == algorithm {
+					hashes = append(hashes, map[string]any{"alg": alg, "content": checksum["checksumValue"]})
+				}
+			}
+		}
+		if len(hashes) > 0 {
+			component["hashes"] = hashes
+		}
+		return component
+	}
+
+	metadata := map[string]any{}
+	if creationInfo, ok := doc["creationInfo"].(map[string]any); ok {
+		if created, ok := creationInfo["created"].(string); ok {
+			metadata["timestamp"] = created
+		}
+	}
+	var components []any
+	for _, item := range List(doc, "packages") {
+		pkg, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		component := toComponent(pkg)
+		if pkg["SPDXID"] == root {
+			component["type"] = "application"
+			if strings.HasPrefix(SPDXPackagePurl(pkg), "pkg:oci/") {
+				component["type"] = "container"
+			}
+			metadata["component"] = component
+			continue
+		}
+		components = append(components, component)
+	}
+
+	dependsOn := make(map[string][]any)
+	var refs []string
+	addDependency := func(from, to any) {
+		fromRef, ok := idRefs[fmt.Sprint(from)]
+		if !ok {
+			return
+		}
+		toRef, ok := idRefs[fmt.Sprint(to)]
+		if !ok || slices.Contains(dependsOn[fromRef], any(toRef)) {
+			return
+		}
+		if _, seen := dependsOn[fromRef]; !seen {
+			refs = append(refs, fromRef)
+		}
+		dependsOn[fromRef] = append(dependsOn[fromRef], toRef)
+	}
+	for _, item := range List(doc, "relationships") {
+		relationship, _ := item.(map[string]any)
+		switch relationship["relationshipType"] {
+		case "DEPENDS_ON":
+			addDependency(relationship["spdxElementId"], relationship["relatedSpdxElement"])
+		case "DEPENDENCY_OF":
+			addDependency(relationship["relatedSpdxElement"], relationship["spdxElementId"])
+		}
+	}
+	var dependencies []any
+	for _, ref := range refs {
+		dependencies = append(dependencies, map[string]any{"ref": ref, "dependsOn": dependsOn[ref]})
+	}
+
+	converted := Document{
+		"bomFormat":   "CycloneDX",
+		"specVersion": "1.5",
+		"version":     1,
+		"metadata":    metadata,
+		"components":  components,
+	}
+	if len(dependencies) > 0 {
+		converted["dependencies"] = dependencies
+	}
+	return converted
+}
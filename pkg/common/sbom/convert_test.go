@@ -0,0 +1,112 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_cycloneDXToSPDX(t *testing.T) {
+	g := NewWithT(t)
+
+	doc := Document{}
+	g.Expect(json.Unmarshal([]byte(`{
+		"bomFormat": "CycloneDX",
+		"metadata": {
+			"timestamp": "2026-01-01T00:00:00Z",
+			"component": {"type": "container", "name": "quay.io/org/app", "purl": "pkg:oci/app@sha256:abc"}
+		},
+		"components": [
+			{
+				"bom-ref": "bash", "name": "bash", "version": "5.1", "purl": "pkg:rpm/bash@5.1",
+				"licenses": [{"license": {"id": "GPL-3.0-or-later"}}, {"expression": "MIT OR Apache-2.0"}],
+				"hashes": [{"alg": "SHA-256", "content": "0123"}],
+				"components": [{"bom-ref": "nested", "name": "nested"}]
+			}
+		],
+		"dependencies": [{"ref": "bash", "dependsOn": ["nested", "unknown"]}]
+	}`), &doc)).To(Succeed())
+
+	converted := cycloneDXToSPDX(doc)
+
+	g.Expect(Format(converted)).To(Equal("spdx"))
+	g.Expect(converted["name"]).To(Equal("quay.io/org/app"))
+	g.Expect(converted["creationInfo"]).To(HaveKeyWithValue("created", "2026-01-01T00:00:00Z"))
+
+	packages := List(converted, "packages")
+	g.Expect(packages).To(HaveLen(3))
+	root := packages[0].(map[string]any)
+	bash := packages[1].(map[string]any)
+	nested := packages[2].(map[string]any)
+	g.Expect(SPDXDescribedElement(converted)).To(Equal(root["SPDXID"]))
+	g.Expect(SPDXPackagePurl(root)).To(Equal("pkg:oci/app@sha256:abc"))
+	g.Expect(bash).To(HaveKeyWithValue("versionInfo", "5.1"))
+	g.Expect(bash).To(HaveKeyWithValue("licenseDeclared", "GPL-3.0-or-later AND (MIT OR Apache-2.0)"))
+	g.Expect(bash).To(HaveKeyWithValue("checksums", []any{map[string]any{"algorithm": "SHA256", "checksumValue": "0123"}}))
+	g.Expect(nested).To(HaveKeyWithValue("licenseDeclared", "NOASSERTION"))
+
+	g.Expect(List(converted, "relationships")).To(ConsistOf(
+		map[string]any{"spdxElementId": SPDXDocumentID, "relationshipType": "DESCRIBES", "relatedSpdxElement": root["SPDXID"]},
+		map[string]any{"spdxElementId": root["SPDXID"], "relationshipType": "CONTAINS", "relatedSpdxElement": bash["SPDXID"]},
+		map[string]any{"spdxElementId": root["SPDXID"], "relationshipType": "CONTAINS", "relatedSpdxElement": nested["SPDXID"]},
+		map[string]any{"spdxElementId": bash["SPDXID"], "relationshipType": "DEPENDS_ON", "relatedSpdxElement": nested["SPDXID"]},
+	))
+}
+
+func Test_spdxToCycloneDX(t *testing.T) {
+	g := NewWithT(t)
+
+	doc := Document{}
+	g.Expect(json.Unmarshal([]byte(`{
+		"spdxVersion": "SPDX-2.3",
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"creationInfo": {"created": "2026-01-01T00:00:00Z"},
+		"packages": [
+			{
+				"SPDXID": "SPDXRef-Image", "name": "quay.io/org/app",
+				"externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:oci/app@sha256:abc"}]
+			},
+			{
+				"SPDXID": "SPDXRef-bash", "name": "bash", "versionInfo": "5.1", "licenseDeclared": "NOASSERTION", "licenseConcluded": "GPL-3.0-or-later",
+				"externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:rpm/bash@5.1"}],
+				"checksums": [{"algorithm": "SHA1", "checksumValue": "abcd"}]
+			},
+			{"SPDXID": "SPDXRef-glibc", "name": "glibc"}
+		],
+		"relationships": [
+			{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Image"},
+			{"spdxElementId": "SPDXRef-Image", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-bash"},
+			{"spdxElementId": "SPDXRef-bash", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-glibc"},
+			{"spdxElementId": "SPDXRef-glibc", "relationshipType": "DEPENDENCY_OF", "relatedSpdxElement": "SPDXRef-bash"}
+		]
+	}`), &doc)).To(Succeed())
+
+	converted := spdxToCycloneDX(doc)
+
+	g.Expect(Format(converted)).To(Equal("cyclonedx"))
+	g.Expect(converted["metadata"]).To(Equal(map[string]any{
+		"timestamp": "2026-01-01T00:00:00Z",
+		"component": map[string]any{
+			"type":    "container",
+			"bom-ref": "pkg:oci/app@sha256:abc",
+			"name":    "quay.io/org/app",
+			"purl":    "pkg:oci/app@sha256:abc",
+		},
+	}))
+	g.Expect(converted["components"]).To(Equal([]any{
+		map[string]any{
+			"type":     "library",
+			"bom-ref":  "pkg:rpm/bash@5.1",
+			"name":     "bash",
+			"version":  "5.1",
+			"purl":     "pkg:rpm/bash@5.1",
+			"licenses": []any{map[string]any{"expression": "GPL-3.0-or-later"}},
+			"hashes":   []any{map[string]any{"alg": "SHA-1", "content": "abcd"}},
+		},
+		map[string]any{"type": "library", "bom-ref": "SPDXRef-glibc", "name": "glibc"},
+	}))
+	g.Expect(converted["dependencies"]).To(Equal([]any{
+		map[string]any{"ref": "pkg:rpm/bash@5.1", "dependsOn": []any{"SPDXRef-glibc"}},
+	}))
+}
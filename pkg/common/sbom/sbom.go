@@ -0,0 +1,76 @@
+// Package sbom reads CycloneDX and SPDX SBOMs and converts them between the two formats.
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Document is a CycloneDX or SPDX SBOM as generic JSON, so that the fields this package
+// doesn't know about are preserved.
+type Document = map[string]any
+
+// ReadFile parses the SBOM JSON file at path.
+func ReadFile(path string) (Document, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // SBOMs written by syft or hermeto
+	if err != nil {
+		return nil, err
+	}
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// Format returns the format of the SBOM, cyclonedx or spdx, empty if it's neither.
+func Format(doc Document) string {
+	if doc["bomFormat"] == "CycloneDX" {
+		return "cyclonedx"
+	}
+	if _, ok := doc["spdxVersion"].(string); ok {
+		return "spdx"
+	}
+	return ""
+}
+
+// SPDXDocumentID is the SPDXID of the document itself.
+const SPDXDocumentID = "SPDXRef-DOCUMENT"
+
+// SPDXPackagePurl returns the purl of an SPDX package, from its external references.
+func SPDXPackagePurl(pkg map[string]any) string {
+	refs, _ := pkg["externalRefs"].([]any)
+	for _, item := range refs {
+		if ref, ok := item.(map[string]any); ok && ref["referenceType"] == "purl" {
+			if locator, ok := ref["referenceLocator"].(string); ok {
+				return locator
+			}
+		}
+	}
+	return ""
+}
+
+// SPDXDescribedElement returns the element an SPDX document describes, e.g. the image for the syft scan of the image.
+func SPDXDescribedElement(doc Document) string {
+	for _, item := range List(doc, "relationships") {
+		relationship, ok := item.(map[string]any)
+		if ok && relationship["spdxElementId"] == SPDXDocumentID && relationship["relationshipType"] == "DESCRIBES" {
+			if element, ok := relationship["relatedSpdxElement"].(string); ok {
+				return element
+			}
+		}
+	}
+	if describes := List(doc, "documentDescribes"); len(describes) > 0 {
+		if element, ok := describes[0].(string); ok {
+			return element
+		}
+	}
+	return ""
+}
+
+// List returns the doc[field] list, nil if it's not a list.
+func List(doc Document, field string) []any {
+	list, _ := doc[field].([]any)
+	return list
+}
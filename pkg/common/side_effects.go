@@ -0,0 +1,162 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+type SideEffectAction string
+
+const (
+	SideEffectCreated  SideEffectAction = "created"
+	SideEffectModified SideEffectAction = "modified"
+	SideEffectRenamed  SideEffectAction = "renamed"
+)
+
+// SideEffect describes one change a command made to the file system.
+type SideEffect struct {
+	Action SideEffectAction `json:"action"`
+	Path   string           `json:"path"`
+	// Path before the rename, only for renamed files.
+	OriginalPath string `json:"original_path,omitempty"`
+	// Content and mode of the file before modification, only for modified files.
+	OriginalContent []byte      `json:"original_content,omitempty"`
+	OriginalMode    os.FileMode `json:"original_mode,omitempty"`
+}
+
+// SideEffects records files written or renamed by a command outside its temp workspace,
+// so that they can be reported (side_effects.json) and reverted on a best-effort basis.
+// All methods are no-ops on a nil *SideEffects, callers don't need to check if tracing is enabled.
+type SideEffects struct {
+	Entries []SideEffect `json:"side_effects"`
+}
+
+func NewSideEffects() *SideEffects {
+	return &SideEffects{Entries: []SideEffect{}}
+}
+
+// RecordWrite must be called before writing to path.
+// Remembers the original content if the file exists, so that the write can be undone.
+// Only the first write to a given path is recorded.
+func (s *SideEffects) RecordWrite(path string) error {
+	if s == nil {
+		return nil
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range s.Entries {
+		if entry.Path == absPath {
+			return nil
+		}
+	}
+
+	entry := SideEffect{Action: SideEffectCreated, Path: absPath}
+
+	stat, err := os.Stat(absPath)
+	if err == nil && stat.Mode().IsRegular() {
+		content, err := os.ReadFile(absPath) //nolint:gosec // path of a file the command is about to overwrite
+		if err != nil {
+			return fmt.Errorf("failed to back up %s: %w", absPath, err)
+		}
+		entry.Action = SideEffectModified
+		entry.OriginalContent = content
+		entry.OriginalMode = stat.Mode().Perm()
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	s.Entries = append(s.Entries, entry)
+	return nil
+}
+
+// RecordRename must be called after successfully renaming oldPath to newPath.
+func (s *SideEffects) RecordRename(oldPath, newPath string) error {
+	if s == nil {
+		return nil
+	}
+	absOldPath, err := filepath.Abs(oldPath)
+	if err != nil {
+		return err
+	}
+	absNewPath, err := filepath.Abs(newPath)
+	if err != nil {
+		return err
+	}
+	s.Entries = append(s.Entries, SideEffect{Action: SideEffectRenamed, Path: absNewPath, OriginalPath: absOldPath})
+	return nil
+}
+
+// Save writes the recorded side effects as JSON into the given file.
+// The file may contain original content of overwritten files, hence the restrictive permissions.
+func (s *SideEffects) Save(path string) error {
+	if s == nil || path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write side effects into '%s': %w", path, err)
+	}
+	l.Logger.Debugf("Wrote %d side effect(s) into '%s'", len(s.Entries), path)
+	return nil
+}
+
+// LoadSideEffects reads side effects previously written by Save.
+func LoadSideEffects(path string) (*SideEffects, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // side effects file path from controlled input
+	if err != nil {
+		return nil, err
+	}
+	var s SideEffects
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse side effects file '%s': %w", path, err)
+	}
+	return &s, nil
+}
+
+// Undo reverts the recorded side effects in reverse order.
+// Reverting continues after a failure, all the errors are returned together.
+func (s *SideEffects) Undo() error {
+	if s == nil {
+		return nil
+	}
+	var errs []error
+	for i := len(s.Entries) - 1; i >= 0; i-- {
+		entry := s.Entries[i]
+		if err := undoSideEffect(entry); err != nil {
+			errs = append(errs, fmt.Errorf("failed to undo %s %s: %w", entry.Action, entry.Path, err))
+			continue
+		}
+		l.Logger.Infof("Reverted %s file %s", entry.Action, entry.Path)
+	}
+	return errors.Join(errs...)
+}
+
+func undoSideEffect(entry SideEffect) error {
+	switch entry.Action {
+	case SideEffectCreated:
+		if err := os.Remove(entry.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	case SideEffectModified:
+		// The file may have been made read-only, remove it rather than overwriting it
+		if err := os.Remove(entry.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return os.WriteFile(entry.Path, entry.OriginalContent, entry.OriginalMode)
+	case SideEffectRenamed:
+		return os.Rename(entry.Path, entry.OriginalPath)
+	default:
+		return fmt.Errorf("unknown action '%s'", entry.Action)
+	}
+}
@@ -0,0 +1,107 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSideEffects(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should be no-op on nil receiver", func(t *testing.T) {
+		var s *SideEffects
+		g.Expect(s.RecordWrite("/some/file")).To(Succeed())
+		g.Expect(s.RecordRename("/some/file", "/other/file")).To(Succeed())
+		g.Expect(s.Save(filepath.Join(t.TempDir(), "side_effects.json"))).To(Succeed())
+		g.Expect(s.Undo()).To(Succeed())
+	})
+
+	t.Run("should record created, modified and renamed files", func(t *testing.T) {
+		tempDir := t.TempDir()
+		existingFile := filepath.Join(tempDir, "existing")
+		newFile := filepath.Join(tempDir, "new")
+		renamedFile := filepath.Join(tempDir, "renamed")
+		g.Expect(os.WriteFile(existingFile, []byte("original"), 0640)).To(Succeed())
+
+		s := NewSideEffects()
+		g.Expect(s.RecordWrite(existingFile)).To(Succeed())
+		g.Expect(s.RecordWrite(newFile)).To(Succeed())
+		g.Expect(os.WriteFile(newFile, []byte("new"), 0644)).To(Succeed())
+		// Only the first write is recorded
+		g.Expect(s.RecordWrite(newFile)).To(Succeed())
+		g.Expect(os.Rename(newFile, renamedFile)).To(Succeed())
+		g.Expect(s.RecordRename(newFile, renamedFile)).To(Succeed())
+
+		g.Expect(s.Entries).To(Equal([]SideEffect{
+			{Action: SideEffectModified, Path: existingFile, OriginalContent: []byte("original"), OriginalMode: 0640},
+			{Action: SideEffectCreated, Path: newFile},
+			{Action: SideEffectRenamed, Path: renamedFile, OriginalPath: newFile},
+		}))
+	})
+
+	t.Run("should save, load and undo side effects", func(t *testing.T) {
+		tempDir := t.TempDir()
+		existingFile := filepath.Join(tempDir, "existing")
+		createdFile := filepath.Join(tempDir, "created")
+		originalRepoFile := filepath.Join(tempDir, "hermeto.repo")
+		renamedRepoFile := filepath.Join(tempDir, "cachi2.repo")
+		sideEffectsFile := filepath.Join(tempDir, "side_effects.json")
+
+		g.Expect(os.WriteFile(existingFile, []byte("original"), 0644)).To(Succeed())
+		g.Expect(os.WriteFile(originalRepoFile, []byte("[repo]"), 0644)).To(Succeed())
+
+		s := NewSideEffects()
+		g.Expect(s.RecordWrite(existingFile)).To(Succeed())
+		g.Expect(os.Remove(existingFile)).To(Succeed())
+		g.Expect(os.WriteFile(existingFile, []byte("modified"), 0444)).To(Succeed())
+		g.Expect(s.RecordWrite(createdFile)).To(Succeed())
+		g.Expect(os.WriteFile(createdFile, []byte("created"), 0444)).To(Succeed())
+		g.Expect(os.Rename(originalRepoFile, renamedRepoFile)).To(Succeed())
+		g.Expect(s.RecordRename(originalRepoFile, renamedRepoFile)).To(Succeed())
+
+		g.Expect(s.Save(sideEffectsFile)).To(Succeed())
+		stat, err := os.Stat(sideEffectsFile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(stat.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		loaded, err := LoadSideEffects(sideEffectsFile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(loaded).To(Equal(s))
+
+		g.Expect(loaded.Undo()).To(Succeed())
+
+		content, err := os.ReadFile(existingFile)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(content)).To(Equal("original"))
+		g.Expect(createdFile).ToNot(BeAnExistingFile())
+		g.Expect(originalRepoFile).To(BeAnExistingFile())
+		g.Expect(renamedRepoFile).ToNot(BeAnExistingFile())
+	})
+
+	t.Run("should continue undoing after a failure", func(t *testing.T) {
+		tempDir := t.TempDir()
+		createdFile := filepath.Join(tempDir, "created")
+		g.Expect(os.WriteFile(createdFile, []byte("created"), 0644)).To(Succeed())
+
+		s := &SideEffects{Entries: []SideEffect{
+			{Action: SideEffectCreated, Path: createdFile},
+			{Action: SideEffectRenamed, Path: filepath.Join(tempDir, "missing"), OriginalPath: filepath.Join(tempDir, "original")},
+		}}
+
+		err := s.Undo()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("failed to undo renamed"))
+		g.Expect(createdFile).ToNot(BeAnExistingFile())
+	})
+
+	t.Run("should fail to load invalid side effects file", func(t *testing.T) {
+		sideEffectsFile := filepath.Join(t.TempDir(), "side_effects.json")
+		g.Expect(os.WriteFile(sideEffectsFile, []byte("not json"), 0644)).To(Succeed())
+
+		_, err := LoadSideEffects(sideEffectsFile)
+		g.Expect(err).To(HaveOccurred())
+	})
+}
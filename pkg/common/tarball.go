@@ -0,0 +1,198 @@
+package common
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// Fixed modification time of the files in the tarballs, so that the same inputs produce the same tarballs
+var tarballFileModTime = time.Unix(0, 0)
+
+// WriteDirTarball writes a gzip compressed tarball of the content of dir, skipping .git directories.
+func WriteDirTarball(w io.Writer, dir string) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		} else if !info.Mode().IsRegular() && !info.IsDir() {
+			// Sockets, devices and such have no place in a source tarball
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		NormalizeTarHeader(header)
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			return CopyFileInto(tarWriter, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// NormalizeTarHeader drops the owner and timestamps of the file, which depend on the build environment.
+func NormalizeTarHeader(header *tar.Header) {
+	header.Uid = 0
+	header.Gid = 0
+	header.Uname = ""
+	header.Gname = ""
+	header.ModTime = tarballFileModTime
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	header.Format = tar.FormatPAX
+}
+
+// CopyFileInto copies the content of the file at path to w.
+func CopyFileInto(w io.Writer, path string) error {
+	f, err := os.Open(path) //nolint:gosec // files of the source directories
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// ExtractTarball extracts a tarball into root. Entries that would escape root are rejected by os.Root.
+func ExtractTarball(root *os.Root, tarballPath string, gzipped bool) error {
+	tarball, err := os.Open(tarballPath) //nolint:gosec // tarball path is in a controlled temp dir
+	if err != nil {
+		return err
+	}
+	defer tarball.Close()
+
+	var r io.Reader = tarball
+	if gzipped {
+		gzipReader, err := gzip.NewReader(tarball)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		r = gzipReader
+	}
+
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if name == "." {
+			continue
+		}
+		if dir := filepath.Dir(name); dir != "." {
+			if err := root.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := root.MkdirAll(name, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			file, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fs.FileMode(header.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(file, tarReader); err != nil { //nolint:gosec // the artifact is trusted by its digest
+				_ = file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := root.Symlink(header.Linkname, name); err != nil {
+				return err
+			}
+		default:
+			l.Logger.Warnf("Skipping %s of unsupported type %c", header.Name, header.Typeflag)
+		}
+	}
+}
+
+// WriteDirTarballFile writes a reproducible tarball of dir into a file, see WriteDirTarball,
+// so that the same content results in the same file. Returns the digest of the tarball.
+func WriteDirTarballFile(tarballPath, dir string) (digest.Digest, error) {
+	tarball, err := os.Create(tarballPath) //nolint:gosec // path in the temporary workdir
+	if err != nil {
+		return "", err
+	}
+	digester := digest.Canonical.Digester()
+	if err := WriteDirTarball(io.MultiWriter(tarball, digester.Hash()), dir); err != nil {
+		_ = tarball.Close()
+		return "", err
+	}
+	if err := tarball.Close(); err != nil {
+		return "", err
+	}
+	return digester.Digest(), nil
+}
+
+// ExtractTarballFile extracts a tarball written by WriteDirTarballFile into dir.
+func ExtractTarballFile(tarballPath, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+	return ExtractTarball(root, tarballPath, true)
+}
@@ -0,0 +1,107 @@
+package common
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestWriteDirTarballFile(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Run("should archive directory without .git reproducibly", func(t *testing.T) {
+		dir := t.TempDir()
+		g.Expect(os.MkdirAll(filepath.Join(dir, ".git", "objects"), 0755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/main"), 0644)).To(Succeed())
+		g.Expect(os.MkdirAll(filepath.Join(dir, "cmd"), 0755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(dir, "cmd", "root.go"), []byte("package cmd"), 0644)).To(Succeed())
+
+		tarballPath := filepath.Join(t.TempDir(), "dir.tar.gz")
+		tarballDigest, err := WriteDirTarballFile(tarballPath, dir)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(os.Chtimes(filepath.Join(dir, "main.go"), time.Now(), time.Now())).To(Succeed())
+		g.Expect(WriteDirTarballFile(filepath.Join(t.TempDir(), "dir.tar.gz"), dir)).To(Equal(tarballDigest))
+
+		tarball, err := os.ReadFile(tarballPath)
+		g.Expect(err).ToNot(HaveOccurred())
+		gzipReader, err := gzip.NewReader(bytes.NewReader(tarball))
+		g.Expect(err).ToNot(HaveOccurred())
+		tarReader := tar.NewReader(gzipReader)
+		var names []string
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(header.Uid).To(Equal(0))
+			g.Expect(header.ModTime.Unix()).To(Equal(int64(0)))
+			names = append(names, header.Name)
+		}
+		g.Expect(names).To(ConsistOf("cmd/", "cmd/root.go", "main.go"))
+
+		extractedDir := filepath.Join(t.TempDir(), "extracted")
+		g.Expect(ExtractTarballFile(tarballPath, extractedDir)).To(Succeed())
+		g.Expect(os.ReadFile(filepath.Join(extractedDir, "main.go"))).To(Equal([]byte("package main")))
+		g.Expect(os.ReadFile(filepath.Join(extractedDir, "cmd", "root.go"))).To(Equal([]byte("package cmd")))
+		g.Expect(filepath.Join(extractedDir, ".git")).ToNot(BeAnExistingFile())
+	})
+}
+
+func TestExtractTarball(t *testing.T) {
+	writeTarball := func(t *testing.T, headers ...*tar.Header) string {
+		var buf bytes.Buffer
+		tarWriter := tar.NewWriter(&buf)
+		for _, header := range headers {
+			if err := tarWriter.WriteHeader(header); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tarWriter.Close(); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "layer.tar")
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("should not write outside of the output directory", func(t *testing.T) {
+		g := NewWithT(t)
+		outputDir := t.TempDir()
+		root, err := os.OpenRoot(outputDir)
+		g.Expect(err).ToNot(HaveOccurred())
+		defer root.Close()
+
+		tarball := writeTarball(t, &tar.Header{Name: "../escape", Typeflag: tar.TypeReg, Mode: 0644})
+
+		g.Expect(ExtractTarball(root, tarball, false)).ToNot(Succeed())
+		g.Expect(filepath.Join(filepath.Dir(outputDir), "escape")).ToNot(BeAnExistingFile())
+	})
+
+	t.Run("should not write through symlinks pointing outside of the output directory", func(t *testing.T) {
+		g := NewWithT(t)
+		outputDir := t.TempDir()
+		outsideDir := t.TempDir()
+		root, err := os.OpenRoot(outputDir)
+		g.Expect(err).ToNot(HaveOccurred())
+		defer root.Close()
+
+		tarball := writeTarball(t,
+			&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: outsideDir},
+			&tar.Header{Name: "link/escape", Typeflag: tar.TypeReg, Mode: 0644},
+		)
+
+		g.Expect(ExtractTarball(root, tarball, false)).ToNot(Succeed())
+		g.Expect(filepath.Join(outsideDir, "escape")).ToNot(BeAnExistingFile())
+	})
+}
@@ -0,0 +1,167 @@
+package common
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultTektonTaskImage is the image the steps of the generated Tekton tasks run in.
+const DefaultTektonTaskImage = "quay.io/konflux-ci/konflux-build-cli:latest"
+
+var resultsTypesOfCommands = map[*cobra.Command]reflect.Type{}
+
+// RegisterResults declares the results struct the command prints, e.g. commands.BuildResults{},
+// so that its fields can be declared as the results of the generated Tekton task.
+func RegisterResults(cmd *cobra.Command, results any) {
+	resultsTypesOfCommands[cmd] = reflect.TypeOf(results)
+}
+
+type tektonTask struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Metadata   tektonTaskMetadata `json:"metadata"`
+	Spec       tektonTaskSpec     `json:"spec"`
+}
+
+type tektonTaskMetadata struct {
+	Name string `json:"name"`
+}
+
+type tektonTaskSpec struct {
+	Description string             `json:"description,omitempty"`
+	Params      []tektonParam      `json:"params,omitempty"`
+	Results     []tektonTaskResult `json:"results,omitempty"`
+	Steps       []tektonStep       `json:"steps"`
+}
+
+type tektonParam struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	// nil for required params, an empty default still makes the param optional
+	Default *string `json:"default,omitempty"`
+}
+
+type tektonTaskResult struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+}
+
+type tektonStep struct {
+	Name    string      `json:"name"`
+	Image   string      `json:"image"`
+	Command []string    `json:"command"`
+	Args    []string    `json:"args,omitempty"`
+	Env     []tektonEnv `json:"env,omitempty"`
+}
+
+type tektonEnv struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// GenerateTektonTask renders the Tekton Task YAML running cmd in image.
+//
+// Every parameter of the command becomes a string param of the task with the same default,
+// passed to the step in the env var of the parameter. Array parameters take space separated values,
+// as their env vars do. The fields of the results struct become the task results,
+// written by the step in the Tekton results mode (see SetTektonResultsDir).
+func GenerateTektonTask(cmd *cobra.Command, image string) ([]byte, error) {
+	paramsConfig, ok := RegisteredParameters(cmd)
+	if !ok {
+		return nil, fmt.Errorf("command '%s' has no registered parameters", cmd.CommandPath())
+	}
+
+	// The command path without the root command
+	commandPath := strings.Fields(cmd.CommandPath())[1:]
+	step := tektonStep{
+		Name:    cmd.Name(),
+		Image:   image,
+		Command: append([]string{cmd.Root().Name()}, commandPath...),
+	}
+
+	params := []tektonParam{}
+	for _, name := range slices.Sorted(maps.Keys(paramsConfig)) {
+		p := paramsConfig[name]
+		tektonName := tektonParamName(p.Name)
+
+		description := p.Usage
+		if p.TypeKind == reflect.Array || p.TypeKind == reflect.Slice {
+			description += "\nSpace separated list."
+		}
+		param := tektonParam{Name: tektonName, Description: description, Type: "string"}
+		if !p.Required {
+			defaultValue := p.DefaultValue
+			param.Default = &defaultValue
+		}
+		params = append(params, param)
+
+		if p.EnvVarName != "" {
+			step.Env = append(step.Env, tektonEnv{Name: p.EnvVarName, Value: fmt.Sprintf("$(params.%s)", tektonName)})
+		} else {
+			step.Args = append(step.Args, fmt.Sprintf("--%s=$(params.%s)", p.Name, tektonName))
+		}
+	}
+
+	var results []tektonTaskResult
+	if resultsType, ok := resultsTypesOfCommands[cmd]; ok {
+		results = tektonResults(resultsType)
+		step.Env = append(step.Env, tektonEnv{Name: "KBC_TEKTON_RESULTS", Value: "true"})
+	}
+
+	task := tektonTask{
+		APIVersion: "tekton.dev/v1",
+		Kind:       "Task",
+		Metadata:   tektonTaskMetadata{Name: strings.Join(commandPath, "-")},
+		Spec: tektonTaskSpec{
+			Description: cmd.Short,
+			Params:      params,
+			Results:     results,
+			Steps:       []tektonStep{step},
+		},
+	}
+	return yaml.Marshal(task)
+}
+
+// Tekton params are conventionally upper case, e.g. output-ref becomes OUTPUT_REF.
+func tektonParamName(paramName string) string {
+	return strings.ToUpper(strings.ReplaceAll(paramName, "-", "_"))
+}
+
+// Declare the top-level JSON fields of the results struct as string results,
+// non-string values are written as JSON.
+func tektonResults(resultsType reflect.Type) []tektonTaskResult {
+	results := []tektonTaskResult{}
+	for i := 0; i < resultsType.NumField(); i++ {
+		field := resultsType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			// Fields of embedded structs are inlined in the JSON
+			results = append(results, tektonResults(field.Type)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		result := tektonTaskResult{Name: name, Type: "string"}
+		if field.Type.Kind() != reflect.String {
+			result.Description = "JSON encoded value."
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,124 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+func TestGenerateTektonTask(t *testing.T) {
+	type embeddedResults struct {
+		Extra string `json:"extra"`
+	}
+	type testResults struct {
+		embeddedResults
+		ImageUrl string   `json:"image_url"`
+		Tags     []string `json:"tags,omitempty"`
+		Internal string   `json:"-"`
+	}
+
+	newCommand := func() *cobra.Command {
+		rootCmd := &cobra.Command{Use: "konflux-build-cli"}
+		groupCmd := &cobra.Command{Use: "image"}
+		cmd := &cobra.Command{Use: "do-things", Short: "Does things"}
+		rootCmd.AddCommand(groupCmd)
+		groupCmd.AddCommand(cmd)
+
+		RegisterParameters(cmd, map[string]Parameter{
+			"image-url": {
+				Name:       "image-url",
+				EnvVarName: "KBC_DO_THINGS_IMAGE_URL",
+				TypeKind:   reflect.String,
+				Usage:      "Image to do things with.",
+				Required:   true,
+			},
+			"tags": {
+				Name:         "tags",
+				EnvVarName:   "KBC_DO_THINGS_TAGS",
+				TypeKind:     reflect.Slice,
+				DefaultValue: "latest stable",
+				Usage:        "Tags.",
+			},
+			"retries": {
+				Name:     "retries",
+				TypeKind: reflect.Int,
+				Usage:    "Retries.",
+			},
+		})
+		return cmd
+	}
+
+	t.Run("should render params, env mapping and results", func(t *testing.T) {
+		g := NewWithT(t)
+		cmd := newCommand()
+		RegisterResults(cmd, testResults{})
+
+		task, err := GenerateTektonTask(cmd, "quay.io/org/kbc:v1")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(task)).To(Equal(`apiVersion: tekton.dev/v1
+kind: Task
+metadata:
+  name: image-do-things
+spec:
+  description: Does things
+  params:
+  - description: Image to do things with.
+    name: IMAGE_URL
+    type: string
+  - default: ""
+    description: Retries.
+    name: RETRIES
+    type: string
+  - default: latest stable
+    description: |-
+      Tags.
+      Space separated list.
+    name: TAGS
+    type: string
+  results:
+  - name: extra
+    type: string
+  - name: image_url
+    type: string
+  - description: JSON encoded value.
+    name: tags
+    type: string
+  steps:
+  - args:
+    - --retries=$(params.RETRIES)
+    command:
+    - konflux-build-cli
+    - image
+    - do-things
+    env:
+    - name: KBC_DO_THINGS_IMAGE_URL
+      value: $(params.IMAGE_URL)
+    - name: KBC_DO_THINGS_TAGS
+      value: $(params.TAGS)
+    - name: KBC_TEKTON_RESULTS
+      value: "true"
+    image: quay.io/org/kbc:v1
+    name: do-things
+`))
+	})
+
+	t.Run("should omit results of commands without registered results", func(t *testing.T) {
+		g := NewWithT(t)
+		cmd := newCommand()
+
+		task, err := GenerateTektonTask(cmd, DefaultTektonTaskImage)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(task)).ToNot(ContainSubstring("results:"))
+		g.Expect(string(task)).ToNot(ContainSubstring("KBC_TEKTON_RESULTS"))
+	})
+
+	t.Run("should fail for commands without registered parameters", func(t *testing.T) {
+		g := NewWithT(t)
+		cmd := &cobra.Command{Use: "nothing"}
+
+		_, err := GenerateTektonTask(cmd, DefaultTektonTaskImage)
+		g.Expect(err).To(MatchError(ContainSubstring("has no registered parameters")))
+	})
+}
@@ -0,0 +1,154 @@
+// Package errors defines the categories of errors the CLI fails with.
+// Each category has its own process exit code, and failures are also reported
+// as a JSON object on stderr, so that callers can tell them apart without
+// parsing the error messages.
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+// Process exit codes of the error categories.
+const (
+	ExitCodeGeneric      = 1
+	ExitCodeValidation   = 2
+	ExitCodeToolMissing  = 3
+	ExitCodeRegistryAuth = 4
+	ExitCodeSubprocess   = 5
+)
+
+type Category string
+
+const (
+	CategoryGeneric      Category = "generic"
+	CategoryValidation   Category = "validation"
+	CategoryToolMissing  Category = "tool_missing"
+	CategoryRegistryAuth Category = "registry_auth"
+	CategorySubprocess   Category = "subprocess"
+)
+
+// ValidationError is an invalid parameter or a combination of parameters.
+type ValidationError struct {
+	Err error
+}
+
+// NewValidationError wraps err in a ValidationError, nil stays nil.
+func NewValidationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ValidationError{Err: err}
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// ToolMissingError is a CLI tool the command needs that is not installed.
+type ToolMissingError struct {
+	Tool string
+}
+
+func (e *ToolMissingError) Error() string { return fmt.Sprintf("%s CLI is not available", e.Tool) }
+
+// RegistryAuthError is a registry rejecting the credentials, or the lack of them.
+type RegistryAuthError struct {
+	Err error
+}
+
+func (e *RegistryAuthError) Error() string { return e.Err.Error() }
+func (e *RegistryAuthError) Unwrap() error { return e.Err }
+
+// SubprocessError is a CLI tool exiting with a non-zero exit code.
+type SubprocessError struct {
+	Command  string
+	ExitCode int
+	Err      error
+}
+
+func (e *SubprocessError) Error() string { return e.Err.Error() }
+func (e *SubprocessError) Unwrap() error { return e.Err }
+
+// Classify returns the category and the exit code of err.
+// A registry auth error is reported as such even though it's also a subprocess error.
+func Classify(err error) (Category, int) {
+	var registryAuthErr *RegistryAuthError
+	var toolMissingErr *ToolMissingError
+	var validationErr *ValidationError
+	var subprocessErr *SubprocessError
+	switch {
+	case errors.As(err, &registryAuthErr):
+		return CategoryRegistryAuth, ExitCodeRegistryAuth
+	case errors.As(err, &toolMissingErr):
+		return CategoryToolMissing, ExitCodeToolMissing
+	case errors.As(err, &validationErr):
+		return CategoryValidation, ExitCodeValidation
+	case errors.As(err, &subprocessErr):
+		return CategorySubprocess, ExitCodeSubprocess
+	default:
+		return CategoryGeneric, ExitCodeGeneric
+	}
+}
+
+// ExitCode returns the process exit code for err.
+func ExitCode(err error) int {
+	_, exitCode := Classify(err)
+	return exitCode
+}
+
+type errorJSON struct {
+	Category           Category `json:"category"`
+	Message            string   `json:"message"`
+	ExitCode           int      `json:"exit_code"`
+	Command            string   `json:"command,omitempty"`
+	SubprocessExitCode int      `json:"subprocess_exit_code,omitempty"`
+}
+
+// WriteJSON writes err to w as a single line {"error": {...}} JSON object.
+func WriteJSON(w io.Writer, err error) error {
+	category, exitCode := Classify(err)
+	errJSON := errorJSON{
+		Category: category,
+		Message:  l.Redact(err.Error()),
+		ExitCode: exitCode,
+	}
+	var subprocessErr *SubprocessError
+	if errors.As(err, &subprocessErr) {
+		errJSON.Command = subprocessErr.Command
+		errJSON.SubprocessExitCode = subprocessErr.ExitCode
+	}
+
+	content, jsonErr := json.Marshal(map[string]errorJSON{"error": errJSON})
+	if jsonErr != nil {
+		return jsonErr
+	}
+	_, writeErr := w.Write(append(content, '\n'))
+	return writeErr
+}
+
+// Functions Fatal calls with the exit code before exiting, see RegisterExitHandler.
+var exitHandlers []func(exitCode int)
+
+// RegisterExitHandler makes Fatal call handler with the exit code before exiting,
+// e.g. for writing what the command recorded so far.
+func RegisterExitHandler(handler func(exitCode int)) {
+	exitHandlers = append(exitHandlers, handler)
+}
+
+// Fatal logs err, writes it as JSON to stderr and exits with the exit code of its category.
+func Fatal(err error) {
+	l.Logger.Error(err)
+	if jsonErr := WriteJSON(os.Stderr, err); jsonErr != nil {
+		l.Logger.Warnf("failed to write error JSON: %s", jsonErr)
+	}
+	exitCode := ExitCode(err)
+	for _, handler := range exitHandlers {
+		handler(exitCode)
+	}
+	os.Exit(exitCode)
+}
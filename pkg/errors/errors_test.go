@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestClassify(t *testing.T) {
+	subprocessErr := &SubprocessError{Command: "skopeo", ExitCode: 1, Err: errors.New("exit status 1")}
+
+	tests := []struct {
+		name             string
+		err              error
+		expectedCategory Category
+		expectedExitCode int
+	}{
+		{
+			name:             "generic error",
+			err:              errors.New("something failed"),
+			expectedCategory: CategoryGeneric,
+			expectedExitCode: ExitCodeGeneric,
+		},
+		{
+			name:             "wrapped validation error",
+			err:              fmt.Errorf("parsing params: %w", NewValidationError(errors.New("invalid"))),
+			expectedCategory: CategoryValidation,
+			expectedExitCode: ExitCodeValidation,
+		},
+		{
+			name:             "tool missing error",
+			err:              &ToolMissingError{Tool: "oras"},
+			expectedCategory: CategoryToolMissing,
+			expectedExitCode: ExitCodeToolMissing,
+		},
+		{
+			name:             "subprocess error",
+			err:              fmt.Errorf("error on copying image: %w", subprocessErr),
+			expectedCategory: CategorySubprocess,
+			expectedExitCode: ExitCodeSubprocess,
+		},
+		{
+			name:             "registry auth error of a subprocess",
+			err:              fmt.Errorf("error on copying image: %w", &RegistryAuthError{Err: subprocessErr}),
+			expectedCategory: CategoryRegistryAuth,
+			expectedExitCode: ExitCodeRegistryAuth,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			category, exitCode := Classify(tc.err)
+
+			g.Expect(category).To(Equal(tc.expectedCategory))
+			g.Expect(exitCode).To(Equal(tc.expectedExitCode))
+			g.Expect(ExitCode(tc.err)).To(Equal(tc.expectedExitCode))
+		})
+	}
+}
+
+func TestNewValidationError(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(NewValidationError(nil)).To(BeNil())
+	err := NewValidationError(errors.New("output-ref is invalid"))
+	g.Expect(err).To(MatchError("output-ref is invalid"))
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Run("should write the category and exit code", func(t *testing.T) {
+		g := NewWithT(t)
+		var buf bytes.Buffer
+
+		g.Expect(WriteJSON(&buf, NewValidationError(errors.New("output-ref is invalid")))).To(Succeed())
+
+		g.Expect(buf.String()).To(Equal(`{"error":{"category":"validation","message":"output-ref is invalid","exit_code":2}}` + "\n"))
+	})
+
+	t.Run("should write the command and exit code of subprocess errors", func(t *testing.T) {
+		g := NewWithT(t)
+		var buf bytes.Buffer
+
+		err := fmt.Errorf("error on building image: %w", &SubprocessError{Command: "buildah", ExitCode: 125, Err: errors.New("exit status 125")})
+		g.Expect(WriteJSON(&buf, err)).To(Succeed())
+
+		g.Expect(buf.String()).To(MatchJSON(`{"error": {
+			"category": "subprocess",
+			"message": "error on building image: exit status 125",
+			"exit_code": 5,
+			"command": "buildah",
+			"subprocess_exit_code": 125
+		}}`))
+	})
+}
@@ -1,18 +1,45 @@
 package logger
 
 import (
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
 var Logger = logrus.New()
 
-func InitLogger(logLevel string) error {
+func init() {
+	Logger.AddHook(redactHook{})
+}
+
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// Name of the running command (e.g. "image build"), added to the JSON log entries.
+var command string
+
+func InitLogger(logLevel string, logFormat string) error {
 	Logger.SetOutput(os.Stderr)
-	Logger.SetFormatter(&logrus.TextFormatter{
-		EnvironmentOverrideColors: true,
-	})
+	Logger.ReplaceHooks(make(logrus.LevelHooks))
+	Logger.AddHook(redactHook{})
+
+	switch logFormat {
+	case LogFormatText, "":
+		Logger.SetFormatter(&logrus.TextFormatter{
+			EnvironmentOverrideColors: true,
+		})
+	case LogFormatJSON:
+		Logger.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339Nano,
+		})
+		Logger.AddHook(commandHook{})
+	default:
+		return fmt.Errorf("unknown log format '%s', expected %s or %s", logFormat, LogFormatText, LogFormatJSON)
+	}
 
 	level, err := logrus.ParseLevel(logLevel)
 	if err != nil {
@@ -22,3 +49,48 @@ func InitLogger(logLevel string) error {
 
 	return nil
 }
+
+// SetCommand sets the name of the running command.
+func SetCommand(name string) {
+	command = name
+}
+
+// Called with the duration of each finished step, see SetStepObserver.
+var stepObserver func(step string, duration time.Duration)
+
+// SetStepObserver makes StartStep report the duration of each finished step to observer,
+// e.g. for recording metrics. A nil observer disables it.
+func SetStepObserver(observer func(step string, duration time.Duration)) {
+	stepObserver = observer
+}
+
+// StartStep logs the start of a command step and returns a function that logs its end,
+// with the step duration in seconds.
+func StartStep(step string) func() {
+	start := time.Now()
+	entry := Logger.WithField("step", step)
+	entry.Debugf("Step %s started", step)
+	return func() {
+		duration := time.Since(start)
+		entry.WithField("duration", duration.Seconds()).Infof("Step %s finished", step)
+		if stepObserver != nil {
+			stepObserver(step, duration)
+		}
+	}
+}
+
+// Adds the command field to all log entries.
+type commandHook struct{}
+
+func (commandHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (commandHook) Fire(entry *logrus.Entry) error {
+	if command != "" {
+		if _, ok := entry.Data["command"]; !ok {
+			entry.Data["command"] = command
+		}
+	}
+	return nil
+}
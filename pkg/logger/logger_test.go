@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestInitLogger(t *testing.T) {
+	g := NewWithT(t)
+	defer func() {
+		SetCommand("")
+		_ = InitLogger("info", LogFormatText)
+	}()
+
+	t.Run("should log JSON entries with the command and step fields", func(t *testing.T) {
+		g.Expect(InitLogger("info", LogFormatJSON)).To(Succeed())
+		var buf bytes.Buffer
+		Logger.SetOutput(&buf)
+		SetCommand("image build")
+
+		endStep := StartStep("push")
+		endStep()
+
+		var entry map[string]any
+		g.Expect(json.Unmarshal(buf.Bytes(), &entry)).To(Succeed())
+		g.Expect(entry["msg"]).To(Equal("Step push finished"))
+		g.Expect(entry["level"]).To(Equal("info"))
+		g.Expect(entry["command"]).To(Equal("image build"))
+		g.Expect(entry["step"]).To(Equal("push"))
+		g.Expect(entry["duration"]).To(BeNumerically(">=", 0))
+		g.Expect(entry).To(HaveKey("time"))
+	})
+
+	t.Run("should not add the command field to text logs", func(t *testing.T) {
+		g.Expect(InitLogger("info", LogFormatText)).To(Succeed())
+		var buf bytes.Buffer
+		Logger.SetOutput(&buf)
+		SetCommand("image build")
+
+		Logger.Info("hello")
+
+		g.Expect(buf.String()).To(ContainSubstring("hello"))
+		g.Expect(strings.Contains(buf.String(), "command=")).To(BeFalse())
+	})
+
+	t.Run("should reject unknown log format", func(t *testing.T) {
+		g.Expect(InitLogger("info", "xml")).ToNot(Succeed())
+	})
+
+	t.Run("should reject unknown log level", func(t *testing.T) {
+		g.Expect(InitLogger("loud", LogFormatText)).ToNot(Succeed())
+	})
+}
+
+func TestStartStep(t *testing.T) {
+	g := NewWithT(t)
+	defer SetStepObserver(nil)
+
+	var steps []string
+	SetStepObserver(func(step string, duration time.Duration) {
+		g.Expect(duration).To(BeNumerically(">=", 0))
+		steps = append(steps, step)
+	})
+
+	endStep := StartStep("build")
+	g.Expect(steps).To(BeEmpty())
+	endStep()
+
+	g.Expect(steps).To(Equal([]string{"build"}))
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redacted = "***"
+
+// Shorter values are not registered as secrets, masking them would garble unrelated log output.
+const minSecretLength = 4
+
+// NAME=value assignments (build args, env vars) of environment-style names that look like credentials,
+// e.g. --build-arg API_TOKEN=abc or [param] build-args: [DB_PASSWORD=abc]. The name must be upper case
+// and start a word, so that flags like --secret=id=x or --key=cosign.pub are not masked. The value ends
+// at whitespace, a quote or a closing bracket, or at the closing quote if the assignment is quoted.
+var sensitiveAssignmentRegex = func() *regexp.Regexp {
+	name := `[A-Z0-9_]*(?:TOKEN|PASSWORD|PASSWD|SECRET|KEY)[A-Z0-9_]*=`
+	return regexp.MustCompile(`(?:^|[\s\[=])(?:'` + name + `[^']*'|"` + name + `[^"]*"|` + name + `[^\s'"\]]+)`)
+}()
+
+// The values registered by RegisterSecret, masked in all log entries.
+var secrets = struct {
+	sync.RWMutex
+	values   map[string]struct{}
+	replacer *strings.Replacer
+}{values: make(map[string]struct{})}
+
+// RegisterSecret masks the value in all subsequent log entries, e.g. the content of a token
+// or a secret file. Each line of a multi-line value is registered on its own.
+func RegisterSecret(value string) {
+	secrets.Lock()
+	defer secrets.Unlock()
+
+	changed := false
+	for _, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) < minSecretLength {
+			continue
+		}
+		if _, ok := secrets.values[line]; !ok {
+			secrets.values[line] = struct{}{}
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	// Longer secrets first, the replacer prefers the earlier of the overlapping values
+	values := slices.Collect(maps.Keys(secrets.values))
+	slices.SortFunc(values, func(a, b string) int { return len(b) - len(a) })
+	var oldnew []string
+	for _, secret := range values {
+		oldnew = append(oldnew, secret, redacted)
+	}
+	secrets.replacer = strings.NewReplacer(oldnew...)
+}
+
+// Redact masks the registered secrets and the values of credential-like NAME=value assignments.
+func Redact(s string) string {
+	secrets.RLock()
+	replacer := secrets.replacer
+	secrets.RUnlock()
+
+	if replacer != nil {
+		s = replacer.Replace(s)
+	}
+	return sensitiveAssignmentRegex.ReplaceAllStringFunc(s, func(assignment string) string {
+		// Keep the character before the name, matched as the start of the word
+		prefix := ""
+		if strings.ContainsAny(assignment[:1], " \t\r\n\f\v[=") {
+			prefix, assignment = assignment[:1], assignment[1:]
+		}
+		name, _, _ := strings.Cut(assignment, "=")
+		masked := prefix + name + "=" + redacted
+		if quote := assignment[0]; quote == '\'' || quote == '"' {
+			masked += string(quote)
+		}
+		return masked
+	})
+}
+
+// Redacts the message and the string fields of all log entries.
+type redactHook struct{}
+
+func (redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = Redact(entry.Message)
+	for key, value := range entry.Data {
+		if s, ok := value.(string); ok {
+			entry.Data[key] = Redact(s)
+		}
+	}
+	return nil
+}
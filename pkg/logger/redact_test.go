@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRedact(t *testing.T) {
+	t.Run("should mask the values of credential-like assignments", func(t *testing.T) {
+		g := NewWithT(t)
+
+		g.Expect(Redact("buildah build --build-arg API_TOKEN=abc123 --build-arg VERSION=1.0 .")).To(
+			Equal("buildah build --build-arg API_TOKEN=*** --build-arg VERSION=1.0 ."))
+		g.Expect(Redact("[param] build-args: [DB_PASSWORD=hunter2 AWS_SECRET_ACCESS_KEY=xyz]")).To(
+			Equal("[param] build-args: [DB_PASSWORD=*** AWS_SECRET_ACCESS_KEY=***]"))
+		g.Expect(Redact("podman run --env=GITHUB_TOKEN=abc")).To(Equal("podman run --env=GITHUB_TOKEN=***"))
+		g.Expect(Redact(`buildah build --build-arg 'SSH_KEY=a b' --env "PASSWORD=c d" --env NAME=x`)).To(
+			Equal(`buildah build --build-arg 'SSH_KEY=***' --env "PASSWORD=***" --env NAME=x`))
+	})
+
+	t.Run("should not mask ordinary options", func(t *testing.T) {
+		g := NewWithT(t)
+
+		for _, line := range []string{
+			"buildah build --secret=id=npm,src=/secrets/npm --on-duplicate-secret=suffix .",
+			"cosign verify --key=/keys/cosign.pub quay.io/org/app",
+			"[param] signature-key: /keys/cosign.pub",
+			"git -c http.extraHeader=x --api_key=abc",
+		} {
+			g.Expect(Redact(line)).To(Equal(line))
+		}
+	})
+
+	t.Run("should mask the registered secrets", func(t *testing.T) {
+		g := NewWithT(t)
+
+		RegisterSecret("s3cr3t-token\n")
+		RegisterSecret("line-one\nline-two\n")
+		RegisterSecret("abc")
+
+		g.Expect(Redact("curl -H 'Authorization: Bearer s3cr3t-token'")).To(Equal("curl -H 'Authorization: Bearer ***'"))
+		g.Expect(Redact("got line-two")).To(Equal("got ***"))
+		// Too short to be registered
+		g.Expect(Redact("abc")).To(Equal("abc"))
+	})
+
+	t.Run("should redact log entries", func(t *testing.T) {
+		g := NewWithT(t)
+		var buf bytes.Buffer
+		Logger.SetOutput(&buf)
+		defer func() { _ = InitLogger("info", LogFormatText) }()
+
+		RegisterSecret("registry-password")
+		Logger.WithField("arg", "registry-password").Infof("Running command:\nbuildah build --build-arg NPM_TOKEN=%s", "abc")
+
+		g.Expect(buf.String()).ToNot(ContainSubstring("registry-password"))
+		g.Expect(buf.String()).ToNot(ContainSubstring("NPM_TOKEN=abc"))
+		g.Expect(buf.String()).To(ContainSubstring("NPM_TOKEN=***"))
+	})
+
+	t.Run("should keep redacting after the logger is initialized", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(InitLogger("info", LogFormatJSON)).To(Succeed())
+		defer func() { _ = InitLogger("info", LogFormatText) }()
+		var buf bytes.Buffer
+		Logger.SetOutput(&buf)
+
+		Logger.Info("GITHUB_TOKEN=ghp_abc")
+
+		g.Expect(buf.String()).To(ContainSubstring(`"msg":"GITHUB_TOKEN=***"`))
+		g.Expect(Logger.Hooks[logrus.InfoLevel]).To(ContainElement(redactHook{}))
+	})
+}
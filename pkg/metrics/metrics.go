@@ -0,0 +1,201 @@
+// Package metrics records where the time of a command goes: the durations of its steps
+// and of the CLI tools it runs. The metrics are written into a file when the command
+// exits, as JSON or in the Prometheus textfile format, only if a metrics file is set.
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/konflux-ci/konflux-build-cli/pkg/common"
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+const (
+	FormatJSON       = "json"
+	FormatPrometheus = "prometheus"
+)
+
+type Phase struct {
+	Name            string  `json:"name"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+type Subprocess struct {
+	Command string `json:"command"`
+	// The first argument of the command if it's not a flag, e.g. build for buildah build
+	Subcommand      string  `json:"subcommand,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	ExitCode        int     `json:"exit_code"`
+}
+
+type Metrics struct {
+	Command         string       `json:"command"`
+	ExitCode        int          `json:"exit_code"`
+	StartedAt       time.Time    `json:"started_at"`
+	DurationSeconds float64      `json:"duration_seconds"`
+	Phases          []Phase      `json:"phases"`
+	Subprocesses    []Subprocess `json:"subprocesses"`
+}
+
+type recorder struct {
+	mu      sync.Mutex
+	path    string
+	format  string
+	metrics Metrics
+	written bool
+}
+
+// The recorder of the process, nil if the metrics are disabled.
+var current *recorder
+
+// now is replaced in tests.
+var now = time.Now
+
+// Enable starts recording the metrics of the process, to be written into path by Write.
+func Enable(path, format string) error {
+	if format == "" {
+		format = FormatJSON
+	}
+	if format != FormatJSON && format != FormatPrometheus {
+		return fmt.Errorf("unknown metrics format '%s', expected %s or %s", format, FormatJSON, FormatPrometheus)
+	}
+	current = &recorder{
+		path:   path,
+		format: format,
+		metrics: Metrics{
+			StartedAt:    now(),
+			Phases:       []Phase{},
+			Subprocesses: []Subprocess{},
+		},
+	}
+	l.SetStepObserver(RecordPhase)
+	return nil
+}
+
+// Disable stops recording the metrics, the recorded ones are dropped.
+func Disable() {
+	current = nil
+	l.SetStepObserver(nil)
+}
+
+// RecordPhase records the duration of a step of the command.
+func RecordPhase(name string, duration time.Duration) {
+	r := current
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics.Phases = append(r.metrics.Phases, Phase{Name: name, DurationSeconds: duration.Seconds()})
+}
+
+// RecordSubprocess records the duration of a CLI tool run by the command.
+func RecordSubprocess(command string, args []string, duration time.Duration, exitCode int) {
+	r := current
+	if r == nil {
+		return
+	}
+	subprocess := Subprocess{Command: command, DurationSeconds: duration.Seconds(), ExitCode: exitCode}
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subprocess.Subcommand = args[0]
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics.Subprocesses = append(r.metrics.Subprocesses, subprocess)
+}
+
+// Write writes the recorded metrics of the command into the metrics file, once.
+// Does nothing if the metrics are disabled.
+func Write(command string, exitCode int) error {
+	r := current
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.written {
+		return nil
+	}
+	r.written = true
+
+	r.metrics.Command = command
+	r.metrics.ExitCode = exitCode
+	r.metrics.DurationSeconds = now().Sub(r.metrics.StartedAt).Seconds()
+
+	var content []byte
+	if r.format == FormatPrometheus {
+		content = []byte(formatPrometheus(&r.metrics))
+	} else {
+		var err error
+		if content, err = json.MarshalIndent(r.metrics, "", "  "); err != nil {
+			return err
+		}
+	}
+	if err := common.WriteResultFile(r.path, content); err != nil {
+		return fmt.Errorf("failed to write metrics file '%s': %w", r.path, err)
+	}
+	l.Logger.Debugf("Wrote metrics into '%s'", r.path)
+	return nil
+}
+
+// Format the metrics as gauges for the node exporter textfile collector.
+// Each series must be unique, so repeated phases and subprocesses are summed up.
+func formatPrometheus(m *Metrics) string {
+	var b strings.Builder
+	command := fmt.Sprintf("command=%q", m.Command)
+
+	writeHeader := func(name, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+
+	writeHeader("kbc_command_duration_seconds", "Duration of the command.")
+	fmt.Fprintf(&b, "kbc_command_duration_seconds{%s} %g\n", command, m.DurationSeconds)
+	writeHeader("kbc_command_exit_code", "Exit code of the command.")
+	fmt.Fprintf(&b, "kbc_command_exit_code{%s} %d\n", command, m.ExitCode)
+	writeHeader("kbc_command_start_time_seconds", "Start time of the command since the Unix epoch.")
+	fmt.Fprintf(&b, "kbc_command_start_time_seconds{%s} %d\n", command, m.StartedAt.Unix())
+
+	if len(m.Phases) > 0 {
+		phaseDurations := map[string]float64{}
+		for _, phase := range m.Phases {
+			phaseDurations[phase.Name] += phase.DurationSeconds
+		}
+		writeHeader("kbc_phase_duration_seconds", "Total duration of the steps of the command.")
+		for _, name := range slices.Sorted(maps.Keys(phaseDurations)) {
+			fmt.Fprintf(&b, "kbc_phase_duration_seconds{%s,phase=%q} %g\n", command, name, phaseDurations[name])
+		}
+	}
+
+	if len(m.Subprocesses) > 0 {
+		type subprocessKey struct{ command, subcommand string }
+		durations := map[subprocessKey]float64{}
+		runs := map[subprocessKey]int{}
+		for _, subprocess := range m.Subprocesses {
+			key := subprocessKey{subprocess.Command, subprocess.Subcommand}
+			durations[key] += subprocess.DurationSeconds
+			runs[key]++
+		}
+		keys := slices.SortedFunc(maps.Keys(durations), func(a, b subprocessKey) int {
+			return strings.Compare(a.command+" "+a.subcommand, b.command+" "+b.subcommand)
+		})
+		labels := func(key subprocessKey) string {
+			return fmt.Sprintf("%s,subprocess=%q,subcommand=%q", command, key.command, key.subcommand)
+		}
+		writeHeader("kbc_subprocess_duration_seconds", "Total duration of the runs of a CLI tool.")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "kbc_subprocess_duration_seconds{%s} %g\n", labels(key), durations[key])
+		}
+		writeHeader("kbc_subprocess_runs", "Number of runs of a CLI tool.")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "kbc_subprocess_runs{%s} %d\n", labels(key), runs[key])
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	l "github.com/konflux-ci/konflux-build-cli/pkg/logger"
+)
+
+func TestMetrics(t *testing.T) {
+	startedAt := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	setClock := func(t *testing.T, times ...time.Time) {
+		now = func() time.Time {
+			next := times[0]
+			times = times[1:]
+			return next
+		}
+		t.Cleanup(func() {
+			now = time.Now
+			Disable()
+		})
+	}
+
+	record := func() {
+		endStep := l.StartStep("build")
+		endStep()
+		RecordSubprocess("buildah", []string{"build", "--tls-verify=true", "."}, 2*time.Second, 0)
+		RecordSubprocess("skopeo", []string{"--retry-times", "3", "inspect"}, 500*time.Millisecond, 0)
+		RecordSubprocess("buildah", []string{"build", "."}, time.Second, 1)
+		RecordPhase("push", 3*time.Second)
+		RecordPhase("push", 1500*time.Millisecond)
+	}
+
+	t.Run("should write JSON metrics", func(t *testing.T) {
+		g := NewWithT(t)
+		setClock(t, startedAt, startedAt.Add(90*time.Second))
+		path := filepath.Join(t.TempDir(), "metrics.json")
+
+		g.Expect(Enable(path, "")).To(Succeed())
+		record()
+		g.Expect(Write("image build", 5)).To(Succeed())
+
+		content, err := os.ReadFile(path)
+		g.Expect(err).ToNot(HaveOccurred())
+		var metrics Metrics
+		g.Expect(json.Unmarshal(content, &metrics)).To(Succeed())
+		g.Expect(metrics.Command).To(Equal("image build"))
+		g.Expect(metrics.ExitCode).To(Equal(5))
+		g.Expect(metrics.StartedAt).To(BeTemporally("==", startedAt))
+		g.Expect(metrics.DurationSeconds).To(Equal(90.0))
+		g.Expect(metrics.Phases).To(HaveLen(3))
+		g.Expect(metrics.Phases[0].Name).To(Equal("build"))
+		g.Expect(metrics.Phases[1:]).To(Equal([]Phase{{"push", 3}, {"push", 1.5}}))
+		g.Expect(metrics.Subprocesses).To(Equal([]Subprocess{
+			{Command: "buildah", Subcommand: "build", DurationSeconds: 2},
+			{Command: "skopeo", DurationSeconds: 0.5},
+			{Command: "buildah", Subcommand: "build", DurationSeconds: 1, ExitCode: 1},
+		}))
+	})
+
+	t.Run("should write Prometheus metrics", func(t *testing.T) {
+		g := NewWithT(t)
+		setClock(t, startedAt, startedAt.Add(90*time.Second))
+		path := filepath.Join(t.TempDir(), "kbc.prom")
+
+		g.Expect(Enable(path, FormatPrometheus)).To(Succeed())
+		RecordSubprocess("buildah", []string{"build", "."}, 2*time.Second, 0)
+		RecordSubprocess("skopeo", []string{"--retry-times", "3", "inspect"}, 500*time.Millisecond, 0)
+		RecordSubprocess("buildah", []string{"build", "."}, time.Second, 1)
+		RecordPhase("push", 3*time.Second)
+		RecordPhase("push", 1500*time.Millisecond)
+		g.Expect(Write("image build", 0)).To(Succeed())
+
+		content, err := os.ReadFile(path)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(content)).To(Equal(`# HELP kbc_command_duration_seconds Duration of the command.
+# TYPE kbc_command_duration_seconds gauge
+kbc_command_duration_seconds{command="image build"} 90
+# HELP kbc_command_exit_code Exit code of the command.
+# TYPE kbc_command_exit_code gauge
+kbc_command_exit_code{command="image build"} 0
+# HELP kbc_command_start_time_seconds Start time of the command since the Unix epoch.
+# TYPE kbc_command_start_time_seconds gauge
+kbc_command_start_time_seconds{command="image build"} 1749988800
+# HELP kbc_phase_duration_seconds Total duration of the steps of the command.
+# TYPE kbc_phase_duration_seconds gauge
+kbc_phase_duration_seconds{command="image build",phase="push"} 4.5
+# HELP kbc_subprocess_duration_seconds Total duration of the runs of a CLI tool.
+# TYPE kbc_subprocess_duration_seconds gauge
+kbc_subprocess_duration_seconds{command="image build",subprocess="buildah",subcommand="build"} 3
+kbc_subprocess_duration_seconds{command="image build",subprocess="skopeo",subcommand=""} 0.5
+# HELP kbc_subprocess_runs Number of runs of a CLI tool.
+# TYPE kbc_subprocess_runs gauge
+kbc_subprocess_runs{command="image build",subprocess="buildah",subcommand="build"} 2
+kbc_subprocess_runs{command="image build",subprocess="skopeo",subcommand=""} 1
+`))
+	})
+
+	t.Run("should write the metrics only once", func(t *testing.T) {
+		g := NewWithT(t)
+		setClock(t, startedAt, startedAt.Add(time.Second))
+		path := filepath.Join(t.TempDir(), "metrics.json")
+
+		g.Expect(Enable(path, FormatJSON)).To(Succeed())
+		g.Expect(Write("image build", 0)).To(Succeed())
+		g.Expect(os.Remove(path)).To(Succeed())
+		g.Expect(Write("image build", 1)).To(Succeed())
+
+		g.Expect(path).ToNot(BeAnExistingFile())
+	})
+
+	t.Run("should record nothing if disabled", func(t *testing.T) {
+		g := NewWithT(t)
+
+		record()
+
+		g.Expect(Write("image build", 0)).To(Succeed())
+	})
+
+	t.Run("should reject unknown format", func(t *testing.T) {
+		g := NewWithT(t)
+
+		g.Expect(Enable("metrics.txt", "xml")).To(MatchError(ContainSubstring("unknown metrics format 'xml'")))
+	})
+}